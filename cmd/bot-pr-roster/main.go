@@ -0,0 +1,335 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/test-infra/prow/config/secret"
+	"k8s.io/test-infra/prow/flagutil"
+	"k8s.io/test-infra/prow/github"
+	"k8s.io/test-infra/prow/labels"
+)
+
+// defaultTools maps the automation tools that create PRs to a regex matching the titles of their PRs
+var defaultTools = map[string]string{
+	"registry-replacer":       `^Registry-Replacer autoupdate`,
+	"ocp-build-data-enforcer": `^Updating .* baseimages to match ocp-build-data config`,
+	"golang-version-enforcer": `^Update the golang builders of OCP`,
+	"autoconfigbrancher":      `^Automate config brancher`,
+	"autoowners":              `by autoowners job at`,
+	"autoperibolossync":       `^Automate peribolos configuration sync`,
+	"autopublicizeconfig":     `^Automate publicize configuration sync`,
+	"autotestgridgenerator":   `^Update OpenShift testgrid definitions`,
+	"prow-job-dispatcher":     `^Automate prow job dispatcher`,
+}
+
+type options struct {
+	github flagutil.GitHubOptions
+
+	authors flagutil.Strings
+	orgs    flagutil.Strings
+	repos   flagutil.Strings
+	tools   flagutil.Strings
+
+	staleAfter time.Duration
+
+	slackTokenPath string
+	slackChannel   string
+}
+
+func gatherOptions() options {
+	o := options{}
+	fs := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+
+	fs.Var(&o.authors, "author", "Login of a bot account whose PRs are listed. Can be passed multiple times.")
+	fs.Var(&o.orgs, "org", "Organization to search for PRs in. Can be passed multiple times.")
+	fs.Var(&o.repos, "repo", "Repository in org/repo form to search for PRs in. Can be passed multiple times.")
+	fs.Var(&o.tools, "tool", "Additional tool in name=regex form, where the regex matches the titles of the PRs the tool creates. Can be passed multiple times.")
+	fs.DurationVar(&o.staleAfter, "stale-after", 72*time.Hour, "Age after which a PR is considered stale.")
+	fs.StringVar(&o.slackTokenPath, "slack-token-path", "", "Path to the file containing the Slack token to use. If unset, the digest is printed to stdout.")
+	fs.StringVar(&o.slackChannel, "slack-channel", "", "Slack channel to post the digest to.")
+	o.github.AddFlags(fs)
+
+	if err := fs.Parse(os.Args[1:]); err != nil {
+		logrus.WithError(err).Fatal("could not parse input")
+	}
+	return o
+}
+
+func (o *options) validate() error {
+	if len(o.authors.Strings()) == 0 {
+		return fmt.Errorf("--author is required")
+	}
+	if len(o.orgs.Strings()) == 0 && len(o.repos.Strings()) == 0 {
+		return fmt.Errorf("at least one --org or --repo is required")
+	}
+	for _, repo := range o.repos.Strings() {
+		if len(strings.Split(repo, "/")) != 2 {
+			return fmt.Errorf("--repo %q is not in org/repo form", repo)
+		}
+	}
+	if o.slackTokenPath != "" && o.slackChannel == "" {
+		return fmt.Errorf("--slack-channel is required when --slack-token-path is set")
+	}
+	return o.github.Validate(true)
+}
+
+func main() {
+	o := gatherOptions()
+	if err := o.validate(); err != nil {
+		logrus.WithError(err).Fatal("Invalid options")
+	}
+
+	tools, err := parseTools(o.tools.Strings())
+	if err != nil {
+		logrus.WithError(err).Fatal("Invalid --tool")
+	}
+
+	secrets := []string{o.github.TokenPath}
+	if o.slackTokenPath != "" {
+		secrets = append(secrets, o.slackTokenPath)
+	}
+	secretAgent := &secret.Agent{}
+	if err := secretAgent.Start(secrets); err != nil {
+		logrus.WithError(err).Fatal("Error starting secrets agent.")
+	}
+	client, err := o.github.GitHubClient(secretAgent, true)
+	if err != nil {
+		logrus.WithError(err).Fatal("Error creating github client.")
+	}
+
+	query := searchQuery(o.authors.Strings(), o.orgs.Strings(), o.repos.Strings())
+	roster, err := gatherRoster(client, query, tools, time.Now())
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to gather PRs")
+	}
+	digest := formatDigest(roster, o.staleAfter)
+
+	if o.slackTokenPath == "" {
+		fmt.Print(digest)
+		return
+	}
+	slackClient := slack.New(string(secretAgent.GetSecret(o.slackTokenPath)))
+	if _, _, err := slackClient.PostMessage(o.slackChannel, slack.MsgOptionText(digest, false)); err != nil {
+		logrus.WithError(err).Fatal("Failed to post digest to Slack")
+	}
+	logrus.Infof("Posted digest of %d PRs to %s", len(roster), o.slackChannel)
+}
+
+type tool struct {
+	name  string
+	title *regexp.Regexp
+}
+
+func parseTools(additional []string) ([]tool, error) {
+	raw := map[string]string{}
+	for name, expr := range defaultTools {
+		raw[name] = expr
+	}
+	for _, item := range additional {
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("%q is not in name=regex form", item)
+		}
+		raw[parts[0]] = parts[1]
+	}
+
+	var tools []tool
+	var errs []error
+	for name, expr := range raw {
+		title, err := regexp.Compile(expr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid regex for tool %s: %w", name, err))
+			continue
+		}
+		tools = append(tools, tool{name: name, title: title})
+	}
+	sort.Slice(tools, func(i, j int) bool { return tools[i].name < tools[j].name })
+	return tools, utilerrors.NewAggregate(errs)
+}
+
+func searchQuery(authors, orgs, repos []string) string {
+	parts := []string{"is:pr", "is:open", "archived:false"}
+	for _, author := range authors {
+		parts = append(parts, "author:"+author)
+	}
+	for _, org := range orgs {
+		parts = append(parts, "org:"+org)
+	}
+	for _, repo := range repos {
+		parts = append(parts, "repo:"+repo)
+	}
+	return strings.Join(parts, " ")
+}
+
+type githubClient interface {
+	FindIssues(query, sort string, asc bool) ([]github.Issue, error)
+	GetPullRequest(org, repo string, number int) (*github.PullRequest, error)
+	GetCombinedStatus(org, repo, ref string) (*github.CombinedStatus, error)
+}
+
+// pullRequest is a PR created by one of the tools along with the reasons it can not merge
+type pullRequest struct {
+	org, repo string
+	number    int
+	title     string
+	url       string
+	tool      string
+	age       time.Duration
+	approved  bool
+	ciState   string
+	blockers  []string
+}
+
+func gatherRoster(client githubClient, query string, tools []tool, now time.Time) ([]pullRequest, error) {
+	issues, err := client.FindIssues(query, "created", true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search for PRs with query %q: %w", query, err)
+	}
+
+	var roster []pullRequest
+	var errs []error
+	for _, issue := range issues {
+		if issue.PullRequest == nil {
+			continue
+		}
+		org, repo, err := orgRepoFromURL(issue.HTMLURL)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		pr := pullRequest{
+			org:    org,
+			repo:   repo,
+			number: issue.Number,
+			title:  issue.Title,
+			url:    issue.HTMLURL,
+			tool:   toolFor(issue.Title, tools),
+			age:    now.Sub(issue.CreatedAt),
+		}
+		if err := pr.determineStatus(client, issue.Labels); err != nil {
+			errs = append(errs, fmt.Errorf("failed to determine status of %s: %w", issue.HTMLURL, err))
+			continue
+		}
+		roster = append(roster, pr)
+	}
+	return roster, utilerrors.NewAggregate(errs)
+}
+
+func (pr *pullRequest) determineStatus(client githubClient, issueLabels []github.Label) error {
+	var hasLGTM, hasApproved bool
+	for _, label := range issueLabels {
+		switch {
+		case label.Name == labels.LGTM:
+			hasLGTM = true
+		case label.Name == labels.Approved:
+			hasApproved = true
+		case label.Name == labels.NeedsRebase, strings.HasPrefix(label.Name, "do-not-merge/"):
+			pr.blockers = append(pr.blockers, "label "+label.Name)
+		}
+	}
+	pr.approved = hasLGTM && hasApproved
+	if !hasLGTM {
+		pr.blockers = append(pr.blockers, "missing "+labels.LGTM)
+	}
+	if !hasApproved {
+		pr.blockers = append(pr.blockers, "missing "+labels.Approved)
+	}
+
+	pull, err := client.GetPullRequest(pr.org, pr.repo, pr.number)
+	if err != nil {
+		return fmt.Errorf("failed to get PR: %w", err)
+	}
+	if pull.Mergable != nil && !*pull.Mergable {
+		pr.blockers = append(pr.blockers, "merge conflict")
+	}
+	status, err := client.GetCombinedStatus(pr.org, pr.repo, pull.Head.SHA)
+	if err != nil {
+		return fmt.Errorf("failed to get status of %s: %w", pull.Head.SHA, err)
+	}
+	pr.ciState = status.State
+	for _, context := range status.Statuses {
+		if context.State == github.StatusFailure || context.State == github.StatusError {
+			pr.blockers = append(pr.blockers, "failing "+context.Context)
+		}
+	}
+	return nil
+}
+
+func orgRepoFromURL(url string) (string, string, error) {
+	// https://github.com/org/repo/pull/123
+	parts := strings.Split(strings.TrimPrefix(url, "https://github.com/"), "/")
+	if len(parts) != 4 {
+		return "", "", fmt.Errorf("can not determine org and repo of PR %s", url)
+	}
+	return parts[0], parts[1], nil
+}
+
+func toolFor(title string, tools []tool) string {
+	for _, tool := range tools {
+		if tool.title.MatchString(title) {
+			return tool.name
+		}
+	}
+	return "other"
+}
+
+func formatDigest(roster []pullRequest, staleAfter time.Duration) string {
+	if len(roster) == 0 {
+		return "There are no open PRs created by automation.\n"
+	}
+
+	byTool := map[string][]pullRequest{}
+	var stale int
+	for _, pr := range roster {
+		byTool[pr.tool] = append(byTool[pr.tool], pr)
+		if pr.age > staleAfter {
+			stale++
+		}
+	}
+	var toolNames []string
+	for name := range byTool {
+		toolNames = append(toolNames, name)
+	}
+	sort.Strings(toolNames)
+
+	digest := &strings.Builder{}
+	fmt.Fprintf(digest, "*%d open PRs created by automation, %d of them older than %s:*\n", len(roster), stale, formatAge(staleAfter))
+	for _, name := range toolNames {
+		prs := byTool[name]
+		sort.Slice(prs, func(i, j int) bool { return prs[i].age > prs[j].age })
+		fmt.Fprintf(digest, "\n*%s* (%d)\n", name, len(prs))
+		for _, pr := range prs {
+			var marker string
+			if pr.age > staleAfter {
+				marker = " :warning:"
+			}
+			review := "not approved"
+			if pr.approved {
+				review = "approved"
+			}
+			fmt.Fprintf(digest, "• <%s|%s/%s#%d>%s: open for %s, %s, CI %s", pr.url, pr.org, pr.repo, pr.number, marker, formatAge(pr.age), review, pr.ciState)
+			if len(pr.blockers) > 0 {
+				fmt.Fprintf(digest, ", blocked by: %s", strings.Join(pr.blockers, ", "))
+			}
+			digest.WriteString("\n")
+		}
+	}
+	return digest.String()
+}
+
+func formatAge(age time.Duration) string {
+	if age >= 24*time.Hour {
+		return fmt.Sprintf("%dd", int(age.Hours()/24))
+	}
+	return fmt.Sprintf("%dh", int(age.Hours()))
+}
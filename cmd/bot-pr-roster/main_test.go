@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"k8s.io/test-infra/prow/github"
+)
+
+type fakeGitHubClient struct {
+	issues   []github.Issue
+	prs      map[int]*github.PullRequest
+	statuses map[string]*github.CombinedStatus
+}
+
+func (f *fakeGitHubClient) FindIssues(_, _ string, _ bool) ([]github.Issue, error) {
+	return f.issues, nil
+}
+
+func (f *fakeGitHubClient) GetPullRequest(_, _ string, number int) (*github.PullRequest, error) {
+	if pr, ok := f.prs[number]; ok {
+		return pr, nil
+	}
+	return nil, fmt.Errorf("no PR %d", number)
+}
+
+func (f *fakeGitHubClient) GetCombinedStatus(_, _, ref string) (*github.CombinedStatus, error) {
+	if status, ok := f.statuses[ref]; ok {
+		return status, nil
+	}
+	return nil, fmt.Errorf("no status for %s", ref)
+}
+
+func TestRoster(t *testing.T) {
+	now := time.Date(2021, 5, 10, 12, 0, 0, 0, time.UTC)
+	conflict := false
+	client := &fakeGitHubClient{
+		issues: []github.Issue{
+			{
+				Number:      1,
+				Title:       "Registry-Replacer autoupdate",
+				HTMLURL:     "https://github.com/openshift/release/pull/1",
+				CreatedAt:   now.Add(-5 * 24 * time.Hour),
+				PullRequest: &struct{}{},
+			},
+			{
+				Number:      2,
+				Title:       "Updating Dockerfile baseimages to match ocp-build-data config",
+				HTMLURL:     "https://github.com/openshift/origin/pull/2",
+				CreatedAt:   now.Add(-3 * time.Hour),
+				Labels:      []github.Label{{Name: "lgtm"}, {Name: "approved"}},
+				PullRequest: &struct{}{},
+			},
+			{
+				Number:      3,
+				Title:       "Bump the thing",
+				HTMLURL:     "https://github.com/openshift/ci-tools/pull/3",
+				CreatedAt:   now.Add(-4 * 24 * time.Hour),
+				Labels:      []github.Label{{Name: "lgtm"}, {Name: "approved"}, {Name: "do-not-merge/hold"}},
+				PullRequest: &struct{}{},
+			},
+			{
+				Number:  4,
+				Title:   "Not a PR",
+				HTMLURL: "https://github.com/openshift/ci-tools/issues/4",
+			},
+		},
+		prs: map[int]*github.PullRequest{
+			1: {Head: github.PullRequestBranch{SHA: "one"}, Mergable: &conflict},
+			2: {Head: github.PullRequestBranch{SHA: "two"}},
+			3: {Head: github.PullRequestBranch{SHA: "three"}},
+		},
+		statuses: map[string]*github.CombinedStatus{
+			"one":   {State: "failure", Statuses: []github.Status{{Context: "ci/prow/unit", State: "failure"}, {Context: "ci/prow/lint", State: "success"}}},
+			"two":   {State: "pending"},
+			"three": {State: "success"},
+		},
+	}
+	tools, err := parseTools([]string{"bumper=^Bump "})
+	if err != nil {
+		t.Fatalf("failed to parse tools: %v", err)
+	}
+
+	roster, err := gatherRoster(client, "is:pr", tools, now)
+	if err != nil {
+		t.Fatalf("failed to gather roster: %v", err)
+	}
+	expected := `*3 open PRs created by automation, 2 of them older than 3d:*
+
+*bumper* (1)
+• <https://github.com/openshift/ci-tools/pull/3|openshift/ci-tools#3> :warning:: open for 4d, approved, CI success, blocked by: label do-not-merge/hold
+
+*ocp-build-data-enforcer* (1)
+• <https://github.com/openshift/origin/pull/2|openshift/origin#2>: open for 3h, approved, CI pending
+
+*registry-replacer* (1)
+• <https://github.com/openshift/release/pull/1|openshift/release#1> :warning:: open for 5d, not approved, CI failure, blocked by: missing lgtm, missing approved, merge conflict, failing ci/prow/unit
+`
+	if diff := cmp.Diff(expected, formatDigest(roster, 72*time.Hour)); diff != "" {
+		t.Errorf("digest differs from expected: %s", diff)
+	}
+}
+
+func TestSearchQuery(t *testing.T) {
+	expected := "is:pr is:open archived:false author:openshift-bot author:openshift-merge-robot org:openshift repo:openshift-priv/ci-tools"
+	if diff := cmp.Diff(expected, searchQuery([]string{"openshift-bot", "openshift-merge-robot"}, []string{"openshift"}, []string{"openshift-priv/ci-tools"})); diff != "" {
+		t.Errorf("query differs from expected: %s", diff)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 
 	"github.com/openshift/ci-tools/pkg/api"
@@ -14,34 +15,39 @@ import (
 	"github.com/openshift/ci-tools/pkg/steps/release"
 )
 
-type tagSet map[api.ImageStreamTagReference][]*config.Info
-
 func main() {
 	var configDir, registryDir string
+	var onlyPromotionCollisions bool
 	flag.StringVar(&configDir, "config-dir", "", "The directory containing configuration files.")
 	flag.StringVar(&registryDir, "registry", "", "Path to the step registry directory")
+	flag.BoolVar(&onlyPromotionCollisions, "only-promotion-collisions", false, "Only check that no ImageStreamTag is promoted to by more than one configuration.")
 	flag.Parse()
 
 	if configDir == "" {
 		fmt.Fprintln(os.Stderr, "The --config-dir flag is required but was not provided")
 		os.Exit(1)
 	}
+	if onlyPromotionCollisions && registryDir != "" {
+		fmt.Fprintln(os.Stderr, "The --registry flag can not be used with --only-promotion-collisions")
+		os.Exit(1)
+	}
 	resolver, err := loadResolver(registryDir)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "failed to load registry: %v\n", err)
 		os.Exit(1)
 	}
-	seen := tagSet{}
+	configurations := map[string]api.ReleaseBuildConfiguration{}
 	if err := config.OperateOnCIOperatorConfigDir(configDir, func(configuration *api.ReleaseBuildConfiguration, repoInfo *config.Info) error {
+		configurations[identifierFor(repoInfo)] = *configuration
+		if onlyPromotionCollisions {
+			return nil
+		}
 		// basic validation of the configuration is implicit in the iteration
 		if resolver != nil {
 			if _, err := registry.ResolveConfig(resolver, *configuration); err != nil {
 				return err
 			}
 		}
-		for _, tag := range release.PromotedTags(configuration) {
-			seen[tag] = append(seen[tag], repoInfo)
-		}
 		if configuration.PromotionConfiguration != nil && configuration.PromotionConfiguration.RegistryOverride != "" {
 			return errors.New("setting promotion.registry_override is not allowed")
 		}
@@ -50,7 +56,7 @@ func main() {
 		fmt.Fprintf(os.Stderr, "error validating configuration files: %v\n", err)
 		os.Exit(1)
 	}
-	if dupes := validateTags(seen); len(dupes) > 0 {
+	if dupes := validateTags(release.PromotionCollisions(configurations)); len(dupes) > 0 {
 		fmt.Fprintln(os.Stderr, "non-unique image publication found: ")
 		for _, dupe := range dupes {
 			fmt.Fprintf(os.Stderr, "ERROR: %v\n", dupe)
@@ -70,21 +76,23 @@ func loadResolver(path string) (registry.Resolver, error) {
 	return registry.NewResolver(refs, chains, workflows, observers), nil
 }
 
-func validateTags(seen tagSet) []error {
+func identifierFor(info *config.Info) string {
+	identifier := fmt.Sprintf("%s/%s@%s", info.Org, info.Repo, info.Branch)
+	if info.Variant != "" {
+		identifier = fmt.Sprintf("%s [%s]", identifier, info.Variant)
+	}
+	return identifier
+}
+
+func validateTags(collisions map[string][]string) []error {
+	var tags []string
+	for tag := range collisions {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
 	var dupes []error
-	for tag, infos := range seen {
-		if len(infos) <= 1 {
-			continue
-		}
-		formatted := []string{}
-		for _, info := range infos {
-			identifier := fmt.Sprintf("%s/%s@%s", info.Org, info.Repo, info.Branch)
-			if info.Variant != "" {
-				identifier = fmt.Sprintf("%s [%s]", identifier, info.Variant)
-			}
-			formatted = append(formatted, identifier)
-		}
-		dupes = append(dupes, fmt.Errorf("output tag %s is promoted from more than one place: %v", tag.ISTagName(), strings.Join(formatted, ", ")))
+	for _, tag := range tags {
+		dupes = append(dupes, fmt.Errorf("output tag %s is promoted from more than one place: %v", tag, strings.Join(collisions[tag], ", ")))
 	}
 	return dupes
 }
@@ -0,0 +1,78 @@
+// ci-operator-config-lint enforces the policy of an organization on the
+// ci-operator configuration files, e.g. which namespaces images may be
+// promoted to. The findings can be reported as text, as GitHub annotations
+// or in the SARIF format to give feedback on pull requests.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/config"
+)
+
+type options struct {
+	configDir  string
+	policyPath string
+	format     string
+}
+
+func (o *options) validate() error {
+	if o.configDir == "" {
+		return errors.New("--config-dir is required")
+	}
+	if o.policyPath == "" {
+		return errors.New("--policy is required")
+	}
+	if !sets.NewString(formats...).Has(o.format) {
+		return fmt.Errorf("--output must be one of %v", formats)
+	}
+	return nil
+}
+
+func gatherOptions() options {
+	o := options{}
+	flag.StringVar(&o.configDir, "config-dir", "", "The directory containing configuration files.")
+	flag.StringVar(&o.policyPath, "policy", "", "Path to the policy file with the rules to enforce.")
+	flag.StringVar(&o.format, "output", formatText, fmt.Sprintf("The format of the report, one of %v.", formats))
+	flag.Parse()
+	return o
+}
+
+func main() {
+	o := gatherOptions()
+	if err := o.validate(); err != nil {
+		logrus.WithError(err).Fatal("Invalid options")
+	}
+	policy, err := loadPolicy(o.policyPath)
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to load the policy")
+	}
+
+	var findings []finding
+	if err := config.OperateOnCIOperatorConfigDir(o.configDir, func(configuration *api.ReleaseBuildConfiguration, info *config.Info) error {
+		raw, err := ioutil.ReadFile(info.Filename)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", info.Filename, err)
+		}
+		findings = append(findings, policy.rulesFor(info.Org).lint(configuration, info, raw)...)
+		return nil
+	}); err != nil {
+		logrus.WithError(err).Fatal("Failed to load the configurations")
+	}
+
+	if err := writeReport(os.Stdout, o.format, findings); err != nil {
+		logrus.WithError(err).Fatal("Failed to write the report")
+	}
+	if len(findings) != 0 {
+		logrus.Fatalf("Found %d violations of the policy", len(findings))
+	}
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/config"
+)
+
+const (
+	ruleForbiddenRegistry = "forbidden-registry"
+	rulePromotionNS       = "promotion-namespace"
+	ruleResourceRequests  = "resource-requests"
+	ruleImageOwners       = "image-owners"
+)
+
+// ruleDescriptions describe the rules for the consumers of the report
+var ruleDescriptions = map[string]string{
+	ruleForbiddenRegistry: "Configurations must not reference forbidden registries directly",
+	rulePromotionNS:       "Images must be promoted to an allowed namespace",
+	ruleResourceRequests:  "Containers must declare resource requests",
+	ruleImageOwners:       "Repositories building images must have an OWNERS file next to their configuration",
+}
+
+// Policy holds the rules enforced on the configurations. The rules at the
+// top level apply to all orgs without an entry in Orgs, an entry replaces
+// them entirely for the org.
+type Policy struct {
+	Rules
+	Orgs map[string]Rules `json:"orgs,omitempty"`
+}
+
+// Rules are the checks enabled for a set of configurations
+type Rules struct {
+	// ForbiddenRegistries are registry hosts that must not appear in the
+	// configuration, e.g. the internal service address of a registry
+	ForbiddenRegistries []string `json:"forbidden_registries,omitempty"`
+	// PromotionNamespaces is the allowlist of namespaces images may be
+	// promoted to. Any namespace is allowed when empty.
+	PromotionNamespaces []string `json:"promotion_namespaces,omitempty"`
+	// RequireResourceRequests requires every entry in `resources` to set requests
+	RequireResourceRequests bool `json:"require_resource_requests,omitempty"`
+	// RequireImageOwners requires an OWNERS file for repositories building images
+	RequireImageOwners bool `json:"require_image_owners,omitempty"`
+}
+
+func loadPolicy(path string) (*Policy, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the policy: %w", err)
+	}
+	var policy Policy
+	if err := yaml.UnmarshalStrict(raw, &policy); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal the policy: %w", err)
+	}
+	return &policy, nil
+}
+
+func (p *Policy) rulesFor(org string) Rules {
+	if rules, ok := p.Orgs[org]; ok {
+		return rules
+	}
+	return p.Rules
+}
+
+// finding is a violation of a rule in a configuration file
+type finding struct {
+	Rule    string
+	File    string
+	Line    int
+	Message string
+}
+
+// lint checks the configuration against the rules, raw is the content of the
+// file the configuration was loaded from and is used to locate the findings
+func (r Rules) lint(configuration *api.ReleaseBuildConfiguration, info *config.Info, raw []byte) []finding {
+	var findings []finding
+	add := func(rule string, line int, format string, args ...interface{}) {
+		findings = append(findings, finding{Rule: rule, File: info.Filename, Line: line, Message: fmt.Sprintf(format, args...)})
+	}
+
+	lines := bytes.Split(raw, []byte("\n"))
+	for _, registry := range r.ForbiddenRegistries {
+		for i, line := range lines {
+			if bytes.Contains(line, []byte(registry)) {
+				add(ruleForbiddenRegistry, i+1, "the registry %s must not be referenced directly", registry)
+			}
+		}
+	}
+
+	if promotion := configuration.PromotionConfiguration; promotion != nil && !promotion.Disabled && len(r.PromotionNamespaces) != 0 {
+		if allowed := sets.NewString(r.PromotionNamespaces...); !allowed.Has(promotion.Namespace) {
+			add(rulePromotionNS, lineOf(lines, "promotion"), "images must not be promoted to the namespace %s, allowed namespaces: %v", promotion.Namespace, allowed.List())
+		}
+	}
+
+	if r.RequireResourceRequests {
+		if len(configuration.Resources) == 0 {
+			add(ruleResourceRequests, 1, "resources: requests must be declared")
+		}
+		var containers []string
+		for container := range configuration.Resources {
+			containers = append(containers, container)
+		}
+		sort.Strings(containers)
+		for _, container := range containers {
+			if len(configuration.Resources[container].Requests) == 0 {
+				add(ruleResourceRequests, lineOf(lines, "resources"), "resources[%q]: requests must be declared", container)
+			}
+		}
+	}
+
+	if r.RequireImageOwners && len(configuration.Images) != 0 {
+		if _, err := os.Stat(filepath.Join(info.RepoPath, "OWNERS")); err != nil {
+			add(ruleImageOwners, lineOf(lines, "images"), "the repository builds images but %s has no OWNERS file", info.RepoPath)
+		}
+	}
+	return findings
+}
+
+// lineOf returns the line of a top-level key in the file, or the first line
+// if the key is not found
+func lineOf(lines [][]byte, key string) int {
+	prefix := []byte(key + ":")
+	for i, line := range lines {
+		if bytes.HasPrefix(line, prefix) {
+			return i + 1
+		}
+	}
+	return 1
+}
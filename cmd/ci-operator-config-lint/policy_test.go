@@ -0,0 +1,134 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/config"
+)
+
+func TestLint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config-lint")
+	if err != nil {
+		t.Fatalf("failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	owned := filepath.Join(dir, "org", "owned")
+	for _, repo := range []string{owned, filepath.Join(dir, "org", "repo")} {
+		if err := os.MkdirAll(repo, 0755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+	}
+	if err := ioutil.WriteFile(filepath.Join(owned, "OWNERS"), []byte("approvers:\n- someone\n"), 0644); err != nil {
+		t.Fatalf("failed to write OWNERS: %v", err)
+	}
+
+	raw := []byte(`base_images:
+  base:
+    name: base
+    namespace: ci
+    tag: latest
+images:
+- from: base
+  to: component
+promotion:
+  namespace: other
+resources:
+  '*':
+    limits:
+      memory: 4Gi
+tests:
+- as: e2e
+  commands: oc image mirror registry.svc.ci.openshift.org/ci/base:latest quay.io/org/base:latest
+zz_generated_metadata:
+  branch: master
+  org: org
+  repo: repo
+`)
+	configuration := &api.ReleaseBuildConfiguration{
+		Images:                 []api.ProjectDirectoryImageBuildStepConfiguration{{From: "base", To: "component"}},
+		PromotionConfiguration: &api.PromotionConfiguration{Namespace: "other"},
+		Resources:              api.ResourceConfiguration{"*": {Limits: api.ResourceList{"memory": "4Gi"}}},
+	}
+	rules := Rules{
+		ForbiddenRegistries:     []string{"registry.svc.ci.openshift.org"},
+		PromotionNamespaces:     []string{"ocp", "openshift"},
+		RequireResourceRequests: true,
+		RequireImageOwners:      true,
+	}
+
+	var testCases = []struct {
+		name          string
+		rules         Rules
+		configuration *api.ReleaseBuildConfiguration
+		raw           []byte
+		repoPath      string
+		expected      []finding
+	}{
+		{
+			name:          "no rules",
+			configuration: configuration,
+			raw:           raw,
+			repoPath:      filepath.Join(dir, "org", "repo"),
+		},
+		{
+			name:          "all rules are violated",
+			rules:         rules,
+			configuration: configuration,
+			raw:           raw,
+			repoPath:      filepath.Join(dir, "org", "repo"),
+			expected: []finding{
+				{Rule: ruleForbiddenRegistry, File: "config.yaml", Line: 17, Message: "the registry registry.svc.ci.openshift.org must not be referenced directly"},
+				{Rule: rulePromotionNS, File: "config.yaml", Line: 9, Message: "images must not be promoted to the namespace other, allowed namespaces: [ocp openshift]"},
+				{Rule: ruleResourceRequests, File: "config.yaml", Line: 11, Message: `resources["*"]: requests must be declared`},
+				{Rule: ruleImageOwners, File: "config.yaml", Line: 6, Message: "the repository builds images but " + filepath.Join(dir, "org", "repo") + " has no OWNERS file"},
+			},
+		},
+		{
+			name:  "compliant configuration",
+			rules: rules,
+			configuration: &api.ReleaseBuildConfiguration{
+				Images:                 []api.ProjectDirectoryImageBuildStepConfiguration{{From: "base", To: "component"}},
+				PromotionConfiguration: &api.PromotionConfiguration{Namespace: "ocp"},
+				Resources:              api.ResourceConfiguration{"*": {Requests: api.ResourceList{"cpu": "100m"}}},
+			},
+			repoPath: owned,
+		},
+		{
+			name:  "disabled promotion and no resources",
+			rules: Rules{PromotionNamespaces: []string{"ocp"}, RequireResourceRequests: true},
+			configuration: &api.ReleaseBuildConfiguration{
+				PromotionConfiguration: &api.PromotionConfiguration{Namespace: "other", Disabled: true},
+			},
+			expected: []finding{
+				{Rule: ruleResourceRequests, File: "config.yaml", Line: 1, Message: "resources: requests must be declared"},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			info := &config.Info{Filename: "config.yaml", RepoPath: tc.repoPath}
+			if diff := cmp.Diff(tc.expected, tc.rules.lint(tc.configuration, info, tc.raw)); diff != "" {
+				t.Errorf("unexpected findings: %s", diff)
+			}
+		})
+	}
+}
+
+func TestRulesFor(t *testing.T) {
+	policy := Policy{
+		Rules: Rules{RequireImageOwners: true},
+		Orgs:  map[string]Rules{"special": {PromotionNamespaces: []string{"special"}}},
+	}
+	if diff := cmp.Diff(Rules{RequireImageOwners: true}, policy.rulesFor("org")); diff != "" {
+		t.Errorf("unexpected rules for an org without an entry: %s", diff)
+	}
+	if diff := cmp.Diff(Rules{PromotionNamespaces: []string{"special"}}, policy.rulesFor("special")); diff != "" {
+		t.Errorf("unexpected rules for an org with an entry: %s", diff)
+	}
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/openshift/ci-tools/pkg/testhelper"
+)
+
+func TestWriteReport(t *testing.T) {
+	findings := []finding{
+		{Rule: ruleForbiddenRegistry, File: "ci-operator/config/org/repo/org-repo-master.yaml", Line: 16, Message: "the registry registry.svc.ci.openshift.org must not be referenced directly"},
+		{Rule: rulePromotionNS, File: "ci-operator/config/org/repo/org-repo-master.yaml", Line: 9, Message: "images must not be promoted to the namespace other, allowed namespaces: [ocp openshift]"},
+	}
+	for _, format := range formats {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeReport(&buf, format, findings); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			testhelper.CompareWithFixture(t, buf.Bytes())
+		})
+	}
+}
+
+func TestEscapeGitHubProperty(t *testing.T) {
+	if actual, expected := escapeGitHubProperty("a,b:c%d\ne"), "a%2Cb%3Ac%25d%0Ae"; actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+}
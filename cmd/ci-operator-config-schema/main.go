@@ -0,0 +1,35 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/openshift/ci-tools/pkg/config"
+)
+
+func main() {
+	var output string
+	flag.StringVar(&output, "output", "", "File to write the JSON schema of ci-operator configuration files to. Written to stdout if unset.")
+	flag.Parse()
+
+	raw, err := json.MarshalIndent(config.CIOperatorConfigSchema(), "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to marshal the schema: %v\n", err)
+		os.Exit(1)
+	}
+	raw = append(raw, '\n')
+	if output == "" {
+		if _, err := os.Stdout.Write(raw); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write the schema: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if err := ioutil.WriteFile(output, raw, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write the schema: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"k8s.io/apimachinery/pkg/util/cache"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/load/agents"
+)
+
+// resolvedConfigTTL bounds how long an entry is kept; entries never go stale
+// as the generations of the agents are part of the key
+const resolvedConfigTTL = time.Hour
+
+var resolvedConfigCacheMetric = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "configresolver_resolved_config_cache_total",
+		Help: "Lookups of resolved configs in the cache, by result",
+	},
+	[]string{"result"},
+)
+
+func init() {
+	prometheus.MustRegister(resolvedConfigCacheMetric)
+}
+
+// resolvedConfig is a resolved configuration marshalled for a response
+type resolvedConfig struct {
+	body []byte
+	etag string
+}
+
+func newResolvedConfig(body []byte) *resolvedConfig {
+	hash := sha256.Sum256(body)
+	return &resolvedConfig{body: body, etag: `"` + hex.EncodeToString(hash[:]) + `"`}
+}
+
+// resolvedConfigKey identifies a resolved configuration: it can only change
+// when either the configuration or the registry are reloaded
+type resolvedConfigKey struct {
+	configGeneration   int
+	registryGeneration int
+	metadata           api.Metadata
+}
+
+// resolvedConfigCache keeps the configurations that were resolved recently,
+// so identical requests do not need to resolve them again
+type resolvedConfigCache struct {
+	configAgent   agents.ConfigAgent
+	registryAgent agents.RegistryAgent
+	cache         *cache.LRUExpireCache
+}
+
+func newResolvedConfigCache(configAgent agents.ConfigAgent, registryAgent agents.RegistryAgent, size int) *resolvedConfigCache {
+	return &resolvedConfigCache{
+		configAgent:   configAgent,
+		registryAgent: registryAgent,
+		cache:         cache.NewLRUExpireCache(size),
+	}
+}
+
+func (c *resolvedConfigCache) key(metadata api.Metadata) resolvedConfigKey {
+	return resolvedConfigKey{
+		configGeneration:   c.configAgent.GetGeneration(),
+		registryGeneration: c.registryAgent.GetGeneration(),
+		metadata:           metadata,
+	}
+}
+
+// get returns the resolved configuration for the metadata, calling resolve
+// when it is not cached for the current generations of the agents
+func (c *resolvedConfigCache) get(metadata api.Metadata, resolve func() (*resolvedConfig, error)) (*resolvedConfig, error) {
+	key := c.key(metadata)
+	if cached, ok := c.cache.Get(key); ok {
+		resolvedConfigCacheMetric.WithLabelValues("hit").Inc()
+		return cached.(*resolvedConfig), nil
+	}
+	resolvedConfigCacheMetric.WithLabelValues("miss").Inc()
+	resolved, err := resolve()
+	if err != nil {
+		return nil, err
+	}
+	// a reload while resolving may have mixed generations, do not cache that
+	if c.key(metadata) == key {
+		c.cache.Add(key, resolved, resolvedConfigTTL)
+	}
+	return resolved, nil
+}
+
+// etagMatches determines whether the If-None-Match header of the request
+// matches the ETag, so the client already has the current content
+func etagMatches(r *http.Request, etag string) bool {
+	header := r.Header.Get("If-None-Match")
+	if header == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
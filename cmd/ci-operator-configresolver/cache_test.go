@@ -0,0 +1,134 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/load/agents"
+)
+
+type fakeConfigAgent struct {
+	agents.ConfigAgent
+	generation int
+	lookups    int
+}
+
+func (a *fakeConfigAgent) GetMatchingConfig(metadata api.Metadata) (api.ReleaseBuildConfiguration, error) {
+	a.lookups++
+	if metadata.Org != "org" {
+		return api.ReleaseBuildConfiguration{}, errors.New("no config")
+	}
+	return api.ReleaseBuildConfiguration{Metadata: metadata}, nil
+}
+
+func (a *fakeConfigAgent) GetGeneration() int {
+	return a.generation
+}
+
+type fakeRegistryAgent struct {
+	agents.RegistryAgent
+	generation int
+	resolved   int
+}
+
+func (a *fakeRegistryAgent) ResolveConfig(config api.ReleaseBuildConfiguration) (api.ReleaseBuildConfiguration, error) {
+	a.resolved++
+	config.Metadata.Variant = "resolved"
+	return config, nil
+}
+
+func (a *fakeRegistryAgent) GetGeneration() int {
+	return a.generation
+}
+
+func TestResolveConfigCaching(t *testing.T) {
+	configAgent := &fakeConfigAgent{}
+	registryAgent := &fakeRegistryAgent{}
+	handler := resolveConfig(configAgent, registryAgent, newResolvedConfigCache(configAgent, registryAgent, 10))
+	request := func(org, ifNoneMatch string) *httptest.ResponseRecorder {
+		t.Helper()
+		r := httptest.NewRequest(http.MethodGet, "/config?org="+org+"&repo=repo&branch=master", nil)
+		if ifNoneMatch != "" {
+			r.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	first := request("org", "")
+	if first.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, first.Code, first.Body.String())
+	}
+	etag := first.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("expected an ETag in the response")
+	}
+
+	second := request("org", "")
+	if diff := cmp.Diff(first.Body.String(), second.Body.String()); diff != "" {
+		t.Errorf("cached response differs: %s", diff)
+	}
+	if diff := cmp.Diff(etag, second.Header().Get("ETag")); diff != "" {
+		t.Errorf("cached ETag differs: %s", diff)
+	}
+	if registryAgent.resolved != 1 || configAgent.lookups != 1 {
+		t.Errorf("expected the config to be resolved once, got %d lookups and %d resolutions", configAgent.lookups, registryAgent.resolved)
+	}
+
+	notModified := request("org", `"other", `+etag)
+	if notModified.Code != http.StatusNotModified {
+		t.Errorf("expected status %d, got %d", http.StatusNotModified, notModified.Code)
+	}
+	if notModified.Body.Len() != 0 {
+		t.Errorf("expected no body, got %s", notModified.Body.String())
+	}
+
+	registryAgent.generation++
+	request("org", "")
+	configAgent.generation++
+	request("org", "")
+	if registryAgent.resolved != 3 {
+		t.Errorf("expected a reload of either agent to resolve the config again, got %d resolutions", registryAgent.resolved)
+	}
+
+	for i := 0; i < 2; i++ {
+		if missing := request("other", ""); missing.Code != http.StatusNotFound {
+			t.Errorf("expected status %d, got %d", http.StatusNotFound, missing.Code)
+		}
+	}
+	if configAgent.lookups != 5 {
+		t.Errorf("expected failed lookups not to be cached, got %d lookups", configAgent.lookups)
+	}
+}
+
+func TestEtagMatches(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		header   string
+		expected bool
+	}{
+		{name: "no header"},
+		{name: "same tag", header: `"tag"`, expected: true},
+		{name: "different tag", header: `"other"`},
+		{name: "one of many tags", header: `"other", "tag"`, expected: true},
+		{name: "weak tag", header: `W/"tag"`, expected: true},
+		{name: "any tag", header: "*", expected: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/config", nil)
+			if tc.header != "" {
+				r.Header.Set("If-None-Match", tc.header)
+			}
+			if actual := etagMatches(r, `"tag"`); actual != tc.expected {
+				t.Errorf("expected %t, got %t", tc.expected, actual)
+			}
+		})
+	}
+}
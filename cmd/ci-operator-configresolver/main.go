@@ -11,6 +11,8 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/ghodss/yaml"
+	"github.com/pmezard/go-difflib/difflib"
 	"github.com/sirupsen/logrus"
 
 	prowConfig "k8s.io/test-infra/prow/config"
@@ -22,13 +24,16 @@ import (
 	"k8s.io/test-infra/prow/simplifypath"
 
 	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/config"
 	"github.com/openshift/ci-tools/pkg/load/agents"
+	"github.com/openshift/ci-tools/pkg/steps/release"
 	"github.com/openshift/ci-tools/pkg/webreg"
 )
 
 type options struct {
 	configPath             string
 	registryPath           string
+	releaseRepoPath        string
 	logLevel               string
 	address                string
 	port                   int
@@ -37,6 +42,7 @@ type options struct {
 	gracePeriod            time.Duration
 	validateOnly           bool
 	flatRegistry           bool
+	resolvedCacheSize      int
 	instrumentationOptions flagutil.InstrumentationOptions
 }
 
@@ -49,6 +55,7 @@ func gatherOptions() (options, error) {
 	fs := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
 	fs.StringVar(&o.configPath, "config", "", "Path to config dirs")
 	fs.StringVar(&o.registryPath, "registry", "", "Path to registry dirs")
+	fs.StringVar(&o.releaseRepoPath, "release-repo", "", "Path to a git clone of the release repo. When set, configuration diffs between its revisions are served.")
 	fs.StringVar(&o.logLevel, "log-level", "info", "Level at which to log output.")
 	fs.StringVar(&o.address, "address", ":8080", "DEPRECATED: Address to run server on")
 	fs.StringVar(&o.uiAddress, "ui-address", ":8082", "DEPRECATED: Address to run the registry UI on")
@@ -57,6 +64,7 @@ func gatherOptions() (options, error) {
 	fs.DurationVar(&o.gracePeriod, "gracePeriod", time.Second*10, "Grace period for server shutdown")
 	_ = fs.Duration("cycle", time.Minute*2, "Legacy flag kept for compatibility. Does nothing")
 	fs.BoolVar(&o.validateOnly, "validate-only", false, "Load the config and registry, validate them and exit.")
+	fs.IntVar(&o.resolvedCacheSize, "resolved-config-cache-size", 1000, "How many resolved configs to keep in memory for repeated requests.")
 	fs.BoolVar(&o.flatRegistry, "flat-registry", false, "Disable directory structure based registry validation")
 	o.instrumentationOptions.AddFlags(fs)
 	if err := fs.Parse(os.Args[1:]); err != nil {
@@ -88,13 +96,21 @@ func validateOptions(o options) error {
 		}
 		return fmt.Errorf("Error getting stat info for --registry directory: %w", err)
 	}
+	if o.releaseRepoPath != "" {
+		if _, err := os.Stat(o.releaseRepoPath); err != nil {
+			return fmt.Errorf("Error getting stat info for --release-repo directory: %w", err)
+		}
+	}
+	if o.resolvedCacheSize < 1 {
+		return errors.New("--resolved-config-cache-size must be positive")
+	}
 	if o.validateOnly && o.flatRegistry {
 		return errors.New("--validate-only and --flat-registry flags cannot be set simultaneously")
 	}
 	return o.instrumentationOptions.Validate(false)
 }
 
-func resolveConfig(configAgent agents.ConfigAgent, registryAgent agents.RegistryAgent) http.HandlerFunc {
+func resolveConfig(configAgent agents.ConfigAgent, registryAgent agents.RegistryAgent, resolved *resolvedConfigCache) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != "GET" {
 			w.WriteHeader(http.StatusNotImplemented)
@@ -107,15 +123,23 @@ func resolveConfig(configAgent agents.ConfigAgent, registryAgent agents.Registry
 		}
 		logger := logrus.WithFields(api.LogFieldsFor(metadata))
 
-		config, err := configAgent.GetMatchingConfig(metadata)
-		if err != nil {
+		var notFound bool
+		response, err := resolved.get(metadata, func() (*resolvedConfig, error) {
+			config, err := configAgent.GetMatchingConfig(metadata)
+			if err != nil {
+				notFound = true
+				return nil, err
+			}
+			return resolve(registryAgent, config)
+		})
+		if notFound {
 			metrics.RecordError("config not found", configresolverMetrics.ErrorRate)
 			w.WriteHeader(http.StatusNotFound)
 			fmt.Fprintf(w, "failed to get config: %v", err)
 			logger.WithError(err).Warning("failed to get config")
 			return
 		}
-		resolveAndRespond(registryAgent, config, w, logger)
+		respond(w, r, response, err, logger)
 	}
 }
 
@@ -140,13 +164,39 @@ func resolveLiteralConfig(registryAgent agents.RegistryAgent) http.HandlerFunc {
 			_, _ = w.Write([]byte("Could not parse request body as unresolved config."))
 			return
 		}
-		resolveAndRespond(registryAgent, unresolvedConfig, w, logger)
+		response, err := resolve(registryAgent, unresolvedConfig)
+		respond(w, r, response, err, logger)
 	}
 }
 
-func resolveAndRespond(registryAgent agents.RegistryAgent, config api.ReleaseBuildConfiguration, w http.ResponseWriter, logger *logrus.Entry) {
+// errMarshal marks failures to marshal a resolved config, which are not
+// caused by the request
+type errMarshal struct{ error }
+
+func resolve(registryAgent agents.RegistryAgent, config api.ReleaseBuildConfiguration) (*resolvedConfig, error) {
 	config, err := registryAgent.ResolveConfig(config)
 	if err != nil {
+		return nil, err
+	}
+	jsonConfig, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return nil, errMarshal{err}
+	}
+	return newResolvedConfig(jsonConfig), nil
+}
+
+// respond writes the resolved config, or only its ETag when the client
+// already has the current content
+func respond(w http.ResponseWriter, r *http.Request, response *resolvedConfig, err error, logger *logrus.Entry) {
+	var marshalErr errMarshal
+	switch {
+	case errors.As(err, &marshalErr):
+		metrics.RecordError("failed to marshal config", configresolverMetrics.ErrorRate)
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, "failed to marshal config to JSON: %v", marshalErr.error)
+		logger.WithError(marshalErr.error).Errorf("failed to marshal config to JSON")
+		return
+	case err != nil:
 		metrics.RecordError("failed to resolve config with registry", configresolverMetrics.ErrorRate)
 		w.WriteHeader(http.StatusBadRequest)
 		if _, writeErr := w.Write([]byte(fmt.Sprintf("failed to resolve config: %v", err))); writeErr != nil {
@@ -156,20 +206,93 @@ func resolveAndRespond(registryAgent agents.RegistryAgent, config api.ReleaseBui
 		logger.WithError(err).Warning("failed to resolve config with registry")
 		return
 	}
-	jsonConfig, err := json.MarshalIndent(config, "", "  ")
-	if err != nil {
-		metrics.RecordError("failed to marshal config", configresolverMetrics.ErrorRate)
-		w.WriteHeader(http.StatusInternalServerError)
-		fmt.Fprintf(w, "failed to marshal config to JSON: %v", err)
-		logger.WithError(err).Errorf("failed to marshal config to JSON")
+	w.Header().Set("ETag", response.etag)
+	if etagMatches(r, response.etag) {
+		w.WriteHeader(http.StatusNotModified)
 		return
 	}
 	w.WriteHeader(http.StatusOK)
-	if _, err := w.Write(jsonConfig); err != nil {
+	if _, err := w.Write(response.body); err != nil {
 		logrus.WithError(err).Error("Failed to write response")
 	}
 }
 
+const (
+	baseQuery = "base"
+	headQuery = "head"
+)
+
+// revisionResolver resolves configurations at revisions of the release repo
+type revisionResolver interface {
+	ResolvedConfig(revision string, metadata api.Metadata) (*api.ReleaseBuildConfiguration, error)
+}
+
+// configDiff responds with the unified diff between the resolved configuration
+// at the base and head revisions of the release repo, so the effective change
+// to the jobs can be reviewed instead of the change to the raw configuration
+func configDiff(resolver revisionResolver) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		metadata, err := webreg.MetadataFromQuery(w, r)
+		if err != nil {
+			metrics.RecordError("invalid query", configresolverMetrics.ErrorRate)
+			return
+		}
+		logger := logrus.WithFields(api.LogFieldsFor(metadata))
+		var sides []string
+		for _, query := range []string{baseQuery, headQuery} {
+			revision := r.URL.Query().Get(query)
+			if revision == "" {
+				metrics.RecordError("invalid query", configresolverMetrics.ErrorRate)
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprintf(w, "%s query missing or incorrect", query)
+				return
+			}
+			resolved, err := resolver.ResolvedConfig(revision, metadata)
+			if err != nil {
+				status := http.StatusBadRequest
+				if errors.Is(err, config.ErrUnknownRevision) {
+					status = http.StatusNotFound
+				}
+				metrics.RecordError("failed to resolve config at revision", configresolverMetrics.ErrorRate)
+				w.WriteHeader(status)
+				fmt.Fprintf(w, "failed to resolve config at %s: %v", revision, err)
+				logger.WithError(err).Warning("failed to resolve config at revision")
+				return
+			}
+			var raw []byte
+			if resolved != nil {
+				if raw, err = yaml.Marshal(resolved); err != nil {
+					metrics.RecordError("failed to marshal config", configresolverMetrics.ErrorRate)
+					w.WriteHeader(http.StatusInternalServerError)
+					fmt.Fprintf(w, "failed to marshal config to YAML: %v", err)
+					logger.WithError(err).Error("failed to marshal config to YAML")
+					return
+				}
+			}
+			sides = append(sides, string(raw))
+		}
+		diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        difflib.SplitLines(sides[0]),
+			B:        difflib.SplitLines(sides[1]),
+			FromFile: fmt.Sprintf("%s@%s", metadata.Basename(), r.URL.Query().Get(baseQuery)),
+			ToFile:   fmt.Sprintf("%s@%s", metadata.Basename(), r.URL.Query().Get(headQuery)),
+			Context:  3,
+		})
+		if err != nil {
+			metrics.RecordError("failed to diff configs", configresolverMetrics.ErrorRate)
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprintf(w, "failed to diff configs: %v", err)
+			logger.WithError(err).Error("failed to diff configs")
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		if _, err := w.Write([]byte(diff)); err != nil {
+			logrus.WithError(err).Error("Failed to write response")
+		}
+	}
+}
+
 func getConfigGeneration(agent agents.ConfigAgent) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -205,6 +328,9 @@ func main() {
 	if err != nil {
 		logrus.Fatalf("Failed to get config agent: %v", err)
 	}
+	if err := configAgent.AddIndex(release.PromotionIndexName, release.PromotionIndexKeys); err != nil {
+		logrus.Fatalf("Failed to add promotion index to config agent: %v", err)
+	}
 
 	registryAgent, err := agents.NewRegistryAgent(o.registryPath, agents.WithRegistryMetrics(configresolverMetrics.ErrorRate), agents.WithRegistryFlat(o.flatRegistry))
 	if err != nil {
@@ -221,6 +347,11 @@ func main() {
 		l("resolve"),
 		l("configGeneration"),
 		l("registryGeneration"),
+		l("configDiff"),
+		l("query",
+			l("config"),
+			l("index"),
+		),
 	))
 
 	uisimplifier := simplifypath.NewSimplifier(l("", // shadow element mimicing the root
@@ -241,10 +372,14 @@ func main() {
 	uihandler := metrics.TraceHandler(uisimplifier, configresolverMetrics.HTTPRequestDuration, configresolverMetrics.HTTPResponseSize)
 	// add handler func for incorrect paths as well; can help with identifying errors/404s caused by incorrect paths
 	http.HandleFunc("/", handler(http.HandlerFunc(http.NotFound)).ServeHTTP)
-	http.HandleFunc("/config", handler(resolveConfig(configAgent, registryAgent)).ServeHTTP)
+	http.HandleFunc("/config", handler(resolveConfig(configAgent, registryAgent, newResolvedConfigCache(configAgent, registryAgent, o.resolvedCacheSize))).ServeHTTP)
 	http.HandleFunc("/resolve", handler(resolveLiteralConfig(registryAgent)).ServeHTTP)
 	http.HandleFunc("/configGeneration", handler(getConfigGeneration(configAgent)).ServeHTTP)
 	http.HandleFunc("/registryGeneration", handler(getRegistryGeneration(registryAgent)).ServeHTTP)
+	if o.releaseRepoPath != "" {
+		http.HandleFunc("/configDiff", handler(configDiff(config.NewRevisionResolver(o.releaseRepoPath))).ServeHTTP)
+	}
+	http.Handle(agents.QueryPathPrefix, handler(agents.NewConfigQueryHandler(configAgent)))
 	interrupts.ListenAndServe(&http.Server{Addr: ":" + strconv.Itoa(o.port)}, o.gracePeriod)
 	uiServer := &http.Server{
 		Addr:    ":" + strconv.Itoa(o.uiPort),
@@ -26,6 +26,8 @@ type options struct {
 	toDir         string
 	toReleaseRepo bool
 
+	policyPath string
+
 	help bool
 }
 
@@ -38,6 +40,8 @@ func bindOptions(flag *flag.FlagSet) *options {
 	flag.StringVar(&opt.toDir, "to-dir", "", "Path to a directory with a directory structure holding Prow job configuration files for multiple components")
 	flag.BoolVar(&opt.toReleaseRepo, "to-release-repo", false, "If set, it behaves like --to-dir=$GOPATH/src/github.com/openshift/release/ci-operator/jobs")
 
+	flag.StringVar(&opt.policyPath, "policy", "", "Path to a file with the policy that sets defaults for the generated jobs, like their decoration or cluster")
+
 	flag.BoolVar(&opt.help, "h", false, "Show help for ci-operator-prowgen")
 
 	return opt
@@ -94,7 +98,9 @@ func readProwgenConfig(path string) (*config.Prowgen, error) {
 // appropriate location, and either stored a pointer to the parsed config if if was
 // successfully read, or stored `nil` when the prowgen config could not be read (usually
 // because the drop-in is not there).
-func generateJobsToDir(dir string) func(configSpec *cioperatorapi.ReleaseBuildConfiguration, info *config.Info) error {
+//
+// The defaults of the policy, if any, are applied to the generated jobs.
+func generateJobsToDir(dir string, policy *prowgen.Policy) func(configSpec *cioperatorapi.ReleaseBuildConfiguration, info *config.Info) error {
 	// Return a closure so the cache is shared among callback calls
 	cache := map[string]*config.Prowgen{}
 	return func(configSpec *cioperatorapi.ReleaseBuildConfiguration, info *config.Info) error {
@@ -125,7 +131,9 @@ func generateJobsToDir(dir string) func(configSpec *cioperatorapi.ReleaseBuildCo
 			pInfo.Config = *repoConfig
 		}
 
-		return jc.WriteToDir(dir, info.Org, info.Repo, prowgen.GenerateJobs(configSpec, pInfo))
+		jobConfig := prowgen.GenerateJobs(configSpec, pInfo)
+		policy.Apply(pInfo, jobConfig)
+		return jc.WriteToDir(dir, info.Org, info.Repo, jobConfig)
 	}
 }
 
@@ -179,7 +187,14 @@ func main() {
 	if len(args) == 0 {
 		args = append(args, "")
 	}
-	genJobs := generateJobsToDir(opt.toDir)
+	var policy *prowgen.Policy
+	if opt.policyPath != "" {
+		var err error
+		if policy, err = prowgen.LoadPolicy(opt.policyPath); err != nil {
+			logrus.WithError(err).Fatal("Failed to load the policy")
+		}
+	}
+	genJobs := generateJobsToDir(opt.toDir, policy)
 	for _, subDir := range args {
 		if err := config.OperateOnCIOperatorConfigSubdir(opt.fromDir, subDir, genJobs); err != nil {
 			fields := logrus.Fields{"target": opt.toDir, "source": opt.fromDir, "subdir": subDir}
@@ -187,13 +187,21 @@ func main() {
 	}
 	// "i just doin't want spam"
 	klog.LogToStderr(false)
-	logrus.Infof("%s version %s", version.Name, version.Version)
 	flagSet := flag.NewFlagSet("", flag.ExitOnError)
 	opt := bindOptions(flagSet)
 	opt.censor = censor
 	if err := flagSet.Parse(os.Args[1:]); err != nil {
 		logrus.WithError(err).Fatal("failed to parse flags")
 	}
+	if opt.capabilities {
+		// the output is meant to be consumed by tools, so nothing else may be printed
+		if err := printCapabilities(os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to print capabilities: %v\n", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	logrus.Infof("%s version %s", version.Name, version.Version)
 	if opt.verbose {
 		fs := flag.NewFlagSet("", flag.ExitOnError)
 		klog.InitFlags(fs)
@@ -236,7 +244,7 @@ func main() {
 
 	if err := opt.Complete(); err != nil {
 		logrus.WithError(err).Error("Failed to load arguments.")
-		opt.Report(results.ForReason("loading_args").ForError(err))
+		opt.Report(results.ForReason("loading_args").WithCategory(results.CategoryConfig).ForError(err))
 		os.Exit(1)
 	}
 
@@ -262,6 +270,15 @@ func main() {
 	opt.Report()
 }
 
+// printCapabilities writes the capabilities of this binary as JSON
+func printCapabilities(w io.Writer) error {
+	capabilities := api.CurrentCapabilities()
+	capabilities.Version = version.Version
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(capabilities)
+}
+
 // setupLogger sets up logrus to print all logs to a file and user-friendly logs to stdout
 func setupLogger() (*secrets.DynamicCensor, io.Closer, error) {
 	logrus.SetLevel(logrus.TraceLevel)
@@ -346,9 +363,10 @@ type options struct {
 	targets stringSlice
 	promote bool
 
-	verbose bool
-	help    bool
-	print   bool
+	verbose      bool
+	help         bool
+	print        bool
+	capabilities bool
 
 	writeParams string
 	artifactDir string
@@ -361,6 +379,7 @@ type options struct {
 	idleCleanupDurationSet bool
 	cleanupDuration        time.Duration
 	cleanupDurationSet     bool
+	keepOnFailureDuration  time.Duration
 
 	inputHash                  string
 	secrets                    []*coreapi.Secret
@@ -399,11 +418,20 @@ type options struct {
 	cloneAuthConfig *steps.CloneAuthConfig
 
 	resultsOptions results.Options
+	sinkOptions    results.SinkOptions
 
 	censor *secrets.DynamicCensor
 
 	hiveKubeconfigPath string
 	hiveKubeconfig     *rest.Config
+
+	pushgatewayAddress string
+	pushgatewayJob     string
+
+	resume bool
+	local  bool
+
+	nodeArchitecture string
 }
 
 func bindOptions(flag *flag.FlagSet) *options {
@@ -426,6 +454,7 @@ func bindOptions(flag *flag.FlagSet) *options {
 	flag.StringVar(&opt.unresolvedConfigPath, "unresolved-config", "", "The configuration file, before resolution. If not specified the UNRESOLVED_CONFIG environment variable will be used, if set.")
 	flag.Var(&opt.targets, "target", "One or more targets in the configuration to build. Only steps that are required for this target will be run.")
 	flag.BoolVar(&opt.print, "print-graph", opt.print, "Print a directed graph of the build steps and exit. Intended for use with the golang digraph utility.")
+	flag.BoolVar(&opt.capabilities, "capabilities", false, "Print the step types, configuration fields and behaviors this binary supports as JSON and exit.")
 
 	// add to the graph of things we run or create
 	flag.Var(&opt.templatePaths, "template", "A set of paths to optional templates to add as stages to this job. Each template is expected to contain at least one restart=Never pod. Parameters are filled from environment or from the automatic parameters generated by the operator.")
@@ -464,12 +493,21 @@ func bindOptions(flag *flag.FlagSet) *options {
 	flag.StringVar(&opt.uploadSecretPath, "gcs-upload-secret", "", "GCS credentials used to upload logs and artifacts.")
 
 	flag.StringVar(&opt.hiveKubeconfigPath, "hive-kubeconfig", "", "Path to the kubeconfig file to use for requests to Hive.")
+	flag.StringVar(&opt.pushgatewayAddress, "metrics-pushgateway", "", "Address of the aggregating Prometheus Pushgateway the duration, retries and outcome of every step are pushed to. The gateway must add up the pushes of all builds of a job, like prom-aggregation-gateway does. Metrics are not pushed if unset.")
+	flag.BoolVar(&opt.local, "local", false, "Run builds with podman on this machine instead of on the cluster and run tests against the cluster of the local kubeconfig, like a CodeReady Containers cluster. Images are pulled from and pushed to the public route of the registry of the cluster, which podman must be logged into.")
+	flag.BoolVar(&opt.resume, "resume", false, "Record the steps that complete in the namespace and skip steps that completed in an earlier run whose images still exist.")
+	flag.StringVar(&opt.pushgatewayJob, "metrics-pushgateway-job", "ci-operator", "Job the metrics of the steps are grouped under on the Pushgateway.")
+	flag.StringVar(&opt.nodeArchitecture, "node-architecture", "", "Schedule the builds and the test pods on nodes of this architecture, e.g. arm64. Images built for multiple architectures are still built on nodes of each of them. If unset, the scheduler picks the nodes.")
 
 	opt.resultsOptions.Bind(flag)
+	opt.sinkOptions.Bind(flag)
 	return opt
 }
 
 func (o *options) Complete() error {
+	if err := o.sinkOptions.Validate(); err != nil {
+		return err
+	}
 	jobSpec, err := api.ResolveSpecFromEnv()
 	if err != nil {
 		if len(o.gitRef) == 0 {
@@ -499,6 +537,12 @@ func (o *options) Complete() error {
 
 	info := o.getResolverInfo(jobSpec)
 
+	if o.nodeArchitecture != "" {
+		if err := validation.ValidateArchitecture("--node-architecture", api.ReleaseArchitecture(o.nodeArchitecture)); err != nil {
+			return err
+		}
+	}
+
 	if o.unresolvedConfigPath != "" && o.configSpecPath != "" {
 		return errors.New("cannot set --config and --unresolved-config at the same time")
 	}
@@ -508,7 +552,7 @@ func (o *options) Complete() error {
 
 	config, err := load.Config(o.configSpecPath, o.unresolvedConfigPath, o.registryPath, info)
 	if err != nil {
-		return results.ForReason("loading_config").WithError(err).Errorf("failed to load configuration: %v", err)
+		return results.ForReason("loading_config").WithCategory(results.CategoryConfig).WithError(err).Errorf("failed to load configuration: %v", err)
 	}
 	if len(o.gitRef) != 0 && config.CanonicalGoRepository != nil {
 		o.jobSpec.Refs.PathAlias = *config.CanonicalGoRepository
@@ -516,8 +560,9 @@ func (o *options) Complete() error {
 	o.configSpec = config
 	o.jobSpec.Metadata = config.Metadata
 	if err := validation.IsValidResolvedConfiguration(o.configSpec); err != nil {
-		return results.ForReason("validating_config").ForError(err)
+		return results.ForReason("validating_config").WithCategory(results.CategoryConfig).ForError(err)
 	}
+	o.applyNamespaceTTL(namespaceTTLFor(o.configSpec.Tests, o.targets.values))
 
 	if o.verbose {
 		config, _ := yaml.Marshal(o.configSpec)
@@ -605,7 +650,11 @@ func (o *options) Complete() error {
 		o.templates = append(o.templates, template)
 	}
 
-	clusterConfig, err := util.LoadClusterConfig()
+	loadClusterConfig := util.LoadClusterConfig
+	if o.local {
+		loadClusterConfig = util.LoadLocalClusterConfig
+	}
+	clusterConfig, err := loadClusterConfig()
 	if err != nil {
 		return fmt.Errorf("failed to load cluster config: %w", err)
 	}
@@ -668,6 +717,7 @@ func (o *options) Report(errs ...error) {
 	if len(errs) > 0 {
 		o.writeFailingJUnit(errs)
 	}
+	o.writeResult(errs)
 
 	reporter, loadErr := o.resultsOptions.Reporter(o.jobSpec, o.consoleHost)
 	if loadErr != nil {
@@ -700,9 +750,9 @@ func (o *options) Run() []error {
 		leaseClient = &o.leaseClient
 	}
 	// load the graph from the configuration
-	buildSteps, postSteps, err := defaults.FromConfig(ctx, o.configSpec, o.jobSpec, o.templates, o.writeParams, o.promote, o.clusterConfig, leaseClient, o.targets.values, o.cloneAuthConfig, o.pullSecret, o.pushSecret, o.censor, o.hiveKubeconfig)
+	buildSteps, postSteps, err := defaults.FromConfig(ctx, o.configSpec, o.jobSpec, o.templates, o.writeParams, o.promote, o.clusterConfig, leaseClient, o.targets.values, o.cloneAuthConfig, o.pullSecret, o.pushSecret, o.censor, o.hiveKubeconfig, o.local, api.ReleaseArchitecture(o.nodeArchitecture))
 	if err != nil {
-		return []error{results.ForReason("defaulting_config").WithError(err).Errorf("failed to generate steps from config: %v", err)}
+		return []error{results.ForReason("defaulting_config").WithCategory(results.CategoryConfig).WithError(err).Errorf("failed to generate steps from config: %v", err)}
 	}
 	// Before we create the namespace, we need to ensure all inputs to the graph
 	// have been resolved. We must run this step before we resolve the partial
@@ -751,6 +801,11 @@ func (o *options) Run() []error {
 	if err := o.initializeNamespace(); err != nil {
 		return []error{results.ForReason("initializing_namespace").WithError(err).Errorf("could not initialize namespace: %v", err)}
 	}
+	if o.resume {
+		if err := o.resumeSteps(ctx, nodes); err != nil {
+			return []error{results.ForReason("resuming_steps").WithError(err).Errorf("could not resume steps: %v", err)}
+		}
+	}
 
 	return interrupt.New(handler, o.saveNamespaceArtifacts).Run(func() []error {
 		if leaseClient != nil {
@@ -774,17 +829,30 @@ func (o *options) Run() []error {
 		runtimeObject := &coreapi.ObjectReference{Namespace: o.namespace}
 		eventRecorder.Event(runtimeObject, coreapi.EventTypeNormal, "CiJobStarted", eventJobDescription(o.jobSpec, o.namespace))
 		// execute the graph
-		suites, graphDetails, errs := steps.Run(ctx, nodes)
+		metrics := steps.NewStepMetrics()
+		suites, graphDetails, errs := steps.Run(ctx, nodes, metrics)
+		o.pushStepMetrics(metrics)
+		o.publishStepResults(metrics)
 		if err := o.writeJUnit(suites, "operator"); err != nil {
 			logrus.WithError(err).Warn("Unable to write JUnit result.")
 		}
 		graph.MergeFrom(graphDetails...)
+		// merge the results of the steps whether they passed or not
+		if artifactDir, set := api.Artifacts(); set {
+			details, err := runStep(ctx, steps.JUnitAggregationStep(artifactDir))
+			graph.MergeFrom(details)
+			if err != nil {
+				logrus.WithError(err).Warn("Unable to aggregate the JUnit results of the steps.")
+			}
+		}
 		// Rewrite the Metadata JSON to catch custom metadata if it has been generated by the job
 		if err := o.writeMetadataJSON(); err != nil {
 			logrus.WithError(err).Warn("Unable to update metadata.json for build")
 		}
 		if len(errs) > 0 {
 			eventRecorder.Event(runtimeObject, coreapi.EventTypeWarning, "CiJobFailed", eventJobDescription(o.jobSpec, o.namespace))
+			o.gatherFailureBundle()
+			o.retainNamespaceOnFailure()
 			var wrapped []error
 			for _, err := range errs {
 				wrapped = append(wrapped, &errWroteJUnit{wrapped: results.ForReason("executing_graph").WithError(err).Errorf("could not run steps: %v", err)})
@@ -798,6 +866,8 @@ func (o *options) Run() []error {
 			if err != nil {
 				eventRecorder.Event(runtimeObject, coreapi.EventTypeWarning, "PostStepFailed",
 					fmt.Sprintf("Post step %s failed while %s", step.Name(), eventJobDescription(o.jobSpec, o.namespace)))
+				o.gatherFailureBundle()
+				o.retainNamespaceOnFailure()
 				return []error{results.ForReason("executing_post").WithError(err).Errorf("could not run post step %s: %v", step.Name(), err)}
 			}
 		}
@@ -807,6 +877,57 @@ func (o *options) Run() []error {
 	})
 }
 
+// resumeSteps lets the steps of the graph that create pipeline images skip
+// their work if they completed in an earlier run in the namespace.
+func (o *options) resumeSteps(ctx context.Context, nodes []*api.StepNode) error {
+	client, err := ctrlruntimeclient.New(o.clusterConfig, ctrlruntimeclient.Options{})
+	if err != nil {
+		return fmt.Errorf("could not get a client for the cluster config: %w", err)
+	}
+	manifest, err := steps.LoadResumeManifest(ctx, client, o.namespace)
+	if err != nil {
+		return err
+	}
+	api.IterateAllEdges(nodes, func(node *api.StepNode) {
+		node.Step = steps.ResumableStep(manifest, node.Step)
+	})
+	return nil
+}
+
+// pushStepMetrics pushes the metrics of the steps to the Pushgateway, grouped
+// by the job that ran them. Grouping by build would leave a group behind for
+// every build, so the gateway aggregates the pushes of all builds of a job
+// instead. Failures to push do not fail the job.
+func (o *options) pushStepMetrics(metrics *steps.StepMetrics) {
+	if o.pushgatewayAddress == "" {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	grouping := map[string]string{"prow_job": o.jobSpec.Job}
+	if err := metrics.Push(ctx, o.pushgatewayAddress, o.pushgatewayJob, grouping); err != nil {
+		logrus.WithError(err).Warn("Unable to push the step metrics.")
+	}
+}
+
+// publishStepResults publishes the results of the steps to the configured
+// sink. Failures to publish do not fail the job.
+func (o *options) publishStepResults(metrics *steps.StepMetrics) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	sink, err := o.sinkOptions.Sink(ctx)
+	if err != nil {
+		logrus.WithError(err).Warn("Unable to create the sink for the step results.")
+		return
+	}
+	if sink == nil {
+		return
+	}
+	if err := sink.Publish(ctx, results.StepRecords(o.jobSpec, o.consoleHost, metrics.Results())); err != nil {
+		logrus.WithError(err).Warn("Unable to publish the step results.")
+	}
+}
+
 // runStep mostly duplicates steps.runStep. The latter uses an *api.StepNode though and we only have an api.Step for the PostSteps
 // so we can not re-use it.
 func runStep(ctx context.Context, step api.Step) (api.CIOperatorStepDetails, error) {
@@ -1026,6 +1147,12 @@ func (o *options) initializeNamespace() error {
 		if ns.Annotations == nil {
 			ns.Annotations = make(map[string]string)
 		}
+		// a namespace retained for a failed test is not retained anymore when it is reused
+		if raw, ok := ns.Annotations[api.ReleaseAnnotationSoftDelete]; ok {
+			if retainUntil, err := time.Parse(time.RFC3339, raw); err == nil && retainUntil.After(time.Now()) {
+				delete(ns.Annotations, api.ReleaseAnnotationSoftDelete)
+			}
+		}
 		for key, value := range annotationUpdates {
 			// allow specific annotations to be skipped if they are already set and the user didn't ask
 			switch key {
@@ -1427,6 +1554,19 @@ func (o *options) writeFailingJUnit(errs []error) {
 	}
 }
 
+// writeResult writes the machine-readable outcome of the run for reporting
+// dashboards, so they do not need to parse the reasons out of the logs
+func (o *options) writeResult(errs []error) {
+	data, err := json.MarshalIndent(results.ForErrors(errs...), "", "  ")
+	if err != nil {
+		logrus.WithError(err).Trace("Unable to marshal the result")
+		return
+	}
+	if err := api.SaveArtifact(o.censor, results.ResultFile, data); err != nil {
+		logrus.WithError(err).Trace("Unable to write the result artifact")
+	}
+}
+
 func (o *options) writeJUnit(suites *junit.TestSuites, name string) error {
 	if suites == nil {
 		return nil
@@ -1505,6 +1645,98 @@ func (o *options) saveNamespaceArtifacts() {
 	}
 }
 
+// namespaceTTLFor returns the namespace TTL configured for the targeted tests.
+// When several of them configure one, the longest durations are used.
+func namespaceTTLFor(tests []api.TestStepConfiguration, targets []string) *api.NamespaceTTL {
+	targeted := sets.NewString(targets...)
+	longest := func(a, b *prowapi.Duration) *prowapi.Duration {
+		if a == nil || (b != nil && b.Duration > a.Duration) {
+			return b
+		}
+		return a
+	}
+	var ttl *api.NamespaceTTL
+	for _, test := range tests {
+		if test.NamespaceTTL == nil || !targeted.Has(test.As) {
+			continue
+		}
+		if ttl == nil {
+			ttl = &api.NamespaceTTL{}
+		}
+		ttl.Hard = longest(ttl.Hard, test.NamespaceTTL.Hard)
+		ttl.Idle = longest(ttl.Idle, test.NamespaceTTL.Idle)
+		ttl.KeepOnFailure = longest(ttl.KeepOnFailure, test.NamespaceTTL.KeepOnFailure)
+	}
+	return ttl
+}
+
+// applyNamespaceTTL overrides the default TTLs of the namespace with the ones
+// configured for the tests. TTLs passed explicitly as flags take precedence.
+func (o *options) applyNamespaceTTL(ttl *api.NamespaceTTL) {
+	if ttl == nil {
+		return
+	}
+	if ttl.Hard != nil && !o.cleanupDurationSet {
+		o.cleanupDuration = ttl.Hard.Duration
+		o.cleanupDurationSet = true
+	}
+	if ttl.Idle != nil && !o.idleCleanupDurationSet {
+		o.idleCleanupDuration = ttl.Idle.Duration
+		o.idleCleanupDurationSet = true
+	}
+	if ttl.KeepOnFailure != nil {
+		o.keepOnFailureDuration = ttl.KeepOnFailure.Duration
+	}
+}
+
+// retainNamespaceOnFailure is a best effort attempt to retain the namespace
+// of a failed test for debugging. It sets the soft-delete annotation to a time
+// in the future, until which the namespace reaper ignores the TTLs.
+func (o *options) retainNamespaceOnFailure() {
+	if o.keepOnFailureDuration == 0 {
+		return
+	}
+	client, err := ctrlruntimeclient.New(o.clusterConfig, ctrlruntimeclient.Options{})
+	if err != nil {
+		logrus.WithError(err).Warn("Could not create client to retain the namespace.")
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	retainUntil := time.Now().Add(o.keepOnFailureDuration)
+	if err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		ns := &coreapi.Namespace{}
+		if err := client.Get(ctx, ctrlruntimeclient.ObjectKey{Name: o.namespace}, ns); err != nil {
+			return err
+		}
+		if ns.Annotations == nil {
+			ns.Annotations = map[string]string{}
+		}
+		ns.Annotations[api.ReleaseAnnotationSoftDelete] = retainUntil.Format(time.RFC3339)
+		return client.Update(ctx, ns)
+	}); err != nil {
+		logrus.WithError(err).Warn("Failed to retain the namespace of the failed test.")
+		return
+	}
+	logrus.Infof("The namespace %s of the failed test is retained until %s.", o.namespace, retainUntil.Format(time.RFC3339))
+}
+
+// gatherFailureBundle is a best effort attempt to save a summary of the state of the namespace
+// that helps to debug a failed job. It does not use the context of the job, as that may already
+// be cancelled.
+func (o *options) gatherFailureBundle() {
+	client, err := ctrlruntimeclient.New(o.clusterConfig, ctrlruntimeclient.Options{})
+	if err != nil {
+		logrus.WithError(err).Warn("Could not create client to gather the failure bundle.")
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if err := steps.GatherFailureBundle(ctx, client, o.namespace, o.censor); err != nil {
+		logrus.WithError(err).Warn("Failed to gather the complete failure bundle.")
+	}
+}
+
 func loadLeaseCredentials(leaseServerCredentialsFile string) (string, func() []byte, error) {
 	sa := &secret.Agent{}
 	if err := sa.Start([]string{leaseServerCredentialsFile}); err != nil {
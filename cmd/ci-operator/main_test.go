@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -10,6 +11,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 
@@ -377,6 +379,22 @@ func TestGetResolverInfo(t *testing.T) {
 	}
 }
 
+func TestPrintCapabilities(t *testing.T) {
+	var out bytes.Buffer
+	if err := printCapabilities(&out); err != nil {
+		t.Fatalf("failed to print capabilities: %v", err)
+	}
+	var capabilities api.Capabilities
+	if err := json.Unmarshal(out.Bytes(), &capabilities); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+	expected := api.CurrentCapabilities()
+	expected.Version = capabilities.Version
+	if diff := cmp.Diff(expected, capabilities); diff != "" {
+		t.Errorf("unexpected capabilities: %s", diff)
+	}
+}
+
 func TestErrWroteJUnit(t *testing.T) {
 	// this simulates the error chain bubbling up to the top of the call chain
 	rootCause := errors.New("failure")
@@ -416,10 +434,10 @@ func TestBuildPartialGraph(t *testing.T) {
 						},
 						To: api.PipelineImageStreamTagReference("oc-bin-image"),
 					},
-					&api.ReleaseBuildConfiguration{}, api.ResourceConfiguration{}, nil, nil, nil, nil,
+					&api.ReleaseBuildConfiguration{}, api.ResourceConfiguration{}, nil, nil, nil, nil, nil,
 				),
-				steps.OutputImageTagStep(api.OutputImageTagStepConfiguration{From: api.PipelineImageStreamTagReference("oc-bin-image")}, nil, nil),
-				steps.ImagesReadyStep(steps.OutputImageTagStep(api.OutputImageTagStepConfiguration{From: api.PipelineImageStreamTagReference("oc-bin-image")}, nil, nil).Creates()),
+				steps.OutputImageTagStep(api.OutputImageTagStepConfiguration{From: api.PipelineImageStreamTagReference("oc-bin-image")}, nil, nil, nil),
+				steps.ImagesReadyStep(steps.OutputImageTagStep(api.OutputImageTagStepConfiguration{From: api.PipelineImageStreamTagReference("oc-bin-image")}, nil, nil, nil).Creates()),
 			},
 			targetName:       "[images]",
 			expectedErrorMsg: "steps are missing dependencies",
@@ -543,3 +561,66 @@ func TestExcludeContextCancelledErrors(t *testing.T) {
 		}
 	}
 }
+
+func TestNamespaceTTL(t *testing.T) {
+	duration := func(d time.Duration) *prowapi.Duration {
+		return &prowapi.Duration{Duration: d}
+	}
+	tests := []api.TestStepConfiguration{
+		{As: "unit"},
+		{As: "e2e", NamespaceTTL: &api.NamespaceTTL{Hard: duration(24 * time.Hour), KeepOnFailure: duration(48 * time.Hour)}},
+		{As: "e2e-upgrade", NamespaceTTL: &api.NamespaceTTL{Hard: duration(6 * time.Hour), Idle: duration(2 * time.Hour), KeepOnFailure: duration(72 * time.Hour)}},
+	}
+	type durations struct {
+		Idle, Hard, KeepOnFailure time.Duration
+		IdleSet, HardSet          bool
+	}
+	testCases := []struct {
+		name     string
+		targets  []string
+		idleFlag time.Duration
+		expected durations
+	}{
+		{
+			name:     "no test with a namespace TTL is targeted",
+			targets:  []string{"unit"},
+			expected: durations{Idle: time.Hour, Hard: 12 * time.Hour},
+		},
+		{
+			name:     "namespace TTL of the targeted test is used",
+			targets:  []string{"e2e"},
+			expected: durations{Idle: time.Hour, Hard: 24 * time.Hour, HardSet: true, KeepOnFailure: 48 * time.Hour},
+		},
+		{
+			name:     "longest durations of the targeted tests are used",
+			targets:  []string{"e2e", "e2e-upgrade"},
+			expected: durations{Idle: 2 * time.Hour, IdleSet: true, Hard: 24 * time.Hour, HardSet: true, KeepOnFailure: 72 * time.Hour},
+		},
+		{
+			name:     "flags take precedence",
+			targets:  []string{"e2e-upgrade"},
+			idleFlag: 10 * time.Minute,
+			expected: durations{Idle: 10 * time.Minute, IdleSet: true, Hard: 6 * time.Hour, HardSet: true, KeepOnFailure: 72 * time.Hour},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			o := options{idleCleanupDuration: time.Hour, cleanupDuration: 12 * time.Hour}
+			if tc.idleFlag != 0 {
+				o.idleCleanupDuration = tc.idleFlag
+				o.idleCleanupDurationSet = true
+			}
+			o.applyNamespaceTTL(namespaceTTLFor(tests, tc.targets))
+			actual := durations{
+				Idle:          o.idleCleanupDuration,
+				IdleSet:       o.idleCleanupDurationSet,
+				Hard:          o.cleanupDuration,
+				HardSet:       o.cleanupDurationSet,
+				KeepOnFailure: o.keepOnFailureDuration,
+			}
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Errorf("got incorrect durations: %s", diff)
+			}
+		})
+	}
+}
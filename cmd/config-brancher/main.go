@@ -18,7 +18,9 @@ import (
 type options struct {
 	promotion.FutureOptions
 
-	BumpRelease string
+	BumpRelease       string
+	OverridesPath     string
+	SkippedReportPath string
 }
 
 func (o *options) Validate() error {
@@ -32,6 +34,8 @@ func (o *options) Validate() error {
 
 func (o *options) Bind(fs *flag.FlagSet) {
 	fs.StringVar(&o.BumpRelease, "bump-release", "", "Bump the dev config to this release and manage mirroring.")
+	fs.StringVar(&o.OverridesPath, "overrides", "", "Path to a file with per-repository overrides, e.g. to skip repositories or to name their release branches differently.")
+	fs.StringVar(&o.SkippedReportPath, "skipped-report", "", "Path to write a report of the configurations that were intentionally not branched to.")
 	o.FutureOptions.Bind(fs)
 }
 
@@ -62,15 +66,39 @@ func gatherOptions() options {
 //    the `--bump` flag, enabling the promotion in the release branch that used to match
 //    the dev branch version and disabling promotion in the release branch that now matches
 //    the dev branch version.
+//
+// Variants of a selected configuration are branched along with it, even when they do not
+// promote themselves. Repositories can be skipped or use their own naming for release
+// branches with `--overrides`.
 func main() {
 	o := gatherOptions()
 	if err := o.Validate(); err != nil {
 		logrus.Fatalf("Invalid options: %v", err)
 	}
+	repoOverrides, err := loadOverrides(o.OverridesPath)
+	if err != nil {
+		logrus.WithError(err).Fatal("Could not load overrides.")
+	}
 
 	var toCommit []config.DataWithInfo
-	if err := o.OperateOnCIOperatorConfigDir(o.ConfigDir, func(configuration *api.ReleaseBuildConfiguration, info *config.Info) error {
-		for _, output := range generateBranchedConfigs(o.CurrentRelease, o.BumpRelease, o.FutureReleases.Strings(), config.DataWithInfo{Configuration: *configuration, Info: *info}) {
+	var skipped skipReport
+	// branched holds the org/repo@branch of the configurations that were branched
+	branched := sets.NewString()
+	// handled holds the configuration files that were already handled
+	handled := sets.NewString()
+	branch := func(configuration *api.ReleaseBuildConfiguration, info *config.Info) error {
+		handled.Insert(info.RelativePath())
+		override := repoOverrides.forRepo(info.Org, info.Repo)
+		if override.Skip {
+			skipped.add(info.RelativePath(), "the repository is skipped by an override")
+			return nil
+		}
+		if _, err := override.releaseBranch(o.CurrentRelease, o.CurrentRelease, info.Branch); err != nil {
+			skipped.add(info.RelativePath(), err.Error())
+			return nil
+		}
+		branched.Insert(branchKey(info))
+		for _, output := range generateBranchedConfigs(o.CurrentRelease, o.BumpRelease, o.FutureReleases.Strings(), override, config.DataWithInfo{Configuration: *configuration, Info: *info}) {
 			if !o.Confirm {
 				output.Logger().Info("Would commit new file.")
 				continue
@@ -81,9 +109,27 @@ func main() {
 		}
 
 		return nil
-	}); err != nil {
+	}
+	if err := o.OperateOnCIOperatorConfigDir(o.ConfigDir, branch); err != nil {
 		logrus.WithError(err).Fatal("Could not branch configurations.")
 	}
+	if err := o.ConfirmableOptions.OperateOnCIOperatorConfigDir(o.ConfigDir, func(configuration *api.ReleaseBuildConfiguration, info *config.Info) error {
+		if info.Variant == "" || handled.Has(info.RelativePath()) || !branched.Has(branchKey(info)) {
+			return nil
+		}
+		return branch(configuration, info)
+	}); err != nil {
+		logrus.WithError(err).Fatal("Could not branch configuration variants.")
+	}
+
+	for _, entry := range skipped {
+		logrus.WithField("config", entry.Config).Infof("Skipped configuration: %s", entry.Reason)
+	}
+	if o.SkippedReportPath != "" {
+		if err := skipped.write(o.SkippedReportPath); err != nil {
+			logrus.WithError(err).Fatal("Could not write the report of skipped configurations.")
+		}
+	}
 
 	var failed bool
 	for _, output := range toCommit {
@@ -96,7 +142,12 @@ func main() {
 	}
 }
 
-func generateBranchedConfigs(currentRelease, bumpRelease string, futureReleases []string, input config.DataWithInfo) []config.DataWithInfo {
+// branchKey identifies the branch of a configuration regardless of its variant
+func branchKey(info *config.Info) string {
+	return fmt.Sprintf("%s/%s@%s", info.Org, info.Repo, info.Branch)
+}
+
+func generateBranchedConfigs(currentRelease, bumpRelease string, futureReleases []string, override repoOverride, input config.DataWithInfo) []config.DataWithInfo {
 	var output []config.DataWithInfo
 	input.Logger().Info("Branching configuration.")
 	currentConfig := input.Configuration
@@ -112,7 +163,7 @@ func generateBranchedConfigs(currentRelease, bumpRelease string, futureReleases
 	}
 
 	for _, futureRelease := range futureReleases {
-		futureBranch, err := promotion.DetermineReleaseBranch(currentRelease, futureRelease, input.Info.Branch)
+		futureBranch, err := override.releaseBranch(currentRelease, futureRelease, input.Info.Branch)
 		if err != nil {
 			input.Logger().WithError(err).Error("could not determine future branch that would promote to current imagestream")
 			return nil
@@ -133,7 +184,9 @@ func generateBranchedConfigs(currentRelease, bumpRelease string, futureReleases
 		updateRelease(&futureConfig, futureRelease)
 		// we cannot have two configs promoting to the same output, so
 		// we need to make sure the release branch config is disabled
-		futureConfig.PromotionConfiguration.Disabled = futureRelease == devRelease
+		if futureConfig.PromotionConfiguration != nil {
+			futureConfig.PromotionConfiguration.Disabled = futureRelease == devRelease
+		}
 		// users can reference the release streams via build roots or
 		// input images, so we need to update those, too
 		updateImages(&futureConfig, devRelease, futureRelease)
@@ -2,9 +2,13 @@ package main
 
 import (
 	"flag"
+	"io/ioutil"
+	"path/filepath"
 	"reflect"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
+
 	"k8s.io/apimachinery/pkg/util/diff"
 	"k8s.io/test-infra/prow/flagutil"
 
@@ -19,6 +23,7 @@ func TestGenerateBranchedConfigs(t *testing.T) {
 		currentRelease string
 		bumpRelease    string
 		futureReleases []string
+		override       repoOverride
 		input          config.DataWithInfo
 		output         []config.DataWithInfo
 	}{
@@ -154,6 +159,65 @@ func TestGenerateBranchedConfigs(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:           "repository with its own naming of release branches gets a branched config on the custom branch",
+			currentRelease: "current-release",
+			futureReleases: []string{"current-release", "future-release"},
+			override:       repoOverride{ReleaseBranchPrefix: "openshift-"},
+			input: config.DataWithInfo{
+				Configuration: api.ReleaseBuildConfiguration{
+					PromotionConfiguration: &api.PromotionConfiguration{Name: "current-release", Namespace: "ocp"},
+				},
+				Info: config.Info{
+					Metadata: api.Metadata{Org: "org", Repo: "repo", Branch: "master"},
+				},
+			},
+			output: []config.DataWithInfo{
+				{
+					Configuration: api.ReleaseBuildConfiguration{
+						PromotionConfiguration: &api.PromotionConfiguration{Name: "current-release", Namespace: "ocp", Disabled: true},
+					},
+					Info: config.Info{
+						Metadata: api.Metadata{Org: "org", Repo: "repo", Branch: "openshift-current-release"},
+					},
+				},
+				{
+					Configuration: api.ReleaseBuildConfiguration{
+						PromotionConfiguration: &api.PromotionConfiguration{Name: "future-release", Namespace: "ocp"},
+					},
+					Info: config.Info{
+						Metadata: api.Metadata{Org: "org", Repo: "repo", Branch: "openshift-future-release"},
+					},
+				},
+			},
+		},
+		{
+			name:           "variant that does not promote gets a branched config with the variant",
+			currentRelease: "current-release",
+			futureReleases: []string{"current-release"},
+			input: config.DataWithInfo{
+				Configuration: api.ReleaseBuildConfiguration{
+					InputConfiguration: api.InputConfiguration{
+						ReleaseTagConfiguration: &api.ReleaseTagConfiguration{Name: "current-release", Namespace: "ocp"},
+					},
+				},
+				Info: config.Info{
+					Metadata: api.Metadata{Org: "org", Repo: "repo", Branch: "master", Variant: "variant"},
+				},
+			},
+			output: []config.DataWithInfo{
+				{
+					Configuration: api.ReleaseBuildConfiguration{
+						InputConfiguration: api.InputConfiguration{
+							ReleaseTagConfiguration: &api.ReleaseTagConfiguration{Name: "current-release", Namespace: "ocp"},
+						},
+					},
+					Info: config.Info{
+						Metadata: api.Metadata{Org: "org", Repo: "repo", Branch: "release-current-release", Variant: "variant"},
+					},
+				},
+			},
+		},
 		{
 			name:           "config that promotes to the current release from an non-dev branch gets no new config for the current release",
 			currentRelease: "current-release",
@@ -434,7 +498,7 @@ func TestGenerateBranchedConfigs(t *testing.T) {
 	}
 	for _, testCase := range testCases {
 		t.Run(testCase.name, func(t *testing.T) {
-			actual, expected := generateBranchedConfigs(testCase.currentRelease, testCase.bumpRelease, testCase.futureReleases, testCase.input), testCase.output
+			actual, expected := generateBranchedConfigs(testCase.currentRelease, testCase.bumpRelease, testCase.futureReleases, testCase.override, testCase.input), testCase.output
 			if len(actual) != len(expected) {
 				t.Fatalf("%s: did not generate correct amount of output configs, needed %d got %d", testCase.name, len(expected), len(actual))
 			}
@@ -453,6 +517,50 @@ func TestGenerateBranchedConfigs(t *testing.T) {
 	}
 }
 
+func TestLoadOverrides(t *testing.T) {
+	var testCases = []struct {
+		name        string
+		content     string
+		expected    overrides
+		expectedErr string
+	}{
+		{
+			name:    "overrides for repositories",
+			content: "repos:\n  org/repo:\n    skip: true\n  org/other:\n    release_branch_prefix: openshift-\n",
+			expected: overrides{Repos: map[string]repoOverride{
+				"org/repo":  {Skip: true},
+				"org/other": {ReleaseBranchPrefix: "openshift-"},
+			}},
+		},
+		{
+			name:        "overrides for an org",
+			content:     "repos:\n  org:\n    skip: true\n",
+			expectedErr: `overrides must be keyed by org/repo, got "org"`,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "overrides.yaml")
+			if err := ioutil.WriteFile(path, []byte(testCase.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			actual, err := loadOverrides(path)
+			var actualErr string
+			if err != nil {
+				actualErr = err.Error()
+			}
+			if diff := cmp.Diff(testCase.expectedErr, actualErr); diff != "" {
+				t.Fatalf("unexpected error: %s", diff)
+			}
+			if err == nil {
+				if diff := cmp.Diff(testCase.expected, actual); diff != "" {
+					t.Errorf("unexpected overrides: %s", diff)
+				}
+			}
+		})
+	}
+}
+
 func TestOptions_Bind(t *testing.T) {
 	var testCases = []struct {
 		name               string
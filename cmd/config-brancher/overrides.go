@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+
+	"github.com/openshift/ci-tools/pkg/promotion"
+)
+
+// repoOverride customizes how the configurations of a repository are branched
+type repoOverride struct {
+	// Skip excludes the repository from branching altogether
+	Skip bool `json:"skip,omitempty"`
+	// ReleaseBranchPrefix is the prefix of the names of the release branches
+	// cut from the development branch, e.g. `openshift-` for repositories
+	// that use openshift-4.y instead of the default release-4.y
+	ReleaseBranchPrefix string `json:"release_branch_prefix,omitempty"`
+}
+
+// overrides holds the per-repository overrides, keyed by org/repo
+type overrides struct {
+	Repos map[string]repoOverride `json:"repos,omitempty"`
+}
+
+func loadOverrides(path string) (overrides, error) {
+	var o overrides
+	if path == "" {
+		return o, nil
+	}
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return o, fmt.Errorf("failed to read overrides: %w", err)
+	}
+	if err := yaml.Unmarshal(raw, &o); err != nil {
+		return o, fmt.Errorf("failed to unmarshal overrides: %w", err)
+	}
+	for orgRepo := range o.Repos {
+		if parts := strings.Split(orgRepo, "/"); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return o, fmt.Errorf("overrides must be keyed by org/repo, got %q", orgRepo)
+		}
+	}
+	return o, nil
+}
+
+func (o overrides) forRepo(org, repo string) repoOverride {
+	return o.Repos[fmt.Sprintf("%s/%s", org, repo)]
+}
+
+// releaseBranch determines the branch for the future release, honoring the
+// naming of release branches the repository overrides
+func (o repoOverride) releaseBranch(currentRelease, futureRelease, currentBranch string) (string, error) {
+	if o.ReleaseBranchPrefix != "" && (currentBranch == "master" || currentBranch == "main") {
+		return o.ReleaseBranchPrefix + futureRelease, nil
+	}
+	return promotion.DetermineReleaseBranch(currentRelease, futureRelease, currentBranch)
+}
+
+// skippedConfig records a configuration the tool intentionally did not branch
+type skippedConfig struct {
+	Config string `json:"config"`
+	Reason string `json:"reason"`
+}
+
+// skipReport collects the configurations that were not branched, so they can
+// be followed up on instead of being noticed only after the release is cut
+type skipReport []skippedConfig
+
+func (r *skipReport) add(config, reason string) {
+	*r = append(*r, skippedConfig{Config: config, Reason: reason})
+}
+
+func (r skipReport) write(path string) error {
+	if r == nil {
+		r = skipReport{}
+	}
+	sort.Slice(r, func(i, j int) bool { return r[i].Config < r[j].Config })
+	raw, err := yaml.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("failed to marshal the report: %w", err)
+	}
+	if err := ioutil.WriteFile(path, raw, 0644); err != nil {
+		return fmt.Errorf("failed to write the report: %w", err)
+	}
+	return nil
+}
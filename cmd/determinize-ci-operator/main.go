@@ -22,10 +22,9 @@ const (
 	OpenShiftInstallerTemplateName                = "openshift_installer"
 )
 
-var validTemplateMigrations = sets.NewString(openshiftInstallerCustomTestImageTemplateName, OpenshiftInstallerUPITemplateName, OpenShiftInstallerTemplateName)
-
 type options struct {
 	config.ConfirmableOptions
+	enabledMigrations                       flagutil.Strings
 	enabledTemplateMigrations               flagutil.Strings
 	templateMigrationCeiling                int
 	templateMigrationAllowedBranches        flagutil.Strings
@@ -38,20 +37,27 @@ func (o options) validate() error {
 	if err := o.ConfirmableOptions.Validate(); err != nil {
 		errs = append(errs, err)
 	}
-	if diff := sets.NewString(o.enabledTemplateMigrations.Strings()...).Difference(validTemplateMigrations); len(diff) != 0 {
-		errs = append(errs, fmt.Errorf("invalid values %v for --enabled-template-migration, valid values: %v", diff.List(), validTemplateMigrations.List()))
+	if diff := o.enabledMigrationNames().Difference(sets.NewString(migrationNames()...)); len(diff) != 0 {
+		errs = append(errs, fmt.Errorf("invalid values %v for --enabled-migration, valid values: %v", diff.List(), migrationNames()))
 	}
 
 	return utilerrors.NewAggregate(errs)
 }
 
+// enabledMigrationNames returns the migrations enabled by either flag, the
+// template flag predates the other migrations and is kept as an alias
+func (o options) enabledMigrationNames() sets.String {
+	return o.enabledMigrations.StringSet().Union(o.enabledTemplateMigrations.StringSet())
+}
+
 func gatherOptions() options {
 	o := options{}
 	o.Bind(flag.CommandLine)
-	flag.Var(&o.enabledTemplateMigrations, "enabled-template-migration", fmt.Sprintf("The enabled template migrations. Can be passed multiple times. Valid values are %v", validTemplateMigrations.List()))
-	flag.IntVar(&o.templateMigrationCeiling, "template-migration-ceiling", 10, "The maximum number of templates to migrate")
-	flag.Var(&o.templateMigrationAllowedBranches, "template-migration-allowed-branch", "Allowed branches to automigrate templates on. Can be passed multiple times. All branches are allowed if unset.")
-	flag.Var(&o.templateMigrationAllowedOrgs, "template-migration-allowed-org", "Allowed orgs to automigrate templates on. Can be passed multiple times. All orgs are allowed if unset.")
+	flag.Var(&o.enabledMigrations, "enabled-migration", fmt.Sprintf("The enabled migrations of deprecated configuration. Can be passed multiple times. Valid values are %v", migrationNames()))
+	flag.Var(&o.enabledTemplateMigrations, "enabled-template-migration", "Deprecated alias of --enabled-migration.")
+	flag.IntVar(&o.templateMigrationCeiling, "template-migration-ceiling", 10, "The maximum number of constructs to migrate")
+	flag.Var(&o.templateMigrationAllowedBranches, "template-migration-allowed-branch", "Allowed branches to automigrate on. Can be passed multiple times. All branches are allowed if unset.")
+	flag.Var(&o.templateMigrationAllowedOrgs, "template-migration-allowed-org", "Allowed orgs to automigrate on. Can be passed multiple times. All orgs are allowed if unset.")
 	flag.Var(&o.templateMigrationAllowedClusterProfiles, "template-migration-allowed-cluster-profile", "Allowed cluster profiles to automigrate templates on. Can be passed multiple times. All cluster profiles are allowed if unset.")
 	flag.Parse()
 
@@ -65,6 +71,12 @@ func main() {
 	}
 
 	var migratedCount int
+	enabledMigrations := o.enabledMigrationNames()
+	scope := migrationScope{
+		branches:        o.templateMigrationAllowedBranches.StringSet(),
+		orgs:            o.templateMigrationAllowedOrgs.StringSet(),
+		clusterProfiles: o.templateMigrationAllowedClusterProfiles.StringSet(),
+	}
 	var toCommit []config.DataWithInfo
 	if err := o.OperateOnCIOperatorConfigDir(o.ConfigDir, func(configuration *api.ReleaseBuildConfiguration, info *config.Info) error {
 		output := config.DataWithInfo{Configuration: *configuration, Info: *info}
@@ -73,18 +85,7 @@ func main() {
 			return nil
 		}
 
-		allowedBranches := o.templateMigrationAllowedBranches.StringSet()
-		allowedOrgs := o.templateMigrationAllowedOrgs.StringSet()
-		allowedClusterProfiles := o.templateMigrationAllowedClusterProfiles.StringSet()
-		if sets.NewString(o.enabledTemplateMigrations.Strings()...).Has(openshiftInstallerCustomTestImageTemplateName) && migratedCount <= o.templateMigrationCeiling {
-			migratedCount += migrateOpenshiftInstallerCustomTestImageTemplates(&output, allowedBranches, allowedOrgs, allowedClusterProfiles)
-		}
-		if o.enabledTemplateMigrations.StringSet().Has(OpenshiftInstallerUPITemplateName) && migratedCount <= o.templateMigrationCeiling {
-			migratedCount += migrateOpenshiftOpenshiftInstallerUPIClusterTestConfiguration(&output, allowedBranches, allowedOrgs, allowedClusterProfiles)
-		}
-		if o.enabledTemplateMigrations.StringSet().Has(OpenShiftInstallerTemplateName) && migratedCount <= o.templateMigrationCeiling {
-			migratedCount += migrateOpenShiftInstallerTemplates(&output, allowedBranches, allowedOrgs, allowedClusterProfiles)
-		}
+		migratedCount = runMigrations(&output, enabledMigrations, scope, migratedCount, o.templateMigrationCeiling)
 
 		// we treat the filepath as the ultimate source of truth for this
 		// data, but we record it in the configuration files to ensure that
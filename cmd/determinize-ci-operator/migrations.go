@@ -0,0 +1,79 @@
+package main
+
+import (
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/ci-tools/pkg/config"
+)
+
+const secretToSecretsMigrationName = "secret_to_secrets"
+
+// migrationScope limits the configurations and tests a migration may rewrite,
+// empty sets allow everything
+type migrationScope struct {
+	branches        sets.String
+	orgs            sets.String
+	clusterProfiles sets.String
+}
+
+// migration rewrites a deprecated construct in a configuration to its modern
+// equivalent, returning how many constructs it rewrote
+type migration func(configuration *config.DataWithInfo, scope migrationScope) (migratedCount int)
+
+// migrations holds all known migrations. Each needs to be enabled explicitly
+// and must have a fixture in TestMigrations.
+var migrations = map[string]migration{
+	openshiftInstallerCustomTestImageTemplateName: func(configuration *config.DataWithInfo, scope migrationScope) int {
+		return migrateOpenshiftInstallerCustomTestImageTemplates(configuration, scope.branches, scope.orgs, scope.clusterProfiles)
+	},
+	OpenshiftInstallerUPITemplateName: func(configuration *config.DataWithInfo, scope migrationScope) int {
+		return migrateOpenshiftOpenshiftInstallerUPIClusterTestConfiguration(configuration, scope.branches, scope.orgs, scope.clusterProfiles)
+	},
+	OpenShiftInstallerTemplateName: func(configuration *config.DataWithInfo, scope migrationScope) int {
+		return migrateOpenShiftInstallerTemplates(configuration, scope.branches, scope.orgs, scope.clusterProfiles)
+	},
+	secretToSecretsMigrationName: migrateSecretToSecrets,
+}
+
+// migrationNames returns the names of all known migrations in the order they run
+func migrationNames() []string {
+	names := sets.NewString()
+	for name := range migrations {
+		names.Insert(name)
+	}
+	return names.List()
+}
+
+// runMigrations runs the enabled migrations on the configuration until the
+// ceiling is reached and returns the updated count of migrated constructs
+func runMigrations(configuration *config.DataWithInfo, enabled sets.String, scope migrationScope, migratedCount, ceiling int) int {
+	for _, name := range migrationNames() {
+		if !enabled.Has(name) || migratedCount > ceiling {
+			continue
+		}
+		migratedCount += migrations[name](configuration, scope)
+	}
+	return migratedCount
+}
+
+func (s migrationScope) allowsConfig(info config.Info) bool {
+	return (len(s.branches) == 0 || s.branches.Has(info.Branch)) && (len(s.orgs) == 0 || s.orgs.Has(info.Org))
+}
+
+// migrateSecretToSecrets replaces the deprecated single `secret` of tests with
+// the equivalent `secrets` list
+func migrateSecretToSecrets(configuration *config.DataWithInfo, scope migrationScope) (migratedCount int) {
+	if !scope.allowsConfig(configuration.Info) {
+		return 0
+	}
+	for idx, test := range configuration.Configuration.Tests {
+		if test.Secret == nil || test.Secrets != nil {
+			continue
+		}
+		test.Secrets = append(test.Secrets, test.Secret)
+		test.Secret = nil
+		configuration.Configuration.Tests[idx] = test
+		migratedCount++
+	}
+	return migratedCount
+}
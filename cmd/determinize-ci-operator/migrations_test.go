@@ -0,0 +1,108 @@
+package main
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/config"
+	"github.com/openshift/ci-tools/pkg/testhelper"
+)
+
+func TestMigrations(t *testing.T) {
+	info := config.Info{Metadata: api.Metadata{Org: "org", Repo: "repo", Branch: "master"}}
+	testCases := []struct {
+		migration string
+		tests     []api.TestStepConfiguration
+	}{
+		{
+			migration: openshiftInstallerCustomTestImageTemplateName,
+			tests: []api.TestStepConfiguration{{
+				As:       "e2e",
+				Commands: "make e2e",
+				OpenshiftInstallerCustomTestImageClusterTestConfiguration: &api.OpenshiftInstallerCustomTestImageClusterTestConfiguration{
+					From:                     "test-image",
+					ClusterTestConfiguration: api.ClusterTestConfiguration{ClusterProfile: api.ClusterProfileAWS},
+				},
+			}},
+		},
+		{
+			migration: OpenshiftInstallerUPITemplateName,
+			tests: []api.TestStepConfiguration{{
+				As:       "e2e-upi",
+				Commands: "TEST_SUITE=openshift/conformance/parallel run-tests",
+				OpenshiftInstallerUPIClusterTestConfiguration: &api.OpenshiftInstallerUPIClusterTestConfiguration{
+					ClusterTestConfiguration: api.ClusterTestConfiguration{ClusterProfile: api.ClusterProfileGCP},
+				},
+			}},
+		},
+		{
+			migration: OpenShiftInstallerTemplateName,
+			tests: []api.TestStepConfiguration{
+				{
+					As:       "e2e",
+					Commands: "TEST_SUITE=openshift/conformance/parallel run-tests",
+					OpenshiftInstallerClusterTestConfiguration: &api.OpenshiftInstallerClusterTestConfiguration{
+						ClusterTestConfiguration: api.ClusterTestConfiguration{ClusterProfile: api.ClusterProfileAWS},
+					},
+				},
+				{
+					As:       "e2e-upgrade",
+					Commands: "TEST_SUITE=all run-upgrade-tests",
+					OpenshiftInstallerClusterTestConfiguration: &api.OpenshiftInstallerClusterTestConfiguration{
+						ClusterTestConfiguration: api.ClusterTestConfiguration{ClusterProfile: api.ClusterProfileAWS},
+						Upgrade:                  true,
+					},
+				},
+			},
+		},
+		{
+			migration: secretToSecretsMigrationName,
+			tests: []api.TestStepConfiguration{
+				{
+					As:                         "unit",
+					Commands:                   "make test",
+					Secret:                     &api.Secret{Name: "credentials", MountPath: "/var/secrets"},
+					ContainerTestConfiguration: &api.ContainerTestConfiguration{From: "src"},
+				},
+				{
+					As:                         "integration",
+					Commands:                   "make integration",
+					Secrets:                    []*api.Secret{{Name: "credentials"}, {Name: "other"}},
+					ContainerTestConfiguration: &api.ContainerTestConfiguration{From: "src"},
+				},
+			},
+		},
+	}
+
+	covered := sets.NewString()
+	for _, tc := range testCases {
+		covered.Insert(tc.migration)
+		t.Run(tc.migration, func(t *testing.T) {
+			configuration := &config.DataWithInfo{
+				Configuration: api.ReleaseBuildConfiguration{Tests: tc.tests, Metadata: info.Metadata},
+				Info:          info,
+			}
+			if migrated := runMigrations(configuration, sets.NewString(tc.migration), migrationScope{}, 0, 10); migrated == 0 {
+				t.Error("expected the migration to rewrite the configuration")
+			}
+			testhelper.CompareWithFixture(t, configuration.Configuration)
+		})
+	}
+	if missing := sets.NewString(migrationNames()...).Difference(covered); len(missing) != 0 {
+		t.Errorf("migrations without a fixture: %v", missing.List())
+	}
+}
+
+func TestRunMigrationsCeiling(t *testing.T) {
+	configuration := &config.DataWithInfo{Configuration: api.ReleaseBuildConfiguration{
+		Tests: []api.TestStepConfiguration{{As: "unit", Secret: &api.Secret{Name: "credentials"}}},
+	}}
+	if migrated := runMigrations(configuration, sets.NewString(secretToSecretsMigrationName), migrationScope{}, 11, 10); migrated != 11 {
+		t.Errorf("expected no migration past the ceiling, got %d migrated", migrated)
+	}
+	if configuration.Configuration.Tests[0].Secret == nil {
+		t.Error("expected the configuration not to be rewritten past the ceiling")
+	}
+}
@@ -22,12 +22,21 @@ import (
 	"k8s.io/test-infra/prow/logrusutil"
 	"k8s.io/test-infra/prow/pjutil/pprof"
 	controllerruntime "sigs.k8s.io/controller-runtime"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 	ctrlruntimelog "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 
 	imagev1 "github.com/openshift/api/image/v1"
 
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/controller/health"
+	"github.com/openshift/ci-tools/pkg/controller/leaderelection"
+	namespacereaper "github.com/openshift/ci-tools/pkg/controller/namespace_reaper"
 	"github.com/openshift/ci-tools/pkg/controller/promotionreconciler"
+	"github.com/openshift/ci-tools/pkg/controller/promotionreconciler/prowjobreconciler"
+	registrysyncer "github.com/openshift/ci-tools/pkg/controller/registry_syncer"
+	secretsyncer "github.com/openshift/ci-tools/pkg/controller/secret_syncer"
 	serviceaccountsecretrefresher "github.com/openshift/ci-tools/pkg/controller/serviceaccount_secret_refresher"
 	testimagesdistributor "github.com/openshift/ci-tools/pkg/controller/test-images-distributor"
 	controllerutil "github.com/openshift/ci-tools/pkg/controller/util"
@@ -43,23 +52,46 @@ var allControllers = sets.NewString(
 	promotionreconciler.ControllerName,
 	testimagesdistributor.ControllerName,
 	serviceaccountsecretrefresher.ControllerName,
+	namespacereaper.ControllerName,
+	secretsyncer.ControllerName,
+	registrysyncer.ControllerName,
 )
 
+var defaultMaxConcurrentReconciles = map[string]int{
+	promotionreconciler.ControllerName:           promotionreconciler.DefaultMaxConcurrentReconciles,
+	testimagesdistributor.ControllerName:         testimagesdistributor.DefaultMaxConcurrentReconciles,
+	serviceaccountsecretrefresher.ControllerName: serviceaccountsecretrefresher.DefaultMaxConcurrentReconciles,
+	namespacereaper.ControllerName:               namespacereaper.DefaultMaxConcurrentReconciles,
+	secretsyncer.ControllerName:                  secretsyncer.DefaultMaxConcurrentReconciles,
+	registrysyncer.ControllerName:                registrysyncer.DefaultMaxConcurrentReconciles,
+}
+
 type options struct {
 	leaderElectionNamespace              string
 	ciOperatorconfigPath                 string
+	ciOperatorConfigOrgs                 flagutil.Strings
 	stepConfigPath                       string
 	prowconfig                           configflagutil.ConfigOptions
 	kubeconfig                           string
 	leaderElectionSuffix                 string
+	leaderElectionPerController          bool
 	enabledControllers                   flagutil.Strings
 	enabledControllersSet                sets.String
+	maxConcurrentReconciles              map[string]*int
 	registryClusterName                  string
 	dryRun                               bool
+	logLevelRaw                          string
+	logLevel                             logrus.Level
+	controllerLogLevelsRaw               flagutil.Strings
+	controllerLogLevels                  map[string]logrus.Level
 	blockProfileRate                     time.Duration
+	promotionReconcilerOptions           promotionReconcilerOptions
 	testImagesDistributorOptions         testImagesDistributorOptions
 	serviceAccountSecretRefresherOptions serviceAccountSecretRefresherOptions
+	secretSyncerOptions                  secretSyncerOptions
 	imagePusherOptions                   imagePusherOptions
+	healthOptions                        healthOptions
+	otlpEndpoint                         string
 	*flagutil.GitHubOptions
 }
 
@@ -67,6 +99,10 @@ func (o *options) addDefaults() {
 	o.enabledControllers = flagutil.NewStrings(promotionreconciler.ControllerName, testimagesdistributor.ControllerName)
 }
 
+type promotionReconcilerOptions struct {
+	annotateOnlyNamespaces flagutil.Strings
+}
+
 type testImagesDistributorOptions struct {
 	additionalImageStreamTagsRaw       flagutil.Strings
 	additionalImageStreamTags          sets.String
@@ -76,11 +112,24 @@ type testImagesDistributorOptions struct {
 	additionalImageStreamNamespaces    sets.String
 	forbiddenRegistriesRaw             flagutil.Strings
 	forbiddenRegistries                sets.String
+	configFile                         string
+	importLatencySLOThreshold          time.Duration
+	importLatencySLOObjective          float64
 }
 
 type imagePusherOptions struct {
-	imageStreamsRaw flagutil.Strings
-	imageStreams    sets.String
+	imageStreamsRaw      flagutil.Strings
+	imageStreams         sets.String
+	inventoryBindAddress string
+	resyncNamespaces     flagutil.Strings
+	resyncInterval       time.Duration
+}
+
+type healthOptions struct {
+	probeBindAddress     string
+	maxQueueDepth        int
+	maxReconcileDuration time.Duration
+	maxTimeSinceSuccess  time.Duration
 }
 
 type serviceAccountSecretRefresherOptions struct {
@@ -88,6 +137,12 @@ type serviceAccountSecretRefresherOptions struct {
 	removeOldSecrets  bool
 }
 
+type secretSyncerOptions struct {
+	secretsRaw     flagutil.Strings
+	secrets        sets.String
+	targetClusters flagutil.Strings
+}
+
 func newOpts() (*options, error) {
 	opts := &options{GitHubOptions: &flagutil.GitHubOptions{}}
 	opts.addDefaults()
@@ -105,19 +160,41 @@ func newOpts() (*options, error) {
 		flag.StringVar(&opts.kubeconfig, "kubeconfig", "", kubeconfigFlagDescription)
 	}
 	flag.StringVar(&opts.ciOperatorconfigPath, "ci-operator-config-path", "", "Path to the ci operator config")
+	flag.Var(&opts.ciOperatorConfigOrgs, "ci-operator-config-org", "Only load the ci operator configs of this org. Can be passed multiple times. All configs are loaded if unset, which is required for controllers other than the promotionreconciler.")
 	flag.StringVar(&opts.stepConfigPath, "step-config-path", "", "Path to the registries step configuration")
 	flag.StringVar(&opts.leaderElectionSuffix, "leader-election-suffix", "", "Suffix for the leader election lock. Useful for local testing. If set, --dry-run must be set as well")
+	flag.BoolVar(&opts.leaderElectionPerController, "leader-election-per-controller", false, "Use a dedicated lease per controller rather than a single one for all controllers. This allows to spread the controllers across multiple replicas.")
 	flag.Var(&opts.enabledControllers, "enable-controller", fmt.Sprintf("Enabled controllers. Available controllers are: %v. Can be specified multiple times. Defaults to %v", allControllers.List(), opts.enabledControllers.Strings()))
+	opts.maxConcurrentReconciles = map[string]*int{}
+	for _, controller := range allControllers.List() {
+		opts.maxConcurrentReconciles[controller] = flag.Int(fmt.Sprintf("%s-max-concurrent-reconciles", controller), defaultMaxConcurrentReconciles[controller], fmt.Sprintf("The number of workers of the %s controller.", controller))
+	}
+	flag.Var(&opts.promotionReconcilerOptions.annotateOnlyNamespaces, "promotionReconcilerOptions.annotate-only-namespace", "A namespace in which outdated imagestreamtags are annotated instead of rebuilt. Can be passed multiple times.")
 	flag.Var(&opts.testImagesDistributorOptions.additionalImageStreamTagsRaw, "testImagesDistributorOptions.additional-image-stream-tag", "An imagestreamtag that will be distributed even if no test explicitly references it. It must be in namespace/name:tag format (e.G `ci/clonerefs:latest`). Can be passed multiple times.")
 	flag.Var(&opts.testImagesDistributorOptions.additionalImageStreamsRaw, "testImagesDistributorOptions.additional-image-stream", "An imagestream that will be distributed even if no test explicitly references it. It must be in namespace/name format (e.G `ci/clonerefs`). Can be passed multiple times.")
 	flag.Var(&opts.testImagesDistributorOptions.additionalImageStreamNamespacesRaw, "testImagesDistributorOptions.additional-image-stream-namespace", "A namespace in which imagestreams will be distributed even if no test explicitly references them (e.G `ci`). Can be passed multiple times.")
 	flag.Var(&opts.testImagesDistributorOptions.forbiddenRegistriesRaw, "testImagesDistributorOptions.forbidden-registry", "The hostname of an image registry from which there is no synchronization of its images. Can be passed multiple times.")
+	flag.StringVar(&opts.testImagesDistributorOptions.configFile, "testImagesDistributorOptions.config-file", "", "A file with additional imagestreamtags, imagestreams, namespaces and forbidden registries. It gets reloaded on change and its content is added to the values passed via flags.")
+	flag.DurationVar(&opts.testImagesDistributorOptions.importLatencySLOThreshold, "testImagesDistributorOptions.import-latency-slo-threshold", controllerutil.DefaultImportLatencySLO.Threshold, "The time within which imports into the build clusters should succeed after the source tag was created.")
+	flag.Float64Var(&opts.testImagesDistributorOptions.importLatencySLOObjective, "testImagesDistributorOptions.import-latency-slo-objective", controllerutil.DefaultImportLatencySLO.Objective, "The share of imports that should succeed within the import latency SLO threshold.")
 	flag.DurationVar(&opts.blockProfileRate, "block-profile-rate", time.Duration(0), "The block profile rate. Set to non-zero to enable.")
 	flag.StringVar(&opts.registryClusterName, "registry-cluster-name", "api.ci", "the cluster name on which the CI central registry is running")
 	flag.Var(&opts.serviceAccountSecretRefresherOptions.enabledNamespaces, "serviceAccountRefresherOptions.enabled-namespace", "A namespace for which the serviceaccount_secret_refresher should be enabled. Can be passed multiple times.")
 	flag.BoolVar(&opts.serviceAccountSecretRefresherOptions.removeOldSecrets, "serviceAccountRefresherOptions.remove-old-secrets", false, "whether the serviceaccountsecretrefresher should delete secrets older than 30 days")
+	flag.Var(&opts.secretSyncerOptions.secretsRaw, "secretSyncerOptions.secret", fmt.Sprintf("A secret in namespace/name format that will be synced from app.ci to the target clusters. Can be passed multiple times. Defaults to ci/%s.", api.RegistryPullCredentialsSecret))
+	flag.Var(&opts.secretSyncerOptions.targetClusters, "secretSyncerOptions.target-cluster", "A cluster the secrets will be synced to. Can be passed multiple times. Defaults to all clusters except app.ci.")
 	flag.Var(&opts.imagePusherOptions.imageStreamsRaw, "imagePusherOptions.image-stream", "An imagestream that will be synced. It must be in namespace/name format (e.G `ci/clonerefs`). Can be passed multiple times.")
+	flag.StringVar(&opts.imagePusherOptions.inventoryBindAddress, "imagePusherOptions.inventory-bind-address", "", fmt.Sprintf("The address on which the inventory of the synced imagestreams is served under %s. Set to empty to disable.", registrysyncer.InventoryPath))
+	flag.Var(&opts.imagePusherOptions.resyncNamespaces, "imagePusherOptions.resync-namespace", fmt.Sprintf("A namespace whose imagestreams are all synced by the %s controller every --imagePusherOptions.resync-interval, in addition to syncing the imagestreams passed via --imagePusherOptions.image-stream whenever they change. Can be passed multiple times.", registrysyncer.ControllerName))
+	flag.DurationVar(&opts.imagePusherOptions.resyncInterval, "imagePusherOptions.resync-interval", registrysyncer.DefaultResyncInterval, "The interval in which all imagestreams of the namespaces passed via --imagePusherOptions.resync-namespace are synced.")
+	flag.StringVar(&opts.healthOptions.probeBindAddress, "health-probe-bind-address", ":8081", "The address the /healthz and /readyz endpoints bind to. Set to 0 to disable.")
+	flag.IntVar(&opts.healthOptions.maxQueueDepth, "health.max-queue-depth", 0, "The workqueue depth above which a controller is considered unhealthy. Set to 0 to disable.")
+	flag.DurationVar(&opts.healthOptions.maxReconcileDuration, "health.max-reconcile-duration", 30*time.Minute, "The duration of a single reconciliation above which a controller is considered unhealthy. Set to 0 to disable.")
+	flag.DurationVar(&opts.healthOptions.maxTimeSinceSuccess, "health.max-time-since-success", time.Hour, "The duration without a successful reconciliation while items are queued above which a controller is considered unhealthy. Set to 0 to disable.")
+	flag.StringVar(&opts.otlpEndpoint, "tracing.otlp-endpoint", "", "The host:port of an OTLP gRPC collector to export traces of reconciliations and the calls they make to. Set to empty to disable tracing.")
 	flag.BoolVar(&opts.dryRun, "dry-run", true, "Whether to run the controller-manager with dry-run")
+	flag.StringVar(&opts.logLevelRaw, "log-level", logrus.InfoLevel.String(), "The log level.")
+	flag.Var(&opts.controllerLogLevelsRaw, "controller-log-level", fmt.Sprintf("A log level for a single controller in controller=level format (e.G `%s=debug`), overriding --log-level. Can be passed multiple times.", testimagesdistributor.ControllerName))
 	flag.Parse()
 
 	var errs []error
@@ -136,6 +213,20 @@ func newOpts() (*options, error) {
 			errs = append(errs, fmt.Errorf("the following controllers are unknown but were disabled via --disable-controller: %v", diff.List()))
 		}
 	}
+	for _, controller := range allControllers.List() {
+		if *opts.maxConcurrentReconciles[controller] < 1 {
+			errs = append(errs, fmt.Errorf("--%s-max-concurrent-reconciles must be at least 1", controller))
+		}
+	}
+
+	logLevel, err := logrus.ParseLevel(opts.logLevelRaw)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("--log-level: %w", err))
+	}
+	opts.logLevel = logLevel
+	controllerLogLevels, logLevelErrors := completeControllerLogLevels("controller-log-level", opts.controllerLogLevelsRaw, allControllers.Union(sets.NewString(prowjobreconciler.ControllerName)))
+	errs = append(errs, logLevelErrors...)
+	opts.controllerLogLevels = controllerLogLevels
 
 	isTags, isTagErrors := completeImageStreamTags("testImagesDistributorOptions.additional-image-stream-tag", opts.testImagesDistributorOptions.additionalImageStreamTagsRaw)
 	errs = append(errs, isTagErrors...)
@@ -148,13 +239,35 @@ func newOpts() (*options, error) {
 	opts.testImagesDistributorOptions.additionalImageStreamNamespaces = completeSet(opts.testImagesDistributorOptions.additionalImageStreamNamespacesRaw)
 	opts.testImagesDistributorOptions.forbiddenRegistries = completeSet(opts.testImagesDistributorOptions.forbiddenRegistriesRaw)
 
+	if len(opts.secretSyncerOptions.secretsRaw.Strings()) == 0 {
+		opts.secretSyncerOptions.secretsRaw = flagutil.NewStrings("ci/" + api.RegistryPullCredentialsSecret)
+	}
+	secrets, secretErrors := completeImageStream("secretSyncerOptions.secret", opts.secretSyncerOptions.secretsRaw)
+	errs = append(errs, secretErrors...)
+	opts.secretSyncerOptions.secrets = secrets
+
 	imagePusherImageStreams, isErrors := completeImageStream("uniRegistrySyncerOptions.image-stream", opts.imagePusherOptions.imageStreamsRaw)
 	errs = append(errs, isErrors...)
 	opts.imagePusherOptions.imageStreams = imagePusherImageStreams
+	if opts.imagePusherOptions.inventoryBindAddress != "" && len(imagePusherImageStreams) == 0 {
+		errs = append(errs, errors.New("--imagePusherOptions.inventory-bind-address requires --imagePusherOptions.image-stream to be passed at least once"))
+	}
+	if opts.enabledControllersSet.Has(registrysyncer.ControllerName) && len(imagePusherImageStreams) == 0 && len(opts.imagePusherOptions.resyncNamespaces.Strings()) == 0 {
+		errs = append(errs, fmt.Errorf("--imagePusherOptions.image-stream or --imagePusherOptions.resync-namespace must be passed at least once when enabling the %s controller, otherwise it won't do anything", registrysyncer.ControllerName))
+	}
+	if opts.imagePusherOptions.resyncInterval <= 0 {
+		errs = append(errs, fmt.Errorf("--imagePusherOptions.resync-interval must be positive, was %v", opts.imagePusherOptions.resyncInterval))
+	}
 
 	if opts.enabledControllersSet.Has(testimagesdistributor.ControllerName) && opts.stepConfigPath == "" {
 		errs = append(errs, fmt.Errorf("--step-config-path is required when the %s controller is enabled", testimagesdistributor.ControllerName))
 	}
+	if opts.enabledControllersSet.Has(testimagesdistributor.ControllerName) && len(opts.ciOperatorConfigOrgs.Strings()) > 0 {
+		errs = append(errs, fmt.Errorf("--ci-operator-config-org can not be used when the %s controller is enabled, as it needs the configs of all orgs", testimagesdistributor.ControllerName))
+	}
+	if objective := opts.testImagesDistributorOptions.importLatencySLOObjective; objective <= 0 || objective >= 1 {
+		errs = append(errs, fmt.Errorf("--testImagesDistributorOptions.import-latency-slo-objective must be between 0 and 1, was %v", objective))
+	}
 
 	if opts.enabledControllersSet.Has(serviceaccountsecretrefresher.ControllerName) {
 		if len(opts.serviceAccountSecretRefresherOptions.enabledNamespaces.Strings()) == 0 {
@@ -205,6 +318,29 @@ func completeImageStream(name string, raw flagutil.Strings) (sets.String, []erro
 	return imageStreams, errs
 }
 
+func completeControllerLogLevels(name string, raw flagutil.Strings, controllers sets.String) (map[string]logrus.Level, []error) {
+	levels := map[string]logrus.Level{}
+	var errs []error
+	for _, val := range raw.Strings() {
+		equalSplit := strings.Split(val, "=")
+		if len(equalSplit) != 2 {
+			errs = append(errs, fmt.Errorf("--%s value %s was not in controller=level format", name, val))
+			continue
+		}
+		if !controllers.Has(equalSplit[0]) {
+			errs = append(errs, fmt.Errorf("--%s value %s references unknown controller %s, known controllers are: %v", name, val, equalSplit[0], controllers.List()))
+			continue
+		}
+		level, err := logrus.ParseLevel(equalSplit[1])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("--%s value %s: %w", name, val, err))
+			continue
+		}
+		levels[equalSplit[0]] = level
+	}
+	return levels, errs
+}
+
 func completeSet(raw flagutil.Strings) sets.String {
 	result := sets.String{}
 	if vals := raw.Strings(); len(vals) > 0 {
@@ -215,6 +351,67 @@ func completeSet(raw flagutil.Strings) sets.String {
 	return result
 }
 
+// addHealthChecks registers a readiness check for the informers of every cluster
+// and a health check for every enabled controller on the given manager.
+func addHealthChecks(mgr controllerruntime.Manager, allManagers map[string]controllerruntime.Manager, opts *options) error {
+	for cluster, clusterMgr := range allManagers {
+		if err := mgr.AddReadyzCheck(fmt.Sprintf("informers_%s", cluster), health.CacheSyncedChecker(clusterMgr.GetCache())); err != nil {
+			return fmt.Errorf("failed to add readiness check for cluster %s: %w", cluster, err)
+		}
+	}
+
+	var controllers []string
+	if opts.enabledControllersSet.Has(promotionreconciler.ControllerName) {
+		controllers = append(controllers, promotionreconciler.ControllerName, prowjobreconciler.ControllerName)
+	}
+	if opts.enabledControllersSet.Has(testimagesdistributor.ControllerName) {
+		controllers = append(controllers, testimagesdistributor.ControllerName)
+	}
+	if opts.enabledControllersSet.Has(serviceaccountsecretrefresher.ControllerName) {
+		for cluster := range allManagers {
+			controllers = append(controllers, fmt.Sprintf("%s_%s", serviceaccountsecretrefresher.ControllerName, cluster))
+		}
+	}
+	if opts.enabledControllersSet.Has(secretsyncer.ControllerName) {
+		controllers = append(controllers, secretsyncer.ControllerName)
+	}
+	if opts.enabledControllersSet.Has(registrysyncer.ControllerName) {
+		controllers = append(controllers, registrysyncer.ControllerName)
+	}
+	if opts.enabledControllersSet.Has(namespacereaper.ControllerName) {
+		for cluster := range allManagers {
+			controllers = append(controllers, fmt.Sprintf("%s_%s", namespacereaper.ControllerName, cluster))
+		}
+	}
+	checker := health.NewChecker(metrics.Registry, health.Options{
+		MaxQueueDepth:        opts.healthOptions.maxQueueDepth,
+		MaxReconcileDuration: opts.healthOptions.maxReconcileDuration,
+		MaxTimeSinceSuccess:  opts.healthOptions.maxTimeSinceSuccess,
+	})
+	for _, controller := range controllers {
+		if err := mgr.AddHealthzCheck(controller, checker.CheckerFor(controller)); err != nil {
+			return fmt.Errorf("failed to add health check for controller %s: %w", controller, err)
+		}
+	}
+	return nil
+}
+
+// controllerManagers returns a func that maps a manager to the one the given controller
+// has to be added to. If --leader-election-per-controller is set, the returned managers
+// only start the controller while the replica holds the lease of the controller.
+func controllerManagers(mgr controllerruntime.Manager, opts *options, controller string) (func(controllerruntime.Manager) controllerruntime.Manager, error) {
+	if !opts.leaderElectionPerController {
+		return func(m controllerruntime.Manager) controllerruntime.Manager { return m }, nil
+	}
+	// Underscores are not allowed in object names
+	name := fmt.Sprintf("dptp-controller-manager%s-%s", opts.leaderElectionSuffix, strings.ReplaceAll(controller, "_", "-"))
+	lease, err := leaderelection.NewLease(mgr, opts.leaderElectionNamespace, name)
+	if err != nil {
+		return nil, err
+	}
+	return lease.Manager, nil
+}
+
 func main() {
 	logrusutil.ComponentInit()
 
@@ -222,6 +419,7 @@ func main() {
 	if err != nil {
 		logrus.WithError(err).Fatal("Failed to get options")
 	}
+	controllerutil.SetLogLevels(opts.logLevel, opts.controllerLogLevels)
 	if val := int(opts.blockProfileRate.Nanoseconds()); val != 0 {
 		logrus.WithField("rate", opts.blockProfileRate.String()).Info("Setting block profile rate")
 		runtime.SetBlockProfileRate(val)
@@ -230,6 +428,18 @@ func main() {
 	ctx := controllerruntime.SetupSignalHandler()
 	ctx, cancel := context.WithCancel(ctx)
 
+	if opts.otlpEndpoint != "" {
+		shutdownTracing, err := controllerutil.SetupTracing("dptp-controller-manager", opts.otlpEndpoint)
+		if err != nil {
+			logrus.WithError(err).Fatal("Failed to set up tracing")
+		}
+		defer func() {
+			if err := shutdownTracing(context.Background()); err != nil {
+				logrus.WithError(err).Error("Failed to flush traces")
+			}
+		}()
+	}
+
 	kubeconfigChangedCallBack := func(e fsnotify.Event) {
 		logrus.WithField("event", e.String()).Info("Kubeconfig changed, exiting to get restarted by Kubelet and pick up the changes")
 		cancel()
@@ -254,7 +464,7 @@ func main() {
 		logrus.Fatalf("--kubeconfig must include a context named `%s`", opts.registryClusterName)
 	}
 
-	ciOPConfigAgent, err := agents.NewConfigAgent(opts.ciOperatorconfigPath)
+	ciOPConfigAgent, err := agents.NewConfigAgent(opts.ciOperatorconfigPath, agents.WithConfigOrgs(opts.ciOperatorConfigOrgs.Strings()...))
 	if err != nil {
 		logrus.WithError(err).Fatal("Failed to construct ci-operator config agent")
 	}
@@ -278,10 +488,11 @@ func main() {
 			Logger:       ctrlruntimelog.NullLogger{},
 		}
 		if cluster == appCIContextName {
-			options.LeaderElection = true
+			options.LeaderElection = !opts.leaderElectionPerController
 			options.LeaderElectionReleaseOnCancel = true
 			options.LeaderElectionNamespace = opts.leaderElectionNamespace
 			options.LeaderElectionID = fmt.Sprintf("dptp-controller-manager%s", opts.leaderElectionSuffix)
+			options.HealthProbeBindAddress = opts.healthOptions.probeBindAddress
 		} else {
 			options.MetricsBindAddress = "0"
 		}
@@ -289,6 +500,9 @@ func main() {
 			syncPeriod := 24 * time.Hour
 			options.SyncPeriod = &syncPeriod
 		}
+		if opts.otlpEndpoint != "" {
+			cfg.Wrap(controllerutil.TracingRoundTripper)
+		}
 		mgr, err := controllerruntime.NewManager(cfg, options)
 		if err != nil {
 			errs = append(errs, fmt.Errorf("failed to construct manager for cluster %s: %w", cluster, err))
@@ -342,6 +556,10 @@ func main() {
 	}
 
 	if opts.enabledControllersSet.Has(promotionreconciler.ControllerName) {
+		managerFor, err := controllerManagers(mgr, opts, promotionreconciler.ControllerName)
+		if err != nil {
+			logrus.WithError(err).Fatalf("Failed to set up leader election for the %s controller", promotionreconciler.ControllerName)
+		}
 		gitHubClient, err := opts.GitHubClient(secretAgent, opts.dryRun)
 		if err != nil {
 			logrus.WithError(err).Fatal("Failed to get gitHubClient")
@@ -352,13 +570,15 @@ func main() {
 		// state.
 		gitHubClient.Throttle(600, 300)
 		promotionreconcilerOptions := promotionreconciler.Options{
-			DryRun:                opts.dryRun,
-			CIOperatorConfigAgent: ciOPConfigAgent,
-			ConfigGetter:          configAgent.Config,
-			GitHubClient:          gitHubClient,
-			RegistryManager:       registryMgr,
+			DryRun:                  opts.dryRun,
+			CIOperatorConfigAgent:   ciOPConfigAgent,
+			ConfigGetter:            configAgent.Config,
+			GitHubClient:            gitHubClient,
+			RegistryManager:         managerFor(registryMgr),
+			MaxConcurrentReconciles: *opts.maxConcurrentReconciles[promotionreconciler.ControllerName],
+			AnnotateOnlyNamespaces:  opts.promotionReconcilerOptions.annotateOnlyNamespaces.StringSet(),
 		}
-		if err := promotionreconciler.AddToManager(mgr, promotionreconcilerOptions); err != nil {
+		if err := promotionreconciler.AddToManager(managerFor(mgr), promotionreconcilerOptions); err != nil {
 			logrus.WithError(err).Fatal("Failed to add imagestreamtagreconciler")
 		}
 	}
@@ -367,6 +587,10 @@ func main() {
 		if err := controllerutil.RegisterMetrics(); err != nil {
 			logrus.WithError(err).Fatal("failed to register metrics")
 		}
+		controllerutil.SetImportLatencySLO(testimagesdistributor.ControllerName, controllerutil.ImportLatencySLO{
+			Threshold: opts.testImagesDistributorOptions.importLatencySLOThreshold,
+			Objective: opts.testImagesDistributorOptions.importLatencySLOObjective,
+		})
 	}
 
 	if opts.enabledControllersSet.Has(testimagesdistributor.ControllerName) {
@@ -375,30 +599,120 @@ func main() {
 			logrus.WithError(err).Fatal("failed to construct registryAgent")
 		}
 
+		filters := testimagesdistributor.Filters{
+			AdditionalImageStreamTags:       opts.testImagesDistributorOptions.additionalImageStreamTags,
+			AdditionalImageStreams:          opts.testImagesDistributorOptions.additionalImageStreams,
+			AdditionalImageStreamNamespaces: opts.testImagesDistributorOptions.additionalImageStreamNamespaces,
+			ForbiddenRegistries:             opts.testImagesDistributorOptions.forbiddenRegistries,
+		}
+		filtersAgent := testimagesdistributor.NewStaticFiltersAgent(filters)
+		if opts.testImagesDistributorOptions.configFile != "" {
+			filtersAgent, err = testimagesdistributor.NewFiltersAgent(filters, opts.testImagesDistributorOptions.configFile)
+			if err != nil {
+				logrus.WithError(err).Fatal("failed to construct filters agent for testimagesdistributor")
+			}
+		}
+
+		managerFor, err := controllerManagers(mgr, opts, testimagesdistributor.ControllerName)
+		if err != nil {
+			logrus.WithError(err).Fatalf("Failed to set up leader election for the %s controller", testimagesdistributor.ControllerName)
+		}
 		if err := testimagesdistributor.AddToManager(
-			mgr,
+			managerFor(mgr),
 			opts.registryClusterName,
 			registryMgr,
 			allClustersExceptRegistryCluster,
 			ciOPConfigAgent,
 			registryConfigAgent,
-			opts.testImagesDistributorOptions.additionalImageStreamTags,
-			opts.testImagesDistributorOptions.additionalImageStreams,
-			opts.testImagesDistributorOptions.additionalImageStreamNamespaces,
-			opts.testImagesDistributorOptions.forbiddenRegistries,
+			filtersAgent,
+			*opts.maxConcurrentReconciles[testimagesdistributor.ControllerName],
+			opts.dryRun,
 		); err != nil {
 			logrus.WithError(err).Fatal("failed to add testimagesdistributor")
 		}
 	}
 
 	if opts.enabledControllersSet.Has(serviceaccountsecretrefresher.ControllerName) {
+		managerFor, err := controllerManagers(mgr, opts, serviceaccountsecretrefresher.ControllerName)
+		if err != nil {
+			logrus.WithError(err).Fatalf("Failed to set up leader election for the %s controller", serviceaccountsecretrefresher.ControllerName)
+		}
 		for clusterName, clusterMgr := range allManagers {
-			if err := serviceaccountsecretrefresher.AddToManager(clusterName, clusterMgr, opts.serviceAccountSecretRefresherOptions.enabledNamespaces.StringSet(), opts.serviceAccountSecretRefresherOptions.removeOldSecrets); err != nil {
+			if err := serviceaccountsecretrefresher.AddToManager(clusterName, managerFor(clusterMgr), opts.serviceAccountSecretRefresherOptions.enabledNamespaces.StringSet(), opts.serviceAccountSecretRefresherOptions.removeOldSecrets, *opts.maxConcurrentReconciles[serviceaccountsecretrefresher.ControllerName]); err != nil {
 				logrus.WithError(err).Fatalf("Failed to add the %s controller to the %s cluster", serviceaccountsecretrefresher.ControllerName, clusterName)
 			}
 		}
 	}
 
+	if opts.enabledControllersSet.Has(namespacereaper.ControllerName) {
+		managerFor, err := controllerManagers(mgr, opts, namespacereaper.ControllerName)
+		if err != nil {
+			logrus.WithError(err).Fatalf("Failed to set up leader election for the %s controller", namespacereaper.ControllerName)
+		}
+		for clusterName, clusterMgr := range allManagers {
+			if err := namespacereaper.AddToManager(clusterName, managerFor(clusterMgr), *opts.maxConcurrentReconciles[namespacereaper.ControllerName]); err != nil {
+				logrus.WithError(err).Fatalf("Failed to add the %s controller to the %s cluster", namespacereaper.ControllerName, clusterName)
+			}
+		}
+	}
+
+	if opts.enabledControllersSet.Has(secretsyncer.ControllerName) {
+		targetClusters := map[string]controllerruntime.Manager{}
+		for _, cluster := range opts.secretSyncerOptions.targetClusters.Strings() {
+			clusterMgr, ok := allManagers[cluster]
+			if !ok {
+				logrus.Fatalf("--secretSyncerOptions.target-cluster: no context for cluster %s in --kubeconfig", cluster)
+			}
+			targetClusters[cluster] = clusterMgr
+		}
+		if len(targetClusters) == 0 {
+			for cluster, clusterMgr := range allManagers {
+				if cluster != appCIContextName {
+					targetClusters[cluster] = clusterMgr
+				}
+			}
+		}
+		managerFor, err := controllerManagers(mgr, opts, secretsyncer.ControllerName)
+		if err != nil {
+			logrus.WithError(err).Fatalf("Failed to set up leader election for the %s controller", secretsyncer.ControllerName)
+		}
+		if err := secretsyncer.AddToManager(managerFor(mgr), targetClusters, opts.secretSyncerOptions.secrets, *opts.maxConcurrentReconciles[secretsyncer.ControllerName]); err != nil {
+			logrus.WithError(err).Fatalf("Failed to add the %s controller", secretsyncer.ControllerName)
+		}
+	}
+
+	if opts.enabledControllersSet.Has(registrysyncer.ControllerName) {
+		managerFor, err := controllerManagers(mgr, opts, registrysyncer.ControllerName)
+		if err != nil {
+			logrus.WithError(err).Fatalf("Failed to set up leader election for the %s controller", registrysyncer.ControllerName)
+		}
+		if err := registrysyncer.AddToManager(
+			managerFor(mgr),
+			allManagers,
+			opts.imagePusherOptions.imageStreams,
+			sets.NewString(opts.imagePusherOptions.resyncNamespaces.Strings()...),
+			opts.imagePusherOptions.resyncInterval,
+			*opts.maxConcurrentReconciles[registrysyncer.ControllerName],
+			opts.dryRun,
+		); err != nil {
+			logrus.WithError(err).Fatalf("Failed to add the %s controller", registrysyncer.ControllerName)
+		}
+	}
+
+	if opts.imagePusherOptions.inventoryBindAddress != "" {
+		clients := map[string]ctrlruntimeclient.Client{}
+		for cluster, clusterMgr := range allManagers {
+			clients[cluster] = clusterMgr.GetClient()
+		}
+		if err := registrysyncer.AddInventoryServer(mgr, opts.imagePusherOptions.inventoryBindAddress, clients, opts.imagePusherOptions.imageStreams); err != nil {
+			logrus.WithError(err).Fatal("Failed to add the imagestream inventory server")
+		}
+	}
+
+	if err := addHealthChecks(mgr, allManagers, opts); err != nil {
+		logrus.WithError(err).Fatal("Failed to add health checks")
+	}
+
 	if err := mgr.Start(ctx); err != nil {
 		logrus.WithError(err).Fatal("Manager ended with error")
 	}
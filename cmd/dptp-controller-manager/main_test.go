@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
 
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/test-infra/prow/flagutil"
@@ -119,3 +120,44 @@ func TestCompleteSet(t *testing.T) {
 		})
 	}
 }
+
+func TestCompleteControllerLogLevels(t *testing.T) {
+	tests := []struct {
+		name           string
+		raw            flagutil.Strings
+		expected       map[string]logrus.Level
+		expectedErrors []error
+	}{
+		{
+			name:     "no flags",
+			expected: map[string]logrus.Level{},
+		},
+		{
+			name:     "some flags",
+			raw:      flagutil.NewStrings("a=debug", "b=warn"),
+			expected: map[string]logrus.Level{"a": logrus.DebugLevel, "b": logrus.WarnLevel},
+		},
+		{
+			name:     "invalid flags",
+			raw:      flagutil.NewStrings("a=debug", "b", "c=info", "a=loud"),
+			expected: map[string]logrus.Level{"a": logrus.DebugLevel},
+			expectedErrors: []error{
+				fmt.Errorf("--some-flag value b was not in controller=level format"),
+				fmt.Errorf("--some-flag value c=info references unknown controller c, known controllers are: [a b]"),
+				fmt.Errorf(`--some-flag value a=loud: not a valid logrus Level: "loud"`),
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, actualErrors := completeControllerLogLevels("some-flag", tc.raw, sets.NewString("a", "b"))
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Errorf("actual does not match expected, diff: %s", diff)
+			}
+			if diff := cmp.Diff(tc.expectedErrors, actualErrors, testhelper.EquateErrorMessage); diff != "" {
+				t.Errorf("actualError does not match expectedError, diff: %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"time"
+
+	coreapi "k8s.io/api/core/v1"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	imagev1 "github.com/openshift/api/image/v1"
+)
+
+// scanCluster collects the image failures from the events and ImageStreams of all namespaces on a cluster
+func scanCluster(ctx context.Context, cluster string, client ctrlruntimeclient.Client, since time.Time) ([]failure, error) {
+	events := &coreapi.EventList{}
+	if err := client.List(ctx, events, ctrlruntimeclient.MatchingFields{"reason": "Failed"}); err != nil {
+		return nil, fmt.Errorf("failed to list events on cluster %s: %w", cluster, err)
+	}
+	eventsByNamespace := map[string][]coreapi.Event{}
+	for _, event := range events.Items {
+		eventsByNamespace[event.Namespace] = append(eventsByNamespace[event.Namespace], event)
+	}
+	var result []failure
+	for namespace, items := range eventsByNamespace {
+		result = append(result, failuresFromEvents(path.Join(cluster, namespace), items, since)...)
+	}
+
+	streams := &imagev1.ImageStreamList{}
+	if err := client.List(ctx, streams); err != nil {
+		return nil, fmt.Errorf("failed to list imagestreams on cluster %s: %w", cluster, err)
+	}
+	streamsByNamespace := map[string][]imagev1.ImageStream{}
+	for _, stream := range streams.Items {
+		streamsByNamespace[stream.Namespace] = append(streamsByNamespace[stream.Namespace], stream)
+	}
+	for namespace, items := range streamsByNamespace {
+		result = append(result, failuresFromImageStreams(path.Join(cluster, namespace), items, since)...)
+	}
+	return result, nil
+}
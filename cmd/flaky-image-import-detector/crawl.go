@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"time"
+
+	coreapi "k8s.io/api/core/v1"
+
+	imagev1 "github.com/openshift/api/image/v1"
+
+	"github.com/openshift/ci-tools/pkg/jobartifacts"
+)
+
+// crawl collects the image failures from the namespace artifacts ci-operator uploads for
+// all builds of the given jobs that finished after since
+func crawl(ctx context.Context, source jobartifacts.Source, jobs []string, maxBuildsPerJob int, since time.Time) ([]failure, error) {
+	var result []failure
+	err := jobartifacts.Crawl(ctx, source, jobs, maxBuildsPerJob, since, func(ctx context.Context, job, build string, _ time.Time) error {
+		failures, err := crawlBuild(ctx, source, job, build, since)
+		if err != nil {
+			return err
+		}
+		result = append(result, failures...)
+		return nil
+	})
+	return result, err
+}
+
+func crawlBuild(ctx context.Context, source jobartifacts.Source, job, build string, since time.Time) ([]failure, error) {
+	name := path.Join(job, path.Base(build))
+	var result []failure
+	rawEvents, err := source.Read(ctx, path.Join(build, "artifacts", "build-resources", "events.json"))
+	switch {
+	case errors.Is(err, jobartifacts.ErrNotExist):
+		// Not a ci-operator job
+	case err != nil:
+		return nil, err
+	default:
+		var events coreapi.EventList
+		if err := json.Unmarshal(rawEvents, &events); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal events: %w", err)
+		}
+		result = append(result, failuresFromEvents(name, events.Items, since)...)
+	}
+
+	rawStreams, err := source.Read(ctx, path.Join(build, "artifacts", "build-resources", "imagestreams.json"))
+	switch {
+	case errors.Is(err, jobartifacts.ErrNotExist):
+	case err != nil:
+		return nil, err
+	default:
+		var streams imagev1.ImageStreamList
+		if err := json.Unmarshal(rawStreams, &streams); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal imagestreams: %w", err)
+		}
+		result = append(result, failuresFromImageStreams(name, streams.Items, since)...)
+	}
+	return result, nil
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	coreapi "k8s.io/api/core/v1"
+
+	imagev1 "github.com/openshift/api/image/v1"
+)
+
+type failureKind string
+
+const (
+	// pullFailure is a kubelet that failed to pull the image of a container
+	pullFailure failureKind = "pull"
+	// importFailure is an ImageStream tag that failed to import an image
+	importFailure failureKind = "import"
+)
+
+// failure is one or more failed attempts to retrieve an image from its registry
+type failure struct {
+	Kind  failureKind
+	Image string
+	// Source identifies where the failure was seen, e.g. a build of a job or a namespace on a cluster
+	Source  string
+	Message string
+	Count   int
+	Time    time.Time
+}
+
+// pullFailureMessage matches the message of the events the kubelet emits when it fails to pull an image.
+// Back-off events for the same image are not considered, as they would count the same failure twice.
+var pullFailureMessage = regexp.MustCompile(`^Failed to pull image "([^"]+)"`)
+
+// failuresFromEvents returns the image pull failures recorded by events that were last seen after since
+func failuresFromEvents(source string, events []coreapi.Event, since time.Time) []failure {
+	var failures []failure
+	for _, event := range events {
+		if event.Reason != "Failed" {
+			continue
+		}
+		match := pullFailureMessage.FindStringSubmatch(event.Message)
+		if match == nil {
+			continue
+		}
+		seen := eventTime(event)
+		if seen.Before(since) {
+			continue
+		}
+		count := int(event.Count)
+		if count < 1 {
+			count = 1
+		}
+		failures = append(failures, failure{
+			Kind:    pullFailure,
+			Image:   match[1],
+			Source:  source,
+			Message: event.Message,
+			Count:   count,
+			Time:    seen,
+		})
+	}
+	return failures
+}
+
+// eventTime returns when the event was last seen
+func eventTime(event coreapi.Event) time.Time {
+	switch {
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	default:
+		return event.FirstTimestamp.Time
+	}
+}
+
+// failuresFromImageStreams returns the failed imports of external images into the given
+// ImageStreams that happened after since
+func failuresFromImageStreams(source string, streams []imagev1.ImageStream, since time.Time) []failure {
+	var failures []failure
+	for _, stream := range streams {
+		pullSpecs := map[string]string{}
+		for _, tag := range stream.Spec.Tags {
+			if tag.From != nil && tag.From.Kind == "DockerImage" {
+				pullSpecs[tag.Name] = tag.From.Name
+			}
+		}
+		for _, tag := range stream.Status.Tags {
+			pullSpec, external := pullSpecs[tag.Tag]
+			if !external {
+				continue
+			}
+			for _, condition := range tag.Conditions {
+				if condition.Type != imagev1.ImportSuccess || condition.Status != coreapi.ConditionFalse {
+					continue
+				}
+				if condition.LastTransitionTime.Time.Before(since) {
+					continue
+				}
+				failures = append(failures, failure{
+					Kind:    importFailure,
+					Image:   pullSpec,
+					Source:  source,
+					Message: condition.Message,
+					Count:   1,
+					Time:    condition.LastTransitionTime.Time,
+				})
+			}
+		}
+	}
+	return failures
+}
+
+// splitImage returns the registry and the repository of a pull spec, without its tag or digest
+func splitImage(pullSpec string) (string, string) {
+	if idx := strings.Index(pullSpec, "@"); idx != -1 {
+		pullSpec = pullSpec[:idx]
+	}
+	if idx := strings.LastIndex(pullSpec, ":"); idx > strings.LastIndex(pullSpec, "/") {
+		pullSpec = pullSpec[:idx]
+	}
+	parts := strings.SplitN(pullSpec, "/", 2)
+	if len(parts) == 2 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		return parts[0], parts[1]
+	}
+	// Images without a registry are pulled from Docker Hub, where official images live in the library namespace
+	if len(parts) == 1 {
+		return "docker.io", "library/" + pullSpec
+	}
+	return "docker.io", pullSpec
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/api/option"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/test-infra/prow/flagutil"
+	"k8s.io/test-infra/prow/logrusutil"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	imagev1 "github.com/openshift/api/image/v1"
+
+	"github.com/openshift/ci-tools/pkg/jobartifacts"
+	"github.com/openshift/ci-tools/pkg/util"
+)
+
+type options struct {
+	bucket             string
+	gcsCredentialsFile string
+	jobs               flagutil.Strings
+	maxBuildsPerJob    int
+
+	kubeconfig string
+
+	window            time.Duration
+	imagesPerRegistry int
+	outputJSON        string
+}
+
+func gatherOptions() options {
+	o := options{}
+	flag.StringVar(&o.bucket, "bucket", "", "GCS bucket to crawl job artifacts from.")
+	flag.StringVar(&o.gcsCredentialsFile, "gcs-credentials-file", "", "File holding the GCS credentials. If unset, the bucket is accessed anonymously.")
+	flag.Var(&o.jobs, "job", "Name of a job whose artifacts to crawl. Can be passed multiple times.")
+	flag.IntVar(&o.maxBuildsPerJob, "max-builds-per-job", 200, "Maximum number of most recent builds to crawl per job. Zero means no limit.")
+	flag.StringVar(&o.kubeconfig, "kubeconfig", "", "Kubeconfig with the build clusters whose events and imagestreams to scan. Every context is scanned.")
+	flag.DurationVar(&o.window, "window", 7*24*time.Hour, "Only failures that happened within this period are reported.")
+	flag.IntVar(&o.imagesPerRegistry, "images-per-registry", 10, "Maximum number of images to print per registry. Zero means no limit. The JSON report always contains all images.")
+	flag.StringVar(&o.outputJSON, "output-json", "", "File to write the JSON report to.")
+	flag.Parse()
+	return o
+}
+
+func (o *options) validate() error {
+	if o.bucket == "" && o.kubeconfig == "" {
+		return errors.New("at least one of --bucket and --kubeconfig must be set")
+	}
+	if o.bucket != "" && len(o.jobs.Strings()) == 0 {
+		return errors.New("--job must be passed at least once when --bucket is set")
+	}
+	if o.window <= 0 {
+		return errors.New("--window must be positive")
+	}
+	return nil
+}
+
+func main() {
+	logrusutil.ComponentInit()
+	o := gatherOptions()
+	if err := o.validate(); err != nil {
+		logrus.WithError(err).Fatal("Invalid options")
+	}
+
+	ctx := context.Background()
+	since := time.Now().Add(-o.window)
+	var failures []failure
+	if o.bucket != "" {
+		clientOption := option.WithoutAuthentication()
+		if o.gcsCredentialsFile != "" {
+			clientOption = option.WithCredentialsFile(o.gcsCredentialsFile)
+		}
+		client, err := storage.NewClient(ctx, clientOption)
+		if err != nil {
+			logrus.WithError(err).Fatal("Failed to create GCS client")
+		}
+		crawled, err := crawl(ctx, jobartifacts.NewBucketSource(client.Bucket(o.bucket)), o.jobs.Strings(), o.maxBuildsPerJob, since)
+		if err != nil {
+			logrus.WithError(err).Error("Failed to crawl all jobs, the report will be incomplete")
+		}
+		failures = append(failures, crawled...)
+	}
+
+	if o.kubeconfig != "" {
+		scanned, err := scanClusters(ctx, o.kubeconfig, since)
+		if err != nil {
+			logrus.WithError(err).Error("Failed to scan all clusters, the report will be incomplete")
+		}
+		failures = append(failures, scanned...)
+	}
+
+	r := aggregate(failures, since)
+	writeTable(os.Stdout, r, o.imagesPerRegistry)
+	if o.outputJSON != "" {
+		if err := writeJSONFile(o.outputJSON, r); err != nil {
+			logrus.WithError(err).Fatal("Failed to write JSON report")
+		}
+	}
+}
+
+func scanClusters(ctx context.Context, kubeconfig string, since time.Time) ([]failure, error) {
+	if err := imagev1.AddToScheme(scheme.Scheme); err != nil {
+		return nil, fmt.Errorf("failed to add imagev1 to scheme: %w", err)
+	}
+	configs, _, err := util.LoadKubeConfigs(kubeconfig, nil)
+	if err != nil {
+		logrus.WithError(err).Warn("Failed to load some kubeconfigs")
+	}
+	if len(configs) == 0 {
+		return nil, errors.New("no kubeconfigs available")
+	}
+	var clusters []string
+	for cluster := range configs {
+		clusters = append(clusters, cluster)
+	}
+	sort.Strings(clusters)
+
+	var result []failure
+	var errs []error
+	for _, cluster := range clusters {
+		client, err := ctrlruntimeclient.New(configs[cluster], ctrlruntimeclient.Options{})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to construct client for cluster %s: %w", cluster, err))
+			continue
+		}
+		logrus.WithField("cluster", cluster).Info("Scanning cluster")
+		failures, err := scanCluster(ctx, cluster, client, since)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		result = append(result, failures...)
+	}
+	return result, utilerrors.NewAggregate(errs)
+}
+
+func writeJSONFile(path string, r *report) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	if err := writeJSON(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	coreapi "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	imagev1 "github.com/openshift/api/image/v1"
+
+	"github.com/openshift/ci-tools/pkg/jobartifacts"
+)
+
+func TestSplitImage(t *testing.T) {
+	var testCases = []struct {
+		pullSpec           string
+		expectedRegistry   string
+		expectedRepository string
+	}{
+		{pullSpec: "quay.io/org/image:tag", expectedRegistry: "quay.io", expectedRepository: "org/image"},
+		{pullSpec: "quay.io/org/image@sha256:abc", expectedRegistry: "quay.io", expectedRepository: "org/image"},
+		{pullSpec: "registry.local:5000/image", expectedRegistry: "registry.local:5000", expectedRepository: "image"},
+		{pullSpec: "localhost/image:latest", expectedRegistry: "localhost", expectedRepository: "image"},
+		{pullSpec: "org/image:tag", expectedRegistry: "docker.io", expectedRepository: "org/image"},
+		{pullSpec: "centos:7", expectedRegistry: "docker.io", expectedRepository: "library/centos"},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.pullSpec, func(t *testing.T) {
+			registry, repository := splitImage(testCase.pullSpec)
+			if registry != testCase.expectedRegistry || repository != testCase.expectedRepository {
+				t.Errorf("expected %s and %s, got %s and %s", testCase.expectedRegistry, testCase.expectedRepository, registry, repository)
+			}
+		})
+	}
+}
+
+func TestFailuresFromEvents(t *testing.T) {
+	since := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	recent := metav1.NewTime(since.Add(time.Hour))
+	events := []coreapi.Event{
+		{
+			Reason:        "Failed",
+			Message:       `Failed to pull image "quay.io/org/image:tag": rpc error: code = Unknown desc = unexpected EOF`,
+			Count:         3,
+			LastTimestamp: recent,
+		},
+		{
+			Reason:        "Failed",
+			Message:       `Failed to pull image "docker.io/library/centos:7": toomanyrequests`,
+			LastTimestamp: metav1.NewTime(since.Add(-time.Hour)),
+		},
+		{
+			Reason:        "BackOff",
+			Message:       `Back-off pulling image "quay.io/org/image:tag"`,
+			LastTimestamp: recent,
+		},
+		{
+			Reason:        "Failed",
+			Message:       "Error: ImagePullBackOff",
+			LastTimestamp: recent,
+		},
+	}
+	expected := []failure{{
+		Kind:    pullFailure,
+		Image:   "quay.io/org/image:tag",
+		Source:  "job/1",
+		Message: `Failed to pull image "quay.io/org/image:tag": rpc error: code = Unknown desc = unexpected EOF`,
+		Count:   3,
+		Time:    recent.Time,
+	}}
+	if diff := cmp.Diff(expected, failuresFromEvents("job/1", events, since)); diff != "" {
+		t.Errorf("failures differ from expected: %s", diff)
+	}
+}
+
+func TestFailuresFromImageStreams(t *testing.T) {
+	since := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	recent := metav1.NewTime(since.Add(time.Hour))
+	streams := []imagev1.ImageStream{{
+		Spec: imagev1.ImageStreamSpec{Tags: []imagev1.TagReference{
+			{Name: "external", From: &coreapi.ObjectReference{Kind: "DockerImage", Name: "registry.example.com/org/image:1"}},
+			{Name: "old", From: &coreapi.ObjectReference{Kind: "DockerImage", Name: "registry.example.com/org/old:1"}},
+			{Name: "internal", From: &coreapi.ObjectReference{Kind: "ImageStreamTag", Name: "other:latest"}},
+		}},
+		Status: imagev1.ImageStreamStatus{Tags: []imagev1.NamedTagEventList{
+			{Tag: "external", Conditions: []imagev1.TagEventCondition{{Type: imagev1.ImportSuccess, Status: coreapi.ConditionFalse, Message: "Internal error occurred: timeout", LastTransitionTime: recent}}},
+			{Tag: "old", Conditions: []imagev1.TagEventCondition{{Type: imagev1.ImportSuccess, Status: coreapi.ConditionFalse, LastTransitionTime: metav1.NewTime(since.Add(-time.Hour))}}},
+			{Tag: "internal", Conditions: []imagev1.TagEventCondition{{Type: imagev1.ImportSuccess, Status: coreapi.ConditionFalse, LastTransitionTime: recent}}},
+		}},
+	}}
+	expected := []failure{{
+		Kind:    importFailure,
+		Image:   "registry.example.com/org/image:1",
+		Source:  "build01/ci-op-1",
+		Message: "Internal error occurred: timeout",
+		Count:   1,
+		Time:    recent.Time,
+	}}
+	if diff := cmp.Diff(expected, failuresFromImageStreams("build01/ci-op-1", streams, since)); diff != "" {
+		t.Errorf("failures differ from expected: %s", diff)
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	since := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	failures := []failure{
+		{Kind: pullFailure, Image: "quay.io/org/a:1", Source: "job/1", Message: "first", Count: 2, Time: since.Add(time.Hour)},
+		{Kind: importFailure, Image: "quay.io/org/a:2", Source: "job/2", Message: "second", Count: 1, Time: since.Add(2 * time.Hour)},
+		{Kind: pullFailure, Image: "quay.io/org/b@sha256:abc", Source: "job/2", Message: "b", Count: 1, Time: since.Add(time.Hour)},
+		{Kind: pullFailure, Image: "centos:7", Source: "job/1", Message: "rate limited", Count: 1, Time: since.Add(time.Hour)},
+	}
+	expected := &report{
+		Since: since,
+		Registries: []registryReport{
+			{
+				Registry:        "quay.io",
+				Failures:        4,
+				AffectedSources: 2,
+				Images: []imageReport{
+					{Image: "quay.io/org/a", Failures: 3, PullFailures: 2, ImportFailures: 1, AffectedSources: 2, LastSeen: since.Add(2 * time.Hour), LastMessage: "second"},
+					{Image: "quay.io/org/b", Failures: 1, PullFailures: 1, AffectedSources: 1, LastSeen: since.Add(time.Hour), LastMessage: "b"},
+				},
+			},
+			{
+				Registry:        "docker.io",
+				Failures:        1,
+				AffectedSources: 1,
+				Images: []imageReport{
+					{Image: "docker.io/library/centos", Failures: 1, PullFailures: 1, AffectedSources: 1, LastSeen: since.Add(time.Hour), LastMessage: "rate limited"},
+				},
+			},
+		},
+	}
+	if diff := cmp.Diff(expected, aggregate(failures, since)); diff != "" {
+		t.Errorf("report differs from expected: %s", diff)
+	}
+}
+
+type fakeSource map[string]string
+
+func (f fakeSource) Builds(_ context.Context, job string) ([]string, error) {
+	return []string{"logs/" + job + "/1"}, nil
+}
+
+func (f fakeSource) Read(_ context.Context, path string) ([]byte, error) {
+	data, ok := f[path]
+	if !ok {
+		return nil, jobartifacts.ErrNotExist
+	}
+	return []byte(data), nil
+}
+
+func TestCrawl(t *testing.T) {
+	since := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	source := fakeSource{
+		"logs/job/1/finished.json":                               `{"timestamp": 1622592000}`,
+		"logs/job/1/artifacts/build-resources/events.json":       `{"items": [{"reason": "Failed", "message": "Failed to pull image \"quay.io/org/a:1\": EOF", "count": 1, "lastTimestamp": "2021-06-01T12:00:00Z"}]}`,
+		"logs/job/1/artifacts/build-resources/imagestreams.json": `{"items": [{"spec": {"tags": [{"name": "a", "from": {"kind": "DockerImage", "name": "quay.io/org/a:2"}}]}, "status": {"tags": [{"tag": "a", "items": null, "conditions": [{"type": "ImportSuccess", "status": "False", "message": "timeout", "lastTransitionTime": "2021-06-01T13:00:00Z", "generation": 1}]}]}}]}`,
+		"logs/running/1/artifacts/build-resources/events.json":   `{"items": [{"reason": "Failed", "message": "Failed to pull image \"quay.io/org/a:1\": EOF", "count": 1, "lastTimestamp": "2021-06-01T12:00:00Z"}]}`,
+	}
+	expected := []failure{
+		{Kind: pullFailure, Image: "quay.io/org/a:1", Source: "job/1", Message: `Failed to pull image "quay.io/org/a:1": EOF`, Count: 1, Time: time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)},
+		{Kind: importFailure, Image: "quay.io/org/a:2", Source: "job/1", Message: "timeout", Count: 1, Time: time.Date(2021, 6, 1, 13, 0, 0, 0, time.UTC)},
+	}
+	actual, err := crawl(context.Background(), source, []string{"job", "running"}, 0, since)
+	if err != nil {
+		t.Fatalf("failed to crawl: %v", err)
+	}
+	if diff := cmp.Diff(expected, actual, cmp.Comparer(func(a, b time.Time) bool { return a.Equal(b) })); diff != "" {
+		t.Errorf("failures differ from expected: %s", diff)
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/kataras/tablewriter"
+)
+
+// imageReport summarizes the failures to retrieve a single image
+type imageReport struct {
+	Image          string `json:"image"`
+	Failures       int    `json:"failures"`
+	PullFailures   int    `json:"pull_failures"`
+	ImportFailures int    `json:"import_failures"`
+	// AffectedSources is the number of distinct builds or namespaces that saw a failure
+	AffectedSources int       `json:"affected_sources"`
+	LastSeen        time.Time `json:"last_seen"`
+	// LastMessage is the message of the most recent failure
+	LastMessage string `json:"last_message"`
+}
+
+// registryReport summarizes the failures to retrieve images from a single registry
+type registryReport struct {
+	Registry        string        `json:"registry"`
+	Failures        int           `json:"failures"`
+	AffectedSources int           `json:"affected_sources"`
+	Images          []imageReport `json:"images"`
+}
+
+// report ranks registries and their images by the number of failures, most failures first
+type report struct {
+	Since      time.Time        `json:"since"`
+	Registries []registryReport `json:"registries"`
+}
+
+func aggregate(failures []failure, since time.Time) *report {
+	type imageKey struct{ registry, image string }
+	images := map[imageKey]*imageReport{}
+	imageSources := map[imageKey]map[string]bool{}
+	registrySources := map[string]map[string]bool{}
+	for _, f := range failures {
+		registry, repository := splitImage(f.Image)
+		key := imageKey{registry: registry, image: registry + "/" + repository}
+		summary, ok := images[key]
+		if !ok {
+			summary = &imageReport{Image: key.image}
+			images[key] = summary
+			imageSources[key] = map[string]bool{}
+		}
+		if _, ok := registrySources[registry]; !ok {
+			registrySources[registry] = map[string]bool{}
+		}
+		summary.Failures += f.Count
+		switch f.Kind {
+		case pullFailure:
+			summary.PullFailures += f.Count
+		case importFailure:
+			summary.ImportFailures += f.Count
+		}
+		if !f.Time.Before(summary.LastSeen) {
+			summary.LastSeen = f.Time
+			summary.LastMessage = f.Message
+		}
+		imageSources[key][f.Source] = true
+		registrySources[registry][f.Source] = true
+	}
+
+	registries := map[string]*registryReport{}
+	for key, summary := range images {
+		summary.AffectedSources = len(imageSources[key])
+		registry, ok := registries[key.registry]
+		if !ok {
+			registry = &registryReport{Registry: key.registry, AffectedSources: len(registrySources[key.registry])}
+			registries[key.registry] = registry
+		}
+		registry.Failures += summary.Failures
+		registry.Images = append(registry.Images, *summary)
+	}
+
+	result := &report{Since: since}
+	for _, registry := range registries {
+		sort.Slice(registry.Images, func(i, j int) bool {
+			if registry.Images[i].Failures != registry.Images[j].Failures {
+				return registry.Images[i].Failures > registry.Images[j].Failures
+			}
+			return registry.Images[i].Image < registry.Images[j].Image
+		})
+		result.Registries = append(result.Registries, *registry)
+	}
+	sort.Slice(result.Registries, func(i, j int) bool {
+		if result.Registries[i].Failures != result.Registries[j].Failures {
+			return result.Registries[i].Failures > result.Registries[j].Failures
+		}
+		return result.Registries[i].Registry < result.Registries[j].Registry
+	})
+	return result
+}
+
+func writeJSON(w io.Writer, r *report) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(r); err != nil {
+		return fmt.Errorf("failed to serialize report: %w", err)
+	}
+	return nil
+}
+
+// writeTable prints the registries and the top images of every registry
+func writeTable(w io.Writer, r *report, imagesPerRegistry int) {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"registry", "image", "failures", "pull", "import", "affected", "last seen"})
+	for _, registry := range r.Registries {
+		table.Append([]string{registry.Registry, "", strconv.Itoa(registry.Failures), "", "", strconv.Itoa(registry.AffectedSources), ""})
+		for i, image := range registry.Images {
+			if imagesPerRegistry > 0 && i >= imagesPerRegistry {
+				break
+			}
+			table.Append([]string{"", image.Image, strconv.Itoa(image.Failures), strconv.Itoa(image.PullFailures), strconv.Itoa(image.ImportFailures), strconv.Itoa(image.AffectedSources), image.LastSeen.Format(time.RFC3339)})
+		}
+	}
+	table.Render()
+}
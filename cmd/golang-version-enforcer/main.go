@@ -0,0 +1,171 @@
+// golang-version-enforcer updates the golang builders in the build_root and the
+// base_images of all ci-operator configs of a release to the golang version the
+// release uses according to ocp-build-data, and optionally opens a PR with the
+// result against openshift/release.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/test-infra/prow/flagutil"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/api/ocpbuilddata"
+	"github.com/openshift/ci-tools/pkg/config"
+	"github.com/openshift/ci-tools/pkg/github/prcreation"
+)
+
+type options struct {
+	ocpBuildDataRepoDir string
+	releaseRepoDir      string
+	minor               string
+	excluded            flagutil.Strings
+	createPR            bool
+	*prcreation.PRCreationOptions
+}
+
+func gatherOptions() (*options, error) {
+	o := &options{PRCreationOptions: &prcreation.PRCreationOptions{}}
+	o.PRCreationOptions.AddFlags(flag.CommandLine)
+	flag.StringVar(&o.ocpBuildDataRepoDir, "ocp-build-data-repo-dir", "../ocp-build-data", "The directory in which the ocp-build-data repository is, with the openshift-4.$minor branch checked out")
+	flag.StringVar(&o.releaseRepoDir, "release-repo-dir", "../release", "The directory in which the release repository is")
+	flag.StringVar(&o.minor, "minor", "", "The minor version of the release whose ci-operator configs are updated")
+	flag.Var(&o.excluded, "exclude", "An org/repo whose ci-operator configs are pinned to a golang version intentionally and are not updated. Can be passed multiple times.")
+	flag.BoolVar(&o.createPR, "create-pr", false, "If the tool should create a PR")
+	flag.Parse()
+
+	if o.minor == "" {
+		return nil, errors.New("--minor is required")
+	}
+	if _, err := strconv.Atoi(o.minor); err != nil {
+		return nil, fmt.Errorf("--minor %q is not a number", o.minor)
+	}
+	for _, excluded := range o.excluded.Strings() {
+		if len(strings.Split(excluded, "/")) != 2 {
+			return nil, fmt.Errorf("--exclude %q is not in org/repo form", excluded)
+		}
+	}
+	if o.createPR {
+		if err := o.PRCreationOptions.Finalize(); err != nil {
+			return nil, fmt.Errorf("failed to finalize pr creation options: %w", err)
+		}
+	}
+	return o, nil
+}
+
+func main() {
+	o, err := gatherOptions()
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to gather options")
+	}
+	majorMinor := ocpbuilddata.MajorMinor{Major: "4", Minor: o.minor}
+	version, err := ocpbuilddata.LoadGoVersion(o.ocpBuildDataRepoDir, majorMinor)
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to determine the golang version of the release")
+	}
+	logrus.Infof("Release %s uses go%s", majorMinor, version)
+
+	configDir := filepath.Join(o.releaseRepoDir, config.CiopConfigInRepoPath)
+	configs, err := config.LoadDataByFilename(configDir)
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to load ci-operator configs")
+	}
+	updated := enforceGoVersion(configs, majorMinor.String(), version, o.excluded.StringSet())
+	if len(updated) == 0 {
+		logrus.Info("All ci-operator configs of the release use the golang version of the release")
+		return
+	}
+	for _, data := range updated {
+		data.Logger().Info("Updating golang builders")
+		if err := data.CommitTo(configDir); err != nil {
+			logrus.WithError(err).Fatalf("Failed to write ci-operator config %s", data.Info.Basename())
+		}
+	}
+	if !o.createPR {
+		return
+	}
+
+	if err := o.PRCreationOptions.UpsertPR(
+		o.releaseRepoDir,
+		"openshift",
+		"release",
+		"master",
+		fmt.Sprintf("Update the golang builders of OCP %s to go%s", majorMinor, version),
+		prcreation.PrBody(strings.Join([]string{
+			"This PR is autogenerated by the [golang-version-enforcer][1].",
+			fmt.Sprintf("It updates the golang builders used in the ci-operator configs of OCP %s to go%s, which", majorMinor, version),
+			"is the version the release uses according to the [ocp-build-data repository][2].",
+			"",
+			"[1]: https://github.com/openshift/ci-tools/tree/master/cmd/golang-version-enforcer",
+			"[2]: https://github.com/openshift/ocp-build-data",
+		}, "\n")),
+	); err != nil {
+		logrus.WithError(err).Fatal("Failed to create PR")
+	}
+}
+
+// isForRelease determines if the configuration promotes to the release or
+// tests with it when it does not promote
+func isForRelease(configuration api.ReleaseBuildConfiguration, release string) bool {
+	if promotion := configuration.PromotionConfiguration; promotion != nil && !promotion.Disabled {
+		return promotion.Namespace == "ocp" && promotion.Name == release
+	}
+	if tagSpec := configuration.ReleaseTagConfiguration; tagSpec != nil {
+		return tagSpec.Namespace == "ocp" && tagSpec.Name == release
+	}
+	return false
+}
+
+// enforceGoVersion returns the configs of the release that use golang builders
+// with a different version, with those replaced by builders of the version.
+// Configs of excluded repositories are left untouched.
+func enforceGoVersion(configs config.DataByFilename, release, version string, excluded sets.String) []config.DataWithInfo {
+	fix := func(reference api.ImageStreamTagReference) (api.ImageStreamTagReference, bool) {
+		if current := ocpbuilddata.GoVersionOf(reference.Tag); current == "" || current == version {
+			return reference, false
+		}
+		reference.Tag = ocpbuilddata.ReplaceGoVersion(reference.Tag, version)
+		return reference, true
+	}
+
+	var updated []config.DataWithInfo
+	for _, data := range configs {
+		if excluded.Has(fmt.Sprintf("%s/%s", data.Info.Org, data.Info.Repo)) || !isForRelease(data.Configuration, release) {
+			continue
+		}
+		var changed bool
+		if buildRoot := data.Configuration.BuildRootImage; buildRoot != nil && buildRoot.ImageStreamTagReference != nil {
+			if fixed, ok := fix(*buildRoot.ImageStreamTagReference); ok {
+				// Copy what we mutate, the config is shared with configs
+				copied := *buildRoot
+				copied.ImageStreamTagReference = &fixed
+				data.Configuration.BuildRootImage = &copied
+				changed = true
+			}
+		}
+		baseImages := make(map[string]api.ImageStreamTagReference, len(data.Configuration.BaseImages))
+		for name, baseImage := range data.Configuration.BaseImages {
+			fixed, ok := fix(baseImage)
+			baseImages[name] = fixed
+			changed = changed || ok
+		}
+		if !changed {
+			continue
+		}
+		if len(baseImages) != 0 {
+			data.Configuration.BaseImages = baseImages
+		}
+		updated = append(updated, data)
+	}
+	sort.Slice(updated, func(i, j int) bool { return updated[i].Info.Basename() < updated[j].Info.Basename() })
+	return updated
+}
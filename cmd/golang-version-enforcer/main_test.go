@@ -0,0 +1,88 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/config"
+)
+
+func TestEnforceGoVersion(t *testing.T) {
+	data := func(org, repo string, configuration api.ReleaseBuildConfiguration) config.DataWithInfo {
+		return config.DataWithInfo{
+			Configuration: configuration,
+			Info:          config.Info{Metadata: api.Metadata{Org: org, Repo: repo, Branch: "release-4.8"}},
+		}
+	}
+	buildRoot := func(tag string) *api.BuildRootImageConfiguration {
+		return &api.BuildRootImageConfiguration{ImageStreamTagReference: &api.ImageStreamTagReference{Namespace: "openshift", Name: "release", Tag: tag}}
+	}
+	promotion := &api.PromotionConfiguration{Namespace: "ocp", Name: "4.8"}
+	builder := func(tag string) api.ImageStreamTagReference {
+		return api.ImageStreamTagReference{Namespace: "ocp", Name: "builder", Tag: tag}
+	}
+
+	configs := config.DataByFilename{
+		"org-outdated-release-4.8.yaml": data("org", "outdated", api.ReleaseBuildConfiguration{
+			InputConfiguration: api.InputConfiguration{
+				BuildRootImage: buildRoot("golang-1.15"),
+				BaseImages: map[string]api.ImageStreamTagReference{
+					"builder": builder("rhel-8-golang-1.15-openshift-4.8"),
+					"base":    {Namespace: "ocp", Name: "4.8", Tag: "base"},
+				},
+			},
+			PromotionConfiguration: promotion,
+		}),
+		"org-tests-release-4.8.yaml": data("org", "tests", api.ReleaseBuildConfiguration{
+			InputConfiguration: api.InputConfiguration{
+				BuildRootImage:          buildRoot("golang-1.15"),
+				ReleaseTagConfiguration: &api.ReleaseTagConfiguration{Namespace: "ocp", Name: "4.8"},
+			},
+		}),
+		"org-current-release-4.8.yaml": data("org", "current", api.ReleaseBuildConfiguration{
+			InputConfiguration: api.InputConfiguration{
+				BuildRootImage: buildRoot("golang-1.16"),
+				BaseImages:     map[string]api.ImageStreamTagReference{"builder": builder("rhel-8-golang-1.16-openshift-4.8")},
+			},
+			PromotionConfiguration: promotion,
+		}),
+		"org-pinned-release-4.8.yaml": data("org", "pinned", api.ReleaseBuildConfiguration{
+			InputConfiguration:     api.InputConfiguration{BuildRootImage: buildRoot("golang-1.13")},
+			PromotionConfiguration: promotion,
+		}),
+		"org-other-release-release-4.7.yaml": data("org", "other-release", api.ReleaseBuildConfiguration{
+			InputConfiguration:     api.InputConfiguration{BuildRootImage: buildRoot("golang-1.15")},
+			PromotionConfiguration: &api.PromotionConfiguration{Namespace: "ocp", Name: "4.7"},
+		}),
+	}
+
+	expected := []config.DataWithInfo{
+		data("org", "outdated", api.ReleaseBuildConfiguration{
+			InputConfiguration: api.InputConfiguration{
+				BuildRootImage: buildRoot("golang-1.16"),
+				BaseImages: map[string]api.ImageStreamTagReference{
+					"builder": builder("rhel-8-golang-1.16-openshift-4.8"),
+					"base":    {Namespace: "ocp", Name: "4.8", Tag: "base"},
+				},
+			},
+			PromotionConfiguration: promotion,
+		}),
+		data("org", "tests", api.ReleaseBuildConfiguration{
+			InputConfiguration: api.InputConfiguration{
+				BuildRootImage:          buildRoot("golang-1.16"),
+				ReleaseTagConfiguration: &api.ReleaseTagConfiguration{Namespace: "ocp", Name: "4.8"},
+			},
+		}),
+	}
+	actual := enforceGoVersion(configs, "4.8", "1.16", sets.NewString("org/pinned"))
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("got incorrect configs: %s", diff)
+	}
+	if tag := configs["org-outdated-release-4.8.yaml"].Configuration.BuildRootImage.ImageStreamTagReference.Tag; tag != "golang-1.15" {
+		t.Errorf("the loaded config was mutated, its build root has the tag %s", tag)
+	}
+}
@@ -1,20 +1,138 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
 
+	"cloud.google.com/go/storage"
 	"github.com/sirupsen/logrus"
+	"google.golang.org/api/option"
+
+	"k8s.io/test-infra/prow/flagutil"
 
 	jobruntimeanalyzer "github.com/openshift/ci-tools/pkg/job-runtime-analyzer"
+	"github.com/openshift/ci-tools/pkg/jobartifacts"
 )
 
 const defaultJobURL = "https://storage.googleapis.com/origin-ci-test/pr-logs/pull/openshift_ci-tools/999/pull-ci-openshift-ci-tools-master-validate-vendor/1283812971092381696"
 
-func main() {
-	jobURL := flag.String("job-url", defaultJobURL, "url to a job")
+type options struct {
+	jobURL string
+
+	bucket             string
+	gcsCredentialsFile string
+	jobs               flagutil.Strings
+	maxBuildsPerJob    int
+	baselineWindow     time.Duration
+	currentWindow      time.Duration
+	threshold          float64
+	minSamples         int
+	outputJSON         string
+	outputHTML         string
+}
+
+func gatherOptions() options {
+	o := options{}
+	flag.StringVar(&o.jobURL, "job-url", defaultJobURL, "url to a job")
+	flag.StringVar(&o.bucket, "bucket", "", "GCS bucket to crawl job artifacts from. If set, the step runtimes of the --job jobs are analyzed for regressions instead of analyzing a single --job-url.")
+	flag.StringVar(&o.gcsCredentialsFile, "gcs-credentials-file", "", "File holding the GCS credentials. If unset, the bucket is accessed anonymously.")
+	flag.Var(&o.jobs, "job", "Name of a job to analyze. Can be passed multiple times.")
+	flag.IntVar(&o.maxBuildsPerJob, "max-builds-per-job", 200, "Maximum number of most recent builds to crawl per job. Zero means no limit.")
+	flag.DurationVar(&o.baselineWindow, "baseline-window", 14*24*time.Hour, "Period before the current window whose runtimes are the baseline.")
+	flag.DurationVar(&o.currentWindow, "current-window", 3*24*time.Hour, "Period whose runtimes are checked for regressions.")
+	flag.Float64Var(&o.threshold, "regression-threshold", 0.2, "Fraction by which the P90 of a step has to increase to be flagged as regression.")
+	flag.IntVar(&o.minSamples, "min-samples", 5, "Minimum number of runtimes a step needs in both windows to be flagged as regression.")
+	flag.StringVar(&o.outputJSON, "output-json", "", "File to write the JSON report to.")
+	flag.StringVar(&o.outputHTML, "output-html", "", "File to write the HTML report to.")
 	flag.Parse()
+	return o
+}
+
+func (o *options) validate() error {
+	if o.bucket == "" {
+		return nil
+	}
+	if len(o.jobs.Strings()) == 0 {
+		return errors.New("--job must be passed at least once when --bucket is set")
+	}
+	if o.baselineWindow <= 0 || o.currentWindow <= 0 {
+		return errors.New("--baseline-window and --current-window must be positive")
+	}
+	if o.threshold < 0 {
+		return errors.New("--regression-threshold must not be negative")
+	}
+	return nil
+}
+
+func main() {
+	o := gatherOptions()
+	if err := o.validate(); err != nil {
+		logrus.WithError(err).Fatal("Invalid options")
+	}
+
+	if o.bucket == "" {
+		if err := jobruntimeanalyzer.Run(o.jobURL); err != nil {
+			logrus.WithError(err).Fatal("Failed")
+		}
+		return
+	}
+
+	ctx := context.Background()
+	clientOption := option.WithoutAuthentication()
+	if o.gcsCredentialsFile != "" {
+		clientOption = option.WithCredentialsFile(o.gcsCredentialsFile)
+	}
+	client, err := storage.NewClient(ctx, clientOption)
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to create GCS client")
+	}
+
+	report, err := jobruntimeanalyzer.Analyze(ctx, jobartifacts.NewBucketSource(client.Bucket(o.bucket)), jobruntimeanalyzer.AnalysisOptions{
+		Jobs:            o.jobs.Strings(),
+		MaxBuildsPerJob: o.maxBuildsPerJob,
+		Now:             time.Now(),
+		CurrentWindow:   o.currentWindow,
+		BaselineWindow:  o.baselineWindow,
+		Threshold:       o.threshold,
+		MinSamples:      o.minSamples,
+	})
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to analyze step runtimes")
+	}
+	for _, regression := range report.Regressions() {
+		logrus.WithFields(logrus.Fields{
+			"repo":         regression.Repo,
+			"step":         regression.Step,
+			"baseline_p90": regression.Baseline.P90,
+			"current_p90":  regression.Current.P90,
+		}).Warn("Step runtime regressed")
+	}
 
-	if err := jobruntimeanalyzer.Run(*jobURL); err != nil {
-		logrus.WithError(err).Fatal("Failed")
+	if o.outputJSON != "" {
+		if err := writeReport(o.outputJSON, report, jobruntimeanalyzer.WriteJSON); err != nil {
+			logrus.WithError(err).Fatal("Failed to write JSON report")
+		}
+	}
+	if o.outputHTML != "" {
+		if err := writeReport(o.outputHTML, report, jobruntimeanalyzer.WriteHTML); err != nil {
+			logrus.WithError(err).Fatal("Failed to write HTML report")
+		}
+	}
+}
+
+func writeReport(path string, report *jobruntimeanalyzer.Report, write func(io.Writer, *jobruntimeanalyzer.Report) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	if err := write(f, report); err != nil {
+		f.Close()
+		return err
 	}
+	return f.Close()
 }
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/api/ocpbuilddata"
+	"github.com/openshift/ci-tools/pkg/config"
+)
+
+// builderMismatch is a golang builder in a ci-operator config whose version differs from
+// the one ART uses to build the image
+type builderMismatch struct {
+	configFile string
+	// field is either build_root or base_images.$name
+	field      string
+	current    api.ImageStreamTagReference
+	ciVersion  string
+	artVersion string
+	// image is the promotion target and artConfig the ocp-build-data config that requires artVersion
+	image     string
+	artConfig string
+}
+
+func (m builderMismatch) String() string {
+	return fmt.Sprintf("%s: %s uses %s with go%s, but ART builds %s with go%s according to %s. Change %s to a go%s builder.",
+		m.configFile, m.field, m.current.ISTagName(), m.ciVersion, m.image, m.artVersion, m.artConfig, m.field, m.artVersion)
+}
+
+// fixed returns the reference with the golang version replaced by the one ART uses
+func (m builderMismatch) fixed() api.ImageStreamTagReference {
+	fixed := m.current
+	fixed.Tag = ocpbuilddata.ReplaceGoVersion(fixed.Tag, m.artVersion)
+	return fixed
+}
+
+// validateBuilderVersions compares the golang versions of the builders of every image ART builds
+// with the ones that the ci-operator config which promotes the same image uses for its base images
+// and its build root. Images for which ART uses more than one golang version are skipped.
+func validateBuilderVersions(majorMinor ocpbuilddata.MajorMinor, imageConfigs []ocpbuilddata.OCPImageConfig, ciConfigs config.DataByFilename) []builderMismatch {
+	ciImages := ciImagesByPromotionTarget(majorMinor, ciConfigs)
+
+	var mismatches []builderMismatch
+	for _, imageConfig := range imageConfigs {
+		if imageConfig.PublicRepo.Org == "openshift-priv" {
+			continue
+		}
+		log := logrus.WithField("file", imageConfig.SourceFileName)
+		promoted, ok := ciImages[imageConfig.PromotesTo()]
+		if !ok {
+			continue
+		}
+		stages, err := imageConfig.Stages()
+		if err != nil {
+			log.WithError(err).Warn("Failed to determine the stages")
+			continue
+		}
+		artVersions := sets.NewString()
+		for _, stage := range stages {
+			if version := ocpbuilddata.GoVersionOf(stage); version != "" {
+				artVersions.Insert(version)
+			}
+		}
+		if artVersions.Len() != 1 {
+			if artVersions.Len() > 1 {
+				log.Debugf("ART uses multiple golang versions %v, not validating the builders", artVersions.List())
+			}
+			continue
+		}
+		artVersion := artVersions.List()[0]
+
+		configuration := ciConfigs[promoted.filename].Configuration
+		mismatch := func(field string, current api.ImageStreamTagReference) {
+			if ciVersion := ocpbuilddata.GoVersionOf(current.Tag); ciVersion != "" && ciVersion != artVersion {
+				mismatches = append(mismatches, builderMismatch{
+					configFile: promoted.filename,
+					field:      field,
+					current:    current,
+					ciVersion:  ciVersion,
+					artVersion: artVersion,
+					image:      imageConfig.PromotesTo(),
+					artConfig:  imageConfig.SourceFileName,
+				})
+			}
+		}
+		if configuration.BuildRootImage != nil && configuration.BuildRootImage.ImageStreamTagReference != nil {
+			mismatch("build_root", *configuration.BuildRootImage.ImageStreamTagReference)
+		}
+		names := sets.NewString(string(promoted.image.From))
+		for name := range promoted.image.Inputs {
+			names.Insert(name)
+		}
+		for _, name := range names.List() {
+			if baseImage, ok := configuration.BaseImages[name]; ok {
+				mismatch("base_images."+name, baseImage)
+			}
+		}
+	}
+
+	sort.Slice(mismatches, func(i, j int) bool {
+		if mismatches[i].configFile != mismatches[j].configFile {
+			return mismatches[i].configFile < mismatches[j].configFile
+		}
+		if mismatches[i].field != mismatches[j].field {
+			return mismatches[i].field < mismatches[j].field
+		}
+		return mismatches[i].image < mismatches[j].image
+	})
+	return mismatches
+}
+
+// fixBuilderVersions updates the builders of the ci-operator configs to the golang version ART uses and
+// returns the updated configs. Fields for which the images of a config require different versions are
+// left untouched, as they can not be fixed automatically.
+func fixBuilderVersions(mismatches []builderMismatch, ciConfigs config.DataByFilename) []config.DataWithInfo {
+	versions := map[string]map[string]sets.String{}
+	for _, mismatch := range mismatches {
+		if versions[mismatch.configFile] == nil {
+			versions[mismatch.configFile] = map[string]sets.String{}
+		}
+		if versions[mismatch.configFile][mismatch.field] == nil {
+			versions[mismatch.configFile][mismatch.field] = sets.NewString()
+		}
+		versions[mismatch.configFile][mismatch.field].Insert(mismatch.artVersion)
+	}
+
+	updated := map[string]config.DataWithInfo{}
+	for _, mismatch := range mismatches {
+		if required := versions[mismatch.configFile][mismatch.field]; required.Len() > 1 {
+			logrus.WithField("file", mismatch.configFile).Warnf("Not updating %s, ART requires the conflicting golang versions %v", mismatch.field, required.List())
+			continue
+		}
+		data, ok := updated[mismatch.configFile]
+		if !ok {
+			data = ciConfigs[mismatch.configFile]
+			// Copy what we mutate, the config is shared with ciConfigs
+			if data.Configuration.BuildRootImage != nil {
+				buildRoot := *data.Configuration.BuildRootImage
+				data.Configuration.BuildRootImage = &buildRoot
+			}
+			baseImages := make(map[string]api.ImageStreamTagReference, len(data.Configuration.BaseImages))
+			for name, baseImage := range data.Configuration.BaseImages {
+				baseImages[name] = baseImage
+			}
+			data.Configuration.BaseImages = baseImages
+		}
+		fixed := mismatch.fixed()
+		if mismatch.field == "build_root" {
+			data.Configuration.BuildRootImage.ImageStreamTagReference = &fixed
+		} else {
+			data.Configuration.BaseImages[strings.TrimPrefix(mismatch.field, "base_images.")] = fixed
+		}
+		updated[mismatch.configFile] = data
+	}
+
+	var result []config.DataWithInfo
+	for _, data := range updated {
+		result = append(result, data)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Info.Basename() < result[j].Info.Basename() })
+	return result
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/ci-tools/pkg/api/ocpbuilddata"
+)
+
+// globalConfigFiles are the files of ocp-build-data that affect all images
+var globalConfigFiles = sets.NewString("streams.yml", "group.yml")
+
+// changedFiles returns the files of the repository that changed since the given ref,
+// including uncommitted changes
+func changedFiles(dir, ref string) (sets.String, error) {
+	out, err := exec.Command("git", "-C", dir, "diff", "--name-only", ref).CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine the files changed since %s: %w, output: %s", ref, err, string(out))
+	}
+	changed := sets.NewString()
+	for _, line := range strings.Split(string(out), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			changed.Insert(line)
+		}
+	}
+	return changed, nil
+}
+
+// filterChangedConfigs returns the configs that are affected by the changed files. These are the
+// configs that changed themselves and the ones that use one of them as a builder or base image
+// through a member reference. If a file that affects all images changed, all configs are returned.
+func filterChangedConfigs(configs []ocpbuilddata.OCPImageConfig, changed sets.String) []ocpbuilddata.OCPImageConfig {
+	if changed.HasAny(globalConfigFiles.UnsortedList()...) {
+		logrus.Infof("One of %v changed, processing all images", globalConfigFiles.List())
+		return configs
+	}
+
+	changedTargets := sets.NewString()
+	for _, config := range configs {
+		if changed.Has(config.SourceFileName) {
+			changedTargets.Insert(config.PromotesTo())
+		}
+	}
+	var filtered []ocpbuilddata.OCPImageConfig
+	for _, config := range configs {
+		if changed.Has(config.SourceFileName) {
+			filtered = append(filtered, config)
+			continue
+		}
+		// Members are dereferenced into the pull spec the image is promoted to
+		stages, _ := config.Stages()
+		if changedTargets.HasAny(stages...) {
+			filtered = append(filtered, config)
+		}
+	}
+	return filtered
+}
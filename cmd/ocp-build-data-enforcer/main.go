@@ -2,12 +2,16 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
 	"io/ioutil"
+	"os"
+	"os/exec"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 
@@ -16,31 +20,49 @@ import (
 	"golang.org/x/sync/errgroup"
 
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/test-infra/prow/config/secret"
+	"k8s.io/test-infra/prow/flagutil"
 	git "k8s.io/test-infra/prow/git/v2"
 
 	"github.com/openshift/imagebuilder"
 	dockercmd "github.com/openshift/imagebuilder/dockerfile/command"
 
 	"github.com/openshift/ci-tools/pkg/api/ocpbuilddata"
+	"github.com/openshift/ci-tools/pkg/config"
 	"github.com/openshift/ci-tools/pkg/github"
 	"github.com/openshift/ci-tools/pkg/github/prcreation"
 )
 
 type options struct {
 	ocpBuildDataRepoDir string
-	majorMinor          ocpbuilddata.MajorMinor
+	minors              flagutil.Strings
 	createPRs           bool
 	prCreationCeiling   int
+	scaffoldConfigs     bool
+	report              bool
+	validateBuilders    bool
+	fixBuilders         bool
+	releaseRepoDir      string
+	sinceRef            string
+	stateFile           string
 	*prcreation.PRCreationOptions
 }
 
 func gatherOptions() (*options, error) {
-	o := &options{PRCreationOptions: &prcreation.PRCreationOptions{}}
+	o := &options{PRCreationOptions: &prcreation.PRCreationOptions{}, minors: flagutil.NewStrings("6")}
 	o.PRCreationOptions.AddFlags(flag.CommandLine)
 	flag.StringVar(&o.ocpBuildDataRepoDir, "ocp-build-data-repo-dir", "../ocp-build-data", "The directory in which the ocp-build-data repository is")
-	flag.StringVar(&o.majorMinor.Minor, "minor", "6", "The minor version to target")
+	flag.Var(&o.minors, "minor", "The minor version to target. Can be passed multiple times, the openshift-4.$minor branch of the ocp-build-data repository is checked out for each.")
 	flag.BoolVar(&o.createPRs, "create-prs", false, "If the tool should create PRs")
 	flag.IntVar(&o.prCreationCeiling, "pr-creation-ceiling", 5, "The maximum number of PRs to upsert")
+	flag.BoolVar(&o.scaffoldConfigs, "scaffold-ci-operator-configs", false, "If the tool should generate ci-operator configs for images that have none instead of updating Dockerfiles")
+	flag.BoolVar(&o.report, "report", false, "If the tool should print a JSON report of the drift between ocp-build-data and the ci-operator configs instead of updating Dockerfiles")
+	flag.BoolVar(&o.validateBuilders, "validate-builder-versions", false, "If the tool should check that the ci-operator configs use the same golang builder versions as ocp-build-data instead of updating Dockerfiles")
+	flag.BoolVar(&o.fixBuilders, "fix-builder-versions", false, "If the tool should update the golang builder versions in the ci-operator configs to the ones in ocp-build-data. Implies --validate-builder-versions")
+	flag.StringVar(&o.releaseRepoDir, "release-repo-dir", "../release", "The directory in which the release repository is, used with --scaffold-ci-operator-configs, --report and --validate-builder-versions")
+	flag.StringVar(&o.sinceRef, "since-ref", "", "If set, only the images whose ocp-build-data configs changed since this git ref are processed. The ocp-build-data repository is used as checked out, so exactly one --minor must be passed.")
+	flag.StringVar(&o.stateFile, "state-file", "", "A file in which state is kept across runs. If set, Dockerfiles that were up to date in the last run are skipped as long as neither they nor their ocp-build-data config change.")
 	flag.Parse()
 
 	if o.createPRs {
@@ -49,31 +71,58 @@ func gatherOptions() (*options, error) {
 		}
 	} else {
 		o.prCreationCeiling = 0
+		if o.AppID != "" || o.AppPrivateKeyPath != "" {
+			if err := o.GitHubOptions.Validate(false); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if o.fixBuilders {
+		o.validateBuilders = true
+	}
+	var modes int
+	for _, enabled := range []bool{o.scaffoldConfigs, o.report, o.validateBuilders} {
+		if enabled {
+			modes++
+		}
+	}
+	if modes > 1 {
+		return nil, errors.New("--scaffold-ci-operator-configs, --report and --validate-builder-versions are mutually exclusive")
+	}
+	for _, minor := range o.minors.Strings() {
+		if _, err := strconv.Atoi(minor); err != nil {
+			return nil, fmt.Errorf("--minor %q is not a number", minor)
+		}
+	}
+	if o.sinceRef != "" && o.minors.StringSet().Len() != 1 {
+		return nil, errors.New("--since-ref requires exactly one --minor")
 	}
 	o.ocpBuildDataRepoDir = filepath.Clean(o.ocpBuildDataRepoDir)
+	o.releaseRepoDir = filepath.Clean(o.releaseRepoDir)
 	return o, nil
 }
 
+// releases returns the releases to target, the newest one last
+func (o *options) releases() []ocpbuilddata.MajorMinor {
+	var releases []ocpbuilddata.MajorMinor
+	for _, minor := range o.minors.StringSet().UnsortedList() {
+		releases = append(releases, ocpbuilddata.MajorMinor{Major: "4", Minor: minor})
+	}
+	sort.Slice(releases, func(i, j int) bool {
+		// Validated in gatherOptions
+		a, _ := strconv.Atoi(releases[i].Minor)
+		b, _ := strconv.Atoi(releases[j].Minor)
+		return a < b
+	})
+	return releases
+}
+
 func main() {
 	logrus.StandardLogger().SetFormatter(&logrus.TextFormatter{EnvironmentOverrideColors: true})
 	opts, err := gatherOptions()
 	if err != nil {
 		logrus.WithError(err).Fatal("Failed to gather options")
 	}
-	opts.majorMinor.Major = "4"
-
-	configs, err := ocpbuilddata.LoadImageConfigs(opts.ocpBuildDataRepoDir, opts.majorMinor)
-	if err != nil {
-		switch err := err.(type) {
-		case utilerrors.Aggregate:
-			for _, err := range err.Errors() {
-				logrus.WithError(err).Error("Encountered error")
-			}
-		default:
-			logrus.WithError(err).Error("Encountered error")
-		}
-		logrus.Fatal("Encountered errors")
-	}
 
 	clientFactory, err := git.NewClientFactory()
 	if err != nil {
@@ -85,35 +134,279 @@ func main() {
 		prCreationOpts: opts.PRCreationOptions,
 	}
 
-	errGroup := &errgroup.Group{}
-	for idx := range configs {
-		idx := idx
-		errGroup.Go(func() error {
-			return processDockerfile(configs[idx], diffProcessor.addDiff)
-		})
+	// Authenticate as GitHub App when getting Dockerfiles, so private repositories can be enforced
+	endpoints := github.EndpointsForHost(opts.Host)
+	getterOpts := []github.Opt{github.WithEndpoints(endpoints)}
+	if opts.AppID != "" {
+		secretAgent := &secret.Agent{}
+		if err := secretAgent.Start(nil); err != nil {
+			logrus.WithError(err).Fatal("Failed to start secret agent")
+		}
+		tokenGenerator, err := github.AppInstallationTokenGenerator(opts.AppID, opts.AppPrivateKeyPath, secretAgent, endpoints)
+		if err != nil {
+			logrus.WithError(err).Fatal("Failed to set up GitHub App authentication")
+		}
+		getterOpts = append(getterOpts, github.WithAppInstallationAuthentication(tokenGenerator))
+	}
+
+	var runState *state
+	var upToDate *upToDateDockerfiles
+	if opts.stateFile != "" {
+		if runState, err = loadState(opts.stateFile); err != nil {
+			logrus.WithError(err).Fatal("Failed to load state")
+		}
+		upToDate = runState.upToDateDockerfiles()
+	}
+
+	var ciConfigs config.DataByFilename
+	if opts.report || opts.validateBuilders {
+		if ciConfigs, err = config.LoadDataByFilename(filepath.Join(opts.releaseRepoDir, config.CiopConfigInRepoPath)); err != nil {
+			logrus.WithError(err).Fatal("Failed to load ci-operator configs")
+		}
+	}
+
+	releases := opts.releases()
+	var summaries []*releaseSummary
+	var reports []driftReport
+	var mismatches []builderMismatch
+	for idx, majorMinor := range releases {
+		log := logrus.WithField("release", majorMinor.String())
+		if opts.sinceRef == "" {
+			if err := checkoutOCPBuildDataBranch(opts.ocpBuildDataRepoDir, majorMinor); err != nil {
+				log.WithError(err).Fatal("Failed to check out ocp-build-data branch")
+			}
+		}
+		configs, err := ocpbuilddata.LoadImageConfigs(opts.ocpBuildDataRepoDir, majorMinor)
+		if err != nil {
+			switch err := err.(type) {
+			case utilerrors.Aggregate:
+				for _, err := range err.Errors() {
+					log.WithError(err).Error("Encountered error")
+				}
+			default:
+				log.WithError(err).Error("Encountered error")
+			}
+			log.Fatal("Encountered errors")
+		}
+		if opts.sinceRef != "" {
+			changed, err := changedFiles(opts.ocpBuildDataRepoDir, opts.sinceRef)
+			if err != nil {
+				log.WithError(err).Fatal("Failed to determine changed files")
+			}
+			configs = filterChangedConfigs(configs, changed)
+			log.Infof("Processing %d images whose configs changed since %s", len(configs), opts.sinceRef)
+		}
+
+		if opts.scaffoldConfigs {
+			if err := scaffoldCIOperatorConfigsMode(opts, majorMinor, configs); err != nil {
+				log.WithError(err).Fatal("Failed to scaffold ci-operator configs")
+			}
+			continue
+		}
+		if opts.report {
+			reports = append(reports, reportDrift(majorMinor, configs, ciConfigs))
+			continue
+		}
+		if opts.validateBuilders {
+			mismatches = append(mismatches, validateBuilderVersions(majorMinor, configs, ciConfigs)...)
+			continue
+		}
+
+		summary := &releaseSummary{release: majorMinor, configs: len(configs)}
+		// PRs for the newest release go to the development branch, for all others to the release branch
+		isNewest := idx == len(releases)-1
+		processor := func(l *logrus.Entry, org, repo, branch, path, configFile string, oldContent, newContent []byte) error {
+			summary.recordDiff(org, repo, branch)
+			return diffProcessor.addDiff(l, majorMinor, org, repo, branch, path, configFile, oldContent, newContent)
+		}
+		errGroup := &errgroup.Group{}
+		for idx := range configs {
+			idx := idx
+			errGroup.Go(func() error {
+				return processDockerfile(configs[idx], majorMinor, isNewest, processor, upToDate, getterOpts...)
+			})
+		}
+		if err := errGroup.Wait(); err != nil {
+			log.WithError(err).Fatal("Processing failed")
+		}
+		summaries = append(summaries, summary)
+	}
+
+	if opts.report {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(reports); err != nil {
+			logrus.WithError(err).Fatal("Failed to write report")
+		}
+		return
 	}
-	if err := errGroup.Wait(); err != nil {
-		logrus.WithError(err).Fatal("Processing failed")
+	if opts.validateBuilders {
+		if err := builderVersionsMode(opts, mismatches, ciConfigs); err != nil {
+			logrus.WithError(err).Fatal("Builder versions are not valid")
+		}
+		return
 	}
 
 	if err := diffProcessor.process(); err != nil {
 		logrus.WithError(err).Fatal("PR creation/diff printing failed")
 	}
+	if runState != nil {
+		runState.update(upToDate)
+		if err := runState.write(opts.stateFile); err != nil {
+			logrus.WithError(err).Fatal("Failed to write state")
+		}
+	}
+
+	for _, summary := range summaries {
+		logrus.WithField("release", summary.release.String()).Infof("Processed %d configs, %d Dockerfiles in %d branches need updating", summary.configs, summary.diffs, len(summary.branches))
+	}
+}
+
+// releaseSummary records the outcome of enforcing a single release
+type releaseSummary struct {
+	release  ocpbuilddata.MajorMinor
+	configs  int
+	lock     sync.Mutex
+	diffs    int
+	branches sets.String
+}
+
+func (s *releaseSummary) recordDiff(org, repo, branch string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.branches == nil {
+		s.branches = sets.NewString()
+	}
+	s.diffs++
+	s.branches.Insert(fmt.Sprintf("%s/%s@%s", org, repo, branch))
+}
+
+// checkoutOCPBuildDataBranch checks out the branch of the given release in the ocp-build-data repository. It
+// uses the local branch if fetching it fails.
+func checkoutOCPBuildDataBranch(dir string, majorMinor ocpbuilddata.MajorMinor) error {
+	branch := "openshift-" + majorMinor.String()
+	ref := "FETCH_HEAD"
+	if out, err := exec.Command("git", "-C", dir, "fetch", "origin", branch).CombinedOutput(); err != nil {
+		logrus.WithError(err).WithField("output", string(out)).Warnf("Failed to fetch %s, using the local branch", branch)
+		ref = branch
+	}
+	if out, err := exec.Command("git", "-C", dir, "checkout", ref).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to check out %s: %w, output: %s", ref, err, string(out))
+	}
+	return nil
+}
+
+func scaffoldCIOperatorConfigsMode(opts *options, majorMinor ocpbuilddata.MajorMinor, configs []ocpbuilddata.OCPImageConfig) error {
+	existing, err := config.LoadDataByFilename(filepath.Join(opts.releaseRepoDir, config.CiopConfigInRepoPath))
+	if err != nil {
+		return fmt.Errorf("failed to load ci-operator configs: %w", err)
+	}
+	generated := scaffoldCIOperatorConfigs(configs, existing)
+	if len(generated) == 0 {
+		logrus.Info("All images have a ci-operator config")
+		return nil
+	}
+	for _, data := range generated {
+		data.Logger().Info("Generating ci-operator config")
+	}
+	if err := writeScaffoldedConfigs(opts.releaseRepoDir, generated); err != nil {
+		return err
+	}
+	if !opts.createPRs {
+		return nil
+	}
 
-	logrus.Infof("Successfully processed %d configs", len(configs))
+	return opts.PRCreationOptions.UpsertPR(
+		opts.releaseRepoDir,
+		"openshift",
+		"release",
+		"master",
+		fmt.Sprintf("Add ci-operator configs for OCP %s images", majorMinor),
+		prcreation.PrBody(strings.Join([]string{
+			"This PR is autogenerated by the [ocp-build-data-enforcer][1].",
+			"It adds ci-operator configs for repositories that build images for OCP according to the",
+			"[ocp-build-data repository][2] but have no ci-operator config yet, so the images get built and",
+			"promoted in CI.",
+			"",
+			"[1]: https://github.com/openshift/ci-tools/tree/master/cmd/ocp-build-data-enforcer",
+			"[2]: https://github.com/openshift/ocp-build-data",
+		}, "\n")),
+	)
 }
 
-type diffProcessorFunc func(l *logrus.Entry, org, repo, branch, path string, oldContent, newContent []byte) error
+func builderVersionsMode(opts *options, mismatches []builderMismatch, ciConfigs config.DataByFilename) error {
+	for _, mismatch := range mismatches {
+		logrus.Error(mismatch.String())
+	}
+	if len(mismatches) == 0 {
+		logrus.Info("All builder versions match ocp-build-data")
+		return nil
+	}
+	if !opts.fixBuilders {
+		return fmt.Errorf("found %d builders with a different golang version than in ocp-build-data", len(mismatches))
+	}
+
+	for _, data := range fixBuilderVersions(mismatches, ciConfigs) {
+		data.Logger().Info("Updating builder versions")
+		if err := data.CommitTo(filepath.Join(opts.releaseRepoDir, config.CiopConfigInRepoPath)); err != nil {
+			return fmt.Errorf("failed to write ci-operator config %s: %w", data.Info.Basename(), err)
+		}
+	}
+	if !opts.createPRs {
+		return nil
+	}
 
-func processDockerfile(config ocpbuilddata.OCPImageConfig, processor diffProcessorFunc) error {
-	log := logrus.WithField("file", config.SourceFileName).WithField("org/repo", config.PublicRepo.String())
+	return opts.PRCreationOptions.UpsertPR(
+		opts.releaseRepoDir,
+		"openshift",
+		"release",
+		"master",
+		"Align golang builder versions with ocp-build-data",
+		prcreation.PrBody(strings.Join([]string{
+			"This PR is autogenerated by the [ocp-build-data-enforcer][1].",
+			"It updates the golang builders used in ci-operator configs to the versions that are used",
+			"to build the same images for releases according to the [ocp-build-data repository][2].",
+			"",
+			"[1]: https://github.com/openshift/ci-tools/tree/master/cmd/ocp-build-data-enforcer",
+			"[2]: https://github.com/openshift/ocp-build-data",
+		}, "\n")),
+	)
+}
+
+type diffProcessorFunc func(l *logrus.Entry, org, repo, branch, path, configFile string, oldContent, newContent []byte) error
+
+// processDockerfile compares the Dockerfile in the release branch of the repository with the config. The diff
+// targets the development branch if isNewest is set and the release branch otherwise.
+func processDockerfile(config ocpbuilddata.OCPImageConfig, majorMinor ocpbuilddata.MajorMinor, isNewest bool, processor diffProcessorFunc, upToDate *upToDateDockerfiles, getterOpts ...github.Opt) error {
+	log := logrus.WithField("file", config.SourceFileName).WithField("org/repo", config.PublicRepo.String()).WithField("release", majorMinor.String())
 	if config.PublicRepo.Org == "openshift-priv" {
 		log.Trace("Ignoring repo in openshift-priv org")
 		return nil
 	}
-	getter := github.FileGetterFactory(config.PublicRepo.Org, config.PublicRepo.Repo, "release-4.6")
-
+	releaseBranch := "release-" + majorMinor.String()
+	if config.Content != nil && config.Content.Source.Git != nil && config.Content.Source.Git.Branch.Taget != "" {
+		releaseBranch = config.Content.Source.Git.Branch.Taget
+	}
 	log = log.WithField("dockerfile", config.Dockerfile())
+
+	var key, fingerprint string
+	if upToDate != nil {
+		blobSHA, err := github.BlobSHAGetterFactory(config.PublicRepo.Org, config.PublicRepo.Repo, releaseBranch, getterOpts...)(config.Dockerfile())
+		if err != nil {
+			return fmt.Errorf("failed to get the blob SHA of the dockerfile: %w", err)
+		}
+		stages, err := config.Stages()
+		if err != nil {
+			return fmt.Errorf("failed to get stages: %w", err)
+		}
+		key, fingerprint = majorMinor.String()+"/"+config.SourceFileName, dockerfileFingerprint(config.Dockerfile(), blobSHA, stages)
+		if upToDate.isUpToDate(key, fingerprint) {
+			log.Trace("Dockerfile is up to date since the last run")
+			return nil
+		}
+	}
+
+	getter := github.FileGetterFactory(config.PublicRepo.Org, config.PublicRepo.Repo, releaseBranch, getterOpts...)
 	data, err := getter(config.Dockerfile())
 	if err != nil {
 		return fmt.Errorf("failed to get dockerfile: %w", err)
@@ -128,13 +421,16 @@ func processDockerfile(config ocpbuilddata.OCPImageConfig, processor diffProcess
 		return fmt.Errorf("failed to update dockerfile: %w", err)
 	}
 	if !hasDiff {
+		if upToDate != nil {
+			upToDate.record(key, fingerprint)
+		}
 		return nil
 	}
-	branch := "master"
-	if config.Content != nil && config.Content.Source.Git != nil && strings.HasPrefix(config.Content.Source.Git.Branch.Taget, "openshift-") {
-		branch = config.Content.Source.Git.Branch.Taget
+	branch := releaseBranch
+	if isNewest && !strings.HasPrefix(releaseBranch, "openshift-") {
+		branch = "master"
 	}
-	if err := processor(log, config.PublicRepo.Org, config.PublicRepo.Repo, branch, config.Dockerfile(), data, updated); err != nil {
+	if err := processor(log, config.PublicRepo.Org, config.PublicRepo.Repo, branch, config.Dockerfile(), config.SourceFileName, data, updated); err != nil {
 		return fmt.Errorf("failed to process updated dockerfile: %w", err)
 	}
 
@@ -222,15 +518,88 @@ type dockerFileReplacment struct {
 }
 
 type diff struct {
-	log        *logrus.Entry
-	org        string
-	repo       string
-	path       string
-	branch     string
+	log    *logrus.Entry
+	org    string
+	repo   string
+	path   string
+	branch string
+	// release and configFile identify the ocp-build-data config that drove the change
+	release    ocpbuilddata.MajorMinor
+	configFile string
 	oldContent []byte
 	newContent []byte
 }
 
+// unified renders the change as a unified diff
+func (d diff) unified() (string, error) {
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(d.oldContent)),
+		B:        difflib.SplitLines(string(d.newContent)),
+		FromFile: "a/" + d.path,
+		ToFile:   "b/" + d.path,
+		Context:  3,
+	})
+}
+
+// repositoryDiffs are all the changes to a branch of a repository, they end up in a single PR
+type repositoryDiffs struct {
+	org    string
+	repo   string
+	branch string
+	diffs  []diff
+}
+
+func (r repositoryDiffs) String() string {
+	return fmt.Sprintf("%s/%s@%s", r.org, r.repo, r.branch)
+}
+
+// title is the title of the PR for the changes. It is used as name of the branch on the fork,
+// so it must be unique per target branch.
+func (r repositoryDiffs) title() string {
+	title := "Updating Dockerfile baseimages to match ocp-build-data config"
+	if r.branch != "master" {
+		title += " on " + r.branch
+	}
+	return title
+}
+
+// prBody explains the changes along with the ocp-build-data configs that drove them
+func (r repositoryDiffs) prBody() (string, error) {
+	lines := []string{
+		"This PR is autogenerated by the [ocp-build-data-enforcer][1].",
+		"It updates the base images in the Dockerfiles used for promotion in order to ensure they",
+		"match the configuration in the [ocp-build-data repository][2] used",
+		"for producing release artifacts.",
+		"",
+	}
+	for _, d := range r.diffs {
+		unified, err := d.unified()
+		if err != nil {
+			return "", fmt.Errorf("failed to construct diff for %s: %w", d.path, err)
+		}
+		lines = append(lines,
+			fmt.Sprintf("#### `%s`", d.path),
+			"",
+			fmt.Sprintf("Driven by [`%s`](https://github.com/openshift/ocp-build-data/blob/openshift-%s/%s) for OCP %s:", d.configFile, d.release, d.configFile, d.release),
+			"",
+			"```diff",
+			strings.TrimSuffix(unified, "\n"),
+			"```",
+			"",
+		)
+	}
+	lines = append(lines,
+		"Instead of merging this PR you can also create an alternate PR that includes the changes found here.",
+		"",
+		"If you believe the content of this PR is incorrect, please contact the dptp team in",
+		"#aos-art.",
+		"",
+		"[1]: https://github.com/openshift/ci-tools/tree/master/cmd/ocp-build-data-enforcer",
+		"[2]: https://github.com/openshift/ocp-build-data",
+	)
+	return strings.Join(lines, "\n"), nil
+}
+
 type diffProcessor struct {
 	lock           sync.Mutex
 	maxPRs         int
@@ -239,84 +608,89 @@ type diffProcessor struct {
 	diffs          []diff
 }
 
-func (dp *diffProcessor) addDiff(l *logrus.Entry, org, repo, branch, path string, oldContent, newContent []byte) error {
+func (dp *diffProcessor) addDiff(l *logrus.Entry, release ocpbuilddata.MajorMinor, org, repo, branch, path, configFile string, oldContent, newContent []byte) error {
 	dp.lock.Lock()
 	defer dp.lock.Unlock()
-	dp.diffs = append(dp.diffs, diff{log: l, org: org, repo: repo, branch: branch, path: path, oldContent: oldContent, newContent: newContent})
+	dp.diffs = append(dp.diffs, diff{log: l, org: org, repo: repo, branch: branch, path: path, release: release, configFile: configFile, oldContent: oldContent, newContent: newContent})
 	return nil
 }
 
-func (dp *diffProcessor) process() error {
-	// In order to be able to make use of the ceiling setting, we need to sort the diffs first
-	sort.Slice(dp.diffs, func(i, j int) bool {
-		return dp.diffs[i].org+dp.diffs[i].repo+dp.diffs[i].repo < dp.diffs[j].org+dp.diffs[j].repo+dp.diffs[j].repo
-	})
+// byRepository groups the diffs by the branch of the repository they target. In order to be able to
+// make use of the ceiling setting, the result is sorted.
+func byRepository(diffs []diff) []repositoryDiffs {
+	grouped := map[string]*repositoryDiffs{}
+	for _, d := range diffs {
+		key := fmt.Sprintf("%s/%s@%s", d.org, d.repo, d.branch)
+		if _, ok := grouped[key]; !ok {
+			grouped[key] = &repositoryDiffs{org: d.org, repo: d.repo, branch: d.branch}
+		}
+		grouped[key].diffs = append(grouped[key].diffs, d)
+	}
+	var result []repositoryDiffs
+	for _, group := range grouped {
+		sort.Slice(group.diffs, func(i, j int) bool { return group.diffs[i].path < group.diffs[j].path })
+		result = append(result, *group)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].String() < result[j].String() })
+	return result
+}
 
-	logrus.Infof("diffs: %d", len(dp.diffs))
-	for _, d := range dp.diffs {
+func (dp *diffProcessor) process() error {
+	repositories := byRepository(dp.diffs)
+	logrus.Infof("diffs: %d in %d repository branches", len(dp.diffs), len(repositories))
+	for _, r := range repositories {
 		// Closure so we can use defer to clean up the git client
-		if err := func(d diff) error {
-			// Just print the diff
+		if err := func(r repositoryDiffs) error {
+			log := logrus.WithField("org", r.org).WithField("repo", r.repo).WithField("branch", r.branch)
+			// Just print the diffs
 			if dp.maxPRs == 0 {
-				diff := difflib.UnifiedDiff{
-					A:        difflib.SplitLines(string(d.oldContent)),
-					B:        difflib.SplitLines(string(d.newContent)),
-					FromFile: "original",
-					ToFile:   "updated",
-					Context:  3,
-				}
-				diffStr, err := difflib.GetUnifiedDiffString(diff)
-				if err != nil {
-					return fmt.Errorf("failed to construct diff: %w", err)
+				for _, d := range r.diffs {
+					unified, err := d.unified()
+					if err != nil {
+						return fmt.Errorf("failed to construct diff: %w", err)
+					}
+					d.log.Infof("Diff:\n---\n%s\n---\n", unified)
 				}
-				d.log.Infof("Diff:\n---\n%s\n---\n", diffStr)
 				return nil
 			}
 
 			// Create PR
 			dp.maxPRs--
-			gitClient, err := dp.gitClient.ClientFor(d.org, d.repo)
+			gitClient, err := dp.gitClient.ClientFor(r.org, r.repo)
 			if err != nil {
 				return fmt.Errorf("Failed to get git client: %w", err)
 			}
 			defer func() {
 				if err := gitClient.Clean(); err != nil {
-					d.log.WithError(err).Error("Gitclient clean failed")
+					log.WithError(err).Error("Gitclient clean failed")
 				}
 			}()
 
-			if err := gitClient.Checkout(d.branch); err != nil {
-				return fmt.Errorf("failed to checkout %s branch: %w", d.branch, err)
+			if err := gitClient.Checkout(r.branch); err != nil {
+				return fmt.Errorf("failed to checkout %s branch: %w", r.branch, err)
 			}
-			if err := ioutil.WriteFile(filepath.Join(gitClient.Directory(), d.path), d.newContent, 0644); err != nil {
-				return fmt.Errorf("failed to write updated Dockerfile into repo: %w", err)
+			for _, d := range r.diffs {
+				if err := ioutil.WriteFile(filepath.Join(gitClient.Directory(), d.path), d.newContent, 0644); err != nil {
+					return fmt.Errorf("failed to write updated Dockerfile %s into repo: %w", d.path, err)
+				}
+			}
+			body, err := r.prBody()
+			if err != nil {
+				return err
 			}
 			if err := dp.prCreationOpts.UpsertPR(
 				gitClient.Directory(),
-				d.org,
-				d.repo,
-				d.branch,
-				fmt.Sprintf("Updating %s baseimages to match ocp-build-data config", d.path),
-				prcreation.PrBody(strings.Join([]string{
-					"This PR is autogenerated by the [ocp-build-data-enforcer][1].",
-					"It updates the base images in the Dockerfile used for promotion in order to ensure it",
-					"matches the configuration in the [ocp-build-data repository][2] used",
-					"for producing release artifacts.",
-					"",
-					"Instead of merging this PR you can also create an alternate PR that includes the changes found here.",
-					"",
-					"If you believe the content of this PR is incorrect, please contact the dptp team in",
-					"#aos-art.",
-					"",
-					"[1]: https://github.com/openshift/ci-tools/tree/master/cmd/ocp-build-data-enforcer",
-					"[2]: https://github.com/openshift/ocp-build-data/tree/openshift-4.6/images",
-				}, "\n")),
+				r.org,
+				r.repo,
+				r.branch,
+				r.title(),
+				prcreation.PrBody(body),
 			); err != nil {
-				return fmt.Errorf("failed to create PR: %w", err)
+				return fmt.Errorf("failed to create PR for %s: %w", r, err)
 			}
 
 			return nil
-		}(d); err != nil {
+		}(r); err != nil {
 			return err
 		}
 	}
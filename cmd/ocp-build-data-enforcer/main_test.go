@@ -1,11 +1,17 @@
 package main
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/ci-tools/pkg/api"
 	"github.com/openshift/ci-tools/pkg/api/ocpbuilddata"
+	"github.com/openshift/ci-tools/pkg/config"
 )
 
 func TestUpdateDockerfile(t *testing.T) {
@@ -144,3 +150,366 @@ FROM replacement-2
 		})
 	}
 }
+
+func TestScaffoldCIOperatorConfigs(t *testing.T) {
+	imageConfig := func(name, org, repo, builder string) ocpbuilddata.OCPImageConfig {
+		return ocpbuilddata.OCPImageConfig{
+			Name: name,
+			Content: &ocpbuilddata.OCPImageConfigContent{Source: ocpbuilddata.OCPImageConfigSource{
+				Dockerfile: "Dockerfile.rhel",
+				Path:       "images/" + name,
+				Git:        &ocpbuilddata.OCPImageConfigSourceGit{Branch: ocpbuilddata.OCPImageConfigSourceGitBRanch{Taget: "release-4.8"}},
+			}},
+			From: ocpbuilddata.OCPImageConfigFrom{
+				Builder:                  []ocpbuilddata.OCPImageConfigFromStream{{Stream: builder}},
+				OCPImageConfigFromStream: ocpbuilddata.OCPImageConfigFromStream{Stream: "registry.ci.openshift.org/ocp/4.8:base"},
+			},
+			Version:    ocpbuilddata.MajorMinor{Major: "4", Minor: "8"},
+			PublicRepo: ocpbuilddata.OrgRepo{Org: org, Repo: repo},
+		}
+	}
+	const builder = "registry.ci.openshift.org/ocp/builder:rhel-8-golang-1.15-openshift-4.8"
+	imageConfigs := []ocpbuilddata.OCPImageConfig{
+		imageConfig("openshift/ose-has-config", "openshift", "has-config", builder),
+		imageConfig("openshift/ose-second", "openshift", "new", builder),
+		imageConfig("openshift/ose-first", "openshift", "new", builder),
+		imageConfig("openshift/ose-private", "openshift-priv", "private", builder),
+		imageConfig("openshift/ose-unknown-builder", "openshift", "unknown-builder", "quay.io/org/builder:latest"),
+	}
+	existing := config.DataByFilename{
+		"openshift-has-config-release-4.8.yaml": {Info: config.Info{Metadata: api.Metadata{Org: "openshift", Repo: "has-config", Branch: "release-4.8"}}},
+	}
+
+	metadata := api.Metadata{Org: "openshift", Repo: "new", Branch: "release-4.8"}
+	expected := []config.DataWithInfo{{
+		Info: config.Info{Metadata: metadata},
+		Configuration: api.ReleaseBuildConfiguration{
+			Metadata: metadata,
+			InputConfiguration: api.InputConfiguration{
+				BuildRootImage: &api.BuildRootImageConfiguration{ImageStreamTagReference: &api.ImageStreamTagReference{
+					Namespace: "ocp",
+					Name:      "builder",
+					Tag:       "rhel-8-golang-1.15-openshift-4.8",
+				}},
+				ReleaseTagConfiguration: &api.ReleaseTagConfiguration{Namespace: "ocp", Name: "4.8"},
+			},
+			Images: []api.ProjectDirectoryImageBuildStepConfiguration{
+				{
+					To:                               "first",
+					ProjectDirectoryImageBuildInputs: api.ProjectDirectoryImageBuildInputs{ContextDir: "images/openshift/ose-first", DockerfilePath: "Dockerfile.rhel"},
+				},
+				{
+					To:                               "second",
+					ProjectDirectoryImageBuildInputs: api.ProjectDirectoryImageBuildInputs{ContextDir: "images/openshift/ose-second", DockerfilePath: "Dockerfile.rhel"},
+				},
+			},
+			PromotionConfiguration: &api.PromotionConfiguration{Namespace: "ocp", Name: "4.8"},
+			Resources: map[string]api.ResourceRequirements{"*": {
+				Limits:   map[string]string{"memory": "4Gi"},
+				Requests: map[string]string{"memory": "200Mi", "cpu": "100m"},
+			}},
+		},
+	}}
+	if diff := cmp.Diff(expected, scaffoldCIOperatorConfigs(imageConfigs, existing)); diff != "" {
+		t.Errorf("generated configs differ from expected: %s", diff)
+	}
+}
+
+func TestReleases(t *testing.T) {
+	opts := &options{}
+	for _, minor := range []string{"10", "8", "9", "8"} {
+		if err := opts.minors.Set(minor); err != nil {
+			t.Fatalf("failed to set minor: %v", err)
+		}
+	}
+	expected := []ocpbuilddata.MajorMinor{{Major: "4", Minor: "8"}, {Major: "4", Minor: "9"}, {Major: "4", Minor: "10"}}
+	if diff := cmp.Diff(expected, opts.releases()); diff != "" {
+		t.Errorf("releases differ from expected: %s", diff)
+	}
+}
+
+func TestReportDrift(t *testing.T) {
+	const builder = "registry.ci.openshift.org/ocp/builder:rhel-8-golang-1.15-openshift-4.8"
+	const base = "registry.ci.openshift.org/ocp/4.8:base"
+	imageConfig := func(name, repo, path string) ocpbuilddata.OCPImageConfig {
+		return ocpbuilddata.OCPImageConfig{
+			Name:           name,
+			SourceFileName: "images/" + name + ".yml",
+			Content:        &ocpbuilddata.OCPImageConfigContent{Source: ocpbuilddata.OCPImageConfigSource{Path: path}},
+			From: ocpbuilddata.OCPImageConfigFrom{
+				Builder:                  []ocpbuilddata.OCPImageConfigFromStream{{Stream: builder}},
+				OCPImageConfigFromStream: ocpbuilddata.OCPImageConfigFromStream{Stream: base},
+			},
+			Version:    ocpbuilddata.MajorMinor{Major: "4", Minor: "8"},
+			PublicRepo: ocpbuilddata.OrgRepo{Org: "openshift", Repo: repo},
+		}
+	}
+	imageConfigs := []ocpbuilddata.OCPImageConfig{
+		imageConfig("openshift/ose-in-sync", "repo", "in-sync"),
+		imageConfig("openshift/ose-drifted", "repo", "drifted"),
+		imageConfig("openshift/ose-only-art", "repo", "only-art"),
+		imageConfig("openshift/ose-private", "repo", "private"),
+	}
+	imageConfigs[3].PublicRepo.Org = "openshift-priv"
+
+	image := func(name, contextDir string, inputs ...string) api.ProjectDirectoryImageBuildStepConfiguration {
+		image := api.ProjectDirectoryImageBuildStepConfiguration{
+			From:                             "base",
+			To:                               api.PipelineImageStreamTagReference(name),
+			ProjectDirectoryImageBuildInputs: api.ProjectDirectoryImageBuildInputs{ContextDir: contextDir, Inputs: map[string]api.ImageBuildInputs{}},
+		}
+		for _, input := range inputs {
+			image.Inputs[input] = api.ImageBuildInputs{As: []string{input}}
+		}
+		return image
+	}
+	ciConfigs := config.DataByFilename{
+		"openshift-repo-master.yaml": {
+			Info: config.Info{Metadata: api.Metadata{Org: "openshift", Repo: "repo", Branch: "master"}},
+			Configuration: api.ReleaseBuildConfiguration{
+				InputConfiguration: api.InputConfiguration{BaseImages: map[string]api.ImageStreamTagReference{
+					"base":    {Namespace: "ocp", Name: "4.8", Tag: "base"},
+					"builder": {Namespace: "ocp", Name: "builder", Tag: "rhel-8-golang-1.15-openshift-4.8"},
+				}},
+				Images: []api.ProjectDirectoryImageBuildStepConfiguration{
+					image("in-sync", "in-sync", "builder"),
+					image("drifted", "elsewhere"),
+					image("only-ci", "only-ci", "builder"),
+				},
+				PromotionConfiguration: &api.PromotionConfiguration{Namespace: "ocp", Name: "4.8"},
+			},
+		},
+		"openshift-other-release-4.7.yaml": {
+			Info: config.Info{Metadata: api.Metadata{Org: "openshift", Repo: "other", Branch: "release-4.7"}},
+			Configuration: api.ReleaseBuildConfiguration{
+				Images:                 []api.ProjectDirectoryImageBuildStepConfiguration{image("in-sync", "")},
+				PromotionConfiguration: &api.PromotionConfiguration{Namespace: "ocp", Name: "4.7"},
+			},
+		},
+	}
+
+	artImageFor := func(name, path string) *artImage {
+		return &artImage{
+			Name:       "openshift/ose-" + name,
+			ConfigFile: "images/openshift/ose-" + name + ".yml",
+			Repository: "openshift/repo",
+			Dockerfile: path + "/Dockerfile",
+			Builders:   []string{base, builder},
+		}
+	}
+	ciImageFor := func(name, contextDir string, builders ...string) *ciImage {
+		return &ciImage{
+			Name:       name,
+			ConfigFile: "openshift-repo-master.yaml",
+			Repository: "openshift/repo",
+			Branch:     "master",
+			Dockerfile: contextDir + "/Dockerfile",
+			Builders:   builders,
+		}
+	}
+	expected := driftReport{
+		Release: "4.8",
+		Images: []imageDrift{
+			{
+				PromotesTo: "registry.ci.openshift.org/ocp/4.8:drifted",
+				ART:        artImageFor("drifted", "drifted"),
+				CI:         ciImageFor("drifted", "elsewhere", base),
+				Drift:      []string{driftDockerfile, driftBuilders},
+			},
+			{
+				PromotesTo: "registry.ci.openshift.org/ocp/4.8:in-sync",
+				ART:        artImageFor("in-sync", "in-sync"),
+				CI:         ciImageFor("in-sync", "in-sync", base, builder),
+			},
+			{
+				PromotesTo: "registry.ci.openshift.org/ocp/4.8:only-art",
+				ART:        artImageFor("only-art", "only-art"),
+				Drift:      []string{driftOnlyInART},
+			},
+			{
+				PromotesTo: "registry.ci.openshift.org/ocp/4.8:only-ci",
+				CI:         ciImageFor("only-ci", "only-ci", base, builder),
+				Drift:      []string{driftOnlyInCI},
+			},
+		},
+	}
+	if diff := cmp.Diff(expected, reportDrift(ocpbuilddata.MajorMinor{Major: "4", Minor: "8"}, imageConfigs, ciConfigs)); diff != "" {
+		t.Errorf("report differs from expected: %s", diff)
+	}
+}
+
+func TestBuilderVersions(t *testing.T) {
+	version := ocpbuilddata.MajorMinor{Major: "4", Minor: "8"}
+	imageConfig := func(name string, builders ...string) ocpbuilddata.OCPImageConfig {
+		config := ocpbuilddata.OCPImageConfig{
+			Name:           "openshift/ose-" + name,
+			SourceFileName: "images/" + name + ".yml",
+			Content:        &ocpbuilddata.OCPImageConfigContent{},
+			From:           ocpbuilddata.OCPImageConfigFrom{OCPImageConfigFromStream: ocpbuilddata.OCPImageConfigFromStream{Stream: "registry.ci.openshift.org/ocp/4.8:base"}},
+			Version:        version,
+			PublicRepo:     ocpbuilddata.OrgRepo{Org: "openshift", Repo: name},
+		}
+		for _, builder := range builders {
+			config.From.Builder = append(config.From.Builder, ocpbuilddata.OCPImageConfigFromStream{Stream: "registry.ci.openshift.org/ocp/builder:" + builder})
+		}
+		return config
+	}
+	imageConfigs := []ocpbuilddata.OCPImageConfig{
+		imageConfig("matching", "rhel-8-golang-1.16-openshift-4.8"),
+		imageConfig("outdated", "rhel-8-golang-1.16-openshift-4.8"),
+		imageConfig("multiple-versions", "rhel-8-golang-1.15-openshift-4.8", "rhel-8-golang-1.16-openshift-4.8"),
+		imageConfig("conflicting-a", "rhel-8-golang-1.16-openshift-4.8"),
+		imageConfig("conflicting-b", "rhel-8-golang-1.15-openshift-4.8"),
+	}
+
+	ciConfig := func(org, repo string, buildRoot string, images ...string) config.DataWithInfo {
+		data := config.DataWithInfo{
+			Info: config.Info{Metadata: api.Metadata{Org: org, Repo: repo, Branch: "master"}},
+			Configuration: api.ReleaseBuildConfiguration{
+				InputConfiguration: api.InputConfiguration{
+					BuildRootImage: &api.BuildRootImageConfiguration{ImageStreamTagReference: &api.ImageStreamTagReference{Namespace: "ocp", Name: "builder", Tag: buildRoot}},
+					BaseImages: map[string]api.ImageStreamTagReference{
+						"base":    {Namespace: "ocp", Name: "4.8", Tag: "base"},
+						"builder": {Namespace: "ocp", Name: "builder", Tag: buildRoot},
+					},
+				},
+				PromotionConfiguration: &api.PromotionConfiguration{Namespace: "ocp", Name: "4.8"},
+			},
+		}
+		for _, image := range images {
+			data.Configuration.Images = append(data.Configuration.Images, api.ProjectDirectoryImageBuildStepConfiguration{
+				From:                             "base",
+				To:                               api.PipelineImageStreamTagReference(image),
+				ProjectDirectoryImageBuildInputs: api.ProjectDirectoryImageBuildInputs{Inputs: map[string]api.ImageBuildInputs{"builder": {As: []string{"builder"}}}},
+			})
+		}
+		return data
+	}
+	ciConfigs := config.DataByFilename{
+		"openshift-matching-master.yaml":    ciConfig("openshift", "matching", "rhel-8-golang-1.16-openshift-4.8", "matching"),
+		"openshift-outdated-master.yaml":    ciConfig("openshift", "outdated", "rhel-8-golang-1.15-openshift-4.8", "outdated"),
+		"openshift-multiple-master.yaml":    ciConfig("openshift", "multiple", "rhel-8-golang-1.14-openshift-4.8", "multiple-versions"),
+		"openshift-conflicting-master.yaml": ciConfig("openshift", "conflicting", "rhel-8-golang-1.14-openshift-4.8", "conflicting-a", "conflicting-b"),
+	}
+
+	mismatches := validateBuilderVersions(version, imageConfigs, ciConfigs)
+	var messages []string
+	for _, mismatch := range mismatches {
+		messages = append(messages, mismatch.String())
+	}
+	expectedMessages := []string{
+		"openshift-conflicting-master.yaml: base_images.builder uses ocp/builder:rhel-8-golang-1.14-openshift-4.8 with go1.14, but ART builds registry.ci.openshift.org/ocp/4.8:conflicting-a with go1.16 according to images/conflicting-a.yml. Change base_images.builder to a go1.16 builder.",
+		"openshift-conflicting-master.yaml: base_images.builder uses ocp/builder:rhel-8-golang-1.14-openshift-4.8 with go1.14, but ART builds registry.ci.openshift.org/ocp/4.8:conflicting-b with go1.15 according to images/conflicting-b.yml. Change base_images.builder to a go1.15 builder.",
+		"openshift-conflicting-master.yaml: build_root uses ocp/builder:rhel-8-golang-1.14-openshift-4.8 with go1.14, but ART builds registry.ci.openshift.org/ocp/4.8:conflicting-a with go1.16 according to images/conflicting-a.yml. Change build_root to a go1.16 builder.",
+		"openshift-conflicting-master.yaml: build_root uses ocp/builder:rhel-8-golang-1.14-openshift-4.8 with go1.14, but ART builds registry.ci.openshift.org/ocp/4.8:conflicting-b with go1.15 according to images/conflicting-b.yml. Change build_root to a go1.15 builder.",
+		"openshift-outdated-master.yaml: base_images.builder uses ocp/builder:rhel-8-golang-1.15-openshift-4.8 with go1.15, but ART builds registry.ci.openshift.org/ocp/4.8:outdated with go1.16 according to images/outdated.yml. Change base_images.builder to a go1.16 builder.",
+		"openshift-outdated-master.yaml: build_root uses ocp/builder:rhel-8-golang-1.15-openshift-4.8 with go1.15, but ART builds registry.ci.openshift.org/ocp/4.8:outdated with go1.16 according to images/outdated.yml. Change build_root to a go1.16 builder.",
+	}
+	if diff := cmp.Diff(expectedMessages, messages); diff != "" {
+		t.Errorf("mismatches differ from expected: %s", diff)
+	}
+
+	fixed := fixBuilderVersions(mismatches, ciConfigs)
+	expectedFixed := ciConfig("openshift", "outdated", "rhel-8-golang-1.16-openshift-4.8", "outdated")
+	if diff := cmp.Diff([]config.DataWithInfo{expectedFixed}, fixed); diff != "" {
+		t.Errorf("fixed configs differ from expected: %s", diff)
+	}
+	if tag := ciConfigs["openshift-outdated-master.yaml"].Configuration.BuildRootImage.ImageStreamTagReference.Tag; tag != "rhel-8-golang-1.15-openshift-4.8" {
+		t.Errorf("expected the loaded config to be unchanged, but its build root is %s", tag)
+	}
+}
+
+func TestByRepository(t *testing.T) {
+	release := ocpbuilddata.MajorMinor{Major: "4", Minor: "8"}
+	diffs := []diff{
+		{org: "openshift", repo: "b", branch: "master", path: "Dockerfile", release: release, configFile: "images/b.yml", oldContent: []byte("FROM old\n"), newContent: []byte("FROM new\n")},
+		{org: "openshift", repo: "a", branch: "master", path: "images/two/Dockerfile", release: release, configFile: "images/a-two.yml", oldContent: []byte("FROM old\n"), newContent: []byte("FROM new\n")},
+		{org: "openshift", repo: "a", branch: "release-4.7", path: "Dockerfile", release: release, configFile: "images/a.yml", oldContent: []byte("FROM old\n"), newContent: []byte("FROM new\n")},
+		{org: "openshift", repo: "a", branch: "master", path: "Dockerfile", release: release, configFile: "images/a.yml", oldContent: []byte("FROM builder AS builder\nFROM old\nCOPY --from=builder /bin /bin\n"), newContent: []byte("FROM builder AS builder\nFROM new\nCOPY --from=builder /bin /bin\n")},
+	}
+
+	repositories := byRepository(diffs)
+	var actual []string
+	for _, r := range repositories {
+		var paths []string
+		for _, d := range r.diffs {
+			paths = append(paths, d.path)
+		}
+		actual = append(actual, fmt.Sprintf("%s: %s", r.title(), strings.Join(paths, ",")))
+	}
+	expected := []string{
+		"Updating Dockerfile baseimages to match ocp-build-data config: Dockerfile,images/two/Dockerfile",
+		"Updating Dockerfile baseimages to match ocp-build-data config on release-4.7: Dockerfile",
+		"Updating Dockerfile baseimages to match ocp-build-data config: Dockerfile",
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Fatalf("repositories differ from expected: %s", diff)
+	}
+
+	body, err := repositories[0].prBody()
+	if err != nil {
+		t.Fatalf("failed to render PR body: %v", err)
+	}
+	for _, expected := range []string{
+		"#### `Dockerfile`\n\nDriven by [`images/a.yml`](https://github.com/openshift/ocp-build-data/blob/openshift-4.8/images/a.yml) for OCP 4.8:\n\n```diff\n--- a/Dockerfile\n+++ b/Dockerfile\n@@ -1,4 +1,4 @@\n FROM builder AS builder\n-FROM old\n+FROM new\n COPY --from=builder /bin /bin\n \n```\n",
+		"#### `images/two/Dockerfile`\n\nDriven by [`images/a-two.yml`](https://github.com/openshift/ocp-build-data/blob/openshift-4.8/images/a-two.yml) for OCP 4.8:",
+	} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("expected PR body to contain %q, got:\n%s", expected, body)
+		}
+	}
+}
+
+func TestFilterChangedConfigs(t *testing.T) {
+	version := ocpbuilddata.MajorMinor{Major: "4", Minor: "8"}
+	imageConfig := func(name, base string) ocpbuilddata.OCPImageConfig {
+		return ocpbuilddata.OCPImageConfig{
+			Name:           "openshift/ose-" + name,
+			SourceFileName: "images/" + name + ".yml",
+			From:           ocpbuilddata.OCPImageConfigFrom{OCPImageConfigFromStream: ocpbuilddata.OCPImageConfigFromStream{Stream: base}},
+			Version:        version,
+		}
+	}
+	configs := []ocpbuilddata.OCPImageConfig{
+		imageConfig("base", "registry.ci.openshift.org/ocp/builder:rhel-8-base"),
+		imageConfig("uses-base", "registry.ci.openshift.org/ocp/4.8:base"),
+		imageConfig("unrelated", "registry.ci.openshift.org/ocp/builder:rhel-8-base"),
+	}
+
+	testCases := []struct {
+		name     string
+		changed  sets.String
+		expected []string
+	}{
+		{
+			name:    "nothing changed",
+			changed: sets.NewString(),
+		},
+		{
+			name:     "image without dependents changed",
+			changed:  sets.NewString("images/unrelated.yml", "README.md"),
+			expected: []string{"images/unrelated.yml"},
+		},
+		{
+			name:     "member of another image changed",
+			changed:  sets.NewString("images/base.yml"),
+			expected: []string{"images/base.yml", "images/uses-base.yml"},
+		},
+		{
+			name:     "streams changed",
+			changed:  sets.NewString("streams.yml"),
+			expected: []string{"images/base.yml", "images/uses-base.yml", "images/unrelated.yml"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var actual []string
+			for _, config := range filterChangedConfigs(configs, tc.changed) {
+				actual = append(actual, config.SourceFileName)
+			}
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Errorf("filtered configs differ from expected: %s", diff)
+			}
+		})
+	}
+}
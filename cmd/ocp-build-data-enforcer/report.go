@@ -0,0 +1,191 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/api/ocpbuilddata"
+	"github.com/openshift/ci-tools/pkg/config"
+	"github.com/openshift/ci-tools/pkg/steps/release"
+)
+
+// The kinds of drift between ocp-build-data and the ci-operator configs
+const (
+	driftOnlyInART  = "only_in_art"
+	driftOnlyInCI   = "only_in_ci"
+	driftRepository = "repository"
+	driftDockerfile = "dockerfile_path"
+	driftBuilders   = "builders"
+)
+
+// driftReport compares the images of one release in ocp-build-data with the images the
+// ci-operator configs promote into the same release
+type driftReport struct {
+	Release string       `json:"release"`
+	Images  []imageDrift `json:"images"`
+}
+
+// imageDrift compares how one image of the release is built by ART and by CI. Drift holds
+// the kinds of differences, it is empty if both sides agree.
+type imageDrift struct {
+	// PromotesTo is the pull spec of the image in the CI registry
+	PromotesTo string    `json:"promotes_to"`
+	ART        *artImage `json:"art,omitempty"`
+	CI         *ciImage  `json:"ci,omitempty"`
+	Drift      []string  `json:"drift,omitempty"`
+}
+
+type artImage struct {
+	Name       string   `json:"name"`
+	ConfigFile string   `json:"config_file"`
+	Repository string   `json:"repository"`
+	Branch     string   `json:"branch,omitempty"`
+	Dockerfile string   `json:"dockerfile"`
+	Builders   []string `json:"builders"`
+}
+
+type ciImage struct {
+	Name       string   `json:"name"`
+	ConfigFile string   `json:"config_file"`
+	Repository string   `json:"repository"`
+	Branch     string   `json:"branch"`
+	Dockerfile string   `json:"dockerfile"`
+	Builders   []string `json:"builders"`
+}
+
+// reportDrift builds the drift report for a release. Builders are compared as the set of
+// pull specs in the CI registry both sides use for the stages of the Dockerfile.
+func reportDrift(majorMinor ocpbuilddata.MajorMinor, imageConfigs []ocpbuilddata.OCPImageConfig, ciConfigs config.DataByFilename) driftReport {
+	byTarget := map[string]*imageDrift{}
+	for _, imageConfig := range imageConfigs {
+		if imageConfig.PublicRepo.Org == "openshift-priv" {
+			continue
+		}
+		stages, err := imageConfig.Stages()
+		if err != nil {
+			logrus.WithField("file", imageConfig.SourceFileName).WithError(err).Warn("Failed to determine the stages")
+		}
+		image := &artImage{
+			Name:       imageConfig.Name,
+			ConfigFile: imageConfig.SourceFileName,
+			Repository: imageConfig.PublicRepo.String(),
+			Dockerfile: imageConfig.Dockerfile(),
+			Builders:   sets.NewString(stages...).Delete("").List(),
+		}
+		if imageConfig.Content.Source.Git != nil {
+			image.Branch = imageConfig.Content.Source.Git.Branch.Taget
+		}
+		byTarget[imageConfig.PromotesTo()] = &imageDrift{PromotesTo: imageConfig.PromotesTo(), ART: image}
+	}
+
+	for pullSpec, promoted := range ciImagesByPromotionTarget(majorMinor, ciConfigs) {
+		drift, ok := byTarget[pullSpec]
+		if !ok {
+			drift = &imageDrift{PromotesTo: pullSpec}
+			byTarget[pullSpec] = drift
+		}
+		data := ciConfigs[promoted.filename]
+		drift.CI = &ciImage{
+			Name:       string(promoted.image.To),
+			ConfigFile: promoted.filename,
+			Repository: fmt.Sprintf("%s/%s", data.Info.Org, data.Info.Repo),
+			Branch:     data.Info.Branch,
+			Dockerfile: ciDockerfilePath(promoted.image),
+			Builders:   ciBuilders(data.Configuration.BaseImages, promoted.image),
+		}
+	}
+
+	report := driftReport{Release: majorMinor.String()}
+	for _, drift := range byTarget {
+		drift.Drift = driftBetween(drift.ART, drift.CI)
+		report.Images = append(report.Images, *drift)
+	}
+	sort.Slice(report.Images, func(i, j int) bool { return report.Images[i].PromotesTo < report.Images[j].PromotesTo })
+	return report
+}
+
+// promotedCIImage is an image of a ci-operator config along with the file the config is in
+type promotedCIImage struct {
+	filename string
+	image    api.ProjectDirectoryImageBuildStepConfiguration
+}
+
+// ciImagesByPromotionTarget returns the images the ci-operator configs promote into the release,
+// keyed by the pull spec of their promotion target
+func ciImagesByPromotionTarget(majorMinor ocpbuilddata.MajorMinor, ciConfigs config.DataByFilename) map[string]promotedCIImage {
+	var filenames []string
+	for filename := range ciConfigs {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+
+	result := map[string]promotedCIImage{}
+	for _, filename := range filenames {
+		data := ciConfigs[filename]
+		promotedTags, _ := release.PromotedTagsWithRequiredImages(&data.Configuration, sets.NewString())
+		for _, image := range data.Configuration.Images {
+			for _, target := range promotedTags[string(image.To)] {
+				if target.Namespace != "ocp" || target.Name != majorMinor.String() {
+					continue
+				}
+				pullSpec := ciRegistry + target.ISTagName()
+				if existing, ok := result[pullSpec]; ok {
+					logrus.WithField("promotes_to", pullSpec).Warnf("Image is promoted by both %s and %s, only considering the former", existing.filename, filename)
+					continue
+				}
+				result[pullSpec] = promotedCIImage{filename: filename, image: image}
+			}
+		}
+	}
+	return result
+}
+
+func driftBetween(art *artImage, ci *ciImage) []string {
+	switch {
+	case ci == nil:
+		return []string{driftOnlyInART}
+	case art == nil:
+		return []string{driftOnlyInCI}
+	}
+	var drift []string
+	if art.Repository != ci.Repository {
+		drift = append(drift, driftRepository)
+	}
+	if filepath.Clean(art.Dockerfile) != filepath.Clean(ci.Dockerfile) {
+		drift = append(drift, driftDockerfile)
+	}
+	if !sets.NewString(art.Builders...).Equal(sets.NewString(ci.Builders...)) {
+		drift = append(drift, driftBuilders)
+	}
+	return drift
+}
+
+func ciDockerfilePath(image api.ProjectDirectoryImageBuildStepConfiguration) string {
+	dockerfile := image.DockerfilePath
+	if dockerfile == "" {
+		dockerfile = "Dockerfile"
+	}
+	return filepath.Join(image.ContextDir, dockerfile)
+}
+
+// ciBuilders returns the pull specs of the base images an image is built from, which are the
+// images it replaces stages of the Dockerfile with and the one it uses as its base
+func ciBuilders(baseImages map[string]api.ImageStreamTagReference, image api.ProjectDirectoryImageBuildStepConfiguration) []string {
+	builders := sets.NewString()
+	add := func(name string) {
+		if baseImage, ok := baseImages[name]; ok {
+			builders.Insert(ciRegistry + baseImage.ISTagName())
+		}
+	}
+	add(string(image.From))
+	for name := range image.Inputs {
+		add(name)
+	}
+	return builders.List()
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/api/ocpbuilddata"
+	"github.com/openshift/ci-tools/pkg/config"
+	"github.com/openshift/ci-tools/pkg/jobconfig"
+	"github.com/openshift/ci-tools/pkg/prowgen"
+)
+
+const ciRegistry = "registry.ci.openshift.org/"
+
+// scaffoldCIOperatorConfigs generates a minimal ci-operator config for every branch of a repository that
+// has images in ocp-build-data but no ci-operator config in the release repository yet. Replacements for
+// the base images are not part of it, the registry-replacer adds them once the config merged.
+func scaffoldCIOperatorConfigs(imageConfigs []ocpbuilddata.OCPImageConfig, existing config.DataByFilename) []config.DataWithInfo {
+	hasConfig := map[api.Metadata]bool{}
+	for _, data := range existing {
+		hasConfig[api.Metadata{Org: data.Info.Org, Repo: data.Info.Repo, Branch: data.Info.Branch}] = true
+	}
+
+	byMetadata := map[api.Metadata][]ocpbuilddata.OCPImageConfig{}
+	for _, imageConfig := range imageConfigs {
+		log := logrus.WithField("file", imageConfig.SourceFileName).WithField("org/repo", imageConfig.PublicRepo.String())
+		if imageConfig.PublicRepo.Org == "openshift-priv" {
+			log.Trace("Ignoring repo in openshift-priv org")
+			continue
+		}
+		metadata := api.Metadata{Org: imageConfig.PublicRepo.Org, Repo: imageConfig.PublicRepo.Repo, Branch: "master"}
+		if imageConfig.Content != nil && imageConfig.Content.Source.Git != nil && imageConfig.Content.Source.Git.Branch.Taget != "" {
+			metadata.Branch = imageConfig.Content.Source.Git.Branch.Taget
+		}
+		if hasConfig[metadata] {
+			continue
+		}
+		byMetadata[metadata] = append(byMetadata[metadata], imageConfig)
+	}
+
+	var result []config.DataWithInfo
+	for metadata, imageConfigs := range byMetadata {
+		log := logrus.WithField("org", metadata.Org).WithField("repo", metadata.Repo).WithField("branch", metadata.Branch)
+		generated, err := scaffoldCIOperatorConfig(metadata, imageConfigs)
+		if err != nil {
+			log.WithError(err).Warn("Not generating a ci-operator config")
+			continue
+		}
+		result = append(result, config.DataWithInfo{Configuration: *generated, Info: config.Info{Metadata: metadata}})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Info.Basename() < result[j].Info.Basename() })
+	return result
+}
+
+func scaffoldCIOperatorConfig(metadata api.Metadata, imageConfigs []ocpbuilddata.OCPImageConfig) (*api.ReleaseBuildConfiguration, error) {
+	sort.Slice(imageConfigs, func(i, j int) bool { return imageConfigs[i].Name < imageConfigs[j].Name })
+
+	var buildRoot *api.ImageStreamTagReference
+	for _, imageConfig := range imageConfigs {
+		for _, builder := range imageConfig.From.Builder {
+			if buildRoot = imageStreamTagReferenceFor(builder.Stream); buildRoot != nil {
+				break
+			}
+		}
+		if buildRoot != nil {
+			break
+		}
+	}
+	if buildRoot == nil {
+		return nil, fmt.Errorf("none of the builders is in %s, can not determine a build root", ciRegistry)
+	}
+
+	version := imageConfigs[0].Version
+	generated := &api.ReleaseBuildConfiguration{
+		Metadata: metadata,
+		InputConfiguration: api.InputConfiguration{
+			BuildRootImage: &api.BuildRootImageConfiguration{ImageStreamTagReference: buildRoot},
+			ReleaseTagConfiguration: &api.ReleaseTagConfiguration{
+				Namespace: "ocp",
+				Name:      version.String(),
+			},
+		},
+		PromotionConfiguration: &api.PromotionConfiguration{
+			Namespace: "ocp",
+			Name:      version.String(),
+		},
+		Resources: map[string]api.ResourceRequirements{"*": {
+			Limits:   map[string]string{"memory": "4Gi"},
+			Requests: map[string]string{"memory": "200Mi", "cpu": "100m"},
+		}},
+	}
+	for _, imageConfig := range imageConfigs {
+		// Defaults the Dockerfile name
+		imageConfig.Dockerfile()
+		generated.Images = append(generated.Images, api.ProjectDirectoryImageBuildStepConfiguration{
+			To: api.PipelineImageStreamTagReference(strings.TrimPrefix(imageConfig.Name, "openshift/ose-")),
+			ProjectDirectoryImageBuildInputs: api.ProjectDirectoryImageBuildInputs{
+				ContextDir:     imageConfig.Content.Source.Path,
+				DockerfilePath: imageConfig.Content.Source.Dockerfile,
+			},
+		})
+	}
+	return generated, nil
+}
+
+// imageStreamTagReferenceFor returns the reference for pull specs in the CI registry
+func imageStreamTagReferenceFor(pullSpec string) *api.ImageStreamTagReference {
+	if !strings.HasPrefix(pullSpec, ciRegistry) {
+		return nil
+	}
+	slashSplit := strings.Split(strings.TrimPrefix(pullSpec, ciRegistry), "/")
+	if len(slashSplit) != 2 {
+		return nil
+	}
+	nameTag := strings.Split(slashSplit[1], ":")
+	if len(nameTag) != 2 {
+		return nil
+	}
+	return &api.ImageStreamTagReference{Namespace: slashSplit[0], Name: nameTag[0], Tag: nameTag[1]}
+}
+
+// writeScaffoldedConfigs writes the configs and their jobs into the release repository
+func writeScaffoldedConfigs(releaseRepoDir string, generated []config.DataWithInfo) error {
+	for _, data := range generated {
+		if err := data.CommitTo(filepath.Join(releaseRepoDir, config.CiopConfigInRepoPath)); err != nil {
+			return fmt.Errorf("failed to write ci-operator config %s: %w", data.Info.Basename(), err)
+		}
+		jobs := prowgen.GenerateJobs(&data.Configuration, &prowgen.ProwgenInfo{Metadata: data.Info.Metadata})
+		if err := jobconfig.WriteToDir(filepath.Join(releaseRepoDir, config.JobConfigInRepoPath), data.Info.Org, data.Info.Repo, jobs); err != nil {
+			return fmt.Errorf("failed to write jobs for %s: %w", data.Info.Basename(), err)
+		}
+	}
+	return nil
+}
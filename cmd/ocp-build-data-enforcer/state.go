@@ -0,0 +1,88 @@
+package main
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+
+	"sigs.k8s.io/yaml"
+)
+
+// state is persisted across runs
+type state struct {
+	// UpToDateDockerfiles holds for every image config whose Dockerfile was up to date in
+	// the last run the fingerprint of the Dockerfile and the config it was checked against.
+	// It is keyed by the release and the config file.
+	UpToDateDockerfiles map[string]string `json:"up_to_date_dockerfiles,omitempty"`
+}
+
+func loadState(path string) (*state, error) {
+	s := &state{}
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, fmt.Errorf("failed to read state file %s: %w", path, err)
+	}
+	if err := yaml.Unmarshal(raw, s); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal state file %s: %w", path, err)
+	}
+	return s, nil
+}
+
+func (s *state) write(path string) error {
+	raw, err := yaml.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("failed to marshal state: %w", err)
+	}
+	if err := ioutil.WriteFile(path, raw, 0644); err != nil {
+		return fmt.Errorf("failed to write state file %s: %w", path, err)
+	}
+	return nil
+}
+
+// upToDateDockerfiles tracks the Dockerfiles that did not need updates, so they can be
+// skipped as long as neither they nor the config they are checked against change.
+type upToDateDockerfiles struct {
+	lock     sync.Mutex
+	previous map[string]string
+	current  map[string]string
+}
+
+func (s *state) upToDateDockerfiles() *upToDateDockerfiles {
+	return &upToDateDockerfiles{previous: s.UpToDateDockerfiles, current: map[string]string{}}
+}
+
+// update records the Dockerfiles that were up to date in a run. Configs that were not
+// part of the run are dropped.
+func (s *state) update(upToDate *upToDateDockerfiles) {
+	s.UpToDateDockerfiles = upToDate.current
+}
+
+// isUpToDate returns if the Dockerfile was up to date in the last run and neither it nor the
+// config changed since. If so, it is carried over to the current run.
+func (u *upToDateDockerfiles) isUpToDate(key, fingerprint string) bool {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	if u.previous[key] != fingerprint {
+		return false
+	}
+	u.current[key] = fingerprint
+	return true
+}
+
+func (u *upToDateDockerfiles) record(key, fingerprint string) {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	u.current[key] = fingerprint
+}
+
+// dockerfileFingerprint hashes everything the check of a Dockerfile depends on: the blob SHA
+// of the Dockerfile and the stages it is expected to have
+func dockerfileFingerprint(path, blobSHA string, stages []string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(fmt.Sprintf("%s=%s\n%s", path, blobSHA, strings.Join(stages, "\n")))))
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestUpToDateDockerfiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.yaml")
+	s, err := loadState(path)
+	if err != nil {
+		t.Fatalf("failed to load missing state: %v", err)
+	}
+	stages := []string{"registry.ci.openshift.org/ocp/builder:golang-1.16", "registry.ci.openshift.org/ocp/4.8:base"}
+
+	// The first run records the up to date Dockerfiles
+	upToDate := s.upToDateDockerfiles()
+	if upToDate.isUpToDate("4.8/images/a.yml", dockerfileFingerprint("Dockerfile", "sha", stages)) {
+		t.Error("expected Dockerfile not to be up to date without state")
+	}
+	upToDate.record("4.8/images/a.yml", dockerfileFingerprint("Dockerfile", "sha", stages))
+	upToDate.record("4.8/images/b.yml", dockerfileFingerprint("Dockerfile", "sha", stages))
+	s.update(upToDate)
+	if err := s.write(path); err != nil {
+		t.Fatalf("failed to write state: %v", err)
+	}
+	if s, err = loadState(path); err != nil {
+		t.Fatalf("failed to load state: %v", err)
+	}
+
+	upToDate = s.upToDateDockerfiles()
+	if !upToDate.isUpToDate("4.8/images/a.yml", dockerfileFingerprint("Dockerfile", "sha", stages)) {
+		t.Error("expected unchanged Dockerfile to be up to date")
+	}
+	if upToDate.isUpToDate("4.8/images/b.yml", dockerfileFingerprint("Dockerfile", "sha", stages[1:])) {
+		t.Error("expected Dockerfile not to be up to date after its config changed")
+	}
+	if upToDate.isUpToDate("4.7/images/a.yml", dockerfileFingerprint("Dockerfile", "sha", stages)) {
+		t.Error("expected Dockerfile of another release not to be up to date")
+	}
+	s.update(upToDate)
+	if len(s.UpToDateDockerfiles) != 1 {
+		t.Errorf("expected only the up to date Dockerfile to be carried over, got %v", s.UpToDateDockerfiles)
+	}
+}
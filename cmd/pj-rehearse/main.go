@@ -25,6 +25,8 @@ import (
 	"k8s.io/client-go/tools/clientcmd"
 	pjapi "k8s.io/test-infra/prow/apis/prowjobs/v1"
 	prowconfig "k8s.io/test-infra/prow/config"
+	"k8s.io/test-infra/prow/config/secret"
+	prowflagutil "k8s.io/test-infra/prow/flagutil"
 	prowgithub "k8s.io/test-infra/prow/github"
 	prowplugins "k8s.io/test-infra/prow/plugins"
 	pjdwapi "k8s.io/test-infra/prow/pod-utils/downwardapi"
@@ -51,8 +53,11 @@ type options struct {
 	noRegistry        bool
 	noClusterProfiles bool
 
-	releaseRepoPath string
-	rehearsalLimit  int
+	releaseRepoPath         string
+	rehearsalLimit          int
+	registryRehearsalSample int
+
+	github prowflagutil.GitHubOptions
 }
 
 func gatherOptions() (options, error) {
@@ -70,6 +75,10 @@ func gatherOptions() (options, error) {
 	fs.BoolVar(&o.noClusterProfiles, "no-cluster-profiles", false, "If true, do not attempt to compare cluster profiles")
 
 	fs.IntVar(&o.rehearsalLimit, "rehearsal-limit", 35, "Upper limit of jobs attempted to rehearse (if more jobs are being touched, only this many will be rehearsed)")
+	fs.IntVar(&o.registryRehearsalSample, "registry-rehearsal-sample", 1, "Number of jobs rehearsed for each step registry component affected by the change")
+
+	o.github.AddFlags(fs)
+	o.github.AllowAnonymous = true
 
 	if err := fs.Parse(os.Args[1:]); err != nil {
 		return o, fmt.Errorf("failed to parse flags: %w", err)
@@ -81,7 +90,10 @@ func validateOptions(o options) error {
 	if len(o.releaseRepoPath) == 0 {
 		return fmt.Errorf("--candidate-path was not provided")
 	}
-	return nil
+	if o.registryRehearsalSample < 1 {
+		return fmt.Errorf("--registry-rehearsal-sample must be positive")
+	}
+	return o.github.Validate(o.dryRun)
 }
 
 const (
@@ -298,7 +310,7 @@ func rehearseMain() error {
 	randomJobsForChangedTemplates := rehearse.AddRandomJobsForChangedTemplates(rehearsalTemplates.ProductionNames, toRehearse, prConfig.Prow.JobConfig.PresubmitsStatic, loggers)
 	toRehearse.AddAll(randomJobsForChangedTemplates, config.ChangedTemplate)
 
-	presubmitsForRegistry, periodicsForRegistry := rehearse.SelectJobsForChangedRegistry(changedRegistrySteps, prConfig.Prow.JobConfig.PresubmitsStatic, prConfig.Prow.JobConfig.Periodics, prConfig.CiOperator, loggers)
+	presubmitsForRegistry, periodicsForRegistry, affectedByRegistry := rehearse.SelectJobsForChangedRegistry(changedRegistrySteps, prConfig.Prow.JobConfig.PresubmitsStatic, prConfig.Prow.JobConfig.Periodics, prConfig.CiOperator, o.registryRehearsalSample, loggers)
 	toRehearse.AddAll(presubmitsForRegistry, config.ChangedRegistryContent)
 	periodicsToRehearse.AddAll(periodicsForRegistry)
 
@@ -333,6 +345,14 @@ func rehearseMain() error {
 		presubmitsToRehearse = determineSubsetToRehearse(presubmitsToRehearse, o.rehearsalLimit)
 	}
 
+	if len(changedRegistrySteps) != 0 {
+		summary := registryImpactSummary(changedRegistrySteps, affectedByRegistry, presubmitsToRehearse, prNumber)
+		logger.Info(summary)
+		if err := postComment(o, org, repo, prNumber, summary); err != nil {
+			logger.WithError(err).Warn("Failed to post the step registry impact summary")
+		}
+	}
+
 	if prConfig.Prow.JobConfig.PresubmitsStatic == nil {
 		prConfig.Prow.JobConfig.PresubmitsStatic = map[string][]prowconfig.Presubmit{}
 	}
@@ -400,6 +420,79 @@ func main() {
 	}
 }
 
+// registryImpactSummary describes how many of the jobs affected by the changes
+// to the step registry are rehearsed. Rehearsals are matched to the affected jobs
+// by name, so periodics and jobs selected for another reason are counted as well.
+func registryImpactSummary(changed []registry.Node, affected sets.String, rehearsals []*prowconfig.Presubmit, prNumber int) string {
+	prefix := fmt.Sprintf("rehearse-%d-", prNumber)
+	rehearsed := 0
+	for _, presubmit := range rehearsals {
+		if affected.Has(strings.TrimPrefix(presubmit.Name, prefix)) {
+			rehearsed++
+		}
+	}
+	var names []string
+	for _, node := range changed {
+		names = append(names, fmt.Sprintf("`%s`", node.Name()))
+	}
+	sort.Strings(names)
+	summary := fmt.Sprintf("The step registry changes in this PR (%s) affect %d jobs, %d of them are rehearsed.", strings.Join(names, ", "), len(affected), rehearsed)
+	if len(affected) > rehearsed {
+		summary += " The remaining jobs are not rehearsed, their owners may want to run them after the change merges."
+	}
+	return summary
+}
+
+// registryImpactMarker identifies the comment with the step registry impact
+// summary, so it is updated on every run instead of being posted again
+const registryImpactMarker = "<!-- pj-rehearse: registry impact summary -->"
+
+type commentClient interface {
+	BotUserChecker() (func(candidate string) bool, error)
+	ListIssueComments(org, repo string, number int) ([]prowgithub.IssueComment, error)
+	CreateComment(org, repo string, number int, comment string) error
+	EditComment(org, repo string, id int, comment string) error
+}
+
+// postComment comments on the tested pull request, when a GitHub token is configured
+func postComment(o options, org, repo string, number int, comment string) error {
+	if o.github.TokenPath == "" {
+		return nil
+	}
+	secretAgent := &secret.Agent{}
+	if err := secretAgent.Start([]string{o.github.TokenPath}); err != nil {
+		return fmt.Errorf("failed to start the secret agent: %w", err)
+	}
+	client, err := o.github.GitHubClient(secretAgent, o.dryRun)
+	if err != nil {
+		return fmt.Errorf("failed to create a GitHub client: %w", err)
+	}
+	return upsertComment(client, org, repo, number, registryImpactMarker, comment)
+}
+
+// upsertComment edits the comment of the bot starting with the marker, or
+// creates it when there is none yet
+func upsertComment(client commentClient, org, repo string, number int, marker, comment string) error {
+	body := marker + "\n" + comment
+	isBot, err := client.BotUserChecker()
+	if err != nil {
+		return fmt.Errorf("failed to determine the bot user: %w", err)
+	}
+	comments, err := client.ListIssueComments(org, repo, number)
+	if err != nil {
+		return fmt.Errorf("failed to list the comments: %w", err)
+	}
+	for _, existing := range comments {
+		if isBot(existing.User.Login) && strings.HasPrefix(existing.Body, marker) {
+			if existing.Body == body {
+				return nil
+			}
+			return client.EditComment(org, repo, existing.ID, body)
+		}
+	}
+	return client.CreateComment(org, repo, number, body)
+}
+
 func pjKubeconfig(path string, defaultKubeconfig *rest.Config) (*rest.Config, error) {
 	if path == "" {
 		return defaultKubeconfig, nil
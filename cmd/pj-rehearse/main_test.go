@@ -14,8 +14,10 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/kubernetes/scheme"
 	prowconfig "k8s.io/test-infra/prow/config"
+	prowgithub "k8s.io/test-infra/prow/github"
 	utilpointer "k8s.io/utils/pointer"
 	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -24,6 +26,7 @@ import (
 
 	testimagestreamtagimportv1 "github.com/openshift/ci-tools/pkg/api/testimagestreamtagimport/v1"
 	"github.com/openshift/ci-tools/pkg/config"
+	"github.com/openshift/ci-tools/pkg/registry"
 )
 
 func init() {
@@ -302,3 +305,114 @@ func TestDetermineSubsetToRehearse(t *testing.T) {
 		})
 	}
 }
+
+func TestRegistryImpactSummary(t *testing.T) {
+	graph, err := registry.NewGraph(registry.ReferenceByName{"ipi-install": {As: "ipi-install"}, "gather": {As: "gather"}}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create the graph: %v", err)
+	}
+	changed := []registry.Node{graph.References["ipi-install"], graph.References["gather"]}
+	rehearsal := func(name string, source config.SourceType) *prowconfig.Presubmit {
+		return &prowconfig.Presubmit{JobBase: prowconfig.JobBase{Name: "rehearse-123-" + name, Labels: map[string]string{config.SourceTypeLabel: string(source)}}}
+	}
+	var testCases = []struct {
+		name       string
+		affected   sets.String
+		rehearsals []*prowconfig.Presubmit
+		expected   string
+	}{
+		{
+			name:       "all affected jobs are rehearsed",
+			affected:   sets.NewString("a"),
+			rehearsals: []*prowconfig.Presubmit{rehearsal("a", config.ChangedRegistryContent), rehearsal("b", config.ChangedCiopConfig)},
+			expected:   "The step registry changes in this PR (`gather`, `ipi-install`) affect 1 jobs, 1 of them are rehearsed.",
+		},
+		{
+			name:       "a sample of the affected jobs is rehearsed",
+			affected:   sets.NewString("a", "b", "c"),
+			rehearsals: []*prowconfig.Presubmit{rehearsal("a", config.ChangedRegistryContent)},
+			expected:   "The step registry changes in this PR (`gather`, `ipi-install`) affect 3 jobs, 1 of them are rehearsed. The remaining jobs are not rehearsed, their owners may want to run them after the change merges.",
+		},
+		{
+			name:       "periodics and jobs selected for another reason are counted",
+			affected:   sets.NewString("a", "periodic-b", "c"),
+			rehearsals: []*prowconfig.Presubmit{rehearsal("a", config.ChangedCiopConfig), rehearsal("periodic-b", config.ChangedPeriodic), rehearsal("d", config.ChangedRegistryContent)},
+			expected:   "The step registry changes in this PR (`gather`, `ipi-install`) affect 3 jobs, 2 of them are rehearsed. The remaining jobs are not rehearsed, their owners may want to run them after the change merges.",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.expected, registryImpactSummary(changed, tc.affected, tc.rehearsals, 123)); diff != "" {
+				t.Errorf("unexpected summary: %s", diff)
+			}
+		})
+	}
+}
+
+type fakeCommentClient struct {
+	comments []prowgithub.IssueComment
+	created  []string
+	edited   map[int]string
+}
+
+func (c *fakeCommentClient) BotUserChecker() (func(candidate string) bool, error) {
+	return func(candidate string) bool { return candidate == "bot" }, nil
+}
+
+func (c *fakeCommentClient) ListIssueComments(_, _ string, _ int) ([]prowgithub.IssueComment, error) {
+	return c.comments, nil
+}
+
+func (c *fakeCommentClient) CreateComment(_, _ string, _ int, comment string) error {
+	c.created = append(c.created, comment)
+	return nil
+}
+
+func (c *fakeCommentClient) EditComment(_, _ string, id int, comment string) error {
+	if c.edited == nil {
+		c.edited = map[int]string{}
+	}
+	c.edited[id] = comment
+	return nil
+}
+
+func TestUpsertComment(t *testing.T) {
+	var testCases = []struct {
+		name            string
+		comments        []prowgithub.IssueComment
+		expectedCreated []string
+		expectedEdited  map[int]string
+	}{
+		{
+			name:            "no comment yet",
+			comments:        []prowgithub.IssueComment{{ID: 1, Body: "<!-- marker -->\nsummary", User: prowgithub.User{Login: "human"}}},
+			expectedCreated: []string{"<!-- marker -->\nsummary"},
+		},
+		{
+			name: "outdated comment is edited",
+			comments: []prowgithub.IssueComment{
+				{ID: 1, Body: "/retest", User: prowgithub.User{Login: "bot"}},
+				{ID: 2, Body: "<!-- marker -->\nold summary", User: prowgithub.User{Login: "bot"}},
+			},
+			expectedEdited: map[int]string{2: "<!-- marker -->\nsummary"},
+		},
+		{
+			name:     "current comment is kept",
+			comments: []prowgithub.IssueComment{{ID: 2, Body: "<!-- marker -->\nsummary", User: prowgithub.User{Login: "bot"}}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := &fakeCommentClient{comments: tc.comments}
+			if err := upsertComment(client, "org", "repo", 1, "<!-- marker -->", "summary"); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expectedCreated, client.created); diff != "" {
+				t.Errorf("created comments differ from expected: %s", diff)
+			}
+			if diff := cmp.Diff(tc.expectedEdited, client.edited); diff != "" {
+				t.Errorf("edited comments differ from expected: %s", diff)
+			}
+		})
+	}
+}
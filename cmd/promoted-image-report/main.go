@@ -0,0 +1,114 @@
+// promoted-image-report maps every ImageStreamTag the ci-operator configurations
+// promote to onto the repository, branch and Dockerfile it is built from, the
+// time its current content was built and whether that content is synced to
+// all clusters.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	imagev1 "github.com/openshift/api/image/v1"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/config"
+	registrysyncer "github.com/openshift/ci-tools/pkg/controller/registry_syncer"
+	"github.com/openshift/ci-tools/pkg/util"
+)
+
+type options struct {
+	configDir           string
+	kubeconfig          string
+	registryClusterName string
+	output              string
+}
+
+func (o *options) validate() error {
+	if o.configDir == "" {
+		return errors.New("--config-dir is required")
+	}
+	if o.registryClusterName == "" {
+		return errors.New("--registry-cluster-name is required")
+	}
+	return nil
+}
+
+func gatherOptions() options {
+	o := options{}
+	flag.StringVar(&o.configDir, "config-dir", "", "The directory containing configuration files.")
+	flag.StringVar(&o.kubeconfig, "kubeconfig", "", "The kubeconfig to use. All contexts in it are considered clusters the images are synced to.")
+	flag.StringVar(&o.registryClusterName, "registry-cluster-name", "app.ci", "The context of the cluster images are promoted to.")
+	flag.StringVar(&o.output, "output", "", "The file to write the report to. The report is written to stdout if unset.")
+	flag.Parse()
+	return o
+}
+
+func main() {
+	o := gatherOptions()
+	if err := o.validate(); err != nil {
+		logrus.WithError(err).Fatal("Invalid options")
+	}
+	if err := imagev1.AddToScheme(scheme.Scheme); err != nil {
+		logrus.WithError(err).Fatal("Failed to add imagev1 to scheme")
+	}
+
+	var configurations []api.ReleaseBuildConfiguration
+	if err := config.OperateOnCIOperatorConfigDir(o.configDir, func(configuration *api.ReleaseBuildConfiguration, _ *config.Info) error {
+		configurations = append(configurations, *configuration)
+		return nil
+	}); err != nil {
+		logrus.WithError(err).Fatal("Failed to load the configurations")
+	}
+	images := promotedImages(configurations)
+
+	kubeconfigs, _, err := util.LoadKubeConfigs(o.kubeconfig, nil)
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to load kubeconfigs")
+	}
+	if _, ok := kubeconfigs[o.registryClusterName]; !ok {
+		logrus.Fatalf("--kubeconfig must include a context named %s", o.registryClusterName)
+	}
+	clients := map[string]ctrlruntimeclient.Client{}
+	for cluster, kubeconfig := range kubeconfigs {
+		client, err := ctrlruntimeclient.New(kubeconfig, ctrlruntimeclient.Options{})
+		if err != nil {
+			logrus.WithError(err).Fatalf("Failed to construct client for cluster %s", cluster)
+		}
+		clients[cluster] = client
+	}
+
+	ctx := context.Background()
+	if err := addBuildInfo(ctx, clients[o.registryClusterName], images); err != nil {
+		logrus.WithError(err).Fatal("Failed to determine when the images were built")
+	}
+	inventory, err := registrysyncer.Inventory(ctx, clients, imageStreams(images))
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to determine the sync status of the images")
+	}
+	addSyncStatus(images, inventory, sets.StringKeySet(clients).List())
+
+	if images == nil {
+		images = []promotedImage{}
+	}
+	raw, err := yaml.Marshal(images)
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to marshal the report")
+	}
+	if o.output == "" {
+		fmt.Print(string(raw))
+		return
+	}
+	if err := ioutil.WriteFile(o.output, raw, 0644); err != nil {
+		logrus.WithError(err).Fatal("Failed to write the report")
+	}
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift/api/image/docker10"
+	imagev1 "github.com/openshift/api/image/v1"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	registrysyncer "github.com/openshift/ci-tools/pkg/controller/registry_syncer"
+	"github.com/openshift/ci-tools/pkg/steps/release"
+)
+
+// promotedImage describes where a promoted ImageStreamTag comes from and
+// whether its content is the same on all clusters
+type promotedImage struct {
+	// ImageStreamTag is the promoted tag in namespace/name:tag form
+	ImageStreamTag string `json:"imagestreamtag"`
+	api.Metadata   `json:",inline"`
+	// Image is the image in the pipeline of the configuration that is promoted
+	Image string `json:"image"`
+	// Dockerfile is the path of the Dockerfile the image is built from,
+	// unset for images that are not built from the repository
+	Dockerfile string `json:"dockerfile,omitempty"`
+	// Commit is the commit the current content of the tag was built from
+	Commit string `json:"commit,omitempty"`
+	// BuildTime is the time the current content of the tag was built
+	BuildTime *time.Time `json:"build_time,omitempty"`
+	// Missing is set when the tag does not exist on the registry cluster
+	Missing bool `json:"missing,omitempty"`
+	// SourceCluster is the cluster that got the current content of the tag most recently
+	SourceCluster string `json:"source_cluster,omitempty"`
+	// LastSync is the most recent time the current content arrived on another cluster
+	LastSync *time.Time `json:"last_sync,omitempty"`
+	// OutOfSync are the clusters that do not hold the current content of the tag
+	OutOfSync []string `json:"out_of_sync,omitempty"`
+	// Conflicting are the clusters that got different content for the tag at
+	// about the same time, which needs to be resolved by a human
+	Conflicting []string `json:"conflicting,omitempty"`
+
+	tag api.ImageStreamTagReference
+}
+
+// promotedImages returns the ImageStreamTags the configurations promote to,
+// sorted by their name
+func promotedImages(configurations []api.ReleaseBuildConfiguration) []promotedImage {
+	var images []promotedImage
+	for _, configuration := range configurations {
+		promoted, _ := release.PromotedTagsWithRequiredImages(&configuration, sets.NewString())
+		for src, tags := range promoted {
+			for _, tag := range tags {
+				images = append(images, promotedImage{
+					ImageStreamTag: tag.ISTagName(),
+					Metadata:       configuration.Metadata,
+					Image:          src,
+					Dockerfile:     dockerfileFor(configuration, src),
+					tag:            tag,
+				})
+			}
+		}
+	}
+	sort.Slice(images, func(i, j int) bool {
+		return images[i].ImageStreamTag < images[j].ImageStreamTag
+	})
+	return images
+}
+
+func dockerfileFor(configuration api.ReleaseBuildConfiguration, image string) string {
+	for _, build := range configuration.Images {
+		if string(build.To) != image {
+			continue
+		}
+		if build.DockerfileLiteral != nil {
+			return ""
+		}
+		dockerfile := build.DockerfilePath
+		if dockerfile == "" {
+			dockerfile = "Dockerfile"
+		}
+		return path.Join(build.ContextDir, dockerfile)
+	}
+	return ""
+}
+
+// addBuildInfo records the commit and build time of the current content of
+// the tags on the registry cluster
+func addBuildInfo(ctx context.Context, client ctrlruntimeclient.Client, images []promotedImage) error {
+	for i := range images {
+		tag := images[i].tag
+		ist := &imagev1.ImageStreamTag{}
+		if err := client.Get(ctx, types.NamespacedName{Namespace: tag.Namespace, Name: fmt.Sprintf("%s:%s", tag.Name, tag.Tag)}, ist); err != nil {
+			if apierrors.IsNotFound(err) {
+				images[i].Missing = true
+				continue
+			}
+			return fmt.Errorf("failed to get imagestreamtag %s: %w", images[i].ImageStreamTag, err)
+		}
+		if len(ist.Image.DockerImageMetadata.Raw) == 0 {
+			continue
+		}
+		metadata := &docker10.DockerImage{}
+		if err := json.Unmarshal(ist.Image.DockerImageMetadata.Raw, metadata); err != nil {
+			return fmt.Errorf("failed to unmarshal the metadata of imagestreamtag %s: %w", images[i].ImageStreamTag, err)
+		}
+		if metadata.Config != nil {
+			images[i].Commit = metadata.Config.Labels["io.openshift.build.commit.id"]
+		}
+		if !metadata.Created.IsZero() {
+			created := metadata.Created.Time
+			images[i].BuildTime = &created
+		}
+	}
+	return nil
+}
+
+// addSyncStatus records which clusters hold the current content of the tags,
+// based on the inventory the registry syncer uses
+func addSyncStatus(images []promotedImage, inventory []registrysyncer.TagInventory, clusters []string) {
+	byTag := map[string]registrysyncer.TagInventory{}
+	for _, item := range inventory {
+		byTag[fmt.Sprintf("%s/%s:%s", item.Namespace, item.ImageStream, item.Tag)] = item
+	}
+	for i := range images {
+		item, ok := byTag[images[i].ImageStreamTag]
+		if !ok {
+			images[i].OutOfSync = clusters
+			continue
+		}
+		if item.Conflict != nil {
+			images[i].Conflicting = item.Conflict.Clusters
+			continue
+		}
+		images[i].SourceCluster = item.SourceCluster
+		images[i].LastSync = item.LastSync
+		for _, cluster := range clusters {
+			if state, ok := item.Clusters[cluster]; !ok || state.Digest != item.Digest {
+				images[i].OutOfSync = append(images[i].OutOfSync, cluster)
+			}
+		}
+	}
+}
+
+// imageStreams returns the ImageStreams of the tags in namespace/name form
+func imageStreams(images []promotedImage) sets.String {
+	streams := sets.NewString()
+	for _, image := range images {
+		streams.Insert(fmt.Sprintf("%s/%s", image.tag.Namespace, image.tag.Name))
+	}
+	return streams
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	imagev1 "github.com/openshift/api/image/v1"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	registrysyncer "github.com/openshift/ci-tools/pkg/controller/registry_syncer"
+)
+
+func init() {
+	if err := imagev1.AddToScheme(scheme.Scheme); err != nil {
+		panic(fmt.Sprintf("failed to register imagev1 scheme: %v", err))
+	}
+}
+
+func TestPromotedImages(t *testing.T) {
+	literal := "FROM base"
+	configurations := []api.ReleaseBuildConfiguration{
+		{
+			Metadata: api.Metadata{Org: "org", Repo: "repo", Branch: "master"},
+			Images: []api.ProjectDirectoryImageBuildStepConfiguration{
+				{To: "component"},
+				{To: "other", ProjectDirectoryImageBuildInputs: api.ProjectDirectoryImageBuildInputs{ContextDir: "images/other", DockerfilePath: "Dockerfile.rhel"}},
+			},
+			PromotionConfiguration: &api.PromotionConfiguration{Namespace: "ocp", Name: "4.8"},
+		},
+		{
+			Metadata: api.Metadata{Org: "org", Repo: "tools", Branch: "main", Variant: "ci"},
+			Images: []api.ProjectDirectoryImageBuildStepConfiguration{
+				{To: "tool", ProjectDirectoryImageBuildInputs: api.ProjectDirectoryImageBuildInputs{DockerfileLiteral: &literal}},
+			},
+			PromotionConfiguration: &api.PromotionConfiguration{Namespace: "ci", Tag: "latest"},
+		},
+		{
+			Metadata:               api.Metadata{Org: "org", Repo: "disabled", Branch: "master"},
+			Images:                 []api.ProjectDirectoryImageBuildStepConfiguration{{To: "component"}},
+			PromotionConfiguration: &api.PromotionConfiguration{Namespace: "ocp", Name: "4.8", Disabled: true},
+		},
+	}
+	expected := []promotedImage{
+		{
+			ImageStreamTag: "ci/tool:latest",
+			Metadata:       api.Metadata{Org: "org", Repo: "tools", Branch: "main", Variant: "ci"},
+			Image:          "tool",
+			tag:            api.ImageStreamTagReference{Namespace: "ci", Name: "tool", Tag: "latest"},
+		},
+		{
+			ImageStreamTag: "ocp/4.8:component",
+			Metadata:       api.Metadata{Org: "org", Repo: "repo", Branch: "master"},
+			Image:          "component",
+			Dockerfile:     "Dockerfile",
+			tag:            api.ImageStreamTagReference{Namespace: "ocp", Name: "4.8", Tag: "component"},
+		},
+		{
+			ImageStreamTag: "ocp/4.8:other",
+			Metadata:       api.Metadata{Org: "org", Repo: "repo", Branch: "master"},
+			Image:          "other",
+			Dockerfile:     "images/other/Dockerfile.rhel",
+			tag:            api.ImageStreamTagReference{Namespace: "ocp", Name: "4.8", Tag: "other"},
+		},
+	}
+	if diff := cmp.Diff(expected, promotedImages(configurations), cmp.AllowUnexported(promotedImage{})); diff != "" {
+		t.Errorf("unexpected promoted images: %s", diff)
+	}
+}
+
+func TestAddBuildInfo(t *testing.T) {
+	built := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	client := fakectrlruntimeclient.NewFakeClient(&imagev1.ImageStreamTag{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ocp", Name: "4.8:component"},
+		Image: imagev1.Image{DockerImageMetadata: runtime.RawExtension{
+			Raw: []byte(`{"Created":"2021-06-01T00:00:00Z","Config":{"Labels":{"io.openshift.build.commit.id":"abcdef"}}}`),
+		}},
+	})
+	images := []promotedImage{
+		{ImageStreamTag: "ocp/4.8:component", tag: api.ImageStreamTagReference{Namespace: "ocp", Name: "4.8", Tag: "component"}},
+		{ImageStreamTag: "ocp/4.8:missing", tag: api.ImageStreamTagReference{Namespace: "ocp", Name: "4.8", Tag: "missing"}},
+	}
+	if err := addBuildInfo(context.Background(), client, images); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []promotedImage{
+		{ImageStreamTag: "ocp/4.8:component", Commit: "abcdef", BuildTime: &built, tag: images[0].tag},
+		{ImageStreamTag: "ocp/4.8:missing", Missing: true, tag: images[1].tag},
+	}
+	if diff := cmp.Diff(expected, images, cmp.AllowUnexported(promotedImage{})); diff != "" {
+		t.Errorf("unexpected build info: %s", diff)
+	}
+}
+
+func TestAddSyncStatus(t *testing.T) {
+	synced := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	inventory := []registrysyncer.TagInventory{{
+		Namespace:     "ocp",
+		ImageStream:   "4.8",
+		Tag:           "component",
+		SourceCluster: "app.ci",
+		Digest:        "sha256:new",
+		LastSync:      &synced,
+		Clusters: map[string]registrysyncer.TagState{
+			"app.ci":  {Digest: "sha256:new"},
+			"build01": {Digest: "sha256:old"},
+			"build02": {Digest: "sha256:new"},
+		},
+	}, {
+		Namespace:   "ocp",
+		ImageStream: "4.8",
+		Tag:         "conflict",
+		Clusters: map[string]registrysyncer.TagState{
+			"app.ci":  {Digest: "sha256:one"},
+			"build01": {Digest: "sha256:other"},
+		},
+		Conflict: &registrysyncer.TagConflict{Clusters: []string{"app.ci", "build01"}, Digests: []string{"sha256:one", "sha256:other"}},
+	}}
+	images := []promotedImage{{ImageStreamTag: "ocp/4.8:component"}, {ImageStreamTag: "ocp/4.8:conflict"}, {ImageStreamTag: "ocp/4.8:missing"}}
+	addSyncStatus(images, inventory, []string{"app.ci", "build01", "build02", "build03"})
+	expected := []promotedImage{
+		{ImageStreamTag: "ocp/4.8:component", SourceCluster: "app.ci", LastSync: &synced, OutOfSync: []string{"build01", "build03"}},
+		{ImageStreamTag: "ocp/4.8:conflict", Conflicting: []string{"app.ci", "build01"}},
+		{ImageStreamTag: "ocp/4.8:missing", OutOfSync: []string{"app.ci", "build01", "build02", "build03"}},
+	}
+	if diff := cmp.Diff(expected, images, cmp.AllowUnexported(promotedImage{})); diff != "" {
+		t.Errorf("unexpected sync status: %s", diff)
+	}
+}
@@ -2,7 +2,6 @@ package main
 
 import (
 	"bytes"
-	"context"
 	"errors"
 	"flag"
 	"fmt"
@@ -11,10 +10,8 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
-	"sync"
 
 	"github.com/sirupsen/logrus"
-	"golang.org/x/sync/semaphore"
 
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -33,6 +30,7 @@ import (
 	"github.com/openshift/ci-tools/pkg/api/ocpbuilddata"
 	"github.com/openshift/ci-tools/pkg/config"
 	"github.com/openshift/ci-tools/pkg/github"
+	"github.com/openshift/ci-tools/pkg/secrets"
 	"github.com/openshift/ci-tools/pkg/steps/release"
 )
 
@@ -48,11 +46,21 @@ type options struct {
 	pruneUnusedReplacements                      bool
 	pruneOCPBuilderReplacements                  bool
 	ensureCorrectPromotionDockerfileIngoredRepos *flagutil.Strings
+	stateFile                                    string
+	emptyDockerfileThreshold                     int
+	skipUnchanged                                bool
+	hostCredentials                              flagutil.Strings
+	hostTokenPaths                               map[string]string
+	dockerfileNames                              flagutil.Strings
 	flagutil.GitHubOptions
 }
 
+// defaultDockerfileNames are the names of the Dockerfile tried in order for
+// images that do not set a dockerfile_path
+var defaultDockerfileNames = []string{"Dockerfile", "Containerfile"}
+
 func gatherOptions() (*options, error) {
-	o := &options{ensureCorrectPromotionDockerfileIngoredRepos: &flagutil.Strings{}}
+	o := &options{ensureCorrectPromotionDockerfileIngoredRepos: &flagutil.Strings{}, dockerfileNames: flagutil.NewStrings(defaultDockerfileNames...)}
 	o.AddFlags(flag.CommandLine)
 	flag.StringVar(&o.configDir, "config-dir", "", "The directory with the ci-operator configs")
 	flag.BoolVar(&o.createPR, "create-pr", false, "If the tool should automatically create a PR. Requires --token-file")
@@ -65,6 +73,11 @@ func gatherOptions() (*options, error) {
 	flag.StringVar(&o.currentRelease.Minor, "current-release-minor", "6", "The minor version of the current release that is getting forwarded to from the master branch")
 	flag.BoolVar(&o.pruneUnusedReplacements, "prune-unused-replacements", false, "If replacements that match nothing should get pruned from the config")
 	flag.BoolVar(&o.pruneOCPBuilderReplacements, "prune-ocp-builder-replacements", false, "If all replacements that target the ocp/builder imagestream should be removed")
+	flag.StringVar(&o.stateFile, "state-file", "", "A file in which state is kept across runs. It is used to report repos for which we only get empty Dockerfiles. Nothing is reported if unset.")
+	flag.IntVar(&o.emptyDockerfileThreshold, "empty-dockerfile-threshold", 5, "The number of consecutive runs in which we only got empty Dockerfiles for a repo after which it gets reported. Requires --state-file.")
+	flag.BoolVar(&o.skipUnchanged, "skip-unchanged", false, "If configs that did not need changes in the last run should be skipped as long as they and their Dockerfiles stay the same. Requires --state-file.")
+	flag.Var(&o.dockerfileNames, "dockerfile-name", fmt.Sprintf("The name of the Dockerfile to try in order for images that do not set a dockerfile_path. Can be passed multiple times. Defaults to %v.", defaultDockerfileNames))
+	flag.Var(&o.hostCredentials, "github-host-token-path", "A host=path pair of a GitHub host and a file with the token of --github-user-name on that host, used to get files from it. Takes precedence over other credentials for that host. Can be passed multiple times.")
 	flag.Parse()
 
 	var errs []error
@@ -76,6 +89,11 @@ func gatherOptions() (*options, error) {
 		if o.githubUserName == "" {
 			errs = append(errs, errors.New("--github-user-name was unset, it is required when --create-pr is set"))
 		}
+		if o.TokenPath == "" {
+			errs = append(errs, errors.New("--github-token-path was unset, it is required when --create-pr is set, GitHub App authentication is only used to get files"))
+		}
+		errs = append(errs, o.GitHubOptions.Validate(false))
+	} else if o.AppID != "" || o.AppPrivateKeyPath != "" {
 		errs = append(errs, o.GitHubOptions.Validate(false))
 	}
 
@@ -89,6 +107,24 @@ func gatherOptions() (*options, error) {
 		o.currentRelease.Major = "4"
 	}
 
+	if o.skipUnchanged && o.stateFile == "" {
+		errs = append(errs, errors.New("--skip-unchanged requires --state-file"))
+	}
+
+	o.hostTokenPaths = map[string]string{}
+	for _, value := range o.hostCredentials.Strings() {
+		parts := strings.SplitN(value, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			errs = append(errs, fmt.Errorf("--github-host-token-path must be in host=path format, got %q", value))
+			continue
+		}
+		o.hostTokenPaths[parts[0]] = parts[1]
+	}
+
+	if o.emptyDockerfileThreshold < 1 {
+		errs = append(errs, errors.New("--empty-dockerfile-threshold must be at least 1"))
+	}
+
 	return o, utilerrors.NewAggregate(errs)
 }
 
@@ -102,10 +138,17 @@ func main() {
 	// Already create the client here if needed to make sure we fail asap if there is an issue
 	var githubClient pgithub.Client
 	var secretAgent *secret.Agent
+	var secretPaths []string
 	if opts.TokenPath != "" {
+		secretPaths = append(secretPaths, opts.TokenPath)
+	}
+	for _, path := range opts.hostTokenPaths {
+		secretPaths = append(secretPaths, path)
+	}
+	if len(secretPaths) > 0 || opts.AppPrivateKeyPath != "" {
 		secretAgent = &secret.Agent{}
-		if err := secretAgent.Start([]string{opts.TokenPath}); err != nil {
-			logrus.WithError(err).Fatal("Failed to load github token")
+		if err := secretAgent.Start(secretPaths); err != nil {
+			logrus.WithError(err).Fatal("Failed to load github tokens")
 		}
 	}
 	if opts.createPR {
@@ -125,70 +168,85 @@ func main() {
 		}
 	}
 
-	var credentials *usernameToken
-	if secretAgent != nil {
-		credentials = &usernameToken{
-			username: opts.githubUserName,
-			token:    string(secretAgent.GetSecret(opts.TokenPath)),
+	endpoints := github.EndpointsForHost(opts.Host)
+	getterOpts := []github.Opt{github.WithEndpoints(endpoints)}
+	if opts.AppID != "" {
+		tokenGenerator, err := github.AppInstallationTokenGenerator(opts.AppID, opts.AppPrivateKeyPath, secretAgent, endpoints)
+		if err != nil {
+			logrus.WithError(err).Fatal("Failed to set up GitHub App authentication")
+		}
+		getterOpts = append(getterOpts, github.WithAppInstallationAuthentication(tokenGenerator))
+	} else if opts.TokenPath != "" {
+		getterOpts = append(getterOpts, github.WithAuthentication(opts.githubUserName, string(secretAgent.GetSecret(opts.TokenPath))))
+	}
+	for host, path := range opts.hostTokenPaths {
+		getterOpts = append(getterOpts, github.WithHostAuthentication(host, opts.githubUserName, string(secretAgent.GetSecret(path))))
+	}
+
+	var runState *state
+	if opts.stateFile != "" {
+		runState, err = loadState(opts.stateFile)
+		if err != nil {
+			logrus.WithError(err).Fatal("Failed to load state")
 		}
 	}
+	dockerfileResults := newDockerfileResults()
+	var unchanged *unchangedConfigs
+	if runState != nil && opts.skipUnchanged {
+		unchanged = runState.unchangedConfigs()
+	}
 
-	var errs []error
-	errLock := &sync.Mutex{}
-	sem := semaphore.NewWeighted(int64(opts.maxConcurrency))
-	ctx := context.TODO()
-	if err := config.OperateOnCIOperatorConfigDir(
+	if err := config.OperateOnCIOperatorConfigDirInParallel(
 		opts.configDir,
+		config.ParallelOptions{Workers: opts.maxConcurrency},
 		func(config *api.ReleaseBuildConfiguration, info *config.Info) error {
-			if err := sem.Acquire(ctx, 1); err != nil {
-				return fmt.Errorf("failed to acquire semaphore: %w", err)
-			}
-			go func(filename string) {
-				defer sem.Release(1)
-				if err := replacer(
-					github.FileGetterFactory,
-					func(data []byte) error {
-						return ioutil.WriteFile(filename, data, 0644)
-					},
-					opts.pruneUnusedReplacements,
-					opts.pruneOCPBuilderReplacements,
-					opts.ensureCorrectPromotionDockerfile,
-					sets.NewString(opts.ensureCorrectPromotionDockerfileIngoredRepos.Strings()...),
-					promotionTargetToDockerfileMapping,
-					opts.currentRelease,
-					credentials,
-				)(config, info); err != nil {
-					errLock.Lock()
-					errs = append(errs, err)
-					errLock.Unlock()
-				}
-			}(info.Filename)
-			return nil
+			filename := info.Filename
+			return replacer(
+				github.FileGetterFactory,
+				func(data []byte) error {
+					return ioutil.WriteFile(filename, data, 0644)
+				},
+				opts.pruneUnusedReplacements,
+				opts.pruneOCPBuilderReplacements,
+				opts.ensureCorrectPromotionDockerfile,
+				sets.NewString(opts.ensureCorrectPromotionDockerfileIngoredRepos.Strings()...),
+				promotionTargetToDockerfileMapping,
+				opts.currentRelease,
+				opts.dockerfileNames.Strings(),
+				getterOpts,
+				dockerfileResults.record,
+				github.BlobSHAGetterFactory,
+				unchanged,
+			)(config, info)
 		},
 	); err != nil {
-		logrus.WithError(err).Fatal("Failed to operate on ci-operator-config")
-	}
-	if err := sem.Acquire(ctx, int64(opts.maxConcurrency)); err != nil {
-		logrus.WithError(err).Fatal("failed to acquire semaphore while wating all workers to finish")
-	}
-	if err := utilerrors.NewAggregate(errs); err != nil {
 		logrus.WithError(err).Fatal("Encountered errors")
 	}
 
+	var reposWithEmptyDockerfiles []string
+	if runState != nil {
+		runState.update(dockerfileResults)
+		if unchanged != nil {
+			runState.updateUnchangedConfigs(unchanged)
+		}
+		if err := runState.write(opts.stateFile); err != nil {
+			logrus.WithError(err).Fatal("Failed to write state")
+		}
+		reposWithEmptyDockerfiles = runState.reposWithEmptyDockerfiles(opts.emptyDockerfileThreshold)
+		for _, repo := range reposWithEmptyDockerfiles {
+			logrus.WithField("repo", repo).WithField("runs", runState.ConsecutiveEmptyDockerfileRuns[repo]).Warn("Only got empty Dockerfiles for repo, we might lack permissions or it might have been renamed")
+		}
+	}
+
 	if !opts.createPR {
 		return
 	}
 
-	if err := upsertPR(githubClient, opts.configDir, opts.githubUserName, secretAgent.GetSecret(opts.TokenPath), opts.selfApprove, opts.pruneUnusedReplacements, opts.ensureCorrectPromotionDockerfile); err != nil {
+	if err := upsertPR(githubClient, opts.configDir, opts.githubUserName, secretAgent.GetSecret(opts.TokenPath), opts.selfApprove, opts.pruneUnusedReplacements, opts.ensureCorrectPromotionDockerfile, reposWithEmptyDockerfiles, opts.emptyDockerfileThreshold); err != nil {
 		logrus.WithError(err).Fatal("Failed to create PR")
 	}
 }
 
-type usernameToken struct {
-	username string
-	token    string
-}
-
 // replacer ensures replace directives are in place. It fetches the files via http because using git
 // en masse easily kills a developer laptop whereas the http calls are cheap and can be parallelized without
 // bounds.
@@ -201,7 +259,11 @@ func replacer(
 	ensureCorrectPromotionDockerfileIgnoredrepos sets.String,
 	promotionTargetToDockerfileMapping map[string]dockerfileLocation,
 	majorMinor ocpbuilddata.MajorMinor,
-	credentials *usernameToken,
+	dockerfileNames []string,
+	getterOpts []github.Opt,
+	recordDockerfileResult func(org, repo string, hasNonEmptyDockerfile bool),
+	blobSHAGetterFactory func(org, repo, branch string, opts ...github.Opt) github.BlobSHAGetter,
+	unchanged *unchangedConfigs,
 ) func(*api.ReleaseBuildConfiguration, *config.Info) error {
 	return func(config *api.ReleaseBuildConfiguration, info *config.Info) error {
 		if len(config.Images) == 0 {
@@ -219,12 +281,22 @@ func replacer(
 			updateDockerfilesToMatchOCPBuildData(config, promotionTargetToDockerfileMapping, majorMinor.String(), ensureCorrectPromotionDockerfileIgnoredrepos)
 		}
 
-		var getter github.FileGetter
-		if credentials == nil {
-			getter = githubFileGetterFactory(info.Org, info.Repo, info.Branch)
-		} else {
-			getter = githubFileGetterFactory(info.Org, info.Repo, info.Branch, github.WithAuthentication(credentials.username, credentials.token))
+		var fingerprint string
+		if unchanged != nil {
+			fingerprint, err = inputFingerprint(config, dockerfileNames, blobSHAGetterFactory(info.Org, info.Repo, info.Branch, getterOpts...), pruneUnusedReplacementsEnabled, pruneOCPBuilderReplacementsEnabled)
+			if err != nil {
+				return fmt.Errorf("failed to fingerprint the inputs: %w", err)
+			}
+			if unchanged.isUnchanged(info.Filename, fingerprint) {
+				// Only configs with a non-empty Dockerfile are recorded as unchanged
+				if recordDockerfileResult != nil {
+					recordDockerfileResult(info.Org, info.Repo, true)
+				}
+				return nil
+			}
 		}
+
+		getter := githubFileGetterFactory(info.Org, info.Repo, info.Branch, getterOpts...)
 		allReplacementCandidates := sets.String{}
 
 		// We have to skip pruning if we only get empty dockerfiles because it might mean
@@ -232,14 +304,9 @@ func replacer(
 		var hasNonEmptyDockerfile bool
 
 		for idx, image := range config.Images {
-			dockerFilePath := "Dockerfile"
-			if image.DockerfilePath != "" {
-				dockerFilePath = image.DockerfilePath
-			}
-
-			dockerfile, err := getter(filepath.Join(image.ContextDir, dockerFilePath))
+			dockerfile, err := getDockerfile(getter, image, dockerfileNames)
 			if err != nil {
-				return fmt.Errorf("failed to get dockerfile %s: %w", image.DockerfilePath, err)
+				return err
 			}
 
 			hasNonEmptyDockerfile = hasNonEmptyDockerfile || len(dockerfile) > 0
@@ -274,6 +341,10 @@ func replacer(
 			allReplacementCandidates.Insert(replacementCandidates.UnsortedList()...)
 		}
 
+		if recordDockerfileResult != nil {
+			recordDockerfileResult(info.Org, info.Repo, hasNonEmptyDockerfile)
+		}
+
 		if pruneUnusedReplacementsEnabled && hasNonEmptyDockerfile {
 			if err := pruneUnusedReplacements(config, allReplacementCandidates); err != nil {
 				return fmt.Errorf("failed to prune unused replacements: %w", err)
@@ -295,6 +366,9 @@ func replacer(
 
 		// Avoid filesystem access if possible
 		if bytes.Equal(originalConfig, newConfig) {
+			if unchanged != nil && hasNonEmptyDockerfile {
+				unchanged.record(info.Filename, fingerprint)
+			}
 			return nil
 		}
 
@@ -306,6 +380,33 @@ func replacer(
 	}
 }
 
+// dockerfilePaths returns the paths the Dockerfile of the image may have, in
+// the order they are tried. Only the dockerfile_path is considered if it is set.
+func dockerfilePaths(image api.ProjectDirectoryImageBuildStepConfiguration, dockerfileNames []string) []string {
+	if image.DockerfilePath != "" {
+		return []string{filepath.Join(image.ContextDir, image.DockerfilePath)}
+	}
+	var paths []string
+	for _, name := range dockerfileNames {
+		paths = append(paths, filepath.Join(image.ContextDir, name))
+	}
+	return paths
+}
+
+// getDockerfile returns the first non-empty Dockerfile of the image
+func getDockerfile(getter github.FileGetter, image api.ProjectDirectoryImageBuildStepConfiguration, dockerfileNames []string) ([]byte, error) {
+	for _, path := range dockerfilePaths(image, dockerfileNames) {
+		dockerfile, err := getter(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get dockerfile %s: %w", path, err)
+		}
+		if len(dockerfile) > 0 {
+			return dockerfile, nil
+		}
+	}
+	return nil, nil
+}
+
 var registryRegex = regexp.MustCompile(`registry\.(|svc\.)ci\.openshift\.org/\S+`)
 
 type orgRepoTag struct{ org, repo, tag string }
@@ -387,7 +488,7 @@ func orgRepoTagFromPullString(pullString string) (orgRepoTag, error) {
 	return res, nil
 }
 
-func upsertPR(gc pgithub.Client, dir, githubUsername string, token []byte, selfApprove, pruneUnusedReplacements, ensureCorrectPromotionDockerfile bool) error {
+func upsertPR(gc pgithub.Client, dir, githubUsername string, token []byte, selfApprove, pruneUnusedReplacements, ensureCorrectPromotionDockerfile bool, reposWithEmptyDockerfiles []string, emptyDockerfileThreshold int) error {
 	if err := os.Chdir(dir); err != nil {
 		return fmt.Errorf("failed to chdir into %s: %w", dir, err)
 	}
@@ -402,9 +503,17 @@ func upsertPR(gc pgithub.Client, dir, githubUsername string, token []byte, selfA
 		return nil
 	}
 
-	censor := censor{secret: token}
-	stdout := bumper.HideSecretsWriter{Delegate: os.Stdout, Censor: &censor}
-	stderr := bumper.HideSecretsWriter{Delegate: os.Stderr, Censor: &censor}
+	censor := secrets.NewDynamicCensor()
+	censor.AddSecrets(string(token))
+	stdout := secrets.NewCensoringWriter(os.Stdout, &censor)
+	stderr := secrets.NewCensoringWriter(os.Stderr, &censor)
+	defer func() {
+		for _, w := range []*secrets.CensoringWriter{stdout, stderr} {
+			if err := w.Flush(); err != nil {
+				logrus.WithError(err).Warn("Failed to flush output")
+			}
+		}
+	}()
 
 	const targetBranch = "registry-replacer"
 	if err := bumper.GitCommitAndPush(
@@ -435,6 +544,7 @@ func upsertPR(gc pgithub.Client, dir, githubUsername string, token []byte, selfA
 	if ensureCorrectPromotionDockerfile {
 		prBody += "\n* Ensures the Dockerfiles used for promotion jobs matches the ones configured in [ocp-build-data](https://github.com/openshift/ocp-build-data/tree/openshift-4.6/images)"
 	}
+	prBody += reposWithEmptyDockerfilesSection(reposWithEmptyDockerfiles, emptyDockerfileThreshold)
 	if err := bumper.UpdatePullRequestWithLabels(
 		gc,
 		"openshift",
@@ -455,12 +565,15 @@ func upsertPR(gc pgithub.Client, dir, githubUsername string, token []byte, selfA
 
 const prTitle = "Registry-Replacer autoupdate"
 
-type censor struct {
-	secret []byte
-}
-
-func (c *censor) Censor(data []byte) []byte {
-	return bytes.ReplaceAll(data, c.secret, []byte("<< REDACTED >>"))
+func reposWithEmptyDockerfilesSection(repos []string, threshold int) string {
+	if len(repos) == 0 {
+		return ""
+	}
+	section := fmt.Sprintf("\n\nWe only got empty Dockerfiles for the following repos for at least %d runs in a row, so their unused replacements are never pruned. We might lack permissions for them or they might have been renamed:", threshold)
+	for _, repo := range repos {
+		section += fmt.Sprintf("\n* %s", repo)
+	}
+	return section
 }
 
 // applyReplacementsToDockerfile duplicates what the build tools would do
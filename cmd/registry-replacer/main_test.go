@@ -27,7 +27,7 @@ func TestReplacer(t *testing.T) {
 		ensureCorrectPromotionDockerfileIngoredRepos sets.String
 		promotionTargetToDockerfileMapping           map[string]dockerfileLocation
 		files                                        map[string][]byte
-		credentials                                  *usernameToken
+		getterOpts                                   []github.Opt
 		expectWrite                                  bool
 		epectedOpts                                  github.Opts
 	}{
@@ -45,6 +45,25 @@ func TestReplacer(t *testing.T) {
 			files:       map[string][]byte{"Dockerfile": []byte("FROM registry.svc.ci.openshift.org/org/repo:tag")},
 			expectWrite: true,
 		},
+		{
+			name: "Containerfile is used without a Dockerfile",
+			config: &api.ReleaseBuildConfiguration{
+				Images: []api.ProjectDirectoryImageBuildStepConfiguration{{}},
+			},
+			files:       map[string][]byte{"Containerfile": []byte("FROM registry.svc.ci.openshift.org/org/repo:tag")},
+			expectWrite: true,
+		},
+		{
+			name: "Dockerfile is preferred over a Containerfile",
+			config: &api.ReleaseBuildConfiguration{
+				Images: []api.ProjectDirectoryImageBuildStepConfiguration{{}},
+			},
+			files: map[string][]byte{
+				"Dockerfile":    []byte("FROM registry.svc.ci.openshift.org/org/repo:tag"),
+				"Containerfile": []byte("FROM registry.svc.ci.openshift.org/org/other:tag"),
+			},
+			expectWrite: true,
+		},
 		{
 			name: "Existing base_image is not overwritten",
 			config: &api.ReleaseBuildConfiguration{
@@ -334,7 +353,7 @@ func TestReplacer(t *testing.T) {
 			},
 			ensureCorrectPromotionDockerfile:   true,
 			promotionTargetToDockerfileMapping: map[string]dockerfileLocation{fmt.Sprintf("registry.svc.ci.openshift.org/ocp/%s:promotionTarget", majorMinor.String()): {contextDir: "some_dir", dockerfile: "Dockerfile.rhel"}},
-			credentials:                        &usernameToken{username: "some-user", token: "some-token"},
+			getterOpts:                         []github.Opt{github.WithAuthentication("some-user", "some-token")},
 			epectedOpts:                        github.Opts{BasicAuthUser: "some-user", BasicAuthPassword: "some-token"},
 		},
 	}
@@ -355,6 +374,10 @@ func TestReplacer(t *testing.T) {
 				tc.ensureCorrectPromotionDockerfileIngoredRepos,
 				tc.promotionTargetToDockerfileMapping,
 				majorMinor,
+				defaultDockerfileNames,
+				tc.getterOpts,
+				nil,
+				nil,
 				nil,
 			)(tc.config, &config.Info{}); err != nil {
 				t.Errorf("replacer failed: %v", err)
@@ -396,6 +419,69 @@ func fakeGithubFileGetterFactory(data map[string][]byte) (*github.Opts, func(str
 	}
 }
 
+func TestReplacerSkipsUnchangedConfigs(t *testing.T) {
+	// The config already has the replacement for the Dockerfile, so it never needs changes
+	newConfig := func() *api.ReleaseBuildConfiguration {
+		return &api.ReleaseBuildConfiguration{
+			InputConfiguration: api.InputConfiguration{
+				BaseImages: map[string]api.ImageStreamTagReference{"ocp_4.8_base": {Namespace: "ocp", Name: "4.8", Tag: "base"}},
+			},
+			Images: []api.ProjectDirectoryImageBuildStepConfiguration{{
+				ProjectDirectoryImageBuildInputs: api.ProjectDirectoryImageBuildInputs{
+					ContextDir: "images",
+					Inputs:     map[string]api.ImageBuildInputs{"ocp_4.8_base": {As: []string{"registry.ci.openshift.org/ocp/4.8:base"}}},
+				},
+				To: "image",
+			}},
+		}
+	}
+	dockerfileSHA := "sha"
+	var fetches int
+	fileGetterFactory := func(_, _, _ string, _ ...github.Opt) github.FileGetter {
+		return func(path string) ([]byte, error) {
+			fetches++
+			return []byte("FROM registry.ci.openshift.org/ocp/4.8:base"), nil
+		}
+	}
+	blobSHAGetterFactory := func(_, _, _ string, _ ...github.Opt) github.BlobSHAGetter {
+		return func(path string) (string, error) {
+			switch path {
+			case "images/Dockerfile":
+				return dockerfileSHA, nil
+			case "images/Containerfile":
+				return "", nil
+			}
+			t.Errorf("got unexpected path %s", path)
+			return "", nil
+		}
+	}
+
+	s := &state{}
+	run := func() bool {
+		unchanged := s.unchangedConfigs()
+		fakeWriter := &fakeWriter{}
+		if err := replacer(fileGetterFactory, fakeWriter.Write, false, false, false, nil, nil, ocpbuilddata.MajorMinor{}, defaultDockerfileNames, nil, nil, blobSHAGetterFactory, unchanged)(newConfig(), &config.Info{Filename: "config.yaml"}); err != nil {
+			t.Fatalf("replacer failed: %v", err)
+		}
+		if fakeWriter.data != nil {
+			t.Fatalf("expected no changes, got %s", string(fakeWriter.data))
+		}
+		s.updateUnchangedConfigs(unchanged)
+		return len(s.UnchangedConfigs) == 1
+	}
+
+	if !run() || fetches != 1 {
+		t.Fatalf("expected the config to be processed and recorded as unchanged, got %d fetches", fetches)
+	}
+	if !run() || fetches != 1 {
+		t.Errorf("expected the config to be skipped, got %d fetches", fetches)
+	}
+	dockerfileSHA = "changed"
+	if !run() || fetches != 2 {
+		t.Errorf("expected the config to be processed after its Dockerfile changed, got %d fetches", fetches)
+	}
+}
+
 func TestExtractReplacementCandidatesFromDockerfile(t *testing.T) {
 	testCases := []struct {
 		name           string
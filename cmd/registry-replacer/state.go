@@ -0,0 +1,152 @@
+package main
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/github"
+)
+
+// state is persisted across runs
+type state struct {
+	// ConsecutiveEmptyDockerfileRuns counts for every repo the number of runs in a row
+	// in which we only got empty Dockerfiles for it.
+	ConsecutiveEmptyDockerfileRuns map[string]int `json:"consecutive_empty_dockerfile_runs,omitempty"`
+	// UnchangedConfigs holds for every config that did not need changes in the last run
+	// the fingerprint of the inputs it was processed with.
+	UnchangedConfigs map[string]string `json:"unchanged_configs,omitempty"`
+}
+
+func loadState(path string) (*state, error) {
+	s := &state{}
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, fmt.Errorf("failed to read state file %s: %w", path, err)
+	}
+	if err := yaml.Unmarshal(raw, s); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal state file %s: %w", path, err)
+	}
+	return s, nil
+}
+
+func (s *state) write(path string) error {
+	raw, err := yaml.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("failed to marshal state: %w", err)
+	}
+	if err := ioutil.WriteFile(path, raw, 0644); err != nil {
+		return fmt.Errorf("failed to write state file %s: %w", path, err)
+	}
+	return nil
+}
+
+// update records the results of a run. Repos that were not part of the
+// run are dropped, as their configs got removed.
+func (s *state) update(results *dockerfileResults) {
+	previous := s.ConsecutiveEmptyDockerfileRuns
+	s.ConsecutiveEmptyDockerfileRuns = map[string]int{}
+	for repo, hasNonEmptyDockerfile := range results.results {
+		if !hasNonEmptyDockerfile {
+			s.ConsecutiveEmptyDockerfileRuns[repo] = previous[repo] + 1
+		}
+	}
+}
+
+// reposWithEmptyDockerfiles returns the repos for which we only got empty
+// Dockerfiles for at least threshold runs in a row.
+func (s *state) reposWithEmptyDockerfiles(threshold int) []string {
+	var repos []string
+	for repo, runs := range s.ConsecutiveEmptyDockerfileRuns {
+		if runs >= threshold {
+			repos = append(repos, repo)
+		}
+	}
+	sort.Strings(repos)
+	return repos
+}
+
+// dockerfileResults records for every repo whether we got at least one
+// non-empty Dockerfile for any of its configs.
+type dockerfileResults struct {
+	lock    sync.Mutex
+	results map[string]bool
+}
+
+func newDockerfileResults() *dockerfileResults {
+	return &dockerfileResults{results: map[string]bool{}}
+}
+
+func (r *dockerfileResults) record(org, repo string, hasNonEmptyDockerfile bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	key := fmt.Sprintf("%s/%s", org, repo)
+	r.results[key] = r.results[key] || hasNonEmptyDockerfile
+}
+
+// unchangedConfigs tracks the configs that did not need changes, so they can be
+// skipped as long as the fingerprint of their inputs stays the same.
+type unchangedConfigs struct {
+	lock     sync.Mutex
+	previous map[string]string
+	current  map[string]string
+}
+
+func (s *state) unchangedConfigs() *unchangedConfigs {
+	return &unchangedConfigs{previous: s.UnchangedConfigs, current: map[string]string{}}
+}
+
+// updateUnchangedConfigs records the configs that did not need changes in a run.
+// Configs that were not part of the run are dropped.
+func (s *state) updateUnchangedConfigs(unchanged *unchangedConfigs) {
+	s.UnchangedConfigs = unchanged.current
+}
+
+// isUnchanged returns if the config did not need changes in the last run and its inputs are
+// still the same. If so, it is carried over to the current run.
+func (u *unchangedConfigs) isUnchanged(filename, fingerprint string) bool {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	if u.previous[filename] != fingerprint {
+		return false
+	}
+	u.current[filename] = fingerprint
+	return true
+}
+
+func (u *unchangedConfigs) record(filename, fingerprint string) {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	u.current[filename] = fingerprint
+}
+
+// inputFingerprint hashes everything the processing of a config depends on: the config itself,
+// the blob SHAs of its Dockerfiles and the pruning settings.
+func inputFingerprint(config *api.ReleaseBuildConfiguration, dockerfileNames []string, getBlobSHA github.BlobSHAGetter, pruneUnusedReplacements, pruneOCPBuilderReplacements bool) (string, error) {
+	raw, err := yaml.Marshal(config)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal config: %w", err)
+	}
+	hash := sha256.New()
+	hash.Write(raw)
+	fmt.Fprintf(hash, "prune-unused-replacements=%t,prune-ocp-builder-replacements=%t\n", pruneUnusedReplacements, pruneOCPBuilderReplacements)
+	for _, image := range config.Images {
+		for _, path := range dockerfilePaths(image, dockerfileNames) {
+			sha, err := getBlobSHA(path)
+			if err != nil {
+				return "", fmt.Errorf("failed to get the blob SHA of %s: %w", path, err)
+			}
+			fmt.Fprintf(hash, "%s=%s\n", path, sha)
+		}
+	}
+	return fmt.Sprintf("%x", hash.Sum(nil)), nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.yaml")
+	s, err := loadState(path)
+	if err != nil {
+		t.Fatalf("failed to load missing state: %v", err)
+	}
+
+	// A repo only counts as empty if all of its configs only had empty Dockerfiles
+	runs := []map[string][]bool{
+		{"org/empty": {false}, "org/renamed": {false}, "org/mixed": {false, true}},
+		{"org/empty": {false, false}, "org/renamed": {false}, "org/mixed": {true}},
+		{"org/empty": {false}, "org/mixed": {false}},
+	}
+	for _, run := range runs {
+		results := newDockerfileResults()
+		for repo, nonEmpty := range run {
+			for _, value := range nonEmpty {
+				orgRepo := strings.SplitN(repo, "/", 2)
+				results.record(orgRepo[0], orgRepo[1], value)
+			}
+		}
+		s.update(results)
+		if err := s.write(path); err != nil {
+			t.Fatalf("failed to write state: %v", err)
+		}
+		if s, err = loadState(path); err != nil {
+			t.Fatalf("failed to load state: %v", err)
+		}
+	}
+
+	if diff := cmp.Diff(map[string]int{"org/empty": 3, "org/mixed": 1}, s.ConsecutiveEmptyDockerfileRuns); diff != "" {
+		t.Errorf("state differs from expected: %s", diff)
+	}
+	if diff := cmp.Diff([]string{"org/empty"}, s.reposWithEmptyDockerfiles(2)); diff != "" {
+		t.Errorf("repos with empty Dockerfiles differ from expected: %s", diff)
+	}
+}
@@ -550,7 +550,13 @@ func run(o options) error {
 		if len(replacement.tests) == 0 {
 			continue
 		}
-		updatedConfig.Tests = append(updatedConfig.Tests, replacement.tests...)
+		// The loaded configurations are shared, so build new collections rather than modifying them
+		updatedConfig.Tests = append(append([]api.TestStepConfiguration{}, updatedConfig.Tests...), replacement.tests...)
+		baseImages := map[string]api.ImageStreamTagReference{}
+		for name, ist := range updatedConfig.BaseImages {
+			baseImages[name] = ist
+		}
+		updatedConfig.BaseImages = baseImages
 		for name, ist := range replacement.baseImages {
 			if _, ok := updatedConfig.BaseImages[name]; !ok {
 				updatedConfig.BaseImages[name] = ist
@@ -3,6 +3,8 @@
 // prow as well as ci-operator. It is not intended to replace
 // manual interaction with the configuration, especially for all
 // complicated scenarios, but to provide a good set of defaults.
+// The answers to the prompts can also be provided in a file, so
+// onboarding can be automated without the interactive mode.
 package main
 
 import (
@@ -17,6 +19,7 @@ import (
 	"reflect"
 	"strings"
 
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
 	prowconfig "k8s.io/test-infra/prow/config"
 	"k8s.io/test-infra/prow/interrupts"
@@ -30,12 +33,16 @@ import (
 type options struct {
 	releaseRepo string
 	config      string
+	answersFile string
 }
 
 func (o *options) Validate() error {
 	if o.releaseRepo == "" {
 		return errors.New("--release-repo is required")
 	}
+	if o.config != "" && o.answersFile != "" {
+		return errors.New("--config and --answers-file are mutually exclusive")
+	}
 	return nil
 }
 
@@ -44,6 +51,7 @@ func gatherOptions() options {
 	fs := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
 	fs.StringVar(&o.releaseRepo, "release-repo", "", "Path to the root of the openshift/release repository.")
 	fs.StringVar(&o.config, "config", "", "JSON configuration to use instead of the interactive mode.")
+	fs.StringVar(&o.answersFile, "answers-file", "", "Path to a YAML file with the answers to use instead of the interactive mode. Omitted answers get the defaults of the prompts.")
 	if err := fs.Parse(os.Args[1:]); err != nil {
 		fmt.Printf("ERROR: could not parse input: %v", err)
 		os.Exit(1)
@@ -101,6 +109,16 @@ In order to generate a new set of configurations, some information will be neces
 		if err := json.Unmarshal([]byte(o.config), &config); err != nil {
 			errorExit(fmt.Sprintf("could not unmarshal provided configuration: %v", err))
 		}
+	} else if o.answersFile != "" {
+		fmt.Println("Loading configuration from the answers file ...")
+		answers, err := loadAnswers(o.answersFile)
+		if err != nil {
+			errorExit(fmt.Sprintf("could not load answers: %v", err))
+		}
+		if err := validateAnswers(answers, o.releaseRepo); err != nil {
+			errorExit(fmt.Sprintf("invalid answers: %v", err))
+		}
+		config = answers
 	} else {
 		fmt.Println(`
 Let's start with general information about the repository...`)
@@ -273,6 +291,103 @@ create this run without using the interactive interface:
 	}
 }
 
+// loadAnswers reads the answers to the prompts from a file, defaulting the
+// answers that were omitted the same way the prompts do
+func loadAnswers(path string) (initConfig, error) {
+	var config initConfig
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return config, fmt.Errorf("could not read answers file: %w", err)
+	}
+	if err := yaml.UnmarshalStrict(raw, &config); err != nil {
+		return config, fmt.Errorf("could not unmarshal answers file: %w", err)
+	}
+	if config.Branch == "" {
+		config.Branch = "master"
+	}
+	if config.GoVersion == "" {
+		config.GoVersion = "1.13"
+	}
+	for i := range config.Tests {
+		if config.Tests[i].From == "" {
+			config.Tests[i].From = api.PipelineImageStreamTagReferenceSource
+		}
+	}
+	for i := range config.CustomE2E {
+		if config.CustomE2E[i].Profile == "" {
+			config.CustomE2E[i].Profile = api.ClusterProfileAWS
+		}
+	}
+	if config.ReleaseType != "" && config.ReleaseVersion == "" {
+		config.ReleaseVersion = "4.6"
+	}
+	return config, nil
+}
+
+// validateAnswers holds the answers from a file to the same constraints the
+// prompts enforce on the interactive answers
+func validateAnswers(config initConfig, releaseRepo string) error {
+	var errs []error
+	if config.Org == "" {
+		errs = append(errs, errors.New("org: must be set"))
+	}
+	if config.Repo == "" {
+		errs = append(errs, errors.New("repo: must be set"))
+	}
+	if config.Org != "" && config.Repo != "" {
+		configPath := path.Join(releaseRepo, "ci-operator", "config", config.Org, config.Repo)
+		if _, err := os.Stat(configPath); err == nil {
+			errs = append(errs, fmt.Errorf("configuration for %s/%s already exists at %s", config.Org, config.Repo, configPath))
+		}
+	}
+
+	names := sets.NewString()
+	validateName := func(field, name string) {
+		switch {
+		case name == "":
+			errs = append(errs, fmt.Errorf("%s.as: must be set", field))
+		case names.Has(name):
+			errs = append(errs, fmt.Errorf("%s.as: a test named %s already exists", field, name))
+		default:
+			names.Insert(name)
+		}
+	}
+	sources := map[api.PipelineImageStreamTagReference]string{
+		api.PipelineImageStreamTagReferenceSource:       "",
+		api.PipelineImageStreamTagReferenceBinaries:     config.BuildCommands,
+		api.PipelineImageStreamTagReferenceTestBinaries: config.TestBuildCommands,
+	}
+	for i, test := range config.Tests {
+		field := fmt.Sprintf("tests[%d]", i)
+		validateName(field, test.As)
+		if commands, valid := sources[test.From]; !valid {
+			errs = append(errs, fmt.Errorf("%s.from: must be one of %s, %s or %s", field, api.PipelineImageStreamTagReferenceSource, api.PipelineImageStreamTagReferenceBinaries, api.PipelineImageStreamTagReferenceTestBinaries))
+		} else if test.From != api.PipelineImageStreamTagReferenceSource && commands == "" {
+			errs = append(errs, fmt.Errorf("%s.from: %s requires the commands to build them", field, test.From))
+		}
+		if test.Command == "" {
+			errs = append(errs, fmt.Errorf("%s.command: must be set", field))
+		}
+	}
+	clusterProfiles := sets.NewString("gcp", "aws", "azure")
+	for i, test := range config.CustomE2E {
+		field := fmt.Sprintf("custom_e2e[%d]", i)
+		validateName(field, test.As)
+		if !clusterProfiles.Has(string(test.Profile)) {
+			errs = append(errs, fmt.Errorf("%s.profile: cluster profile %s is not valid, must be one of: %s", field, test.Profile, strings.Join(clusterProfiles.List(), ", ")))
+		}
+		if test.Command == "" {
+			errs = append(errs, fmt.Errorf("%s.command: must be set", field))
+		}
+	}
+	if len(config.CustomE2E) > 0 && !config.Promotes {
+		if valid := sets.NewString("nightly", "published"); !valid.Has(config.ReleaseType) {
+			errs = append(errs, fmt.Errorf("release_type: must be one of %s when end-to-end tests are configured for a repository that does not promote", strings.Join(valid.List(), ", ")))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
 func errorExit(msg string) {
 	fmt.Printf("ERROR: %s\n", msg)
 	os.Exit(1)
@@ -1,6 +1,9 @@
 package main
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 
@@ -715,3 +718,119 @@ func TestGenerateCIOperatorConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestLoadAnswers(t *testing.T) {
+	dir, err := ioutil.TempDir("", "repo-init")
+	if err != nil {
+		t.Fatalf("failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "answers.yaml")
+	answers := `org: org
+repo: repo
+promotes: true
+build_commands: make install
+tests:
+- as: unit
+  command: make test-unit
+- as: cmd
+  from: bin
+  command: make test-cmd
+custom_e2e:
+- as: e2e
+  command: make test-e2e
+`
+	if err := ioutil.WriteFile(path, []byte(answers), 0644); err != nil {
+		t.Fatalf("failed to write answers: %v", err)
+	}
+	expected := initConfig{
+		Org:           "org",
+		Repo:          "repo",
+		Branch:        "master",
+		Promotes:      true,
+		GoVersion:     "1.13",
+		BuildCommands: "make install",
+		Tests: []test{
+			{As: "unit", From: api.PipelineImageStreamTagReferenceSource, Command: "make test-unit"},
+			{As: "cmd", From: api.PipelineImageStreamTagReferenceBinaries, Command: "make test-cmd"},
+		},
+		CustomE2E: []e2eTest{{As: "e2e", Profile: api.ClusterProfileAWS, Command: "make test-e2e"}},
+	}
+	actual, err := loadAnswers(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("got incorrect answers: %v", diff.ObjectReflectDiff(actual, expected))
+	}
+
+	if err := ioutil.WriteFile(path, []byte("org: org\npromote: true\n"), 0644); err != nil {
+		t.Fatalf("failed to write answers: %v", err)
+	}
+	if _, err := loadAnswers(path); err == nil {
+		t.Error("expected an error for an unknown answer, got none")
+	}
+}
+
+func TestValidateAnswers(t *testing.T) {
+	releaseRepo, err := ioutil.TempDir("", "repo-init")
+	if err != nil {
+		t.Fatalf("failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(releaseRepo)
+	if err := os.MkdirAll(filepath.Join(releaseRepo, "ci-operator", "config", "org", "existing"), 0755); err != nil {
+		t.Fatalf("failed to create existing configuration: %v", err)
+	}
+
+	var testCases = []struct {
+		name     string
+		config   initConfig
+		expected string
+	}{
+		{
+			name: "valid answers",
+			config: initConfig{
+				Org:               "org",
+				Repo:              "repo",
+				TestBuildCommands: "make test-install",
+				Tests:             []test{{As: "race", From: api.PipelineImageStreamTagReferenceTestBinaries, Command: "make test-race"}},
+				CustomE2E:         []e2eTest{{As: "e2e", Profile: api.ClusterProfileGCP, Command: "make test-e2e"}},
+				ReleaseType:       "nightly",
+			},
+		},
+		{
+			name:     "repository that is already configured",
+			config:   initConfig{Org: "org", Repo: "existing"},
+			expected: "configuration for org/existing already exists at " + filepath.Join(releaseRepo, "ci-operator", "config", "org", "existing"),
+		},
+		{
+			name: "invalid tests",
+			config: initConfig{
+				Org:  "org",
+				Repo: "repo",
+				Tests: []test{
+					{As: "unit", From: api.PipelineImageStreamTagReferenceBinaries, Command: "make test-unit"},
+					{As: "unit", From: "other"},
+				},
+				CustomE2E: []e2eTest{{Profile: "openstack", Command: "make test-e2e"}},
+			},
+			expected: "[tests[0].from: bin requires the commands to build them, tests[1].as: a test named unit already exists, tests[1].from: must be one of src, bin or test-bin, tests[1].command: must be set, custom_e2e[0].as: must be set, custom_e2e[0].profile: cluster profile openstack is not valid, must be one of: aws, azure, gcp, release_type: must be one of nightly, published when end-to-end tests are configured for a repository that does not promote]",
+		},
+		{
+			name:     "missing repository",
+			config:   initConfig{},
+			expected: "[org: must be set, repo: must be set]",
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var actual string
+			if err := validateAnswers(testCase.config, releaseRepo); err != nil {
+				actual = err.Error()
+			}
+			if actual != testCase.expected {
+				t.Errorf("got incorrect error: %v", diff.StringDiff(actual, testCase.expected))
+			}
+		})
+	}
+}
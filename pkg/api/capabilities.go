@@ -0,0 +1,211 @@
+package api
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// Behaviors of ci-operator that are not visible in the configuration, but that
+// the consumers of its results may depend on.
+const (
+	// BehaviorFailureBundle means that a bundle of the state of the test
+	// namespace is gathered into the artifacts when the job fails.
+	BehaviorFailureBundle = "failure-bundle"
+	// BehaviorResourceHints means that containers get environment variables
+	// like GOMAXPROCS that are derived from their resources.
+	BehaviorResourceHints = "resource-hints"
+	// BehaviorCredentialKeysEnv means that the keys of step credentials are
+	// exposed as environment variables.
+	BehaviorCredentialKeysEnv = "credential-keys-env"
+	// BehaviorArchitectureSkipping means that steps restricted to architectures
+	// the build cluster has no nodes of are skipped.
+	BehaviorArchitectureSkipping = "architecture-skipping"
+	// BehaviorPayloadVerification means that assembled release payloads are
+	// verified to contain exactly the images of their stream.
+	BehaviorPayloadVerification = "payload-verification"
+	// BehaviorImageNameValidation means that the names of images in the
+	// pipeline are validated when the graph is constructed.
+	BehaviorImageNameValidation = "image-name-validation"
+)
+
+// Capabilities describes what a ci-operator binary supports, so that tools that
+// generate or serve configurations can detect features the deployed binary does
+// not know about yet.
+type Capabilities struct {
+	// Version is the version of the binary.
+	Version string `json:"version,omitempty"`
+	// StepTypes are the types of steps that can be used in raw_steps.
+	StepTypes []string `json:"step_types"`
+	// TestTypes are the types of tests that can be used in tests.
+	TestTypes []string `json:"test_types"`
+	// ConfigFields are the paths of all fields of the configuration, with
+	// `*` standing for the keys of maps.
+	ConfigFields []string `json:"config_fields"`
+	// Behaviors are the behaviors of the binary that are not visible in the
+	// configuration.
+	Behaviors []string `json:"behaviors"`
+}
+
+// CurrentCapabilities returns the capabilities of the code it is compiled into.
+func CurrentCapabilities() Capabilities {
+	capabilities := Capabilities{
+		StepTypes: jsonFieldNames(reflect.TypeOf(StepConfiguration{}), func(reflect.StructField) bool { return true }),
+		TestTypes: jsonFieldNames(reflect.TypeOf(TestStepConfiguration{}), func(field reflect.StructField) bool {
+			return field.Type.Kind() == reflect.Ptr && strings.Contains(field.Name, "TestConfiguration")
+		}),
+		Behaviors: []string{
+			BehaviorArchitectureSkipping,
+			BehaviorCredentialKeysEnv,
+			BehaviorFailureBundle,
+			BehaviorImageNameValidation,
+			BehaviorPayloadVerification,
+			BehaviorResourceHints,
+		},
+	}
+	fields := sets.NewString()
+	collectFields(reflect.TypeOf(ReleaseBuildConfiguration{}), "", fields, sets.NewString())
+	capabilities.ConfigFields = fields.List()
+	return capabilities
+}
+
+// HasBehavior determines if the binary has the given behavior.
+func (c Capabilities) HasBehavior(behavior string) bool {
+	return sets.NewString(c.Behaviors...).Has(behavior)
+}
+
+// UnsupportedFields returns the paths of the fields that are set in the
+// configuration but not supported by the binary with these capabilities.
+func (c Capabilities) UnsupportedFields(config *ReleaseBuildConfiguration) ([]string, error) {
+	raw, err := json.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal configuration: %w", err)
+	}
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal configuration: %w", err)
+	}
+	fields, parents := sets.NewString(c.ConfigFields...), sets.NewString()
+	for _, field := range c.ConfigFields {
+		for i := strings.LastIndex(field, "."); i > 0; i = strings.LastIndex(field[:i], ".") {
+			parents.Insert(field[:i])
+		}
+	}
+	unsupported := sets.NewString()
+	unsupportedFields(value, "", fields, parents, unsupported)
+	return unsupported.List(), nil
+}
+
+func unsupportedFields(value interface{}, path string, fields, parents, unsupported sets.String) {
+	switch value := value.(type) {
+	case []interface{}:
+		for _, item := range value {
+			unsupportedFields(item, path, fields, parents, unsupported)
+		}
+	case map[string]interface{}:
+		if path != "" && !parents.Has(path) {
+			// the field is opaque to the configuration, e.g. a free-form value
+			return
+		}
+		for key, item := range value {
+			field := joinField(path, key)
+			if !fields.Has(field) {
+				if wildcard := joinField(path, "*"); fields.Has(wildcard) {
+					field = wildcard
+				} else {
+					unsupported.Insert(field)
+					continue
+				}
+			}
+			unsupportedFields(item, field, fields, parents, unsupported)
+		}
+	}
+}
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// collectFields records the paths of all fields that can be serialized for
+// the given type. Types with custom serialization are treated as values.
+func collectFields(t reflect.Type, path string, fields, visiting sets.String) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	for _, candidate := range []reflect.Type{t, reflect.PtrTo(t)} {
+		if candidate.Implements(jsonMarshalerType) || candidate.Implements(textMarshalerType) {
+			return
+		}
+	}
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		collectFields(t.Elem(), path, fields, visiting)
+	case reflect.Map:
+		wildcard := joinField(path, "*")
+		fields.Insert(wildcard)
+		collectFields(t.Elem(), wildcard, fields, visiting)
+	case reflect.Struct:
+		// recursive types would otherwise never terminate
+		id := t.PkgPath() + "." + t.Name()
+		if visiting.Has(id) {
+			return
+		}
+		visiting.Insert(id)
+		defer visiting.Delete(id)
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name, inline := jsonName(field)
+			switch {
+			case name == "-":
+			case inline:
+				collectFields(field.Type, path, fields, visiting)
+			default:
+				fieldPath := joinField(path, name)
+				fields.Insert(fieldPath)
+				collectFields(field.Type, fieldPath, fields, visiting)
+			}
+		}
+	}
+}
+
+// jsonName returns the name under which encoding/json serializes the field,
+// "-" if it does not, and whether its fields are serialized inline instead.
+func jsonName(field reflect.StructField) (string, bool) {
+	tag := strings.Split(field.Tag.Get("json"), ",")
+	name := tag[0]
+	if field.Anonymous && name == "" {
+		return "", true
+	}
+	if field.PkgPath != "" || name == "-" && len(tag) == 1 {
+		return "-", false
+	}
+	if name == "" {
+		name = field.Name
+	}
+	return name, false
+}
+
+func jsonFieldNames(t reflect.Type, include func(reflect.StructField) bool) []string {
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if name, inline := jsonName(field); name != "-" && !inline && include(field) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func joinField(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
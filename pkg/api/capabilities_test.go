@@ -0,0 +1,88 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+func TestCurrentCapabilities(t *testing.T) {
+	capabilities := CurrentCapabilities()
+	for _, field := range []string{
+		"zz_generated_metadata.org",
+		"base_images.*.name",
+		"build_root.image_stream_tag.tag",
+		"images.build_args.value",
+		"tests.source_snapshot",
+		"tests.literal_steps.test.commands",
+		"promotion.excluded_images",
+	} {
+		if !sets.NewString(capabilities.ConfigFields...).Has(field) {
+			t.Errorf("expected config fields to contain %s", field)
+		}
+	}
+	if !sets.NewString(capabilities.StepTypes...).HasAll("source_step", "test_step") {
+		t.Errorf("expected step types to contain source_step and test_step, got %v", capabilities.StepTypes)
+	}
+	if !sets.NewString(capabilities.TestTypes...).HasAll("container", "steps", "literal_steps") {
+		t.Errorf("expected test types to contain container, steps and literal_steps, got %v", capabilities.TestTypes)
+	}
+	if sets.NewString(capabilities.TestTypes...).Has("cluster_claim") {
+		t.Error("expected test types not to contain cluster_claim")
+	}
+	if !capabilities.HasBehavior(BehaviorFailureBundle) {
+		t.Errorf("expected behavior %s", BehaviorFailureBundle)
+	}
+}
+
+func TestUnsupportedFields(t *testing.T) {
+	config := &ReleaseBuildConfiguration{
+		InputConfiguration: InputConfiguration{
+			BaseImages: map[string]ImageStreamTagReference{"base": {Namespace: "ocp", Name: "4.9", Tag: "base"}},
+		},
+		SourceSnapshots: []SourceSnapshot{{As: "other"}},
+		Tests: []TestStepConfiguration{{
+			As:             "unit",
+			SourceSnapshot: "other",
+			ContainerTestConfiguration: &ContainerTestConfiguration{
+				From: "src",
+			},
+		}},
+		Resources: ResourceConfiguration{"*": {Requests: ResourceList{"cpu": "100m"}}},
+	}
+	current := CurrentCapabilities()
+
+	var testCases = []struct {
+		name         string
+		capabilities Capabilities
+		expected     []string
+	}{
+		{
+			name:         "all fields are supported",
+			capabilities: current,
+			expected:     []string{},
+		},
+		{
+			name: "unsupported fields are reported",
+			capabilities: Capabilities{ConfigFields: sets.NewString(current.ConfigFields...).Delete(
+				"source_snapshots",
+				"tests.source_snapshot",
+				"base_images.*.namespace",
+			).List()},
+			expected: []string{"base_images.*.namespace", "source_snapshots", "tests.source_snapshot"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := tc.capabilities.UnsupportedFields(config)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Errorf("unexpected unsupported fields: %s", diff)
+			}
+		})
+	}
+}
@@ -35,6 +35,7 @@ func (config *ReleaseBuildConfiguration) Default() {
 	}
 	defTest := func(t *TestStepConfiguration) {
 		defClusterClaim(t.ClusterClaim)
+		defLeases(t.Leases)
 		if s := t.MultiStageTestConfigurationLiteral; s != nil {
 			defLeases(s.Leases)
 			for i := range s.Pre {
@@ -56,6 +57,12 @@ func (config *ReleaseBuildConfiguration) Default() {
 	for i := range config.Tests {
 		defTest(&config.Tests[i])
 	}
+	for alias, image := range config.ExternalImages {
+		if image.Policy == "" {
+			image.Policy = ExternalImagePolicyPin
+			config.ExternalImages[alias] = image
+		}
+	}
 }
 
 // ImageStreamFor guesses at the ImageStream that will hold a tag.
@@ -77,6 +84,9 @@ func (config *ReleaseBuildConfiguration) ImageStreamFor(image string) (string, b
 // DependencyParts returns the imageStream and tag name from a user-provided
 // reference to an image in the test namespace
 func (config *ReleaseBuildConfiguration) DependencyParts(dependency StepDependency) (string, string, bool) {
+	if dependency.Release != "" {
+		return ReleaseStreamFor(dependency.Release), dependency.Name, true
+	}
 	if !strings.Contains(dependency.Name, ":") {
 		stream, explicit := config.ImageStreamFor(dependency.Name)
 		return stream, dependency.Name, explicit
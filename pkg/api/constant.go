@@ -20,6 +20,9 @@ const (
 
 	KVMDeviceLabel = "devices.kubevirt.io/kvm"
 	ClusterLabel   = "ci-operator.openshift.io/cluster"
+	// ArchitectureLabel is the label on a job generated for a test on one of its
+	// additional architectures, so it is dispatched to a cluster with nodes of it
+	ArchitectureLabel = "ci-operator.openshift.io/architecture"
 
 	// HiveCluster is the cluster where Hive is deployed
 	HiveCluster = ClusterAPPCI
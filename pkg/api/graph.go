@@ -166,6 +166,15 @@ func InternalImageLink(tag PipelineImageStreamTagReference, o ...StepLinkOption)
 	}
 }
 
+// ImageStreamTagFor returns the image stream and tag in the test namespace a
+// link describes, if the link describes a single tag.
+func ImageStreamTagFor(link StepLink) (string, string, bool) {
+	if l, ok := link.(*internalImageStreamTagLink); ok {
+		return l.name, l.tag, true
+	}
+	return "", "", false
+}
+
 func ReleasePayloadImageLink(tag string) StepLink {
 	return &internalImageStreamTagLink{
 		name: ReleaseImageStream,
@@ -0,0 +1,98 @@
+package ocpbuilddata
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// DefaultGoStream is the stream in streams.yml with the golang builder a
+// release uses by default
+const DefaultGoStream = "golang"
+
+// goVersionRegex matches the golang version in the pull spec or tag of a
+// builder image, e.g. rhel-8-golang-1.15-openshift-4.8 or rhel_8_golang_1.15
+var goVersionRegex = regexp.MustCompile(`golang([-_])(\d+\.\d+)`)
+
+// GoVersionOf returns the major.minor golang version of a builder image, or
+// an empty string if the pull spec or tag is not the one of a golang builder
+func GoVersionOf(pullSpecOrTag string) string {
+	if match := goVersionRegex.FindStringSubmatch(pullSpecOrTag); match != nil {
+		return match[2]
+	}
+	return ""
+}
+
+// ReplaceGoVersion returns the pull spec or tag of a golang builder with the
+// golang version replaced
+func ReplaceGoVersion(pullSpecOrTag, version string) string {
+	return goVersionRegex.ReplaceAllString(pullSpecOrTag, "golang${1}"+version)
+}
+
+// GoBuilder is a golang builder image from streams.yml
+type GoBuilder struct {
+	// Stream is the name of the stream
+	Stream string
+	// PullSpec is the pull spec of the image CI uses for the stream
+	PullSpec string
+	// GoVersion is the major.minor golang version of the builder
+	GoVersion string
+}
+
+// GoBuilders returns the streams that are golang builders, sorted by their name
+func (s StreamMap) GoBuilders() []GoBuilder {
+	var builders []GoBuilder
+	for name, stream := range s {
+		version := GoVersionOf(stream.UpstreamImage)
+		if version == "" {
+			version = GoVersionOf(stream.Image)
+		}
+		if version == "" {
+			continue
+		}
+		builders = append(builders, GoBuilder{Stream: name, PullSpec: stream.UpstreamImage, GoVersion: version})
+	}
+	sort.Slice(builders, func(i, j int) bool {
+		return builders[i].Stream < builders[j].Stream
+	})
+	return builders
+}
+
+// GoVersion returns the golang version of the builder of the stream
+func (s StreamMap) GoVersion(stream string) (string, error) {
+	element, ok := s[stream]
+	if !ok {
+		return "", fmt.Errorf("streams.yml has no stream %s", stream)
+	}
+	version := GoVersionOf(element.UpstreamImage)
+	if version == "" {
+		version = GoVersionOf(element.Image)
+	}
+	if version == "" {
+		return "", fmt.Errorf("stream %s is not a golang builder", stream)
+	}
+	return version, nil
+}
+
+// LoadStreams loads the streams.yml of a release from the provided ocp-build-data repo root
+func LoadStreams(ocpBuildDataDir string, majorMinor MajorMinor) (StreamMap, error) {
+	streamMap, err := readStreamMap(ocpBuildDataDir, majorMinor)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read streams file: %w", err)
+	}
+	return streamMap, nil
+}
+
+// LoadGoVersion returns the golang version a release uses by default, according
+// to the streams.yml in the provided ocp-build-data repo root
+func LoadGoVersion(ocpBuildDataDir string, majorMinor MajorMinor) (string, error) {
+	streamMap, err := LoadStreams(ocpBuildDataDir, majorMinor)
+	if err != nil {
+		return "", err
+	}
+	version, err := streamMap.GoVersion(DefaultGoStream)
+	if err != nil {
+		return "", fmt.Errorf("failed to determine the golang version of %s: %w", majorMinor, err)
+	}
+	return version, nil
+}
@@ -0,0 +1,84 @@
+package ocpbuilddata
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGoVersionOf(t *testing.T) {
+	testCases := []struct {
+		pullSpecOrTag string
+		expected      string
+	}{
+		{pullSpecOrTag: "registry.ci.openshift.org/ocp/builder:rhel-8-golang-1.15-openshift-4.8", expected: "1.15"},
+		{pullSpecOrTag: "openshift/golang-builder:rhel_8_golang_1.14", expected: "1.14"},
+		{pullSpecOrTag: "golang-1.16", expected: "1.16"},
+		{pullSpecOrTag: "registry.ci.openshift.org/ocp/4.8:base"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.pullSpecOrTag, func(t *testing.T) {
+			if diff := cmp.Diff(tc.expected, GoVersionOf(tc.pullSpecOrTag)); diff != "" {
+				t.Errorf("got incorrect version: %s", diff)
+			}
+		})
+	}
+}
+
+func TestReplaceGoVersion(t *testing.T) {
+	for pullSpecOrTag, expected := range map[string]string{
+		"rhel-8-golang-1.15-openshift-4.8":            "rhel-8-golang-1.16-openshift-4.8",
+		"openshift/golang-builder:rhel_8_golang_1.15": "openshift/golang-builder:rhel_8_golang_1.16",
+		"base": "base",
+	} {
+		if diff := cmp.Diff(expected, ReplaceGoVersion(pullSpecOrTag, "1.16")); diff != "" {
+			t.Errorf("%s: got incorrect result: %s", pullSpecOrTag, diff)
+		}
+	}
+}
+
+func TestStreams(t *testing.T) {
+	dir := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(dir, "streams.yml"), []byte(`golang:
+  image: openshift/golang-builder:rhel_8_golang_1.15
+  mirror: true
+  upstream_image: registry.ci.openshift.org/ocp/builder:rhel-8-golang-1.15-openshift-{MAJOR}.{MINOR}
+rhel-7-golang:
+  image: openshift/golang-builder:rhel_7_golang_1.14
+rhel:
+  image: openshift/ose-base:ubi8
+  mirror: true
+  upstream_image: registry.ci.openshift.org/ocp/{MAJOR}.{MINOR}:base
+`), 0644); err != nil {
+		t.Fatalf("failed to write streams.yml: %v", err)
+	}
+	majorMinor := MajorMinor{Major: "4", Minor: "8"}
+
+	streams, err := LoadStreams(dir, majorMinor)
+	if err != nil {
+		t.Fatalf("failed to load streams: %v", err)
+	}
+	expectedBuilders := []GoBuilder{
+		{Stream: "golang", PullSpec: "registry.ci.openshift.org/ocp/builder:rhel-8-golang-1.15-openshift-4.8", GoVersion: "1.15"},
+		{Stream: "rhel-7-golang", GoVersion: "1.14"},
+	}
+	if diff := cmp.Diff(expectedBuilders, streams.GoBuilders()); diff != "" {
+		t.Errorf("got incorrect builders: %s", diff)
+	}
+	if _, err := streams.GoVersion("rhel"); err == nil || err.Error() != "stream rhel is not a golang builder" {
+		t.Errorf("expected an error for a stream that is not a golang builder, got %v", err)
+	}
+	if _, err := streams.GoVersion("missing"); err == nil || err.Error() != "streams.yml has no stream missing" {
+		t.Errorf("expected an error for a missing stream, got %v", err)
+	}
+
+	version, err := LoadGoVersion(dir, majorMinor)
+	if err != nil {
+		t.Fatalf("failed to load the golang version: %v", err)
+	}
+	if diff := cmp.Diff("1.15", version); diff != "" {
+		t.Errorf("got incorrect golang version: %s", diff)
+	}
+}
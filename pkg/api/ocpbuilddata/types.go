@@ -7,6 +7,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 
@@ -17,13 +18,19 @@ import (
 )
 
 type OCPImageConfig struct {
-	Content        *OCPImageConfigContent `json:"content"`
-	From           OCPImageConfigFrom     `json:"from"`
-	Push           OCPImageConfigPush     `json:"push"`
-	Name           string                 `json:"name"`
-	SourceFileName string                 `json:"-"`
-	Version        MajorMinor             `json:"-"`
-	PublicRepo     OrgRepo                `json:"-"`
+	Content *OCPImageConfigContent `json:"content"`
+	From    OCPImageConfigFrom     `json:"from"`
+	Push    OCPImageConfigPush     `json:"push"`
+	Name    string                 `json:"name"`
+	// Distgit is the dist-git repository ART builds the image from
+	Distgit *OCPConfigDistgit `json:"distgit,omitempty"`
+	// ContainerYAML is the container.yaml that is passed to OSBS for the build
+	ContainerYAML *OCPImageConfigContainerYAML `json:"container_yaml,omitempty"`
+	// EnabledRepos are the RPM repositories that are enabled during the build
+	EnabledRepos   []string   `json:"enabled_repos,omitempty"`
+	SourceFileName string     `json:"-"`
+	Version        MajorMinor `json:"-"`
+	PublicRepo     OrgRepo    `json:"-"`
 }
 
 func (o OCPImageConfig) validate() error {
@@ -47,6 +54,29 @@ func (o OCPImageConfig) PromotesTo() string {
 	return fmt.Sprintf("registry.ci.openshift.org/ocp/%s.%s:%s", o.Version.Major, o.Version.Minor, strings.TrimPrefix(o.Name, "openshift/ose-"))
 }
 
+// DistgitComponent returns the name of the Brew component of the image, which
+// defaults to the name of its config file with a -container suffix.
+func (o OCPImageConfig) DistgitComponent() string {
+	if o.Distgit != nil && o.Distgit.Component != "" {
+		return o.Distgit.Component
+	}
+	return strings.TrimSuffix(filepath.Base(o.SourceFileName), ".yml") + "-container"
+}
+
+// UsesPackageManager returns if the build fetches dependencies through the given
+// package manager, e.g. gomod.
+func (o OCPImageConfig) UsesPackageManager(name string) bool {
+	if o.Content == nil {
+		return false
+	}
+	for _, pkgManager := range o.Content.Source.PkgManagers {
+		if pkgManager == name {
+			return true
+		}
+	}
+	return false
+}
+
 type OCPImageConfigContent struct {
 	Source OCPImageConfigSource `json:"source"`
 }
@@ -66,6 +96,29 @@ type OCPImageConfigSource struct {
 	Path       string `json:"path"`
 	// +Optional, mutually exclusive with alias
 	Git *OCPImageConfigSourceGit `json:"git,omitempty"`
+	// PkgManagers are the package managers whose dependencies are
+	// fetched ahead of the build by Cachito, e.g. gomod
+	PkgManagers []string `json:"pkg_managers,omitempty"`
+}
+
+type OCPConfigDistgit struct {
+	Namespace string `json:"namespace,omitempty"`
+	Component string `json:"component,omitempty"`
+	// Branch defaults to the branch in group.yml
+	Branch string `json:"branch,omitempty"`
+}
+
+type OCPImageConfigContainerYAML struct {
+	Go *OCPImageConfigContainerYAMLGo `json:"go,omitempty"`
+}
+
+type OCPImageConfigContainerYAMLGo struct {
+	Modules []OCPImageConfigContainerYAMLGoModule `json:"modules,omitempty"`
+}
+
+type OCPImageConfigContainerYAMLGoModule struct {
+	Module string `json:"module"`
+	Path   string `json:"path,omitempty"`
 }
 
 type OCPImageConfigSourceGit struct {
@@ -85,16 +138,26 @@ type OCPImageConfigFrom struct {
 type OCPImageConfigFromStream struct {
 	Stream string `json:"stream"`
 	Member string `json:"member"`
+	// Image is a literal pull spec
+	Image string `json:"image,omitempty"`
 }
 
 func (icfs OCPImageConfigFromStream) validate() error {
-	if icfs.Stream == "" && icfs.Member == "" {
-		return errors.New("both stream and member were unset")
+	var set []string
+	for field, value := range map[string]string{"stream": icfs.Stream, "member": icfs.Member, "image": icfs.Image} {
+		if value != "" {
+			set = append(set, fmt.Sprintf("%s(%s)", field, value))
+		}
 	}
-	if icfs.Stream != "" && icfs.Member != "" {
-		return fmt.Errorf("both stream(%s) and member(%s) were set", icfs.Stream, icfs.Member)
+	switch len(set) {
+	case 0:
+		return errors.New("all of stream, member and image were unset")
+	case 1:
+		return nil
+	default:
+		sort.Strings(set)
+		return fmt.Errorf("only one of stream, member and image may be set, got %s", strings.Join(set, ", "))
 	}
-	return nil
 }
 
 type OCPImageConfigPush struct {
@@ -109,35 +172,56 @@ func (oic *OCPImageConfig) Dockerfile() string {
 	return filepath.Join(oic.Content.Source.Path, oic.Content.Source.Dockerfile)
 }
 
+// Stages returns the pull specs of all builders and the final base image in the order
+// in which they appear in the Dockerfile. It must only be called on dereferenced configs.
 func (oic *OCPImageConfig) Stages() ([]string, error) {
 	var result []string
 	var errs []error
 	for idx, builder := range oic.From.Builder {
-		if builder.Stream == "" {
-			errs = append(errs, fmt.Errorf("couldn't dereference from.builder.%d", idx))
+		if err := builder.checkDereferenced(); err != nil {
+			errs = append(errs, fmt.Errorf("couldn't dereference from.builder.%d: %w", idx, err))
 		}
 		result = append(result, builder.Stream)
 	}
-	if oic.From.Stream == "" {
-		errs = append(errs, errors.New("couldn't dereference from.stream"))
+	if err := oic.From.checkDereferenced(); err != nil {
+		errs = append(errs, fmt.Errorf("couldn't dereference from.stream: %w", err))
 	}
 	return append(result, oic.From.Stream), utilerrors.NewAggregate(errs)
 }
 
+// checkDereferenced verifies that the reference was resolved into a pull spec in .stream
+func (icfs OCPImageConfigFromStream) checkDereferenced() error {
+	switch {
+	case icfs.Member != "":
+		return fmt.Errorf("member %s was not resolved", icfs.Member)
+	case icfs.Image != "":
+		return fmt.Errorf("image %s was not resolved", icfs.Image)
+	case icfs.Stream == "":
+		return errors.New("no pull spec")
+	}
+	return nil
+}
+
 func (oic *OCPImageConfig) setPublicOrgRepo(mappings []PublicPrivateMapping) {
-	var name string
-	if oic.Content == nil || oic.Content.Source.Git == nil || oic.Content.Source.Git.URL == "" {
-		name = oic.Name
+	var git *OCPImageConfigSourceGit
+	if oic.Content != nil {
+		git = oic.Content.Source.Git
 	}
-	if name == "" && oic.Content != nil && oic.Content.Source.Git != nil {
-		name = strings.TrimSuffix(strings.TrimPrefix(oic.Content.Source.Git.URL, "git@github.com:"), ".git")
+	oic.PublicRepo = publicOrgRepo(oic.Name, git, mappings)
+}
+
+// publicOrgRepo returns the public repository for the git source, or the given name if there is none
+func publicOrgRepo(name string, git *OCPImageConfigSourceGit, mappings []PublicPrivateMapping) OrgRepo {
+	if git != nil && git.URL != "" {
+		name = strings.TrimSuffix(strings.TrimPrefix(git.URL, "git@github.com:"), ".git")
 	}
 
-	oic.PublicRepo.Org = publicRepo(name, mappings)
-	if split := strings.Split(oic.PublicRepo.Org, "/"); len(split) == 2 {
-		oic.PublicRepo.Org = split[0]
-		oic.PublicRepo.Repo = split[1]
+	result := OrgRepo{Org: publicRepo(name, mappings)}
+	if split := strings.Split(result.Org, "/"); len(split) == 2 {
+		result.Org = split[0]
+		result.Repo = split[1]
 	}
+	return result
 }
 
 type StreamMap map[string]StreamElement
@@ -149,6 +233,8 @@ type StreamElement struct {
 }
 
 type GroupYAML struct {
+	// Branch is the default dist-git branch
+	Branch          string                             `json:"branch,omitempty"`
 	Sources         map[string]OCPImageConfigSourceGit `json:"sources"`
 	PublicUpstreams []PublicPrivateMapping             `json:"public_upstreams,omitempty"`
 }
@@ -198,6 +284,10 @@ func LoadImageConfigs(ocpBuildDataDir string, majorMinor MajorMinor) ([]OCPImage
 	var errs []error
 	var configs []OCPImageConfig
 	for _, cfg := range configsUnverified {
+		// Distgit only repositories, we only need them to dereference members
+		if cfg.Content == nil {
+			continue
+		}
 		if err := cfg.validate(); err != nil {
 			errs = append(errs, fmt.Errorf("error validating %s: %w", cfg.SourceFileName, err))
 			continue
@@ -220,51 +310,41 @@ func dereferenceConfig(
 ) error {
 	var errs []error
 
-	var err error
-	if config.From.Stream != "" {
-		config.From.Stream, err = replaceStream(config.From.Stream, streamMap)
-		if err != nil {
-			errs = append(errs, fmt.Errorf("failed to replace .from.stream: %w", err))
-		}
-	}
-	if config.From.Member != "" {
-		config.From.Stream, err = streamForMember(config.From.Member, allConfigs)
-		if err != nil {
-			errs = append(errs, fmt.Errorf("failed to replace .from.member: %w", err))
-		}
-		config.From.Member = ""
-	}
+	// Copy the builders, they share their backing array with the entry in allConfigs
+	config.From.Builder = append([]OCPImageConfigFromStream(nil), config.From.Builder...)
+
+	errs = append(errs, dereferenceFrom(&config.From.OCPImageConfigFromStream, ".from", allConfigs, streamMap)...)
 	if config.From.Stream == "" {
 		errs = append(errs, errors.New("failed to find replacement for .from.stream"))
 	}
 
 	for blder := range config.From.Builder {
-		if config.From.Builder[blder].Stream != "" {
-			config.From.Builder[blder].Stream, err = replaceStream(config.From.Builder[blder].Stream, streamMap)
-			if err != nil {
-				errs = append(errs, fmt.Errorf("failed to replace .from.%d.stream: %w", blder, err))
-			}
-		}
-		if config.From.Builder[blder].Member != "" {
-			config.From.Builder[blder].Stream, err = streamForMember(config.From.Builder[blder].Member, allConfigs)
-			if err != nil {
-				errs = append(errs, fmt.Errorf("failed to replace .from.%d.member: %w", blder, err))
-			}
-			config.From.Builder[blder].Member = ""
-		}
+		errs = append(errs, dereferenceFrom(&config.From.Builder[blder], fmt.Sprintf(".from.%d", blder), allConfigs, streamMap)...)
 		if config.From.Builder[blder].Stream == "" {
 			errs = append(errs, fmt.Errorf("failed to dereference from.builder.%d", blder))
 		}
 	}
 
 	if config.Content.Source.Alias != "" {
-		if _, hasReplacement := groupYAML.Sources[config.Content.Source.Alias]; !hasReplacement {
-			return fmt.Errorf("groups.yaml has no replacement for alias %s", config.Content.Source.Alias)
+		if source, hasReplacement := groupYAML.Sources[config.Content.Source.Alias]; !hasReplacement {
+			errs = append(errs, fmt.Errorf("groups.yaml has no replacement for alias %s", config.Content.Source.Alias))
+		} else {
+			// Create a new pointer rather than directly creating a pointer to the map value
+			config.Content.Source.Git = &OCPImageConfigSourceGit{}
+			*config.Content.Source.Git = source
+		}
+	}
+
+	if groupYAML.Branch != "" {
+		// Copy the distgit, it is shared with the entry in allConfigs
+		distgit := OCPConfigDistgit{}
+		if config.Distgit != nil {
+			distgit = *config.Distgit
+		}
+		if distgit.Branch == "" {
+			distgit.Branch = groupYAML.Branch
 		}
-		// Create a new pointer and set its value to groupYAML.Sources[config.Content.Source.Alias]
-		// rather than directly creating a pointer to the latter.
-		config.Content.Source.Git = &OCPImageConfigSourceGit{}
-		*config.Content.Source.Git = groupYAML.Sources[config.Content.Source.Alias]
+		config.Distgit = &distgit
 	}
 
 	config.setPublicOrgRepo(groupYAML.PublicUpstreams)
@@ -272,6 +352,36 @@ func dereferenceConfig(
 	return utilerrors.NewAggregate(errs)
 }
 
+// dereferenceFrom resolves the stream, member or image of a from or builder entry into
+// the pull spec in .stream that is used for it in CI.
+func dereferenceFrom(
+	from *OCPImageConfigFromStream,
+	path string,
+	allConfigs map[string]OCPImageConfig,
+	streamMap StreamMap,
+) []error {
+	var errs []error
+	var err error
+	if from.Stream != "" {
+		from.Stream, err = replaceStream(from.Stream, streamMap)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to replace %s.stream: %w", path, err))
+		}
+	}
+	if from.Member != "" {
+		from.Stream, err = streamForMember(from.Member, allConfigs)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to replace %s.member: %w", path, err))
+		}
+		from.Member = ""
+	}
+	if from.Image != "" {
+		from.Stream = from.Image
+		from.Image = ""
+	}
+	return errs
+}
+
 func replaceStream(streamName string, streamMap StreamMap) (string, error) {
 	replacement, hasReplacement := streamMap[streamName]
 	if !hasReplacement {
@@ -340,11 +450,6 @@ func gatherAllOCPImageConfigs(ocpBuildDataDir string, majorMinor MajorMinor) (ma
 				return err
 			}
 
-			// Distgit only repositories
-			if config.Content == nil {
-				return nil
-			}
-
 			config.SourceFileName = strings.TrimPrefix(path, ocpBuildDataDir+"/")
 			config.Version = majorMinor
 			resultLock.Lock()
@@ -378,3 +483,100 @@ func readYAML(path string, unmarshalTarget interface{}, majorMinor MajorMinor) e
 	}
 	return nil
 }
+
+// OCPRPMConfig is the config of an RPM ART builds, from the rpms directory of ocp-build-data
+type OCPRPMConfig struct {
+	Content OCPRPMConfigContent `json:"content"`
+	Name    string              `json:"name"`
+	Distgit *OCPConfigDistgit   `json:"distgit,omitempty"`
+	// Targets are the Brew targets the RPM is built for
+	Targets        []string   `json:"targets,omitempty"`
+	SourceFileName string     `json:"-"`
+	Version        MajorMinor `json:"-"`
+	PublicRepo     OrgRepo    `json:"-"`
+}
+
+type OCPRPMConfigContent struct {
+	Source OCPRPMConfigSource `json:"source"`
+	Build  OCPRPMConfigBuild  `json:"build"`
+}
+
+type OCPRPMConfigSource struct {
+	Alias string `json:"alias,omitempty"`
+	Path  string `json:"path,omitempty"`
+	// Specfile is the path of the spec file in the repository
+	Specfile string `json:"specfile,omitempty"`
+	// +Optional, mutually exclusive with alias
+	Git *OCPImageConfigSourceGit `json:"git,omitempty"`
+}
+
+type OCPRPMConfigBuild struct {
+	// UseSourceTitoConfig means the RPM is built with the tito config in the repository
+	UseSourceTitoConfig bool   `json:"use_source_tito_config,omitempty"`
+	TitoTarget          string `json:"tito_target,omitempty"`
+}
+
+// LoadRPMConfigs loads and dereferences all RPM configs from the provided ocp-build-data repo root
+func LoadRPMConfigs(ocpBuildDataDir string, majorMinor MajorMinor) ([]OCPRPMConfig, error) {
+	groupYAML, err := readGroupYAML(ocpBuildDataDir, majorMinor)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read group file: %w", err)
+	}
+
+	dir := filepath.Join(ocpBuildDataDir, "rpms")
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+
+	var errs []error
+	var configs []OCPRPMConfig
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".yml" {
+			continue
+		}
+		config := OCPRPMConfig{}
+		if err := readYAML(filepath.Join(dir, file.Name()), &config, majorMinor); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		config.SourceFileName = filepath.Join("rpms", file.Name())
+		config.Version = majorMinor
+		if err := dereferenceRPMConfig(&config, groupYAML); err != nil {
+			errs = append(errs, fmt.Errorf("failed dereferencing config for %s: %w", config.SourceFileName, err))
+			continue
+		}
+		configs = append(configs, config)
+	}
+
+	return configs, utilerrors.NewAggregate(errs)
+}
+
+func dereferenceRPMConfig(config *OCPRPMConfig, groupYAML GroupYAML) error {
+	if config.Content.Source.Alias != "" {
+		if config.Content.Source.Git != nil {
+			return errors.New("both content.source.alias and content.source.git are set")
+		}
+		source, hasReplacement := groupYAML.Sources[config.Content.Source.Alias]
+		if !hasReplacement {
+			return fmt.Errorf("groups.yaml has no replacement for alias %s", config.Content.Source.Alias)
+		}
+		config.Content.Source.Git = &source
+	}
+	if config.Content.Source.Git == nil || config.Content.Source.Git.URL == "" {
+		return errors.New("content.source.git.url is unset")
+	}
+	if groupYAML.Branch != "" {
+		if config.Distgit == nil {
+			config.Distgit = &OCPConfigDistgit{}
+		}
+		if config.Distgit.Branch == "" {
+			config.Distgit.Branch = groupYAML.Branch
+		}
+	}
+	config.PublicRepo = publicOrgRepo(config.Name, config.Content.Source.Git, groupYAML.PublicUpstreams)
+	return nil
+}
@@ -3,6 +3,9 @@ package ocpbuilddata
 import (
 	"errors"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -10,6 +13,8 @@ import (
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	utilpointer "k8s.io/utils/pointer"
 	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/ci-tools/pkg/testhelper"
 )
 
 func TestOCPImageConfig(t *testing.T) {
@@ -104,6 +109,136 @@ owners:
 	}
 }
 
+func TestOCPImageConfigBuildMetadata(t *testing.T) {
+	in := []byte(`container_yaml:
+  go:
+    modules:
+    - module: k8s.io/autoscaler
+content:
+  source:
+    dockerfile: images/cluster-autoscaler/Dockerfile.rhel7
+    git:
+      branch:
+        target: release-{MAJOR}.{MINOR}
+      url: git@github.com:openshift-priv/kubernetes-autoscaler.git
+    pkg_managers:
+    - gomod
+distgit:
+  namespace: containers
+enabled_repos:
+- rhel-8-baseos-rpms
+- rhel-8-appstream-rpms
+from:
+  stream: rhel
+name: openshift/ose-cluster-autoscaler
+`)
+	config := OCPImageConfig{}
+	if err := yaml.Unmarshal(in, &config); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+	config.SourceFileName = "images/atomic-openshift-cluster-autoscaler.yml"
+
+	if diff := cmp.Diff(&OCPConfigDistgit{Namespace: "containers"}, config.Distgit); diff != "" {
+		t.Errorf("distgit differs from expected: %s", diff)
+	}
+	if diff := cmp.Diff(&OCPImageConfigContainerYAML{Go: &OCPImageConfigContainerYAMLGo{Modules: []OCPImageConfigContainerYAMLGoModule{{Module: "k8s.io/autoscaler"}}}}, config.ContainerYAML); diff != "" {
+		t.Errorf("container.yaml differs from expected: %s", diff)
+	}
+	if diff := cmp.Diff([]string{"rhel-8-baseos-rpms", "rhel-8-appstream-rpms"}, config.EnabledRepos); diff != "" {
+		t.Errorf("enabled repos differ from expected: %s", diff)
+	}
+	if !config.UsesPackageManager("gomod") || config.UsesPackageManager("npm") {
+		t.Errorf("expected config to use only gomod, got %v", config.Content.Source.PkgManagers)
+	}
+	if component := config.DistgitComponent(); component != "atomic-openshift-cluster-autoscaler-container" {
+		t.Errorf("expected default component atomic-openshift-cluster-autoscaler-container, got %s", component)
+	}
+	config.Distgit.Component = "cluster-autoscaler"
+	if component := config.DistgitComponent(); component != "cluster-autoscaler" {
+		t.Errorf("expected component cluster-autoscaler, got %s", component)
+	}
+}
+
+func TestLoadRPMConfigs(t *testing.T) {
+	dir := t.TempDir()
+	for path, content := range map[string]string{
+		"group.yml": `branch: rhaos-{MAJOR}.{MINOR}-rhel-8
+sources:
+  ose:
+    url: git@github.com:openshift-priv/ose.git
+    branch:
+      target: release-{MAJOR}.{MINOR}
+public_upstreams:
+- private: https://github.com/openshift-priv
+  public: https://github.com/openshift
+`,
+		"rpms/openshift.yml": `content:
+  build:
+    use_source_tito_config: true
+  source:
+    alias: ose
+    specfile: origin.spec
+name: openshift
+targets:
+- rhaos-{MAJOR}.{MINOR}-rhel-8-candidate
+`,
+		"rpms/openshift-clients.yml": `content:
+  source:
+    git:
+      url: git@github.com:openshift-priv/oc.git
+distgit:
+  branch: rhaos-{MAJOR}.{MINOR}-rhel-7
+name: openshift-clients
+`,
+		"rpms/README.md": "not a config",
+	} {
+		path = filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", path, err)
+		}
+	}
+
+	version := MajorMinor{Major: "4", Minor: "8"}
+	configs, err := LoadRPMConfigs(dir, version)
+	if err != nil {
+		t.Fatalf("failed to load RPM configs: %v", err)
+	}
+	expected := []OCPRPMConfig{
+		{
+			Content: OCPRPMConfigContent{Source: OCPRPMConfigSource{
+				Git: &OCPImageConfigSourceGit{URL: "git@github.com:openshift-priv/oc.git"},
+			}},
+			Name:           "openshift-clients",
+			Distgit:        &OCPConfigDistgit{Branch: "rhaos-4.8-rhel-7"},
+			SourceFileName: "rpms/openshift-clients.yml",
+			Version:        version,
+			PublicRepo:     OrgRepo{Org: "openshift", Repo: "oc"},
+		},
+		{
+			Content: OCPRPMConfigContent{
+				Source: OCPRPMConfigSource{
+					Alias:    "ose",
+					Specfile: "origin.spec",
+					Git:      &OCPImageConfigSourceGit{URL: "git@github.com:openshift-priv/ose.git", Branch: OCPImageConfigSourceGitBRanch{Taget: "release-4.8"}},
+				},
+				Build: OCPRPMConfigBuild{UseSourceTitoConfig: true},
+			},
+			Name:           "openshift",
+			Distgit:        &OCPConfigDistgit{Branch: "rhaos-4.8-rhel-8"},
+			Targets:        []string{"rhaos-4.8-rhel-8-candidate"},
+			SourceFileName: "rpms/openshift.yml",
+			Version:        version,
+			PublicRepo:     OrgRepo{Org: "openshift", Repo: "ose"},
+		},
+	}
+	if diff := cmp.Diff(expected, configs); diff != "" {
+		t.Errorf("configs differ from expected: %s", diff)
+	}
+}
+
 func TestSetPublicRepo(t *testing.T) {
 	testCases := []struct {
 		name      string
@@ -208,6 +343,18 @@ func TestDereferenceConfig(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "distgit branch is defaulted from group.yml",
+			config: OCPImageConfig{
+				From:    OCPImageConfigFrom{OCPImageConfigFromStream: OCPImageConfigFromStream{Image: "quay.io/org/base:latest"}},
+				Distgit: &OCPConfigDistgit{Namespace: "containers"},
+			},
+			groupYAML: GroupYAML{Branch: "rhaos-4.6-rhel-8"},
+			expectedConfig: OCPImageConfig{
+				From:    OCPImageConfigFrom{OCPImageConfigFromStream: OCPImageConfigFromStream{Stream: "quay.io/org/base:latest"}},
+				Distgit: &OCPConfigDistgit{Namespace: "containers", Branch: "rhaos-4.6-rhel-8"},
+			},
+		},
 		{
 			name:          "both config from.stream and config.from.member are empty, error",
 			expectedError: errors.New("failed to find replacement for .from.stream"),
@@ -251,6 +398,80 @@ func TestDereferenceConfig(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "config.from.image and config.from.builder.image are used verbatim",
+			config: OCPImageConfig{
+				From: OCPImageConfigFrom{
+					Builder:                  []OCPImageConfigFromStream{{Image: "registry.ci.openshift.org/ocp/builder:golang-1.15"}},
+					OCPImageConfigFromStream: OCPImageConfigFromStream{Image: "registry.ci.openshift.org/ocp/4.6:base"},
+				},
+			},
+			expectedConfig: OCPImageConfig{
+				From: OCPImageConfigFrom{
+					Builder:                  []OCPImageConfigFromStream{{Stream: "registry.ci.openshift.org/ocp/builder:golang-1.15"}},
+					OCPImageConfigFromStream: OCPImageConfigFromStream{Stream: "registry.ci.openshift.org/ocp/4.6:base"},
+				},
+			},
+		},
+		{
+			name: "config.from.member referencing a distgit only config gets replaced",
+			config: OCPImageConfig{
+				From: OCPImageConfigFrom{
+					OCPImageConfigFromStream: OCPImageConfigFromStream{Member: "openshift-enterprise-base"},
+				},
+			},
+			allConfigs: map[string]OCPImageConfig{
+				"images/openshift-enterprise-base.yml": {
+					Name:    "openshift/ose-base",
+					Version: MajorMinor{Major: "4", Minor: "6"},
+				},
+			},
+			expectedConfig: OCPImageConfig{
+				From: OCPImageConfigFrom{
+					OCPImageConfigFromStream: OCPImageConfigFromStream{Stream: "registry.ci.openshift.org/ocp/4.6:base"},
+				},
+			},
+		},
+		{
+			name: "content.source.alias gets resolved",
+			config: OCPImageConfig{
+				Content: &OCPImageConfigContent{Source: OCPImageConfigSource{Alias: "whereabouts"}},
+				From: OCPImageConfigFrom{
+					OCPImageConfigFromStream: OCPImageConfigFromStream{Image: "registry.ci.openshift.org/ocp/4.6:base"},
+				},
+			},
+			groupYAML: GroupYAML{
+				Sources: map[string]OCPImageConfigSourceGit{
+					"whereabouts": {URL: "git@github.com:openshift-priv/whereabouts-cni.git"},
+				},
+				PublicUpstreams: []PublicPrivateMapping{{Private: "https://github.com/openshift-priv", Public: "https://github.com/openshift"}},
+			},
+			expectedConfig: OCPImageConfig{
+				Content: &OCPImageConfigContent{Source: OCPImageConfigSource{
+					Alias: "whereabouts",
+					Git:   &OCPImageConfigSourceGit{URL: "git@github.com:openshift-priv/whereabouts-cni.git"},
+				}},
+				From: OCPImageConfigFrom{
+					OCPImageConfigFromStream: OCPImageConfigFromStream{Stream: "registry.ci.openshift.org/ocp/4.6:base"},
+				},
+				PublicRepo: OrgRepo{Org: "openshift", Repo: "whereabouts-cni"},
+			},
+		},
+		{
+			name: "unknown alias and unresolvable builder are both reported",
+			config: OCPImageConfig{
+				Content: &OCPImageConfigContent{Source: OCPImageConfigSource{Alias: "whereabouts"}},
+				From: OCPImageConfigFrom{
+					Builder:                  []OCPImageConfigFromStream{{Member: "missing"}},
+					OCPImageConfigFromStream: OCPImageConfigFromStream{Image: "registry.ci.openshift.org/ocp/4.6:base"},
+				},
+			},
+			expectedError: utilerrors.NewAggregate([]error{
+				errors.New("failed to replace .from.0.member: no config images/missing.yml found"),
+				errors.New("failed to dereference from.builder.0"),
+				errors.New("groups.yaml has no replacement for alias whereabouts"),
+			}),
+		},
 		{
 			name: "both config.from.builder.stream and config.from.builder.member are empty, error",
 			config: OCPImageConfig{
@@ -298,3 +519,44 @@ func TestDereferenceConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestStages(t *testing.T) {
+	testCases := []struct {
+		name          string
+		config        OCPImageConfig
+		expected      []string
+		expectedError error
+	}{
+		{
+			name: "dereferenced config",
+			config: OCPImageConfig{From: OCPImageConfigFrom{
+				Builder:                  []OCPImageConfigFromStream{{Stream: "registry.ci.openshift.org/ocp/builder:golang-1.15"}},
+				OCPImageConfigFromStream: OCPImageConfigFromStream{Stream: "registry.ci.openshift.org/ocp/4.6:base"},
+			}},
+			expected: []string{"registry.ci.openshift.org/ocp/builder:golang-1.15", "registry.ci.openshift.org/ocp/4.6:base"},
+		},
+		{
+			name: "member and image that were not dereferenced",
+			config: OCPImageConfig{From: OCPImageConfigFrom{
+				Builder:                  []OCPImageConfigFromStream{{Member: "openshift-enterprise-base"}},
+				OCPImageConfigFromStream: OCPImageConfigFromStream{Image: "registry.ci.openshift.org/ocp/4.6:base"},
+			}},
+			expected: []string{"", ""},
+			expectedError: utilerrors.NewAggregate([]error{
+				errors.New("couldn't dereference from.builder.0: member openshift-enterprise-base was not resolved"),
+				errors.New("couldn't dereference from.stream: image registry.ci.openshift.org/ocp/4.6:base was not resolved"),
+			}),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := tc.config.Stages()
+			if diff := cmp.Diff(tc.expectedError, err, testhelper.EquateErrorMessage); diff != "" {
+				t.Errorf("error differs from expected: %s", diff)
+			}
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Errorf("stages differ from expected: %s", diff)
+			}
+		})
+	}
+}
@@ -2,7 +2,9 @@ package api
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	"k8s.io/apimachinery/pkg/util/sets"
 	prowv1 "k8s.io/test-infra/prow/apis/prowjobs/v1"
@@ -54,6 +56,16 @@ type ReleaseBuildConfiguration struct {
 	// unset, this will default under the repository root to
 	// _output/local/releases/rpms/.
 	RpmBuildLocation string `json:"rpm_build_location,omitempty"`
+	// RpmBuildArchitectures are the architectures the RPM build produces
+	// packages for. When set, the RPMs are served from one repository per
+	// architecture under its RPM name, e.g. x86_64/, which also holds the
+	// noarch packages. The repository injected into images selects the one
+	// for their architecture. The "rpms" image must provide createrepo_c.
+	RpmBuildArchitectures []ReleaseArchitecture `json:"rpm_build_architectures,omitempty"`
+	// RpmSigning makes the metadata of the served RPM repositories signed
+	// with a key from a secret, so that consumers can validate it the same
+	// way they do for production composes. The "rpms" image must provide gpg.
+	RpmSigning *RPMSigningConfiguration `json:"rpm_signing,omitempty"`
 
 	// CanonicalGoRepository is a directory path that represents
 	// the desired location of the contents of this repository in
@@ -61,6 +73,24 @@ type ReleaseBuildConfiguration struct {
 	// cloning from is ignored.
 	CanonicalGoRepository *string `json:"canonical_go_repository,omitempty"`
 
+	// SourceArchive configures the source code to be extracted
+	// from an archive instead of being cloned. This allows to
+	// build from source that was pre-processed, e.g. to generate
+	// code or vendor dependencies.
+	SourceArchive *SourceArchive `json:"source_archive,omitempty"`
+
+	// Clone configures how the source code is cloned, e.g.
+	// to limit the history that is fetched for repositories
+	// that take long to clone.
+	Clone *CloneConfiguration `json:"clone,omitempty"`
+
+	// SourceSnapshots describe alternative source images that
+	// contain the code under test along with the code of other
+	// repositories, like an open pull request of another repo.
+	// Tests can use a snapshot instead of "src" by setting
+	// source_snapshot.
+	SourceSnapshots []SourceSnapshot `json:"source_snapshots,omitempty"`
+
 	// Images describes the images that are built
 	// baseImage the project as part of the release
 	// process. The name of each image is its "to" value
@@ -70,6 +100,10 @@ type ReleaseBuildConfiguration struct {
 	// Operator describes the operator bundle(s) that is built by the project
 	Operator *OperatorStepConfiguration `json:"operator,omitempty"`
 
+	// ImageScans describes scans of built images for vulnerabilities,
+	// licenses or other issues.
+	ImageScans []ImageScanStepConfiguration `json:"image_scans,omitempty"`
+
 	// Tests describes the tests to run inside of built images.
 	// The images launched as pods but have no explicit access to
 	// the cluster they are running on.
@@ -123,6 +157,11 @@ func (config ReleaseBuildConfiguration) IsBaseImage(name string) bool {
 			return true
 		}
 	}
+	for i := range config.ExternalImages {
+		if i == name {
+			return true
+		}
+	}
 	return false
 }
 
@@ -143,6 +182,11 @@ func (config ReleaseBuildConfiguration) IsPipelineImage(name string) bool {
 	if IsIndexImage(name) {
 		return true
 	}
+	for _, snapshot := range config.SourceSnapshots {
+		if name == string(SourceSnapshotName(snapshot.As)) {
+			return true
+		}
+	}
 	return config.IsBundleImage(name)
 }
 
@@ -151,17 +195,24 @@ func (config ReleaseBuildConfiguration) IsPipelineImage(name string) bool {
 type ResourceConfiguration map[string]ResourceRequirements
 
 func (c ResourceConfiguration) RequirementsForStep(name string) ResourceRequirements {
+	return c.RequirementsForStepOn(name, "", "")
+}
+
+// RequirementsForStepOn resolves the requirements for a step that runs with
+// the cluster profile on the architecture. The blanket policy is applied
+// first and the values for the step override it, each of them with their
+// cluster profile and architecture overrides applied on top.
+func (c ResourceConfiguration) RequirementsForStepOn(name string, profile ClusterProfile, architecture ReleaseArchitecture) ResourceRequirements {
 	req := ResourceRequirements{
 		Requests: make(ResourceList),
 		Limits:   make(ResourceList),
 	}
-	if defaults, ok := c["*"]; ok {
-		req.Requests.Add(defaults.Requests)
-		req.Limits.Add(defaults.Limits)
-	}
-	if values, ok := c[name]; ok {
-		req.Requests.Add(values.Requests)
-		req.Limits.Add(values.Limits)
+	for _, key := range []string{"*", name} {
+		if values, ok := c[key]; ok {
+			values = values.For(profile, architecture)
+			req.Requests.Add(values.Requests)
+			req.Limits.Add(values.Limits)
+		}
 	}
 	return req
 }
@@ -176,6 +227,41 @@ type ResourceRequirements struct {
 	// Limits are resource limits applied to an individual step in the job.
 	// These are directly used in creating the Pods that execute the Job.
 	Limits ResourceList `json:"limits,omitempty"`
+	// ClusterProfiles override the requests and limits for steps that
+	// run with one of the cluster profiles.
+	ClusterProfiles map[ClusterProfile]ResourceOverride `json:"cluster_profiles,omitempty"`
+	// Architectures override the requests and limits for the builds
+	// of images for one of the architectures.
+	Architectures map[ReleaseArchitecture]ResourceOverride `json:"architectures,omitempty"`
+}
+
+// ResourceOverride are resource requests and limits that replace
+// the values of the same resources in the requirements they are
+// specified in.
+type ResourceOverride struct {
+	// Requests are resource requests applied to an individual step in the job.
+	Requests ResourceList `json:"requests,omitempty"`
+	// Limits are resource limits applied to an individual step in the job.
+	Limits ResourceList `json:"limits,omitempty"`
+}
+
+// For returns the requests and limits with the overrides for the cluster
+// profile and the architecture applied, the latter taking precedence.
+// Requirements without a matching override are returned as they are.
+func (r ResourceRequirements) For(profile ClusterProfile, architecture ReleaseArchitecture) ResourceRequirements {
+	profileOverride, hasProfile := r.ClusterProfiles[profile]
+	architectureOverride, hasArchitecture := r.Architectures[architecture]
+	if !hasProfile && !hasArchitecture {
+		return ResourceRequirements{Requests: r.Requests, Limits: r.Limits}
+	}
+	ret := ResourceRequirements{Requests: ResourceList{}, Limits: ResourceList{}}
+	ret.Requests.Add(r.Requests)
+	ret.Limits.Add(r.Limits)
+	for _, override := range []ResourceOverride{profileOverride, architectureOverride} {
+		ret.Requests.Add(override.Requests)
+		ret.Limits.Add(override.Limits)
+	}
+	return ret
 }
 
 // ResourceList is a map of string resource names and resource
@@ -205,6 +291,11 @@ type InputConfiguration struct {
 	// have RPM repositories injected into them for downstream
 	// image builds that require built project RPMs.
 	BaseRPMImages map[string]ImageStreamTagReference `json:"base_rpm_images,omitempty"`
+	// ExternalImages is a list of images and their aliases that are
+	// imported directly from an external registry instead of from an
+	// ImageStream on the build farm. The key will be the alias that other
+	// steps use to refer to this image.
+	ExternalImages map[string]ExternalImage `json:"external_images,omitempty"`
 
 	// BuildRootImage supports two ways to get the image that
 	// the pipeline will caches on. The one way is to take the reference
@@ -224,6 +315,38 @@ type InputConfiguration struct {
 	Releases map[string]UnresolvedRelease `json:"releases,omitempty"`
 }
 
+// ExternalImagePolicy determines which image of an external
+// registry is used when a job runs.
+type ExternalImagePolicy string
+
+const (
+	// ExternalImagePolicyPin uses the image with the digest
+	// in the pull spec, so every job uses exactly the same image.
+	ExternalImagePolicyPin ExternalImagePolicy = "pin"
+	// ExternalImagePolicyTrack uses the image the tag in the
+	// pull spec currently points to, so jobs pick up new images
+	// that get pushed to the tag.
+	ExternalImagePolicyTrack ExternalImagePolicy = "track"
+)
+
+// ExternalImage describes an image that is imported
+// directly from an external registry.
+type ExternalImage struct {
+	// PullSpec is the pull spec of the image in the external
+	// registry. It must contain a digest when the image is
+	// pinned and a tag when it is tracked.
+	PullSpec string `json:"pull_spec"`
+	// Policy determines whether the image is pinned to the
+	// digest in the pull spec or tracks its tag. Defaults
+	// to pin.
+	Policy ExternalImagePolicy `json:"policy,omitempty"`
+	// Refresh is how long a tracked image that was imported
+	// into the namespace of a job is reused by later jobs
+	// running in the same namespace before it is imported
+	// again. By default, every job imports it again.
+	Refresh *prowv1.Duration `json:"refresh,omitempty"`
+}
+
 // UnresolvedRelease describes a semantic release payload
 // identifier we need to resolve to a pull spec.
 type UnresolvedRelease struct {
@@ -287,6 +410,7 @@ type ReleaseArchitecture string
 
 const (
 	ReleaseArchitectureAMD64   ReleaseArchitecture = "amd64"
+	ReleaseArchitectureARM64   ReleaseArchitecture = "arm64"
 	ReleaseArchitecturePPC64le ReleaseArchitecture = "ppc64le"
 	ReleaseArchitectureS390x   ReleaseArchitecture = "s390x"
 )
@@ -297,6 +421,20 @@ const (
 	ReleaseStreamCI      ReleaseStream = "ci"
 	ReleaseStreamNightly ReleaseStream = "nightly"
 	ReleaseStreamOKD     ReleaseStream = "okd"
+	ReleaseStreamOKDSCOS ReleaseStream = "okd-scos"
+)
+
+// ReleaseFlavor describes the operating system a release payload is built
+// for, which determines how its versions are named
+type ReleaseFlavor string
+
+const (
+	// ReleaseFlavorOCP payloads are built for RHEL CoreOS
+	ReleaseFlavorOCP ReleaseFlavor = "ocp"
+	// ReleaseFlavorOKD payloads are built for Fedora CoreOS
+	ReleaseFlavorOKD ReleaseFlavor = "okd"
+	// ReleaseFlavorSCOS payloads are built for CentOS Stream CoreOS
+	ReleaseFlavorSCOS ReleaseFlavor = "okd-scos"
 )
 
 // Release describes a generally available release payload
@@ -367,6 +505,10 @@ type ReleaseTagConfiguration struct {
 	// Name is the image stream name to use that contains all
 	// component tags.
 	Name string `json:"name"`
+
+	// Flavor is the flavor of the release payloads that are assembled
+	// from the stream. Defaults to ocp.
+	Flavor ReleaseFlavor `json:"flavor,omitempty"`
 }
 
 // ReleaseConfiguration records a resolved release with its name.
@@ -426,12 +568,42 @@ type PromotionConfiguration struct {
 	// promotion does not imply output artifacts are being created
 	// for posterity.
 	DisableBuildCache bool `json:"disable_build_cache,omitempty"`
+
+	// ExternalRegistry is a registry outside of the cluster the images
+	// are pushed to in addition to the promotion target, e.g. quay.io.
+	// The credentials for it are taken from the push secret.
+	ExternalRegistry *ExternalRegistryPromotion `json:"external_registry,omitempty"`
+}
+
+// ExternalRegistryPromotion describes where promoted images are pushed
+// to in a registry outside of the cluster. An image promoted to the
+// namespace/name:tag ImageStreamTag is pushed as registry/organization/name:tag.
+// Only a fixed set of organizations of registries is allowed, e.g. those
+// of the openshift and openshift-ci organizations on quay.io.
+type ExternalRegistryPromotion struct {
+	// Registry is the domain of the external registry.
+	Registry string `json:"registry"`
+
+	// Organization is the organization in the external registry the
+	// images are pushed to. Defaults to the promotion namespace.
+	Organization string `json:"organization,omitempty"`
+}
+
+// StepTimeouts bound the runtime of a step. When the timeout expires, the
+// step is aborted and fails with the "timeout" reason.
+type StepTimeouts struct {
+	// Timeout is how long the step may run before it is aborted.
+	Timeout *prowv1.Duration `json:"timeout,omitempty"`
+	// GracePeriod is how long the step may take to clean up after it was
+	// aborted before ci-operator stops waiting for it. Defaults to one minute.
+	GracePeriod *prowv1.Duration `json:"grace_period,omitempty"`
 }
 
 // StepConfiguration holds one step configuration.
 // Only one of the fields in this can be non-null.
 type StepConfiguration struct {
 	InputImageTagStepConfiguration              *InputImageTagStepConfiguration              `json:"input_image_tag_step,omitempty"`
+	ExternalImageImportStepConfiguration        *ExternalImageImportStepConfiguration        `json:"external_image_import_step,omitempty"`
 	PipelineImageCacheStepConfiguration         *PipelineImageCacheStepConfiguration         `json:"pipeline_image_cache_step,omitempty"`
 	SourceStepConfiguration                     *SourceStepConfiguration                     `json:"source_step,omitempty"`
 	BundleSourceStepConfiguration               *BundleSourceStepConfiguration               `json:"bundle_source_step,omitempty"`
@@ -443,6 +615,7 @@ type StepConfiguration struct {
 	ReleaseImagesTagStepConfiguration           *ReleaseTagConfiguration                     `json:"release_images_tag_step,omitempty"`
 	ResolvedReleaseImagesStepConfiguration      *ReleaseConfiguration                        `json:"resolved_release_images_step,omitempty"`
 	TestStepConfiguration                       *TestStepConfiguration                       `json:"test_step,omitempty"`
+	ImageScanStepConfiguration                  *ImageScanStepConfiguration                  `json:"image_scan_step,omitempty"`
 	ProjectDirectoryImageBuildInputs            *ProjectDirectoryImageBuildInputs            `json:"project_directory_image_build_inputs,omitempty"`
 }
 
@@ -489,6 +662,14 @@ type InputImage struct {
 	To        PipelineImageStreamTagReference `json:"to,omitempty"`
 }
 
+// ExternalImageImportStepConfiguration describes a step that
+// imports an image from an external registry into the build
+// pipeline.
+type ExternalImageImportStepConfiguration struct {
+	ExternalImage `json:",inline"`
+	To            PipelineImageStreamTagReference `json:"to"`
+}
+
 type ImageStreamSourceType string
 
 const (
@@ -543,7 +724,7 @@ const (
 // gathers artifacts from that step.
 type TestStepConfiguration struct {
 	// As is the name of the test.
-	As string `json:"as"`
+	As string `json:"as" jsonschema:"required"`
 	// Commands are the shell commands to run in
 	// the repository root to execute tests.
 	Commands string `json:"commands,omitempty"`
@@ -551,6 +732,10 @@ type TestStepConfiguration struct {
 	// Cluster specifies the name of the cluster where the test runs.
 	Cluster Cluster `json:"cluster,omitempty"`
 
+	// SourceSnapshot is the name of an entry in source_snapshots
+	// whose image replaces "src" for this test.
+	SourceSnapshot string `json:"source_snapshot,omitempty"`
+
 	// Secret is an optional secret object which
 	// will be mounted inside the test container.
 	// You cannot set the Secret and Secrets attributes
@@ -582,9 +767,28 @@ type TestStepConfiguration struct {
 	// Postsubmit configures prowgen to generate the job as a postsubmit rather than a presubmit
 	Postsubmit bool `json:"postsubmit,omitempty"`
 
+	// Architectures are additional architectures the test runs on. For each
+	// of them, prowgen generates another job for the test, named after the
+	// test and the architecture, e.g. `e2e-arm64`, that is dispatched to a
+	// build cluster with nodes of the architecture and runs the builds and
+	// the test on them. Postsubmits cannot run on additional architectures.
+	Architectures []ReleaseArchitecture `json:"architectures,omitempty"`
+
 	// ClusterClaim claims an OpenShift cluster and exposes environment variable ${KUBECONFIG} to the test container
 	ClusterClaim *ClusterClaim `json:"cluster_claim,omitempty"`
 
+	// Leases lists resources that are acquired for a container test. The
+	// names of the leased resources are exposed to the test container in
+	// the configured environment variables. Multi-stage tests configure
+	// their leases under `steps` instead.
+	Leases []StepLease `json:"leases,omitempty"`
+
+	// NamespaceTTL configures how long the namespace the test runs in is
+	// retained, e.g. to keep it around for debugging after a failure.
+	NamespaceTTL *NamespaceTTL `json:"namespace_ttl,omitempty"`
+
+	StepTimeouts `json:",inline"`
+
 	// Only one of the following can be not-null.
 	ContainerTestConfiguration                                *ContainerTestConfiguration                                `json:"container,omitempty"`
 	MultiStageTestConfiguration                               *MultiStageTestConfiguration                               `json:"steps,omitempty"`
@@ -598,6 +802,52 @@ type TestStepConfiguration struct {
 	OpenshiftInstallerCustomTestImageClusterTestConfiguration *OpenshiftInstallerCustomTestImageClusterTestConfiguration `json:"openshift_installer_custom_test_image,omitempty"`
 }
 
+// GetClusterProfile returns the cluster profile the test runs with, which is
+// empty for tests that do not run against a cluster from a profile
+func (config TestStepConfiguration) GetClusterProfile() ClusterProfile {
+	switch {
+	case config.MultiStageTestConfiguration != nil:
+		return config.MultiStageTestConfiguration.ClusterProfile
+	case config.MultiStageTestConfigurationLiteral != nil:
+		return config.MultiStageTestConfigurationLiteral.ClusterProfile
+	case config.OpenshiftAnsibleClusterTestConfiguration != nil:
+		return config.OpenshiftAnsibleClusterTestConfiguration.ClusterProfile
+	case config.OpenshiftAnsibleSrcClusterTestConfiguration != nil:
+		return config.OpenshiftAnsibleSrcClusterTestConfiguration.ClusterProfile
+	case config.OpenshiftAnsibleCustomClusterTestConfiguration != nil:
+		return config.OpenshiftAnsibleCustomClusterTestConfiguration.ClusterProfile
+	case config.OpenshiftInstallerClusterTestConfiguration != nil:
+		return config.OpenshiftInstallerClusterTestConfiguration.ClusterProfile
+	case config.OpenshiftInstallerUPIClusterTestConfiguration != nil:
+		return config.OpenshiftInstallerUPIClusterTestConfiguration.ClusterProfile
+	case config.OpenshiftInstallerUPISrcClusterTestConfiguration != nil:
+		return config.OpenshiftInstallerUPISrcClusterTestConfiguration.ClusterProfile
+	case config.OpenshiftInstallerCustomTestImageClusterTestConfiguration != nil:
+		return config.OpenshiftInstallerCustomTestImageClusterTestConfiguration.ClusterProfile
+	}
+	return ""
+}
+
+// NamespaceTTL configures the lifetime of the namespace of a test. The
+// namespace is deleted by the namespace reaper once it expires. None of
+// the TTLs can exceed 72h.
+type NamespaceTTL struct {
+	// Hard is how long the namespace is retained after it was last
+	// active. Overrides the --delete-after flag of ci-operator.
+	Hard *prowv1.Duration `json:"hard,omitempty"`
+	// Idle is how long the namespace is retained after it was last
+	// active once no pods run in it anymore. Overrides the
+	// --delete-when-idle flag of ci-operator.
+	Idle *prowv1.Duration `json:"idle,omitempty"`
+	// KeepOnFailure is how long the namespace is retained after the
+	// test failed, regardless of the other TTLs.
+	KeepOnFailure *prowv1.Duration `json:"keep_on_failure,omitempty"`
+}
+
+// MaxNamespaceTTL bounds each of the namespace TTLs of a test, so tests can
+// not keep their namespaces and the resources in them around indefinitely.
+const MaxNamespaceTTL = 72 * time.Hour
+
 // Cloud is the name of a cloud provider, e.g., aws cluster topology, etc.
 type Cloud string
 
@@ -622,7 +872,22 @@ type ClusterClaim struct {
 	// Timeout is how long ci-operator will wait for the cluster to be ready.
 	// Defaults to 1h.
 	Timeout *prowv1.Duration `json:"timeout,omitempty"`
-}
+	// Pool is the name of the Hive ClusterPool to claim the cluster from.
+	// When set, the pool is not selected by the product, version,
+	// architecture, cloud and owner, which become optional.
+	Pool string `json:"pool,omitempty"`
+	// Labels are additional labels the cluster pool must have, e.g. to
+	// select the pool of a team among those providing the same cluster.
+	Labels map[string]string `json:"labels,omitempty"`
+	// Lifetime is how long the claimed cluster exists before Hive deletes
+	// it, in case the claim is never released. Defaults to 4h and can not
+	// exceed 12h.
+	Lifetime *prowv1.Duration `json:"lifetime,omitempty"`
+}
+
+// MaxClusterClaimLifetime bounds the lifetime of a claimed cluster, so a
+// claim that is never released can not hold a cluster of the pool forever.
+const MaxClusterClaimLifetime = 12 * time.Hour
 
 // RegistryReferenceConfig is the struct that step references are unmarshalled into.
 type RegistryReferenceConfig struct {
@@ -769,6 +1034,52 @@ type LiteralTestStep struct {
 	// RunAsScript defines if this step should be executed as a script mounted
 	// in the test container instead of being executed directly via bash
 	RunAsScript *bool `json:"run_as_script,omitempty"`
+	// Architectures restricts the step to build clusters that have nodes of
+	// at least one of the given architectures. On other clusters, the step is
+	// skipped instead of failing. If unset, the step runs on all clusters.
+	Architectures []ReleaseArchitecture `json:"architectures,omitempty"`
+	// InitContainers run in order before the step to prepare data for it,
+	// e.g. to download fixtures or unpack tools, keeping the image and the
+	// commands of the step simple. They write the data into the directory
+	// exposed to them and to the step as $INIT_DIR.
+	InitContainers []StepInitContainer `json:"init_containers,omitempty"`
+	// Optional defines if a failure of this step should be ignored: it
+	// neither fails the test nor prevents the following steps from running.
+	Optional *bool `json:"optional,omitempty"`
+	// RunIf defines when the step runs, based on whether any step before it
+	// failed: `success` runs it only if none failed, `failure` only if one
+	// failed and `always` regardless. Defaults to `success` for `pre` and
+	// `test` steps and to `always` for `post` steps.
+	RunIf StepRunCondition `json:"run_if,omitempty" jsonschema:"enum=success|failure|always"`
+	// Retries is how many more times the step is run when it fails before
+	// its failure is reported, e.g. for steps that provision infrastructure
+	// and fail intermittently. Defaults to 0.
+	Retries *int `json:"retries,omitempty"`
+}
+
+// StepRunCondition defines when a step runs in relation to the outcome of the
+// steps that ran before it.
+type StepRunCondition string
+
+const (
+	StepRunIfSuccess StepRunCondition = "success"
+	StepRunIfFailure StepRunCondition = "failure"
+	StepRunIfAlways  StepRunCondition = "always"
+)
+
+// MaxStepRetries bounds the retries of a step, so a broken step can not
+// hold a test for too long.
+const MaxStepRetries = 5
+
+// StepInitContainer is a lightweight container that runs before a step.
+type StepInitContainer struct {
+	// As is the name of the init container.
+	As string `json:"as"`
+	// From is the container image that will be used, it is resolved like
+	// the `from` of the step.
+	From string `json:"from"`
+	// Commands is the command(s) that will be run inside the image.
+	Commands string `json:"commands"`
 }
 
 // StepParameter is a variable set by the test, with an optional default.
@@ -779,6 +1090,66 @@ type StepParameter struct {
 	Default *string `json:"default,omitempty"`
 	// Documentation is a textual description of the parameter.
 	Documentation string `json:"documentation,omitempty"`
+	// Type of the value of the parameter, one of `string` (the
+	// default), `integer`, `boolean` or `enum`.
+	Type ParameterType `json:"type,omitempty" jsonschema:"enum=string|integer|boolean|enum"`
+	// Values are the values allowed for an `enum` parameter.
+	Values []string `json:"values,omitempty"`
+}
+
+// ParameterType is the type of the value of a step parameter. Values
+// are always passed to the step as strings in its environment, the
+// type only determines which strings are valid.
+type ParameterType string
+
+const (
+	ParameterTypeString  ParameterType = "string"
+	ParameterTypeInteger ParameterType = "integer"
+	ParameterTypeBoolean ParameterType = "boolean"
+	ParameterTypeEnum    ParameterType = "enum"
+)
+
+// Validate checks that the type of the parameter is known and that its
+// default, if any, is a valid value.
+func (p StepParameter) Validate() error {
+	switch p.Type {
+	case "", ParameterTypeString, ParameterTypeInteger, ParameterTypeBoolean:
+		if len(p.Values) != 0 {
+			return fmt.Errorf("parameter %s: `values` can only be set for parameters of type %s", p.Name, ParameterTypeEnum)
+		}
+	case ParameterTypeEnum:
+		if len(p.Values) == 0 {
+			return fmt.Errorf("parameter %s: `values` must be set for parameters of type %s", p.Name, ParameterTypeEnum)
+		}
+	default:
+		return fmt.Errorf("parameter %s: invalid type %q, must be one of %s, %s, %s or %s", p.Name, p.Type, ParameterTypeString, ParameterTypeInteger, ParameterTypeBoolean, ParameterTypeEnum)
+	}
+	if p.Default != nil {
+		return p.ValidateValue(*p.Default)
+	}
+	return nil
+}
+
+// ValidateValue checks that the value is valid for the type of the parameter.
+func (p StepParameter) ValidateValue(value string) error {
+	switch p.Type {
+	case ParameterTypeInteger:
+		if _, err := strconv.Atoi(value); err != nil {
+			return fmt.Errorf("parameter %s: %q is not an integer", p.Name, value)
+		}
+	case ParameterTypeBoolean:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("parameter %s: %q is not a boolean", p.Name, value)
+		}
+	case ParameterTypeEnum:
+		for _, allowed := range p.Values {
+			if value == allowed {
+				return nil
+			}
+		}
+		return fmt.Errorf("parameter %s: %q is not one of %s", p.Name, value, strings.Join(p.Values, ", "))
+	}
+	return nil
 }
 
 // CredentialReference defines a secret to mount into a step and where to mount it.
@@ -787,8 +1158,21 @@ type CredentialReference struct {
 	Namespace string `json:"namespace"`
 	// Names is which source secret to mount.
 	Name string `json:"name"`
-	// MountPath is where the secret should be mounted.
-	MountPath string `json:"mount_path"`
+	// MountPath is where the secret should be mounted. It may be omitted
+	// if keys of the secret are exposed through Env instead.
+	MountPath string `json:"mount_path,omitempty"`
+	// Env lists keys of the secret that are exposed to the step as
+	// environment variables. Like the rest of the secret, their values
+	// are censored from all output.
+	Env []CredentialEnv `json:"env,omitempty"`
+}
+
+// CredentialEnv exposes a key of a secret as an environment variable.
+type CredentialEnv struct {
+	// Name of the environment variable.
+	Name string `json:"name"`
+	// Key in the secret whose value is exposed.
+	Key string `json:"key"`
 }
 
 // StepDependency defines a dependency on an image and the environment variable
@@ -796,6 +1180,10 @@ type CredentialReference struct {
 type StepDependency struct {
 	// Name is the tag or stream:tag that this dependency references
 	Name string `json:"name"`
+	// Release is the name of the release the image is taken from, like
+	// `initial`, `latest` or the name of a custom release. If set, Name is
+	// the tag of the image in the release.
+	Release string `json:"release,omitempty"`
 	// Env is the environment variable that the image's pull spec is exposed with
 	Env string `json:"env"`
 }
@@ -847,6 +1235,11 @@ type TestStep struct {
 type MultiStageTestConfiguration struct {
 	// ClusterProfile defines the profile/cloud provider for end-to-end test steps.
 	ClusterProfile ClusterProfile `json:"cluster_profile,omitempty"`
+	// FallbackClusterProfiles are the profiles the test falls back to, in
+	// order, when the leases of ClusterProfile have no free capacity. The
+	// profile the test runs with is exposed to the steps as
+	// $CLUSTER_PROFILE_NAME.
+	FallbackClusterProfiles []ClusterProfile `json:"fallback_cluster_profiles,omitempty"`
 	// Pre is the array of test steps run to set up the environment for the test.
 	Pre []TestStep `json:"pre,omitempty"`
 	// Test is the array of test steps that define the actual test.
@@ -876,6 +1269,10 @@ type MultiStageTestConfiguration struct {
 	AllowBestEffortPostSteps *bool `json:"allow_best_effort_post_steps,omitempty"`
 	// Observers are the observers that should be running
 	Observers *Observers `json:"observers,omitempty"`
+	// Gather enables the built-in step that gathers must-gather, the audit
+	// logs and the events of the cluster under test at the start of the
+	// `post` phase, even when earlier steps failed.
+	Gather *GatherConfiguration `json:"gather,omitempty"`
 }
 
 // MultiStageTestConfigurationLiteral is a form of the MultiStageTestConfiguration that does not include
@@ -884,6 +1281,11 @@ type MultiStageTestConfiguration struct {
 type MultiStageTestConfigurationLiteral struct {
 	// ClusterProfile defines the profile/cloud provider for end-to-end test steps.
 	ClusterProfile ClusterProfile `json:"cluster_profile"`
+	// FallbackClusterProfiles are the profiles the test falls back to, in
+	// order, when the leases of ClusterProfile have no free capacity. The
+	// profile the test runs with is exposed to the steps as
+	// $CLUSTER_PROFILE_NAME.
+	FallbackClusterProfiles []ClusterProfile `json:"fallback_cluster_profiles,omitempty"`
 	// Pre is the array of test steps run to set up the environment for the test.
 	Pre []LiteralTestStep `json:"pre,omitempty"`
 	// Test is the array of test steps that define the actual test.
@@ -909,6 +1311,25 @@ type MultiStageTestConfigurationLiteral struct {
 	AllowBestEffortPostSteps *bool `json:"allow_best_effort_post_steps,omitempty"`
 	// Observers are the observers that need to be run
 	Observers []Observer `json:"observers,omitempty"`
+	// Gather enables the built-in step that gathers must-gather, the audit
+	// logs and the events of the cluster under test at the start of the
+	// `post` phase, even when earlier steps failed.
+	Gather *GatherConfiguration `json:"gather,omitempty"`
+}
+
+// GatherStepName is the name of the built-in step that gathers data from
+// the cluster under test. No other step of a test that enables it can use it.
+const GatherStepName = "gather-cluster"
+
+// GatherConfiguration configures the built-in step that gathers data from the
+// cluster under test. The step reads the kubeconfig from $SHARED_DIR, and
+// its failures never fail the test.
+type GatherConfiguration struct {
+	// Timeout is how long the gathering may take. Defaults to 30 minutes.
+	Timeout *prowv1.Duration `json:"timeout,omitempty"`
+	// Resources defines the resource requirements for the gathering step.
+	// Defaults to requesting 300m of CPU and 300Mi of memory.
+	Resources *ResourceRequirements `json:"resources,omitempty"`
 }
 
 // TestEnvironment has the values of parameters for multi-stage tests.
@@ -1275,6 +1696,68 @@ type SourceStepConfiguration struct {
 	// ClonerefsPath is the path in the above image where the
 	// clonerefs tool is placed
 	ClonerefsPath string `json:"clonerefs_path"`
+
+	// SourceArchive, if set, is extracted instead of
+	// cloning the source repositories
+	SourceArchive *SourceArchive `json:"source_archive,omitempty"`
+
+	// ExtraRefs are cloned in addition to the refs of the job
+	ExtraRefs []prowv1.Refs `json:"extra_refs,omitempty"`
+
+	// Clone configures how the refs are cloned
+	Clone *CloneConfiguration `json:"clone,omitempty"`
+}
+
+// CloneConfiguration configures how the repositories
+// are cloned into the source image
+type CloneConfiguration struct {
+	// Depth limits the history that is fetched to the
+	// given number of commits. The full history is fetched
+	// by default. Merging a pull request fails when its
+	// base is not within the depth.
+	Depth int `json:"depth,omitempty"`
+	// SkipSubmodules disables the recursive initialization
+	// of the submodules of the repositories.
+	SkipSubmodules bool `json:"skip_submodules,omitempty"`
+	// Filter makes the clones partial clones that omit the
+	// objects the filter excludes, e.g. `blob:none` fetches
+	// the files of the checked out commit only. Omitted
+	// objects are fetched when they are needed, which
+	// requires git in the image the source is built from.
+	// Supported filters are `blob:none`, `blob:limit=<n>[kmg]`
+	// and `tree:<depth>`.
+	Filter string `json:"filter,omitempty"`
+}
+
+// SourceArchive describes an archive that holds the
+// source code of the repository
+type SourceArchive struct {
+	// From is the image that contains the archive. It can
+	// be produced by an earlier step or be imported from
+	// an external system through `base_images`.
+	From PipelineImageStreamTagReference `json:"from"`
+	// Path is the location of the archive in the above
+	// image. The archive can be uncompressed or be
+	// compressed with gzip, bzip2 or xz. Its content is
+	// extracted into the working directory of the source.
+	Path string `json:"path"`
+}
+
+// SourceSnapshot describes a source image that contains the code
+// under test along with the code of other repositories
+type SourceSnapshot struct {
+	// As is the name of the snapshot. Its image is tagged
+	// into the pipeline as src-<as>.
+	As string `json:"as"`
+	// Refs are the repositories that are cloned in addition
+	// to the refs of the job. Pull requests listed for a
+	// repository are merged into its base_ref.
+	Refs []prowv1.Refs `json:"refs"`
+}
+
+// SourceSnapshotName returns the name of the pipeline image of a source snapshot
+func SourceSnapshotName(as string) PipelineImageStreamTagReference {
+	return PipelineImageStreamTagReference(fmt.Sprintf("%s-%s", PipelineImageStreamTagReferenceSource, as))
 }
 
 // OperatorStepConfiguration describes the locations of operator bundle information,
@@ -1298,6 +1781,20 @@ const (
 	IndexUpdateSemverSkippatch = "semver-skippatch"
 )
 
+// IndexFormat specifies how the content of an index is stored
+type IndexFormat string
+
+const (
+	// IndexFormatSQLite stores the index content in a sqlite database
+	IndexFormatSQLite IndexFormat = "sqlite"
+	// IndexFormatFileBasedCatalog stores the index content in a declarative
+	// file-based catalog
+	IndexFormatFileBasedCatalog IndexFormat = "file-based-catalog"
+)
+
+// DefaultOPMImage is the image that provides the opm binary used to generate indices
+const DefaultOPMImage = "quay.io/operator-framework/upstream-opm-builder"
+
 // Bundle contains the data needed to build a bundle from the bundle source image and update an index to include the new bundle
 type Bundle struct {
 	// As defines the name for this bundle. If not set, a name will be automatically generated for the bundle.
@@ -1306,11 +1803,21 @@ type Bundle struct {
 	DockerfilePath string `json:"dockerfile_path,omitempty"`
 	// ContextDir defines the source directory to build the bundle from relative to the repository root
 	ContextDir string `json:"context_dir,omitempty"`
-	// BaseIndex defines what index image to use as a base when adding the bundle to an index
+	// BaseIndex defines what index image to use as a base when adding the bundle to an index.
+	// This can either be the name of an image in the pipeline or the pull spec of a published
+	// index image, like registry.redhat.io/redhat/redhat-operator-index:v4.8
 	BaseIndex string `json:"base_index,omitempty"`
 	// UpdateGraph defines the update mode to use when adding the bundle to the base index.
 	// Can be: semver (default), semver-skippatch, or replaces
 	UpdateGraph IndexUpdate `json:"update_graph,omitempty"`
+	// IndexFormat defines how the content of the index for this bundle is stored.
+	// Can be: sqlite (default) or file-based-catalog
+	IndexFormat IndexFormat `json:"index_format,omitempty"`
+	// OPMImage is the pull spec of the image that provides the opm binary used to
+	// generate the index for this bundle. Defaults to quay.io/operator-framework/upstream-opm-builder
+	OPMImage string `json:"opm_image,omitempty"`
+	// StepTimeouts bound the generation of the index for this bundle.
+	StepTimeouts `json:",inline"`
 }
 
 // IndexGeneratorStepConfiguration describes a step that creates an index database and
@@ -1323,11 +1830,22 @@ type IndexGeneratorStepConfiguration struct {
 	// index will contain in its database.
 	OperatorIndex []string `json:"operator_index,omitempty"`
 
-	// BaseIndex is the index image to add the bundle(s) to. If unset, a new index is created
+	// BaseIndex is the index image to add the bundle(s) to. If unset, a new index is created.
+	// This is either the name of an image in the pipeline or the pull spec of a published index
 	BaseIndex string `json:"base_index,omitempty"`
 
 	// UpdateGraph defines the mode to us when updating the index graph
 	UpdateGraph IndexUpdate `json:"update_graph,omitempty"`
+
+	// IndexFormat defines how the content of the index is stored. If unset,
+	// a sqlite database is generated
+	IndexFormat IndexFormat `json:"index_format,omitempty"`
+
+	// OPMImage is the image that provides the opm binary. If unset,
+	// DefaultOPMImage is used
+	OPMImage string `json:"opm_image,omitempty"`
+
+	StepTimeouts `json:",inline"`
 }
 
 // PipelineImageStreamTagReferenceIndexImageGenerator is the name of the index image generator built by ci-operator
@@ -1348,6 +1866,38 @@ func IndexGeneratorName(indexName PipelineImageStreamTagReference) PipelineImage
 	return PipelineImageStreamTagReference(fmt.Sprintf("%s-gen", indexName))
 }
 
+// ImageScanStepConfiguration describes a step that runs a scanner against
+// images in the pipeline. The scanner runs in one pod per image, with the
+// pull spec of the image in $IMAGE. A scan fails when its commands exit
+// with a non-zero code. Reports written to $ARTIFACT_DIR are uploaded
+// with the artifacts of the job and every scanned image is recorded as a
+// test case in the JUnit results.
+type ImageScanStepConfiguration struct {
+	// As is the name of the scan.
+	As string `json:"as"`
+
+	// Images are the images in the pipeline to scan.
+	Images []PipelineImageStreamTagReference `json:"images"`
+
+	// Scanner is the pull spec of the image that provides the scanner,
+	// for instance clair or grype.
+	Scanner string `json:"scanner"`
+
+	// Commands are the shell commands that scan the image.
+	Commands string `json:"commands"`
+
+	// Informational scans do not fail the job when they find issues.
+	// Their findings are recorded as skipped test cases.
+	Informational bool `json:"informational,omitempty"`
+}
+
+// IsPublishedIndex determines if the base index is the pull spec of a published index image
+// rather than the name of an image in the pipeline. Names of images in the pipeline never
+// contain a slash, while pull specs always do.
+func IsPublishedIndex(baseIndex string) bool {
+	return strings.Contains(baseIndex, "/")
+}
+
 // BundleSourceStepConfiguration describes a step that performs a set of
 // substitutions on all yaml files in the `src` image so that the
 // pullspecs in the operator manifests point to images inside the CI registry.
@@ -1389,12 +1939,67 @@ type ProjectDirectoryImageBuildStepConfiguration struct {
 	From PipelineImageStreamTagReference `json:"from,omitempty"`
 	To   PipelineImageStreamTagReference `json:"to"`
 
+	// AdditionalNames are further names the image is published and promoted
+	// as, next to `to`. The image is only built once.
+	AdditionalNames []string `json:"additional_names,omitempty"`
+
 	ProjectDirectoryImageBuildInputs `json:",inline"`
 
 	// Optional means the build step is not built, published, or
 	// promoted unless explicitly targeted. Use for builds which
 	// are invoked only when testing certain parts of the repo.
 	Optional bool `json:"optional,omitempty"`
+
+	// RequiresEntitlement means the build needs access to subscription
+	// content. The shared entitlement certificates and CA are made available
+	// in the etc-pki-entitlement directory of the build context.
+	RequiresEntitlement bool `json:"requires_entitlement,omitempty"`
+
+	// Architectures are the architectures the image is built for. One
+	// build is run for every architecture and the results are assembled
+	// into a manifest list that is tagged as `to`. Resources for the build
+	// of a single architecture can be overridden under the `to-architecture`
+	// key, e.g. `src-arm64`. When unset, a single image is built for the
+	// architecture of the build cluster.
+	Architectures []ReleaseArchitecture `json:"architectures,omitempty"`
+
+	// BuildBackend selects how the image is built. Images are built as
+	// OpenShift builds unless `buildah-pod` is set, in which case buildah
+	// runs unprivileged in a pod in the test namespace, with the pipeline
+	// images mounted into the build context. Use it on clusters where the
+	// build controller is unavailable or too slow. It cannot be used with
+	// architectures or requires_entitlement.
+	BuildBackend BuildBackend `json:"build_backend,omitempty" jsonschema:"enum=openshift|buildah-pod"`
+
+	StepTimeouts `json:",inline"`
+}
+
+// BuildBackend is the way an image is built.
+type BuildBackend string
+
+const (
+	// BuildBackendOpenShift builds the image as an OpenShift build, the default.
+	BuildBackendOpenShift BuildBackend = "openshift"
+	// BuildBackendBuildahPod builds the image with buildah in an unprivileged pod.
+	BuildBackendBuildahPod BuildBackend = "buildah-pod"
+)
+
+// IsMultiArch determines if the image is built for multiple architectures
+// and assembled into a manifest list.
+func (config ProjectDirectoryImageBuildStepConfiguration) IsMultiArch() bool {
+	return len(config.Architectures) > 0
+}
+
+// ArchitectureTag is the pipeline tag the image for a single architecture
+// is built into before it is assembled into a manifest list.
+func ArchitectureTag(to PipelineImageStreamTagReference, architecture ReleaseArchitecture) PipelineImageStreamTagReference {
+	return PipelineImageStreamTagReference(fmt.Sprintf("%s-%s", to, architecture))
+}
+
+// ArchitectureTestName is the name of the job generated for a test on one
+// of its additional architectures.
+func ArchitectureTestName(test string, architecture ReleaseArchitecture) string {
+	return fmt.Sprintf("%s-%s", test, architecture)
 }
 
 // ProjectDirectoryImageBuildInputs holds inputs for an image build from the repo under test
@@ -1425,11 +2030,17 @@ type BuildArg struct {
 	// Name of the build arg.
 	Name string `json:"name,omitempty"`
 
-	// Value of the build arg. Cannot be set if ValueFrom is set.
+	// Value of the build arg. Cannot be set if ValueFrom or FromJobSpec is set.
 	Value string `json:"value,omitempty"`
 
-	// ValueFrom specifies the value of the build Arg is taken from a key of a secret. Cannot be set if Value is set.
+	// ValueFrom specifies the value of the build Arg is taken from a key of a secret. Cannot be set if Value or FromJobSpec is set.
 	ValueFrom *SecretKeySelector `json:"secret_key_ref,omitempty"`
+
+	// FromJobSpec specifies the value of the build arg is taken from the variable Prow
+	// exposes to the job, e.g. PULL_BASE_SHA or PULL_NUMBER. Variables that are not defined
+	// for the type of the job, like PULL_NUMBER for periodic jobs, resolve to an empty string.
+	// Cannot be set if Value or ValueFrom is set.
+	FromJobSpec string `json:"from_job_spec,omitempty"`
 }
 
 // SecretKeySelector selects a key of a Secret.
@@ -1496,12 +2107,35 @@ type ImageSourcePath struct {
 type RPMImageInjectionStepConfiguration struct {
 	From PipelineImageStreamTagReference `json:"from"`
 	To   PipelineImageStreamTagReference `json:"to,omitempty"`
+	// PerArchitecture means the RPMs are served from one
+	// repository per architecture.
+	PerArchitecture bool `json:"per_architecture,omitempty"`
+	// Signed means the repository metadata is signed and
+	// its signature should be validated.
+	Signed bool `json:"signed,omitempty"`
 }
 
 // RPMServeStepConfiguration describes a step that launches
 // a server from an image with RPMs and exposes it to the web.
 type RPMServeStepConfiguration struct {
 	From PipelineImageStreamTagReference `json:"from"`
+	// Architectures are served from separate repositories.
+	Architectures []ReleaseArchitecture `json:"architectures,omitempty"`
+	// Signing configures the key the repository metadata
+	// is signed with.
+	Signing *RPMSigningConfiguration `json:"signing,omitempty"`
+}
+
+// RPMSigningConfiguration describes the key the metadata of
+// served RPM repositories is signed with.
+type RPMSigningConfiguration struct {
+	// Namespace is where the secret holding the key exists.
+	Namespace string `json:"namespace"`
+	// Name is the secret holding the key.
+	Name string `json:"name"`
+	// Key is the key in the secret that holds the
+	// ASCII-armored private GPG key.
+	Key string `json:"key"`
 }
 
 const (
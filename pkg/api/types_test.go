@@ -171,6 +171,9 @@ func TestIsPipelineImage(t *testing.T) {
 			BaseRPMImages: map[string]ImageStreamTagReference{
 				"base-rpm-img": {},
 			},
+			ExternalImages: map[string]ExternalImage{
+				"external-img": {},
+			},
 		},
 		BinaryBuildCommands:     "make",
 		TestBinaryBuildCommands: "make test-bin",
@@ -183,6 +186,7 @@ func TestIsPipelineImage(t *testing.T) {
 	}{
 		{name: "base-img", want: true},
 		{name: "base-rpm-img", want: true},
+		{name: "external-img", want: true},
 		{name: "root", want: true},
 		{name: "bin", want: true},
 		{name: "test-bin", want: true},
@@ -341,3 +345,70 @@ func TestInputImageTagStepConfiguration(t *testing.T) {
 		})
 	}
 }
+
+func TestRequirementsForStepOn(t *testing.T) {
+	resources := ResourceConfiguration{
+		"*": {
+			Requests: ResourceList{"cpu": "100m", "memory": "200Mi"},
+			ClusterProfiles: map[ClusterProfile]ResourceOverride{
+				ClusterProfileAWS: {Requests: ResourceList{"memory": "1Gi"}},
+			},
+		},
+		"step": {
+			Requests: ResourceList{"cpu": "1"},
+			Limits:   ResourceList{"memory": "4Gi"},
+			ClusterProfiles: map[ClusterProfile]ResourceOverride{
+				ClusterProfileAWS: {Requests: ResourceList{"cpu": "2"}},
+			},
+			Architectures: map[ReleaseArchitecture]ResourceOverride{
+				ReleaseArchitectureARM64: {Requests: ResourceList{"cpu": "3"}, Limits: ResourceList{"memory": "8Gi"}},
+			},
+		},
+	}
+	var testCases = []struct {
+		name         string
+		step         string
+		profile      ClusterProfile
+		architecture ReleaseArchitecture
+		expected     ResourceRequirements
+	}{
+		{
+			name:     "without overrides, the step values override the defaults",
+			step:     "step",
+			expected: ResourceRequirements{Requests: ResourceList{"cpu": "1", "memory": "200Mi"}, Limits: ResourceList{"memory": "4Gi"}},
+		},
+		{
+			name:     "unknown step gets the defaults",
+			step:     "other",
+			profile:  ClusterProfileGCP,
+			expected: ResourceRequirements{Requests: ResourceList{"cpu": "100m", "memory": "200Mi"}, Limits: ResourceList{}},
+		},
+		{
+			name:     "cluster profile overrides apply to the defaults and the step",
+			step:     "step",
+			profile:  ClusterProfileAWS,
+			expected: ResourceRequirements{Requests: ResourceList{"cpu": "2", "memory": "1Gi"}, Limits: ResourceList{"memory": "4Gi"}},
+		},
+		{
+			name:         "architecture overrides take precedence over cluster profile overrides",
+			step:         "step",
+			profile:      ClusterProfileAWS,
+			architecture: ReleaseArchitectureARM64,
+			expected:     ResourceRequirements{Requests: ResourceList{"cpu": "3", "memory": "1Gi"}, Limits: ResourceList{"memory": "8Gi"}},
+		},
+		{
+			name:     "step overrides take precedence over the defaults for the cluster profile",
+			step:     "other",
+			profile:  ClusterProfileAWS,
+			expected: ResourceRequirements{Requests: ResourceList{"cpu": "100m", "memory": "1Gi"}, Limits: ResourceList{}},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			actual := resources.RequirementsForStepOn(testCase.step, testCase.profile, testCase.architecture)
+			if diff := cmp.Diff(testCase.expected, actual); diff != "" {
+				t.Errorf("unexpected requirements: %s", diff)
+			}
+		})
+	}
+}
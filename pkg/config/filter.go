@@ -0,0 +1,136 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	cioperatorapi "github.com/openshift/ci-tools/pkg/api"
+)
+
+// InfoPredicate selects configurations by the metadata encoded in their path,
+// so it can be evaluated before a file is read
+type InfoPredicate func(info *Info) bool
+
+// ConfigPredicate selects configurations by their content
+type ConfigPredicate func(configuration *cioperatorapi.ReleaseBuildConfiguration) bool
+
+// ByOrg matches configurations of any of the orgs
+func ByOrg(orgs ...string) InfoPredicate {
+	set := sets.NewString(orgs...)
+	return func(info *Info) bool {
+		return set.Has(info.Org)
+	}
+}
+
+// ByRepo matches configurations of any of the repos, given in org/repo form
+func ByRepo(orgRepos ...string) InfoPredicate {
+	set := sets.NewString(orgRepos...)
+	return func(info *Info) bool {
+		return set.Has(info.Org + "/" + info.Repo)
+	}
+}
+
+// ByBranch matches configurations for any of the branches
+func ByBranch(branches ...string) InfoPredicate {
+	set := sets.NewString(branches...)
+	return func(info *Info) bool {
+		return set.Has(info.Branch)
+	}
+}
+
+// ByVariant matches configurations with any of the variants. An empty
+// variant matches configurations without one.
+func ByVariant(variants ...string) InfoPredicate {
+	set := sets.NewString(variants...)
+	return func(info *Info) bool {
+		return set.Has(info.Variant)
+	}
+}
+
+// HasImages matches configurations that build images
+func HasImages(configuration *cioperatorapi.ReleaseBuildConfiguration) bool {
+	return len(configuration.Images) != 0
+}
+
+// HasTests matches configurations that have tests
+func HasTests(configuration *cioperatorapi.ReleaseBuildConfiguration) bool {
+	return len(configuration.Tests) != 0
+}
+
+// Filter selects the configurations that OperateOnFilteredCIOperatorConfigDir
+// handles. Files that are dropped by the predicates on their path are not read.
+type Filter struct {
+	// Include matches configurations by their path. When it is set, only
+	// configurations that match at least one predicate are handled.
+	Include []InfoPredicate
+	// Exclude drops configurations whose path matches any of the predicates
+	Exclude []InfoPredicate
+	// Require drops configurations that do not match all of the predicates
+	Require []ConfigPredicate
+}
+
+func (f Filter) matchesInfo(info *Info) bool {
+	for _, predicate := range f.Exclude {
+		if predicate(info) {
+			return false
+		}
+	}
+	if len(f.Include) == 0 {
+		return true
+	}
+	for _, predicate := range f.Include {
+		if predicate(info) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f Filter) matchesConfig(configuration *cioperatorapi.ReleaseBuildConfiguration) bool {
+	for _, predicate := range f.Require {
+		if !predicate(configuration) {
+			return false
+		}
+	}
+	return true
+}
+
+// OperateOnFilteredCIOperatorConfigDir runs the callback on the CI Operator
+// configuration files found while walking the directory provided that are
+// selected by the filter
+func OperateOnFilteredCIOperatorConfigDir(configDir string, filter Filter, callback func(*cioperatorapi.ReleaseBuildConfiguration, *Info) error) error {
+	return filepath.Walk(configDir, func(path string, fileInfo os.FileInfo, err error) error {
+		if err != nil {
+			logrus.WithField("source-file", path).WithError(err).Error("Failed to walk CI Operator configuration dir")
+			return err
+		}
+		if !isConfigFile(path, fileInfo) {
+			return nil
+		}
+		info, err := InfoFromPath(path)
+		if err != nil {
+			logrus.WithField("source-file", path).WithError(err).Error("Failed to resolve info from CI Operator configuration path")
+			return err
+		}
+		if !filter.matchesInfo(info) {
+			return nil
+		}
+		configuration, err := readCiOperatorConfig(path, *info)
+		if err != nil {
+			logrus.WithField("source-file", path).WithError(err).Error("Failed to load CI Operator configuration")
+			return err
+		}
+		if !filter.matchesConfig(configuration) {
+			return nil
+		}
+		if err := callback(configuration, info); err != nil {
+			logrus.WithField("source-file", path).WithError(err).Error("Failed to execute callback")
+			return err
+		}
+		return nil
+	})
+}
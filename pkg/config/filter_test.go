@@ -0,0 +1,108 @@
+package config
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/openshift/ci-tools/pkg/api"
+)
+
+func TestOperateOnFilteredCIOperatorConfigDir(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, "org", "repo", "master", "")
+	writeConfig(t, dir, "org", "repo", "master", "variant")
+	writeConfig(t, dir, "org", "other", "release-4.9", "")
+	writeConfig(t, dir, "other", "repo", "master", "")
+
+	var testCases = []struct {
+		name     string
+		filter   Filter
+		expected []string
+	}{
+		{
+			name:     "no predicates match everything",
+			expected: []string{"org/other@release-4.9", "org/repo@master", "org/repo@master__variant", "other/repo@master"},
+		},
+		{
+			name:     "any include predicate matches",
+			filter:   Filter{Include: []InfoPredicate{ByOrg("other"), ByBranch("release-4.9")}},
+			expected: []string{"org/other@release-4.9", "other/repo@master"},
+		},
+		{
+			name:     "exclude predicates drop included configs",
+			filter:   Filter{Include: []InfoPredicate{ByRepo("org/repo")}, Exclude: []InfoPredicate{ByVariant("variant")}},
+			expected: []string{"org/repo@master"},
+		},
+		{
+			name:     "empty variant matches configs without variant",
+			filter:   Filter{Include: []InfoPredicate{ByVariant("")}},
+			expected: []string{"org/other@release-4.9", "org/repo@master", "other/repo@master"},
+		},
+		{
+			name:     "required predicates on the content match",
+			filter:   Filter{Include: []InfoPredicate{ByOrg("org")}, Require: []ConfigPredicate{HasTests}},
+			expected: []string{"org/other@release-4.9", "org/repo@master", "org/repo@master__variant"},
+		},
+		{
+			name:   "required predicates on the content drop configs",
+			filter: Filter{Require: []ConfigPredicate{HasTests, HasImages}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var handled []string
+			if err := OperateOnFilteredCIOperatorConfigDir(dir, tc.filter, func(_ *api.ReleaseBuildConfiguration, info *Info) error {
+				handled = append(handled, fmt.Sprintf("%s/%s@%s", info.Org, info.Repo, info.Branch))
+				if info.Variant != "" {
+					handled[len(handled)-1] += "__" + info.Variant
+				}
+				return nil
+			}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, handled); diff != "" {
+				t.Errorf("unexpected configs handled: %s", diff)
+			}
+		})
+	}
+}
+
+func benchmarkConfigDir(b *testing.B) string {
+	dir := b.TempDir()
+	for i := 0; i < 10; i++ {
+		for j := 0; j < 10; j++ {
+			writeConfig(b, dir, fmt.Sprintf("org%d", i), fmt.Sprintf("repo%d", j), "master", "")
+		}
+	}
+	return dir
+}
+
+func BenchmarkOperateOnCIOperatorConfigDir(b *testing.B) {
+	dir := benchmarkConfigDir(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		// without a filter, every file is read before the callback can skip it
+		if err := OperateOnCIOperatorConfigDir(dir, func(configuration *api.ReleaseBuildConfiguration, info *Info) error {
+			return nil
+		}); err != nil {
+			b.Fatalf("unexpected error: %v", err)
+		}
+	}
+}
+
+func BenchmarkOperateOnFilteredCIOperatorConfigDir(b *testing.B) {
+	dir := benchmarkConfigDir(b)
+	filter := Filter{Include: []InfoPredicate{ByOrg("org0")}}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := OperateOnFilteredCIOperatorConfigDir(dir, filter, func(configuration *api.ReleaseBuildConfiguration, info *Info) error {
+			return nil
+		}); err != nil {
+			b.Fatalf("unexpected error: %v", err)
+		}
+	}
+}
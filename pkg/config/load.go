@@ -6,12 +6,18 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"runtime"
 	"strings"
+	"sync"
+	"sync/atomic"
 
 	"github.com/ghodss/yaml"
 	"github.com/sirupsen/logrus"
 
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
 	cioperatorapi "github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/jsonschema"
 	"github.com/openshift/ci-tools/pkg/util/gzip"
 	"github.com/openshift/ci-tools/pkg/validation"
 )
@@ -36,19 +42,39 @@ func readCiOperatorConfig(configFilePath string, info Info) (*cioperatorapi.Rele
 	if err != nil {
 		return nil, fmt.Errorf("failed to read ci-operator config (%w)", err)
 	}
+	return parseCiOperatorConfig(data, &info)
+}
 
+func parseCiOperatorConfig(data []byte, info *Info) (*cioperatorapi.ReleaseBuildConfiguration, error) {
 	var configSpec cioperatorapi.ReleaseBuildConfiguration
 	if err := yaml.Unmarshal(data, &configSpec); err != nil {
-		return nil, fmt.Errorf("failed to load ci-operator config (%w)", err)
+		return nil, fmt.Errorf("failed to load ci-operator config (%w)", explainUnmarshalError(data, err))
 	}
-
 	if err := validation.IsValidConfiguration(&configSpec, info.Org, info.Repo); err != nil {
 		return nil, fmt.Errorf("invalid ci-operator config: %w", err)
 	}
-
 	return &configSpec, nil
 }
 
+var (
+	schemaOnce sync.Once
+	schema     *jsonschema.Schema
+)
+
+// CIOperatorConfigSchema returns the JSON schema of ci-operator configuration files
+func CIOperatorConfigSchema() *jsonschema.Schema {
+	schemaOnce.Do(func() {
+		schema = jsonschema.For(&cioperatorapi.ReleaseBuildConfiguration{})
+	})
+	return schema
+}
+
+// explainUnmarshalError adds the problems the schema finds in the file to the
+// error of unmarshalling it, as they carry their position in the file
+func explainUnmarshalError(data []byte, err error) error {
+	return utilerrors.NewAggregate(append([]error{err}, CIOperatorConfigSchema().ValidateYAML(data)...))
+}
+
 // Info describes the metadata for a CI Operator configuration file
 // along with where it's loaded from
 type Info struct {
@@ -156,6 +182,68 @@ func OperateOnCIOperatorConfigSubdir(configDir, subDir string, callback func(*ci
 	})
 }
 
+// ParallelOptions configures how OperateOnCIOperatorConfigDirInParallel
+// runs the callback
+type ParallelOptions struct {
+	// Workers is the number of callbacks that run at the same time.
+	// It defaults to the number of CPUs.
+	Workers int
+	// FailFast stops running the callback on files that were not
+	// handled yet once it failed for one of them.
+	FailFast bool
+}
+
+// OperateOnCIOperatorConfigDirInParallel runs the callback on all CI Operator
+// configuration files found while walking the directory provided, from as many
+// goroutines as there are workers. The callback must be safe to run concurrently.
+// Errors are aggregated in the order of the files they occurred for, so they do
+// not depend on the order in which the workers ran.
+func OperateOnCIOperatorConfigDirInParallel(configDir string, opts ParallelOptions, callback func(*cioperatorapi.ReleaseBuildConfiguration, *Info) error) error {
+	var paths []string
+	if err := filepath.Walk(configDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			logrus.WithField("source-file", path).WithError(err).Error("Failed to walk CI Operator configuration dir")
+			return err
+		}
+		if isConfigFile(path, info) {
+			paths = append(paths, path)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	workers := opts.Workers
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+	errs := make([]error, len(paths))
+	var failed int32
+	indices := make(chan int)
+	wg := &sync.WaitGroup{}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indices {
+				if opts.FailFast && atomic.LoadInt32(&failed) != 0 {
+					continue
+				}
+				if err := OperateOnCIOperatorConfig(paths[index], callback); err != nil {
+					errs[index] = fmt.Errorf("%s: %w", paths[index], err)
+					atomic.StoreInt32(&failed, 1)
+				}
+			}
+		}()
+	}
+	for index := range paths {
+		indices <- index
+	}
+	close(indices)
+	wg.Wait()
+	return utilerrors.NewAggregate(errs)
+}
+
 func LoggerForInfo(info Info) *logrus.Entry {
 	return logrus.WithFields(info.LogFields())
 }
@@ -191,21 +279,14 @@ func (i *DataWithInfo) CommitTo(dir string) error {
 // DataByFilename stores CI Operator configurations with their metadata by filename
 type DataByFilename map[string]DataWithInfo
 
-func (all DataByFilename) add(handledConfig *cioperatorapi.ReleaseBuildConfiguration, handledElements *Info) error {
-	all[handledElements.Basename()] = DataWithInfo{
-		Configuration: *handledConfig,
-		Info:          *handledElements,
-	}
-	return nil
-}
+// dataLoader is shared by all LoadDataByFilename calls, so files that did not
+// change between calls are not parsed again
+var dataLoader = NewLoader(nil)
 
+// LoadDataByFilename loads all CI Operator configurations found below path in parallel.
+// The configurations are shared with other calls and must not be modified.
 func LoadDataByFilename(path string) (DataByFilename, error) {
-	config := DataByFilename{}
-	if err := OperateOnCIOperatorConfigDir(path, config.add); err != nil {
-		return nil, err
-	}
-
-	return config, nil
+	return dataLoader.Load(path)
 }
 
 // ByFilename stores CI Operator configurations with their metadata by filename
@@ -1,9 +1,18 @@
 package config
 
 import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"reflect"
+	"sync"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
 	"k8s.io/apimachinery/pkg/util/diff"
 
 	"github.com/openshift/ci-tools/pkg/api"
@@ -92,3 +101,126 @@ func TestExtractRepoElementsFromPath(t *testing.T) {
 		})
 	}
 }
+
+func TestOperateOnCIOperatorConfigDirInParallel(t *testing.T) {
+	dir := t.TempDir()
+	branches := []string{"master", "release-4.7", "release-4.8", "release-4.9"}
+	for _, branch := range branches {
+		writeConfig(t, dir, "org", "repo", branch, "")
+	}
+
+	var testCases = []struct {
+		name          string
+		opts          ParallelOptions
+		failOn        []string
+		expected      []string
+		expectedError string
+	}{
+		{
+			name:     "callback runs on all files",
+			opts:     ParallelOptions{Workers: 2},
+			expected: branches,
+		},
+		{
+			name:     "callback runs on all files with the default number of workers",
+			expected: branches,
+		},
+		{
+			name:     "errors are aggregated in the order of the files",
+			opts:     ParallelOptions{Workers: 4},
+			failOn:   []string{"release-4.9", "master"},
+			expected: branches,
+			expectedError: fmt.Sprintf("[%s: failed on master, %s: failed on release-4.9]",
+				filepath.Join(dir, "org", "repo", "org-repo-master.yaml"), filepath.Join(dir, "org", "repo", "org-repo-release-4.9.yaml")),
+		},
+		{
+			name:          "callback stops after an error when failing fast",
+			opts:          ParallelOptions{Workers: 1, FailFast: true},
+			failOn:        []string{"master"},
+			expected:      []string{"master"},
+			expectedError: fmt.Sprintf("%s: failed on master", filepath.Join(dir, "org", "repo", "org-repo-master.yaml")),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var handled []string
+			lock := &sync.Mutex{}
+			err := OperateOnCIOperatorConfigDirInParallel(dir, tc.opts, func(configuration *api.ReleaseBuildConfiguration, info *Info) error {
+				lock.Lock()
+				handled = append(handled, info.Branch)
+				lock.Unlock()
+				for _, branch := range tc.failOn {
+					if branch == info.Branch {
+						return errors.New("failed on " + branch)
+					}
+				}
+				return nil
+			})
+			var actualError string
+			if err != nil {
+				actualError = err.Error()
+			}
+			if diff := cmp.Diff(tc.expectedError, actualError); diff != "" {
+				t.Errorf("unexpected error: %s", diff)
+			}
+			if diff := cmp.Diff(tc.expected, handled, cmpopts.SortSlices(func(a, b string) bool { return a < b })); diff != "" {
+				t.Errorf("unexpected files handled: %s", diff)
+			}
+		})
+	}
+}
+
+func writeConfig(t testing.TB, dir, org, repo, branch, variant string) {
+	name := fmt.Sprintf("%s-%s-%s", org, repo, branch)
+	if variant != "" {
+		name += "__" + variant
+	}
+	path := filepath.Join(dir, org, repo, name+".yaml")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	raw := fmt.Sprintf(`build_root:
+  image_stream_tag:
+    name: release
+    namespace: openshift
+    tag: golang-1.13
+resources:
+  '*':
+    requests:
+      cpu: 100m
+tests:
+- as: unit
+  commands: make test
+  container:
+    from: src
+zz_generated_metadata:
+  branch: %s
+  org: %s
+  repo: %s
+  variant: %s
+`, branch, org, repo, variant)
+	if err := ioutil.WriteFile(path, []byte(raw), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+}
+
+func TestParseCiOperatorConfigExplainsErrors(t *testing.T) {
+	raw := `base_images:
+  base:
+    name: 4.3
+    namespace: ocp
+    tag: base
+tests:
+- commands: make test
+`
+	_, err := parseCiOperatorConfig([]byte(raw), &Info{})
+	if err == nil {
+		t.Fatal("expected an error, got none")
+	}
+	expected := "failed to load ci-operator config ([error unmarshaling JSON: json: cannot unmarshal number into Go struct field ReleaseBuildConfiguration.base_images.base.name of type string, " +
+		"line 3, column 11: base_images.base.name: expected a string, got float \"4.3\", " +
+		"line 7, column 3: tests[0]: missing required field as])"
+	if diff := cmp.Diff(expected, err.Error()); diff != "" {
+		t.Errorf("unexpected error: %s", diff)
+	}
+}
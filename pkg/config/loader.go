@@ -0,0 +1,176 @@
+package config
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	cioperatorapi "github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/util/gzip"
+)
+
+// ParseFunc unmarshals and validates the content of a ci-operator configuration file
+type ParseFunc func(data []byte, info *Info) (*cioperatorapi.ReleaseBuildConfiguration, error)
+
+// Loader loads all ci-operator configuration files below a directory. The files are
+// read and parsed in parallel. A Loader remembers the checksum of every file it parsed,
+// so files that did not change since the previous Load are not parsed again. The
+// configurations are shared between Loads and must not be modified by callers.
+type Loader struct {
+	parse ParseFunc
+	orgs  sets.String
+
+	lock  sync.Mutex
+	cache map[string]cachedConfig
+}
+
+type cachedConfig struct {
+	checksum [sha256.Size]byte
+	config   *cioperatorapi.ReleaseBuildConfiguration
+}
+
+// LoaderOption configures a Loader
+type LoaderOption func(*Loader)
+
+// OnlyOrgs makes a Loader skip the directories of all other orgs, so only
+// the configurations of the given orgs are kept in memory
+func OnlyOrgs(orgs ...string) LoaderOption {
+	return func(l *Loader) {
+		l.orgs = sets.NewString(orgs...)
+	}
+}
+
+// NewLoader returns a Loader that uses parse for files it has not seen before. If parse
+// is nil, configurations are fully validated like when operating on a directory.
+func NewLoader(parse ParseFunc, opts ...LoaderOption) *Loader {
+	if parse == nil {
+		parse = parseCiOperatorConfig
+	}
+	l := &Loader{parse: parse, cache: map[string]cachedConfig{}}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// Load returns all configurations found at or below the given path. The files are
+// parsed by as many goroutines as there are CPUs.
+func (l *Loader) Load(root string) (DataByFilename, error) {
+	var paths []string
+	if err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if info == nil || err != nil {
+			return err
+		}
+		// Skip the internal directories of ConfigMap volumes
+		if strings.HasPrefix(info.Name(), "..") {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() && filepath.Dir(path) == filepath.Clean(root) && !l.Includes(info.Name()) {
+			return filepath.SkipDir
+		}
+		if isConfigFile(path, info) {
+			paths = append(paths, path)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	configs := DataByFilename{}
+	cache := map[string]cachedConfig{}
+	errs := make([]error, len(paths))
+	lock := &sync.Mutex{}
+	indices := make(chan int)
+	wg := &sync.WaitGroup{}
+	for i := 0; i < runtime.NumCPU(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indices {
+				entry, info, err := l.loadFile(paths[index])
+				if err != nil {
+					errs[index] = err
+					continue
+				}
+				lock.Lock()
+				cache[paths[index]] = entry
+				configs[info.Basename()] = DataWithInfo{Configuration: *entry.config, Info: *info}
+				lock.Unlock()
+			}
+		}()
+	}
+	for index := range paths {
+		indices <- index
+	}
+	close(indices)
+	wg.Wait()
+	if err := utilerrors.NewAggregate(errs); err != nil {
+		return nil, err
+	}
+
+	// Replace rather than update the cache, so entries of deleted files are dropped
+	l.lock.Lock()
+	l.cache = cache
+	l.lock.Unlock()
+	return configs, nil
+}
+
+// Includes determines whether the configurations of the org are loaded
+func (l *Loader) Includes(org string) bool {
+	return len(l.orgs) == 0 || l.orgs.Has(org)
+}
+
+// LoadFile returns the configuration in a single file, parsing it only if it
+// changed since it was last loaded.
+func (l *Loader) LoadFile(path string) (*DataWithInfo, error) {
+	entry, info, err := l.loadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	l.lock.Lock()
+	l.cache[path] = entry
+	l.lock.Unlock()
+	return &DataWithInfo{Configuration: *entry.config, Info: *info}, nil
+}
+
+// Forget drops a file that was removed from the cache
+func (l *Loader) Forget(path string) {
+	l.lock.Lock()
+	delete(l.cache, path)
+	l.lock.Unlock()
+}
+
+func (l *Loader) loadFile(path string) (cachedConfig, *Info, error) {
+	info, err := InfoFromPath(path)
+	if err != nil {
+		return cachedConfig{}, nil, fmt.Errorf("failed to resolve info from CI Operator configuration path %s: %w", path, err)
+	}
+	data, err := gzip.ReadFileMaybeGZIP(path)
+	if err != nil {
+		return cachedConfig{}, nil, fmt.Errorf("failed to read ci-operator config %s: %w", path, err)
+	}
+	checksum := sha256.Sum256(data)
+
+	l.lock.Lock()
+	cached, ok := l.cache[path]
+	l.lock.Unlock()
+	if ok && cached.checksum == checksum {
+		return cached, info, nil
+	}
+
+	config, err := l.parse(data, info)
+	if err != nil {
+		return cachedConfig{}, nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return cachedConfig{checksum: checksum, config: config}, info, nil
+}
@@ -0,0 +1,144 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/ghodss/yaml"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/openshift/ci-tools/pkg/api"
+)
+
+func TestLoader(t *testing.T) {
+	dir := t.TempDir()
+	write := func(t *testing.T, name, targetName string) {
+		raw, err := yaml.Marshal(api.ReleaseBuildConfiguration{
+			InputConfiguration: api.InputConfiguration{
+				BuildRootImage: &api.BuildRootImageConfiguration{ImageStreamTagReference: &api.ImageStreamTagReference{Name: targetName}},
+			},
+		})
+		if err != nil {
+			t.Fatalf("failed to marshal config: %v", err)
+		}
+		path := filepath.Join(dir, "org", "repo", name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := ioutil.WriteFile(path, raw, 0644); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+	}
+	write(t, "org-repo-master.yaml", "master")
+	write(t, "org-repo-release-4.7.yaml", "release-4.7")
+
+	var parsed []string
+	lock := sync.Mutex{}
+	loader := NewLoader(func(data []byte, info *Info) (*api.ReleaseBuildConfiguration, error) {
+		lock.Lock()
+		parsed = append(parsed, info.Basename())
+		lock.Unlock()
+		var config api.ReleaseBuildConfiguration
+		return &config, yaml.Unmarshal(data, &config)
+	})
+	load := func(t *testing.T, expectedParsed []string, expectedNames map[string]string) {
+		parsed = nil
+		configs, err := loader.Load(dir)
+		if err != nil {
+			t.Fatalf("failed to load configs: %v", err)
+		}
+		if diff := cmp.Diff(expectedParsed, parsed, cmpopts.SortSlices(func(a, b string) bool { return a < b })); diff != "" {
+			t.Errorf("parsed files differ from expected: %s", diff)
+		}
+		names := map[string]string{}
+		for filename, config := range configs {
+			names[filename] = config.Configuration.BuildRootImage.ImageStreamTagReference.Name
+		}
+		if diff := cmp.Diff(expectedNames, names); diff != "" {
+			t.Errorf("loaded configs differ from expected: %s", diff)
+		}
+	}
+
+	t.Run("all files are parsed initially", func(t *testing.T) {
+		load(t, []string{"org-repo-master.yaml", "org-repo-release-4.7.yaml"}, map[string]string{
+			"org-repo-master.yaml":      "master",
+			"org-repo-release-4.7.yaml": "release-4.7",
+		})
+	})
+	t.Run("unchanged files are not parsed again", func(t *testing.T) {
+		load(t, nil, map[string]string{
+			"org-repo-master.yaml":      "master",
+			"org-repo-release-4.7.yaml": "release-4.7",
+		})
+	})
+	t.Run("changed and new files are parsed", func(t *testing.T) {
+		write(t, "org-repo-master.yaml", "changed")
+		write(t, "org-repo-release-4.8.yaml", "release-4.8")
+		load(t, []string{"org-repo-master.yaml", "org-repo-release-4.8.yaml"}, map[string]string{
+			"org-repo-master.yaml":      "changed",
+			"org-repo-release-4.7.yaml": "release-4.7",
+			"org-repo-release-4.8.yaml": "release-4.8",
+		})
+	})
+	t.Run("deleted files are dropped", func(t *testing.T) {
+		if err := os.Remove(filepath.Join(dir, "org", "repo", "org-repo-release-4.7.yaml")); err != nil {
+			t.Fatalf("failed to remove config: %v", err)
+		}
+		load(t, nil, map[string]string{
+			"org-repo-master.yaml":      "changed",
+			"org-repo-release-4.8.yaml": "release-4.8",
+		})
+	})
+	t.Run("single changed files are parsed", func(t *testing.T) {
+		write(t, "org-repo-master.yaml", "changed-again")
+		parsed = nil
+		data, err := loader.LoadFile(filepath.Join(dir, "org", "repo", "org-repo-master.yaml"))
+		if err != nil {
+			t.Fatalf("failed to load config: %v", err)
+		}
+		if diff := cmp.Diff([]string{"org-repo-master.yaml"}, parsed); diff != "" {
+			t.Errorf("parsed files differ from expected: %s", diff)
+		}
+		if diff := cmp.Diff("changed-again", data.Configuration.BuildRootImage.ImageStreamTagReference.Name); diff != "" {
+			t.Errorf("loaded config differs from expected: %s", diff)
+		}
+		load(t, nil, map[string]string{
+			"org-repo-master.yaml":      "changed-again",
+			"org-repo-release-4.8.yaml": "release-4.8",
+		})
+	})
+}
+
+func TestLoaderOnlyOrgs(t *testing.T) {
+	dir := t.TempDir()
+	for _, org := range []string{"org", "other"} {
+		path := filepath.Join(dir, org, "repo", org+"-repo-master.yaml")
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := ioutil.WriteFile(path, []byte("{}"), 0644); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+	}
+	loader := NewLoader(func(data []byte, info *Info) (*api.ReleaseBuildConfiguration, error) {
+		return &api.ReleaseBuildConfiguration{}, nil
+	}, OnlyOrgs("org"))
+	configs, err := loader.Load(dir)
+	if err != nil {
+		t.Fatalf("failed to load configs: %v", err)
+	}
+	var filenames []string
+	for filename := range configs {
+		filenames = append(filenames, filename)
+	}
+	if diff := cmp.Diff([]string{"org-repo-master.yaml"}, filenames); diff != "" {
+		t.Errorf("loaded configs differ from expected: %s", diff)
+	}
+	if !loader.Includes("org") || loader.Includes("other") {
+		t.Errorf("expected only org to be included")
+	}
+}
@@ -0,0 +1,194 @@
+package config
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/apimachinery/pkg/util/cache"
+
+	cioperatorapi "github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/load"
+	"github.com/openshift/ci-tools/pkg/registry"
+)
+
+const (
+	// resolversCached is how many registries loaded from revisions are kept in memory
+	resolversCached = 16
+	resolverTTL     = time.Hour
+)
+
+var revisionMatcher = regexp.MustCompile(`^[0-9a-f]{7,40}$`)
+
+// ErrUnknownRevision is returned when a revision can not be found in the release repo
+var ErrUnknownRevision = errors.New("unknown revision")
+
+// RevisionResolver resolves ci-operator configurations with the step registry
+// as they are at a revision of the release repo, reading them from the git
+// objects without touching the working copy
+type RevisionResolver struct {
+	releaseRepoPath string
+	// resolvers holds the registry resolvers by full commit SHA
+	resolvers *cache.LRUExpireCache
+}
+
+// NewRevisionResolver returns a resolver for revisions of the git clone of
+// the release repo at the path
+func NewRevisionResolver(releaseRepoPath string) *RevisionResolver {
+	return &RevisionResolver{
+		releaseRepoPath: releaseRepoPath,
+		resolvers:       cache.NewLRUExpireCache(resolversCached),
+	}
+}
+
+// ResolvedConfig returns the configuration for the metadata at the revision,
+// resolved with the step registry at the same revision. A nil configuration
+// is returned when the configuration does not exist at the revision.
+func (r *RevisionResolver) ResolvedConfig(revision string, metadata cioperatorapi.Metadata) (*cioperatorapi.ReleaseBuildConfiguration, error) {
+	sha, err := r.commit(revision)
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(CiopConfigInRepoPath, metadata.RelativePath())
+	if _, err := git(r.releaseRepoPath, "cat-file", "-e", sha+":"+path); err != nil {
+		return nil, nil
+	}
+	raw, err := git(r.releaseRepoPath, "show", sha+":"+path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s at %s: %w", path, sha, err)
+	}
+	var configuration cioperatorapi.ReleaseBuildConfiguration
+	if err := yaml.Unmarshal([]byte(raw), &configuration); err != nil {
+		return nil, fmt.Errorf("failed to load %s at %s: %w", path, sha, explainUnmarshalError([]byte(raw), err))
+	}
+	configuration.Metadata = metadata
+	resolver, err := r.registry(sha)
+	if err != nil {
+		return nil, err
+	}
+	resolved, err := registry.ResolveConfig(resolver, configuration)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s at %s: %w", path, sha, err)
+	}
+	return &resolved, nil
+}
+
+// commit returns the full SHA of the revision, fetching it from the origin
+// when it is not present in the clone yet
+func (r *RevisionResolver) commit(revision string) (string, error) {
+	// only accept hashes, so the revision can never be taken for an option
+	if !revisionMatcher.MatchString(revision) {
+		return "", fmt.Errorf("%w: %q is not a commit SHA", ErrUnknownRevision, revision)
+	}
+	if sha, err := revParse(r.releaseRepoPath, "--verify", "--quiet", revision+"^{commit}"); err == nil {
+		return sha, nil
+	}
+	if _, err := git(r.releaseRepoPath, "fetch", "--quiet", "origin", revision); err != nil {
+		logrus.WithError(err).WithField("revision", revision).Debug("Failed to fetch revision")
+	}
+	sha, err := revParse(r.releaseRepoPath, "--verify", "--quiet", revision+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrUnknownRevision, revision)
+	}
+	return sha, nil
+}
+
+// registry returns the resolver for the step registry at the commit
+func (r *RevisionResolver) registry(sha string) (registry.Resolver, error) {
+	if resolver, ok := r.resolvers.Get(sha); ok {
+		return resolver.(registry.Resolver), nil
+	}
+	dir, err := ioutil.TempDir("", "registry-"+sha)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create a directory for the registry: %w", err)
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			logrus.WithError(err).Warn("Failed to remove the registry directory")
+		}
+	}()
+	if err := exportTree(r.releaseRepoPath, sha, RegistryPath, dir); err != nil {
+		return nil, fmt.Errorf("failed to export the registry at %s: %w", sha, err)
+	}
+	references, chains, workflows, _, _, observers, err := load.Registry(filepath.Join(dir, RegistryPath), false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the registry at %s: %w", sha, err)
+	}
+	resolver := registry.NewResolver(references, chains, workflows, observers)
+	r.resolvers.Add(sha, resolver, resolverTTL)
+	return resolver, nil
+}
+
+// exportTree writes the files under the path at the commit into the directory
+func exportTree(repoPath, sha, path, dir string) error {
+	cmd := exec.Command("git", "archive", "--format=tar", sha, "--", path)
+	cmd.Dir = repoPath
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	extractErr := extractTar(stdout, dir)
+	if extractErr != nil {
+		// drain the archive so git can exit
+		_, _ = io.Copy(ioutil.Discard, stdout)
+	}
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("'%s' failed with error=%w, output:\n%s", cmd.Args, err, stderr.String())
+	}
+	return extractErr
+}
+
+func extractTar(r io.Reader, dir string) error {
+	archive := tar.NewReader(r)
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read the archive: %w", err)
+		}
+		target := filepath.Join(dir, header.Name)
+		if !strings.HasPrefix(target, filepath.Clean(dir)+string(os.PathSeparator)) {
+			return fmt.Errorf("archive entry %s is outside of the directory", header.Name)
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			file, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(header.Mode).Perm())
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(file, archive); err != nil {
+				file.Close()
+				return err
+			}
+			if err := file.Close(); err != nil {
+				return err
+			}
+		}
+	}
+}
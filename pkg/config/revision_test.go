@@ -0,0 +1,115 @@
+package config
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/openshift/ci-tools/pkg/api"
+)
+
+func TestRevisionResolverResolvedConfig(t *testing.T) {
+	dir := t.TempDir()
+	write := func(path, content string) {
+		t.Helper()
+		path = filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0775); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0664); err != nil {
+			t.Fatal(err)
+		}
+	}
+	run := func(script string) string {
+		t.Helper()
+		cmd := exec.Command("sh", "-ec", script)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("%q failed, output:\n%s", cmd.Args, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	ref := `ref:
+  as: step
+  from: src
+  commands: step-commands.sh
+  resources:
+    requests:
+      cpu: %s
+`
+	config := `resources:
+  '*':
+    requests:
+      cpu: 100m
+tests:
+- as: e2e
+  steps:
+    test:
+    - ref: step
+`
+	write("ci-operator/step-registry/step/step-ref.yaml", strings.Replace(ref, "%s", "100m", 1))
+	write("ci-operator/step-registry/step/step-commands.sh", "echo base\n")
+	run(`git init --quiet .
+git config user.name test
+git config user.email test
+git config commit.gpgsign false
+git add .
+git commit --quiet -m registry`)
+	base := run("git rev-parse HEAD")
+	write("ci-operator/config/org/repo/org-repo-master.yaml", config)
+	write("ci-operator/step-registry/step/step-ref.yaml", strings.Replace(ref, "%s", "200m", 1))
+	write("ci-operator/step-registry/step/step-commands.sh", "echo head\n")
+	run(`git add .
+git commit --quiet -m config`)
+	head := run("git rev-parse HEAD")
+	// the working copy must not influence the resolved configurations
+	write("ci-operator/step-registry/step/step-commands.sh", "echo dirty\n")
+
+	resolver := NewRevisionResolver(dir)
+	metadata := api.Metadata{Org: "org", Repo: "repo", Branch: "master"}
+
+	t.Run("config missing at the revision", func(t *testing.T) {
+		resolved, err := resolver.ResolvedConfig(base, metadata)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resolved != nil {
+			t.Errorf("expected no config, got %v", resolved)
+		}
+	})
+
+	t.Run("config is resolved with the registry at the revision", func(t *testing.T) {
+		resolved, err := resolver.ResolvedConfig(head[:12], metadata)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if diff := cmp.Diff(metadata, resolved.Metadata); diff != "" {
+			t.Errorf("unexpected metadata: %s", diff)
+		}
+		steps := resolved.Tests[0].MultiStageTestConfigurationLiteral.Test
+		if len(steps) != 1 {
+			t.Fatalf("expected one resolved step, got %d", len(steps))
+		}
+		if diff := cmp.Diff("echo head\n", steps[0].Commands); diff != "" {
+			t.Errorf("unexpected commands: %s", diff)
+		}
+		if diff := cmp.Diff(api.ResourceList{"cpu": "200m"}, steps[0].Resources.Requests); diff != "" {
+			t.Errorf("unexpected resources: %s", diff)
+		}
+	})
+
+	for _, revision := range []string{"0123456789abcdef", "--upload-pack=true", "HEAD"} {
+		t.Run("unknown revision "+revision, func(t *testing.T) {
+			if _, err := resolver.ResolvedConfig(revision, metadata); !errors.Is(err, ErrUnknownRevision) {
+				t.Errorf("expected an unknown revision error, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+// Package health implements health and readiness checks for controllers. The
+// per-controller health is derived from the workqueue and reconcile metrics that
+// controller-runtime records for every controller, so controllers do not need
+// to be changed in order to be checked.
+package health
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+)
+
+const (
+	workqueueDepthMetric                   = "workqueue_depth"
+	workqueueLongestRunningProcessorMetric = "workqueue_longest_running_processor_seconds"
+	reconcileTotalMetric                   = "controller_runtime_reconcile_total"
+)
+
+// Options holds the thresholds after which a controller is considered unhealthy
+type Options struct {
+	// MaxQueueDepth is the maximum number of items that may be queued
+	MaxQueueDepth int
+	// MaxReconcileDuration is the maximum duration a single reconciliation may take
+	MaxReconcileDuration time.Duration
+	// MaxTimeSinceSuccess is the maximum duration without a successful
+	// reconciliation while there are items in the queue
+	MaxTimeSinceSuccess time.Duration
+}
+
+// Checker checks the health of controllers based on their metrics
+type Checker struct {
+	gatherer prometheus.Gatherer
+	opts     Options
+	now      func() time.Time
+
+	lock     sync.Mutex
+	statuses map[string]*controllerStatus
+}
+
+type controllerStatus struct {
+	successfulReconciles float64
+	lastSuccess          time.Time
+}
+
+// controllerMetrics are the metrics of a single controller at a given point in time
+type controllerMetrics struct {
+	queueDepth            float64
+	longestRunningSeconds float64
+	successfulReconciles  float64
+}
+
+// NewChecker returns a Checker that gathers metrics from the given gatherer,
+// usually the controller-runtime metrics registry.
+func NewChecker(gatherer prometheus.Gatherer, opts Options) *Checker {
+	return &Checker{
+		gatherer: gatherer,
+		opts:     opts,
+		now:      time.Now,
+		statuses: map[string]*controllerStatus{},
+	}
+}
+
+// CheckerFor returns a healthz.Checker for the controller with the given name.
+// Register it under the name of the controller to get its status reported at
+// /healthz/<name>.
+func (c *Checker) CheckerFor(controller string) healthz.Checker {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if _, exists := c.statuses[controller]; !exists {
+		c.statuses[controller] = &controllerStatus{lastSuccess: c.now()}
+	}
+	return func(_ *http.Request) error {
+		if err := c.check(controller); err != nil {
+			logrus.WithField("controller", controller).WithError(err).Warn("Controller is unhealthy")
+			return err
+		}
+		return nil
+	}
+}
+
+func (c *Checker) check(controller string) error {
+	metrics, err := c.gather()
+	if err != nil {
+		return fmt.Errorf("failed to gather metrics: %w", err)
+	}
+	current := metrics[controller]
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	status := c.statuses[controller]
+	if current.successfulReconciles > status.successfulReconciles {
+		status.successfulReconciles = current.successfulReconciles
+		status.lastSuccess = c.now()
+	}
+
+	if c.opts.MaxQueueDepth > 0 && current.queueDepth > float64(c.opts.MaxQueueDepth) {
+		return fmt.Errorf("workqueue depth %.0f exceeds the maximum of %d", current.queueDepth, c.opts.MaxQueueDepth)
+	}
+	if longestRunning := time.Duration(current.longestRunningSeconds * float64(time.Second)); c.opts.MaxReconcileDuration > 0 && longestRunning > c.opts.MaxReconcileDuration {
+		return fmt.Errorf("a reconciliation has been running for %s, exceeding the maximum of %s", longestRunning.Round(time.Second), c.opts.MaxReconcileDuration)
+	}
+	if sinceSuccess := c.now().Sub(status.lastSuccess); c.opts.MaxTimeSinceSuccess > 0 && current.queueDepth > 0 && sinceSuccess > c.opts.MaxTimeSinceSuccess {
+		return fmt.Errorf("there are %.0f queued items but the last successful reconciliation was %s ago, exceeding the maximum of %s", current.queueDepth, sinceSuccess.Round(time.Second), c.opts.MaxTimeSinceSuccess)
+	}
+	return nil
+}
+
+func (c *Checker) gather() (map[string]controllerMetrics, error) {
+	families, err := c.gatherer.Gather()
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]controllerMetrics{}
+	for _, family := range families {
+		for _, metric := range family.Metric {
+			switch family.GetName() {
+			case workqueueDepthMetric:
+				name := labelValue(metric, "name")
+				current := result[name]
+				current.queueDepth = metric.GetGauge().GetValue()
+				result[name] = current
+			case workqueueLongestRunningProcessorMetric:
+				name := labelValue(metric, "name")
+				current := result[name]
+				current.longestRunningSeconds = metric.GetGauge().GetValue()
+				result[name] = current
+			case reconcileTotalMetric:
+				if labelValue(metric, "result") != "success" {
+					continue
+				}
+				name := labelValue(metric, "controller")
+				current := result[name]
+				current.successfulReconciles = metric.GetCounter().GetValue()
+				result[name] = current
+			}
+		}
+	}
+	return result, nil
+}
+
+func labelValue(metric *dto.Metric, name string) string {
+	for _, label := range metric.Label {
+		if label.GetName() == name {
+			return label.GetValue()
+		}
+	}
+	return ""
+}
+
+// CacheSyncedChecker returns a healthz.Checker that succeeds once all informers
+// of the given cache have synced.
+func CacheSyncedChecker(c cache.Cache) healthz.Checker {
+	return func(req *http.Request) error {
+		ctx, cancel := context.WithTimeout(req.Context(), time.Second)
+		defer cancel()
+		if !c.WaitForCacheSync(ctx) {
+			return fmt.Errorf("informers have not synced yet")
+		}
+		return nil
+	}
+}
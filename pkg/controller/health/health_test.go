@@ -0,0 +1,86 @@
+package health
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/openshift/ci-tools/pkg/testhelper"
+)
+
+func TestCheckerFor(t *testing.T) {
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		name      string
+		depth     float64
+		longest   float64
+		successes float64
+		elapsed   time.Duration
+		expected  error
+	}{
+		{
+			name: "idle controller is healthy",
+		},
+		{
+			name:     "queue depth exceeds threshold",
+			depth:    11,
+			expected: errors.New("workqueue depth 11 exceeds the maximum of 10"),
+		},
+		{
+			name:     "wedged reconciliation",
+			longest:  120,
+			expected: errors.New("a reconciliation has been running for 2m0s, exceeding the maximum of 1m0s"),
+		},
+		{
+			name:     "queued items without recent success",
+			depth:    1,
+			elapsed:  time.Hour,
+			expected: errors.New("there are 1 queued items but the last successful reconciliation was 1h0m0s ago, exceeding the maximum of 10m0s"),
+		},
+		{
+			name:      "queued items with recent success",
+			depth:     1,
+			successes: 5,
+			elapsed:   time.Hour,
+		},
+		{
+			name:    "idle controller without recent success is healthy",
+			elapsed: time.Hour,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			registry := prometheus.NewRegistry()
+			depth := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: workqueueDepthMetric}, []string{"name"})
+			longest := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: workqueueLongestRunningProcessorMetric}, []string{"name"})
+			reconciles := prometheus.NewCounterVec(prometheus.CounterOpts{Name: reconcileTotalMetric}, []string{"controller", "result"})
+			registry.MustRegister(depth, longest, reconciles)
+			for _, controller := range []string{"controller", "other"} {
+				depth.WithLabelValues(controller)
+				longest.WithLabelValues(controller)
+				reconciles.WithLabelValues(controller, "success")
+			}
+
+			now := start
+			checker := NewChecker(registry, Options{MaxQueueDepth: 10, MaxReconcileDuration: time.Minute, MaxTimeSinceSuccess: 10 * time.Minute})
+			checker.now = func() time.Time { return now }
+			check, otherCheck := checker.CheckerFor("controller"), checker.CheckerFor("other")
+
+			now = now.Add(tc.elapsed)
+			reconciles.WithLabelValues("controller", "success").Add(tc.successes)
+			depth.WithLabelValues("controller").Set(tc.depth)
+			longest.WithLabelValues("controller").Set(tc.longest)
+
+			if diff := cmp.Diff(tc.expected, check(nil), testhelper.EquateErrorMessage); diff != "" {
+				t.Errorf("error differs from expected: %s", diff)
+			}
+			if err := otherCheck(nil); err != nil {
+				t.Errorf("expected other controller to be healthy, got error: %v", err)
+			}
+		})
+	}
+}
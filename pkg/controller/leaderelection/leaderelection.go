@@ -0,0 +1,171 @@
+// Package leaderelection allows to shard controllers across replicas by giving
+// each of them its own lease, rather than electing a single leader that runs
+// all controllers.
+package leaderelection
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	ctrlruntimeleaderelection "sigs.k8s.io/controller-runtime/pkg/leaderelection"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// Same defaults as controller-runtime uses
+const (
+	defaultLeaseDuration = 15 * time.Second
+	defaultRenewDeadline = 10 * time.Second
+	defaultRetryPeriod   = 2 * time.Second
+)
+
+// Lease starts the runnables that were added to it only while it is held.
+type Lease struct {
+	name string
+	lock resourcelock.Interface
+
+	leaseDuration time.Duration
+	renewDeadline time.Duration
+	retryPeriod   time.Duration
+
+	runnablesLock sync.Mutex
+	runnables     []manager.Runnable
+}
+
+// NewLease constructs a lease with the given name in the given namespace and adds it to mgr. The
+// mgr itself must not use leader election, otherwise the lease is only acquired on the leader.
+func NewLease(mgr manager.Manager, namespace, name string) (*Lease, error) {
+	lock, err := ctrlruntimeleaderelection.NewResourceLock(mgr.GetConfig(), mgr, ctrlruntimeleaderelection.Options{
+		LeaderElection:          true,
+		LeaderElectionNamespace: namespace,
+		LeaderElectionID:        name,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to construct resource lock for lease %s: %w", name, err)
+	}
+	lease := newLease(name, lock)
+	if err := mgr.Add(lease); err != nil {
+		return nil, fmt.Errorf("failed to add lease %s to manager: %w", name, err)
+	}
+	return lease, nil
+}
+
+func newLease(name string, lock resourcelock.Interface) *Lease {
+	return &Lease{
+		name:          name,
+		lock:          lock,
+		leaseDuration: defaultLeaseDuration,
+		renewDeadline: defaultRenewDeadline,
+		retryPeriod:   defaultRetryPeriod,
+	}
+}
+
+// Manager returns a manager that behaves like mgr, except that runnables that get added
+// to it, for example controllers, are only started while the lease is held. This allows
+// controllers to be sharded without changing how they are set up.
+func (l *Lease) Manager(mgr manager.Manager) manager.Manager {
+	return &leasedManager{Manager: mgr, lease: l}
+}
+
+func (l *Lease) add(runnable manager.Runnable) {
+	l.runnablesLock.Lock()
+	defer l.runnablesLock.Unlock()
+	l.runnables = append(l.runnables, runnable)
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable. The lease
+// has to start regardless of the manager being the leader or not.
+func (l *Lease) NeedLeaderElection() bool {
+	return false
+}
+
+// Start implements manager.Runnable. It blocks until the context is cancelled,
+// the lease is lost or one of the runnables fails.
+func (l *Lease) Start(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	log := logrus.WithField("lease", l.name)
+
+	// The elector calls OnStartedLeading asynchronously, so it might get called after
+	// it returned. The lock ensures we never start runnables after we stopped.
+	var lock sync.Mutex
+	var stopped bool
+	var runErr error
+	var wg sync.WaitGroup
+	elector, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock:            l.lock,
+		Name:            l.name,
+		LeaseDuration:   l.leaseDuration,
+		RenewDeadline:   l.renewDeadline,
+		RetryPeriod:     l.retryPeriod,
+		ReleaseOnCancel: true,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(leadingCtx context.Context) {
+				lock.Lock()
+				defer lock.Unlock()
+				if stopped {
+					return
+				}
+				log.Info("Acquired lease, starting runnables")
+				l.runnablesLock.Lock()
+				defer l.runnablesLock.Unlock()
+				for _, runnable := range l.runnables {
+					wg.Add(1)
+					go func(runnable manager.Runnable) {
+						defer wg.Done()
+						if err := runnable.Start(leadingCtx); err != nil {
+							lock.Lock()
+							if runErr == nil {
+								runErr = err
+							}
+							lock.Unlock()
+							cancel()
+						}
+					}(runnable)
+				}
+			},
+			OnStoppedLeading: func() {
+				log.Info("Stopped leading")
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to construct leader elector for lease %s: %w", l.name, err)
+	}
+
+	elector.Run(ctx)
+	lock.Lock()
+	stopped = true
+	lock.Unlock()
+	wg.Wait()
+
+	lock.Lock()
+	defer lock.Unlock()
+	if runErr != nil {
+		return runErr
+	}
+	if ctx.Err() == nil {
+		return fmt.Errorf("lost lease %s", l.name)
+	}
+	return nil
+}
+
+type leasedManager struct {
+	manager.Manager
+	lease *Lease
+}
+
+// Add injects the dependencies into the runnable like the wrapped manager would
+// and then hands it over to the lease.
+func (m *leasedManager) Add(runnable manager.Runnable) error {
+	if err := m.Manager.SetFields(runnable); err != nil {
+		return err
+	}
+	m.lease.add(runnable)
+	return nil
+}
@@ -0,0 +1,103 @@
+package leaderelection
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+func testLease(t *testing.T, client *fake.Clientset, identity string) *Lease {
+	lock, err := resourcelock.New(resourcelock.LeasesResourceLock, "ci", "lease", client.CoreV1(), client.CoordinationV1(), resourcelock.ResourceLockConfig{Identity: identity})
+	if err != nil {
+		t.Fatalf("failed to construct lock: %v", err)
+	}
+	lease := newLease("lease", lock)
+	lease.leaseDuration = 2 * time.Second
+	lease.renewDeadline = time.Second
+	lease.retryPeriod = 100 * time.Millisecond
+	return lease
+}
+
+func TestLease(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	first, second := testLease(t, client, "first"), testLease(t, client, "second")
+
+	firstStarted, secondStarted := make(chan struct{}), make(chan struct{})
+	first.add(manager.RunnableFunc(func(ctx context.Context) error {
+		close(firstStarted)
+		<-ctx.Done()
+		return nil
+	}))
+	second.add(manager.RunnableFunc(func(ctx context.Context) error {
+		close(secondStarted)
+		<-ctx.Done()
+		return nil
+	}))
+
+	firstCtx, firstCancel := context.WithCancel(context.Background())
+	firstDone := make(chan error)
+	go func() { firstDone <- first.Start(firstCtx) }()
+	select {
+	case <-firstStarted:
+	case <-time.After(5 * time.Second):
+		t.Fatal("runnable of the first lease was not started")
+	}
+
+	secondCtx, secondCancel := context.WithCancel(context.Background())
+	defer secondCancel()
+	secondDone := make(chan error)
+	go func() { secondDone <- second.Start(secondCtx) }()
+	select {
+	case <-secondStarted:
+		t.Fatal("runnable of the second lease was started while the first one holds the lease")
+	case <-time.After(500 * time.Millisecond):
+	}
+
+	firstCancel()
+	if err := <-firstDone; err != nil {
+		t.Errorf("first lease returned an error after its context was cancelled: %v", err)
+	}
+	select {
+	case <-secondStarted:
+	case <-time.After(5 * time.Second):
+		t.Fatal("runnable of the second lease was not started after the first one released it")
+	}
+	secondCancel()
+	if err := <-secondDone; err != nil {
+		t.Errorf("second lease returned an error after its context was cancelled: %v", err)
+	}
+}
+
+func TestLeaseRunnableError(t *testing.T) {
+	lease := testLease(t, fake.NewSimpleClientset(), "identity")
+	stopped := make(chan struct{})
+	lease.add(manager.RunnableFunc(func(context.Context) error {
+		return errors.New("failed")
+	}))
+	lease.add(manager.RunnableFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+		close(stopped)
+		return nil
+	}))
+
+	done := make(chan error)
+	go func() { done <- lease.Start(context.Background()) }()
+	select {
+	case err := <-done:
+		if err == nil || err.Error() != "failed" {
+			t.Errorf("expected the error of the runnable, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("lease did not stop after a runnable failed")
+	}
+	select {
+	case <-stopped:
+	default:
+		t.Error("other runnable was not stopped")
+	}
+}
@@ -0,0 +1,209 @@
+package namespacereaper
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/api/nsttl"
+	controllerutil "github.com/openshift/ci-tools/pkg/controller/util"
+)
+
+const (
+	ControllerName = "namespace_reaper"
+
+	DefaultMaxConcurrentReconciles = 10
+
+	// idleRecheckInterval is how long we wait before checking again if a namespace
+	// whose soft TTL expired still has active pods
+	idleRecheckInterval = 5 * time.Minute
+)
+
+const (
+	reasonSoftDelete = "soft_delete"
+	reasonHardTTL    = "hard_ttl"
+	reasonSoftTTL    = "soft_ttl"
+)
+
+var reclaimedNamespacesCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "namespace_reaper_reclaimed_namespaces_total",
+	Help: "The number of namespaces the namespace reaper deleted",
+}, []string{"cluster", "reason"})
+
+func init() {
+	metrics.Registry.MustRegister(reclaimedNamespacesCounter)
+}
+
+// AddToManager adds a controller that deletes the namespaces created by ci-operator once they
+// are past their TTL or were marked for deletion.
+func AddToManager(clusterName string, mgr manager.Manager, maxConcurrentReconciles int) error {
+	r := &reconciler{
+		client:    mgr.GetClient(),
+		apiReader: mgr.GetAPIReader(),
+		cluster:   clusterName,
+		log:       logrus.WithField("controller", ControllerName).WithField("cluster", clusterName),
+		now:       time.Now,
+	}
+	c, err := controller.New(fmt.Sprintf("%s_%s", ControllerName, clusterName), mgr, controller.Options{
+		Reconciler:              controllerutil.TraceReconciler(ControllerName, r),
+		MaxConcurrentReconciles: maxConcurrentReconciles,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to construct controller: %w", err)
+	}
+
+	if err := c.Watch(&source.Kind{Type: &corev1.Namespace{}}, &handler.EnqueueRequestForObject{}, predicate.NewPredicateFuncs(isCIOperatorNamespace)); err != nil {
+		return fmt.Errorf("failed to construct watch for Namespaces: %w", err)
+	}
+
+	return nil
+}
+
+// isCIOperatorNamespace returns true for namespaces that were set up by ci-operator
+func isCIOperatorNamespace(o ctrlruntimeclient.Object) bool {
+	return o.GetLabels()[api.AutoScalePodsLabel] == "true"
+}
+
+type reconciler struct {
+	client ctrlruntimeclient.Client
+	// apiReader is used to list pods, as caching all pods of a build cluster is expensive
+	apiReader ctrlruntimeclient.Reader
+	cluster   string
+	log       *logrus.Entry
+	// Allow faking time for tests
+	now func() time.Time
+}
+
+func (r *reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	log := controllerutil.LoggerForRequest(r.log, req)
+	startTime := time.Now()
+	res, err := r.reconcile(ctx, log, req)
+	log = log.WithField("duration", time.Since(startTime))
+	if err != nil && !apierrors.IsConflict(err) {
+		log.WithError(err).Error("Reconciliation failed")
+	} else {
+		log.Debug("Finished reconciliation")
+	}
+	return res, controllerutil.SwallowIfTerminal(err)
+}
+
+func (r *reconciler) reconcile(ctx context.Context, log *logrus.Entry, req reconcile.Request) (reconcile.Result, error) {
+	ns := &corev1.Namespace{}
+	if err := r.client.Get(ctx, req.NamespacedName, ns); err != nil {
+		if apierrors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, fmt.Errorf("failed to get namespace %s: %w", req.Name, err)
+	}
+	if !isCIOperatorNamespace(ns) || ns.DeletionTimestamp != nil {
+		return reconcile.Result{}, nil
+	}
+
+	now := r.now()
+	reason, requeueAfter, err := expired(ns, now)
+	if err != nil {
+		return reconcile.Result{}, controllerutil.TerminalError(err)
+	}
+	if reason == reasonSoftTTL {
+		active, err := r.hasActivePods(ctx, ns.Name)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		if active {
+			log.Debug("Soft TTL expired, but there are still active pods")
+			reason = ""
+			if requeueAfter == 0 || requeueAfter > idleRecheckInterval {
+				requeueAfter = idleRecheckInterval
+			}
+		}
+	}
+	if reason == "" {
+		return reconcile.Result{RequeueAfter: requeueAfter}, nil
+	}
+
+	log.WithField("reason", reason).Info("Deleting namespace")
+	if err := r.client.Delete(ctx, ns, ctrlruntimeclient.Preconditions{UID: &ns.UID}); err != nil {
+		if apierrors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, fmt.Errorf("failed to delete namespace %s: %w", ns.Name, err)
+	}
+	reclaimedNamespacesCounter.WithLabelValues(r.cluster, reason).Inc()
+	return reconcile.Result{}, nil
+}
+
+// expired returns the reason for which the namespace is expired. If it is not, the reason
+// is empty and the returned duration is the time until it expires, if it has a TTL.
+func expired(ns *corev1.Namespace, now time.Time) (string, time.Duration, error) {
+	if raw, softDeleted := ns.Annotations[api.ReleaseAnnotationSoftDelete]; softDeleted {
+		// ci-operator sets a time in the future to retain namespaces of failed
+		// tests, which takes precedence over their TTLs
+		if retainUntil, err := time.Parse(time.RFC3339, raw); err == nil && retainUntil.After(now) {
+			return "", retainUntil.Sub(now), nil
+		}
+		return reasonSoftDelete, 0, nil
+	}
+
+	lastActive := ns.CreationTimestamp.Time
+	if raw, ok := ns.Annotations[nsttl.AnnotationNamespaceLastActive]; ok {
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return "", 0, fmt.Errorf("failed to parse %s annotation %q: %w", nsttl.AnnotationNamespaceLastActive, raw, err)
+		}
+		lastActive = parsed
+	}
+
+	var requeueAfter time.Duration
+	for _, ttl := range []struct {
+		annotation string
+		reason     string
+	}{
+		{annotation: nsttl.AnnotationCleanupDurationTTL, reason: reasonHardTTL},
+		{annotation: nsttl.AnnotationIdleCleanupDurationTTL, reason: reasonSoftTTL},
+	} {
+		raw, ok := ns.Annotations[ttl.annotation]
+		if !ok {
+			continue
+		}
+		duration, err := time.ParseDuration(raw)
+		if err != nil {
+			return "", 0, fmt.Errorf("failed to parse %s annotation %q: %w", ttl.annotation, raw, err)
+		}
+		remaining := lastActive.Add(duration).Sub(now)
+		if remaining <= 0 {
+			return ttl.reason, 0, nil
+		}
+		if requeueAfter == 0 || remaining < requeueAfter {
+			requeueAfter = remaining
+		}
+	}
+	return "", requeueAfter, nil
+}
+
+func (r *reconciler) hasActivePods(ctx context.Context, namespace string) (bool, error) {
+	pods := &corev1.PodList{}
+	if err := r.apiReader.List(ctx, pods, ctrlruntimeclient.InNamespace(namespace)); err != nil {
+		return false, fmt.Errorf("failed to list pods in namespace %s: %w", namespace, err)
+	}
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != corev1.PodSucceeded && pod.Status.Phase != corev1.PodFailed {
+			return true, nil
+		}
+	}
+	return false, nil
+}
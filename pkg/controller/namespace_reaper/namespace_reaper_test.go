@@ -0,0 +1,157 @@
+package namespacereaper
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/api/nsttl"
+)
+
+func TestReconcile(t *testing.T) {
+	now := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+	namespace := func(annotations map[string]string, mods ...func(*corev1.Namespace)) *corev1.Namespace {
+		ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:              "ci-op-1234",
+			Labels:            map[string]string{api.AutoScalePodsLabel: "true"},
+			Annotations:       annotations,
+			CreationTimestamp: metav1.NewTime(now.Add(-2 * time.Hour)),
+		}}
+		for _, mod := range mods {
+			mod(ns)
+		}
+		return ns
+	}
+	pod := func(phase corev1.PodPhase) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ci-op-1234", Name: "pod"},
+			Status:     corev1.PodStatus{Phase: phase},
+		}
+	}
+
+	testCases := []struct {
+		name            string
+		objects         []runtime.Object
+		expectedResult  reconcile.Result
+		expectedDeleted bool
+	}{
+		{
+			name:            "namespace not found",
+			expectedDeleted: true,
+		},
+		{
+			name: "namespace not created by ci-operator is ignored",
+			objects: []runtime.Object{namespace(map[string]string{api.ReleaseAnnotationSoftDelete: "true"}, func(ns *corev1.Namespace) {
+				ns.Labels = nil
+			})},
+		},
+		{
+			name:            "soft deleted namespace is deleted",
+			objects:         []runtime.Object{namespace(map[string]string{api.ReleaseAnnotationSoftDelete: "2021-05-01T11:00:00Z"})},
+			expectedDeleted: true,
+		},
+		{
+			name: "namespace soft deleted in the future is retained until then, regardless of its TTLs",
+			objects: []runtime.Object{namespace(map[string]string{
+				api.ReleaseAnnotationSoftDelete:    "2021-05-02T12:00:00Z",
+				nsttl.AnnotationCleanupDurationTTL: "1h",
+			})},
+			expectedResult: reconcile.Result{RequeueAfter: 24 * time.Hour},
+		},
+		{
+			name: "namespace without TTL is kept",
+			objects: []runtime.Object{namespace(map[string]string{
+				nsttl.AnnotationNamespaceLastActive: "2021-05-01T11:00:00Z",
+			})},
+		},
+		{
+			name: "namespace past its hard TTL since creation is deleted",
+			objects: []runtime.Object{namespace(map[string]string{
+				nsttl.AnnotationCleanupDurationTTL: "1h",
+			})},
+			expectedDeleted: true,
+		},
+		{
+			name: "namespace past its hard TTL since last activity is deleted",
+			objects: []runtime.Object{namespace(map[string]string{
+				nsttl.AnnotationNamespaceLastActive: "2021-05-01T11:30:00Z",
+				nsttl.AnnotationCleanupDurationTTL:  "20m",
+			}), pod(corev1.PodRunning)},
+			expectedDeleted: true,
+		},
+		{
+			name: "namespace within its TTLs is requeued until the earliest expires",
+			objects: []runtime.Object{namespace(map[string]string{
+				nsttl.AnnotationNamespaceLastActive:    "2021-05-01T11:30:00Z",
+				nsttl.AnnotationCleanupDurationTTL:     "12h",
+				nsttl.AnnotationIdleCleanupDurationTTL: "1h",
+			})},
+			expectedResult: reconcile.Result{RequeueAfter: 30 * time.Minute},
+		},
+		{
+			name: "idle namespace past its soft TTL is deleted",
+			objects: []runtime.Object{namespace(map[string]string{
+				nsttl.AnnotationNamespaceLastActive:    "2021-05-01T10:30:00Z",
+				nsttl.AnnotationIdleCleanupDurationTTL: "1h",
+			}), pod(corev1.PodSucceeded)},
+			expectedDeleted: true,
+		},
+		{
+			name: "namespace past its soft TTL with active pods is rechecked later",
+			objects: []runtime.Object{namespace(map[string]string{
+				nsttl.AnnotationNamespaceLastActive:    "2021-05-01T10:30:00Z",
+				nsttl.AnnotationIdleCleanupDurationTTL: "1h",
+			}), pod(corev1.PodRunning)},
+			expectedResult: reconcile.Result{RequeueAfter: idleRecheckInterval},
+		},
+		{
+			name: "namespace with an invalid TTL is kept",
+			objects: []runtime.Object{namespace(map[string]string{
+				nsttl.AnnotationCleanupDurationTTL: "forever",
+			})},
+		},
+		{
+			name: "namespace that is already being deleted is ignored",
+			objects: []runtime.Object{namespace(map[string]string{api.ReleaseAnnotationSoftDelete: "true"}, func(ns *corev1.Namespace) {
+				ns.DeletionTimestamp = &metav1.Time{Time: now}
+			})},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := fakectrlruntimeclient.NewFakeClient(tc.objects...)
+			r := &reconciler{
+				client:    client,
+				apiReader: client,
+				cluster:   "build01",
+				log:       logrus.NewEntry(logrus.StandardLogger()),
+				now:       func() time.Time { return now },
+			}
+			request := reconcile.Request{NamespacedName: types.NamespacedName{Name: "ci-op-1234"}}
+			result, err := r.Reconcile(context.Background(), request)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expectedResult, result); diff != "" {
+				t.Errorf("result differs from expected: %s", diff)
+			}
+			err = client.Get(context.Background(), request.NamespacedName, &corev1.Namespace{})
+			if deleted := apierrors.IsNotFound(err); deleted != tc.expectedDeleted {
+				t.Errorf("expected namespace to be deleted: %t, was deleted: %t (err: %v)", tc.expectedDeleted, deleted, err)
+			}
+		})
+	}
+}
@@ -27,6 +27,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	cioperatorapi "github.com/openshift/ci-tools/pkg/api"
+	controllerutil "github.com/openshift/ci-tools/pkg/controller/util"
 )
 
 // OrgRepoBranchCommit represents a GitHub commit
@@ -40,11 +41,11 @@ type OrgRepoBranchCommit struct {
 // Enqueuer allows the caller to Enqueue an OrgRepoBranchCommit
 type Enqueuer func(OrgRepoBranchCommit)
 
-const controllerName = "promotion_job_creator"
+const ControllerName = "promotion_job_creator"
 
 func AddToManager(mgr controllerruntime.Manager, config config.Getter, dryRun bool) (Enqueuer, error) {
 	createdJobsCounter := prometheus.NewCounterVec(prometheus.CounterOpts{
-		Namespace: controllerName,
+		Namespace: ControllerName,
 		Name:      "prowjobs_created",
 		Help:      "The number of prowjobs the controller created",
 	}, []string{"org", "repo", "branch"})
@@ -52,18 +53,18 @@ func AddToManager(mgr controllerruntime.Manager, config config.Getter, dryRun bo
 		return nil, fmt.Errorf("failed to register createdJobsCounter metric: %w", err)
 	}
 
-	ctrl, err := controller.New(controllerName, mgr, controller.Options{
+	ctrl, err := controller.New(ControllerName, mgr, controller.Options{
 		MaxConcurrentReconciles: 10,
-		Reconciler: &reconciler{
-			log:    logrus.WithField("controller", controllerName),
+		Reconciler: controllerutil.TraceReconciler(ControllerName, &reconciler{
+			log:    logrus.WithField("controller", ControllerName),
 			config: config,
 			client: mgr.GetClient(),
 			createdProwJobLabels: map[string]string{
-				"openshift.io/created-by": controllerName,
+				"openshift.io/created-by": ControllerName,
 			},
 			createdJobsCounter: createdJobsCounter,
 			dryRun:             dryRun,
-		},
+		}),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to construct controller: %w", err)
@@ -121,10 +122,11 @@ type reconciler struct {
 }
 
 func (r *reconciler) Reconcile(ctx context.Context, request controllerruntime.Request) (controllerruntime.Result, error) {
-	log := r.log.WithField("request", request.String())
+	log := controllerutil.LoggerForRequest(r.log, request)
+	startTime := time.Now()
 	err := r.reconcile(ctx, log, request)
 	if err != nil {
-		log.WithError(err).Error("Reconciliation failed")
+		log.WithField("duration", time.Since(startTime)).WithError(err).Error("Reconciliation failed")
 	}
 	return reconcile.Result{}, err
 }
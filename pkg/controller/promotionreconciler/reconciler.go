@@ -5,16 +5,23 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/label"
 
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/test-infra/prow/config"
 	"k8s.io/test-infra/prow/github"
 	"sigs.k8s.io/controller-runtime"
 	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
@@ -40,10 +47,20 @@ type Options struct {
 	// that contains our imageRegistry. This cluster is
 	// most likely not the one the normal manager talks to.
 	RegistryManager controllerruntime.Manager
+	// MaxConcurrentReconciles is the number of workers. Defaults to DefaultMaxConcurrentReconciles.
+	MaxConcurrentReconciles int
+	// AnnotateOnlyNamespaces are namespaces in which outdated ImageStreamTags are not rebuilt. Instead,
+	// they get annotated with the current HEAD and the time since when they are outdated, which is
+	// also exposed as metrics. This allows to observe the controller in a namespace before enabling it.
+	AnnotateOnlyNamespaces sets.String
 }
 
 const ControllerName = "promotionreconciler"
 
+// We currently have 50k ImageStreamTags in the OCP namespace and need to periodically reconcile all of them,
+// so don't be stingy with the workers
+const DefaultMaxConcurrentReconciles = 100
+
 func AddToManager(mgr controllerruntime.Manager, opts Options) error {
 	// Pre-Allocate the Image informer rather than letting it allocate on demand, because
 	// starting the watch takes very long (~2 minutes) and having that delay added to our
@@ -52,7 +69,7 @@ func AddToManager(mgr controllerruntime.Manager, opts Options) error {
 		return fmt.Errorf("failed to get informer for image: %w", err)
 	}
 
-	if err := opts.CIOperatorConfigAgent.AddIndex(configIndexName, configIndexFn); err != nil {
+	if err := opts.CIOperatorConfigAgent.AddIndex(configIndexName, release.PromotionIndexKeys); err != nil {
 		return fmt.Errorf("failed to add indexer to config-agent: %w", err)
 	}
 
@@ -61,6 +78,16 @@ func AddToManager(mgr controllerruntime.Manager, opts Options) error {
 		return fmt.Errorf("failed to construct prowjobreconciler: %w", err)
 	}
 
+	maxConcurrentReconciles := opts.MaxConcurrentReconciles
+	if maxConcurrentReconciles == 0 {
+		maxConcurrentReconciles = DefaultMaxConcurrentReconciles
+	}
+
+	staleness := newStalenessTracker()
+	if err := staleness.register(metrics.Registry); err != nil {
+		return err
+	}
+
 	log := logrus.WithField("controller", ControllerName)
 	r := &reconciler{
 		log:    log,
@@ -70,12 +97,15 @@ func AddToManager(mgr controllerruntime.Manager, opts Options) error {
 		},
 		gitHubClient: opts.GitHubClient,
 		enqueueJob:   prowJobEnqueuer,
+		recorder:     controllerutil.EventRecorder(opts.RegistryManager, ControllerName, opts.DryRun),
+
+		annotateOnlyNamespaces: opts.AnnotateOnlyNamespaces,
+		staleness:              staleness,
+		now:                    time.Now,
 	}
 	c, err := controller.New(ControllerName, opts.RegistryManager, controller.Options{
-		Reconciler: r,
-		// We currently have 50k ImageStreamTags in the OCP namespace and need to periodically reconcile all of them,
-		// so don't be stingy with the workers
-		MaxConcurrentReconciles: 100,
+		Reconciler:              controllerutil.TraceReconciler(ControllerName, r),
+		MaxConcurrentReconciles: maxConcurrentReconciles,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to construct controller: %w", err)
@@ -106,10 +136,15 @@ type reconciler struct {
 	releaseBuildConfigs ciOperatorConfigGetter
 	gitHubClient        githubClient
 	enqueueJob          prowjobreconciler.Enqueuer
+	recorder            record.EventRecorder
+
+	annotateOnlyNamespaces sets.String
+	staleness              *stalenessTracker
+	now                    func() time.Time
 }
 
 func (r *reconciler) Reconcile(ctx context.Context, req controllerruntime.Request) (controllerruntime.Result, error) {
-	log := r.log.WithField("name", req.Name).WithField("namespace", req.Namespace)
+	log := controllerutil.LoggerForRequest(r.log, req)
 	log.Trace("Starting reconciliation")
 	startTime := time.Now()
 	defer func() { log.WithField("duration", time.Since(startTime)).Trace("Finished reconciliation") }()
@@ -156,20 +191,30 @@ func (r *reconciler) reconcile(ctx context.Context, req controllerruntime.Reques
 	}
 	log = log.WithField("istCommit", istCommit)
 
-	currentHEAD, found, err := r.currentHEADForBranch(ciOPConfig.Metadata, log)
+	currentHEAD, found, err := r.currentHEADForBranch(ctx, ciOPConfig.Metadata, log)
 	if err != nil {
 		return fmt.Errorf("failed to get current git head for imageStreamTag: %w", err)
 	}
 	if !found {
 		return controllerutil.TerminalError(fmt.Errorf("got 404 for %s/%s/%s from github, this likely means the repo or branch got deleted or we are not allowed to access it", ciOPConfig.Metadata.Org, ciOPConfig.Metadata.Repo, ciOPConfig.Metadata.Branch))
 	}
+	annotateOnly := r.annotateOnlyNamespaces.Has(ist.Namespace)
 	// ImageStreamTag is current, nothing to do
 	if currentHEAD == istCommit {
+		if annotateOnly {
+			return r.clearStale(ctx, ist)
+		}
 		return nil
 	}
 	log = log.WithField("currentHEAD", currentHEAD)
 
+	if annotateOnly {
+		return r.annotateStale(ctx, ist, currentHEAD, log)
+	}
+
 	log.Info("Requesting prowjob creation")
+	r.recorder.Eventf(imageStreamFor(ist), corev1.EventTypeNormal, "RebuildEnqueued", "Tag %s was built from commit %s, but %s/%s@%s is at commit %s. Enqueued a job to build and promote it.",
+		ist.Name, istCommit, ciOPConfig.Metadata.Org, ciOPConfig.Metadata.Repo, ciOPConfig.Metadata.Branch, currentHEAD)
 	r.enqueueJob(prowjobreconciler.OrgRepoBranchCommit{
 		Org:    ciOPConfig.Metadata.Org,
 		Repo:   ciOPConfig.Metadata.Repo,
@@ -195,6 +240,16 @@ func (r *reconciler) promotionConfig(ist *imagev1.ImageStreamTag) (*cioperatorap
 	}
 }
 
+// imageStreamFor returns the ImageStream the ImageStreamTag belongs to, so events show up
+// when describing it. ImageStreamTags share the UID with their ImageStream.
+func imageStreamFor(ist *imagev1.ImageStreamTag) *imagev1.ImageStream {
+	return &imagev1.ImageStream{ObjectMeta: metav1.ObjectMeta{
+		Namespace: ist.Namespace,
+		Name:      strings.Split(ist.Name, ":")[0],
+		UID:       ist.UID,
+	}}
+}
+
 func commitForIST(ist *imagev1.ImageStreamTag) (string, error) {
 	metadata := &docker10.DockerImage{}
 	if err := json.Unmarshal(ist.Image.DockerImageMetadata.Raw, metadata); err != nil {
@@ -209,10 +264,13 @@ func commitForIST(ist *imagev1.ImageStreamTag) (string, error) {
 	return commit, nil
 }
 
-func (r *reconciler) currentHEADForBranch(metadata cioperatorapi.Metadata, log *logrus.Entry) (string, bool, error) {
+func (r *reconciler) currentHEADForBranch(ctx context.Context, metadata cioperatorapi.Metadata, log *logrus.Entry) (string, bool, error) {
+	// The GitHub client does not take a context, so we can only trace the call from the outside
+	_, span := controllerutil.StartSpan(ctx, "GitHub GetRef", label.String("org", metadata.Org), label.String("repo", metadata.Repo), label.String("branch", metadata.Branch))
 	// We attempted for some time to use the gitClient for this, but we do so many reconciliations that
 	// it results in a massive performance issues that can easely kill the developers laptop.
 	ref, err := r.gitHubClient.GetRef(metadata.Org, metadata.Repo, "heads/"+metadata.Branch)
+	controllerutil.EndSpan(ctx, span, err)
 	if err != nil {
 		if github.IsNotFound(err) {
 			return "", false, nil
@@ -226,15 +284,7 @@ func (r *reconciler) currentHEADForBranch(metadata cioperatorapi.Metadata, log *
 	return ref, true, nil
 }
 
-const configIndexName = "release-build-config-by-image-stream-tag"
-
-func configIndexFn(in cioperatorapi.ReleaseBuildConfiguration) []string {
-	var result []string
-	for _, istRef := range release.PromotedTags(&in) {
-		result = append(result, istRef.ISTagName())
-	}
-	return result
-}
+const configIndexName = release.PromotionIndexName
 
 func configIndexKeyForIST(ist *imagev1.ImageStreamTag) string {
 	return ist.Namespace + "/" + ist.Name
@@ -6,14 +6,18 @@ import (
 	"fmt"
 	"io/ioutil"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/sirupsen/logrus"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/test-infra/prow/github"
 	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -95,6 +99,7 @@ func TestReconcile(t *testing.T) {
 		githubClient      func(owner, repo, ref string) (string, error)
 		promotionDisabled bool
 		verify            func(error, *prowjobreconciler.OrgRepoBranchCommit) error
+		expectedEvents    []string
 	}{
 		{
 			name:         "404 getting commit for IST returns terminal error",
@@ -166,6 +171,7 @@ func TestReconcile(t *testing.T) {
 				}
 				return nil
 			},
+			expectedEvents: []string{"Normal RebuildEnqueued Tag name:tag was built from commit ist-commit, but ci-op-org/ci-op-repo@ci-op-branch is at commit newer. Enqueued a job to build and promote it."},
 		},
 	}
 
@@ -288,6 +294,7 @@ func TestReconcile(t *testing.T) {
 			}
 
 			var req *prowjobreconciler.OrgRepoBranchCommit
+			recorder := record.NewFakeRecorder(10)
 
 			r := &reconciler{
 				log:    logrus.NewEntry(logrus.New()),
@@ -310,6 +317,7 @@ func TestReconcile(t *testing.T) {
 				},
 				gitHubClient: fakeGithubClient{getGef: tc.githubClient},
 				enqueueJob:   func(orbc prowjobreconciler.OrgRepoBranchCommit) { req = &orbc },
+				recorder:     recorder,
 			}
 
 			err := r.reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{
@@ -320,6 +328,111 @@ func TestReconcile(t *testing.T) {
 			if err := tc.verify(err, req); err != nil {
 				t.Fatal(err)
 			}
+			close(recorder.Events)
+			var events []string
+			for event := range recorder.Events {
+				events = append(events, event)
+			}
+			if diff := cmp.Diff(tc.expectedEvents, events); diff != "" {
+				t.Errorf("events differ from expected: %s", diff)
+			}
+		})
+	}
+}
+
+func TestReconcileAnnotateOnly(t *testing.T) {
+	const istCommit = "96d6c74347445e0687267165a1a7d8f2c98dd3a1"
+	now := time.Date(2021, 5, 10, 12, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		name                string
+		annotations         map[string]string
+		currentHEAD         string
+		expectedAnnotations map[string]string
+		expectedStaleTags   float64
+	}{
+		{
+			name:                "IST up to date, nothing to do",
+			currentHEAD:         istCommit,
+			expectedAnnotations: map[string]string{"other": "annotation"},
+		},
+		{
+			name:                "IST up to date again, annotations are removed",
+			annotations:         map[string]string{StaleHEADAnnotation: "newer", StaleSinceAnnotation: "2021-05-09T12:00:00Z"},
+			currentHEAD:         istCommit,
+			expectedAnnotations: map[string]string{"other": "annotation"},
+		},
+		{
+			name:                "IST outdated, annotated",
+			currentHEAD:         "newer",
+			expectedAnnotations: map[string]string{"other": "annotation", StaleHEADAnnotation: "newer", StaleSinceAnnotation: "2021-05-10T12:00:00Z"},
+			expectedStaleTags:   1,
+		},
+		{
+			name:                "IST still outdated with newer HEAD, since is kept",
+			annotations:         map[string]string{StaleHEADAnnotation: "newer", StaleSinceAnnotation: "2021-05-09T12:00:00Z"},
+			currentHEAD:         "newest",
+			expectedAnnotations: map[string]string{"other": "annotation", StaleHEADAnnotation: "newest", StaleSinceAnnotation: "2021-05-09T12:00:00Z"},
+			expectedStaleTags:   1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rawImageStreamTag, err := ioutil.ReadFile("testdata/imagestreamtag.yaml")
+			if err != nil {
+				t.Fatalf("failed to read imagestreamtag fixture: %v", err)
+			}
+			ist := &imagev1.ImageStreamTag{}
+			if err := yaml.Unmarshal(rawImageStreamTag, ist); err != nil {
+				t.Fatalf("failed to unmarshal imagestreamTag: %v", err)
+			}
+			ist.ResourceVersion = ""
+			ist.Annotations = map[string]string{"other": "annotation"}
+			for key, value := range tc.annotations {
+				ist.Annotations[key] = value
+			}
+			name := types.NamespacedName{Namespace: ist.Namespace, Name: ist.Name}
+
+			client := fakectrlruntimeclient.NewFakeClient(ist)
+			staleness := newStalenessTracker()
+			r := &reconciler{
+				log:    logrus.NewEntry(logrus.New()),
+				client: client,
+				releaseBuildConfigs: func(_ string) ([]*cioperatorapi.ReleaseBuildConfiguration, error) {
+					return []*cioperatorapi.ReleaseBuildConfiguration{{
+						Metadata:               cioperatorapi.Metadata{Org: "org", Repo: "repo", Branch: "branch"},
+						PromotionConfiguration: &cioperatorapi.PromotionConfiguration{Namespace: "ocp", Name: "4.5"},
+						Images:                 []cioperatorapi.ProjectDirectoryImageBuildStepConfiguration{{To: "cluster-openshift-apiserver-operator"}},
+					}}, nil
+				},
+				gitHubClient: fakeGithubClient{getGef: func(_, _, _ string) (string, error) { return tc.currentHEAD, nil }},
+				enqueueJob: func(orbc prowjobreconciler.OrgRepoBranchCommit) {
+					t.Errorf("expected no prowjob to be enqueued, got %v", orbc)
+				},
+				recorder:               record.NewFakeRecorder(10),
+				annotateOnlyNamespaces: sets.NewString("ocp"),
+				staleness:              staleness,
+				now:                    func() time.Time { return now },
+			}
+
+			if err := r.reconcile(context.Background(), reconcile.Request{NamespacedName: name}, r.log); err != nil {
+				t.Fatalf("reconciliation failed: %v", err)
+			}
+
+			actual := &imagev1.ImageStreamTag{}
+			if err := client.Get(context.Background(), name, actual); err != nil {
+				t.Fatalf("failed to get imagestreamtag: %v", err)
+			}
+			if diff := cmp.Diff(tc.expectedAnnotations, actual.Annotations); diff != "" {
+				t.Errorf("annotations differ from expected: %s", diff)
+			}
+			metric := &dto.Metric{}
+			if err := staleness.staleTags.WithLabelValues("ocp").Write(metric); err != nil {
+				t.Fatalf("failed to read stale tags metric: %v", err)
+			}
+			if actual := metric.GetGauge().GetValue(); actual != tc.expectedStaleTags {
+				t.Errorf("expected %v stale tags, got %v", tc.expectedStaleTags, actual)
+			}
 		})
 	}
 }
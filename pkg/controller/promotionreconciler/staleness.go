@@ -0,0 +1,144 @@
+package promotionreconciler
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/apimachinery/pkg/types"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	imagev1 "github.com/openshift/api/image/v1"
+)
+
+const (
+	// StaleHEADAnnotation is set on ImageStreamTags in annotate-only namespaces that were not built
+	// from the current HEAD of their branch and holds that HEAD
+	StaleHEADAnnotation = "ci.openshift.io/stale.head"
+	// StaleSinceAnnotation holds the time at which the ImageStreamTag was first found to be stale
+	StaleSinceAnnotation = "ci.openshift.io/stale.since"
+)
+
+// stalenessTracker keeps track of the stale ImageStreamTags in annotate-only namespaces
+// and exposes them as metrics
+type stalenessTracker struct {
+	lock sync.Mutex
+	// staleSince is keyed by namespace and ImageStreamTag name
+	staleSince map[string]map[string]time.Time
+
+	staleTags        *prometheus.GaugeVec
+	oldestStaleSince *prometheus.GaugeVec
+}
+
+func newStalenessTracker() *stalenessTracker {
+	return &stalenessTracker{
+		staleSince: map[string]map[string]time.Time{},
+		staleTags: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ControllerName,
+			Name:      "stale_imagestreamtags",
+			Help:      "The number of ImageStreamTags in annotate-only namespaces that were not built from the current HEAD of their branch",
+		}, []string{"namespace"}),
+		oldestStaleSince: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: ControllerName,
+			Name:      "oldest_stale_imagestreamtag_timestamp_seconds",
+			Help:      "The unix timestamp at which the longest stale ImageStreamTag in an annotate-only namespace was first found to be stale",
+		}, []string{"namespace"}),
+	}
+}
+
+func (t *stalenessTracker) register(registry prometheus.Registerer) error {
+	if err := registry.Register(t.staleTags); err != nil {
+		return fmt.Errorf("failed to register staleTags metric: %w", err)
+	}
+	if err := registry.Register(t.oldestStaleSince); err != nil {
+		return fmt.Errorf("failed to register oldestStaleSince metric: %w", err)
+	}
+	return nil
+}
+
+func (t *stalenessTracker) markStale(name types.NamespacedName, since time.Time) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.staleSince[name.Namespace] == nil {
+		t.staleSince[name.Namespace] = map[string]time.Time{}
+	}
+	t.staleSince[name.Namespace][name.Name] = since
+	t.updateMetrics(name.Namespace)
+}
+
+func (t *stalenessTracker) markCurrent(name types.NamespacedName) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if _, stale := t.staleSince[name.Namespace][name.Name]; !stale {
+		return
+	}
+	delete(t.staleSince[name.Namespace], name.Name)
+	t.updateMetrics(name.Namespace)
+}
+
+// updateMetrics must be called with the lock held
+func (t *stalenessTracker) updateMetrics(namespace string) {
+	var oldest time.Time
+	for _, since := range t.staleSince[namespace] {
+		if oldest.IsZero() || since.Before(oldest) {
+			oldest = since
+		}
+	}
+	t.staleTags.WithLabelValues(namespace).Set(float64(len(t.staleSince[namespace])))
+	if oldest.IsZero() {
+		t.oldestStaleSince.DeleteLabelValues(namespace)
+		return
+	}
+	t.oldestStaleSince.WithLabelValues(namespace).Set(float64(oldest.Unix()))
+}
+
+// annotateStale records on the ImageStreamTag that it is behind the current HEAD of its branch
+// instead of rebuilding it. The time at which it was first found to be stale is kept across
+// reconciliations, so the drift age can be determined from it.
+func (r *reconciler) annotateStale(ctx context.Context, ist *imagev1.ImageStreamTag, currentHEAD string, log *logrus.Entry) error {
+	since := r.now()
+	if raw, ok := ist.Annotations[StaleSinceAnnotation]; ok {
+		if parsed, err := time.Parse(time.RFC3339, raw); err == nil {
+			since = parsed
+		} else {
+			log.WithError(err).Debugf("Ignoring invalid %s annotation", StaleSinceAnnotation)
+		}
+	}
+	r.staleness.markStale(types.NamespacedName{Namespace: ist.Namespace, Name: ist.Name}, since)
+	log.WithField("staleSince", since).Debug("ImageStreamTag is stale")
+
+	if ist.Annotations[StaleHEADAnnotation] == currentHEAD && ist.Annotations[StaleSinceAnnotation] == since.Format(time.RFC3339) {
+		return nil
+	}
+	updated := ist.DeepCopy()
+	if updated.Annotations == nil {
+		updated.Annotations = map[string]string{}
+	}
+	updated.Annotations[StaleHEADAnnotation] = currentHEAD
+	updated.Annotations[StaleSinceAnnotation] = since.Format(time.RFC3339)
+	if err := r.client.Patch(ctx, updated, ctrlruntimeclient.MergeFrom(ist)); err != nil {
+		return fmt.Errorf("failed to annotate stale imageStreamTag: %w", err)
+	}
+	return nil
+}
+
+// clearStale removes the staleness annotations once the ImageStreamTag is current again
+func (r *reconciler) clearStale(ctx context.Context, ist *imagev1.ImageStreamTag) error {
+	r.staleness.markCurrent(types.NamespacedName{Namespace: ist.Namespace, Name: ist.Name})
+	_, hasHEAD := ist.Annotations[StaleHEADAnnotation]
+	_, hasSince := ist.Annotations[StaleSinceAnnotation]
+	if !hasHEAD && !hasSince {
+		return nil
+	}
+	updated := ist.DeepCopy()
+	delete(updated.Annotations, StaleHEADAnnotation)
+	delete(updated.Annotations, StaleSinceAnnotation)
+	if err := r.client.Patch(ctx, updated, ctrlruntimeclient.MergeFrom(ist)); err != nil {
+		return fmt.Errorf("failed to remove staleness annotations from imageStreamTag: %w", err)
+	}
+	return nil
+}
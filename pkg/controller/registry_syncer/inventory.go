@@ -0,0 +1,254 @@
+package registrysyncer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	imagev1 "github.com/openshift/api/image/v1"
+)
+
+// InventoryPath is the path under which the inventory is served
+const InventoryPath = "/api/v1/inventory"
+
+// ConflictWindow is the time within which different content arriving on different
+// clusters is considered a conflict: the newest content can not be told apart
+// reliably, so picking it would make the content of the tag flip-flop.
+const ConflictWindow = 10 * time.Minute
+
+var conflictGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "registry_syncer_conflicting_tags",
+	Help: "Whether a tag got different content on several clusters within the conflict window and needs to be resolved by a human",
+}, []string{"namespace", "imagestream", "tag"})
+
+func init() {
+	metrics.Registry.MustRegister(conflictGauge)
+}
+
+// TagState is the content of an ImageStreamTag on a single cluster
+type TagState struct {
+	// Digest is the digest of the image the tag currently points to
+	Digest string `json:"digest"`
+	// Created is the time the tag started pointing to the image
+	Created time.Time `json:"created"`
+}
+
+// TagInventory describes which cluster holds the newest content of a
+// synced ImageStreamTag and how far the other clusters are behind.
+type TagInventory struct {
+	Namespace   string `json:"namespace"`
+	ImageStream string `json:"imagestream"`
+	Tag         string `json:"tag"`
+	// SourceCluster is the cluster on which the tag got its current content most recently
+	SourceCluster string `json:"source_cluster"`
+	// Digest is the digest of the newest content
+	Digest string `json:"digest"`
+	// LastSync is the most recent time the newest content arrived on a cluster other
+	// than the source cluster. It is unset if no other cluster holds the newest content.
+	LastSync *time.Time `json:"last_sync,omitempty"`
+	// Clusters holds the content of the tag on every cluster that has it
+	Clusters map[string]TagState `json:"clusters"`
+	// Conflict is set when different content arrived on several clusters within the
+	// ConflictWindow. The source of such a tag is unknown and it must not be synced
+	// until a human resolved the conflict.
+	Conflict *TagConflict `json:"conflict,omitempty"`
+}
+
+// TagConflict describes a tag with a split-brain across clusters
+type TagConflict struct {
+	// Clusters are the clusters holding the conflicting content
+	Clusters []string `json:"clusters"`
+	// Digests are the conflicting digests
+	Digests []string `json:"digests"`
+}
+
+// Inventory determines the source of the current content of every tag of the given
+// ImageStreams, which are in namespace/name format, across all clusters.
+func Inventory(ctx context.Context, clients map[string]ctrlruntimeclient.Client, imageStreams sets.String) ([]TagInventory, error) {
+	clusters := sets.StringKeySet(clients).List()
+	var result []TagInventory
+	for _, imageStream := range imageStreams.List() {
+		slashSplit := strings.Split(imageStream, "/")
+		if len(slashSplit) != 2 {
+			return nil, fmt.Errorf("imagestream %s is not in namespace/name format", imageStream)
+		}
+		key := types.NamespacedName{Namespace: slashSplit[0], Name: slashSplit[1]}
+
+		states := map[string]map[string]TagState{}
+		for _, cluster := range clusters {
+			stream := &imagev1.ImageStream{}
+			if err := clients[cluster].Get(ctx, key, stream); err != nil {
+				if apierrors.IsNotFound(err) {
+					continue
+				}
+				return nil, fmt.Errorf("failed to get imagestream %s from cluster %s: %w", key, cluster, err)
+			}
+			for _, tag := range stream.Status.Tags {
+				if len(tag.Items) == 0 {
+					continue
+				}
+				if states[tag.Tag] == nil {
+					states[tag.Tag] = map[string]TagState{}
+				}
+				states[tag.Tag][cluster] = TagState{Digest: tag.Items[0].Image, Created: tag.Items[0].Created.Time}
+			}
+		}
+
+		var tags []string
+		for tag := range states {
+			tags = append(tags, tag)
+		}
+		sort.Strings(tags)
+		for _, tag := range tags {
+			inventory := inventoryFor(key, tag, states[tag])
+			if inventory.Conflict != nil {
+				logrus.WithFields(logrus.Fields{"imagestream": key.String(), "tag": tag, "clusters": inventory.Conflict.Clusters}).Warn("Tag has conflicting content across clusters")
+			}
+			recordConflict(inventory)
+			result = append(result, inventory)
+		}
+	}
+	return result, nil
+}
+
+// recordConflict exposes whether the tag of the inventory has a conflict in the
+// registry_syncer_conflicting_tags metric
+func recordConflict(inventory TagInventory) {
+	labels := prometheus.Labels{"namespace": inventory.Namespace, "imagestream": inventory.ImageStream, "tag": inventory.Tag}
+	if inventory.Conflict != nil {
+		conflictGauge.With(labels).Set(1)
+	} else {
+		conflictGauge.Delete(labels)
+	}
+}
+
+// conflictMessage describes the conflict of the tag for humans
+func conflictMessage(tag string, conflict *TagConflict) string {
+	return fmt.Sprintf("Tag %s got different content on the clusters %s within %s, it is not synced until the conflict is resolved: %s", tag, strings.Join(conflict.Clusters, ", "), ConflictWindow, strings.Join(conflict.Digests, ", "))
+}
+
+func inventoryFor(imageStream types.NamespacedName, tag string, states map[string]TagState) TagInventory {
+	inventory := TagInventory{Namespace: imageStream.Namespace, ImageStream: imageStream.Name, Tag: tag, Clusters: states}
+	var newest time.Time
+	for _, cluster := range sets.StringKeySet(states).List() {
+		if state := states[cluster]; inventory.SourceCluster == "" || state.Created.After(newest) {
+			inventory.SourceCluster, inventory.Digest, newest = cluster, state.Digest, state.Created
+		}
+	}
+	for cluster, state := range states {
+		if cluster == inventory.SourceCluster || state.Digest != inventory.Digest {
+			continue
+		}
+		if inventory.LastSync == nil || state.Created.After(*inventory.LastSync) {
+			created := state.Created
+			inventory.LastSync = &created
+		}
+	}
+	if conflict := conflictFor(states, inventory.Digest, newest); conflict != nil {
+		inventory.SourceCluster, inventory.Digest, inventory.LastSync = "", "", nil
+		inventory.Conflict = conflict
+	}
+	return inventory
+}
+
+// conflictFor returns the conflict if any cluster got content other than the
+// newest within the ConflictWindow before it
+func conflictFor(states map[string]TagState, newestDigest string, newest time.Time) *TagConflict {
+	clusters, digests := sets.NewString(), sets.NewString()
+	for cluster, state := range states {
+		if state.Digest != newestDigest && newest.Sub(state.Created) <= ConflictWindow {
+			clusters.Insert(cluster)
+			digests.Insert(state.Digest)
+		}
+	}
+	if len(clusters) == 0 {
+		return nil
+	}
+	for cluster, state := range states {
+		if state.Digest == newestDigest && newest.Sub(state.Created) <= ConflictWindow {
+			clusters.Insert(cluster)
+			digests.Insert(state.Digest)
+		}
+	}
+	return &TagConflict{Clusters: clusters.List(), Digests: digests.List()}
+}
+
+// InventoryHandler serves the inventory of the given ImageStreams as JSON. The
+// `imagestream` query parameter limits the response to a single ImageStream.
+func InventoryHandler(clients map[string]ctrlruntimeclient.Client, imageStreams sets.String) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, fmt.Sprintf("method %s is not allowed", r.Method), http.StatusMethodNotAllowed)
+			return
+		}
+		requested := imageStreams
+		if imageStream := r.URL.Query().Get("imagestream"); imageStream != "" {
+			if !imageStreams.Has(imageStream) {
+				http.Error(w, fmt.Sprintf("imagestream %s is not synced", imageStream), http.StatusNotFound)
+				return
+			}
+			requested = sets.NewString(imageStream)
+		}
+		inventory, err := Inventory(r.Context(), clients, requested)
+		if err != nil {
+			logrus.WithError(err).Error("Failed to assemble inventory")
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if inventory == nil {
+			inventory = []TagInventory{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(inventory); err != nil {
+			logrus.WithError(err).Error("Failed to write inventory")
+		}
+	})
+}
+
+// AddInventoryServer adds a server for the inventory to the manager. It runs on all
+// replicas, regardless of which one is the leader.
+func AddInventoryServer(mgr manager.Manager, address string, clients map[string]ctrlruntimeclient.Client, imageStreams sets.String) error {
+	mux := http.NewServeMux()
+	mux.Handle(InventoryPath, InventoryHandler(clients, imageStreams))
+	return mgr.Add(&inventoryServer{server: &http.Server{Addr: address, Handler: mux}})
+}
+
+type inventoryServer struct {
+	server *http.Server
+}
+
+func (s *inventoryServer) Start(ctx context.Context) error {
+	errs := make(chan error, 1)
+	go func() {
+		if err := s.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			errs <- err
+		}
+		close(errs)
+	}()
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return s.server.Shutdown(shutdownCtx)
+	}
+}
+
+func (*inventoryServer) NeedLeaderElection() bool {
+	return false
+}
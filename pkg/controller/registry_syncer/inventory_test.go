@@ -0,0 +1,222 @@
+package registrysyncer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	dto "github.com/prometheus/client_model/go"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	imagev1 "github.com/openshift/api/image/v1"
+)
+
+func init() {
+	if err := imagev1.AddToScheme(scheme.Scheme); err != nil {
+		panic(fmt.Sprintf("failed to register imagev1 scheme: %v", err))
+	}
+}
+
+func imageStream(namespace, name string, tags ...imagev1.NamedTagEventList) *imagev1.ImageStream {
+	return &imagev1.ImageStream{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Status:     imagev1.ImageStreamStatus{Tags: tags},
+	}
+}
+
+func tag(name, digest string, created time.Time) imagev1.NamedTagEventList {
+	return imagev1.NamedTagEventList{Tag: name, Items: []imagev1.TagEvent{{Image: digest, Created: metav1.NewTime(created)}}}
+}
+
+func TestInventory(t *testing.T) {
+	old := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	synced := old.Add(time.Hour)
+	newest := old.Add(2 * time.Hour)
+	clients := map[string]ctrlruntimeclient.Client{
+		"app.ci": fakectrlruntimeclient.NewFakeClient(
+			imageStream("ci", "clonerefs", tag("latest", "sha256:new", newest), tag("untagged", "", old)),
+			imageStream("ci", "unmanaged", tag("latest", "sha256:other", newest)),
+		),
+		"build01": fakectrlruntimeclient.NewFakeClient(
+			imageStream("ci", "clonerefs", tag("latest", "sha256:old", old)),
+		),
+		"build02": fakectrlruntimeclient.NewFakeClient(
+			imageStream("ci", "clonerefs", tag("latest", "sha256:new", synced), tag("only-here", "sha256:only", old)),
+		),
+		"build03": fakectrlruntimeclient.NewFakeClient(),
+	}
+	streams := sets.NewString("ci/clonerefs", "ci/missing")
+
+	expected := []TagInventory{
+		{
+			Namespace:     "ci",
+			ImageStream:   "clonerefs",
+			Tag:           "latest",
+			SourceCluster: "app.ci",
+			Digest:        "sha256:new",
+			LastSync:      &synced,
+			Clusters: map[string]TagState{
+				"app.ci":  {Digest: "sha256:new", Created: newest},
+				"build01": {Digest: "sha256:old", Created: old},
+				"build02": {Digest: "sha256:new", Created: synced},
+			},
+		},
+		{
+			Namespace:     "ci",
+			ImageStream:   "clonerefs",
+			Tag:           "only-here",
+			SourceCluster: "build02",
+			Digest:        "sha256:only",
+			Clusters: map[string]TagState{
+				"build02": {Digest: "sha256:only", Created: old},
+			},
+		},
+		{
+			Namespace:     "ci",
+			ImageStream:   "clonerefs",
+			Tag:           "untagged",
+			SourceCluster: "app.ci",
+			Clusters: map[string]TagState{
+				"app.ci": {Created: old},
+			},
+		},
+	}
+	actual, err := Inventory(context.Background(), clients, streams)
+	if err != nil {
+		t.Fatalf("failed to get inventory: %v", err)
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("inventory differs from expected: %s", diff)
+	}
+}
+
+func TestInventoryConflict(t *testing.T) {
+	created := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	clients := map[string]ctrlruntimeclient.Client{
+		"app.ci": fakectrlruntimeclient.NewFakeClient(
+			imageStream("ci", "clonerefs", tag("latest", "sha256:one", created.Add(2*time.Minute)), tag("resolved", "sha256:new", created.Add(ConflictWindow+time.Minute))),
+		),
+		"build01": fakectrlruntimeclient.NewFakeClient(
+			imageStream("ci", "clonerefs", tag("latest", "sha256:other", created), tag("resolved", "sha256:old", created)),
+		),
+		"build02": fakectrlruntimeclient.NewFakeClient(
+			imageStream("ci", "clonerefs", tag("latest", "sha256:one", created.Add(time.Minute))),
+		),
+	}
+
+	expected := []TagInventory{
+		{
+			Namespace:   "ci",
+			ImageStream: "clonerefs",
+			Tag:         "latest",
+			Clusters: map[string]TagState{
+				"app.ci":  {Digest: "sha256:one", Created: created.Add(2 * time.Minute)},
+				"build01": {Digest: "sha256:other", Created: created},
+				"build02": {Digest: "sha256:one", Created: created.Add(time.Minute)},
+			},
+			Conflict: &TagConflict{Clusters: []string{"app.ci", "build01", "build02"}, Digests: []string{"sha256:one", "sha256:other"}},
+		},
+		{
+			Namespace:     "ci",
+			ImageStream:   "clonerefs",
+			Tag:           "resolved",
+			SourceCluster: "app.ci",
+			Digest:        "sha256:new",
+			Clusters: map[string]TagState{
+				"app.ci":  {Digest: "sha256:new", Created: created.Add(ConflictWindow + time.Minute)},
+				"build01": {Digest: "sha256:old", Created: created},
+			},
+		},
+	}
+	actual, err := Inventory(context.Background(), clients, sets.NewString("ci/clonerefs"))
+	if err != nil {
+		t.Fatalf("failed to get inventory: %v", err)
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("inventory differs from expected: %s", diff)
+	}
+	metric := &dto.Metric{}
+	if err := conflictGauge.WithLabelValues("ci", "clonerefs", "latest").Write(metric); err != nil {
+		t.Fatalf("failed to get metric: %v", err)
+	}
+	if value := metric.GetGauge().GetValue(); value != 1 {
+		t.Errorf("expected the conflict to be exposed as metric, got %f", value)
+	}
+}
+
+func TestInventoryHandler(t *testing.T) {
+	created := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	clients := map[string]ctrlruntimeclient.Client{
+		"app.ci": fakectrlruntimeclient.NewFakeClient(
+			imageStream("ci", "clonerefs", tag("latest", "sha256:new", created)),
+			imageStream("ci", "entrypoint", tag("latest", "sha256:entrypoint", created)),
+		),
+	}
+	handler := InventoryHandler(clients, sets.NewString("ci/clonerefs", "ci/entrypoint"))
+
+	testCases := []struct {
+		name           string
+		method         string
+		query          string
+		expectedStatus int
+		expectedTags   []string
+	}{
+		{
+			name:           "all imagestreams",
+			method:         http.MethodGet,
+			expectedStatus: http.StatusOK,
+			expectedTags:   []string{"ci/clonerefs:latest", "ci/entrypoint:latest"},
+		},
+		{
+			name:           "single imagestream",
+			method:         http.MethodGet,
+			query:          "?imagestream=ci/entrypoint",
+			expectedStatus: http.StatusOK,
+			expectedTags:   []string{"ci/entrypoint:latest"},
+		},
+		{
+			name:           "imagestream that is not synced",
+			method:         http.MethodGet,
+			query:          "?imagestream=ci/other",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "wrong method",
+			method:         http.MethodPost,
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(tc.method, InventoryPath+tc.query, nil))
+			if recorder.Code != tc.expectedStatus {
+				t.Fatalf("expected status %d, got %d: %s", tc.expectedStatus, recorder.Code, recorder.Body.String())
+			}
+			if tc.expectedStatus != http.StatusOK {
+				return
+			}
+			var inventory []TagInventory
+			if err := json.Unmarshal(recorder.Body.Bytes(), &inventory); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+			var tags []string
+			for _, item := range inventory {
+				tags = append(tags, item.Namespace+"/"+item.ImageStream+":"+item.Tag)
+			}
+			if diff := cmp.Diff(tc.expectedTags, tags); diff != "" {
+				t.Errorf("tags differ from expected: %s", diff)
+			}
+		})
+	}
+}
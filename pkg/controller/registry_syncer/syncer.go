@@ -0,0 +1,330 @@
+package registrysyncer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/tools/record"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	imagev1 "github.com/openshift/api/image/v1"
+
+	controllerutil "github.com/openshift/ci-tools/pkg/controller/util"
+	"github.com/openshift/ci-tools/pkg/util/imagestreamtagmapper"
+)
+
+const ControllerName = "registry_syncer"
+
+// DefaultMaxConcurrentReconciles is the default number of tags synced at once
+const DefaultMaxConcurrentReconciles = 10
+
+// DefaultResyncInterval is the default interval in which all ImageStreams of
+// the resynced namespaces are listed and all their tags are enqueued
+const DefaultResyncInterval = 24 * time.Hour
+
+// ConflictAnnotationPrefix is the prefix of the annotation that describes the
+// conflict of a tag on its ImageStream, followed by the name of the tag
+const ConflictAnnotationPrefix = "conflict.registry-syncer.ci.openshift.io/"
+
+// AddToManager adds a controller that syncs the tags of the given ImageStreams, which are
+// in namespace/name format, to all clusters whenever they change on any cluster. Additionally,
+// all ImageStreams in the resyncNamespaces are listed on all clusters every resyncInterval and
+// all of their tags are synced. This catches up on events that got lost while the controller
+// was down and syncs ImageStreams of these namespaces that are not passed explicitly.
+func AddToManager(
+	mgr manager.Manager,
+	managers map[string]manager.Manager,
+	imageStreams sets.String,
+	resyncNamespaces sets.String,
+	resyncInterval time.Duration,
+	maxConcurrentReconciles int,
+	dryRun bool,
+) error {
+	log := logrus.WithField("controller", ControllerName)
+	r := &reconciler{log: log, clients: map[string]ctrlruntimeclient.Client{}, recorders: map[string]record.EventRecorder{}}
+	c, err := controller.New(ControllerName, mgr, controller.Options{
+		Reconciler:              controllerutil.TraceReconciler(ControllerName, r),
+		MaxConcurrentReconciles: maxConcurrentReconciles,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to construct controller: %w", err)
+	}
+
+	for cluster, clusterManager := range managers {
+		r.clients[cluster] = clusterManager.GetClient()
+		r.recorders[cluster] = controllerutil.EventRecorder(clusterManager, ControllerName, dryRun)
+		if err := c.Watch(
+			source.NewKindWithCache(&imagev1.ImageStream{}, clusterManager.GetCache()),
+			imagestreamtagmapper.New(imageStreamFilter(imageStreams)),
+		); err != nil {
+			return fmt.Errorf("failed to create watch for ImageStreams on cluster %s: %w", cluster, err)
+		}
+	}
+
+	if len(resyncNamespaces) > 0 {
+		resynced := make(chan event.GenericEvent)
+		if err := c.Watch(
+			&source.Channel{Source: resynced},
+			imagestreamtagmapper.New(func(in reconcile.Request) []reconcile.Request { return []reconcile.Request{in} }),
+		); err != nil {
+			return fmt.Errorf("failed to create watch for resynced ImageStreams: %w", err)
+		}
+		if err := mgr.Add(&resyncer{log: log, clients: r.clients, namespaces: resyncNamespaces, interval: resyncInterval, events: resynced}); err != nil {
+			return fmt.Errorf("failed to add resyncer: %w", err)
+		}
+	}
+
+	log.Info("Successfully added reconciler to manager")
+	return nil
+}
+
+func imageStreamFilter(imageStreams sets.String) func(reconcile.Request) []reconcile.Request {
+	return func(in reconcile.Request) []reconcile.Request {
+		if !imageStreams.Has(in.Namespace + "/" + strings.Split(in.Name, ":")[0]) {
+			return nil
+		}
+		return []reconcile.Request{in}
+	}
+}
+
+// resyncer lists all ImageStreams of the namespaces on all clusters every interval
+// and sends them to the controller, which enqueues all of their tags
+type resyncer struct {
+	log        *logrus.Entry
+	clients    map[string]ctrlruntimeclient.Client
+	namespaces sets.String
+	interval   time.Duration
+	events     chan<- event.GenericEvent
+}
+
+func (r *resyncer) Start(ctx context.Context) error {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		r.resync(ctx)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *resyncer) resync(ctx context.Context) {
+	for _, cluster := range sets.StringKeySet(r.clients).List() {
+		for _, namespace := range r.namespaces.List() {
+			log := r.log.WithFields(logrus.Fields{"cluster": cluster, "namespace": namespace})
+			streams := &imagev1.ImageStreamList{}
+			if err := r.clients[cluster].List(ctx, streams, ctrlruntimeclient.InNamespace(namespace)); err != nil {
+				log.WithError(err).Error("Failed to list imagestreams for the resync")
+				continue
+			}
+			log.WithField("imagestreams", len(streams.Items)).Debug("Resyncing namespace")
+			for i := range streams.Items {
+				select {
+				case r.events <- event.GenericEvent{Object: &streams.Items[i]}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}
+}
+
+type reconciler struct {
+	log     *logrus.Entry
+	clients map[string]ctrlruntimeclient.Client
+	// recorders record events on the ImageStreams of each cluster
+	recorders map[string]record.EventRecorder
+}
+
+func (r *reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	log := controllerutil.LoggerForRequest(r.log, req)
+	log.Info("Starting reconciliation")
+	startTime := time.Now()
+	err := r.reconcile(ctx, req, log)
+	log = log.WithField("duration", time.Since(startTime))
+	if err != nil && !apierrors.IsConflict(err) {
+		log.WithError(err).Error("Reconciliation failed")
+	} else {
+		log.Info("Finished reconciliation")
+	}
+	return reconcile.Result{}, controllerutil.SwallowIfTerminal(err)
+}
+
+func (r *reconciler) reconcile(ctx context.Context, req reconcile.Request, log *logrus.Entry) error {
+	imageStreamNameAndTag := strings.Split(req.Name, ":")
+	if n := len(imageStreamNameAndTag); n != 2 {
+		return controllerutil.TerminalError(fmt.Errorf("when splitting imagestreamtagname %s by : expected two results, got %d", req.Name, n))
+	}
+	isName := types.NamespacedName{Namespace: req.Namespace, Name: imageStreamNameAndTag[0]}
+	tag := imageStreamNameAndTag[1]
+
+	states := map[string]TagState{}
+	streams := map[string]*imagev1.ImageStream{}
+	for cluster, client := range r.clients {
+		stream := &imagev1.ImageStream{}
+		if err := client.Get(ctx, isName, stream); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return fmt.Errorf("failed to get imagestream %s from cluster %s: %w", isName, cluster, err)
+		}
+		streams[cluster] = stream
+		if current := latestTagEvent(stream, tag); current != nil {
+			states[cluster] = TagState{Digest: current.Image, Created: current.Created.Time}
+		}
+	}
+	if len(states) == 0 {
+		log.Debug("Tag not found on any cluster")
+		return nil
+	}
+
+	inventory := inventoryFor(isName, tag, states)
+	recordConflict(inventory)
+	if err := r.annotateConflict(ctx, streams, tag, inventory.Conflict, log); err != nil {
+		return err
+	}
+	if inventory.SourceCluster == "" {
+		log.Warn("The newest content of the tag can not be determined, not syncing it")
+		return nil
+	}
+	*log = *log.WithField("source_cluster", inventory.SourceCluster)
+	sourceStream := streams[inventory.SourceCluster]
+	if sourceStream.Status.PublicDockerImageRepository == "" {
+		return controllerutil.TerminalError(fmt.Errorf("imagestream %s has no public repository on cluster %s", isName, inventory.SourceCluster))
+	}
+	pullSpec := fmt.Sprintf("%s@%s", sourceStream.Status.PublicDockerImageRepository, inventory.Digest)
+
+	var errs []error
+	for _, cluster := range sets.StringKeySet(r.clients).List() {
+		if state, ok := states[cluster]; ok && state.Digest == inventory.Digest {
+			continue
+		}
+		if err := r.importTag(ctx, cluster, isName, tag, pullSpec, log.WithField("cluster", cluster)); err != nil {
+			errs = append(errs, fmt.Errorf("failed to sync tag %s into cluster %s: %w", tag, cluster, err))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// annotateConflict describes the conflict of the tag in an annotation of the ImageStream
+// on every cluster and records an event whenever the annotation changes, so the owners of
+// the ImageStream learn about it. The annotation is removed once the conflict is resolved.
+func (r *reconciler) annotateConflict(ctx context.Context, streams map[string]*imagev1.ImageStream, tag string, conflict *TagConflict, log *logrus.Entry) error {
+	key := ConflictAnnotationPrefix + tag
+	if errs := validation.IsQualifiedName(key); len(errs) != 0 {
+		if conflict != nil {
+			log.WithField("annotation", key).Warnf("Can not annotate the conflict of the tag: %s", strings.Join(errs, ", "))
+		}
+		return nil
+	}
+	var message string
+	if conflict != nil {
+		message = conflictMessage(tag, conflict)
+	}
+	var errs []error
+	for _, cluster := range sets.StringKeySet(streams).List() {
+		stream := streams[cluster]
+		current, annotated := stream.Annotations[key]
+		if conflict == nil && !annotated || conflict != nil && current == message {
+			continue
+		}
+		original := stream.DeepCopy()
+		if conflict != nil {
+			if stream.Annotations == nil {
+				stream.Annotations = map[string]string{}
+			}
+			stream.Annotations[key] = message
+		} else {
+			delete(stream.Annotations, key)
+		}
+		if err := r.clients[cluster].Patch(ctx, stream, ctrlruntimeclient.MergeFrom(original)); err != nil {
+			errs = append(errs, fmt.Errorf("failed to update the conflict annotation of imagestream %s/%s on cluster %s: %w", stream.Namespace, stream.Name, cluster, err))
+			continue
+		}
+		if conflict != nil {
+			r.recorders[cluster].Event(stream, corev1.EventTypeWarning, "TagConflict", message)
+		} else {
+			r.recorders[cluster].Eventf(stream, corev1.EventTypeNormal, "TagConflictResolved", "The content of tag %s is no longer conflicting across clusters", tag)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// latestTagEvent returns the current content of the tag in the ImageStream
+func latestTagEvent(stream *imagev1.ImageStream, tag string) *imagev1.TagEvent {
+	for _, history := range stream.Status.Tags {
+		if history.Tag == tag && len(history.Items) > 0 {
+			return &history.Items[0]
+		}
+	}
+	return nil
+}
+
+func (r *reconciler) importTag(ctx context.Context, cluster string, isName types.NamespacedName, tag, pullSpec string, log *logrus.Entry) error {
+	client := r.clients[cluster]
+	if err := client.Get(ctx, types.NamespacedName{Name: isName.Namespace}, &corev1.Namespace{}); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to check if namespace %s exists: %w", isName.Namespace, err)
+		}
+		if err := client.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: isName.Namespace}}); err != nil && !apierrors.IsAlreadyExists(err) {
+			return fmt.Errorf("failed to create namespace %s: %w", isName.Namespace, err)
+		}
+	}
+	if err := controllerutil.EnsureImagePullSecret(ctx, isName.Namespace, client, log); err != nil {
+		return fmt.Errorf("failed to ensure imagePullSecret: %w", err)
+	}
+
+	imageStreamImport := &imagev1.ImageStreamImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: isName.Namespace,
+			Name:      isName.Name,
+		},
+		Spec: imagev1.ImageStreamImportSpec{
+			Import: true,
+			Images: []imagev1.ImageImportSpec{{
+				From: corev1.ObjectReference{
+					Kind: "DockerImage",
+					Name: pullSpec,
+				},
+				To: &corev1.LocalObjectReference{Name: tag},
+				ReferencePolicy: imagev1.TagReferencePolicy{
+					Type: imagev1.LocalTagReferencePolicy,
+				},
+			}},
+		},
+	}
+	// ImageStreamImport is not an ordinary api but a virtual one that does the import synchronously
+	if err := client.Create(ctx, imageStreamImport); err != nil {
+		controllerutil.CountImportResult(ControllerName, cluster, isName.Namespace, isName.Name, false)
+		return fmt.Errorf("failed to import Image: %w", err)
+	}
+	// This should never be needed, but we shouldn't panic if the server screws up
+	if imageStreamImport.Status.Images == nil {
+		imageStreamImport.Status.Images = []imagev1.ImageImportStatus{{}}
+	}
+	if imageStreamImport.Status.Images[0].Image == nil {
+		controllerutil.CountImportResult(ControllerName, cluster, isName.Namespace, isName.Name, false)
+		return fmt.Errorf("imageStreamImport did not succeed: reason: %s, message: %s", imageStreamImport.Status.Images[0].Status.Reason, imageStreamImport.Status.Images[0].Status.Message)
+	}
+	controllerutil.CountImportResult(ControllerName, cluster, isName.Namespace, isName.Name, true)
+	log.WithField("pull_spec", pullSpec).Debug("Imported successfully")
+	return nil
+}
@@ -0,0 +1,234 @@
+package registrysyncer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/sirupsen/logrus"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	imagev1 "github.com/openshift/api/image/v1"
+)
+
+// importStatusSettingClient sets the status of ImageStreamImports on creation like the server does
+type importStatusSettingClient struct {
+	ctrlruntimeclient.Client
+}
+
+func (c *importStatusSettingClient) Create(ctx context.Context, obj ctrlruntimeclient.Object, opts ...ctrlruntimeclient.CreateOption) error {
+	if asserted, match := obj.(*imagev1.ImageStreamImport); match {
+		asserted.Status.Images = []imagev1.ImageImportStatus{{Image: &imagev1.Image{}}}
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func publicImageStream(namespace, name, cluster string, tags ...imagev1.NamedTagEventList) *imagev1.ImageStream {
+	stream := imageStream(namespace, name, tags...)
+	stream.Status.PublicDockerImageRepository = "registry." + cluster + ".ci.openshift.org/" + namespace + "/" + name
+	return stream
+}
+
+func TestReconcile(t *testing.T) {
+	old := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	newest := old.Add(2 * time.Hour)
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "ci", Name: "clonerefs:latest"}}
+
+	testCases := []struct {
+		name            string
+		clients         map[string]ctrlruntimeclient.Client
+		request         reconcile.Request
+		expectedImports map[string]string
+		expectedErr     bool
+	}{
+		{
+			name: "newest content is imported into outdated clusters and clusters that lack the tag",
+			clients: map[string]ctrlruntimeclient.Client{
+				"app.ci":  fakectrlruntimeclient.NewFakeClient(publicImageStream("ci", "clonerefs", "app.ci", tag("latest", "sha256:old", old))),
+				"build01": fakectrlruntimeclient.NewFakeClient(publicImageStream("ci", "clonerefs", "build01", tag("latest", "sha256:new", newest))),
+				"build02": fakectrlruntimeclient.NewFakeClient(publicImageStream("ci", "clonerefs", "build02", tag("latest", "sha256:new", newest.Add(time.Hour)))),
+				"build03": fakectrlruntimeclient.NewFakeClient(),
+			},
+			request: request,
+			expectedImports: map[string]string{
+				"app.ci":  "registry.build02.ci.openshift.org/ci/clonerefs@sha256:new",
+				"build03": "registry.build02.ci.openshift.org/ci/clonerefs@sha256:new",
+			},
+		},
+		{
+			name: "tag in sync, nothing is imported",
+			clients: map[string]ctrlruntimeclient.Client{
+				"app.ci":  fakectrlruntimeclient.NewFakeClient(publicImageStream("ci", "clonerefs", "app.ci", tag("latest", "sha256:new", newest))),
+				"build01": fakectrlruntimeclient.NewFakeClient(publicImageStream("ci", "clonerefs", "build01", tag("latest", "sha256:new", old))),
+			},
+			request: request,
+		},
+		{
+			name: "tag exists nowhere, nothing is imported",
+			clients: map[string]ctrlruntimeclient.Client{
+				"app.ci":  fakectrlruntimeclient.NewFakeClient(publicImageStream("ci", "clonerefs", "app.ci", tag("other", "sha256:new", newest))),
+				"build01": fakectrlruntimeclient.NewFakeClient(),
+			},
+			request: request,
+		},
+		{
+			name: "source without public repository is an error",
+			clients: map[string]ctrlruntimeclient.Client{
+				"app.ci":  fakectrlruntimeclient.NewFakeClient(imageStream("ci", "clonerefs", tag("latest", "sha256:new", newest))),
+				"build01": fakectrlruntimeclient.NewFakeClient(),
+			},
+			request:     request,
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			clients := map[string]ctrlruntimeclient.Client{}
+			for cluster, client := range tc.clients {
+				clients[cluster] = &importStatusSettingClient{Client: client}
+			}
+			r := &reconciler{log: logrus.NewEntry(logrus.StandardLogger()), clients: clients}
+			err := r.reconcile(context.Background(), tc.request, logrus.NewEntry(logrus.StandardLogger()))
+			if (err != nil) != tc.expectedErr {
+				t.Fatalf("expected error: %t, got: %v", tc.expectedErr, err)
+			}
+
+			imports := map[string]string{}
+			for cluster, client := range tc.clients {
+				imageStreamImport := &imagev1.ImageStreamImport{}
+				if err := client.Get(context.Background(), types.NamespacedName{Namespace: "ci", Name: "clonerefs"}, imageStreamImport); err != nil {
+					continue
+				}
+				if n := len(imageStreamImport.Spec.Images); n != 1 {
+					t.Fatalf("expected import into cluster %s to import one image, got %d", cluster, n)
+				}
+				image := imageStreamImport.Spec.Images[0]
+				if image.To == nil || image.To.Name != "latest" {
+					t.Errorf("expected import into cluster %s to import into the latest tag, got %v", cluster, image.To)
+				}
+				imports[cluster] = image.From.Name
+				if err := client.Get(context.Background(), types.NamespacedName{Name: "ci"}, &corev1.Namespace{}); err != nil {
+					t.Errorf("namespace was not created on cluster %s: %v", cluster, err)
+				}
+			}
+			if len(imports) == 0 {
+				imports = nil
+			}
+			if diff := cmp.Diff(tc.expectedImports, imports); diff != "" {
+				t.Errorf("imports differ from expected: %s", diff)
+			}
+		})
+	}
+}
+
+func TestReconcileConflict(t *testing.T) {
+	created := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "ci", Name: "clonerefs:conflicting"}}
+	key := types.NamespacedName{Namespace: "ci", Name: "clonerefs"}
+	annotation := ConflictAnnotationPrefix + "conflicting"
+	upstreams := map[string]ctrlruntimeclient.Client{
+		"app.ci":  fakectrlruntimeclient.NewFakeClient(publicImageStream("ci", "clonerefs", "app.ci", tag("conflicting", "sha256:a", created))),
+		"build01": fakectrlruntimeclient.NewFakeClient(publicImageStream("ci", "clonerefs", "build01", tag("conflicting", "sha256:b", created.Add(time.Minute)))),
+	}
+	clients := map[string]ctrlruntimeclient.Client{}
+	recorders := map[string]*record.FakeRecorder{}
+	r := &reconciler{log: logrus.NewEntry(logrus.StandardLogger()), clients: clients, recorders: map[string]record.EventRecorder{}}
+	for cluster, client := range upstreams {
+		clients[cluster] = &importStatusSettingClient{Client: client}
+		recorders[cluster] = record.NewFakeRecorder(10)
+		r.recorders[cluster] = recorders[cluster]
+	}
+	reconcileAndCheck := func(expectedAnnotation string, expectedEvent string, expectedConflict float64) {
+		t.Helper()
+		if err := r.reconcile(context.Background(), request, logrus.NewEntry(logrus.StandardLogger())); err != nil {
+			t.Fatalf("reconciliation failed: %v", err)
+		}
+		for cluster, client := range upstreams {
+			stream := &imagev1.ImageStream{}
+			if err := client.Get(context.Background(), key, stream); err != nil {
+				t.Fatalf("failed to get the imagestream from cluster %s: %v", cluster, err)
+			}
+			if diff := cmp.Diff(expectedAnnotation, stream.Annotations[annotation]); diff != "" {
+				t.Errorf("annotation on cluster %s differs from expected: %s", cluster, diff)
+			}
+			var events []string
+			for len(recorders[cluster].Events) > 0 {
+				events = append(events, <-recorders[cluster].Events)
+			}
+			var expectedEvents []string
+			if expectedEvent != "" {
+				expectedEvents = []string{expectedEvent}
+			}
+			if diff := cmp.Diff(expectedEvents, events); diff != "" {
+				t.Errorf("events on cluster %s differ from expected: %s", cluster, diff)
+			}
+		}
+		metric := &dto.Metric{}
+		if err := conflictGauge.WithLabelValues("ci", "clonerefs", "conflicting").Write(metric); err != nil {
+			t.Fatalf("failed to get metric: %v", err)
+		}
+		if value := metric.GetGauge().GetValue(); value != expectedConflict {
+			t.Errorf("expected the conflict metric to be %f, got %f", expectedConflict, value)
+		}
+	}
+
+	message := "Tag conflicting got different content on the clusters app.ci, build01 within 10m0s, it is not synced until the conflict is resolved: sha256:a, sha256:b"
+	reconcileAndCheck(message, "Warning TagConflict "+message, 1)
+	// the event is only recorded when the conflict changes
+	reconcileAndCheck(message, "", 1)
+
+	resolved := &imagev1.ImageStream{}
+	if err := upstreams["build01"].Get(context.Background(), key, resolved); err != nil {
+		t.Fatalf("failed to get the imagestream: %v", err)
+	}
+	resolved.Status.Tags = []imagev1.NamedTagEventList{tag("conflicting", "sha256:b", created.Add(time.Hour))}
+	if err := upstreams["build01"].Update(context.Background(), resolved); err != nil {
+		t.Fatalf("failed to update the imagestream: %v", err)
+	}
+	reconcileAndCheck("", "Normal TagConflictResolved The content of tag conflicting is no longer conflicting across clusters", 0)
+	imageStreamImport := &imagev1.ImageStreamImport{}
+	if err := upstreams["app.ci"].Get(context.Background(), key, imageStreamImport); err != nil {
+		t.Errorf("expected the resolved tag to be imported into app.ci: %v", err)
+	}
+}
+
+func TestResync(t *testing.T) {
+	events := make(chan event.GenericEvent)
+	r := &resyncer{
+		log: logrus.NewEntry(logrus.StandardLogger()),
+		clients: map[string]ctrlruntimeclient.Client{
+			"app.ci": fakectrlruntimeclient.NewFakeClient(
+				imageStream("ci", "clonerefs"),
+				imageStream("ocp", "4.8"),
+				imageStream("unmanaged", "other"),
+			),
+			"build01": fakectrlruntimeclient.NewFakeClient(imageStream("ci", "clonerefs")),
+		},
+		namespaces: sets.NewString("ci", "ocp"),
+		interval:   time.Hour,
+		events:     events,
+	}
+	go func() {
+		r.resync(context.Background())
+		close(events)
+	}()
+
+	var resynced []string
+	for e := range events {
+		resynced = append(resynced, e.Object.GetNamespace()+"/"+e.Object.GetName())
+	}
+	if diff := cmp.Diff([]string{"ci/clonerefs", "ocp/4.8", "ci/clonerefs"}, resynced); diff != "" {
+		t.Errorf("resynced imagestreams differ from expected: %s", diff)
+	}
+}
@@ -0,0 +1,254 @@
+package secretsyncer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	controllerutil "github.com/openshift/ci-tools/pkg/controller/util"
+)
+
+const (
+	ControllerName = "secret_syncer"
+
+	DefaultMaxConcurrentReconciles = 5
+
+	// HashAnnotation holds the hash of the content of the source secret a
+	// target secret was last synced from. It allows to tell apart changes
+	// of the source secret from changes that were made to the target secret.
+	HashAnnotation = "ci.openshift.io/secret-syncer-hash"
+)
+
+const (
+	reasonCreated       = "created"
+	reasonSourceChanged = "source_changed"
+	reasonDrifted       = "drifted"
+)
+
+var (
+	syncsCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "secret_syncer_syncs_total",
+		Help: "The number of times the secret syncer created or updated a secret in a cluster, by reason",
+	}, []string{"cluster", "namespace", "name", "reason"})
+	inSyncGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "secret_syncer_in_sync",
+		Help: "Whether a secret in a cluster was in sync with its source secret after the last reconciliation",
+	}, []string{"cluster", "namespace", "name"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(syncsCounter, inSyncGauge)
+}
+
+// AddToManager adds a controller that keeps the given secrets of the cluster of mgr in
+// sync across all target clusters.
+func AddToManager(mgr manager.Manager, targetClusters map[string]manager.Manager, secrets sets.String, maxConcurrentReconciles int) error {
+	log := logrus.WithField("controller", ControllerName)
+	r := &reconciler{
+		log:          log,
+		sourceClient: mgr.GetClient(),
+		targets:      map[string]ctrlruntimeclient.Client{},
+	}
+	c, err := controller.New(ControllerName, mgr, controller.Options{
+		Reconciler:              controllerutil.TraceReconciler(ControllerName, r),
+		MaxConcurrentReconciles: maxConcurrentReconciles,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to construct controller: %w", err)
+	}
+
+	clusters := sets.NewString()
+	for cluster, clusterMgr := range targetClusters {
+		clusters.Insert(cluster)
+		r.targets[cluster] = clusterMgr.GetClient()
+		if err := c.Watch(source.NewKindWithCache(&corev1.Secret{}, clusterMgr.GetCache()), secretHandler(secrets, sets.NewString(cluster))); err != nil {
+			return fmt.Errorf("failed to construct watch for Secrets in cluster %s: %w", cluster, err)
+		}
+	}
+	if err := c.Watch(source.NewKindWithCache(&corev1.Secret{}, mgr.GetCache()), secretHandler(secrets, clusters)); err != nil {
+		return fmt.Errorf("failed to construct watch for source Secrets: %w", err)
+	}
+
+	return nil
+}
+
+// secretHandler creates a request per cluster for the secrets that are synced. We have to squeeze
+// both the cluster name and the secret name into a reconcile.Request, which gets put onto the workqueue
+// in namespace/name notation, so we can not use a slash as delimiter for the namespace and the cluster.
+func secretHandler(secrets, clusters sets.String) handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(func(o ctrlruntimeclient.Object) []reconcile.Request {
+		if !secrets.Has(types.NamespacedName{Namespace: o.GetNamespace(), Name: o.GetName()}.String()) {
+			return nil
+		}
+		var requests []reconcile.Request
+		for _, cluster := range clusters.List() {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{
+				Namespace: encodeNamespace(o.GetNamespace(), cluster),
+				Name:      o.GetName(),
+			}})
+		}
+		return requests
+	})
+}
+
+const namespaceAndClusterDelimiter = "_"
+
+// encodeNamespace puts the cluster after the namespace: namespaces can not contain the
+// delimiter, so everything after its first occurrence is the cluster, whatever its name.
+func encodeNamespace(namespace, cluster string) string {
+	return namespace + namespaceAndClusterDelimiter + cluster
+}
+
+func decodeRequest(req reconcile.Request) (string, types.NamespacedName, error) {
+	namespaceAndCluster := strings.SplitN(req.Namespace, namespaceAndClusterDelimiter, 2)
+	if len(namespaceAndCluster) != 2 || namespaceAndCluster[0] == "" || namespaceAndCluster[1] == "" {
+		return "", types.NamespacedName{}, fmt.Errorf("failed to extract namespace and cluster from %q", req.Namespace)
+	}
+	return namespaceAndCluster[1], types.NamespacedName{Namespace: namespaceAndCluster[0], Name: req.Name}, nil
+}
+
+type reconciler struct {
+	log          *logrus.Entry
+	sourceClient ctrlruntimeclient.Client
+	targets      map[string]ctrlruntimeclient.Client
+}
+
+func (r *reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	log := controllerutil.LoggerForRequest(r.log, req)
+	startTime := time.Now()
+	err := r.reconcile(ctx, req, log)
+	log = log.WithField("duration", time.Since(startTime))
+	if err != nil && !apierrors.IsConflict(err) {
+		log.WithError(err).Error("Reconciliation failed")
+	} else {
+		log.Debug("Finished reconciliation")
+	}
+	return reconcile.Result{}, controllerutil.SwallowIfTerminal(err)
+}
+
+func (r *reconciler) reconcile(ctx context.Context, req reconcile.Request, log *logrus.Entry) error {
+	cluster, decoded, err := decodeRequest(req)
+	if err != nil {
+		return controllerutil.TerminalError(fmt.Errorf("failed to decode request %s: %w", req, err))
+	}
+	log = log.WithField("cluster", cluster)
+	client, ok := r.targets[cluster]
+	if !ok {
+		return controllerutil.TerminalError(fmt.Errorf("no client for cluster %s", cluster))
+	}
+
+	source := &corev1.Secret{}
+	if err := r.sourceClient.Get(ctx, decoded, source); err != nil {
+		if apierrors.IsNotFound(err) {
+			// We never delete secrets, as we can not tell if the target secret was created by us
+			log.Warn("Source secret not found")
+			return nil
+		}
+		return fmt.Errorf("failed to get source secret %s: %w", decoded, err)
+	}
+	desiredHash := hashFor(source)
+
+	target := &corev1.Secret{}
+	if err := client.Get(ctx, decoded, target); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to get secret %s: %w", decoded, err)
+		}
+		target = nil
+	}
+
+	inSyncGauge.WithLabelValues(cluster, decoded.Namespace, decoded.Name).Set(0)
+	var reason string
+	switch {
+	case target == nil:
+		reason = reasonCreated
+	case hashFor(target) == desiredHash:
+		inSyncGauge.WithLabelValues(cluster, decoded.Namespace, decoded.Name).Set(1)
+		return nil
+	case target.Annotations[HashAnnotation] != hashFor(target):
+		reason = reasonDrifted
+	default:
+		reason = reasonSourceChanged
+	}
+	log = log.WithField("reason", reason)
+
+	// The type of a secret is immutable
+	if target != nil && target.Type != source.Type {
+		log.Info("Deleting secret to change its type")
+		if err := client.Delete(ctx, target, ctrlruntimeclient.Preconditions{UID: &target.UID}); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete secret %s: %w", decoded, err)
+		}
+		target = nil
+	}
+
+	if target == nil {
+		log.Info("Creating secret")
+		if err := client.Create(ctx, desiredSecret(source, desiredHash, &corev1.Secret{})); err != nil {
+			return fmt.Errorf("failed to create secret %s: %w", decoded, err)
+		}
+	} else {
+		log.Info("Updating secret")
+		if err := client.Update(ctx, desiredSecret(source, desiredHash, target)); err != nil {
+			return fmt.Errorf("failed to update secret %s: %w", decoded, err)
+		}
+	}
+	syncsCounter.WithLabelValues(cluster, decoded.Namespace, decoded.Name, reason).Inc()
+	inSyncGauge.WithLabelValues(cluster, decoded.Namespace, decoded.Name).Set(1)
+	return nil
+}
+
+// desiredSecret updates existing to have the content of source
+func desiredSecret(source *corev1.Secret, hash string, existing *corev1.Secret) *corev1.Secret {
+	existing.ObjectMeta = metav1.ObjectMeta{
+		Namespace:       source.Namespace,
+		Name:            source.Name,
+		ResourceVersion: existing.ResourceVersion,
+		UID:             existing.UID,
+		Labels:          source.Labels,
+		Annotations:     map[string]string{HashAnnotation: hash},
+	}
+	for key, value := range source.Annotations {
+		existing.Annotations[key] = value
+	}
+	existing.Type = source.Type
+	existing.Data = source.Data
+	return existing
+}
+
+// hashFor returns a hash of the content of the secret that gets synced
+func hashFor(secret *corev1.Secret) string {
+	var keys []string
+	for key := range secret.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	hash := sha256.New()
+	hash.Write([]byte(secret.Type))
+	for _, key := range keys {
+		// Separate the fields so different secrets can not have the same input
+		hash.Write([]byte{0})
+		hash.Write([]byte(key))
+		hash.Write([]byte{0})
+		hash.Write(secret.Data[key])
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,148 @@
+package secretsyncer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/sirupsen/logrus"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/openshift/ci-tools/pkg/api"
+)
+
+func TestReconcile(t *testing.T) {
+	secret := func(data string, mods ...func(*corev1.Secret)) *corev1.Secret {
+		s := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ci", Name: api.RegistryPullCredentialsSecret},
+			Type:       corev1.SecretTypeDockerConfigJson,
+			Data:       map[string][]byte{corev1.DockerConfigJsonKey: []byte(data)},
+		}
+		for _, mod := range mods {
+			mod(s)
+		}
+		return s
+	}
+	synced := func(s *corev1.Secret) *corev1.Secret {
+		s.Annotations = map[string]string{HashAnnotation: hashFor(s)}
+		return s
+	}
+	withAnnotation := func(hash string) func(*corev1.Secret) {
+		return func(s *corev1.Secret) { s.Annotations = map[string]string{HashAnnotation: hash} }
+	}
+
+	testCases := []struct {
+		name     string
+		source   []ctrlruntimeclient.Object
+		target   []ctrlruntimeclient.Object
+		expected *corev1.Secret
+	}{
+		{
+			name:     "missing secret is created",
+			source:   []ctrlruntimeclient.Object{secret("new")},
+			expected: synced(secret("new")),
+		},
+		{
+			name:     "secret in sync is left alone",
+			source:   []ctrlruntimeclient.Object{secret("new")},
+			target:   []ctrlruntimeclient.Object{secret("new", withAnnotation("old-hash"))},
+			expected: secret("new", withAnnotation("old-hash")),
+		},
+		{
+			name:     "secret is updated when the source changed",
+			source:   []ctrlruntimeclient.Object{secret("new")},
+			target:   []ctrlruntimeclient.Object{synced(secret("old"))},
+			expected: synced(secret("new")),
+		},
+		{
+			name:     "drifted secret is updated",
+			source:   []ctrlruntimeclient.Object{secret("new")},
+			target:   []ctrlruntimeclient.Object{secret("modified", withAnnotation(hashFor(secret("new"))))},
+			expected: synced(secret("new")),
+		},
+		{
+			name:   "secret with a different type is recreated",
+			source: []ctrlruntimeclient.Object{secret("new")},
+			target: []ctrlruntimeclient.Object{synced(secret("new", func(s *corev1.Secret) {
+				s.Type = corev1.SecretTypeOpaque
+			}))},
+			expected: synced(secret("new")),
+		},
+		{
+			name:     "secret is kept when the source is gone",
+			target:   []ctrlruntimeclient.Object{synced(secret("old"))},
+			expected: synced(secret("old")),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			targetClient := fakectrlruntimeclient.NewClientBuilder().WithObjects(tc.target...).Build()
+			r := &reconciler{
+				log:          logrus.NewEntry(logrus.StandardLogger()),
+				sourceClient: fakectrlruntimeclient.NewClientBuilder().WithObjects(tc.source...).Build(),
+				targets:      map[string]ctrlruntimeclient.Client{"build01": targetClient},
+			}
+			request := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "ci_build01", Name: api.RegistryPullCredentialsSecret}}
+			if _, err := r.Reconcile(context.Background(), request); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			actual := &corev1.Secret{}
+			if err := targetClient.Get(context.Background(), types.NamespacedName{Namespace: "ci", Name: api.RegistryPullCredentialsSecret}, actual); err != nil {
+				t.Fatalf("failed to get target secret: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, actual, cmpopts.IgnoreFields(metav1.ObjectMeta{}, "ResourceVersion"), cmpopts.IgnoreFields(metav1.TypeMeta{}, "Kind", "APIVersion")); diff != "" {
+				t.Errorf("secret differs from expected: %s", diff)
+			}
+		})
+	}
+}
+
+func TestDecodeRequest(t *testing.T) {
+	testCases := []struct {
+		name            string
+		request         reconcile.Request
+		expectedCluster string
+		expectedName    types.NamespacedName
+		expectedErr     bool
+	}{
+		{
+			name:            "cluster and namespace are extracted",
+			request:         reconcile.Request{NamespacedName: types.NamespacedName{Namespace: encodeNamespace("ci", "build01"), Name: "secret"}},
+			expectedCluster: "build01",
+			expectedName:    types.NamespacedName{Namespace: "ci", Name: "secret"},
+		},
+		{
+			name:            "cluster name with the delimiter",
+			request:         reconcile.Request{NamespacedName: types.NamespacedName{Namespace: encodeNamespace("ci", "build_01"), Name: "secret"}},
+			expectedCluster: "build_01",
+			expectedName:    types.NamespacedName{Namespace: "ci", Name: "secret"},
+		},
+		{
+			name:        "no delimiter",
+			request:     reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "ci", Name: "secret"}},
+			expectedErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cluster, name, err := decodeRequest(tc.request)
+			if (err != nil) != tc.expectedErr {
+				t.Fatalf("expected error: %t, got: %v", tc.expectedErr, err)
+			}
+			if cluster != tc.expectedCluster {
+				t.Errorf("expected cluster %q, got %q", tc.expectedCluster, cluster)
+			}
+			if diff := cmp.Diff(tc.expectedName, name); diff != "" {
+				t.Errorf("name differs from expected: %s", diff)
+			}
+		})
+	}
+}
@@ -18,15 +18,20 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	controllerutil "github.com/openshift/ci-tools/pkg/controller/util"
 )
 
 const (
 	ControllerName = "serviceaccount_secret_refresher"
 
 	TTLAnnotationKey = "serviaccount-secret-rotator.openshift.io/delete-after"
+
+	// When > 1, there will be IsConflict errors on updating the same ServiceAccount
+	DefaultMaxConcurrentReconciles = 20
 )
 
-func AddToManager(clusterName string, mgr manager.Manager, enabledNamespaces sets.String, removeOldSecrets bool) error {
+func AddToManager(clusterName string, mgr manager.Manager, enabledNamespaces sets.String, removeOldSecrets bool, maxConcurrentReconciles int) error {
 	r := &reconciler{
 		client:           mgr.GetClient(),
 		filter:           func(r reconcile.Request) bool { return enabledNamespaces.Has(r.Namespace) },
@@ -35,9 +40,8 @@ func AddToManager(clusterName string, mgr manager.Manager, enabledNamespaces set
 		removeOldSecrets: removeOldSecrets,
 	}
 	c, err := controller.New(fmt.Sprintf("%s_%s", ControllerName, clusterName), mgr, controller.Options{
-		Reconciler: r,
-		// When > 1, there will be IsConflict errors on updating the same ServiceAccount
-		MaxConcurrentReconciles: 20,
+		Reconciler:              controllerutil.TraceReconciler(ControllerName, r),
+		MaxConcurrentReconciles: maxConcurrentReconciles,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to construct controller: %w", err)
@@ -78,8 +82,10 @@ type reconciler struct {
 }
 
 func (r *reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
-	l := r.log.WithField("request", req.String())
+	l := controllerutil.LoggerForRequest(r.log, req)
+	startTime := time.Now()
 	res, err := r.reconcile(ctx, l, req)
+	l = l.WithField("duration", time.Since(startTime))
 	// Ignore the logging for IsConflict errors because they are results of concurrent reconciling
 	if err != nil && !apierrors.IsConflict(err) && !apierrors.IsAlreadyExists(err) {
 		l.WithError(err).Error("Reconciliation failed")
@@ -0,0 +1,168 @@
+package testimagesdistributor
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/fsnotify.v1"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/ci-tools/pkg/util"
+)
+
+// Filters determine which imagestreamtags get distributed in addition to the
+// ones referenced by tests and from which registries no imports are done.
+type Filters struct {
+	AdditionalImageStreamTags       sets.String
+	AdditionalImageStreams          sets.String
+	AdditionalImageStreamNamespaces sets.String
+	ForbiddenRegistries             sets.String
+}
+
+func (f Filters) union(other Filters) Filters {
+	return Filters{
+		AdditionalImageStreamTags:       f.AdditionalImageStreamTags.Union(other.AdditionalImageStreamTags),
+		AdditionalImageStreams:          f.AdditionalImageStreams.Union(other.AdditionalImageStreams),
+		AdditionalImageStreamNamespaces: f.AdditionalImageStreamNamespaces.Union(other.AdditionalImageStreamNamespaces),
+		ForbiddenRegistries:             f.ForbiddenRegistries.Union(other.ForbiddenRegistries),
+	}
+}
+
+// FiltersConfig is the serialized form of Filters
+type FiltersConfig struct {
+	// AdditionalImageStreamTags are distributed even if no test references them. They must be in namespace/name:tag format.
+	AdditionalImageStreamTags []string `json:"additional_image_stream_tags,omitempty"`
+	// AdditionalImageStreams are distributed even if no test references them. They must be in namespace/name format.
+	AdditionalImageStreams []string `json:"additional_image_streams,omitempty"`
+	// AdditionalImageStreamNamespaces are namespaces whose imagestreams are distributed even if no test references them.
+	AdditionalImageStreamNamespaces []string `json:"additional_image_stream_namespaces,omitempty"`
+	// ForbiddenRegistries are hostnames of registries from which no images are imported.
+	ForbiddenRegistries []string `json:"forbidden_registries,omitempty"`
+}
+
+func (c FiltersConfig) validate() error {
+	var errs []error
+	for _, val := range c.AdditionalImageStreamTags {
+		slashSplit := strings.Split(val, "/")
+		if len(slashSplit) != 2 || len(strings.Split(slashSplit[1], ":")) != 2 {
+			errs = append(errs, fmt.Errorf("additional_image_stream_tags: %s is not in namespace/name:tag format", val))
+		}
+	}
+	for _, val := range c.AdditionalImageStreams {
+		if len(strings.Split(val, "/")) != 2 {
+			errs = append(errs, fmt.Errorf("additional_image_streams: %s is not in namespace/name format", val))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func (c FiltersConfig) filters() Filters {
+	return Filters{
+		AdditionalImageStreamTags:       sets.NewString(c.AdditionalImageStreamTags...),
+		AdditionalImageStreams:          sets.NewString(c.AdditionalImageStreams...),
+		AdditionalImageStreamNamespaces: sets.NewString(c.AdditionalImageStreamNamespaces...),
+		ForbiddenRegistries:             sets.NewString(c.ForbiddenRegistries...),
+	}
+}
+
+// FiltersAgent provides the current Filters
+type FiltersAgent interface {
+	Filters() Filters
+	// Subscribe registers a callback that gets called with the previous and the
+	// current Filters whenever they change
+	Subscribe(func(previous, current Filters))
+}
+
+type staticFiltersAgent struct {
+	filters Filters
+}
+
+// NewStaticFiltersAgent returns a FiltersAgent whose Filters never change
+func NewStaticFiltersAgent(filters Filters) FiltersAgent {
+	return &staticFiltersAgent{filters: filters}
+}
+
+func (a *staticFiltersAgent) Filters() Filters {
+	return a.filters
+}
+
+func (a *staticFiltersAgent) Subscribe(func(previous, current Filters)) {}
+
+type filtersAgent struct {
+	base Filters
+	path string
+
+	lock        sync.RWMutex
+	current     Filters
+	subscribers []func(previous, current Filters)
+}
+
+// NewFiltersAgent returns a FiltersAgent that serves the union of base and the
+// FiltersConfig at path. The file gets reloaded whenever it changes. If the file
+// is invalid after a change, the previous Filters are kept.
+func NewFiltersAgent(base Filters, path string) (FiltersAgent, error) {
+	agent := &filtersAgent{base: base, path: path}
+	filters, err := agent.load()
+	if err != nil {
+		return nil, err
+	}
+	agent.current = filters
+	// Files mounted from a ConfigMap are symlinks that get replaced on change,
+	// so we have to watch the directory.
+	if err := util.WatchFiles([]string{filepath.Dir(path)}, agent.reload); err != nil {
+		return nil, fmt.Errorf("failed to watch %s: %w", path, err)
+	}
+	return agent, nil
+}
+
+func (a *filtersAgent) load() (Filters, error) {
+	raw, err := ioutil.ReadFile(a.path)
+	if err != nil {
+		return Filters{}, fmt.Errorf("failed to read %s: %w", a.path, err)
+	}
+	var config FiltersConfig
+	if err := yaml.UnmarshalStrict(raw, &config); err != nil {
+		return Filters{}, fmt.Errorf("failed to unmarshal %s: %w", a.path, err)
+	}
+	if err := config.validate(); err != nil {
+		return Filters{}, fmt.Errorf("invalid config in %s: %w", a.path, err)
+	}
+	return a.base.union(config.filters()), nil
+}
+
+func (a *filtersAgent) reload(fsnotify.Event) {
+	filters, err := a.load()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to reload test images distributor filters, keeping the previous ones")
+		return
+	}
+	a.lock.Lock()
+	previous := a.current
+	a.current = filters
+	subscribers := a.subscribers
+	a.lock.Unlock()
+
+	logrus.WithField("path", a.path).Info("Reloaded test images distributor filters")
+	for _, subscriber := range subscribers {
+		subscriber(previous, filters)
+	}
+}
+
+func (a *filtersAgent) Filters() Filters {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	return a.current
+}
+
+func (a *filtersAgent) Subscribe(subscriber func(previous, current Filters)) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.subscribers = append(a.subscribers, subscriber)
+}
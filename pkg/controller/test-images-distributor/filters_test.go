@@ -0,0 +1,134 @@
+package testimagesdistributor
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	imagev1 "github.com/openshift/api/image/v1"
+
+	"github.com/openshift/ci-tools/pkg/testhelper"
+)
+
+func TestFiltersAgentLoad(t *testing.T) {
+	base := Filters{
+		AdditionalImageStreamTags:       sets.NewString("ci/base:latest"),
+		AdditionalImageStreams:          sets.NewString(),
+		AdditionalImageStreamNamespaces: sets.NewString("ocp"),
+		ForbiddenRegistries:             sets.NewString(),
+	}
+	testCases := []struct {
+		name          string
+		content       string
+		expected      Filters
+		expectedError error
+	}{
+		{
+			name:     "empty file, base is returned",
+			expected: base,
+		},
+		{
+			name: "file content is added to base",
+			content: `additional_image_stream_tags:
+- ci/other:latest
+additional_image_streams:
+- ci/stream
+additional_image_stream_namespaces:
+- origin
+forbidden_registries:
+- registry.build01.ci.openshift.org
+`,
+			expected: Filters{
+				AdditionalImageStreamTags:       sets.NewString("ci/base:latest", "ci/other:latest"),
+				AdditionalImageStreams:          sets.NewString("ci/stream"),
+				AdditionalImageStreamNamespaces: sets.NewString("ocp", "origin"),
+				ForbiddenRegistries:             sets.NewString("registry.build01.ci.openshift.org"),
+			},
+		},
+		{
+			name: "invalid values are rejected",
+			content: `additional_image_stream_tags:
+- ci/other
+additional_image_streams:
+- stream
+`,
+			expectedError: errors.New("invalid config in filters.yaml: [additional_image_stream_tags: ci/other is not in namespace/name:tag format, additional_image_streams: stream is not in namespace/name format]"),
+		},
+		{
+			name:          "unknown fields are rejected",
+			content:       "additional_namespaces: []\n",
+			expectedError: errors.New(`failed to unmarshal filters.yaml: error unmarshaling JSON: while decoding JSON: json: unknown field "additional_namespaces"`),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "filters.yaml")
+			if err := ioutil.WriteFile(path, []byte(tc.content), 0644); err != nil {
+				t.Fatalf("failed to write file: %v", err)
+			}
+			agent := &filtersAgent{base: base, path: path}
+			actual, err := agent.load()
+			if err != nil {
+				// The directory is random, so remove it
+				err = errors.New(strings.ReplaceAll(err.Error(), dir+"/", ""))
+			}
+			if diff := cmp.Diff(tc.expectedError, err, testhelper.EquateErrorMessage); diff != "" {
+				t.Fatalf("error differs from expected: %s", diff)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Errorf("filters differ from expected: %s", diff)
+			}
+		})
+	}
+}
+
+func TestImageStreamsAddedToFilters(t *testing.T) {
+	stream := func(namespace, name string) *imagev1.ImageStream {
+		return &imagev1.ImageStream{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+	}
+	client := fakeclient.NewFakeClient(
+		stream("ocp", "4.8"),
+		stream("ocp", "4.9"),
+		stream("ci", "tools"),
+		stream("ci", "other"),
+		stream("origin", "4.8"),
+	)
+	previous := Filters{
+		AdditionalImageStreamTags:       sets.NewString("ci/other:latest"),
+		AdditionalImageStreams:          sets.NewString(),
+		AdditionalImageStreamNamespaces: sets.NewString("origin"),
+		ForbiddenRegistries:             sets.NewString(),
+	}
+	current := Filters{
+		AdditionalImageStreamTags:       sets.NewString("ci/other:latest", "ci/tools:latest", "ocp/4.8:cli"),
+		AdditionalImageStreams:          sets.NewString("ci/doesnotexist"),
+		AdditionalImageStreamNamespaces: sets.NewString("ocp", "origin"),
+		ForbiddenRegistries:             sets.NewString(),
+	}
+
+	imageStreams, err := imageStreamsAddedToFilters(context.Background(), client, previous, current)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var actual []string
+	for _, imageStream := range imageStreams {
+		actual = append(actual, imageStream.Namespace+"/"+imageStream.Name)
+	}
+	if diff := cmp.Diff([]string{"ocp/4.8", "ocp/4.9", "ci/tools"}, actual); diff != "" {
+		t.Errorf("imagestreams differ from expected: %s", diff)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
 
@@ -12,10 +13,13 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
 	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	crcontrollerutil "sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -34,16 +38,21 @@ import (
 
 const ControllerName = "test_images_distributor"
 
+// We conflict on ImageStream level which means multiple request for imagestreamtags
+// of the same imagestream will conflict so stay at one worker in order to reduce the
+// number of errors we see. If we hit performance issues, we will probably need cluster
+// and/or imagestream level locking.
+const DefaultMaxConcurrentReconciles = 1
+
 func AddToManager(mgr manager.Manager,
 	registryClusterName string,
 	registryManager manager.Manager,
 	buildClusterManagers map[string]manager.Manager,
 	configAgent agents.ConfigAgent,
 	resolver agents.RegistryAgent,
-	additionalImageStreamTags sets.String,
-	additionalImageStreams sets.String,
-	additionalImageStreamNamespaces sets.String,
-	forbiddenRegistries sets.String,
+	filters FiltersAgent,
+	maxConcurrentReconciles int,
+	dryRun bool,
 ) error {
 	log := logrus.WithField("controller", ControllerName)
 
@@ -52,15 +61,12 @@ func AddToManager(mgr manager.Manager,
 		registryClusterName: registryClusterName,
 		registryClient:      imagestreamtagwrapper.MustNew(registryManager.GetClient(), registryManager.GetCache()),
 		buildClusterClients: map[string]ctrlruntimeclient.Client{},
-		forbiddenRegistries: forbiddenRegistries,
+		filters:             filters,
+		recorder:            controllerutil.EventRecorder(registryManager, ControllerName, dryRun),
 	}
 	c, err := controller.New(ControllerName, mgr, controller.Options{
-		Reconciler: r,
-		// We conflict on ImageStream level which means multiple request for imagestreamtags
-		// of the same imagestream will conflict so stay at one worker in order to reduce the
-		// number of errors we see. If we hit performance issues, we will probably need cluster
-		// and/or imagestream level locking.
-		MaxConcurrentReconciles: 1,
+		Reconciler:              controllerutil.TraceReconciler(ControllerName, r),
+		MaxConcurrentReconciles: maxConcurrentReconciles,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to construct controller: %w", err)
@@ -93,7 +99,7 @@ func AddToManager(mgr manager.Manager,
 		appCIClient = imagestreamtagwrapper.MustNew(mgr.GetClient(), mgr.GetCache())
 	}
 
-	objectFilter, err := testInputImageStreamTagFilterFactory(log, configAgent, appCIClient, resolver, additionalImageStreamTags, additionalImageStreams, additionalImageStreamNamespaces)
+	objectFilter, err := testInputImageStreamTagFilterFactory(log, configAgent, appCIClient, resolver, filters)
 	if err != nil {
 		return fmt.Errorf("failed to get filter for ImageStreamTags: %w", err)
 	}
@@ -104,6 +110,26 @@ func AddToManager(mgr manager.Manager,
 		return fmt.Errorf("failed to create watch for ImageStreams: %w", err)
 	}
 
+	// Imagestreams that got added to the filters would otherwise only get distributed once they change
+	addedImageStreams := make(chan event.GenericEvent)
+	if err := c.Watch(
+		&source.Channel{Source: addedImageStreams},
+		registryClusterHandlerFactory(buildClusters, objectFilter),
+	); err != nil {
+		return fmt.Errorf("failed to create watch for added ImageStreams: %w", err)
+	}
+	filters.Subscribe(func(previous, current Filters) {
+		imageStreams, err := imageStreamsAddedToFilters(context.TODO(), r.registryClient, previous, current)
+		if err != nil {
+			log.WithError(err).Error("Failed to get imagestreams that were added to the filters")
+		}
+		go func() {
+			for _, imageStream := range imageStreams {
+				addedImageStreams <- event.GenericEvent{Object: imageStream}
+			}
+		}()
+	})
+
 	r.log.Info("Successfully added reconciler to manager")
 	return nil
 }
@@ -166,13 +192,17 @@ type reconciler struct {
 	registryClusterName string
 	registryClient      ctrlruntimeclient.Client
 	buildClusterClients map[string]ctrlruntimeclient.Client
-	forbiddenRegistries sets.String
+	filters             FiltersAgent
+	// recorder records events on the ImageStreams of the registry cluster
+	recorder record.EventRecorder
 }
 
 func (r *reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
-	log := r.log.WithField("request", req.String())
+	log := controllerutil.LoggerForRequest(r.log, req)
 	log.Info("Starting reconciliation")
+	startTime := time.Now()
 	err := r.reconcile(ctx, req, log)
+	log = log.WithField("duration", time.Since(startTime))
 	if err != nil && !apierrors.IsConflict(err) {
 		log.WithError(err).Error("Reconciliation failed")
 	} else {
@@ -217,8 +247,9 @@ func (r *reconciler) reconcile(ctx context.Context, req reconcile.Request, log *
 	}
 
 	*log = *log.WithField("docker_image_reference", sourceImageStreamTag.Image.DockerImageReference)
-	if isImportForbidden(sourceImageStreamTag.Image.DockerImageReference, r.forbiddenRegistries) {
-		log.Debugf("Import from any cluster in %s is forbidden, ignoring", r.forbiddenRegistries)
+	if forbiddenRegistries := r.filters.Filters().ForbiddenRegistries; isImportForbidden(sourceImageStreamTag.Image.DockerImageReference, forbiddenRegistries) {
+		log.Debugf("Import from any cluster in %s is forbidden, ignoring", forbiddenRegistries)
+		r.recorder.Eventf(sourceImageStream, corev1.EventTypeNormal, "SyncSkipped", "Not distributing tag %s to cluster %s, because importing %s is forbidden", imageTag, cluster, sourceImageStreamTag.Image.DockerImageReference)
 		return nil
 	}
 
@@ -284,8 +315,10 @@ func (r *reconciler) reconcile(ctx context.Context, req reconcile.Request, log *
 	}
 
 	// ImageStreamImport is not an ordinary api but a virtual one that does the import synchronously
+	r.recorder.Eventf(sourceImageStream, corev1.EventTypeNormal, "ImportTriggered", "Importing tag %s into cluster %s", imageTag, cluster)
 	if err := client.Create(ctx, imageStreamImport); err != nil {
 		controllerutil.CountImportResult(ControllerName, cluster, decoded.Namespace, imageStreamName, false)
+		r.recorder.Eventf(sourceImageStream, corev1.EventTypeWarning, "ImportFailed", "Failed to import tag %s into cluster %s: %v", imageTag, cluster, err)
 		return fmt.Errorf("failed to import Image: %w", err)
 	}
 
@@ -294,15 +327,34 @@ func (r *reconciler) reconcile(ctx context.Context, req reconcile.Request, log *
 		imageStreamImport.Status.Images = []imagev1.ImageImportStatus{{}}
 	}
 	if imageStreamImport.Status.Images[0].Image == nil {
+		r.recorder.Eventf(sourceImageStream, corev1.EventTypeWarning, "ImportFailed", "Failed to import tag %s into cluster %s: reason: %s, message: %s", imageTag, cluster, imageStreamImport.Status.Images[0].Status.Reason, imageStreamImport.Status.Images[0].Status.Message)
 		return fmt.Errorf("imageStreamImport did not succeed: reason: %s, message: %s", imageStreamImport.Status.Images[0].Status.Reason, imageStreamImport.Status.Images[0].Status.Message)
 	}
 
 	controllerutil.CountImportResult(ControllerName, cluster, decoded.Namespace, imageStreamName, true)
+	if created, found := tagCreationTimestamp(sourceImageStream, imageTag); found {
+		controllerutil.ObserveImportLatency(ControllerName, cluster, decoded.Namespace, created)
+	}
 
 	log.Debug("Imported successfully")
 	return nil
 }
 
+// tagCreationTimestamp returns when the current image of the tag was created in the imagestream.
+// Tags without history or creation timestamp are not found, so they do not skew the latency.
+func tagCreationTimestamp(imageStream *imagev1.ImageStream, tag string) (time.Time, bool) {
+	for _, history := range imageStream.Status.Tags {
+		if history.Tag != tag {
+			continue
+		}
+		if len(history.Items) == 0 || history.Items[0].Created.IsZero() {
+			return time.Time{}, false
+		}
+		return history.Items[0].Created.Time, true
+	}
+	return time.Time{}, false
+}
+
 func (r *reconciler) isImageStreamTagCurrent(
 	ctx context.Context,
 	name types.NamespacedName,
@@ -411,17 +463,18 @@ type registryResolver interface {
 	ResolveConfig(config api.ReleaseBuildConfiguration) (api.ReleaseBuildConfiguration, error)
 }
 
-func testInputImageStreamTagFilterFactory(l *logrus.Entry, ca agents.ConfigAgent, client ctrlruntimeclient.Client, resolver registryResolver, additionalImageStreamTags, additionalImageStreams, additionalImageStreamNamespaces sets.String) (objectFilter, error) {
+func testInputImageStreamTagFilterFactory(l *logrus.Entry, ca agents.ConfigAgent, client ctrlruntimeclient.Client, resolver registryResolver, filtersAgent FiltersAgent) (objectFilter, error) {
 	const indexName = "config-by-test-input-imagestreamtag"
 	if err := ca.AddIndex(indexName, indexConfigsByTestInputImageStreamTag(resolver)); err != nil {
 		return nil, fmt.Errorf("failed to add %s index to configAgent: %w", indexName, err)
 	}
 	l = logrus.WithField("subcomponent", "test-input-image-stream-tag-filter")
 	return func(nn types.NamespacedName) bool {
-		if additionalImageStreamTags.Has(nn.String()) {
+		filters := filtersAgent.Filters()
+		if filters.AdditionalImageStreamTags.Has(nn.String()) {
 			return true
 		}
-		if additionalImageStreamNamespaces.Has(nn.Namespace) {
+		if filters.AdditionalImageStreamNamespaces.Has(nn.Namespace) {
 			return true
 		}
 		imageStreamTagResult, err := ca.GetFromIndex(indexName, nn.String())
@@ -437,7 +490,7 @@ func testInputImageStreamTagFilterFactory(l *logrus.Entry, ca agents.ConfigAgent
 			l.WithField("name", nn.String()).WithError(err).Error("Failed to get imagestreamname for imagestreamtag")
 			return false
 		}
-		if additionalImageStreams.Has(imageStreamName.String()) {
+		if filters.AdditionalImageStreams.Has(imageStreamName.String()) {
 			return true
 		}
 		imageStreamResult, err := ca.GetFromIndex(indexName, indexKeyForImageStream(imageStreamName.Namespace, imageStreamName.Name))
@@ -472,6 +525,45 @@ func testInputImageStreamTagFilterFactory(l *logrus.Entry, ca agents.ConfigAgent
 	}, nil
 }
 
+// imageStreamsAddedToFilters returns the imagestreams that are distributed because
+// of current but were not because of previous
+func imageStreamsAddedToFilters(ctx context.Context, client ctrlruntimeclient.Client, previous, current Filters) ([]*imagev1.ImageStream, error) {
+	var result []*imagev1.ImageStream
+	var errs []error
+	for _, namespace := range current.AdditionalImageStreamNamespaces.Difference(previous.AdditionalImageStreamNamespaces).List() {
+		imageStreams := &imagev1.ImageStreamList{}
+		if err := client.List(ctx, imageStreams, ctrlruntimeclient.InNamespace(namespace)); err != nil {
+			errs = append(errs, fmt.Errorf("failed to list imagestreams in namespace %s: %w", namespace, err))
+			continue
+		}
+		for i := range imageStreams.Items {
+			result = append(result, &imageStreams.Items[i])
+		}
+	}
+
+	names := sets.NewString(current.AdditionalImageStreams.Difference(previous.AdditionalImageStreams).UnsortedList()...)
+	for _, tag := range current.AdditionalImageStreamTags.Difference(previous.AdditionalImageStreamTags).UnsortedList() {
+		// Format was validated when loading the filters
+		names.Insert(strings.Split(tag, ":")[0])
+	}
+	for _, name := range names.List() {
+		slashSplit := strings.Split(name, "/")
+		key := types.NamespacedName{Namespace: slashSplit[0], Name: slashSplit[1]}
+		if current.AdditionalImageStreamNamespaces.Has(key.Namespace) {
+			continue
+		}
+		imageStream := &imagev1.ImageStream{}
+		if err := client.Get(ctx, key, imageStream); err != nil {
+			if !apierrors.IsNotFound(err) {
+				errs = append(errs, fmt.Errorf("failed to get imagestream %s: %w", key, err))
+			}
+			continue
+		}
+		result = append(result, imageStream)
+	}
+	return result, utilerrors.NewAggregate(errs)
+}
+
 func imageStreamNameFromImageStreamTagName(nn types.NamespacedName) (types.NamespacedName, error) {
 	colonSplit := strings.Split(nn.Name, ":")
 	if n := len(colonSplit); n != 2 {
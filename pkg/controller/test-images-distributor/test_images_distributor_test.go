@@ -7,6 +7,7 @@ import (
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
@@ -19,6 +20,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
 	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -377,6 +379,7 @@ func TestReconcile(t *testing.T) {
 		registryClient      ctrlruntimeclient.Client
 		buildClusterClients map[string]ctrlruntimeclient.Client
 		verify              func(ctrlruntimeclient.Client, map[string]ctrlruntimeclient.Client, error) error
+		expectedEvents      []string
 	}{
 		{
 			name:                "Request for non existent object doesn't error",
@@ -424,6 +427,7 @@ func TestReconcile(t *testing.T) {
 				}
 				return nil
 			},
+			expectedEvents: []string{"Normal SyncSkipped Not distributing tag Question to cluster 01, because importing registry.build01.ci.openshift.org/ci-op-hbtwhrrm/pipeline@sha256:328d0a90295ef5f5932807bcab8f230007afeb1572d1d7878ab8bdae671dfa8b is forbidden"},
 		},
 		{
 			name: "ImageStreamTag is current, no import created",
@@ -461,6 +465,7 @@ func TestReconcile(t *testing.T) {
 				}
 				return verifyEverythingCreated(bc["01"])
 			},
+			expectedEvents: []string{"Normal ImportTriggered Importing tag Question into cluster 01"},
 		},
 		{
 			name: "Outdated imageStreamtag, pull secret, imagestream, import and rbac are created",
@@ -480,6 +485,7 @@ func TestReconcile(t *testing.T) {
 				}
 				return verifyEverythingCreated(bc["01"])
 			},
+			expectedEvents: []string{"Normal ImportTriggered Importing tag Question into cluster 01"},
 		},
 		{
 			name: "Outdated imageStreamtag and pull secret, pull secret is updated, imagestream import and rbac created",
@@ -500,6 +506,7 @@ func TestReconcile(t *testing.T) {
 				}
 				return verifyEverythingCreated(bc["01"])
 			},
+			expectedEvents: []string{"Normal ImportTriggered Importing tag Question into cluster 01"},
 		},
 		{
 			name: "Outdated imageStreamtag and rbac, rbac updated, imagestream and import created",
@@ -522,6 +529,7 @@ func TestReconcile(t *testing.T) {
 				}
 				return verifyEverythingCreated(bc["01"])
 			},
+			expectedEvents: []string{"Normal ImportTriggered Importing tag Question into cluster 01"},
 		},
 		{
 			name: "Outdated Imagestream is updated, import is created",
@@ -543,6 +551,7 @@ func TestReconcile(t *testing.T) {
 				}
 				return verifyEverythingCreated(bc["01"])
 			},
+			expectedEvents: []string{"Normal ImportTriggered Importing tag Question into cluster 01"},
 		},
 		{
 			name: "Outdated imageStreamtag, import is created",
@@ -564,6 +573,7 @@ func TestReconcile(t *testing.T) {
 				}
 				return verifyEverythingCreated(bc["01"])
 			},
+			expectedEvents: []string{"Normal ImportTriggered Importing tag Question into cluster 01"},
 		},
 		{
 			name: "Outdated imageStreamtag, import is created, failure is returned",
@@ -587,6 +597,10 @@ func TestReconcile(t *testing.T) {
 				}
 				return nil
 			},
+			expectedEvents: []string{
+				"Normal ImportTriggered Importing tag Question into cluster 01",
+				"Warning ImportFailed Failed to import tag Question into cluster 01: reason: , message: failing as requested",
+			},
 		},
 	}
 
@@ -597,15 +611,17 @@ func TestReconcile(t *testing.T) {
 			t.Parallel()
 			log := logrus.NewEntry(logrus.StandardLogger())
 			logrus.SetLevel(logrus.TraceLevel)
+			recorder := record.NewFakeRecorder(10)
 			r := &reconciler{
 				log:                 log,
 				registryClusterName: "app.ci",
 				registryClient:      tc.registryClient,
 				buildClusterClients: tc.buildClusterClients,
-				forbiddenRegistries: sets.NewString("default-route-openshift-image-registry.apps.build01.ci.devcluster.openshift.com",
+				filters: NewStaticFiltersAgent(Filters{ForbiddenRegistries: sets.NewString("default-route-openshift-image-registry.apps.build01.ci.devcluster.openshift.com",
 					"registry.build01.ci.openshift.org",
 					"registry.build02.ci.openshift.org",
-				),
+				)}),
+				recorder: recorder,
 			}
 
 			request := reconcile.Request{NamespacedName: tc.request}
@@ -613,6 +629,14 @@ func TestReconcile(t *testing.T) {
 			if err := tc.verify(r.registryClient, r.buildClusterClients, err); err != nil {
 				t.Errorf("verification failed: %v", err)
 			}
+			close(recorder.Events)
+			var events []string
+			for event := range recorder.Events {
+				events = append(events, event)
+			}
+			if diff := cmp.Diff(tc.expectedEvents, events); diff != "" {
+				t.Errorf("events differ from expected: %s", diff)
+			}
 		})
 	}
 }
@@ -747,9 +771,11 @@ func TestTestInputImageStreamTagFilterFactory(t *testing.T) {
 				configAgent,
 				tc.client,
 				noOpRegistryResolver{},
-				tc.additionalImageStreamTags,
-				tc.additionalImageStreams,
-				tc.additionalImageStreamNamespaces,
+				NewStaticFiltersAgent(Filters{
+					AdditionalImageStreamTags:       tc.additionalImageStreamTags,
+					AdditionalImageStreams:          tc.additionalImageStreams,
+					AdditionalImageStreamNamespaces: tc.additionalImageStreamNamespaces,
+				}),
 			)
 			if err != nil {
 				t.Fatalf("failed to construct filter: %v", err)
@@ -768,3 +794,38 @@ type noOpRegistryResolver struct{}
 func (noOpRegistryResolver) ResolveConfig(cfg api.ReleaseBuildConfiguration) (api.ReleaseBuildConfiguration, error) {
 	return cfg, nil
 }
+
+func TestTagCreationTimestamp(t *testing.T) {
+	created := time.Date(2021, 5, 10, 12, 0, 0, 0, time.UTC)
+	imageStream := &imagev1.ImageStream{
+		Status: imagev1.ImageStreamStatus{Tags: []imagev1.NamedTagEventList{
+			{Tag: "no-history"},
+			{Tag: "no-timestamp", Items: []imagev1.TagEvent{{Image: "sha256:a"}}},
+			{Tag: "latest", Items: []imagev1.TagEvent{
+				{Image: "sha256:b", Created: metav1.NewTime(created)},
+				{Image: "sha256:a", Created: metav1.NewTime(created.Add(-time.Hour))},
+			}},
+		}},
+	}
+	testCases := []struct {
+		tag           string
+		expected      time.Time
+		expectedFound bool
+	}{
+		{tag: "missing"},
+		{tag: "no-history"},
+		{tag: "no-timestamp"},
+		{tag: "latest", expected: created, expectedFound: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.tag, func(t *testing.T) {
+			actual, found := tagCreationTimestamp(imageStream, tc.tag)
+			if found != tc.expectedFound {
+				t.Errorf("expected found to be %t, was %t", tc.expectedFound, found)
+			}
+			if !actual.Equal(tc.expected) {
+				t.Errorf("expected %s, got %s", tc.expected, actual)
+			}
+		})
+	}
+}
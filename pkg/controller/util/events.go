@@ -0,0 +1,42 @@
+package util
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// EventRecorder returns a recorder for the events of the given controller on the cluster
+// of mgr. The recorder does not use the client of the manager, so in dry-run mode the
+// events are only logged.
+func EventRecorder(mgr manager.Manager, controller string, dryRun bool) record.EventRecorder {
+	if dryRun {
+		return &loggingEventRecorder{log: logrus.WithField("controller", controller)}
+	}
+	return mgr.GetEventRecorderFor(controller)
+}
+
+type loggingEventRecorder struct {
+	log *logrus.Entry
+}
+
+func (r *loggingEventRecorder) Event(object runtime.Object, eventtype, reason, message string) {
+	log := r.log.WithFields(logrus.Fields{"type": eventtype, "reason": reason})
+	if accessor, err := meta.Accessor(object); err == nil {
+		log = log.WithFields(logrus.Fields{"namespace": accessor.GetNamespace(), "name": accessor.GetName()})
+	}
+	log.Infof("Not recording event in dry-run mode: %s", message)
+}
+
+func (r *loggingEventRecorder) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	r.Event(object, eventtype, reason, fmt.Sprintf(messageFmt, args...))
+}
+
+func (r *loggingEventRecorder) AnnotatedEventf(object runtime.Object, _ map[string]string, eventtype, reason, messageFmt string, args ...interface{}) {
+	r.Eventf(object, eventtype, reason, messageFmt, args...)
+}
@@ -0,0 +1,56 @@
+package util
+
+import (
+	"github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// LoggerForRequest returns a logger with the standard fields for the given request
+func LoggerForRequest(log *logrus.Entry, req reconcile.Request) *logrus.Entry {
+	return log.WithFields(logrus.Fields{"namespace": req.Namespace, "name": req.Name})
+}
+
+// levelOverrideFormatter drops entries that are more verbose than the level of the controller
+// they belong to. Entries that do not belong to a controller with an override get checked against
+// the default level.
+type levelOverrideFormatter struct {
+	logrus.Formatter
+	defaultLevel logrus.Level
+	overrides    map[string]logrus.Level
+}
+
+func (f *levelOverrideFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	level := f.defaultLevel
+	if controller, ok := entry.Data["controller"].(string); ok {
+		if override, ok := f.overrides[controller]; ok {
+			level = override
+		}
+	}
+	if entry.Level > level {
+		return nil, nil
+	}
+	return f.Formatter.Format(entry)
+}
+
+// SetLogLevels configures the standard logger to log at defaultLevel, except for entries with
+// a controller field that has an override. Logrus only supports a single level per logger, so
+// the level of the logger is set to the most verbose one and entries are filtered when formatting.
+// The formatter must be set up before calling this.
+func SetLogLevels(defaultLevel logrus.Level, overrides map[string]logrus.Level) {
+	mostVerbose := defaultLevel
+	for _, level := range overrides {
+		if level > mostVerbose {
+			mostVerbose = level
+		}
+	}
+	logrus.SetLevel(mostVerbose)
+	if len(overrides) == 0 {
+		return
+	}
+	logrus.SetFormatter(&levelOverrideFormatter{
+		Formatter:    logrus.StandardLogger().Formatter,
+		defaultLevel: defaultLevel,
+		overrides:    overrides,
+	})
+}
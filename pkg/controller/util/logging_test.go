@@ -0,0 +1,46 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestLevelOverrideFormatter(t *testing.T) {
+	formatter := &levelOverrideFormatter{
+		Formatter:    &logrus.TextFormatter{DisableTimestamp: true},
+		defaultLevel: logrus.InfoLevel,
+		overrides:    map[string]logrus.Level{"verbose": logrus.DebugLevel, "quiet": logrus.WarnLevel},
+	}
+	testCases := []struct {
+		name       string
+		controller string
+		level      logrus.Level
+		expected   bool
+	}{
+		{name: "no controller, default level", level: logrus.InfoLevel, expected: true},
+		{name: "no controller, more verbose than default level", level: logrus.DebugLevel},
+		{name: "controller without override", controller: "other", level: logrus.DebugLevel},
+		{name: "verbose controller", controller: "verbose", level: logrus.DebugLevel, expected: true},
+		{name: "verbose controller, too verbose", controller: "verbose", level: logrus.TraceLevel},
+		{name: "quiet controller", controller: "quiet", level: logrus.InfoLevel},
+		{name: "quiet controller, warning", controller: "quiet", level: logrus.WarnLevel, expected: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			entry := logrus.NewEntry(logrus.New())
+			if tc.controller != "" {
+				entry = entry.WithField("controller", tc.controller)
+			}
+			entry.Level = tc.level
+			entry.Message = "message"
+			serialized, err := formatter.Format(entry)
+			if err != nil {
+				t.Fatalf("formatting failed: %v", err)
+			}
+			if logged := len(serialized) > 0; logged != tc.expected {
+				t.Errorf("expected entry to be logged: %t, was logged: %t", tc.expected, logged)
+			}
+		})
+	}
+}
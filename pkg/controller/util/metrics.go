@@ -2,6 +2,8 @@ package util
 
 import (
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 
@@ -18,6 +20,27 @@ var (
 		Name: "imagestream_failed_import_count",
 		Help: "The number of failed imagestream imports the controller create",
 	}, []string{"controller", "cluster", "namespace", "name"})
+
+	importLatencyHistogram = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "imagestream_import_latency_seconds",
+		Help:    "The time between the creation of the source tag and the successful import into the target cluster",
+		Buckets: []float64{30, 60, 120, 300, 600, 900, 1800, 3600, 7200, 21600},
+	}, []string{"controller", "cluster", "namespace"})
+
+	importLatencySLOGoodCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "imagestream_import_latency_slo_good_total",
+		Help: "The number of successful imports that finished within the latency SLO threshold after the source tag was created",
+	}, []string{"controller", "cluster", "namespace"})
+
+	importLatencySLOTotalCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "imagestream_import_latency_slo_total",
+		Help: "The number of successful imports that are accounted for in the latency SLO",
+	}, []string{"controller", "cluster", "namespace"})
+
+	importLatencySLOObjective = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "imagestream_import_latency_slo_objective",
+		Help: "The share of imports that should finish within the latency SLO threshold, the burn rate over a window is 1 - rate(good) / rate(total) divided by 1 - objective",
+	}, []string{"controller"})
 )
 
 // RegisterMetrics Registers metrics
@@ -28,6 +51,18 @@ func RegisterMetrics() error {
 	if err := metrics.Registry.Register(failedImportsCounter); err != nil {
 		return fmt.Errorf("failed to register failedImportsCounter metric: %w", err)
 	}
+	if err := metrics.Registry.Register(importLatencyHistogram); err != nil {
+		return fmt.Errorf("failed to register importLatencyHistogram metric: %w", err)
+	}
+	if err := metrics.Registry.Register(importLatencySLOGoodCounter); err != nil {
+		return fmt.Errorf("failed to register importLatencySLOGoodCounter metric: %w", err)
+	}
+	if err := metrics.Registry.Register(importLatencySLOTotalCounter); err != nil {
+		return fmt.Errorf("failed to register importLatencySLOTotalCounter metric: %w", err)
+	}
+	if err := metrics.Registry.Register(importLatencySLOObjective); err != nil {
+		return fmt.Errorf("failed to register importLatencySLOObjective metric: %w", err)
+	}
 	return nil
 }
 
@@ -39,3 +74,48 @@ func CountImportResult(controllerName, cluster, namespace, name string, successf
 		failedImportsCounter.WithLabelValues(controllerName, cluster, namespace, name).Inc()
 	}
 }
+
+// ImportLatencySLO is the objective for the share of imports that succeed within
+// the threshold after the source tag was created
+type ImportLatencySLO struct {
+	Threshold time.Duration
+	Objective float64
+}
+
+// DefaultImportLatencySLO is used for controllers that have no SLO configured
+var DefaultImportLatencySLO = ImportLatencySLO{Threshold: 10 * time.Minute, Objective: 0.99}
+
+var (
+	sloLock sync.Mutex
+	slos    = map[string]ImportLatencySLO{}
+)
+
+// SetImportLatencySLO configures the import latency SLO of a controller
+func SetImportLatencySLO(controllerName string, slo ImportLatencySLO) {
+	sloLock.Lock()
+	defer sloLock.Unlock()
+	slos[controllerName] = slo
+	importLatencySLOObjective.WithLabelValues(controllerName).Set(slo.Objective)
+}
+
+func sloFor(controllerName string) ImportLatencySLO {
+	sloLock.Lock()
+	defer sloLock.Unlock()
+	if _, ok := slos[controllerName]; !ok {
+		slos[controllerName] = DefaultImportLatencySLO
+		importLatencySLOObjective.WithLabelValues(controllerName).Set(DefaultImportLatencySLO.Objective)
+	}
+	return slos[controllerName]
+}
+
+// ObserveImportLatency records the latency of a successful import of a tag that was
+// created in the source at sourceCreated and whether it was within the SLO. The burn
+// rate is left to the queries, so it can be computed over any window.
+func ObserveImportLatency(controllerName, cluster, namespace string, sourceCreated time.Time) {
+	latency := time.Since(sourceCreated)
+	importLatencyHistogram.WithLabelValues(controllerName, cluster, namespace).Observe(latency.Seconds())
+	importLatencySLOTotalCounter.WithLabelValues(controllerName, cluster, namespace).Inc()
+	if latency <= sloFor(controllerName).Threshold {
+		importLatencySLOGoodCounter.WithLabelValues(controllerName, cluster, namespace).Inc()
+	}
+}
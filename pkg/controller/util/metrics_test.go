@@ -0,0 +1,39 @@
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestObserveImportLatency(t *testing.T) {
+	SetImportLatencySLO("slo-test", ImportLatencySLO{Threshold: 10 * time.Minute, Objective: 0.9})
+
+	ObserveImportLatency("slo-test", "build01", "ci", time.Now().Add(-time.Minute))
+	ObserveImportLatency("slo-test", "build01", "ci", time.Now().Add(-time.Minute))
+	ObserveImportLatency("slo-test", "build01", "ci", time.Now().Add(-time.Hour))
+
+	for _, tc := range []struct {
+		name     string
+		metric   prometheus.Metric
+		expected float64
+	}{
+		{name: "good", metric: importLatencySLOGoodCounter.WithLabelValues("slo-test", "build01", "ci"), expected: 2},
+		{name: "total", metric: importLatencySLOTotalCounter.WithLabelValues("slo-test", "build01", "ci"), expected: 3},
+		{name: "objective", metric: importLatencySLOObjective.WithLabelValues("slo-test"), expected: 0.9},
+	} {
+		metric := &dto.Metric{}
+		if err := tc.metric.Write(metric); err != nil {
+			t.Fatalf("%s: failed to get metric: %v", tc.name, err)
+		}
+		value := metric.GetCounter().GetValue()
+		if metric.Gauge != nil {
+			value = metric.GetGauge().GetValue()
+		}
+		if value != tc.expected {
+			t.Errorf("%s: expected %f, got %f", tc.name, tc.expected, value)
+		}
+	}
+}
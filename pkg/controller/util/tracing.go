@@ -0,0 +1,111 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/api/global"
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp"
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/propagators"
+	sdkresource "go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/semconv"
+
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const tracerName = "github.com/openshift/ci-tools/pkg/controller"
+
+// SetupTracing configures the global tracer provider to export spans via OTLP
+// to the collector at endpoint. The returned func flushes outstanding spans
+// and must be called before exiting.
+func SetupTracing(serviceName, endpoint string) (func(context.Context) error, error) {
+	exporter, err := otlp.NewExporter(otlp.WithAddress(endpoint), otlp.WithInsecure())
+	if err != nil {
+		return nil, fmt.Errorf("failed to construct OTLP exporter for %s: %w", endpoint, err)
+	}
+	processor := sdktrace.NewBatchSpanProcessor(exporter)
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithSpanProcessor(processor),
+		sdktrace.WithResource(sdkresource.New(semconv.ServiceNameKey.String(serviceName))),
+	)
+	global.SetTracerProvider(provider)
+	global.SetTextMapPropagator(otel.NewCompositeTextMapPropagator(propagators.TraceContext{}, propagators.Baggage{}))
+	return func(ctx context.Context) error {
+		processor.Shutdown()
+		return exporter.Shutdown(ctx)
+	}, nil
+}
+
+// StartSpan starts a span that is a child of the span in ctx, if any. Without
+// a call to SetupTracing, this is a no-op.
+func StartSpan(ctx context.Context, name string, attributes ...label.KeyValue) (context.Context, trace.Span) {
+	return global.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attributes...))
+}
+
+// EndSpan records err on span, if any, and ends it
+func EndSpan(ctx context.Context, span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(ctx, err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+type tracingReconciler struct {
+	controller string
+	reconcile.Reconciler
+}
+
+// TraceReconciler wraps a reconciler so that every reconciliation gets a span
+// that the spans of the calls made by it are children of.
+func TraceReconciler(controller string, r reconcile.Reconciler) reconcile.Reconciler {
+	return &tracingReconciler{controller: controller, Reconciler: r}
+}
+
+func (r *tracingReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	ctx, span := StartSpan(ctx, "Reconcile",
+		label.String("controller", r.controller),
+		label.String("namespace", req.Namespace),
+		label.String("name", req.Name),
+	)
+	result, err := r.Reconciler.Reconcile(ctx, req)
+	span.SetAttributes(label.Bool("requeue", result.Requeue || result.RequeueAfter > 0))
+	EndSpan(ctx, span, err)
+	return result, err
+}
+
+type tracingRoundTripper struct {
+	delegate http.RoundTripper
+}
+
+// TracingRoundTripper returns a http.RoundTripper that creates a span for every
+// request and propagates the trace context to the server. It can be passed to
+// rest.Config.Wrap to trace all requests to a cluster, including the ImageStreamImports
+// that make it pull from image registries.
+func TracingRoundTripper(delegate http.RoundTripper) http.RoundTripper {
+	return &tracingRoundTripper{delegate: delegate}
+}
+
+func (t *tracingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, span := global.Tracer(tracerName).Start(req.Context(), fmt.Sprintf("HTTP %s", req.Method),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.HTTPClientAttributesFromHTTPRequest(req)...),
+	)
+	req = req.Clone(ctx)
+	global.TextMapPropagator().Inject(ctx, req.Header)
+	resp, err := t.delegate.RoundTrip(req)
+	if err != nil {
+		EndSpan(ctx, span, err)
+		return resp, err
+	}
+	span.SetAttributes(semconv.HTTPAttributesFromHTTPStatusCode(resp.StatusCode)...)
+	span.SetStatus(semconv.SpanStatusFromHTTPStatusCode(resp.StatusCode))
+	span.End()
+	return resp, nil
+}
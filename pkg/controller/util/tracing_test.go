@@ -0,0 +1,72 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.opentelemetry.io/otel/api/global"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagators"
+	"go.opentelemetry.io/otel/sdk/export/trace/tracetest"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+type reconcilerFunc func(context.Context, reconcile.Request) (reconcile.Result, error)
+
+func (f reconcilerFunc) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	return f(ctx, req)
+}
+
+func TestTraceReconciler(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	global.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	global.SetTextMapPropagator(propagators.TraceContext{})
+
+	var traceParent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceParent = r.Header.Get("traceparent")
+	}))
+	defer server.Close()
+	client := &http.Client{Transport: TracingRoundTripper(http.DefaultTransport)}
+
+	r := TraceReconciler("controller", reconcilerFunc(func(ctx context.Context, _ reconcile.Request) (reconcile.Result, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		if err != nil {
+			t.Fatalf("failed to construct request: %v", err)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		resp.Body.Close()
+		return reconcile.Result{}, errors.New("failure")
+	}))
+	if _, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "name"}}); err == nil {
+		t.Fatal("expected error from the reconciler to be returned")
+	}
+
+	spans := exporter.GetSpans()
+	if n := len(spans); n != 2 {
+		t.Fatalf("expected two spans, got %d", n)
+	}
+	request, reconciliation := spans[0], spans[1]
+	if diff := cmp.Diff([]string{"HTTP GET", "Reconcile"}, []string{request.Name, reconciliation.Name}); diff != "" {
+		t.Errorf("span names differ from expected: %s", diff)
+	}
+	if request.ParentSpanID != reconciliation.SpanContext.SpanID {
+		t.Error("expected the request span to be a child of the reconcile span")
+	}
+	if reconciliation.StatusCode != codes.Error {
+		t.Errorf("expected the reconcile span to have an error status, got %v", reconciliation.StatusCode)
+	}
+	if traceParent == "" {
+		t.Error("expected the trace context to be propagated to the server")
+	}
+}
@@ -34,6 +34,7 @@ import (
 	"github.com/openshift/ci-tools/pkg/results"
 	"github.com/openshift/ci-tools/pkg/secrets"
 	"github.com/openshift/ci-tools/pkg/steps"
+	"github.com/openshift/ci-tools/pkg/steps/architectureclient"
 	"github.com/openshift/ci-tools/pkg/steps/clusterinstall"
 	"github.com/openshift/ci-tools/pkg/steps/loggingclient"
 	releasesteps "github.com/openshift/ci-tools/pkg/steps/release"
@@ -62,18 +63,26 @@ func FromConfig(
 	pullSecret, pushSecret *coreapi.Secret,
 	censor *secrets.DynamicCensor,
 	hiveKubeconfig *rest.Config,
+	localBuilds bool,
+	nodeArchitecture api.ReleaseArchitecture,
 ) ([]api.Step, []api.Step, error) {
 	crclient, err := ctrlruntimeclient.NewWithWatch(clusterConfig, ctrlruntimeclient.Options{})
 	crclient = secretrecordingclient.Wrap(crclient, censor)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to construct client: %w", err)
 	}
+	if nodeArchitecture != "" {
+		crclient = architectureclient.Wrap(crclient, nodeArchitecture)
+	}
 	client := loggingclient.New(crclient)
 	buildGetter, err := buildclientset.NewForConfig(clusterConfig)
 	if err != nil {
 		return nil, nil, fmt.Errorf("could not get build client for cluster config: %w", err)
 	}
-	buildClient := steps.NewBuildClient(client, buildGetter.RESTClient())
+	buildClient := steps.NewBuildClient(client, buildGetter.RESTClient(), censor)
+	if localBuilds {
+		buildClient = steps.NewLocalBuildClient(client, censor)
+	}
 
 	templateGetter, err := templateclientset.NewForConfig(clusterConfig)
 	if err != nil {
@@ -86,7 +95,7 @@ func FromConfig(
 		return nil, nil, fmt.Errorf("could not get core client for cluster config: %w", err)
 	}
 
-	podClient := steps.NewPodClient(client, clusterConfig, coreGetter.RESTClient())
+	podClient := steps.NewPodClient(client, clusterConfig, coreGetter.RESTClient(), censor)
 
 	var hiveClient ctrlruntimeclient.Client
 	if hiveKubeconfig != nil {
@@ -133,6 +142,7 @@ func fromConfig(
 	// A pointer to a slice of pointers is weird, but necessary. Since the slice mutates inside of the other functions,
 	// we need to pass the pointer - otherwise we will lose the updates after leaving the function scope.
 	imageConfigs := &[]*api.InputImageTagStepConfiguration{}
+	outputImageTagger := steps.NewOutputImageTagger(client, steps.DefaultOutputImageTagParallelism)
 	resolver := rootImageResolver(client, ctx)
 	rawSteps, err := stepConfigsForBuild(config, jobSpec, ioutil.ReadFile, resolver, imageConfigs)
 	if err != nil {
@@ -140,6 +150,9 @@ func fromConfig(
 	}
 	for _, rawStep := range rawSteps {
 		if testStep := rawStep.TestStepConfiguration; testStep != nil {
+			if testStep.SourceSnapshot != "" {
+				testStep = withSourceSnapshot(testStep)
+			}
 			steps, err := stepForTest(config, params, podClient, leaseClient, templateClient, client, hiveClient, jobSpec, inputImages, testStep, imageConfigs)
 			if err != nil {
 				return nil, nil, err
@@ -192,6 +205,8 @@ func fromConfig(
 
 			step = steps.InputImageTagStep(&conf, client, jobSpec)
 			inputImages[conf.InputImage] = struct{}{}
+		} else if rawStep.ExternalImageImportStepConfiguration != nil {
+			step = steps.ExternalImageImportStep(*rawStep.ExternalImageImportStepConfiguration, client, jobSpec)
 		} else if rawStep.PipelineImageCacheStepConfiguration != nil {
 			step = steps.PipelineImageCacheStep(*rawStep.PipelineImageCacheStepConfiguration, config.Resources, buildClient, jobSpec, pullSecret)
 		} else if rawStep.SourceStepConfiguration != nil {
@@ -200,16 +215,24 @@ func fromConfig(
 			step = steps.BundleSourceStep(*rawStep.BundleSourceStepConfiguration, config, config.Resources, buildClient, jobSpec, pullSecret)
 		} else if rawStep.IndexGeneratorStepConfiguration != nil {
 			step = steps.IndexGeneratorStep(*rawStep.IndexGeneratorStepConfiguration, config, config.Resources, buildClient, jobSpec, pullSecret)
+			step = steps.TimeoutStep(rawStep.IndexGeneratorStepConfiguration.StepTimeouts, step)
 		} else if rawStep.ProjectDirectoryImageBuildStepConfiguration != nil {
-			step = steps.ProjectDirectoryImageBuildStep(*rawStep.ProjectDirectoryImageBuildStepConfiguration, config, config.Resources, podClient, buildClient, jobSpec, pullSecret)
+			imageBuildClient := buildClient
+			if rawStep.ProjectDirectoryImageBuildStepConfiguration.BuildBackend == api.BuildBackendBuildahPod {
+				imageBuildClient = steps.NewBuildahPodBuildClient(podClient)
+			}
+			step = steps.ProjectDirectoryImageBuildStep(*rawStep.ProjectDirectoryImageBuildStepConfiguration, config, config.Resources, podClient, imageBuildClient, podClient, jobSpec, pullSecret)
+			step = steps.TimeoutStep(rawStep.ProjectDirectoryImageBuildStepConfiguration.StepTimeouts, step)
 		} else if rawStep.ProjectDirectoryImageBuildInputs != nil {
 			step = steps.GitSourceStep(*rawStep.ProjectDirectoryImageBuildInputs, config.Resources, buildClient, jobSpec, cloneAuthConfig, pullSecret)
 		} else if rawStep.RPMImageInjectionStepConfiguration != nil {
 			step = steps.RPMImageInjectionStep(*rawStep.RPMImageInjectionStepConfiguration, config.Resources, buildClient, jobSpec, pullSecret)
+		} else if rawStep.ImageScanStepConfiguration != nil {
+			step = steps.ImageScanStep(*rawStep.ImageScanStepConfiguration, config.Resources, podClient, jobSpec)
 		} else if rawStep.RPMServeStepConfiguration != nil {
 			step = steps.RPMServerStep(*rawStep.RPMServeStepConfiguration, client, jobSpec)
 		} else if rawStep.OutputImageTagStepConfiguration != nil {
-			step = steps.OutputImageTagStep(*rawStep.OutputImageTagStepConfiguration, client, jobSpec)
+			step = steps.OutputImageTagStep(*rawStep.OutputImageTagStepConfiguration, client, jobSpec, outputImageTagger)
 			// all required or non-optional output images are considered part of [images]
 			if requiredNames.Has(string(rawStep.OutputImageTagStepConfiguration.From)) || !rawStep.OutputImageTagStepConfiguration.Optional {
 				stepLinks = append(stepLinks, step.Creates()...)
@@ -252,8 +275,13 @@ func fromConfig(
 		overridableSteps = append(overridableSteps, step)
 	}
 
+	// templates are named after the test they run
+	profiles := map[string]api.ClusterProfile{}
+	for _, test := range config.Tests {
+		profiles[test.As] = test.GetClusterProfile()
+	}
 	for _, template := range templates {
-		step := steps.TemplateExecutionStep(template, params, podClient, templateClient, jobSpec, config.Resources)
+		step := steps.TemplateExecutionStep(template, params, podClient, templateClient, jobSpec, config.Resources, profiles[template.Name])
 		var hasClusterType, hasUseLease bool
 		for _, p := range template.Parameters {
 			hasClusterType = hasClusterType || p.Name == "CLUSTER_TYPE"
@@ -333,12 +361,18 @@ func stepForTest(
 		}
 		step := steps.MultiStageTestStep(*c, config, params, podClient, jobSpec, leases)
 		if len(leases) != 0 {
-			step = steps.LeaseStep(leaseClient, leases, step, jobSpec.Namespace)
+			if len(test.FallbackClusterProfiles) != 0 {
+				profiles := append([]api.ClusterProfile{test.ClusterProfile}, test.FallbackClusterProfiles...)
+				step = steps.ClusterProfileLeaseStep(leaseClient, profiles, leases, step, jobSpec.Namespace)
+			} else {
+				step = steps.LeaseStep(leaseClient, leases, step, jobSpec.Namespace)
+			}
 			addProvidesForStep(step, params)
 		}
 		if c.ClusterClaim != nil {
 			step = steps.ClusterClaimStep(c.As, c.ClusterClaim, hiveClient, client, jobSpec, step)
 		}
+		step = steps.TimeoutStep(c.StepTimeouts, step)
 		newSteps := stepsForStepImages(client, jobSpec, inputImages, test, imageConfigs)
 		return append([]api.Step{step}, newSteps...), nil
 	}
@@ -357,13 +391,64 @@ func stepForTest(
 			Count:        1,
 		}}, step, jobSpec.Namespace)
 		addProvidesForStep(step, params)
-		return []api.Step{step}, nil
+		return []api.Step{steps.TimeoutStep(c.StepTimeouts, step)}, nil
+	}
+	if len(c.Leases) != 0 {
+		params = api.NewDeferredParameters(params)
+	}
+	step := steps.TestStep(*c, config.Resources, podClient, jobSpec, params)
+	if len(c.Leases) != 0 {
+		step = steps.LeaseStep(leaseClient, c.Leases, step, jobSpec.Namespace)
+		addProvidesForStep(step, params)
 	}
-	step := steps.TestStep(*c, config.Resources, podClient, jobSpec)
 	if c.ClusterClaim != nil {
 		step = steps.ClusterClaimStep(c.As, c.ClusterClaim, hiveClient, client, jobSpec, step)
 	}
-	return []api.Step{step}, nil
+	return []api.Step{steps.TimeoutStep(c.StepTimeouts, step)}, nil
+}
+
+// withSourceSnapshot returns a copy of the test that uses the image of its
+// source snapshot wherever it would use "src".
+func withSourceSnapshot(test *api.TestStepConfiguration) *api.TestStepConfiguration {
+	snapshot := api.SourceSnapshotName(test.SourceSnapshot)
+	replace := func(image string) string {
+		switch image {
+		case string(api.PipelineImageStreamTagReferenceSource):
+			return string(snapshot)
+		case fmt.Sprintf("%s:%s", api.PipelineImageStream, api.PipelineImageStreamTagReferenceSource):
+			return fmt.Sprintf("%s:%s", api.PipelineImageStream, snapshot)
+		}
+		return image
+	}
+	replaceInSteps := func(steps []api.LiteralTestStep) []api.LiteralTestStep {
+		var replaced []api.LiteralTestStep
+		for _, step := range steps {
+			step.From = replace(step.From)
+			var dependencies []api.StepDependency
+			for _, dependency := range step.Dependencies {
+				dependency.Name = replace(dependency.Name)
+				dependencies = append(dependencies, dependency)
+			}
+			step.Dependencies = dependencies
+			replaced = append(replaced, step)
+		}
+		return replaced
+	}
+
+	copied := *test
+	if container := test.ContainerTestConfiguration; container != nil {
+		c := *container
+		c.From = api.PipelineImageStreamTagReference(replace(string(c.From)))
+		copied.ContainerTestConfiguration = &c
+	}
+	if literal := test.MultiStageTestConfigurationLiteral; literal != nil {
+		l := *literal
+		l.Pre = replaceInSteps(l.Pre)
+		l.Test = replaceInSteps(l.Test)
+		l.Post = replaceInSteps(l.Post)
+		copied.MultiStageTestConfigurationLiteral = &l
+	}
+	return &copied
 }
 
 // stepsForStepImages creates steps that import images referenced in test steps.
@@ -437,7 +522,7 @@ func checkForFullyQualifiedStep(step api.Step, params *api.DeferredParameters) (
 
 // leasesForTest aggregates all the lease configurations in a test.
 // It is assumed that they have been validated and contain only valid and
-// unique values.
+// unique values. The lease of the cluster profile comes first.
 func leasesForTest(s *api.MultiStageTestConfigurationLiteral) (ret []api.StepLease) {
 	if p := s.ClusterProfile; p != "" {
 		ret = append(ret, api.StepLease{
@@ -557,20 +642,34 @@ func stepConfigsForBuild(
 		}
 	}
 
-	if jobSpec.Refs != nil || len(jobSpec.ExtraRefs) > 0 {
+	clonerefsImage := api.ImageStreamTagReference{
+		Namespace: "ci",
+		Name:      "managed-clonerefs",
+		Tag:       "latest",
+	}
+	if jobSpec.Refs != nil || len(jobSpec.ExtraRefs) > 0 || config.SourceArchive != nil {
 		step := api.StepConfiguration{SourceStepConfiguration: &api.SourceStepConfiguration{
-			From: api.PipelineImageStreamTagReferenceRoot,
-			To:   api.PipelineImageStreamTagReferenceSource,
-			ClonerefsImage: api.ImageStreamTagReference{
-				Namespace: "ci",
-				Name:      "managed-clonerefs",
-				Tag:       "latest",
-			},
-			ClonerefsPath: "/clonerefs",
+			From:           api.PipelineImageStreamTagReferenceRoot,
+			To:             api.PipelineImageStreamTagReferenceSource,
+			ClonerefsImage: clonerefsImage,
+			ClonerefsPath:  "/clonerefs",
+			SourceArchive:  config.SourceArchive,
+			Clone:          config.Clone,
 		}}
 		buildSteps = append(buildSteps, step)
 	}
 
+	for _, snapshot := range config.SourceSnapshots {
+		buildSteps = append(buildSteps, api.StepConfiguration{SourceStepConfiguration: &api.SourceStepConfiguration{
+			From:           api.PipelineImageStreamTagReferenceRoot,
+			To:             api.SourceSnapshotName(snapshot.As),
+			ClonerefsImage: clonerefsImage,
+			ClonerefsPath:  "/clonerefs",
+			ExtraRefs:      snapshot.Refs,
+			Clone:          config.Clone,
+		}})
+	}
+
 	if len(config.BinaryBuildCommands) > 0 {
 		buildSteps = append(buildSteps, api.StepConfiguration{PipelineImageCacheStepConfiguration: &api.PipelineImageCacheStepConfiguration{
 			From:     api.PipelineImageStreamTagReferenceSource,
@@ -609,7 +708,9 @@ func stepConfigsForBuild(
 		}})
 
 		buildSteps = append(buildSteps, api.StepConfiguration{RPMServeStepConfiguration: &api.RPMServeStepConfiguration{
-			From: api.PipelineImageStreamTagReferenceRPMs,
+			From:          api.PipelineImageStreamTagReferenceRPMs,
+			Architectures: config.RpmBuildArchitectures,
+			Signing:       config.RpmSigning,
 		}})
 	}
 
@@ -626,6 +727,7 @@ func stepConfigsForBuild(
 		*imageConfigs = append(*imageConfigs, &config)
 	}
 
+	perArchitecture, signed := len(config.RpmBuildArchitectures) > 0, config.RpmSigning != nil
 	for alias, target := range config.InputConfiguration.BaseRPMImages {
 		intermediateTag := api.PipelineImageStreamTagReference(fmt.Sprintf("%s-without-rpms", alias))
 		config := api.InputImageTagStepConfiguration{
@@ -639,8 +741,17 @@ func stepConfigsForBuild(
 		*imageConfigs = append(*imageConfigs, &config)
 
 		buildSteps = append(buildSteps, api.StepConfiguration{RPMImageInjectionStepConfiguration: &api.RPMImageInjectionStepConfiguration{
-			From: intermediateTag,
-			To:   api.PipelineImageStreamTagReference(alias),
+			From:            intermediateTag,
+			To:              api.PipelineImageStreamTagReference(alias),
+			PerArchitecture: perArchitecture,
+			Signed:          signed,
+		}})
+	}
+
+	for alias, image := range config.InputConfiguration.ExternalImages {
+		buildSteps = append(buildSteps, api.StepConfiguration{ExternalImageImportStepConfiguration: &api.ExternalImageImportStepConfiguration{
+			ExternalImage: image,
+			To:            api.PipelineImageStreamTagReference(alias),
 		}})
 	}
 
@@ -656,6 +767,16 @@ func stepConfigsForBuild(
 				},
 				Optional: image.Optional,
 			}})
+		for _, name := range image.AdditionalNames {
+			buildSteps = append(buildSteps, api.StepConfiguration{OutputImageTagStepConfiguration: &api.OutputImageTagStepConfiguration{
+				From: image.To,
+				To: api.ImageStreamTagReference{
+					Name: api.StableImageStream,
+					Tag:  name,
+				},
+				Optional: image.Optional,
+			}})
+		}
 	}
 
 	if config.Operator != nil {
@@ -691,6 +812,9 @@ func stepConfigsForBuild(
 				OperatorIndex: []string{bundleConfig.As},
 				BaseIndex:     bundleConfig.BaseIndex,
 				UpdateGraph:   updateGraph,
+				IndexFormat:   bundleConfig.IndexFormat,
+				OPMImage:      bundleConfig.OPMImage,
+				StepTimeouts:  bundleConfig.StepTimeouts,
 			}})
 			// Build the index
 			index := &api.ProjectDirectoryImageBuildStepConfiguration{
@@ -698,6 +822,7 @@ func stepConfigsForBuild(
 				ProjectDirectoryImageBuildInputs: api.ProjectDirectoryImageBuildInputs{
 					DockerfilePath: steps.IndexDockerfileName,
 				},
+				StepTimeouts: bundleConfig.StepTimeouts,
 			}
 			buildSteps = append(buildSteps, api.StepConfiguration{ProjectDirectoryImageBuildStepConfiguration: index})
 		}
@@ -744,6 +869,10 @@ func stepConfigsForBuild(
 		}
 	}
 
+	for i := range config.ImageScans {
+		buildSteps = append(buildSteps, api.StepConfiguration{ImageScanStepConfiguration: &config.ImageScans[i]})
+	}
+
 	if config.ReleaseTagConfiguration != nil {
 		buildSteps = append(buildSteps, api.StepConfiguration{ReleaseImagesTagStepConfiguration: config.ReleaseTagConfiguration})
 	}
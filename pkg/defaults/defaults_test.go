@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"sort"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 
@@ -90,6 +91,111 @@ func TestStepConfigsForBuild(t *testing.T) {
 				},
 			}},
 		},
+		{
+			name: "source snapshot",
+			input: &api.ReleaseBuildConfiguration{
+				InputConfiguration: api.InputConfiguration{
+					BuildRootImage: &api.BuildRootImageConfiguration{
+						ImageStreamTagReference: &api.ImageStreamTagReference{
+							Namespace: "root-ns",
+							Name:      "root-name",
+							Tag:       "manual",
+						},
+					},
+				},
+				SourceSnapshots: []api.SourceSnapshot{{
+					As:   "other-pr",
+					Refs: []prowapi.Refs{{Org: "other-org", Repo: "other-repo", BaseRef: "main", Pulls: []prowapi.Pull{{Number: 42}}}},
+				}},
+			},
+			jobSpec: &api.JobSpec{
+				JobSpec: downwardapi.JobSpec{
+					Refs: &prowapi.Refs{
+						Org:  "org",
+						Repo: "repo",
+					},
+				},
+			},
+			resolver: noopResolver,
+			output: []api.StepConfiguration{{
+				SourceStepConfiguration: addCloneRefs(&api.SourceStepConfiguration{
+					From: api.PipelineImageStreamTagReferenceRoot,
+					To:   api.PipelineImageStreamTagReferenceSource,
+				}),
+			}, {
+				SourceStepConfiguration: addCloneRefs(&api.SourceStepConfiguration{
+					From:      api.PipelineImageStreamTagReferenceRoot,
+					To:        "src-other-pr",
+					ExtraRefs: []prowapi.Refs{{Org: "other-org", Repo: "other-repo", BaseRef: "main", Pulls: []prowapi.Pull{{Number: 42}}}},
+				}),
+			}, {
+				InputImageTagStepConfiguration: &api.InputImageTagStepConfiguration{
+					InputImage: api.InputImage{
+						BaseImage: api.ImageStreamTagReference{
+							Namespace: "root-ns",
+							Name:      "root-name",
+							Tag:       "manual",
+						},
+						To: api.PipelineImageStreamTagReferenceRoot,
+					},
+					Sources: []api.ImageStreamSource{{SourceType: api.ImageStreamSourceRoot}},
+				},
+			}},
+		},
+		{
+			name: "image scan",
+			input: &api.ReleaseBuildConfiguration{
+				InputConfiguration: api.InputConfiguration{
+					BuildRootImage: &api.BuildRootImageConfiguration{
+						ImageStreamTagReference: &api.ImageStreamTagReference{
+							Namespace: "root-ns",
+							Name:      "root-name",
+							Tag:       "manual",
+						},
+					},
+				},
+				ImageScans: []api.ImageScanStepConfiguration{{
+					As:       "cves",
+					Images:   []api.PipelineImageStreamTagReference{api.PipelineImageStreamTagReferenceSource},
+					Scanner:  "quay.io/anchore/grype",
+					Commands: "grype ${IMAGE}",
+				}},
+			},
+			jobSpec: &api.JobSpec{
+				JobSpec: downwardapi.JobSpec{
+					Refs: &prowapi.Refs{
+						Org:  "org",
+						Repo: "repo",
+					},
+				},
+			},
+			resolver: noopResolver,
+			output: []api.StepConfiguration{{
+				SourceStepConfiguration: addCloneRefs(&api.SourceStepConfiguration{
+					From: api.PipelineImageStreamTagReferenceRoot,
+					To:   api.PipelineImageStreamTagReferenceSource,
+				}),
+			}, {
+				InputImageTagStepConfiguration: &api.InputImageTagStepConfiguration{
+					InputImage: api.InputImage{
+						BaseImage: api.ImageStreamTagReference{
+							Namespace: "root-ns",
+							Name:      "root-name",
+							Tag:       "manual",
+						},
+						To: api.PipelineImageStreamTagReferenceRoot,
+					},
+					Sources: []api.ImageStreamSource{{SourceType: api.ImageStreamSourceRoot}},
+				},
+			}, {
+				ImageScanStepConfiguration: &api.ImageScanStepConfiguration{
+					As:       "cves",
+					Images:   []api.PipelineImageStreamTagReference{api.PipelineImageStreamTagReferenceSource},
+					Scanner:  "quay.io/anchore/grype",
+					Commands: "grype ${IMAGE}",
+				},
+			}},
+		},
 		{
 			name: "minimal information provided with build cache in use",
 			input: &api.ReleaseBuildConfiguration{
@@ -340,6 +446,62 @@ func TestStepConfigsForBuild(t *testing.T) {
 				},
 			}},
 		},
+		{
+			name: "rpm build for multiple architectures with signed metadata",
+			input: &api.ReleaseBuildConfiguration{
+				InputConfiguration: api.InputConfiguration{
+					BuildRootImage: &api.BuildRootImageConfiguration{
+						ImageStreamTagReference: &api.ImageStreamTagReference{
+							Namespace: "root-ns",
+							Name:      "root-name",
+							Tag:       "manual",
+						},
+					},
+				},
+				RpmBuildCommands:      "hello",
+				RpmBuildArchitectures: []api.ReleaseArchitecture{api.ReleaseArchitectureAMD64, api.ReleaseArchitectureARM64},
+				RpmSigning:            &api.RPMSigningConfiguration{Namespace: "ci", Name: "rpm-signing", Key: "private.key"},
+			},
+			jobSpec: &api.JobSpec{
+				JobSpec: downwardapi.JobSpec{
+					Refs: &prowapi.Refs{
+						Org:  "org",
+						Repo: "repo",
+					},
+				},
+			},
+			resolver: noopResolver,
+			output: []api.StepConfiguration{{
+				SourceStepConfiguration: addCloneRefs(&api.SourceStepConfiguration{
+					From: api.PipelineImageStreamTagReferenceRoot,
+					To:   api.PipelineImageStreamTagReferenceSource,
+				}),
+			}, {
+				InputImageTagStepConfiguration: &api.InputImageTagStepConfiguration{
+					InputImage: api.InputImage{
+						BaseImage: api.ImageStreamTagReference{
+							Namespace: "root-ns",
+							Name:      "root-name",
+							Tag:       "manual",
+						},
+						To: api.PipelineImageStreamTagReferenceRoot,
+					},
+					Sources: []api.ImageStreamSource{{SourceType: api.ImageStreamSourceRoot}},
+				},
+			}, {
+				PipelineImageCacheStepConfiguration: &api.PipelineImageCacheStepConfiguration{
+					From:     api.PipelineImageStreamTagReferenceSource,
+					To:       api.PipelineImageStreamTagReferenceRPMs,
+					Commands: "hello; ln -s $( pwd )/_output/local/releases/rpms/ /srv/repo",
+				},
+			}, {
+				RPMServeStepConfiguration: &api.RPMServeStepConfiguration{
+					From:          api.PipelineImageStreamTagReferenceRPMs,
+					Architectures: []api.ReleaseArchitecture{api.ReleaseArchitectureAMD64, api.ReleaseArchitectureARM64},
+					Signing:       &api.RPMSigningConfiguration{Namespace: "ci", Name: "rpm-signing", Key: "private.key"},
+				},
+			}},
+		},
 		{
 			name: "rpm with custom output but not binary build requested",
 			input: &api.ReleaseBuildConfiguration{
@@ -593,6 +755,63 @@ func TestStepConfigsForBuild(t *testing.T) {
 				},
 			}},
 		},
+		{
+			name: "external images requested",
+			input: &api.ReleaseBuildConfiguration{
+				InputConfiguration: api.InputConfiguration{
+					BuildRootImage: &api.BuildRootImageConfiguration{
+						ImageStreamTagReference: &api.ImageStreamTagReference{
+							Namespace: "root-ns",
+							Name:      "root-name",
+							Tag:       "manual",
+						},
+					},
+					ExternalImages: map[string]api.ExternalImage{
+						"pinned": {
+							PullSpec: "quay.io/org/pinned@sha256:47e2f82dbede8ff990e6e240f82d78830e7558f7b30df7bd8c0693992018b1e3",
+							Policy:   api.ExternalImagePolicyPin,
+						},
+						"tracked": {
+							PullSpec: "quay.io/org/tracked:latest",
+							Policy:   api.ExternalImagePolicyTrack,
+							Refresh:  &prowapi.Duration{Duration: time.Hour},
+						},
+					},
+				},
+			},
+			jobSpec:  &api.JobSpec{},
+			resolver: noopResolver,
+			output: []api.StepConfiguration{{
+				InputImageTagStepConfiguration: &api.InputImageTagStepConfiguration{
+					InputImage: api.InputImage{
+						BaseImage: api.ImageStreamTagReference{
+							Namespace: "root-ns",
+							Name:      "root-name",
+							Tag:       "manual",
+						},
+						To: api.PipelineImageStreamTagReferenceRoot,
+					},
+					Sources: []api.ImageStreamSource{{SourceType: api.ImageStreamSourceRoot}},
+				},
+			}, {
+				ExternalImageImportStepConfiguration: &api.ExternalImageImportStepConfiguration{
+					ExternalImage: api.ExternalImage{
+						PullSpec: "quay.io/org/pinned@sha256:47e2f82dbede8ff990e6e240f82d78830e7558f7b30df7bd8c0693992018b1e3",
+						Policy:   api.ExternalImagePolicyPin,
+					},
+					To: "pinned",
+				},
+			}, {
+				ExternalImageImportStepConfiguration: &api.ExternalImageImportStepConfiguration{
+					ExternalImage: api.ExternalImage{
+						PullSpec: "quay.io/org/tracked:latest",
+						Policy:   api.ExternalImagePolicyTrack,
+						Refresh:  &prowapi.Duration{Duration: time.Hour},
+					},
+					To: "tracked",
+				},
+			}},
+		},
 		{
 			name: "including an operator bundle creates the bundle-sub and the index-gen and index images",
 			input: &api.ReleaseBuildConfiguration{
@@ -787,6 +1006,48 @@ func sortStepConfig(in []api.StepConfiguration) []api.StepConfiguration {
 	return in
 }
 
+func TestWithSourceSnapshot(t *testing.T) {
+	test := &api.TestStepConfiguration{
+		As:             "e2e",
+		SourceSnapshot: "other-pr",
+		MultiStageTestConfigurationLiteral: &api.MultiStageTestConfigurationLiteral{
+			Pre: []api.LiteralTestStep{{As: "setup", From: "cli"}},
+			Test: []api.LiteralTestStep{{
+				As:           "test",
+				From:         "src",
+				Dependencies: []api.StepDependency{{Name: "pipeline:src", Env: "SRC"}, {Name: "bin", Env: "BIN"}},
+			}},
+		},
+	}
+	expected := &api.TestStepConfiguration{
+		As:             "e2e",
+		SourceSnapshot: "other-pr",
+		MultiStageTestConfigurationLiteral: &api.MultiStageTestConfigurationLiteral{
+			Pre: []api.LiteralTestStep{{As: "setup", From: "cli"}},
+			Test: []api.LiteralTestStep{{
+				As:           "test",
+				From:         "src-other-pr",
+				Dependencies: []api.StepDependency{{Name: "pipeline:src-other-pr", Env: "SRC"}, {Name: "bin", Env: "BIN"}},
+			}},
+		},
+	}
+	if diff := cmp.Diff(expected, withSourceSnapshot(test)); diff != "" {
+		t.Errorf("test differs from expected: %s", diff)
+	}
+	if test.MultiStageTestConfigurationLiteral.Test[0].From != "src" {
+		t.Errorf("original test was modified")
+	}
+
+	container := &api.TestStepConfiguration{
+		As:                         "unit",
+		SourceSnapshot:             "other-pr",
+		ContainerTestConfiguration: &api.ContainerTestConfiguration{From: "src"},
+	}
+	if actual := withSourceSnapshot(container).ContainerTestConfiguration.From; actual != "src-other-pr" {
+		t.Errorf("expected container test to use src-other-pr, got %s", actual)
+	}
+}
+
 type environmentOverride struct {
 	m map[string]string
 }
@@ -854,9 +1115,9 @@ func TestFromConfig(t *testing.T) {
 			t.Fatal(err)
 		}
 	}
-	buildClient := steps.NewBuildClient(client, nil)
+	buildClient := steps.NewBuildClient(client, nil, nil)
 	var templateClient steps.TemplateClient
-	podClient := steps.NewPodClient(client, nil, nil)
+	podClient := steps.NewPodClient(client, nil, nil, nil)
 	hiveClient := fakectrlruntimeclient.NewFakeClient()
 	var leaseClient *lease.Client
 	var requiredTargets []string
@@ -1076,6 +1337,16 @@ func TestFromConfig(t *testing.T) {
 			}},
 		},
 		expectedSteps: []string{"test", "[output-images]", "[images]"},
+	}, {
+		name: "container test with a lease",
+		config: api.ReleaseBuildConfiguration{
+			Tests: []api.TestStepConfiguration{{
+				As:                         "test",
+				ContainerTestConfiguration: &api.ContainerTestConfiguration{},
+				Leases:                     []api.StepLease{{ResourceType: "aws-quota-slice", Env: "ACCOUNT", Count: 1}},
+			}},
+		},
+		expectedSteps: []string{"test", "[output-images]", "[images]"},
 	}, {
 		name: "openshift-installer test",
 		config: api.ReleaseBuildConfiguration{
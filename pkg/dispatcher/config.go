@@ -34,6 +34,9 @@ type Config struct {
 	SSHBastion api.Cluster `json:"sshBastion"`
 	// the cluster names for kvm jobs
 	KVM []api.Cluster `json:"kvm"`
+	// Architectures maps an architecture to the clusters with nodes of it, for the jobs
+	// of tests on an additional architecture
+	Architectures map[api.ReleaseArchitecture][]api.Cluster `json:"architectures,omitempty"`
 	// Groups maps a group of jobs to a cluster
 	Groups JobGroups `json:"groups"`
 	// BuildFarm maps groups of jobs to a cloud provider, like GCP
@@ -83,6 +86,13 @@ func (config *Config) DetermineClusterForJob(jobBase prowconfig.JobBase, path st
 		return config.SSHBastion, false, nil
 	}
 	if jobBase.Labels != nil {
+		if architecture, ok := jobBase.Labels[api.ArchitectureLabel]; ok {
+			clusters := config.Architectures[api.ReleaseArchitecture(architecture)]
+			if len(clusters) == 0 {
+				return "", false, fmt.Errorf("job %s runs on the architecture %s, but no cluster is configured for it", jobBase.Name, architecture)
+			}
+			return clusters[len(jobBase.Name)%len(clusters)], false, nil
+		}
 		if _, ok := jobBase.Labels[api.KVMDeviceLabel]; ok && len(config.KVM) > 0 {
 			// Any deterministic distribution is fine for now.
 			// We could implement more effective distribution when we understand more about the jobs.
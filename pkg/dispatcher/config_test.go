@@ -412,6 +412,23 @@ func TestDetermineClusterForJob(t *testing.T) {
 			expected:               "b01",
 			expectedCanBeRelocated: false,
 		},
+		{
+			name:   "a job on an additional architecture",
+			config: &Config{Default: "api.ci", Architectures: map[api.ReleaseArchitecture][]api.Cluster{api.ReleaseArchitectureARM64: {"arm01"}}},
+			jobBase: config.JobBase{Agent: "kubernetes", Name: "pull-ci-openshift-os-master-unit-arm64",
+				Labels: map[string]string{"ci-operator.openshift.io/architecture": "arm64", "ci-operator.openshift.io/cluster": "b01"},
+			},
+			expected:               "arm01",
+			expectedCanBeRelocated: false,
+		},
+		{
+			name:   "a job on an architecture without clusters",
+			config: &configWithBuildFarmWithJobs,
+			jobBase: config.JobBase{Agent: "kubernetes", Name: "pull-ci-openshift-os-master-unit-arm64",
+				Labels: map[string]string{"ci-operator.openshift.io/architecture": "arm64"},
+			},
+			expectedErr: fmt.Errorf("job pull-ci-openshift-os-master-unit-arm64 runs on the architecture arm64, but no cluster is configured for it"),
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
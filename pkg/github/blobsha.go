@@ -0,0 +1,57 @@
+package github
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+)
+
+// BlobSHAGetter returns the SHA of the git blob of the file at the provided path without downloading
+// the file. The SHA changes whenever the content of the file changes, so it can be used to detect
+// changes across runs. It returns an empty string and a nil error if the file does not exist.
+type BlobSHAGetter func(path string) (string, error)
+
+// BlobSHAGetterFactory returns a BlobSHAGetter for the provided org/repo/branch that uses the git trees
+// API. Every directory is listed once, so getting the SHAs of multiple files in the same directory only
+// results in a single request. It supports private repositories when configured WithAuthentication or
+// WithAppInstallationAuthentication.
+func BlobSHAGetterFactory(org, repo, branch string, opts ...Opt) BlobSHAGetter {
+	o := Opts{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	getTree := treeGetterFor(org, repo, o)
+
+	lock := &sync.Mutex{}
+	// shasByDir holds the SHAs of the blobs in every directory we listed, keyed by their name
+	shasByDir := map[string]map[string]string{}
+	return func(file string) (string, error) {
+		dir, name := path.Split(strings.Trim(path.Clean("/"+file), "/"))
+		dir = strings.TrimSuffix(dir, "/")
+
+		lock.Lock()
+		defer lock.Unlock()
+		if shas, listed := shasByDir[dir]; listed {
+			return shas[name], nil
+		}
+		treeish := branch
+		if dir != "" {
+			treeish += ":" + dir
+		}
+		tree, err := getTree(treeish, false)
+		if err != nil {
+			return "", fmt.Errorf("failed to list %s of %s/%s@%s: %w", dir, org, repo, branch, err)
+		}
+		shas := map[string]string{}
+		if tree != nil {
+			for _, entry := range tree.Tree {
+				if entry.Type == "blob" {
+					shas[entry.Path] = entry.SHA
+				}
+			}
+		}
+		shasByDir[dir] = shas
+		return shas[name], nil
+	}
+}
@@ -0,0 +1,56 @@
+package github
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBlobSHAGetter(t *testing.T) {
+	trees := map[string]string{
+		"/repos/org/repo/git/trees/release/4.8":                 `{"tree": [{"path": "Dockerfile", "type": "blob", "sha": "root-sha"}, {"path": "images", "type": "tree", "sha": "tree-sha"}]}`,
+		"/repos/org/repo/git/trees/release/4.8:images/operator": `{"tree": [{"path": "Dockerfile", "type": "blob", "sha": "operator-sha"}, {"path": "Dockerfile.rhel", "type": "blob", "sha": "rhel-sha"}]}`,
+	}
+	requests := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests[r.URL.Path]++
+		tree, ok := trees[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, tree)
+	}))
+	defer server.Close()
+
+	getter := BlobSHAGetterFactory("org", "repo", "release/4.8", WithEndpoints(Endpoints{API: server.URL}), WithRequestBudget(NewRequestBudget(0)))
+	testCases := []struct {
+		path     string
+		expected string
+	}{
+		{path: "Dockerfile", expected: "root-sha"},
+		{path: "./Dockerfile", expected: "root-sha"},
+		{path: "images", expected: ""},
+		{path: "images/operator/Dockerfile", expected: "operator-sha"},
+		{path: "images/operator/Dockerfile.rhel", expected: "rhel-sha"},
+		{path: "images/operator/missing", expected: ""},
+		{path: "missing/Dockerfile", expected: ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			sha, err := getter(tc.path)
+			if err != nil {
+				t.Fatalf("failed to get SHA: %v", err)
+			}
+			if sha != tc.expected {
+				t.Errorf("expected SHA %q, got %q", tc.expected, sha)
+			}
+		})
+	}
+	for path, count := range requests {
+		if count != 1 {
+			t.Errorf("expected %s to be listed once, got %d requests", path, count)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package github
+
+import (
+	"net/http"
+	"strings"
+
+	pgithub "k8s.io/test-infra/prow/github"
+)
+
+const defaultRawEndpoint = "https://raw.githubusercontent.com"
+
+// Endpoints are the base URLs of a GitHub deployment
+type Endpoints struct {
+	// API is the base URL of the REST API, e.g. https://github.example.com/api/v3
+	API string
+	// GraphQL is the URL of the GraphQL API, e.g. https://github.example.com/api/graphql
+	GraphQL string
+	// Raw is the base URL under which the content of files is served, e.g. https://github.example.com/raw
+	Raw string
+}
+
+// EndpointsForHost returns the endpoints of the GitHub deployment at the given host. Any host
+// other than github.com is assumed to be a GitHub Enterprise Server without subdomain isolation.
+func EndpointsForHost(host string) Endpoints {
+	if host == "" || host == pgithub.DefaultHost {
+		return Endpoints{API: pgithub.DefaultAPIEndpoint, GraphQL: pgithub.DefaultGraphQLEndpoint, Raw: defaultRawEndpoint}
+	}
+	base := "https://" + host
+	return Endpoints{API: base + "/api/v3", GraphQL: base + "/api/graphql", Raw: base + "/raw"}
+}
+
+// WithEndpoints makes all requests go to the given GitHub deployment instead of github.com
+func WithEndpoints(endpoints Endpoints) Opt {
+	return func(o *Opts) {
+		o.Endpoints = endpoints
+	}
+}
+
+// WithHostAuthentication authenticates requests to the given host with the given credentials,
+// which take precedence over the credentials that are configured for all hosts. This allows
+// to use different tokens for github.com and a GitHub Enterprise Server.
+func WithHostAuthentication(host, username, token string) Opt {
+	return func(o *Opts) {
+		if o.HostCredentials == nil {
+			o.HostCredentials = map[string]Credentials{}
+		}
+		o.HostCredentials[host] = Credentials{Username: username, Token: token}
+	}
+}
+
+// Credentials are used for basic auth
+type Credentials struct {
+	Username string
+	Token    string
+}
+
+// endpoints returns the configured endpoints and defaults to github.com
+func (o Opts) endpoints() Endpoints {
+	defaults := EndpointsForHost(pgithub.DefaultHost)
+	endpoints := o.Endpoints
+	if endpoints.API == "" {
+		endpoints.API = defaults.API
+	}
+	if endpoints.GraphQL == "" {
+		endpoints.GraphQL = defaults.GraphQL
+	}
+	if endpoints.Raw == "" {
+		endpoints.Raw = defaults.Raw
+	}
+	return endpoints
+}
+
+// hostCredentials returns the credentials for the host a request goes to. The API and the
+// content of github.com are served from subdomains, which share the credentials of github.com.
+func (o Opts) hostCredentials(req *http.Request) (Credentials, bool) {
+	host := req.URL.Hostname()
+	if credentials, ok := o.HostCredentials[host]; ok {
+		return credentials, true
+	}
+	switch {
+	case host == "raw.githubusercontent.com":
+		host = pgithub.DefaultHost
+	case strings.HasPrefix(host, "api."), strings.HasPrefix(host, "raw."):
+		host = strings.SplitN(host, ".", 2)[1]
+	default:
+		return Credentials{}, false
+	}
+	credentials, ok := o.HostCredentials[host]
+	return credentials, ok
+}
@@ -0,0 +1,109 @@
+package github
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestEndpointsForHost(t *testing.T) {
+	testCases := []struct {
+		host     string
+		expected Endpoints
+	}{
+		{
+			host:     "",
+			expected: Endpoints{API: "https://api.github.com", GraphQL: "https://api.github.com/graphql", Raw: "https://raw.githubusercontent.com"},
+		},
+		{
+			host:     "github.com",
+			expected: Endpoints{API: "https://api.github.com", GraphQL: "https://api.github.com/graphql", Raw: "https://raw.githubusercontent.com"},
+		},
+		{
+			host:     "github.example.com",
+			expected: Endpoints{API: "https://github.example.com/api/v3", GraphQL: "https://github.example.com/api/graphql", Raw: "https://github.example.com/raw"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.host, func(t *testing.T) {
+			if diff := cmp.Diff(tc.expected, EndpointsForHost(tc.host)); diff != "" {
+				t.Errorf("endpoints differ from expected: %s", diff)
+			}
+		})
+	}
+}
+
+func TestHostCredentials(t *testing.T) {
+	o := Opts{}
+	for _, opt := range []Opt{
+		WithHostAuthentication("github.com", "public", "public-token"),
+		WithHostAuthentication("github.example.com", "enterprise", "enterprise-token"),
+	} {
+		opt(&o)
+	}
+	testCases := []struct {
+		url      string
+		expected Credentials
+		found    bool
+	}{
+		{url: "https://api.github.com/repos/org/repo/git/trees/master", expected: Credentials{Username: "public", Token: "public-token"}, found: true},
+		{url: "https://raw.githubusercontent.com/org/repo/master/OWNERS", expected: Credentials{Username: "public", Token: "public-token"}, found: true},
+		{url: "https://github.example.com/api/v3/repos/org/repo/git/trees/master", expected: Credentials{Username: "enterprise", Token: "enterprise-token"}, found: true},
+		{url: "https://raw.github.example.com/org/repo/master/OWNERS", expected: Credentials{Username: "enterprise", Token: "enterprise-token"}, found: true},
+		{url: "https://github.other.com/raw/org/repo/master/OWNERS"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.url, func(t *testing.T) {
+			parsed, err := url.Parse(tc.url)
+			if err != nil {
+				t.Fatalf("failed to parse url: %v", err)
+			}
+			credentials, found := o.hostCredentials(&http.Request{URL: parsed})
+			if found != tc.found {
+				t.Fatalf("expected found to be %t, got %t", tc.found, found)
+			}
+			if diff := cmp.Diff(tc.expected, credentials); diff != "" {
+				t.Errorf("credentials differ from expected: %s", diff)
+			}
+		})
+	}
+}
+
+func TestFileGetterWithEndpoints(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, password, _ := r.BasicAuth(); user != "enterprise" || password != "enterprise-token" {
+			t.Errorf("got unexpected credentials %s:%s", user, password)
+		}
+		if r.URL.Path != "/raw/org/repo/branch/OWNERS" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, "approvers: [someone]")
+	}))
+	defer server.Close()
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("failed to parse server url: %v", err)
+	}
+
+	getter := FileGetterFactory("org", "repo", "branch",
+		WithEndpoints(Endpoints{Raw: server.URL + "/raw"}),
+		WithAuthentication("public", "public-token"),
+		WithHostAuthentication(serverURL.Hostname(), "enterprise", "enterprise-token"),
+		WithRequestBudget(NewRequestBudget(0)),
+	)
+	content, err := getter("OWNERS")
+	if err != nil {
+		t.Fatalf("failed to get file: %v", err)
+	}
+	if string(content) != "approvers: [someone]" {
+		t.Errorf("got unexpected content %q", string(content))
+	}
+	if content, err := getter("missing"); err != nil || content != nil {
+		t.Errorf("expected no content and no error for a missing file, got %q and %v", string(content), err)
+	}
+}
@@ -0,0 +1,168 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+)
+
+// FileLister returns the paths of all files below the provided directory of the repository,
+// recursively. The empty string lists the whole repository. If the pattern is not empty, only
+// files whose name matches it as understood by path.Match are returned, e.g. `Dockerfile*`.
+// It returns a nil error and no files on 404.
+type FileLister func(dir, pattern string) ([]string, error)
+
+// gitTree is a tree as returned by the git trees API
+type gitTree struct {
+	Tree []struct {
+		Path string `json:"path"`
+		Type string `json:"type"`
+		SHA  string `json:"sha"`
+	} `json:"tree"`
+	// Truncated is set if a recursive listing exceeded the limits of the API
+	Truncated bool `json:"truncated"`
+}
+
+// treeGetterFor returns a function that gets trees of the repository through the git trees API.
+// It returns nil on 404.
+func treeGetterFor(org, repo string, o Opts) func(treeish string, recursive bool) (*gitTree, error) {
+	client := newClient(o)
+	endpoint := o.endpoints().API
+	return func(treeish string, recursive bool) (*gitTree, error) {
+		treeURL := fmt.Sprintf("%s/repos/%s/%s/git/trees/%s", endpoint, org, repo, escapeTreeish(treeish))
+		if recursive {
+			treeURL += "?recursive=1"
+		}
+		req, err := http.NewRequest(http.MethodGet, treeURL, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to construct request: %w", err)
+		}
+		req.Header.Set("Accept", "application/vnd.github.v3+json")
+		if err := o.authenticate(req, org); err != nil {
+			return nil, err
+		}
+		resp, err := client.StandardClient().Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to GET %s: %w", treeURL, err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response body when getting %s: %w", treeURL, err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("got unexpected http status code %d when getting %s, response body: %s", resp.StatusCode, treeURL, string(body))
+		}
+		tree := &gitTree{}
+		if err := json.Unmarshal(body, tree); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal tree from %s: %w", treeURL, err)
+		}
+		return tree, nil
+	}
+}
+
+// escapeTreeish escapes a tree-ish like branch:path/to/dir for the use in a URL. Slashes are kept,
+// as they are part of both branch names and paths.
+func escapeTreeish(treeish string) string {
+	segments := strings.Split(treeish, "/")
+	for idx := range segments {
+		segments[idx] = url.PathEscape(segments[idx])
+	}
+	return strings.Join(segments, "/")
+}
+
+// FileListerFactory returns a FileLister for the provided org/repo/branch that uses the git trees
+// API. The tree of the branch is fetched once on the first call and then served from the cache,
+// so the lister can be called repeatedly without getting rate limited. It supports private
+// repositories when configured WithAuthentication or WithAppInstallationAuthentication
+// and repositories on a GitHub Enterprise Server when configured WithEndpoints.
+func FileListerFactory(org, repo, branch string, opts ...Opt) FileLister {
+	o := Opts{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	getTree := treeGetterFor(org, repo, o)
+
+	// listTree lists all files of a tree. Recursive listings are truncated by the API for big trees,
+	// in which case we page through the subtrees one by one.
+	var listTree func(treeish, prefix string) ([]string, error)
+	listTree = func(treeish, prefix string) ([]string, error) {
+		tree, err := getTree(treeish, true)
+		if err != nil || tree == nil {
+			return nil, err
+		}
+		var files []string
+		if !tree.Truncated {
+			for _, entry := range tree.Tree {
+				if entry.Type == "blob" {
+					files = append(files, prefix+entry.Path)
+				}
+			}
+			return files, nil
+		}
+		if tree, err = getTree(treeish, false); err != nil || tree == nil {
+			return nil, err
+		}
+		for _, entry := range tree.Tree {
+			switch entry.Type {
+			case "blob":
+				files = append(files, prefix+entry.Path)
+			case "tree":
+				subtreeFiles, err := listTree(entry.SHA, prefix+entry.Path+"/")
+				if err != nil {
+					return nil, err
+				}
+				files = append(files, subtreeFiles...)
+			}
+		}
+		return files, nil
+	}
+
+	lock := &sync.Mutex{}
+	var cached []string
+	var listed bool
+	listAll := func() ([]string, error) {
+		lock.Lock()
+		defer lock.Unlock()
+		if listed {
+			return cached, nil
+		}
+		files, err := listTree(branch, "")
+		if err != nil {
+			return nil, fmt.Errorf("failed to list files of %s/%s@%s: %w", org, repo, branch, err)
+		}
+		cached, listed = files, true
+		return cached, nil
+	}
+
+	return func(dir, pattern string) ([]string, error) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		files, err := listAll()
+		if err != nil {
+			return nil, err
+		}
+		dir = strings.Trim(path.Clean("/"+dir), "/")
+		var result []string
+		for _, file := range files {
+			if dir != "" && !strings.HasPrefix(file, dir+"/") {
+				continue
+			}
+			// The pattern was validated above
+			if matches, _ := path.Match(pattern, path.Base(file)); pattern != "" && !matches {
+				continue
+			}
+			result = append(result, file)
+		}
+		return result, nil
+	}
+}
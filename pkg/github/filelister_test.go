@@ -0,0 +1,98 @@
+package github
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFileLister(t *testing.T) {
+	trees := map[string]string{
+		// The recursive listing of the branch is truncated, so the subtrees are listed one by one
+		"/repos/org/repo/git/trees/branch?recursive=1": `{"tree": [], "truncated": true}`,
+		"/repos/org/repo/git/trees/branch":             `{"tree": [{"path": "OWNERS", "type": "blob", "sha": "1"}, {"path": "images", "type": "tree", "sha": "images"}, {"path": "vendor", "type": "commit", "sha": "3"}]}`,
+		"/repos/org/repo/git/trees/images?recursive=1": `{"tree": [{"path": "base", "type": "tree", "sha": "4"}, {"path": "base/Dockerfile", "type": "blob", "sha": "5"}, {"path": "base/Dockerfile.rhel", "type": "blob", "sha": "6"}, {"path": "base/OWNERS", "type": "blob", "sha": "7"}, {"path": "other/Dockerfile", "type": "blob", "sha": "8"}]}`,
+	}
+	requests := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "token the-token" {
+			t.Errorf("got unexpected Authorization header %q", auth)
+		}
+		requests[r.URL.String()]++
+		tree, ok := trees[r.URL.String()]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, tree)
+	}))
+	defer server.Close()
+
+	tokenGenerator := func(org string) (string, error) { return "the-token", nil }
+	lister := FileListerFactory("org", "repo", "branch", WithEndpoints(Endpoints{API: server.URL}), WithAppInstallationAuthentication(tokenGenerator))
+
+	testCases := []struct {
+		name        string
+		dir         string
+		pattern     string
+		expected    []string
+		expectedErr string
+	}{
+		{
+			name:     "all files",
+			expected: []string{"OWNERS", "images/base/Dockerfile", "images/base/Dockerfile.rhel", "images/base/OWNERS", "images/other/Dockerfile"},
+		},
+		{
+			name:     "by name",
+			pattern:  "OWNERS",
+			expected: []string{"OWNERS", "images/base/OWNERS"},
+		},
+		{
+			name:     "by pattern below a directory",
+			dir:      "images/base/",
+			pattern:  "Dockerfile*",
+			expected: []string{"images/base/Dockerfile", "images/base/Dockerfile.rhel"},
+		},
+		{
+			name: "directory is not a prefix of a name",
+			dir:  "image",
+		},
+		{
+			name:        "invalid pattern",
+			pattern:     "[",
+			expectedErr: `invalid pattern "[": syntax error in pattern`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := lister(tc.dir, tc.pattern)
+			var actualErr string
+			if err != nil {
+				actualErr = err.Error()
+			}
+			if actualErr != tc.expectedErr {
+				t.Fatalf("expected error %q, got %q", tc.expectedErr, actualErr)
+			}
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Errorf("files differ from expected: %s", diff)
+			}
+		})
+	}
+
+	for request, count := range requests {
+		if count != 1 {
+			t.Errorf("expected the tree to be requested once from %s, got %d requests", request, count)
+		}
+	}
+
+	missing, err := FileListerFactory("org", "missing", "branch", WithEndpoints(Endpoints{API: server.URL}), WithAppInstallationAuthentication(tokenGenerator))("", "")
+	if err != nil {
+		t.Errorf("expected no error for a missing repository, got %v", err)
+	}
+	if len(missing) != 0 {
+		t.Errorf("expected no files for a missing repository, got %v", missing)
+	}
+}
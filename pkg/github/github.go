@@ -1,11 +1,19 @@
 package github
 
 import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"sync"
 
-	"github.com/hashicorp/go-retryablehttp"
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/test-infra/prow/config/secret"
+	pgithub "k8s.io/test-infra/prow/github"
 )
 
 type Opts struct {
@@ -13,10 +21,37 @@ type Opts struct {
 	BasicAuthUser string
 	// The token to use for basic auth
 	BasicAuthPassword string
+	// AppInstallationToken returns a token for the installation of a GitHub App
+	// in an org. If set, it takes precedence over basic auth.
+	AppInstallationToken func(org string) (string, error)
+	// RequestBudget is the budget requests count against, a budget that is
+	// shared by the whole process is used if unset
+	RequestBudget *RequestBudget
+	// Endpoints of the GitHub deployment, defaults to github.com
+	Endpoints Endpoints
+	// HostCredentials are used for requests to the host they are keyed by
+	// and take precedence over all other credentials.
+	HostCredentials map[string]Credentials
 }
 
 type Opt func(*Opts)
 
+// authenticate sets the credentials for a request to a repository in the given org
+func (o Opts) authenticate(req *http.Request, org string) error {
+	if credentials, ok := o.hostCredentials(req); ok {
+		req.SetBasicAuth(credentials.Username, credentials.Token)
+	} else if o.AppInstallationToken != nil {
+		token, err := o.AppInstallationToken(org)
+		if err != nil {
+			return fmt.Errorf("failed to get GitHub App installation token for %s: %w", org, err)
+		}
+		req.Header.Set("Authorization", "token "+token)
+	} else if o.BasicAuthUser != "" {
+		req.SetBasicAuth(o.BasicAuthUser, o.BasicAuthPassword)
+	}
+	return nil
+}
+
 func WithAuthentication(username, token string) Opt {
 	return func(o *Opts) {
 		o.BasicAuthUser = username
@@ -24,29 +59,87 @@ func WithAuthentication(username, token string) Opt {
 	}
 }
 
-// FileGetter is a function that downloads the file from the provided path via raw.githubusercontent.com to avoid getting rate limited.
+// WithAppInstallationAuthentication authenticates with the token of the installation
+// of a GitHub App in the org of the repository. The token is requested for every file,
+// so the generator is expected to cache it, like the one returned by AppInstallationTokenGenerator.
+func WithAppInstallationAuthentication(tokenGenerator func(org string) (string, error)) Opt {
+	return func(o *Opts) {
+		o.AppInstallationToken = tokenGenerator
+	}
+}
+
+// AppInstallationTokenGenerator returns a generator for installation tokens of the GitHub App with the
+// given ID on the GitHub deployment with the given endpoints. The private key is loaded into the secret agent,
+// so it is reloaded when it gets rotated. The tokens are scoped to the installation in an org, cached and
+// refreshed before they expire.
+func AppInstallationTokenGenerator(appID, privateKeyPath string, secretAgent *secret.Agent, endpoints Endpoints) (func(org string) (string, error), error) {
+	if err := secretAgent.Add(privateKeyPath); err != nil {
+		return nil, fmt.Errorf("failed to load the private key of the GitHub App: %w", err)
+	}
+	lock := &sync.Mutex{}
+	privateKey, err := parseRSAPrivateKey(secretAgent.GetSecret(privateKeyPath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the private key of the GitHub App: %w", err)
+	}
+	getPrivateKey := func() *rsa.PrivateKey {
+		lock.Lock()
+		defer lock.Unlock()
+		// Keep using the last valid key if a rotated one can not be parsed
+		if parsed, err := parseRSAPrivateKey(secretAgent.GetSecret(privateKeyPath)); err != nil {
+			logrus.WithError(err).Error("Failed to parse the private key of the GitHub App, using the previous one")
+		} else {
+			privateKey = parsed
+		}
+		return privateKey
+	}
+	endpoints = Opts{Endpoints: endpoints}.endpoints()
+	tokenGenerator, _ := pgithub.NewAppsAuthClientWithFields(logrus.Fields{}, secretAgent.Censor, appID, getPrivateKey, endpoints.GraphQL, endpoints.API)
+	return tokenGenerator, nil
+}
+
+func parseRSAPrivateKey(raw []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, errors.New("key is not PEM encoded")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse key: %w", err)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("key is not an RSA key")
+	}
+	return rsaKey, nil
+}
+
+// FileGetter is a function that downloads the file from the provided path via raw.githubusercontent.com or the raw endpoint
+// of a GitHub Enterprise Server to avoid getting rate limited.
 // It returns a nil error on 404.
 // TODO: Rethink the 404 behavior?
 type FileGetter func(path string) ([]byte, error)
 
 // FileGetterFactory returns a GithubFileGetter that downloads files from raw.githubusercontent.com for the provided org/repo/branch
 // It avoids getting ratelimited by using raw.githubusercontent.com. Because it is using a plain http client it can be heavily paralellized
-// without killing the machine. It supports private repositories when configured WithAuthentication.
+// without killing the machine. Failed requests are retried with backoff, honoring the rate limit. It supports private repositories when configured WithAuthentication or WithAppInstallationAuthentication.
 func FileGetterFactory(org, repo, branch string, opts ...Opt) FileGetter {
 	o := Opts{}
 	for _, opt := range opts {
 		opt(&o)
 	}
-	client := retryablehttp.NewClient()
-	client.Logger = nil
+	client := newClient(o)
+	rawEndpoint := o.endpoints().Raw
 	return func(path string) ([]byte, error) {
-		url := fmt.Sprintf("https://raw.githubusercontent.com/%s/%s/%s/%s", org, repo, branch, path)
+		url := fmt.Sprintf("%s/%s/%s/%s/%s", rawEndpoint, org, repo, branch, path)
 		req, err := http.NewRequest(http.MethodGet, url, nil)
 		if err != nil {
 			return nil, fmt.Errorf("failed to construct request: %w", err)
 		}
-		if o.BasicAuthUser != "" {
-			req.SetBasicAuth(o.BasicAuthUser, o.BasicAuthPassword)
+		if err := o.authenticate(req, org); err != nil {
+			return nil, err
 		}
 		resp, err := client.StandardClient().Do(req)
 		if err != nil {
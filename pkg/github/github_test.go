@@ -0,0 +1,70 @@
+package github
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+)
+
+func TestParseRSAPrivateKey(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate rsa key: %v", err)
+	}
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(rsaKey)
+	if err != nil {
+		t.Fatalf("failed to marshal rsa key: %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate ec key: %v", err)
+	}
+	ecPKCS8, err := x509.MarshalPKCS8PrivateKey(ecKey)
+	if err != nil {
+		t.Fatalf("failed to marshal ec key: %v", err)
+	}
+
+	testCases := []struct {
+		name        string
+		raw         []byte
+		expectedErr string
+	}{
+		{
+			name: "PKCS1",
+			raw:  pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}),
+		},
+		{
+			name: "PKCS8",
+			raw:  pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}),
+		},
+		{
+			name:        "not PEM encoded",
+			raw:         []byte("not a key"),
+			expectedErr: "key is not PEM encoded",
+		},
+		{
+			name:        "not an RSA key",
+			raw:         pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: ecPKCS8}),
+			expectedErr: "key is not an RSA key",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			key, err := parseRSAPrivateKey(tc.raw)
+			var actualErr string
+			if err != nil {
+				actualErr = err.Error()
+			}
+			if actualErr != tc.expectedErr {
+				t.Fatalf("expected error %q, got %q", tc.expectedErr, actualErr)
+			}
+			if err == nil && !key.Equal(rsaKey) {
+				t.Error("parsed key differs from the generated one")
+			}
+		})
+	}
+}
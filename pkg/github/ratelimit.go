@@ -0,0 +1,176 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+// maxRateLimitWait is the longest we wait for an exhausted rate limit to reset before giving up
+const maxRateLimitWait = 15 * time.Minute
+
+// defaultRequestBudget is shared by all getters and listers that are not configured
+// with a budget, so they all back off when GitHub reports the rate limit as exhausted
+var defaultRequestBudget = NewRequestBudget(0)
+
+// RequestBudget bounds the rate of requests to GitHub across all getters and listers
+// it is passed to, which can be used concurrently from many goroutines. Once GitHub
+// reports that the rate limit is exhausted, all requests wait until it resets.
+type RequestBudget struct {
+	// interval is the minimum time between two requests
+	interval time.Duration
+
+	lock sync.Mutex
+	// next is the earliest time at which the next request may be sent
+	next time.Time
+	// resetAt is the time at which an exhausted rate limit resets
+	resetAt time.Time
+}
+
+// NewRequestBudget returns a budget that allows the given number of requests per second,
+// the rate is unlimited if it is not positive
+func NewRequestBudget(requestsPerSecond float64) *RequestBudget {
+	budget := &RequestBudget{}
+	if requestsPerSecond > 0 {
+		budget.interval = time.Duration(float64(time.Second) / requestsPerSecond)
+	}
+	return budget
+}
+
+// WithRequestBudget makes all requests count against the given budget
+func WithRequestBudget(budget *RequestBudget) Opt {
+	return func(o *Opts) {
+		o.RequestBudget = budget
+	}
+}
+
+// wait blocks until a request may be sent
+func (b *RequestBudget) wait(ctx context.Context) error {
+	b.lock.Lock()
+	sendAt := time.Now()
+	if b.resetAt.After(sendAt) {
+		sendAt = b.resetAt
+	}
+	if b.next.After(sendAt) {
+		sendAt = b.next
+	}
+	b.next = sendAt.Add(b.interval)
+	b.lock.Unlock()
+
+	wait := time.Until(sendAt)
+	if wait <= 0 {
+		return nil
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(wait):
+		return nil
+	}
+}
+
+// observe records when the rate limit resets if the response reports it as exhausted
+func (b *RequestBudget) observe(resp *http.Response) {
+	resetAt, exhausted := rateLimitReset(resp)
+	if !exhausted {
+		return
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if resetAt.After(b.resetAt) {
+		b.resetAt = resetAt
+	}
+}
+
+// rateLimitReset returns when the rate limit resets if the response reports it as exhausted
+func rateLimitReset(resp *http.Response) (time.Time, bool) {
+	if resp == nil || resp.Header.Get("X-RateLimit-Remaining") != "0" {
+		return time.Time{}, false
+	}
+	reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(reset, 0), true
+}
+
+// retryAfter returns the wait time requested by the Retry-After header, which GitHub sends
+// when its abuse detection mechanism was triggered
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil {
+		return 0, false
+	}
+	return time.Duration(seconds) * time.Second, true
+}
+
+// budgetedTransport makes every attempt of a request count against a budget
+type budgetedTransport struct {
+	budget   *RequestBudget
+	delegate http.RoundTripper
+}
+
+func (t *budgetedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.budget.wait(req.Context()); err != nil {
+		return nil, err
+	}
+	resp, err := t.delegate.RoundTrip(req)
+	t.budget.observe(resp)
+	return resp, err
+}
+
+// retryPolicy retries connection and server errors like the default policy does and
+// in addition responses that show that the rate limit or the abuse detection mechanism
+// kicked in. It gives up if the rate limit does not reset within maxRateLimitWait.
+func retryPolicy(ctx context.Context, resp *http.Response, err error) (bool, error) {
+	if retry, err := retryablehttp.DefaultRetryPolicy(ctx, resp, err); retry || err != nil || resp == nil {
+		return retry, err
+	}
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return false, nil
+	}
+	if _, ok := retryAfter(resp); ok {
+		return true, nil
+	}
+	if resetAt, exhausted := rateLimitReset(resp); exhausted {
+		if wait := time.Until(resetAt); wait > maxRateLimitWait {
+			return false, fmt.Errorf("rate limit is exhausted until %s", resetAt.Format(time.RFC3339))
+		}
+		return true, nil
+	}
+	return resp.StatusCode == http.StatusTooManyRequests, nil
+}
+
+// backoff waits as long as GitHub asks us to and backs off exponentially otherwise
+func backoff(min, max time.Duration, attemptNum int, resp *http.Response) time.Duration {
+	if wait, ok := retryAfter(resp); ok {
+		return wait
+	}
+	if _, exhausted := rateLimitReset(resp); exhausted {
+		// The budget makes the request wait for the reset
+		return 0
+	}
+	return retryablehttp.DefaultBackoff(min, max, attemptNum, resp)
+}
+
+// newClient returns a client that retries with backoff and counts all requests against the budget
+func newClient(o Opts) *retryablehttp.Client {
+	budget := o.RequestBudget
+	if budget == nil {
+		budget = defaultRequestBudget
+	}
+	client := retryablehttp.NewClient()
+	client.Logger = nil
+	client.CheckRetry = retryPolicy
+	client.Backoff = backoff
+	client.HTTPClient.Transport = &budgetedTransport{budget: budget, delegate: client.HTTPClient.Transport}
+	return client
+}
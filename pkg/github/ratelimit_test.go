@@ -0,0 +1,160 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	response := func(code int, headers map[string]string) *http.Response {
+		resp := &http.Response{StatusCode: code, Header: http.Header{}}
+		for key, value := range headers {
+			resp.Header.Set(key, value)
+		}
+		return resp
+	}
+	soon := strconv.FormatInt(time.Now().Add(time.Minute).Unix(), 10)
+	later := time.Now().Add(time.Hour)
+
+	testCases := []struct {
+		name          string
+		resp          *http.Response
+		err           error
+		expected      bool
+		expectedErr   string
+		expectedWait  time.Duration
+		expectBackoff bool
+	}{
+		{
+			name:     "success",
+			resp:     response(http.StatusOK, nil),
+			expected: false,
+		},
+		{
+			name:     "not found",
+			resp:     response(http.StatusNotFound, nil),
+			expected: false,
+		},
+		{
+			name:          "connection error",
+			err:           errors.New("connection reset"),
+			expected:      true,
+			expectBackoff: true,
+		},
+		{
+			name:          "server error",
+			resp:          response(http.StatusBadGateway, nil),
+			expected:      true,
+			expectBackoff: true,
+		},
+		{
+			name:     "forbidden",
+			resp:     response(http.StatusForbidden, nil),
+			expected: false,
+		},
+		{
+			name:         "abuse detection",
+			resp:         response(http.StatusForbidden, map[string]string{"Retry-After": "30"}),
+			expected:     true,
+			expectedWait: 30 * time.Second,
+		},
+		{
+			name:          "too many requests",
+			resp:          response(http.StatusTooManyRequests, nil),
+			expected:      true,
+			expectBackoff: true,
+		},
+		{
+			name:     "rate limit exhausted",
+			resp:     response(http.StatusForbidden, map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": soon}),
+			expected: true,
+		},
+		{
+			name:        "rate limit exhausted for too long",
+			resp:        response(http.StatusForbidden, map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": strconv.FormatInt(later.Unix(), 10)}),
+			expected:    false,
+			expectedErr: fmt.Sprintf("rate limit is exhausted until %s", time.Unix(later.Unix(), 0).Format(time.RFC3339)),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			retry, err := retryPolicy(context.Background(), tc.resp, tc.err)
+			var actualErr string
+			if err != nil {
+				actualErr = err.Error()
+			}
+			if actualErr != tc.expectedErr {
+				t.Fatalf("expected error %q, got %q", tc.expectedErr, actualErr)
+			}
+			if retry != tc.expected {
+				t.Errorf("expected retry to be %t, got %t", tc.expected, retry)
+			}
+			if !retry {
+				return
+			}
+			expectedWait := tc.expectedWait
+			if tc.expectBackoff {
+				expectedWait = 4 * time.Second
+			}
+			if wait := backoff(time.Second, time.Minute, 2, tc.resp); wait != expectedWait {
+				t.Errorf("expected to wait %s, got %s", expectedWait, wait)
+			}
+		})
+	}
+}
+
+func TestRequestBudget(t *testing.T) {
+	budget := NewRequestBudget(100)
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := budget.wait(context.Background()); err != nil {
+			t.Fatalf("failed to wait: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("expected three requests to take at least 20ms at 100 requests per second, took %s", elapsed)
+	}
+
+	budget.observe(&http.Response{Header: http.Header{
+		"X-Ratelimit-Remaining": []string{"0"},
+		"X-Ratelimit-Reset":     []string{strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)},
+	}})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := budget.wait(ctx); err != context.DeadlineExceeded {
+		t.Errorf("expected to wait for the rate limit to reset, got %v", err)
+	}
+}
+
+func TestRetriesAbuseDetection(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		fmt.Fprint(w, `{"tree": [{"path": "OWNERS", "type": "blob", "sha": "1"}]}`)
+	}))
+	defer server.Close()
+
+	files, err := FileListerFactory("org", "repo", "branch", WithEndpoints(Endpoints{API: server.URL}), WithRequestBudget(NewRequestBudget(0)))("", "")
+	if err != nil {
+		t.Fatalf("failed to list files: %v", err)
+	}
+	if diff := cmp.Diff([]string{"OWNERS"}, files); diff != "" {
+		t.Errorf("files differ from expected: %s", diff)
+	}
+	if requests != 2 {
+		t.Errorf("expected the request to be retried once, got %d requests", requests)
+	}
+}
@@ -0,0 +1,83 @@
+package jobruntimeanalyzer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"time"
+
+	prowv1 "k8s.io/test-infra/prow/apis/prowjobs/v1"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/jobartifacts"
+)
+
+// stepRuntime is the duration of a single step in a single build
+type stepRuntime struct {
+	Repo     string
+	Job      string
+	Step     string
+	Finished time.Time
+	Duration time.Duration
+}
+
+// crawl fetches the step runtimes of all builds of the given jobs that finished after since
+func crawl(ctx context.Context, source jobartifacts.Source, jobs []string, maxBuildsPerJob int, since time.Time) ([]stepRuntime, error) {
+	var result []stepRuntime
+	err := jobartifacts.Crawl(ctx, source, jobs, maxBuildsPerJob, since, func(ctx context.Context, job, build string, finished time.Time) error {
+		runtimes, err := crawlBuild(ctx, source, job, build, finished)
+		if err != nil {
+			return err
+		}
+		result = append(result, runtimes...)
+		return nil
+	})
+	return result, err
+}
+
+func crawlBuild(ctx context.Context, source jobartifacts.Source, job, build string, finishedAt time.Time) ([]stepRuntime, error) {
+	rawProwJob, err := source.Read(ctx, path.Join(build, "prowjob.json"))
+	if err != nil {
+		return nil, err
+	}
+	var prowJob prowv1.ProwJob
+	if err := json.Unmarshal(rawProwJob, &prowJob); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal prowjob.json: %w", err)
+	}
+
+	rawStepGraph, err := source.Read(ctx, path.Join(build, "artifacts", api.CIOperatorStepGraphJSONFilename))
+	if err != nil {
+		if errors.Is(err, jobartifacts.ErrNotExist) {
+			// Not a ci-operator job
+			return nil, nil
+		}
+		return nil, err
+	}
+	var stepGraph api.CIOperatorStepGraph
+	if err := json.Unmarshal(rawStepGraph, &stepGraph); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal step graph: %w", err)
+	}
+
+	repo := repoFor(prowJob.Spec)
+	var result []stepRuntime
+	for _, step := range stepGraph {
+		if step.Duration == nil || (step.Failed != nil && *step.Failed) {
+			continue
+		}
+		result = append(result, stepRuntime{Repo: repo, Job: job, Step: step.StepName, Finished: finishedAt, Duration: *step.Duration})
+	}
+	return result, nil
+}
+
+func repoFor(spec prowv1.ProwJobSpec) string {
+	refs := spec.Refs
+	if refs == nil && len(spec.ExtraRefs) > 0 {
+		refs = &spec.ExtraRefs[0]
+	}
+	if refs == nil {
+		return ""
+	}
+	return refs.Org + "/" + refs.Repo
+}
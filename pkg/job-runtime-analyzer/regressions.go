@@ -0,0 +1,157 @@
+package jobruntimeanalyzer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/montanaflynn/stats"
+
+	"github.com/openshift/ci-tools/pkg/jobartifacts"
+)
+
+// AnalysisOptions configure the regression analysis
+type AnalysisOptions struct {
+	// Jobs whose builds are analyzed
+	Jobs []string
+	// MaxBuildsPerJob limits the number of most recent builds that are crawled per job
+	MaxBuildsPerJob int
+	// Now is the end of the current window
+	Now time.Time
+	// CurrentWindow is the period before Now whose runtimes are checked for regressions
+	CurrentWindow time.Duration
+	// BaselineWindow is the period before the CurrentWindow whose runtimes are the baseline
+	BaselineWindow time.Duration
+	// Threshold is the fraction by which the P90 of the current window has to exceed
+	// the P90 of the baseline window to be flagged as regression
+	Threshold float64
+	// MinSamples is the number of samples both windows need to have for a step
+	// to be compared
+	MinSamples int
+}
+
+// Distribution describes the runtimes of a step within a window
+type Distribution struct {
+	Samples int           `json:"samples"`
+	Median  time.Duration `json:"median"`
+	P90     time.Duration `json:"p90"`
+	Max     time.Duration `json:"max"`
+}
+
+// StepReport describes the runtimes of a step of a repo in both windows
+type StepReport struct {
+	Repo     string        `json:"repo"`
+	Step     string        `json:"step"`
+	Baseline *Distribution `json:"baseline,omitempty"`
+	Current  *Distribution `json:"current,omitempty"`
+	// Change is the relative change of the P90 between the baseline and the current window
+	Change    *float64 `json:"change,omitempty"`
+	Regressed bool     `json:"regressed"`
+}
+
+// Report is the result of the regression analysis
+type Report struct {
+	BaselineStart time.Time    `json:"baseline_start"`
+	CurrentStart  time.Time    `json:"current_start"`
+	End           time.Time    `json:"end"`
+	Threshold     float64      `json:"threshold"`
+	Steps         []StepReport `json:"steps"`
+}
+
+// Regressions returns all steps that regressed
+func (r *Report) Regressions() []StepReport {
+	var result []StepReport
+	for _, step := range r.Steps {
+		if step.Regressed {
+			result = append(result, step)
+		}
+	}
+	return result
+}
+
+// Analyze crawls the builds of the configured jobs and compares the step runtimes of
+// the current window with the ones of the baseline window
+func Analyze(ctx context.Context, source jobartifacts.Source, opts AnalysisOptions) (*Report, error) {
+	currentStart := opts.Now.Add(-opts.CurrentWindow)
+	baselineStart := currentStart.Add(-opts.BaselineWindow)
+	runtimes, err := crawl(ctx, source, opts.Jobs, opts.MaxBuildsPerJob, baselineStart)
+	if err != nil {
+		return nil, fmt.Errorf("failed to crawl jobs: %w", err)
+	}
+	report := &Report{
+		BaselineStart: baselineStart,
+		CurrentStart:  currentStart,
+		End:           opts.Now,
+		Threshold:     opts.Threshold,
+		Steps:         compare(runtimes, currentStart, opts.Now, opts.Threshold, opts.MinSamples),
+	}
+	return report, nil
+}
+
+type repoStep struct {
+	repo string
+	step string
+}
+
+func compare(runtimes []stepRuntime, currentStart, end time.Time, threshold float64, minSamples int) []StepReport {
+	baseline, current := map[repoStep][]float64{}, map[repoStep][]float64{}
+	for _, runtime := range runtimes {
+		key := repoStep{repo: runtime.Repo, step: runtime.Step}
+		switch {
+		case runtime.Finished.After(end):
+			continue
+		case runtime.Finished.Before(currentStart):
+			baseline[key] = append(baseline[key], float64(runtime.Duration))
+		default:
+			current[key] = append(current[key], float64(runtime.Duration))
+		}
+	}
+
+	keys := map[repoStep]struct{}{}
+	for key := range baseline {
+		keys[key] = struct{}{}
+	}
+	for key := range current {
+		keys[key] = struct{}{}
+	}
+
+	var result []StepReport
+	for key := range keys {
+		report := StepReport{
+			Repo:     key.repo,
+			Step:     key.step,
+			Baseline: distributionFor(baseline[key]),
+			Current:  distributionFor(current[key]),
+		}
+		if report.Baseline != nil && report.Current != nil && report.Baseline.P90 > 0 {
+			change := float64(report.Current.P90-report.Baseline.P90) / float64(report.Baseline.P90)
+			report.Change = &change
+			report.Regressed = change > threshold && report.Baseline.Samples >= minSamples && report.Current.Samples >= minSamples
+		}
+		result = append(result, report)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Repo != result[j].Repo {
+			return result[i].Repo < result[j].Repo
+		}
+		return result[i].Step < result[j].Step
+	})
+	return result
+}
+
+func distributionFor(durations []float64) *Distribution {
+	if len(durations) == 0 {
+		return nil
+	}
+	// None of these can fail for non-empty input
+	median, _ := stats.Median(durations)
+	p90, _ := stats.Percentile(durations, 90)
+	max, _ := stats.Max(durations)
+	return &Distribution{
+		Samples: len(durations),
+		Median:  time.Duration(median),
+		P90:     time.Duration(p90),
+		Max:     time.Duration(max),
+	}
+}
@@ -0,0 +1,154 @@
+package jobruntimeanalyzer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	prowv1 "k8s.io/test-infra/prow/apis/prowjobs/v1"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/jobartifacts"
+)
+
+type fakeSource struct {
+	artifacts map[string][]byte
+}
+
+func (f *fakeSource) Builds(_ context.Context, job string) ([]string, error) {
+	builds := sets.NewString()
+	prefix := path.Join("logs", job) + "/"
+	for name := range f.artifacts {
+		if strings.HasPrefix(name, prefix) {
+			builds.Insert(prefix + strings.Split(strings.TrimPrefix(name, prefix), "/")[0])
+		}
+	}
+	return builds.List(), nil
+}
+
+func (f *fakeSource) Read(_ context.Context, name string) ([]byte, error) {
+	raw, ok := f.artifacts[name]
+	if !ok {
+		return nil, jobartifacts.ErrNotExist
+	}
+	return raw, nil
+}
+
+func (f *fakeSource) addBuild(t *testing.T, job string, id int, finished time.Time, repo string, durations map[string]time.Duration) {
+	build := path.Join("logs", job, fmt.Sprintf("%d", id))
+	f.artifacts[path.Join(build, "finished.json")] = []byte(fmt.Sprintf(`{"timestamp":%d,"passed":true}`, finished.Unix()))
+	org, name := path.Split(repo)
+	rawProwJob, err := json.Marshal(prowv1.ProwJob{Spec: prowv1.ProwJobSpec{Refs: &prowv1.Refs{Org: strings.TrimSuffix(org, "/"), Repo: name}}})
+	if err != nil {
+		t.Fatalf("failed to marshal prowjob: %v", err)
+	}
+	f.artifacts[path.Join(build, "prowjob.json")] = rawProwJob
+	var graph api.CIOperatorStepGraph
+	for step, duration := range durations {
+		duration := duration
+		graph = append(graph, api.CIOperatorStepDetails{CIOperatorStepDetailInfo: api.CIOperatorStepDetailInfo{StepName: step, Duration: &duration}})
+	}
+	rawGraph, err := json.Marshal(graph)
+	if err != nil {
+		t.Fatalf("failed to marshal step graph: %v", err)
+	}
+	f.artifacts[path.Join(build, "artifacts", api.CIOperatorStepGraphJSONFilename)] = rawGraph
+}
+
+func TestAnalyze(t *testing.T) {
+	now := time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC)
+	source := &fakeSource{artifacts: map[string][]byte{}}
+	id := 0
+	for i := 0; i < 5; i++ {
+		id++
+		// Baseline
+		source.addBuild(t, "unit", id, now.Add(-5*24*time.Hour), "org/repo", map[string]time.Duration{
+			"src":  10 * time.Minute,
+			"unit": 20 * time.Minute,
+		})
+		id++
+		// Current
+		source.addBuild(t, "unit", id, now.Add(-time.Hour), "org/repo", map[string]time.Duration{
+			"src":  10 * time.Minute,
+			"unit": 30 * time.Minute,
+		})
+		id++
+		// Before the baseline window
+		source.addBuild(t, "unit", id, now.Add(-30*24*time.Hour), "org/repo", map[string]time.Duration{
+			"unit": time.Minute,
+		})
+	}
+	id++
+	// Too few samples to be flagged
+	source.addBuild(t, "other", id, now.Add(-5*24*time.Hour), "org/other", map[string]time.Duration{"e2e": time.Hour})
+	id++
+	source.addBuild(t, "other", id, now.Add(-time.Hour), "org/other", map[string]time.Duration{"e2e": 2 * time.Hour})
+	id++
+	// Still running
+	source.artifacts[path.Join("logs", "other", fmt.Sprintf("%d", id), "prowjob.json")] = []byte("{}")
+
+	report, err := Analyze(context.Background(), source, AnalysisOptions{
+		Jobs:           []string{"unit", "other"},
+		Now:            now,
+		CurrentWindow:  24 * time.Hour,
+		BaselineWindow: 7 * 24 * time.Hour,
+		Threshold:      0.2,
+		MinSamples:     3,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	zero, half, one := 0.0, 0.5, 1.0
+	expected := &Report{
+		BaselineStart: now.Add(-8 * 24 * time.Hour),
+		CurrentStart:  now.Add(-24 * time.Hour),
+		End:           now,
+		Threshold:     0.2,
+		Steps: []StepReport{
+			{
+				Repo:     "org/other",
+				Step:     "e2e",
+				Baseline: &Distribution{Samples: 1, Median: time.Hour, P90: time.Hour, Max: time.Hour},
+				Current:  &Distribution{Samples: 1, Median: 2 * time.Hour, P90: 2 * time.Hour, Max: 2 * time.Hour},
+				Change:   &one,
+			},
+			{
+				Repo:     "org/repo",
+				Step:     "src",
+				Baseline: &Distribution{Samples: 5, Median: 10 * time.Minute, P90: 10 * time.Minute, Max: 10 * time.Minute},
+				Current:  &Distribution{Samples: 5, Median: 10 * time.Minute, P90: 10 * time.Minute, Max: 10 * time.Minute},
+				Change:   &zero,
+			},
+			{
+				Repo:      "org/repo",
+				Step:      "unit",
+				Baseline:  &Distribution{Samples: 5, Median: 20 * time.Minute, P90: 20 * time.Minute, Max: 20 * time.Minute},
+				Current:   &Distribution{Samples: 5, Median: 30 * time.Minute, P90: 30 * time.Minute, Max: 30 * time.Minute},
+				Change:    &half,
+				Regressed: true,
+			},
+		},
+	}
+	if diff := cmp.Diff(expected, report); diff != "" {
+		t.Errorf("report differs from expected: %s", diff)
+	}
+	if diff := cmp.Diff([]StepReport{expected.Steps[2]}, report.Regressions()); diff != "" {
+		t.Errorf("regressions differ from expected: %s", diff)
+	}
+
+	var html strings.Builder
+	if err := WriteHTML(&html, report); err != nil {
+		t.Fatalf("failed to render HTML report: %v", err)
+	}
+	if !strings.Contains(html.String(), `<tr class="regressed"><td>org/repo</td><td>unit</td>`) {
+		t.Errorf("HTML report does not highlight the regression:\n%s", html.String())
+	}
+}
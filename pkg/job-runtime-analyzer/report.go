@@ -0,0 +1,78 @@
+package jobruntimeanalyzer
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"time"
+)
+
+// WriteJSON serializes the report as JSON
+func WriteJSON(w io.Writer, report *Report) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return fmt.Errorf("failed to serialize report: %w", err)
+	}
+	return nil
+}
+
+const htmlReportTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="UTF-8">
+<title>Step runtime report</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; }
+tr.regressed { background-color: #f8d7da; }
+</style>
+</head>
+<body>
+<h1>Step runtime report</h1>
+<p>Baseline: {{ .BaselineStart | timestamp }} to {{ .CurrentStart | timestamp }}, current: {{ .CurrentStart | timestamp }} to {{ .End | timestamp }}. Steps whose P90 increased by more than {{ .Threshold | percentage }} are flagged as regressed.</p>
+<h2>Regressions</h2>
+{{- with .Regressions }}
+{{ template "steps" . }}
+{{- else }}
+<p>No regressions found.</p>
+{{- end }}
+<h2>All steps</h2>
+{{ template "steps" .Steps }}
+</body>
+</html>
+{{ define "steps" -}}
+<table>
+<tr><th>Repo</th><th>Step</th><th>Baseline samples</th><th>Baseline median</th><th>Baseline P90</th><th>Current samples</th><th>Current median</th><th>Current P90</th><th>P90 change</th></tr>
+{{- range . }}
+<tr{{ if .Regressed }} class="regressed"{{ end }}><td>{{ .Repo }}</td><td>{{ .Step }}</td>{{ template "distribution" .Baseline }}{{ template "distribution" .Current }}<td>{{ with .Change }}{{ percentage (deref .) }}{{ end }}</td></tr>
+{{- end }}
+</table>
+{{- end }}
+{{ define "distribution" }}{{ with . }}<td>{{ .Samples }}</td><td>{{ .Median | duration }}</td><td>{{ .P90 | duration }}</td>{{ else }}<td>0</td><td></td><td></td>{{ end }}{{ end }}
+`
+
+var htmlReport = template.Must(template.New("report").Funcs(template.FuncMap{
+	"timestamp": func(t time.Time) string {
+		return t.UTC().Format(time.RFC3339)
+	},
+	"deref": func(f *float64) float64 {
+		return *f
+	},
+	"duration": func(d time.Duration) string {
+		return d.Round(time.Second).String()
+	},
+	"percentage": func(f float64) string {
+		return fmt.Sprintf("%+.1f%%", f*100)
+	},
+}).Parse(htmlReportTemplate))
+
+// WriteHTML renders the report as HTML
+func WriteHTML(w io.Writer, report *Report) error {
+	if err := htmlReport.Execute(w, report); err != nil {
+		return fmt.Errorf("failed to render report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+// Package jobartifacts crawls the artifacts Prow uploads for the builds of jobs.
+package jobartifacts
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/api/iterator"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// Source closes over how job artifacts are retrieved
+type Source interface {
+	// Builds returns the paths to all builds of the given job
+	Builds(ctx context.Context, job string) ([]string, error)
+	// Read returns the content of the artifact under the given path
+	Read(ctx context.Context, path string) ([]byte, error)
+}
+
+// ErrNotExist is returned by Sources if the requested artifact does not exist
+var ErrNotExist = errors.New("artifact does not exist")
+
+type bucketSource struct {
+	bucket *storage.BucketHandle
+}
+
+var _ Source = &bucketSource{}
+
+// NewBucketSource returns a Source for the given GCS bucket
+func NewBucketSource(bucket *storage.BucketHandle) Source {
+	return &bucketSource{bucket: bucket}
+}
+
+func (b *bucketSource) Builds(ctx context.Context, job string) ([]string, error) {
+	var result []string
+	it := b.bucket.Objects(ctx, &storage.Query{Prefix: path.Join("logs", job) + "/", Delimiter: "/"})
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list builds of job %s: %w", job, err)
+		}
+		if attrs.Prefix != "" {
+			result = append(result, strings.TrimSuffix(attrs.Prefix, "/"))
+		}
+	}
+	return result, nil
+}
+
+func (b *bucketSource) Read(ctx context.Context, name string) ([]byte, error) {
+	reader, err := b.bucket.Object(name).NewReader(ctx)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotExist) {
+			return nil, ErrNotExist
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	defer reader.Close()
+	return ioutil.ReadAll(reader)
+}
+
+// BuildFunc processes the artifacts of a single build of a job
+type BuildFunc func(ctx context.Context, job, build string, finished time.Time) error
+
+// Crawl calls crawlBuild for the maxBuildsPerJob most recent builds of each of the jobs
+// that finished after since. Builds that are still running are skipped. Builds that
+// can not be crawled are logged and skipped, only failures to list builds are returned.
+func Crawl(ctx context.Context, source Source, jobs []string, maxBuildsPerJob int, since time.Time, crawlBuild BuildFunc) error {
+	var errs []error
+	for _, job := range jobs {
+		builds, err := source.Builds(ctx, job)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		builds = mostRecent(builds, maxBuildsPerJob)
+		logger := logrus.WithField("job", job)
+		logger.Infof("Crawling %d builds", len(builds))
+		for _, build := range builds {
+			finished, err := finishedAt(ctx, source, build)
+			if err == nil && finished != nil && !finished.Before(since) {
+				err = crawlBuild(ctx, job, build, *finished)
+			}
+			if err != nil {
+				logger.WithError(err).WithField("build", build).Warn("Failed to crawl build, skipping it")
+			}
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// mostRecent returns the max most recent builds. Build IDs increase monotonically.
+func mostRecent(builds []string, max int) []string {
+	id := func(build string) uint64 {
+		parsed, _ := strconv.ParseUint(path.Base(build), 10, 64)
+		return parsed
+	}
+	sort.Slice(builds, func(i, j int) bool { return id(builds[i]) > id(builds[j]) })
+	if max > 0 && len(builds) > max {
+		builds = builds[:max]
+	}
+	return builds
+}
+
+// finished is the subset of the finished.json prow uploads that we care about
+type finished struct {
+	Timestamp *int64 `json:"timestamp,omitempty"`
+}
+
+// finishedAt returns when the build finished or nil if it is still running
+func finishedAt(ctx context.Context, source Source, build string) (*time.Time, error) {
+	raw, err := source.Read(ctx, path.Join(build, "finished.json"))
+	if err != nil {
+		if errors.Is(err, ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var finishedInfo finished
+	if err := json.Unmarshal(raw, &finishedInfo); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal finished.json: %w", err)
+	}
+	if finishedInfo.Timestamp == nil {
+		return nil, nil
+	}
+	finishedAt := time.Unix(*finishedInfo.Timestamp, 0)
+	return &finishedAt, nil
+}
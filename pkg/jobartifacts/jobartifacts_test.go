@@ -0,0 +1,61 @@
+package jobartifacts
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type fakeSource map[string]string
+
+func (f fakeSource) Builds(_ context.Context, job string) ([]string, error) {
+	if job == "broken" {
+		return nil, errors.New("failed to list builds")
+	}
+	return []string{"logs/" + job + "/9", "logs/" + job + "/10", "logs/" + job + "/11", "logs/" + job + "/12"}, nil
+}
+
+func (f fakeSource) Read(_ context.Context, path string) ([]byte, error) {
+	data, ok := f[path]
+	if !ok {
+		return nil, ErrNotExist
+	}
+	return []byte(data), nil
+}
+
+func TestCrawl(t *testing.T) {
+	since := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	source := fakeSource{
+		// Still running
+		"logs/job/12/finished.json": `{}`,
+		"logs/job/11/finished.json": `{"timestamp": 1622592000}`,
+		"logs/job/10/finished.json": `{"timestamp": 1622505600}`,
+		// Finished before since
+		"logs/job/9/finished.json": `{"timestamp": 1622419200}`,
+	}
+	var crawled []string
+	err := Crawl(context.Background(), source, []string{"job", "broken"}, 3, since, func(_ context.Context, job, build string, finished time.Time) error {
+		crawled = append(crawled, job+" "+build+" "+finished.UTC().Format(time.RFC3339))
+		if build == "logs/job/10" {
+			return errors.New("failures to crawl a build are skipped")
+		}
+		return nil
+	})
+	if diff := cmp.Diff("failed to list builds", err.Error()); diff != "" {
+		t.Errorf("error differs from expected: %s", diff)
+	}
+	expected := []string{"job logs/job/11 2021-06-02T00:00:00Z", "job logs/job/10 2021-06-01T00:00:00Z"}
+	if diff := cmp.Diff(expected, crawled); diff != "" {
+		t.Errorf("crawled builds differ from expected: %s", diff)
+	}
+}
+
+func TestMostRecent(t *testing.T) {
+	builds := []string{"logs/job/9", "logs/job/100", "logs/job/10", "logs/job/11"}
+	if diff := cmp.Diff([]string{"logs/job/100", "logs/job/11"}, mostRecent(builds, 2)); diff != "" {
+		t.Errorf("builds differ from expected: %s", diff)
+	}
+}
@@ -0,0 +1,147 @@
+// Package jsonschema generates JSON schemas from Go types and validates YAML
+// documents against them, reporting the position of every problem.
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Draft is the version of the JSON schema specification generated schemas follow
+const Draft = "http://json-schema.org/draft-07/schema#"
+
+const definitionsPrefix = "#/definitions/"
+
+// Schema is the subset of a JSON schema that is needed to describe Go types
+type Schema struct {
+	Schema     string             `json:"$schema,omitempty"`
+	Ref        string             `json:"$ref,omitempty"`
+	Type       string             `json:"type,omitempty"`
+	Enum       []string           `json:"enum,omitempty"`
+	Properties map[string]*Schema `json:"properties,omitempty"`
+	Required   []string           `json:"required,omitempty"`
+	// AdditionalProperties is either false or the *Schema of the values of a map
+	AdditionalProperties interface{}        `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Definitions          map[string]*Schema `json:"definitions,omitempty"`
+}
+
+var unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// For returns the schema for the type of v as it is unmarshalled from JSON.
+// Every named struct type is described once in the definitions of the schema.
+// Fields can be tagged with `jsonschema:"required"` when they have to be set
+// and with `jsonschema:"enum=a|b"` when only some values are allowed.
+func For(v interface{}) *Schema {
+	g := &generator{definitions: map[string]*Schema{}}
+	schema := g.schemaFor(reflect.TypeOf(v))
+	schema.Schema = Draft
+	schema.Definitions = g.definitions
+	return schema
+}
+
+type generator struct {
+	definitions map[string]*Schema
+}
+
+func (g *generator) schemaFor(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	// types with their own encoding can not be described from their fields
+	if t.Implements(unmarshalerType) || reflect.PtrTo(t).Implements(unmarshalerType) {
+		return &Schema{}
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// encoding/json encodes bytes as base64 strings
+			return &Schema{Type: "string"}
+		}
+		return &Schema{Type: "array", Items: g.schemaFor(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schemaFor(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		name := strings.ReplaceAll(t.PkgPath(), "/", ".") + "." + t.Name()
+		if _, seen := g.definitions[name]; !seen {
+			// register the definition before its fields, so recursive types refer to it
+			definition := &Schema{}
+			g.definitions[name] = definition
+			*definition = *g.structSchema(t)
+		}
+		return &Schema{Ref: definitionsPrefix + name}
+	default:
+		return &Schema{}
+	}
+}
+
+func (g *generator) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}, AdditionalProperties: false}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _ := parseTag(field.Tag.Get("json"))
+		if name == "-" || (field.PkgPath != "" && !field.Anonymous) {
+			continue
+		}
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				// encoding/json promotes the fields of embedded structs
+				inlined := g.structSchema(embedded)
+				for property, propertySchema := range inlined.Properties {
+					schema.Properties[property] = propertySchema
+				}
+				schema.Required = append(schema.Required, inlined.Required...)
+				continue
+			}
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		property := g.schemaFor(field.Type)
+		for _, option := range strings.Split(field.Tag.Get("jsonschema"), ",") {
+			switch {
+			case option == "required":
+				schema.Required = append(schema.Required, name)
+			case strings.HasPrefix(option, "enum="):
+				property.Enum = strings.Split(strings.TrimPrefix(option, "enum="), "|")
+			}
+		}
+		schema.Properties[name] = property
+	}
+	return schema
+}
+
+func parseTag(tag string) (string, []string) {
+	parts := strings.Split(tag, ",")
+	return parts[0], parts[1:]
+}
+
+// definition resolves a reference to one of the definitions
+func (s *Schema) definition(ref string) (*Schema, error) {
+	definition, ok := s.Definitions[strings.TrimPrefix(ref, definitionsPrefix)]
+	if !ok {
+		return nil, fmt.Errorf("reference to undefined %s", ref)
+	}
+	return definition, nil
+}
@@ -0,0 +1,139 @@
+package jsonschema
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+type Embedded struct {
+	Inlined string `json:"inlined"`
+}
+
+type Child struct {
+	Name     string   `json:"name" jsonschema:"required"`
+	Children []*Child `json:"children,omitempty"`
+}
+
+type Parent struct {
+	Embedded
+	Kind     string            `json:"kind,omitempty" jsonschema:"enum=a|b"`
+	Count    int               `json:"count,omitempty"`
+	Ratio    float64           `json:"ratio,omitempty"`
+	Enabled  *bool             `json:"enabled,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Child    *Child            `json:"child,omitempty"`
+	Quantity resource.Quantity `json:"quantity,omitempty"`
+	Raw      []byte            `json:"raw,omitempty"`
+	Ignored  string            `json:"-"`
+	private  string
+}
+
+func TestFor(t *testing.T) {
+	child := &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"name":     {Type: "string"},
+			"children": {Type: "array", Items: &Schema{Ref: "#/definitions/github.com.openshift.ci-tools.pkg.jsonschema.Child"}},
+		},
+		Required:             []string{"name"},
+		AdditionalProperties: false,
+	}
+	expected := &Schema{
+		Schema: Draft,
+		Ref:    "#/definitions/github.com.openshift.ci-tools.pkg.jsonschema.Parent",
+		Definitions: map[string]*Schema{
+			"github.com.openshift.ci-tools.pkg.jsonschema.Parent": {
+				Type: "object",
+				Properties: map[string]*Schema{
+					"inlined":  {Type: "string"},
+					"kind":     {Type: "string", Enum: []string{"a", "b"}},
+					"count":    {Type: "integer"},
+					"ratio":    {Type: "number"},
+					"enabled":  {Type: "boolean"},
+					"labels":   {Type: "object", AdditionalProperties: &Schema{Type: "string"}},
+					"child":    {Ref: "#/definitions/github.com.openshift.ci-tools.pkg.jsonschema.Child"},
+					"quantity": {},
+					"raw":      {Type: "string"},
+				},
+				AdditionalProperties: false,
+			},
+			"github.com.openshift.ci-tools.pkg.jsonschema.Child": child,
+		},
+	}
+	if diff := cmp.Diff(expected, For(&Parent{})); diff != "" {
+		t.Errorf("unexpected schema: %s", diff)
+	}
+}
+
+func TestValidateYAML(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		raw      string
+		expected []string
+	}{
+		{
+			name: "valid document",
+			raw: `inlined: value
+kind: a
+count: 1
+ratio: 0.5
+enabled: true
+labels:
+  key: value
+child:
+  name: child
+  children:
+  - name: grandchild
+quantity: 100m
+`,
+		},
+		{
+			name: "empty document",
+		},
+		{
+			name: "null values are allowed",
+			raw:  "kind: null\nchild:\n",
+		},
+		{
+			name: "problems are reported with their position",
+			raw: `kind: c
+count: "1"
+ratio: 1
+unknown: field
+labels:
+  key: 4.3
+child:
+  children:
+  - name: [grandchild]
+`,
+			expected: []string{
+				`line 1, column 7: kind: must be one of a, b, got "c"`,
+				`line 2, column 8: count: expected an integer, got str "1"`,
+				`line 4, column 1: unknown: unknown field`,
+				`line 6, column 8: labels.key: expected a string, got float "4.3"`,
+				`line 8, column 3: child: missing required field name`,
+				`line 9, column 11: child.children[0].name: expected a string, got a list`,
+			},
+		},
+		{
+			name:     "root of the wrong type",
+			raw:      "- item\n",
+			expected: []string{"line 1, column 1: <root>: expected an object, got a list"},
+		},
+	}
+	schema := For(&Parent{})
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var actual []string
+			for _, err := range schema.ValidateYAML([]byte(tc.raw)) {
+				actual = append(actual, err.Error())
+			}
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Errorf("unexpected errors: %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package jsonschema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// ValidateYAML checks the YAML document against the schema and returns an
+// error with the line and column for every problem in it
+func (s *Schema) ValidateYAML(raw []byte) []error {
+	var document yaml.Node
+	if err := yaml.Unmarshal(raw, &document); err != nil {
+		return []error{err}
+	}
+	if len(document.Content) == 0 {
+		return nil
+	}
+	v := &validator{root: s}
+	v.validate(s, document.Content[0], "")
+	sort.SliceStable(v.problems, func(i, j int) bool {
+		if v.problems[i].Line != v.problems[j].Line {
+			return v.problems[i].Line < v.problems[j].Line
+		}
+		return v.problems[i].Column < v.problems[j].Column
+	})
+	var errs []error
+	for _, problem := range v.problems {
+		errs = append(errs, fmt.Errorf("line %d, column %d: %s", problem.Line, problem.Column, problem.message))
+	}
+	return errs
+}
+
+type problem struct {
+	*yaml.Node
+	message string
+}
+
+type validator struct {
+	root     *Schema
+	problems []problem
+}
+
+func (v *validator) errorf(node *yaml.Node, path, format string, args ...interface{}) {
+	if path == "" {
+		path = "<root>"
+	}
+	v.problems = append(v.problems, problem{Node: node, message: path + ": " + fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) validate(schema *Schema, node *yaml.Node, path string) {
+	if schema.Ref != "" {
+		definition, err := v.root.definition(schema.Ref)
+		if err != nil {
+			v.errorf(node, path, "%v", err)
+			return
+		}
+		schema = definition
+	}
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node.Kind == yaml.ScalarNode && node.ShortTag() == "!!null" {
+		// null unmarshals into the zero value of any type
+		return
+	}
+	switch schema.Type {
+	case "object":
+		v.validateObject(schema, node, path)
+	case "array":
+		if node.Kind != yaml.SequenceNode {
+			v.errorf(node, path, "expected a list, got %s", describe(node))
+			return
+		}
+		for i, item := range node.Content {
+			v.validate(schema.Items, item, fmt.Sprintf("%s[%d]", path, i))
+		}
+	case "string":
+		v.validateScalar(schema, node, path, "a string", "!!str")
+	case "integer":
+		v.validateScalar(schema, node, path, "an integer", "!!int")
+	case "number":
+		v.validateScalar(schema, node, path, "a number", "!!int", "!!float")
+	case "boolean":
+		v.validateScalar(schema, node, path, "a boolean", "!!bool")
+	}
+}
+
+func (v *validator) validateObject(schema *Schema, node *yaml.Node, path string) {
+	if node.Kind != yaml.MappingNode {
+		v.errorf(node, path, "expected an object, got %s", describe(node))
+		return
+	}
+	seen := sets.NewString()
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		seen.Insert(key.Value)
+		fieldPath := key.Value
+		if path != "" {
+			fieldPath = path + "." + key.Value
+		}
+		if property, ok := schema.Properties[key.Value]; ok {
+			v.validate(property, value, fieldPath)
+			continue
+		}
+		if additional, ok := schema.AdditionalProperties.(*Schema); ok {
+			v.validate(additional, value, fieldPath)
+			continue
+		}
+		v.errorf(key, fieldPath, "unknown field")
+	}
+	for _, required := range schema.Required {
+		if !seen.Has(required) {
+			v.errorf(node, path, "missing required field %s", required)
+		}
+	}
+}
+
+func (v *validator) validateScalar(schema *Schema, node *yaml.Node, path, expected string, tags ...string) {
+	if node.Kind != yaml.ScalarNode || !sets.NewString(tags...).Has(node.ShortTag()) {
+		v.errorf(node, path, "expected %s, got %s", expected, describe(node))
+		return
+	}
+	if len(schema.Enum) != 0 && !sets.NewString(schema.Enum...).Has(node.Value) {
+		v.errorf(node, path, "must be one of %s, got %q", strings.Join(schema.Enum, ", "), node.Value)
+	}
+}
+
+func describe(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "an object"
+	case yaml.SequenceNode:
+		return "a list"
+	default:
+		return fmt.Sprintf("%s %q", strings.TrimPrefix(node.ShortTag(), "!!"), node.Value)
+	}
+}
@@ -1,28 +1,34 @@
 package agents
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"regexp"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sirupsen/logrus"
 
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/yaml"
+
 	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/config"
 	"github.com/openshift/ci-tools/pkg/load"
+	"github.com/openshift/ci-tools/pkg/validation"
 )
 
 // ConfigAgent is an interface that can load configs from disk into
 // memory and retrieve them when provided with a config.Info.
 type ConfigAgent interface {
-	// GetMatchingConfig loads a configuration that matches the metadata,
-	// allowing for regex matching on branch names.
-	GetMatchingConfig(metadata api.Metadata) (api.ReleaseBuildConfiguration, error)
+	ConfigQuerier
 	GetAll() load.ByOrgRepo
 	GetGeneration() int
 	AddIndex(indexName string, indexFunc IndexFn) error
-	GetFromIndex(indexName string, indexKey string) ([]*api.ReleaseBuildConfiguration, error)
 }
 
 // IndexFn can be used to add indexes to the ConfigAgent
@@ -31,7 +37,9 @@ type IndexFn func(api.ReleaseBuildConfiguration) []string
 type configAgent struct {
 	lock         *sync.RWMutex
 	configs      load.ByOrgRepo
+	files        map[string]api.ReleaseBuildConfiguration
 	configPath   string
+	loader       *config.Loader
 	generation   int
 	errorMetrics *prometheus.CounterVec
 	indexFuncs   map[string]IndexFn
@@ -75,6 +83,9 @@ type ConfigAgentOptions struct {
 	// ErrorMetric holds the CounterVec to count errors on. It must include a `error` label
 	// or the agent panics on the first error.
 	ErrorMetric *prometheus.CounterVec
+	// Orgs limits the configs that are loaded and indexed to those of the
+	// given orgs. All configs are loaded if it is empty.
+	Orgs []string
 }
 
 type ConfigAgentOption func(*ConfigAgentOptions)
@@ -85,6 +96,14 @@ func WithConfigMetrics(m *prometheus.CounterVec) ConfigAgentOption {
 	}
 }
 
+// WithConfigOrgs makes the agent only load the configs of the given orgs, so
+// callers that only care about some orgs do not keep all configs in memory
+func WithConfigOrgs(orgs ...string) ConfigAgentOption {
+	return func(o *ConfigAgentOptions) {
+		o.Orgs = append(o.Orgs, orgs...)
+	}
+}
+
 // NewConfigAgent returns a ConfigAgent interface that automatically reloads when
 // configs are changed on disk.
 func NewConfigAgent(configPath string, opts ...ConfigAgentOption) (ConfigAgent, error) {
@@ -95,14 +114,14 @@ func NewConfigAgent(configPath string, opts ...ConfigAgentOption) (ConfigAgent,
 	if opt.ErrorMetric == nil {
 		opt.ErrorMetric = prometheus.NewCounterVec(prometheus.CounterOpts{Name: "config_agent_errors_total"}, []string{"error"})
 	}
-	a := &configAgent{configPath: configPath, lock: &sync.RWMutex{}, errorMetrics: opt.ErrorMetric}
+	a := &configAgent{configPath: configPath, loader: config.NewLoader(parseRuntimeConfig, config.OnlyOrgs(opt.Orgs...)), lock: &sync.RWMutex{}, errorMetrics: opt.ErrorMetric}
 	a.reloadConfig = a.loadFilenameToConfig
 	// Load config once so we fail early if that doesn't work and are ready as soon as we return
 	if err := a.reloadConfig(); err != nil {
 		return nil, fmt.Errorf("failed to laod config: %w", err)
 	}
 
-	return a, startWatchers(a.configPath, a.reloadConfig, a.recordError)
+	return a, startIncrementalWatchers(a.configPath, a.reloadConfig, a.reloadFiles, a.recordError)
 }
 
 func (a *configAgent) recordError(label string) {
@@ -195,11 +214,15 @@ func (a *configAgent) loadFilenameToConfig() error {
 		a.lock.Lock()
 		defer a.lock.Unlock()
 		startTime := time.Now()
-		configs, err := load.FromPathByOrgRepo(a.configPath)
+		configs, err := a.loader.Load(a.configPath)
 		if err != nil {
 			return time.Duration(0), fmt.Errorf("loading config failed: %w", err)
 		}
-		a.configs = configs
+		a.configs = byOrgRepo(configs)
+		a.files = map[string]api.ReleaseBuildConfiguration{}
+		for _, data := range configs {
+			a.files[data.Info.Filename] = data.Configuration
+		}
 		a.buildIndexes()
 		a.generation++
 		return time.Since(startTime), nil
@@ -212,32 +235,220 @@ func (a *configAgent) loadFilenameToConfig() error {
 	return nil
 }
 
+// errResyncRequired is returned when changes on disk can not be applied
+// one file at a time
+var errResyncRequired = errors.New("all configs need to be reloaded")
+
+// reloadFiles applies the changes to the given paths to the loaded configs
+// and indexes, and falls back to reloading all configs if that is not possible.
+func (a *configAgent) reloadFiles(paths sets.String) error {
+	err := a.updateFiles(paths)
+	if errors.Is(err, errResyncRequired) {
+		logrus.Debug("Changes can not be applied incrementally, reloading all configs")
+		return a.reloadConfig()
+	}
+	return err
+}
+
+// updateFiles re-parses the given files and replaces the configs they held
+// before. Only the org and repo maps that contain changed configs are copied,
+// so maps returned by GetAll are never modified.
+func (a *configAgent) updateFiles(paths sets.String) error {
+	logrus.WithField("files", paths.Len()).Debug("Reloading changed configs")
+	startTime := time.Now()
+	updated := map[string]*config.DataWithInfo{}
+	for _, path := range paths.List() {
+		if !a.includes(path) {
+			continue
+		}
+		info, err := os.Stat(path)
+		switch {
+		case os.IsNotExist(err):
+			updated[path] = nil
+		case err != nil:
+			return fmt.Errorf("could not check %s: %w", path, err)
+		case info.IsDir():
+			// files may have been moved into a directory without events for them
+			return errResyncRequired
+		case !isConfigFile(path):
+			continue
+		default:
+			data, err := a.loader.LoadFile(path)
+			if err != nil {
+				return fmt.Errorf("loading config failed: %w", err)
+			}
+			updated[path] = data
+		}
+	}
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	for path, data := range updated {
+		if _, known := a.files[path]; data == nil && !known && !isConfigFile(path) {
+			// a removed directory does not produce events for the files in it
+			return errResyncRequired
+		}
+	}
+
+	configs := make(load.ByOrgRepo, len(a.configs))
+	for org, repos := range a.configs {
+		configs[org] = repos
+	}
+	copiedOrgs := sets.NewString()
+	reposIn := func(org string) map[string][]api.ReleaseBuildConfiguration {
+		if !copiedOrgs.Has(org) {
+			repos := map[string][]api.ReleaseBuildConfiguration{}
+			for repo, repoConfigs := range configs[org] {
+				repos[repo] = repoConfigs
+			}
+			configs[org] = repos
+			copiedOrgs.Insert(org)
+		}
+		return configs[org]
+	}
+	for path, data := range updated {
+		if old, exists := a.files[path]; exists {
+			org, repo := old.Metadata.Org, old.Metadata.Repo
+			repos := reposIn(org)
+			repos[repo] = withoutConfig(repos[repo], old.Metadata)
+			a.unindexConfig(old)
+			delete(a.files, path)
+		}
+		if data == nil {
+			a.loader.Forget(path)
+		}
+	}
+	// configs are only added once all replaced ones are removed, as a renamed
+	// file may hold a config with the same metadata
+	for path, data := range updated {
+		if data == nil {
+			continue
+		}
+		org, repo := data.Configuration.Metadata.Org, data.Configuration.Metadata.Repo
+		repos := reposIn(org)
+		repos[repo] = append(append([]api.ReleaseBuildConfiguration{}, repos[repo]...), data.Configuration)
+		a.indexConfig(data.Configuration)
+		a.files[path] = data.Configuration
+	}
+	for org := range copiedOrgs {
+		for repo, repoConfigs := range configs[org] {
+			if len(repoConfigs) == 0 {
+				delete(configs[org], repo)
+			}
+		}
+		if len(configs[org]) == 0 {
+			delete(configs, org)
+		}
+	}
+	a.configs = configs
+	a.generation++
+
+	duration := time.Since(startTime)
+	configReloadTimeMetric.Observe(duration.Seconds())
+	logrus.WithFields(logrus.Fields{"duration": duration, "files": len(updated)}).Info("Changed configs reloaded")
+	return nil
+}
+
+// withoutConfig returns a copy of configs without the one for the given
+// metadata, which identifies configs as it determines their file name
+func withoutConfig(configs []api.ReleaseBuildConfiguration, metadata api.Metadata) []api.ReleaseBuildConfiguration {
+	remaining := make([]api.ReleaseBuildConfiguration, 0, len(configs))
+	for _, config := range configs {
+		if config.Metadata != metadata {
+			remaining = append(remaining, config)
+		}
+	}
+	return remaining
+}
+
+// includes determines whether the path is in the directory of an org whose
+// configs are loaded
+func (a *configAgent) includes(path string) bool {
+	rel, err := filepath.Rel(a.configPath, path)
+	if err != nil {
+		return false
+	}
+	return a.loader.Includes(strings.SplitN(filepath.ToSlash(rel), "/", 2)[0])
+}
+
+func isConfigFile(path string) bool {
+	extension := filepath.Ext(path)
+	return extension == ".yaml" || extension == ".yml"
+}
+
+// parseRuntimeConfig only validates what is needed to run the configuration, like
+// load.FromPathByOrgRepo does
+func parseRuntimeConfig(data []byte, _ *config.Info) (*api.ReleaseBuildConfiguration, error) {
+	configSpec := api.ReleaseBuildConfiguration{}
+	if err := yaml.UnmarshalStrict(data, &configSpec); err != nil {
+		return nil, fmt.Errorf("failed to load ci-operator config (%w)", err)
+	}
+	if err := validation.IsValidRuntimeConfiguration(&configSpec); err != nil {
+		return nil, fmt.Errorf("invalid ci-operator config: %w", err)
+	}
+	return &configSpec, nil
+}
+
+func byOrgRepo(configs config.DataByFilename) load.ByOrgRepo {
+	byOrgRepo := load.ByOrgRepo{}
+	for _, data := range configs {
+		org, repo := data.Configuration.Metadata.Org, data.Configuration.Metadata.Repo
+		if _, exists := byOrgRepo[org]; !exists {
+			byOrgRepo[org] = map[string][]api.ReleaseBuildConfiguration{}
+		}
+		byOrgRepo[org][repo] = append(byOrgRepo[org][repo], data.Configuration)
+	}
+	return byOrgRepo
+}
+
 func (a *configAgent) buildIndexes() {
 	a.indexes = map[string]configIndex{}
-	for indexName, indexFunc := range a.indexFuncs {
+	for indexName := range a.indexFuncs {
 		// Make sure the index always exists even if empty, otherwise we return a confusing
 		// "index does not exist error" in case its empty
-		if _, exists := a.indexes[indexName]; !exists {
-			a.indexes[indexName] = configIndex{}
-		}
-		for _, orgConfigs := range a.configs {
-			for _, repoConfigs := range orgConfigs {
-				for _, config := range repoConfigs {
-					var resusableConfigPtr *api.ReleaseBuildConfiguration
-
-					for _, indexKey := range indexFunc(config) {
-						if resusableConfigPtr == nil {
-							config := config
-							resusableConfigPtr = &config
-						}
-						if _, exists := a.indexes[indexName][indexKey]; !exists {
-							a.indexes[indexName][indexKey] = []*api.ReleaseBuildConfiguration{}
-						}
-
-						a.indexes[indexName][indexKey] = append(a.indexes[indexName][indexKey], resusableConfigPtr)
-					}
+		a.indexes[indexName] = configIndex{}
+	}
+	for _, orgConfigs := range a.configs {
+		for _, repoConfigs := range orgConfigs {
+			for _, config := range repoConfigs {
+				a.indexConfig(config)
+			}
+		}
+	}
+}
+
+// indexConfig adds the config to all indexes. Slices returned by GetFromIndex
+// are never changed below their length, so callers do not need to copy them.
+func (a *configAgent) indexConfig(config api.ReleaseBuildConfiguration) {
+	var resusableConfigPtr *api.ReleaseBuildConfiguration
+	for indexName, indexFunc := range a.indexFuncs {
+		for _, indexKey := range indexFunc(config) {
+			if resusableConfigPtr == nil {
+				resusableConfigPtr = &config
+			}
+			if _, exists := a.indexes[indexName]; !exists {
+				a.indexes[indexName] = configIndex{}
+			}
+			a.indexes[indexName][indexKey] = append(a.indexes[indexName][indexKey], resusableConfigPtr)
+		}
+	}
+}
+
+// unindexConfig removes the config from all indexes
+func (a *configAgent) unindexConfig(config api.ReleaseBuildConfiguration) {
+	for indexName, indexFunc := range a.indexFuncs {
+		for _, indexKey := range indexFunc(config) {
+			var remaining []*api.ReleaseBuildConfiguration
+			for _, indexed := range a.indexes[indexName][indexKey] {
+				if indexed.Metadata != config.Metadata {
+					remaining = append(remaining, indexed)
 				}
 			}
+			if len(remaining) == 0 {
+				delete(a.indexes[indexName], indexKey)
+				continue
+			}
+			a.indexes[indexName][indexKey] = remaining
 		}
 	}
 }
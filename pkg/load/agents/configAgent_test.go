@@ -1,12 +1,20 @@
 package agents
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"sync"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/yaml"
 
 	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/config"
 	"github.com/openshift/ci-tools/pkg/load"
 )
 
@@ -356,3 +364,165 @@ func TestConfigAgent_GetMatchingConfig(t *testing.T) {
 		}
 	}
 }
+
+func TestReloadFiles(t *testing.T) {
+	dir := t.TempDir()
+	pathFor := func(org, repo, branch string) string {
+		return filepath.Join(dir, org, repo, org+"-"+repo+"-"+branch+".yaml")
+	}
+	write := func(t *testing.T, org, repo, branch, commands string) string {
+		raw, err := yaml.Marshal(api.ReleaseBuildConfiguration{
+			Metadata:                api.Metadata{Org: org, Repo: repo, Branch: branch},
+			TestBinaryBuildCommands: commands,
+		})
+		if err != nil {
+			t.Fatalf("failed to marshal config: %v", err)
+		}
+		path := pathFor(org, repo, branch)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := ioutil.WriteFile(path, raw, 0644); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+		return path
+	}
+	remove := func(t *testing.T, path string) string {
+		if err := os.RemoveAll(path); err != nil {
+			t.Fatalf("failed to remove %s: %v", path, err)
+		}
+		return path
+	}
+	configFor := func(org, repo, branch, commands string) api.ReleaseBuildConfiguration {
+		return api.ReleaseBuildConfiguration{
+			Metadata:                api.Metadata{Org: org, Repo: repo, Branch: branch},
+			TestBinaryBuildCommands: commands,
+		}
+	}
+
+	write(t, "org", "repo", "master", "make test")
+	write(t, "org", "repo", "release", "make test")
+	write(t, "org", "other", "master", "make other")
+	agent := &configAgent{
+		lock:       &sync.RWMutex{},
+		configPath: dir,
+		loader: config.NewLoader(func(data []byte, _ *config.Info) (*api.ReleaseBuildConfiguration, error) {
+			var config api.ReleaseBuildConfiguration
+			return &config, yaml.UnmarshalStrict(data, &config)
+		}),
+		indexFuncs: map[string]IndexFn{
+			"commands": func(c api.ReleaseBuildConfiguration) []string { return []string{c.TestBinaryBuildCommands} },
+		},
+	}
+	agent.reloadConfig = agent.loadFilenameToConfig
+	if err := agent.reloadConfig(); err != nil {
+		t.Fatalf("failed to load configs: %v", err)
+	}
+	initial := agent.GetAll()
+
+	check := func(t *testing.T, expected load.ByOrgRepo, expectedIndex configIndex) {
+		if diff := cmp.Diff(expected, agent.GetAll(), sortConfigs); diff != "" {
+			t.Errorf("configs differ from expected: %s", diff)
+		}
+		if diff := cmp.Diff(expectedIndex, agent.indexes["commands"], sortConfigs); diff != "" {
+			t.Errorf("index differs from expected: %s", diff)
+		}
+	}
+
+	t.Run("changed, new and removed files are applied", func(t *testing.T) {
+		paths := sets.NewString(
+			write(t, "org", "repo", "master", "make changed"),
+			write(t, "new", "repo", "master", "make test"),
+			remove(t, pathFor("org", "other", "master")),
+			filepath.Join(dir, "org", "repo", ".config.prowgen"),
+		)
+		if err := agent.reloadFiles(paths); err != nil {
+			t.Fatalf("failed to reload files: %v", err)
+		}
+		check(t, load.ByOrgRepo{
+			"org": {"repo": {configFor("org", "repo", "release", "make test"), configFor("org", "repo", "master", "make changed")}},
+			"new": {"repo": {configFor("new", "repo", "master", "make test")}},
+		}, configIndex{
+			"make test":    {ptrTo(configFor("org", "repo", "release", "make test")), ptrTo(configFor("new", "repo", "master", "make test"))},
+			"make changed": {ptrTo(configFor("org", "repo", "master", "make changed"))},
+		})
+		if agent.GetGeneration() != 2 {
+			t.Errorf("expected generation 2, got %d", agent.GetGeneration())
+		}
+	})
+	t.Run("configs returned before are not modified", func(t *testing.T) {
+		if diff := cmp.Diff(load.ByOrgRepo{
+			"org": {
+				"repo":  {configFor("org", "repo", "master", "make test"), configFor("org", "repo", "release", "make test")},
+				"other": {configFor("org", "other", "master", "make other")},
+			},
+		}, initial, sortConfigs); diff != "" {
+			t.Errorf("configs differ from expected: %s", diff)
+		}
+	})
+	t.Run("removed directories cause a full reload", func(t *testing.T) {
+		if err := agent.reloadFiles(sets.NewString(remove(t, filepath.Join(dir, "new")))); err != nil {
+			t.Fatalf("failed to reload files: %v", err)
+		}
+		check(t, load.ByOrgRepo{
+			"org": {"repo": {configFor("org", "repo", "release", "make test"), configFor("org", "repo", "master", "make changed")}},
+		}, configIndex{
+			"make test":    {ptrTo(configFor("org", "repo", "release", "make test"))},
+			"make changed": {ptrTo(configFor("org", "repo", "master", "make changed"))},
+		})
+	})
+	t.Run("invalid files are not applied", func(t *testing.T) {
+		path := pathFor("org", "repo", "master")
+		if err := ioutil.WriteFile(path, []byte("invalid: config"), 0644); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+		if err := agent.reloadFiles(sets.NewString(path)); err == nil {
+			t.Error("expected an error, got none")
+		}
+		check(t, load.ByOrgRepo{
+			"org": {"repo": {configFor("org", "repo", "release", "make test"), configFor("org", "repo", "master", "make changed")}},
+		}, configIndex{
+			"make test":    {ptrTo(configFor("org", "repo", "release", "make test"))},
+			"make changed": {ptrTo(configFor("org", "repo", "master", "make changed"))},
+		})
+	})
+}
+
+func TestReloadFilesOnlyOrgs(t *testing.T) {
+	dir := t.TempDir()
+	agent := &configAgent{
+		lock:       &sync.RWMutex{},
+		configPath: dir,
+		loader:     config.NewLoader(nil, config.OnlyOrgs("org")),
+		configs:    load.ByOrgRepo{},
+		files:      map[string]api.ReleaseBuildConfiguration{},
+	}
+	agent.reloadConfig = func() error {
+		t.Error("expected no full reload")
+		return nil
+	}
+	path := filepath.Join(dir, "other", "repo", "other-repo-master.yaml")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	if err := ioutil.WriteFile(path, []byte("invalid: config"), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if err := agent.reloadFiles(sets.NewString(filepath.Join(dir, "other"), path)); err != nil {
+		t.Fatalf("expected changes in other orgs to be ignored, got: %v", err)
+	}
+	if diff := cmp.Diff(load.ByOrgRepo{}, agent.GetAll()); diff != "" {
+		t.Errorf("configs differ from expected: %s", diff)
+	}
+}
+
+var sortConfigs = cmp.Options{
+	cmpopts.SortSlices(func(a, b api.ReleaseBuildConfiguration) bool { return a.Metadata.Branch < b.Metadata.Branch }),
+	cmpopts.SortSlices(func(a, b *api.ReleaseBuildConfiguration) bool {
+		return a.Metadata.Org+a.Metadata.Branch < b.Metadata.Org+b.Metadata.Branch
+	}),
+}
+
+func ptrTo(config api.ReleaseBuildConfiguration) *api.ReleaseBuildConfiguration {
+	return &config
+}
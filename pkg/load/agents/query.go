@@ -0,0 +1,153 @@
+package agents
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/openshift/ci-tools/pkg/api"
+)
+
+const (
+	// QueryPathPrefix is the prefix of all paths served by NewConfigQueryHandler
+	QueryPathPrefix = "/query/"
+
+	matchingConfigQueryPath = QueryPathPrefix + "config"
+	indexQueryPath          = QueryPathPrefix + "index"
+
+	indexNameQuery = "name"
+	indexKeyQuery  = "key"
+)
+
+// ConfigQuerier looks up configurations, either in a ConfigAgent in the same
+// process or in one that is served by NewConfigQueryHandler.
+type ConfigQuerier interface {
+	// GetMatchingConfig loads a configuration that matches the metadata,
+	// allowing for regex matching on branch names.
+	GetMatchingConfig(metadata api.Metadata) (api.ReleaseBuildConfiguration, error)
+	GetFromIndex(indexName string, indexKey string) ([]*api.ReleaseBuildConfiguration, error)
+}
+
+// NewConfigQueryHandler serves lookups of configurations in the agent, so other
+// processes can use its indexes without loading all configurations themselves.
+// Only indexes that were added to the agent can be queried.
+func NewConfigQueryHandler(agent ConfigQuerier) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(matchingConfigQueryPath, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		metadata := api.Metadata{
+			Org:     query.Get("org"),
+			Repo:    query.Get("repo"),
+			Branch:  query.Get("branch"),
+			Variant: query.Get("variant"),
+		}
+		if metadata.Org == "" || metadata.Repo == "" || metadata.Branch == "" {
+			http.Error(w, "the org, repo and branch queries are required", http.StatusBadRequest)
+			return
+		}
+		config, err := agent.GetMatchingConfig(metadata)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to get config: %v", err), http.StatusNotFound)
+			return
+		}
+		respondWithJSON(w, config)
+	})
+	mux.HandleFunc(indexQueryPath, func(w http.ResponseWriter, r *http.Request) {
+		name, key := r.URL.Query().Get(indexNameQuery), r.URL.Query().Get(indexKeyQuery)
+		if name == "" || key == "" {
+			http.Error(w, "the name and key queries are required", http.StatusBadRequest)
+			return
+		}
+		configs, err := agent.GetFromIndex(name, key)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to get configs from index: %v", err), http.StatusNotFound)
+			return
+		}
+		if configs == nil {
+			configs = []*api.ReleaseBuildConfiguration{}
+		}
+		respondWithJSON(w, configs)
+	})
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, http.StatusText(http.StatusNotImplemented), http.StatusNotImplemented)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+func respondWithJSON(w http.ResponseWriter, data interface{}) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to marshal response: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(raw); err != nil {
+		logrus.WithError(err).Error("Failed to write response")
+	}
+}
+
+type configQueryClient struct {
+	address string
+	client  *http.Client
+}
+
+// NewConfigQueryClient returns a ConfigQuerier that looks up configurations in
+// the ConfigAgent served at the given address by NewConfigQueryHandler.
+func NewConfigQueryClient(address string, client *http.Client) ConfigQuerier {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &configQueryClient{address: address, client: client}
+}
+
+func (c *configQueryClient) GetMatchingConfig(metadata api.Metadata) (api.ReleaseBuildConfiguration, error) {
+	query := url.Values{}
+	query.Set("org", metadata.Org)
+	query.Set("repo", metadata.Repo)
+	query.Set("branch", metadata.Branch)
+	if metadata.Variant != "" {
+		query.Set("variant", metadata.Variant)
+	}
+	var config api.ReleaseBuildConfiguration
+	if err := c.get(matchingConfigQueryPath, query, &config); err != nil {
+		return api.ReleaseBuildConfiguration{}, err
+	}
+	return config, nil
+}
+
+func (c *configQueryClient) GetFromIndex(indexName string, indexKey string) ([]*api.ReleaseBuildConfiguration, error) {
+	query := url.Values{}
+	query.Set(indexNameQuery, indexName)
+	query.Set(indexKeyQuery, indexKey)
+	var configs []*api.ReleaseBuildConfiguration
+	if err := c.get(indexQueryPath, query, &configs); err != nil {
+		return nil, err
+	}
+	return configs, nil
+}
+
+func (c *configQueryClient) get(path string, query url.Values, into interface{}) error {
+	resp, err := c.client.Get(fmt.Sprintf("%s%s?%s", c.address, path, query.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to query %s: %w", c.address, err)
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response from %s: %w", c.address, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("got unexpected http %d status code from %s: %s", resp.StatusCode, c.address, data)
+	}
+	if err := json.Unmarshal(data, into); err != nil {
+		return fmt.Errorf("failed to unmarshal response from %s: %w", c.address, err)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package agents
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/load"
+)
+
+func TestConfigQueryClient(t *testing.T) {
+	master := api.ReleaseBuildConfiguration{
+		Metadata:                api.Metadata{Org: "org", Repo: "repo", Branch: "master"},
+		TestBinaryBuildCommands: "make test",
+	}
+	variant := api.ReleaseBuildConfiguration{
+		Metadata:                api.Metadata{Org: "org", Repo: "repo", Branch: "master", Variant: "variant"},
+		TestBinaryBuildCommands: "make test",
+	}
+	agent := NewFakeConfigAgent(load.ByOrgRepo{"org": {"repo": {master, variant}}})
+	if err := agent.AddIndex("commands", func(c api.ReleaseBuildConfiguration) []string {
+		return []string{c.TestBinaryBuildCommands}
+	}); err != nil {
+		t.Fatalf("failed to add index: %v", err)
+	}
+	server := httptest.NewServer(NewConfigQueryHandler(agent))
+	defer server.Close()
+	client := NewConfigQueryClient(server.URL, nil)
+
+	var testCases = []struct {
+		name          string
+		query         func() (interface{}, error)
+		expected      interface{}
+		expectedError string
+	}{
+		{
+			name: "matching config",
+			query: func() (interface{}, error) {
+				return client.GetMatchingConfig(api.Metadata{Org: "org", Repo: "repo", Branch: "master"})
+			},
+			expected: master,
+		},
+		{
+			name: "matching config with variant",
+			query: func() (interface{}, error) {
+				return client.GetMatchingConfig(api.Metadata{Org: "org", Repo: "repo", Branch: "master", Variant: "variant"})
+			},
+			expected: variant,
+		},
+		{
+			name: "no matching config",
+			query: func() (interface{}, error) {
+				return client.GetMatchingConfig(api.Metadata{Org: "org", Repo: "other", Branch: "master"})
+			},
+			expected:      api.ReleaseBuildConfiguration{},
+			expectedError: "got unexpected http 404 status code from " + server.URL + ": failed to get config: could not find any config for repo org/other\n",
+		},
+		{
+			name: "configs from index",
+			query: func() (interface{}, error) {
+				return client.GetFromIndex("commands", "make test")
+			},
+			expected: []*api.ReleaseBuildConfiguration{&master, &variant},
+		},
+		{
+			name: "no configs for index key",
+			query: func() (interface{}, error) {
+				return client.GetFromIndex("commands", "make other")
+			},
+			expected: []*api.ReleaseBuildConfiguration{},
+		},
+		{
+			name: "missing index",
+			query: func() (interface{}, error) {
+				return client.GetFromIndex("missing", "key")
+			},
+			expected:      []*api.ReleaseBuildConfiguration(nil),
+			expectedError: "got unexpected http 404 status code from " + server.URL + ": failed to get configs from index: no index missing configured\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := tc.query()
+			var actualError string
+			if err != nil {
+				actualError = err.Error()
+			}
+			if diff := cmp.Diff(tc.expectedError, actualError); diff != "" {
+				t.Errorf("unexpected error: %s", diff)
+			}
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Errorf("unexpected result: %s", diff)
+			}
+		})
+	}
+}
+
+func TestConfigQueryHandler(t *testing.T) {
+	server := httptest.NewServer(NewConfigQueryHandler(NewFakeConfigAgent(load.ByOrgRepo{})))
+	defer server.Close()
+	var testCases = []struct {
+		name     string
+		method   string
+		path     string
+		expected int
+	}{
+		{name: "missing metadata", method: http.MethodGet, path: "/query/config?org=org&repo=repo", expected: http.StatusBadRequest},
+		{name: "missing index key", method: http.MethodGet, path: "/query/index?name=index", expected: http.StatusBadRequest},
+		{name: "unknown path", method: http.MethodGet, path: "/query/other", expected: http.StatusNotFound},
+		{name: "wrong method", method: http.MethodPost, path: "/query/index?name=index&key=key", expected: http.StatusNotImplemented},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(tc.method, server.URL+tc.path, nil)
+			if err != nil {
+				t.Fatalf("failed to create request: %v", err)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tc.expected {
+				t.Errorf("expected status %d, got %d", tc.expected, resp.StatusCode)
+			}
+		})
+	}
+}
@@ -2,12 +2,18 @@ package agents
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	log "github.com/sirupsen/logrus"
 	"gopkg.in/fsnotify.v1"
 
+	"k8s.io/apimachinery/pkg/util/sets"
+
 	"k8s.io/test-infra/prow/config"
 	"k8s.io/test-infra/prow/interrupts"
 )
@@ -62,3 +68,114 @@ func startWatchers(path string, callback func() error, recordError func(string))
 	}
 	return nil
 }
+
+// eventBatchPeriod is how long changes on disk are gathered before they are
+// applied, so that a checkout touching many files results in a single update
+var eventBatchPeriod = 500 * time.Millisecond
+
+// startIncrementalWatchers watches the same paths as startWatchers, but passes
+// the files that changed in plain directories to update instead of reloading
+// everything. ConfigMap mounts are swapped atomically and are always reloaded.
+func startIncrementalWatchers(path string, reload func() error, update func(sets.String) error, recordError func(string)) error {
+	cms, dirs, err := config.ListCMsAndDirs(path)
+	if err != nil {
+		return err
+	}
+	errFunc := func(err error, msg string) {
+		recordError(msg)
+		log.WithError(err).Error(msg)
+	}
+	var watchers []func(context.Context)
+	for cm := range cms {
+		watcher, err := config.GetCMMountWatcher(reload, errFunc, cm)
+		if err != nil {
+			return err
+		}
+		watchers = append(watchers, watcher)
+	}
+	if len(dirs) != 0 {
+		watcher, err := getBatchingDirWatcher(reload, update, errFunc, dirs.UnsortedList()...)
+		if err != nil {
+			return err
+		}
+		watchers = append(watchers, watcher)
+	}
+	for _, watcher := range watchers {
+		interrupts.Run(watcher)
+	}
+	return nil
+}
+
+// getBatchingDirWatcher returns a function that watches the directories and
+// runs update with all paths that had events during eventBatchPeriod. When
+// the kernel dropped events, reload is run instead.
+func getBatchingDirWatcher(reload func() error, update func(sets.String) error, errFunc func(error, string), dirs ...string) (func(ctx context.Context), error) {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	for _, dir := range dirs {
+		if err := w.Add(dir); err != nil {
+			return nil, err
+		}
+	}
+	logrus.Debugf("Watching directories: %v", dirs)
+	return func(ctx context.Context) {
+		changed := sets.NewString()
+		var batch <-chan time.Time
+		for {
+			select {
+			case <-ctx.Done():
+				if err := w.Close(); err != nil {
+					errFunc(err, "failed to close fsnotify watcher for directories")
+				}
+				return
+			case event := <-w.Events:
+				changed.Insert(event.Name)
+				if batch == nil {
+					batch = time.After(eventBatchPeriod)
+				}
+			case <-batch:
+				if err := update(changed); err != nil {
+					errFunc(err, "failed to apply changes in watched directories")
+				}
+				if err := watchNewDirs(w, changed); err != nil {
+					errFunc(err, "failed to update watcher")
+				}
+				changed, batch = sets.NewString(), nil
+			case err := <-w.Errors:
+				if !errors.Is(err, fsnotify.ErrEventOverflow) {
+					errFunc(err, "received fsnotify error watching directories")
+					continue
+				}
+				logrus.Warn("Filesystem events were lost, reloading everything")
+				if err := reload(); err != nil {
+					errFunc(err, "failed to reload after losing events")
+				}
+				changed, batch = sets.NewString(), nil
+			}
+		}
+	}, nil
+}
+
+// watchNewDirs adds all directories at or below the paths to the watcher.
+// Adding a directory that is already watched is a no-op.
+func watchNewDirs(w *fsnotify.Watcher, paths sets.String) error {
+	for _, path := range paths.List() {
+		if info, err := os.Stat(path); err != nil || !info.IsDir() {
+			continue
+		}
+		if err := filepath.Walk(path, func(path string, info os.FileInfo, err error) error {
+			if err != nil || !info.IsDir() {
+				return err
+			}
+			if err := w.Add(path); err != nil {
+				return fmt.Errorf("Failed to update watcher: %w", err)
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+package prowgen
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path"
+
+	"github.com/ghodss/yaml"
+
+	corev1 "k8s.io/api/core/v1"
+	prowv1 "k8s.io/test-infra/prow/apis/prowjobs/v1"
+	prowconfig "k8s.io/test-infra/prow/config"
+
+	cioperatorapi "github.com/openshift/ci-tools/pkg/api"
+	jc "github.com/openshift/ci-tools/pkg/jobconfig"
+)
+
+// Policy holds the defaults applied to generated jobs across the fleet, so
+// they can change without touching the generator or every ci-operator config.
+// All rules matching a repository apply in order, so later rules override the
+// defaults of earlier ones. Values set by the generator for a specific job, like
+// the cluster of a test, always win over the defaults. The resources of the
+// ci-operator container are the exception: the generator sets the same ones for
+// all jobs, so the policy overrides them.
+type Policy struct {
+	Rules []PolicyRule `json:"rules,omitempty"`
+}
+
+// PolicyRule holds the defaults for the jobs of the repositories it matches
+type PolicyRule struct {
+	// Repos are globs matching the repositories in org/repo form, e.g. `openshift/*`
+	Repos []string `json:"repos"`
+	// Decoration holds defaults for the decoration of the jobs, e.g. the timeout
+	Decoration *prowv1.DecorationConfig `json:"decoration,omitempty"`
+	// Cluster is the build cluster the jobs run on when their test does not set one
+	Cluster cioperatorapi.Cluster `json:"cluster,omitempty"`
+	// Resources are the requests and limits of the ci-operator container. They
+	// override those the generator sets for the same resources, the others are kept.
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+	// BranchProtection holds hints for the branch protection of the repositories
+	BranchProtection *BranchProtectionHints `json:"branch_protection,omitempty"`
+}
+
+// BranchProtectionHints describe how presubmits relate to branch protection
+type BranchProtectionHints struct {
+	// OptionalTests are globs matching the tests whose presubmits are not
+	// required for a pull request to merge
+	OptionalTests []string `json:"optional_tests,omitempty"`
+}
+
+// LoadPolicy reads and validates the policy at the path
+func LoadPolicy(path string) (*Policy, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the prowgen policy: %w", err)
+	}
+	var policy Policy
+	if err := yaml.Unmarshal(raw, &policy); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal the prowgen policy: %w", err)
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid prowgen policy: %w", err)
+	}
+	return &policy, nil
+}
+
+// Validate ensures all globs of the policy can be matched
+func (p *Policy) Validate() error {
+	for i, rule := range p.Rules {
+		if len(rule.Repos) == 0 {
+			return fmt.Errorf("rules[%d].repos: at least one glob is required", i)
+		}
+		for j, glob := range rule.Repos {
+			if _, err := path.Match(glob, ""); err != nil {
+				return fmt.Errorf("rules[%d].repos[%d]: invalid glob %q: %w", i, j, glob, err)
+			}
+		}
+		if rule.Cluster != "" && !cioperatorapi.ValidClusterNames.Has(string(rule.Cluster)) {
+			return fmt.Errorf("rules[%d].cluster: invalid cluster name %q", i, rule.Cluster)
+		}
+		if rule.BranchProtection != nil {
+			for j, glob := range rule.BranchProtection.OptionalTests {
+				if _, err := path.Match(glob, ""); err != nil {
+					return fmt.Errorf("rules[%d].branch_protection.optional_tests[%d]: invalid glob %q: %w", i, j, glob, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// matches determines whether any of the globs matches the name. The globs
+// are validated when the policy is loaded, so errors cannot happen here.
+func matches(globs []string, name string) bool {
+	for _, glob := range globs {
+		if matched, _ := path.Match(glob, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply applies the defaults of the rules matching the repository of the
+// jobs generated for it
+func (p *Policy) Apply(info *ProwgenInfo, jobConfig *prowconfig.JobConfig) {
+	if p == nil {
+		return
+	}
+	var merged PolicyRule
+	var optionalTests []string
+	for _, rule := range p.Rules {
+		if !matches(rule.Repos, fmt.Sprintf("%s/%s", info.Org, info.Repo)) {
+			continue
+		}
+		merged.Decoration = rule.Decoration.ApplyDefault(merged.Decoration)
+		if rule.Cluster != "" {
+			merged.Cluster = rule.Cluster
+		}
+		if rule.Resources != nil {
+			if merged.Resources == nil {
+				merged.Resources = &corev1.ResourceRequirements{}
+			}
+			mergeResources(merged.Resources, rule.Resources)
+		}
+		if rule.BranchProtection != nil {
+			optionalTests = append(optionalTests, rule.BranchProtection.OptionalTests...)
+		}
+	}
+
+	for repo := range jobConfig.PresubmitsStatic {
+		for i := range jobConfig.PresubmitsStatic[repo] {
+			presubmit := &jobConfig.PresubmitsStatic[repo][i]
+			merged.applyTo(&presubmit.JobBase)
+			if matches(optionalTests, info.TestNameFromJobName(presubmit.Name, jc.PresubmitPrefix)) {
+				presubmit.Optional = true
+			}
+		}
+	}
+	for repo := range jobConfig.PostsubmitsStatic {
+		for i := range jobConfig.PostsubmitsStatic[repo] {
+			merged.applyTo(&jobConfig.PostsubmitsStatic[repo][i].JobBase)
+		}
+	}
+	for i := range jobConfig.Periodics {
+		merged.applyTo(&jobConfig.Periodics[i].JobBase)
+	}
+}
+
+func (r PolicyRule) applyTo(job *prowconfig.JobBase) {
+	if r.Decoration != nil {
+		job.DecorationConfig = job.DecorationConfig.ApplyDefault(r.Decoration)
+	}
+	if r.Cluster != "" {
+		if job.Labels == nil {
+			job.Labels = map[string]string{}
+		}
+		if _, set := job.Labels[cioperatorapi.ClusterLabel]; !set {
+			job.Labels[cioperatorapi.ClusterLabel] = string(r.Cluster)
+		}
+	}
+	if r.Resources != nil && job.Spec != nil && len(job.Spec.Containers) != 0 {
+		mergeResources(&job.Spec.Containers[0].Resources, r.Resources)
+	}
+}
+
+// mergeResources sets the requests and limits of the overrides on the resources,
+// keeping the values of resources the overrides do not mention
+func mergeResources(resources, overrides *corev1.ResourceRequirements) {
+	for _, lists := range []struct {
+		into *corev1.ResourceList
+		from corev1.ResourceList
+	}{
+		{into: &resources.Requests, from: overrides.Requests},
+		{into: &resources.Limits, from: overrides.Limits},
+	} {
+		if len(lists.from) == 0 {
+			continue
+		}
+		if *lists.into == nil {
+			*lists.into = corev1.ResourceList{}
+		}
+		for name, quantity := range lists.from {
+			(*lists.into)[name] = quantity.DeepCopy()
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package prowgen
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	prowv1 "k8s.io/test-infra/prow/apis/prowjobs/v1"
+	utilpointer "k8s.io/utils/pointer"
+
+	cioperatorapi "github.com/openshift/ci-tools/pkg/api"
+)
+
+func TestPolicyApply(t *testing.T) {
+	hour := &prowv1.Duration{Duration: time.Hour}
+	twoHours := &prowv1.Duration{Duration: 2 * time.Hour}
+	resources := &corev1.ResourceRequirements{Requests: corev1.ResourceList{"cpu": resource.MustParse("100m")}}
+	memory := &corev1.ResourceRequirements{Limits: corev1.ResourceList{"memory": resource.MustParse("4Gi")}}
+	policy := &Policy{Rules: []PolicyRule{
+		{
+			Repos:      []string{"*/*"},
+			Decoration: &prowv1.DecorationConfig{Timeout: hour, GracePeriod: hour},
+			Cluster:    cioperatorapi.ClusterBuild01,
+		},
+		{
+			Repos:            []string{"openshift/*"},
+			Decoration:       &prowv1.DecorationConfig{Timeout: twoHours},
+			Resources:        resources,
+			BranchProtection: &BranchProtectionHints{OptionalTests: []string{"e2e-*"}},
+		},
+		{
+			Repos:   []string{"other/*"},
+			Cluster: cioperatorapi.ClusterBuild02,
+		},
+		{
+			Repos:     []string{"*/repo"},
+			Resources: memory,
+		},
+	}}
+	info := &ProwgenInfo{Metadata: cioperatorapi.Metadata{Org: "openshift", Repo: "repo", Branch: "master"}}
+	jobConfig := GenerateJobs(&cioperatorapi.ReleaseBuildConfiguration{
+		Tests: []cioperatorapi.TestStepConfiguration{
+			{As: "unit", ContainerTestConfiguration: &cioperatorapi.ContainerTestConfiguration{From: "src"}},
+			{As: "e2e-aws", Cluster: cioperatorapi.ClusterBuild02, ContainerTestConfiguration: &cioperatorapi.ContainerTestConfiguration{From: "src"}},
+		},
+	}, info)
+
+	policy.Apply(info, jobConfig)
+
+	presubmits := jobConfig.PresubmitsStatic["openshift/repo"]
+	if len(presubmits) != 2 {
+		t.Fatalf("expected two presubmits, got %d", len(presubmits))
+	}
+	unit, e2e := presubmits[0], presubmits[1]
+	expectedDecoration := &prowv1.DecorationConfig{Timeout: twoHours, GracePeriod: hour, SkipCloning: utilpointer.BoolPtr(true)}
+	for _, job := range presubmits {
+		if diff := cmp.Diff(expectedDecoration, job.DecorationConfig); diff != "" {
+			t.Errorf("%s: unexpected decoration: %s", job.Name, diff)
+		}
+	}
+	if diff := cmp.Diff(string(cioperatorapi.ClusterBuild01), unit.Labels[cioperatorapi.ClusterLabel]); diff != "" {
+		t.Errorf("unexpected cluster for the job without one: %s", diff)
+	}
+	if diff := cmp.Diff(string(cioperatorapi.ClusterBuild02), e2e.Labels[cioperatorapi.ClusterLabel]); diff != "" {
+		t.Errorf("the cluster of the test was overridden: %s", diff)
+	}
+	expectedResources := corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{"cpu": resource.MustParse("100m")},
+		Limits:   corev1.ResourceList{"memory": resource.MustParse("4Gi")},
+	}
+	if diff := cmp.Diff(expectedResources, unit.Spec.Containers[0].Resources); diff != "" {
+		t.Errorf("unexpected resources: %s", diff)
+	}
+	if unit.Optional || !e2e.Optional {
+		t.Errorf("expected only the e2e presubmit to be optional, got unit=%t, e2e=%t", unit.Optional, e2e.Optional)
+	}
+}
+
+func TestPolicyApplyResources(t *testing.T) {
+	policy := &Policy{Rules: []PolicyRule{{
+		Repos:     []string{"*/*"},
+		Resources: &corev1.ResourceRequirements{Requests: corev1.ResourceList{"memory": resource.MustParse("200Mi")}},
+	}}}
+	info := &ProwgenInfo{Metadata: cioperatorapi.Metadata{Org: "openshift", Repo: "repo", Branch: "master"}}
+	jobConfig := GenerateJobs(&cioperatorapi.ReleaseBuildConfiguration{
+		Tests: []cioperatorapi.TestStepConfiguration{
+			{As: "unit", ContainerTestConfiguration: &cioperatorapi.ContainerTestConfiguration{From: "src"}},
+		},
+	}, info)
+
+	policy.Apply(info, jobConfig)
+
+	// the cpu request of the generator is kept, as the policy does not set one
+	expected := corev1.ResourceRequirements{Requests: corev1.ResourceList{"cpu": resource.MustParse("10m"), "memory": resource.MustParse("200Mi")}}
+	if diff := cmp.Diff(expected, jobConfig.PresubmitsStatic["openshift/repo"][0].Spec.Containers[0].Resources); diff != "" {
+		t.Errorf("unexpected resources: %s", diff)
+	}
+}
+
+func TestPolicyValidate(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		policy   Policy
+		expected string
+	}{
+		{
+			name:   "valid policy",
+			policy: Policy{Rules: []PolicyRule{{Repos: []string{"openshift/*"}, Cluster: cioperatorapi.ClusterBuild01}}},
+		},
+		{
+			name:     "rule without repos",
+			policy:   Policy{Rules: []PolicyRule{{Cluster: cioperatorapi.ClusterBuild01}}},
+			expected: "rules[0].repos: at least one glob is required",
+		},
+		{
+			name:     "invalid repo glob",
+			policy:   Policy{Rules: []PolicyRule{{Repos: []string{"openshift/["}}}},
+			expected: `rules[0].repos[0]: invalid glob "openshift/[": syntax error in pattern`,
+		},
+		{
+			name:     "unknown cluster",
+			policy:   Policy{Rules: []PolicyRule{{Repos: []string{"*/*"}, Cluster: "build99"}}},
+			expected: `rules[0].cluster: invalid cluster name "build99"`,
+		},
+		{
+			name:     "invalid test glob",
+			policy:   Policy{Rules: []PolicyRule{{Repos: []string{"*/*"}, BranchProtection: &BranchProtectionHints{OptionalTests: []string{"["}}}}},
+			expected: `rules[0].branch_protection.optional_tests[0]: invalid glob "[": syntax error in pattern`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var actual string
+			if err := tc.policy.Validate(); err != nil {
+				actual = err.Error()
+			}
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Errorf("unexpected error: %s", diff)
+			}
+		})
+	}
+}
@@ -161,6 +161,9 @@ func GenerateJobs(configSpec *cioperatorapi.ReleaseBuildConfiguration, info *Pro
 				secrets = append(secrets, &api.Secret{Name: api.HiveControlPlaneKubeconfigSecret})
 			}
 			podSpec = generateCiOperatorPodSpec(info, secrets, []string{element.As}, additionalArgs...)
+			if len(element.Leases) != 0 {
+				addLeaseClient(podSpec)
+			}
 		} else if element.MultiStageTestConfiguration != nil {
 			podSpec = generatePodSpecMultiStage(info, &element, configSpec.Releases != nil || element.ClusterClaim != nil)
 		} else {
@@ -171,37 +174,42 @@ func GenerateJobs(configSpec *cioperatorapi.ReleaseBuildConfiguration, info *Pro
 			podSpec = generatePodSpecTemplate(info, release, &element)
 		}
 
-		if element.Cron != nil || element.Interval != nil || element.ReleaseController {
-			cron := ""
-			if element.Cron != nil {
-				cron = *element.Cron
-			}
-			interval := ""
-			if element.Interval != nil {
-				interval = *element.Interval
-			}
-			periodic := generatePeriodicForTest(element.As, info, podSpec, true, cron, interval, element.ReleaseController, configSpec.CanonicalGoRepository, jobRelease, skipCloning)
-			if element.Cluster != "" {
-				periodic.Labels[cioperatorapi.ClusterLabel] = string(element.Cluster)
-			}
-			periodics = append(periodics, *periodic)
-		} else if element.Postsubmit {
-			postsubmit := generatePostsubmitForTest(element.As, info, podSpec, configSpec.CanonicalGoRepository, jobRelease, skipCloning)
-			postsubmit.MaxConcurrency = 1
-			if element.Cluster != "" {
-				postsubmit.Labels[cioperatorapi.ClusterLabel] = string(element.Cluster)
+		for _, variant := range testVariants(element, podSpec) {
+			if element.Cron != nil || element.Interval != nil || element.ReleaseController {
+				cron := ""
+				if element.Cron != nil {
+					cron = *element.Cron
+				}
+				interval := ""
+				if element.Interval != nil {
+					interval = *element.Interval
+				}
+				periodic := generatePeriodicForTest(variant.name, info, variant.podSpec, true, cron, interval, element.ReleaseController, configSpec.CanonicalGoRepository, jobRelease, skipCloning)
+				if element.Cluster != "" && variant.architecture == "" {
+					periodic.Labels[cioperatorapi.ClusterLabel] = string(element.Cluster)
+				}
+				variant.addLabels(periodic.Labels)
+				periodics = append(periodics, *periodic)
+			} else if element.Postsubmit {
+				postsubmit := generatePostsubmitForTest(variant.name, info, variant.podSpec, configSpec.CanonicalGoRepository, jobRelease, skipCloning)
+				postsubmit.MaxConcurrency = 1
+				if element.Cluster != "" {
+					postsubmit.Labels[cioperatorapi.ClusterLabel] = string(element.Cluster)
+				}
+				variant.addLabels(postsubmit.Labels)
+				postsubmits[orgrepo] = append(postsubmits[orgrepo], *postsubmit)
+			} else {
+				presubmit := *generatePresubmitForTest(variant.name, info, variant.podSpec, configSpec.CanonicalGoRepository, jobRelease, skipCloning)
+				v, requestingKVM := configSpec.Resources.RequirementsForStep(element.As).Requests[cioperatorapi.KVMDeviceLabel]
+				if requestingKVM {
+					presubmit.Labels[cioperatorapi.KVMDeviceLabel] = v
+				}
+				if element.Cluster != "" && variant.architecture == "" {
+					presubmit.Labels[cioperatorapi.ClusterLabel] = string(element.Cluster)
+				}
+				variant.addLabels(presubmit.Labels)
+				presubmits[orgrepo] = append(presubmits[orgrepo], presubmit)
 			}
-			postsubmits[orgrepo] = append(postsubmits[orgrepo], *postsubmit)
-		} else {
-			presubmit := *generatePresubmitForTest(element.As, info, podSpec, configSpec.CanonicalGoRepository, jobRelease, skipCloning)
-			v, requestingKVM := configSpec.Resources.RequirementsForStep(element.As).Requests[cioperatorapi.KVMDeviceLabel]
-			if requestingKVM {
-				presubmit.Labels[cioperatorapi.KVMDeviceLabel] = v
-			}
-			if element.Cluster != "" {
-				presubmit.Labels[cioperatorapi.ClusterLabel] = string(element.Cluster)
-			}
-			presubmits[orgrepo] = append(presubmits[orgrepo], presubmit)
 		}
 	}
 
@@ -275,6 +283,39 @@ func GenerateJobs(configSpec *cioperatorapi.ReleaseBuildConfiguration, info *Pro
 	}
 }
 
+// testVariant is a job generated for a test: the test itself or the test on
+// one of its additional architectures
+type testVariant struct {
+	name         string
+	podSpec      *corev1.PodSpec
+	architecture cioperatorapi.ReleaseArchitecture
+}
+
+// testVariants returns the jobs to generate for the test, one for the test
+// itself and one for each of the architectures it runs on in addition. The
+// jobs for architectures tell ci-operator to run the builds and the tests on
+// nodes of the architecture and are labeled, so they are dispatched to a build
+// cluster that has such nodes.
+func testVariants(test cioperatorapi.TestStepConfiguration, podSpec *corev1.PodSpec) []testVariant {
+	variants := []testVariant{{name: test.As, podSpec: podSpec}}
+	for _, architecture := range test.Architectures {
+		archPodSpec := podSpec.DeepCopy()
+		archPodSpec.Containers[0].Args = append(archPodSpec.Containers[0].Args, fmt.Sprintf("--node-architecture=%s", architecture))
+		variants = append(variants, testVariant{
+			name:         cioperatorapi.ArchitectureTestName(test.As, architecture),
+			podSpec:      archPodSpec,
+			architecture: architecture,
+		})
+	}
+	return variants
+}
+
+func (v testVariant) addLabels(labels map[string]string) {
+	if v.architecture != "" {
+		labels[cioperatorapi.ArchitectureLabel] = string(v.architecture)
+	}
+}
+
 func generateCiOperatorPodSpec(info *ProwgenInfo, secrets []*cioperatorapi.Secret, targets []string, additionalArgs ...string) *corev1.PodSpec {
 	for _, arg := range additionalArgs {
 		if !strings.HasPrefix(arg, "--") {
@@ -336,6 +377,15 @@ func generatePodSpecMultiStage(info *ProwgenInfo, test *cioperatorapi.TestStepCo
 	container := &podSpec.Containers[0]
 	container.Args = append(container.Args, fmt.Sprintf("--secret-dir=%s", clusterProfilePath))
 	container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{Name: "cluster-profile", MountPath: clusterProfilePath})
+	// the profile is only chosen at runtime, so all of them are mounted
+	for _, fallback := range test.MultiStageTestConfiguration.FallbackClusterProfiles {
+		volume := generateClusterProfileVolume(fallback, fallback.ClusterType())
+		volume.Name = fmt.Sprintf("cluster-profile-%s", fallback)
+		path := fmt.Sprintf("%s-%s", clusterProfilePath, fallback)
+		podSpec.Volumes = append(podSpec.Volumes, volume)
+		container.Args = append(container.Args, fmt.Sprintf("--secret-dir=%s", path))
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{Name: volume.Name, MountPath: path})
+	}
 	addLeaseClient(podSpec)
 	return podSpec
 }
@@ -122,6 +122,16 @@ func TestGeneratePodSpecMultiStage(t *testing.T) {
 				},
 			},
 		},
+		{
+			description: "aws cluster profile with fallbacks",
+			test: &ciop.TestStepConfiguration{
+				As: "test",
+				MultiStageTestConfiguration: &ciop.MultiStageTestConfiguration{
+					ClusterProfile:          ciop.ClusterProfileAWS,
+					FallbackClusterProfiles: []ciop.ClusterProfile{ciop.ClusterProfileGCP, ciop.ClusterProfileAzure4},
+				},
+			},
+		},
 	}
 
 	for _, tc := range tests {
@@ -556,6 +566,35 @@ func TestGenerateJobs(t *testing.T) {
 				Branch: "branch",
 			}},
 		},
+		{
+			id:   "container test with leases uses the lease server",
+			keep: true,
+			config: &ciop.ReleaseBuildConfiguration{
+				Tests: []ciop.TestStepConfiguration{
+					{As: "unit", ContainerTestConfiguration: &ciop.ContainerTestConfiguration{From: "bin"}, Leases: []ciop.StepLease{{ResourceType: "aws-quota-slice", Env: "ACCOUNT"}}},
+				},
+			},
+			repoInfo: &ProwgenInfo{Metadata: ciop.Metadata{
+				Org:    "organization",
+				Repo:   "repository",
+				Branch: "branch",
+			}},
+		},
+		{
+			id:   "tests on additional architectures",
+			keep: true,
+			config: &ciop.ReleaseBuildConfiguration{
+				Tests: []ciop.TestStepConfiguration{
+					{As: "unit", Architectures: []ciop.ReleaseArchitecture{ciop.ReleaseArchitectureARM64}, ContainerTestConfiguration: &ciop.ContainerTestConfiguration{From: "bin"}},
+					{As: "e2e", Cron: &cron, Cluster: "build01", Architectures: []ciop.ReleaseArchitecture{ciop.ReleaseArchitectureARM64}, MultiStageTestConfiguration: &ciop.MultiStageTestConfiguration{}},
+				},
+			},
+			repoInfo: &ProwgenInfo{Metadata: ciop.Metadata{
+				Org:    "organization",
+				Repo:   "repository",
+				Branch: "branch",
+			}},
+		},
 		{
 			id: "cluster label for postsubmit",
 			config: &ciop.ReleaseBuildConfiguration{
@@ -24,7 +24,12 @@ type ObserverByName map[string]api.Observer
 func Validate(stepsByName ReferenceByName, chainsByName ChainByName, workflowsByName WorkflowByName, observersByName ObserverByName) error {
 	reg := registry{stepsByName, chainsByName, workflowsByName, observersByName}
 	var ret []error
-	for k := range chainsByName {
+	for k, v := range chainsByName {
+		for _, param := range v.Environment {
+			if err := param.Validate(); err != nil {
+				ret = append(ret, fmt.Errorf("chain/%s: %w", k, err))
+			}
+		}
 		if _, err := reg.process([]api.TestStep{{Chain: &k}}, sets.NewString(), stackForChain()); err != nil {
 			ret = append(ret, err...)
 		}
@@ -68,7 +73,9 @@ func (r *registry) Resolve(name string, config api.MultiStageTestConfiguration)
 			return api.MultiStageTestConfigurationLiteral{}, fmt.Errorf("no workflow named %s", *config.Workflow)
 		}
 		if config.ClusterProfile == "" {
+			// the fallbacks of the workflow only make sense for its profile
 			config.ClusterProfile = workflow.ClusterProfile
+			config.FallbackClusterProfiles = workflow.FallbackClusterProfiles
 		}
 		if config.Pre == nil {
 			config.Pre = workflow.Pre
@@ -92,12 +99,17 @@ func (r *registry) Resolve(name string, config api.MultiStageTestConfiguration)
 		if config.AllowBestEffortPostSteps == nil {
 			config.AllowBestEffortPostSteps = workflow.AllowBestEffortPostSteps
 		}
+		if config.Gather == nil {
+			config.Gather = workflow.Gather
+		}
 	}
 	expandedFlow := api.MultiStageTestConfigurationLiteral{
 		ClusterProfile:           config.ClusterProfile,
+		FallbackClusterProfiles:  config.FallbackClusterProfiles,
 		AllowSkipOnSuccess:       config.AllowSkipOnSuccess,
 		AllowBestEffortPostSteps: config.AllowBestEffortPostSteps,
 		Leases:                   config.Leases,
+		Gather:                   config.Gather,
 	}
 	stack := stackForTest(name, config.Environment, config.Dependencies)
 	if config.Workflow != nil {
@@ -249,6 +261,11 @@ func (r *registry) processStep(step *api.TestStep, seen sets.String, stack stack
 			} else if e.Default == nil && !stack.partial {
 				errs = append(errs, stack.errorf("step/%s: unresolved parameter: %s", ret.As, e.Name))
 			}
+			if e.Default != nil {
+				if err := e.ValidateValue(*e.Default); err != nil {
+					errs = append(errs, stack.errorf("step/%s: %v", ret.As, err))
+				}
+			}
 			env = append(env, e)
 		}
 		ret.Environment = env
@@ -257,7 +274,8 @@ func (r *registry) processStep(step *api.TestStep, seen sets.String, stack stack
 		deps := make([]api.StepDependency, 0, len(ret.Dependencies))
 		for _, e := range ret.Dependencies {
 			if v := stack.resolveDep(e.Env); v != "" {
-				e.Name = v
+				// overrides name the image with the `stream:tag` form
+				e.Name, e.Release = v, ""
 			}
 			deps = append(deps, e)
 		}
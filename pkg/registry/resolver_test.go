@@ -5,11 +5,13 @@ import (
 	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 
 	"k8s.io/apimachinery/pkg/util/diff"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	prowv1 "k8s.io/test-infra/prow/apis/prowjobs/v1"
 
 	"github.com/openshift/ci-tools/pkg/api"
 	"github.com/openshift/ci-tools/pkg/testhelper"
@@ -669,6 +671,66 @@ func TestResolve(t *testing.T) {
 				},
 			}},
 		},
+	}, {
+		name: "Workflow enabling the gather step",
+		config: api.MultiStageTestConfiguration{
+			Workflow: &awsWorkflow,
+		},
+		workflowMap: WorkflowByName{
+			awsWorkflow: {
+				Test: []api.TestStep{{
+					LiteralTestStep: &api.LiteralTestStep{
+						As:       "e2e",
+						From:     "my-image",
+						Commands: "make custom-e2e",
+						Resources: api.ResourceRequirements{
+							Requests: api.ResourceList{"cpu": "1000m"},
+						}},
+				}},
+				Gather: &api.GatherConfiguration{Timeout: &prowv1.Duration{Duration: time.Hour}},
+			},
+		},
+		expectedRes: api.MultiStageTestConfigurationLiteral{
+			Test: []api.LiteralTestStep{{
+				As:       "e2e",
+				From:     "my-image",
+				Commands: "make custom-e2e",
+				Resources: api.ResourceRequirements{
+					Requests: api.ResourceList{"cpu": "1000m"},
+				},
+			}},
+			Gather: &api.GatherConfiguration{Timeout: &prowv1.Duration{Duration: time.Hour}},
+		},
+	}, {
+		name: "Workflow with fallback cluster profiles",
+		config: api.MultiStageTestConfiguration{
+			Workflow: &awsWorkflow,
+		},
+		workflowMap: WorkflowByName{
+			awsWorkflow: {
+				ClusterProfile:          api.ClusterProfileAWS,
+				FallbackClusterProfiles: []api.ClusterProfile{api.ClusterProfileGCP},
+			},
+		},
+		expectedRes: api.MultiStageTestConfigurationLiteral{
+			ClusterProfile:          api.ClusterProfileAWS,
+			FallbackClusterProfiles: []api.ClusterProfile{api.ClusterProfileGCP},
+		},
+	}, {
+		name: "Test overriding the cluster profile of a workflow does not inherit its fallbacks",
+		config: api.MultiStageTestConfiguration{
+			ClusterProfile: api.ClusterProfileGCP,
+			Workflow:       &awsWorkflow,
+		},
+		workflowMap: WorkflowByName{
+			awsWorkflow: {
+				ClusterProfile:          api.ClusterProfileAWS,
+				FallbackClusterProfiles: []api.ClusterProfile{api.ClusterProfileGCP},
+			},
+		},
+		expectedRes: api.MultiStageTestConfigurationLiteral{
+			ClusterProfile: api.ClusterProfileGCP,
+		},
 	}, {
 		name: "Workflow with invalid parameter",
 		config: api.MultiStageTestConfiguration{
@@ -734,6 +796,7 @@ func TestResolveParameters(t *testing.T) {
 	defaultWorkflow := "workflow"
 	defaultTest := "test"
 	defaultEmpty := ""
+	three, yes, slow := "3", "true", "slow"
 	workflows := WorkflowByName{
 		workflow: api.MultiStageTestConfiguration{
 			Test:         []api.TestStep{{Chain: &grandGrandParent}},
@@ -958,6 +1021,50 @@ func TestResolveParameters(t *testing.T) {
 			}},
 		},
 		err: errors.New("test/test: step/step: unresolved parameter: UNRESOLVED"),
+	}, {
+		name: "typed parameters with valid values",
+		test: api.MultiStageTestConfiguration{
+			Test: []api.TestStep{{
+				LiteralTestStep: &api.LiteralTestStep{
+					As: "step",
+					Environment: []api.StepParameter{
+						{Name: "COUNT", Type: api.ParameterTypeInteger},
+						{Name: "ENABLED", Type: api.ParameterTypeBoolean, Default: &defaultStr},
+						{Name: "MODE", Type: api.ParameterTypeEnum, Values: []string{"fast", "slow"}},
+					},
+				},
+			}},
+			Environment: api.TestEnvironment{"COUNT": "3", "ENABLED": "true", "MODE": "slow"},
+		},
+		expectedParams: [][]api.StepParameter{{
+			{Name: "COUNT", Type: api.ParameterTypeInteger, Default: &three},
+			{Name: "ENABLED", Type: api.ParameterTypeBoolean, Default: &yes},
+			{Name: "MODE", Type: api.ParameterTypeEnum, Values: []string{"fast", "slow"}, Default: &slow},
+		}},
+		expectedDeps: [][]api.StepDependency{nil},
+	}, {
+		name: "typed parameter with an invalid value",
+		test: api.MultiStageTestConfiguration{
+			Test: []api.TestStep{{
+				LiteralTestStep: &api.LiteralTestStep{
+					As:          "step",
+					Environment: []api.StepParameter{{Name: "MODE", Type: api.ParameterTypeEnum, Values: []string{"fast", "slow"}}},
+				},
+			}},
+			Environment: api.TestEnvironment{"MODE": "medium"},
+		},
+		err: errors.New(`test/test: step/step: parameter MODE: "medium" is not one of fast, slow`),
+	}, {
+		name: "typed parameter with an invalid default",
+		test: api.MultiStageTestConfiguration{
+			Test: []api.TestStep{{
+				LiteralTestStep: &api.LiteralTestStep{
+					As:          "step",
+					Environment: []api.StepParameter{{Name: "COUNT", Type: api.ParameterTypeInteger, Default: &defaultStr}},
+				},
+			}},
+		},
+		err: errors.New(`test/test: step/step: parameter COUNT: "default" is not an integer`),
 	}} {
 		t.Run(tc.name, func(t *testing.T) {
 			ret, err := NewResolver(refs, chains, workflows, observers).Resolve("test", tc.test)
@@ -399,7 +399,7 @@ func (jc *JobConfigurer) ConfigurePeriodicRehearsals(periodics config.Periodics)
 			metadata.Repo = job.ExtraRefs[0].Repo
 			metadata.Branch = job.ExtraRefs[0].BaseRef
 		}
-		testname := metadata.TestNameFromJobName(job.Name, jobconfig.PeriodicPrefix)
+		testname := testNameFromJob(metadata, job.JobBase, jobconfig.PeriodicPrefix)
 		imageStreamTags, err := jc.configureJobSpec(job.Spec, metadata, testname, jc.loggers.Debug.WithField("name", job.Name))
 		if err != nil {
 			jobLogger.WithError(err).Warn("Failed to inline ci-operator-config into rehearsal periodic job")
@@ -438,7 +438,7 @@ func (jc *JobConfigurer) ConfigurePresubmitRehearsals(presubmits config.Presubmi
 				Branch:  BranchFromRegexes(job.Branches),
 				Variant: VariantFromLabels(job.Labels),
 			}
-			testname := metadata.TestNameFromJobName(job.Name, jobconfig.PresubmitPrefix)
+			testname := testNameFromJob(metadata, job.JobBase, jobconfig.PresubmitPrefix)
 
 			imageStreamTags, err := jc.configureJobSpec(rehearsal.Spec, metadata, testname, jc.loggers.Debug.WithField("name", job.Name))
 			if err != nil {
@@ -454,6 +454,16 @@ func (jc *JobConfigurer) ConfigurePresubmitRehearsals(presubmits config.Presubmi
 	return allImageStreamTags, rehearsals, nil
 }
 
+// testNameFromJob returns the name of the test the job runs. Jobs for tests
+// on additional architectures are named after the test and the architecture.
+func testNameFromJob(metadata api.Metadata, job prowconfig.JobBase, prefix string) string {
+	testname := metadata.TestNameFromJobName(job.Name, prefix)
+	if architecture, ok := job.Labels[api.ArchitectureLabel]; ok {
+		testname = strings.TrimSuffix(testname, "-"+architecture)
+	}
+	return testname
+}
+
 func (jc *JobConfigurer) configureJobSpec(spec *v1.PodSpec, metadata api.Metadata, testName string, logger *logrus.Entry) (apihelper.ImageStreamTagMap, error) {
 	// Remove configresolver flags from ci-operator jobs
 	var metadataFromFlags api.Metadata
@@ -522,19 +532,31 @@ func AddRandomJobsForChangedTemplates(templates sets.String, toBeRehearsed confi
 	return rehearsals
 }
 
-// Generic Prow periodics are not related to a repo, but in OpenShift CI many of them
-// are generated from ci-operator config which are. Code using this type is expected
-// to only work with the generated, repo-connected periodics
-type periodicsByRepo map[string][]prowconfig.Periodic
 type presubmitsByRepo map[string][]prowconfig.Presubmit
 
 type periodicsByName map[string]prowconfig.Periodic
 type presubmitsByName map[string]prowconfig.Presubmit
 
-// selectJobsForRegistryStep returns a sample from all jobs affected by the provided registry node.
-func selectJobsForRegistryStep(node registry.Node, configs []*config.DataWithInfo, allPresubmits presubmitsByName, allPeriodics periodicsByName, skipJobs sets.String, loggers Loggers) (presubmitsByRepo, periodicsByRepo) {
-	selectedPresubmits := make(map[string][]prowconfig.Presubmit)
-	selectedPeriodics := make(map[string][]prowconfig.Periodic)
+// registryJob is a job using a registry node, either a presubmit or a periodic.
+// Generic Prow periodics are not related to a repo, but in OpenShift CI many of them
+// are generated from ci-operator config which are, and only those are used here.
+type registryJob struct {
+	repo      string
+	presubmit *prowconfig.Presubmit
+	periodic  *prowconfig.Periodic
+}
+
+func (j registryJob) name() string {
+	if j.presubmit != nil {
+		return j.presubmit.Name
+	}
+	return j.periodic.Name
+}
+
+// jobsForRegistryNode returns all jobs affected by the provided registry node,
+// in the order of the configurations they are generated from.
+func jobsForRegistryNode(node registry.Node, configs []*config.DataWithInfo, allPresubmits presubmitsByName, allPeriodics periodicsByName, loggers Loggers) []registryJob {
+	var affected []registryJob
 
 	nodeLogger := loggers.Debug.WithFields(registry.FieldsForNode(node))
 	nodeLogger.Debug("Searching for jobs affected by changed node")
@@ -546,7 +568,7 @@ func selectJobsForRegistryStep(node registry.Node, configs []*config.DataWithInf
 			if test.MultiStageTestConfiguration == nil {
 				continue
 			}
-			var selectJob func()
+			var addJob func()
 			var jobName string
 			switch {
 			case test.Postsubmit:
@@ -554,9 +576,9 @@ func selectJobsForRegistryStep(node registry.Node, configs []*config.DataWithInf
 			case test.Cron != nil || test.Interval != nil:
 				jobName = cfg.Info.JobName(jobconfig.PeriodicPrefix, test.As)
 				if periodic, ok := allPeriodics[jobName]; ok {
-					selectJob = func() {
-						testLogger.WithField("job-name", jobName).Debug("Periodic job uses the node: selecting for rehearse")
-						selectedPeriodics[orgRepo] = append(selectedPeriodics[orgRepo], periodic)
+					addJob = func() {
+						testLogger.WithField("job-name", jobName).Debug("Periodic job uses the node")
+						affected = append(affected, registryJob{repo: orgRepo, periodic: &periodic})
 					}
 				} else {
 					testLogger.WithField("job-name", jobName).Debug("Could not find a periodic job for test")
@@ -565,9 +587,9 @@ func selectJobsForRegistryStep(node registry.Node, configs []*config.DataWithInf
 			default: // Everything else is a presubmit
 				jobName = cfg.Info.JobName(jobconfig.PresubmitPrefix, test.As)
 				if presubmit, ok := allPresubmits[jobName]; ok {
-					selectJob = func() {
-						testLogger.WithField("job-name", jobName).Debug("Presubmit job uses the node: selecting for rehearse")
-						selectedPresubmits[orgRepo] = append(selectedPresubmits[orgRepo], presubmit)
+					addJob = func() {
+						testLogger.WithField("job-name", jobName).Debug("Presubmit job uses the node")
+						affected = append(affected, registryJob{repo: orgRepo, presubmit: &presubmit})
 					}
 				} else {
 					testLogger.WithField("job-name", jobName).Debug("Could not find a presubmit job for test")
@@ -575,19 +597,13 @@ func selectJobsForRegistryStep(node registry.Node, configs []*config.DataWithInf
 				}
 			}
 
-			if skipJobs.Has(jobName) {
-				testLogger.WithField("job-name", jobName).Debug("Already saw this job, skipping")
-				continue
-			}
-
 			// TODO: Handle workflows with overridden logFields.
 			// Workflows can have overridden logFields and thus may have overridden the field that made the workflow an ancestor.
 			// This should be handled to reduce the number of rehearsals being done, but requires much more information than
 			// the graph alone provides.
 			if node.Type() == registry.Workflow {
 				if test.MultiStageTestConfiguration.Workflow != nil && node.Name() == *test.MultiStageTestConfiguration.Workflow {
-					selectJob()
-					return selectedPresubmits, selectedPeriodics
+					addJob()
 				}
 				continue
 			}
@@ -596,14 +612,16 @@ func selectJobsForRegistryStep(node registry.Node, configs []*config.DataWithInf
 				hasRef := testStep.Reference != nil && node.Type() == registry.Reference && node.Name() == *testStep.Reference
 				hasChain := testStep.Chain != nil && node.Type() == registry.Chain && node.Name() == *testStep.Chain
 				if hasRef || hasChain {
-					selectJob()
-					return selectedPresubmits, selectedPeriodics
+					addJob()
+					break
 				}
 			}
 		}
 	}
-	loggers.Debug.WithField("node-name", node.Name()).Debug("Found no jobs using node")
-	return selectedPresubmits, selectedPeriodics
+	if len(affected) == 0 {
+		loggers.Debug.WithField("node-name", node.Name()).Debug("Found no jobs using node")
+	}
+	return affected
 }
 
 // getAffectedNodes returns a sorted list of all nodes affected by a seed list
@@ -634,7 +652,11 @@ func getAffectedNodes(changed []registry.Node) []registry.Node {
 	return worklist
 }
 
-func SelectJobsForChangedRegistry(regSteps []registry.Node, allPresubmits presubmitsByRepo, allPeriodics []prowconfig.Periodic, ciopConfigs config.DataByFilename, loggers Loggers) (config.Presubmits, config.Periodics) {
+// SelectJobsForChangedRegistry returns a sample of the jobs affected by the
+// changed registry nodes: for every affected node, up to sampleSize jobs using
+// it that were not selected for another node yet. It also returns the names of
+// all affected jobs, so the impact of the change can be reported.
+func SelectJobsForChangedRegistry(regSteps []registry.Node, allPresubmits presubmitsByRepo, allPeriodics []prowconfig.Periodic, ciopConfigs config.DataByFilename, sampleSize int, loggers Loggers) (config.Presubmits, config.Periodics, sets.String) {
 	// We need a sorted index of ci-operator configs for deterministic behavior
 	var sortedConfigs []*config.DataWithInfo
 	for idx := range ciopConfigs {
@@ -663,26 +685,26 @@ func SelectJobsForChangedRegistry(regSteps []registry.Node, allPresubmits presub
 	selectedPresubmits := config.Presubmits{}
 	selectedPeriodics := config.Periodics{}
 	selectedNames := sets.NewString()
+	affectedNames := sets.NewString()
 	for _, step := range stepWorklist {
-		presubmits, periodics := selectJobsForRegistryStep(step, sortedConfigs, presubmitIndex, periodicsIndex, selectedNames, loggers)
-		for repo, jobs := range presubmits {
-			for _, job := range jobs {
-				selectionFields := logrus.Fields{diffs.LogRepo: repo, diffs.LogJobName: job.Name, diffs.LogReasons: fmt.Sprintf("registry step %s changed", step.Name())}
-				loggers.Job.WithFields(selectionFields).Info(diffs.ChosenJob)
-				selectedPresubmits.Add(repo, job, config.ChangedRegistryContent)
-				selectedNames.Insert(job.Name)
+		sampled := 0
+		for _, job := range jobsForRegistryNode(step, sortedConfigs, presubmitIndex, periodicsIndex, loggers) {
+			affectedNames.Insert(job.name())
+			if sampled >= sampleSize || selectedNames.Has(job.name()) {
+				continue
 			}
-		}
-		for repo, jobs := range periodics {
-			for _, job := range jobs {
-				selectionFields := logrus.Fields{diffs.LogRepo: repo, diffs.LogJobName: job.Name, diffs.LogReasons: fmt.Sprintf("registry step %s changed", step.Name())}
-				loggers.Job.WithFields(selectionFields).Info(diffs.ChosenJob)
-				selectedPeriodics.Add(job, config.ChangedRegistryContent)
-				selectedNames.Insert(job.Name)
+			selectionFields := logrus.Fields{diffs.LogRepo: job.repo, diffs.LogJobName: job.name(), diffs.LogReasons: fmt.Sprintf("registry step %s changed", step.Name())}
+			loggers.Job.WithFields(selectionFields).Info(diffs.ChosenJob)
+			if job.presubmit != nil {
+				selectedPresubmits.Add(job.repo, *job.presubmit, config.ChangedRegistryContent)
+			} else {
+				selectedPeriodics.Add(*job.periodic, config.ChangedRegistryContent)
 			}
+			selectedNames.Insert(job.name())
+			sampled++
 		}
 	}
-	return selectedPresubmits, selectedPeriodics
+	return selectedPresubmits, selectedPeriodics, affectedNames
 }
 
 func getClusterTypes(jobs map[string][]prowconfig.Presubmit) []string {
@@ -1366,3 +1366,115 @@ func TestContextFor(t *testing.T) {
 		})
 	}
 }
+
+func TestSelectJobsForChangedRegistry(t *testing.T) {
+	ipiInstall, other, ipi, ipiAWS := "ipi-install", "other", "ipi", "ipi-aws"
+	graph, err := registry.NewGraph(
+		registry.ReferenceByName{ipiInstall: {As: ipiInstall}, other: {As: other}},
+		registry.ChainByName{ipi: {As: ipi, Steps: []api.TestStep{{Reference: &ipiInstall}}}},
+		registry.WorkflowByName{ipiAWS: {Pre: []api.TestStep{{Chain: &ipi}}}},
+	)
+	if err != nil {
+		t.Fatalf("failed to create the graph: %v", err)
+	}
+	cron := "@daily"
+	configs := config.DataByFilename{}
+	presubmits := presubmitsByRepo{}
+	var periodics []prowconfig.Periodic
+	for _, branch := range []string{"master", "release-4.9"} {
+		info := config.Info{Metadata: api.Metadata{Org: "org", Repo: "repo", Branch: branch}, Filename: fmt.Sprintf("org-repo-%s.yaml", branch)}
+		configs[info.Filename] = config.DataWithInfo{
+			Info: info,
+			Configuration: api.ReleaseBuildConfiguration{
+				Tests: []api.TestStepConfiguration{
+					{As: "e2e", MultiStageTestConfiguration: &api.MultiStageTestConfiguration{Workflow: &ipiAWS}},
+					{As: "unit", MultiStageTestConfiguration: &api.MultiStageTestConfiguration{Test: []api.TestStep{{Reference: &other}}}},
+					{As: "nightly", Cron: &cron, MultiStageTestConfiguration: &api.MultiStageTestConfiguration{Test: []api.TestStep{{Chain: &ipi}}}},
+				},
+			},
+		}
+		for _, test := range []string{"e2e", "unit"} {
+			presubmits["org/repo"] = append(presubmits["org/repo"], prowconfig.Presubmit{JobBase: prowconfig.JobBase{Name: info.JobName(jobconfig.PresubmitPrefix, test)}})
+		}
+		periodics = append(periodics, prowconfig.Periodic{JobBase: prowconfig.JobBase{Name: info.JobName(jobconfig.PeriodicPrefix, "nightly")}})
+	}
+	affected := sets.NewString(
+		"pull-ci-org-repo-master-e2e",
+		"pull-ci-org-repo-release-4.9-e2e",
+		"periodic-ci-org-repo-master-nightly",
+		"periodic-ci-org-repo-release-4.9-nightly",
+	)
+
+	var testCases = []struct {
+		name               string
+		sampleSize         int
+		expectedPresubmits []string
+		expectedPeriodics  []string
+	}{
+		{
+			name:               "one job for every affected node, preferring newer branches",
+			sampleSize:         1,
+			expectedPresubmits: []string{"pull-ci-org-repo-release-4.9-e2e"},
+			expectedPeriodics:  []string{"periodic-ci-org-repo-release-4.9-nightly"},
+		},
+		{
+			name:               "larger sample",
+			sampleSize:         2,
+			expectedPresubmits: []string{"pull-ci-org-repo-master-e2e", "pull-ci-org-repo-release-4.9-e2e"},
+			expectedPeriodics:  []string{"periodic-ci-org-repo-master-nightly", "periodic-ci-org-repo-release-4.9-nightly"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			selectedPresubmits, selectedPeriodics, actualAffected := SelectJobsForChangedRegistry([]registry.Node{graph.References[ipiInstall]}, presubmits, periodics, configs, tc.sampleSize, Loggers{logrus.New(), logrus.New()})
+			var presubmitNames, periodicNames []string
+			for _, job := range selectedPresubmits["org/repo"] {
+				presubmitNames = append(presubmitNames, job.Name)
+				if diff := cmp.Diff(string(config.ChangedRegistryContent), job.Labels[config.SourceTypeLabel]); diff != "" {
+					t.Errorf("unexpected source type for %s: %s", job.Name, diff)
+				}
+			}
+			for name := range selectedPeriodics {
+				periodicNames = append(periodicNames, name)
+			}
+			sort.Strings(presubmitNames)
+			sort.Strings(periodicNames)
+			if diff := cmp.Diff(tc.expectedPresubmits, presubmitNames); diff != "" {
+				t.Errorf("unexpected presubmits: %s", diff)
+			}
+			if diff := cmp.Diff(tc.expectedPeriodics, periodicNames); diff != "" {
+				t.Errorf("unexpected periodics: %s", diff)
+			}
+			if diff := cmp.Diff(affected.List(), actualAffected.List()); diff != "" {
+				t.Errorf("unexpected affected jobs: %s", diff)
+			}
+		})
+	}
+}
+
+func TestTestNameFromJob(t *testing.T) {
+	metadata := api.Metadata{Org: "org", Repo: "repo", Branch: "master"}
+	var testCases = []struct {
+		name     string
+		job      prowconfig.JobBase
+		expected string
+	}{
+		{
+			name:     "job for a test",
+			job:      prowconfig.JobBase{Name: "pull-ci-org-repo-master-e2e-arm64"},
+			expected: "e2e-arm64",
+		},
+		{
+			name:     "job for a test on an additional architecture",
+			job:      prowconfig.JobBase{Name: "pull-ci-org-repo-master-e2e-arm64", Labels: map[string]string{api.ArchitectureLabel: "arm64"}},
+			expected: "e2e",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.expected, testNameFromJob(metadata, tc.job, jobconfig.PresubmitPrefix)); diff != "" {
+				t.Errorf("unexpected test name: %s", diff)
+			}
+		})
+	}
+}
@@ -62,6 +62,15 @@ func TestEndpoint(t *testing.T) {
 			},
 			output: "https://amd64.origin.releases.ci.openshift.org/api/v1/releasestream/4.4.0-0.okd/latest",
 		},
+		{
+			input: api.Candidate{
+				Product:      api.ReleaseProductOKD,
+				Architecture: api.ReleaseArchitectureAMD64,
+				Stream:       api.ReleaseStreamOKDSCOS,
+				Version:      "4.12",
+			},
+			output: "https://amd64.origin.releases.ci.openshift.org/api/v1/releasestream/4.12.0-0.okd-scos/latest",
+		},
 		{
 			input: api.Candidate{
 				Product:      api.ReleaseProductOCP,
@@ -12,9 +12,18 @@ import (
 //     return results.ForReason(results.ReasonFoo).WithError(err).Errorf("could not do something for data: %v", data)
 // }
 type Error struct {
-	reason  Reason
-	message string
-	wrapped error
+	reason   Reason
+	category Category
+	resource *Resource
+	message  string
+	wrapped  error
+}
+
+// Resource identifies the object a failure is about
+type Resource struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
 }
 
 // Error makes an Error an error
@@ -72,6 +81,21 @@ func ForReason(reason Reason) *BuilderWithReason {
 	}
 }
 
+// WithCategory is a builder that classifies the Error. Categories set
+// on errors deeper in the chain take precedence, as they are closer to
+// the cause of the failure.
+func (e *BuilderWithReason) WithCategory(category Category) *BuilderWithReason {
+	e.category = category
+	return e
+}
+
+// WithResource is a builder that records the object the Error is about.
+// Like categories, resources deeper in the chain take precedence.
+func (e *BuilderWithReason) WithResource(kind, namespace, name string) *BuilderWithReason {
+	e.resource = &Resource{Kind: kind, Namespace: namespace, Name: name}
+	return e
+}
+
 // BuilderWithReasonAndError adds a child error to the builder
 type BuilderWithReasonAndError struct {
 	Error
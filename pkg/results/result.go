@@ -0,0 +1,87 @@
+package results
+
+import (
+	"errors"
+	"strings"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// ResultFile is the artifact ci-operator writes the Result of a run to
+const ResultFile = "result.json"
+
+// Result is the machine-readable outcome of a ci-operator run
+type Result struct {
+	// State is "succeeded" or "failed"
+	State string `json:"state"`
+	// Failures describe the errors the run failed with
+	Failures []Failure `json:"failures,omitempty"`
+}
+
+// Failure is the machine-readable description of an error
+type Failure struct {
+	// Reason is a colon-delimited list of reasons for the failure
+	Reason string `json:"reason"`
+	// Reasons are the reasons for the failure, from the outermost
+	// to the innermost one
+	Reasons []Reason `json:"reasons"`
+	// Category classifies the failure
+	Category Category `json:"category"`
+	// Resource is the object the failure is about, if known
+	Resource *Resource `json:"resource,omitempty"`
+	// Message is the human-readable error message
+	Message string `json:"message"`
+	// Causes are the failures of an aggregated error, e.g. the
+	// failed steps of a multi-stage test
+	Causes []Failure `json:"causes,omitempty"`
+}
+
+// ForErrors describes the outcome of a run that failed with the errors,
+// or succeeded if there are none
+func ForErrors(errs ...error) Result {
+	if len(errs) == 0 {
+		return Result{State: StateSucceeded}
+	}
+	result := Result{State: StateFailed}
+	for _, err := range errs {
+		result.Failures = append(result.Failures, FailureFor(err))
+	}
+	return result
+}
+
+// FailureFor describes an error. Aggregated errors in the chain are
+// described as the causes of the failure, which inherit its category
+// unless they are classified themselves.
+func FailureFor(err error) Failure {
+	return failureFor(err, CategoryUnknown)
+}
+
+func failureFor(err error, category Category) Failure {
+	failure := Failure{Category: category, Message: err.Error()}
+	for current := err; current != nil; current = errors.Unwrap(current) {
+		if reasoned, ok := current.(*Error); ok {
+			failure.Reasons = append(failure.Reasons, reasoned.reason)
+			if reasoned.category != "" {
+				failure.Category = reasoned.category
+			}
+			if reasoned.resource != nil {
+				failure.Resource = reasoned.resource
+			}
+		}
+		if aggregate, ok := current.(utilerrors.Aggregate); ok {
+			for _, cause := range aggregate.Errors() {
+				failure.Causes = append(failure.Causes, failureFor(cause, failure.Category))
+			}
+			break
+		}
+	}
+	if len(failure.Reasons) == 0 {
+		failure.Reasons = []Reason{ReasonUnknown}
+	}
+	reasons := make([]string, 0, len(failure.Reasons))
+	for _, reason := range failure.Reasons {
+		reasons = append(reasons, string(reason))
+	}
+	failure.Reason = strings.Join(reasons, ":")
+	return failure
+}
@@ -0,0 +1,90 @@
+package results
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+func TestForErrors(t *testing.T) {
+	podFailure := ForReason("running_pod").WithCategory(CategoryTest).WithResource("Pod", "ns", "e2e-test").ForError(errors.New("pod failed"))
+	testCases := []struct {
+		name     string
+		errs     []error
+		expected Result
+	}{
+		{
+			name:     "no errors",
+			expected: Result{State: StateSucceeded},
+		},
+		{
+			name: "error without reason",
+			errs: []error{errors.New("oops")},
+			expected: Result{
+				State:    StateFailed,
+				Failures: []Failure{{Reason: "unknown", Reasons: []Reason{ReasonUnknown}, Category: CategoryUnknown, Message: "oops"}},
+			},
+		},
+		{
+			name: "chain of reasons, the innermost category wins",
+			errs: []error{
+				ForReason("higher_level_thing").WithCategory(CategoryInfra).WithError(
+					ForReason("root_thing").WithCategory(CategoryConfig).ForError(errors.New("root error")),
+				).Errorf("failed to do higher level thing"),
+			},
+			expected: Result{
+				State: StateFailed,
+				Failures: []Failure{{
+					Reason:   "higher_level_thing:root_thing",
+					Reasons:  []Reason{"higher_level_thing", "root_thing"},
+					Category: CategoryConfig,
+					Message:  "failed to do higher level thing",
+				}},
+			},
+		},
+		{
+			name: "aggregated errors are causes that inherit the category",
+			errs: []error{
+				ForReason("executing_multi_stage_test").WithCategory(CategoryInfra).ForError(utilerrors.NewAggregate([]error{
+					fmt.Errorf("test steps failed: %w", podFailure),
+					errors.New("plain"),
+				})),
+			},
+			expected: Result{
+				State: StateFailed,
+				Failures: []Failure{{
+					Reason:   "executing_multi_stage_test",
+					Reasons:  []Reason{"executing_multi_stage_test"},
+					Category: CategoryInfra,
+					Message:  "[test steps failed: pod failed, plain]",
+					Causes: []Failure{
+						{
+							Reason:   "running_pod",
+							Reasons:  []Reason{"running_pod"},
+							Category: CategoryTest,
+							Resource: &Resource{Kind: "Pod", Namespace: "ns", Name: "e2e-test"},
+							Message:  "test steps failed: pod failed",
+						},
+						{
+							Reason:   "unknown",
+							Reasons:  []Reason{ReasonUnknown},
+							Category: CategoryInfra,
+							Message:  "plain",
+						},
+					},
+				}},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.expected, ForErrors(tc.errs...)); diff != "" {
+				t.Errorf("got incorrect result: %s", diff)
+			}
+		})
+	}
+}
@@ -6,4 +6,24 @@ const (
 	// ReasonUnknown is default reason. Occurrences of this reason in metrics
 	// indicate a bug, a failure to identify the reason for an error somewhere.
 	ReasonUnknown Reason = "unknown"
+	// ReasonTimeout is the reason for steps that were aborted because they
+	// exceeded their timeout.
+	ReasonTimeout Reason = "timeout"
+)
+
+// Category classifies who is most likely responsible for fixing a failure
+type Category string
+
+const (
+	// CategoryUnknown is the category of failures nobody classified.
+	CategoryUnknown Category = "unknown"
+	// CategoryInfra is the category of failures caused by the CI
+	// infrastructure, e.g. a build cluster or a service it depends on.
+	CategoryInfra Category = "infra"
+	// CategoryTest is the category of failures caused by the code that
+	// is tested or by its tests.
+	CategoryTest Category = "test"
+	// CategoryConfig is the category of failures caused by the
+	// configuration of the job.
+	CategoryConfig Category = "config"
 )
@@ -0,0 +1,257 @@
+package results
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"google.golang.org/api/option"
+	htransport "google.golang.org/api/transport/http"
+
+	"github.com/openshift/ci-tools/pkg/api"
+)
+
+const (
+	bigQueryAddress = "https://bigquery.googleapis.com"
+	pubSubAddress   = "https://pubsub.googleapis.com"
+
+	bigQueryScope = "https://www.googleapis.com/auth/bigquery.insertdata"
+	pubSubScope   = "https://www.googleapis.com/auth/pubsub"
+)
+
+// StepResult is the machine-readable outcome of a step of a job
+type StepResult struct {
+	// Step is the name of the step
+	Step string `json:"step"`
+	// StepType is the name of the implementation of the step
+	StepType string `json:"step_type"`
+	// StartedAt is when the step started
+	StartedAt time.Time `json:"started_at"`
+	// DurationSeconds is how long the step took
+	DurationSeconds float64 `json:"duration_seconds"`
+	// State is "succeeded" or "failed"
+	State string `json:"state"`
+	// Reason is a colon-delimited list of reasons for failure
+	Reason string `json:"reason,omitempty"`
+	// Category classifies the failure
+	Category Category `json:"category,omitempty"`
+}
+
+// ForStep describes the outcome of a step that failed with the error, or
+// succeeded if it is nil
+func ForStep(step, stepType string, startedAt time.Time, duration time.Duration, err error) StepResult {
+	result := StepResult{
+		Step:            step,
+		StepType:        stepType,
+		StartedAt:       startedAt,
+		DurationSeconds: duration.Seconds(),
+		State:           StateSucceeded,
+	}
+	if err != nil {
+		failure := FailureFor(err)
+		result.State = StateFailed
+		result.Reason = failure.Reason
+		result.Category = failure.Category
+	}
+	return result
+}
+
+// StepRecord is the result of a step together with the job it ran in, as it
+// is published to a sink
+type StepRecord struct {
+	// Job is the name of the job
+	Job string `json:"job"`
+	// JobType is "presubmit", "postsubmit", "periodic" or "batch"
+	JobType string `json:"job_type"`
+	// BuildID identifies the run of the job
+	BuildID string `json:"build_id"`
+	// Cluster is the cluster's console hostname
+	Cluster string `json:"cluster"`
+	StepResult
+}
+
+// Sink publishes the results of the steps of a job
+type Sink interface {
+	Publish(ctx context.Context, records []StepRecord) error
+}
+
+// SinkOptions holds the configuration for publishing the results of steps
+type SinkOptions struct {
+	bigQueryTable   string
+	pubSubTopic     string
+	credentialsFile string
+}
+
+// Bind adds flags for the options
+func (o *SinkOptions) Bind(flag *flag.FlagSet) {
+	flag.StringVar(&o.bigQueryTable, "step-results-bigquery-table", "", "BigQuery table in the project.dataset.table form the results of the steps are inserted into.")
+	flag.StringVar(&o.pubSubTopic, "step-results-pubsub-topic", "", "Pub/Sub topic in the project/topic form the results of the steps are published to, one message per step.")
+	flag.StringVar(&o.credentialsFile, "step-results-credentials-file", "", "File holding the GCP service account credentials used to publish the results of the steps. Application default credentials are used if unset.")
+}
+
+// Validate validates the options
+func (o *SinkOptions) Validate() error {
+	if o.bigQueryTable != "" && o.pubSubTopic != "" {
+		return errors.New("--step-results-bigquery-table and --step-results-pubsub-topic are mutually exclusive")
+	}
+	if o.bigQueryTable != "" && len(strings.Split(o.bigQueryTable, ".")) != 3 {
+		return fmt.Errorf("--step-results-bigquery-table %q is not in the project.dataset.table form", o.bigQueryTable)
+	}
+	if o.pubSubTopic != "" && len(strings.Split(o.pubSubTopic, "/")) != 2 {
+		return fmt.Errorf("--step-results-pubsub-topic %q is not in the project/topic form", o.pubSubTopic)
+	}
+	return nil
+}
+
+// Sink returns the configured sink, or nil if no sink is configured
+func (o *SinkOptions) Sink(ctx context.Context) (Sink, error) {
+	if o.bigQueryTable == "" && o.pubSubTopic == "" {
+		return nil, nil
+	}
+	scope := bigQueryScope
+	if o.pubSubTopic != "" {
+		scope = pubSubScope
+	}
+	opts := []option.ClientOption{option.WithScopes(scope)}
+	if o.credentialsFile != "" {
+		opts = append(opts, option.WithCredentialsFile(o.credentialsFile))
+	}
+	client, _, err := htransport.NewClient(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the GCP client: %w", err)
+	}
+	if o.pubSubTopic != "" {
+		parts := strings.Split(o.pubSubTopic, "/")
+		return &pubSubSink{client: client, address: pubSubAddress, project: parts[0], topic: parts[1]}, nil
+	}
+	parts := strings.Split(o.bigQueryTable, ".")
+	return &bigQuerySink{client: client, address: bigQueryAddress, project: parts[0], dataset: parts[1], table: parts[2]}, nil
+}
+
+// StepRecords attaches the job to the results of its steps
+func StepRecords(spec *api.JobSpec, consoleHost string, stepResults []StepResult) []StepRecord {
+	var records []StepRecord
+	for _, result := range stepResults {
+		records = append(records, StepRecord{
+			Job:        spec.Job,
+			JobType:    string(spec.Type),
+			BuildID:    spec.BuildID,
+			Cluster:    consoleHost,
+			StepResult: result,
+		})
+	}
+	return records
+}
+
+// bigQuerySink streams the records into a BigQuery table
+type bigQuerySink struct {
+	client                  *http.Client
+	address                 string
+	project, dataset, table string
+}
+
+type bigQueryRow struct {
+	InsertID string     `json:"insertId"`
+	JSON     StepRecord `json:"json"`
+}
+
+type bigQueryInsertResponse struct {
+	InsertErrors []struct {
+		Index  int `json:"index"`
+		Errors []struct {
+			Reason  string `json:"reason"`
+			Message string `json:"message"`
+		} `json:"errors"`
+	} `json:"insertErrors"`
+}
+
+func (s *bigQuerySink) Publish(ctx context.Context, records []StepRecord) error {
+	var rows []bigQueryRow
+	for _, record := range records {
+		// the insert ID makes retried inserts of the same step idempotent
+		rows = append(rows, bigQueryRow{InsertID: record.BuildID + "/" + record.Step, JSON: record})
+	}
+	target := fmt.Sprintf("%s/bigquery/v2/projects/%s/datasets/%s/tables/%s/insertAll", s.address, s.project, s.dataset, s.table)
+	body, err := post(ctx, s.client, target, map[string]interface{}{"rows": rows})
+	if err != nil {
+		return fmt.Errorf("could not insert the step results into BigQuery: %w", err)
+	}
+	var response bigQueryInsertResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return fmt.Errorf("could not parse the response of BigQuery: %w", err)
+	}
+	var messages []string
+	for _, insertError := range response.InsertErrors {
+		for _, e := range insertError.Errors {
+			messages = append(messages, fmt.Sprintf("row %d: %s: %s", insertError.Index, e.Reason, e.Message))
+		}
+	}
+	if len(messages) != 0 {
+		return fmt.Errorf("BigQuery rejected step results: %s", strings.Join(messages, ", "))
+	}
+	return nil
+}
+
+// pubSubSink publishes every record as a message to a Pub/Sub topic
+type pubSubSink struct {
+	client         *http.Client
+	address        string
+	project, topic string
+}
+
+type pubSubMessage struct {
+	// Data is base64 encoded when marshalled
+	Data       []byte            `json:"data"`
+	Attributes map[string]string `json:"attributes"`
+}
+
+func (s *pubSubSink) Publish(ctx context.Context, records []StepRecord) error {
+	var messages []pubSubMessage
+	for _, record := range records {
+		data, err := json.Marshal(record)
+		if err != nil {
+			return fmt.Errorf("could not marshal the result of step %s: %w", record.Step, err)
+		}
+		messages = append(messages, pubSubMessage{
+			Data:       data,
+			Attributes: map[string]string{"job": record.Job, "build_id": record.BuildID, "state": record.State},
+		})
+	}
+	target := fmt.Sprintf("%s/v1/projects/%s/topics/%s:publish", s.address, s.project, s.topic)
+	if _, err := post(ctx, s.client, target, map[string]interface{}{"messages": messages}); err != nil {
+		return fmt.Errorf("could not publish the step results to Pub/Sub: %w", err)
+	}
+	return nil
+}
+
+func post(ctx context.Context, client *http.Client, target string, payload interface{}) ([]byte, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal request: %w", err)
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("could not create request: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("could not send request: %w", err)
+	}
+	defer response.Body.Close()
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("could not read response: %w", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("got unexpected response %s: %s", response.Status, string(body))
+	}
+	return body, nil
+}
@@ -0,0 +1,183 @@
+package results
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSinkOptionsValidate(t *testing.T) {
+	testCases := []struct {
+		name     string
+		options  SinkOptions
+		expected string
+	}{
+		{
+			name: "no sink",
+		},
+		{
+			name:    "BigQuery table",
+			options: SinkOptions{bigQueryTable: "project.dataset.table"},
+		},
+		{
+			name:    "Pub/Sub topic",
+			options: SinkOptions{pubSubTopic: "project/topic"},
+		},
+		{
+			name:     "both sinks",
+			options:  SinkOptions{bigQueryTable: "project.dataset.table", pubSubTopic: "project/topic"},
+			expected: "--step-results-bigquery-table and --step-results-pubsub-topic are mutually exclusive",
+		},
+		{
+			name:     "invalid table",
+			options:  SinkOptions{bigQueryTable: "dataset.table"},
+			expected: `--step-results-bigquery-table "dataset.table" is not in the project.dataset.table form`,
+		},
+		{
+			name:     "invalid topic",
+			options:  SinkOptions{pubSubTopic: "projects/project/topics/topic"},
+			expected: `--step-results-pubsub-topic "projects/project/topics/topic" is not in the project/topic form`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var actual string
+			if err := tc.options.Validate(); err != nil {
+				actual = err.Error()
+			}
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Errorf("got incorrect error: %s", diff)
+			}
+		})
+	}
+}
+
+var records = []StepRecord{
+	{
+		Job:     "pull-ci-org-repo-master-e2e",
+		JobType: "presubmit",
+		BuildID: "1",
+		Cluster: "build01",
+		StepResult: StepResult{
+			Step:            "e2e",
+			StepType:        "multiStageTestStep",
+			StartedAt:       time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC),
+			DurationSeconds: 60,
+			State:           StateFailed,
+			Reason:          "executing_multi_stage_test",
+			Category:        CategoryTest,
+		},
+	},
+}
+
+func TestBigQuerySink(t *testing.T) {
+	var path string
+	var body map[string]interface{}
+	response := `{}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		raw, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("could not read the body: %v", err)
+		}
+		if err := json.Unmarshal(raw, &body); err != nil {
+			t.Errorf("could not unmarshal the body: %v", err)
+		}
+		_, _ = w.Write([]byte(response))
+	}))
+	defer server.Close()
+	sink := &bigQuerySink{client: server.Client(), address: server.URL, project: "project", dataset: "dataset", table: "table"}
+
+	if err := sink.Publish(context.Background(), records); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff("/bigquery/v2/projects/project/datasets/dataset/tables/table/insertAll", path); diff != "" {
+		t.Errorf("unexpected path: %s", diff)
+	}
+	expected := map[string]interface{}{
+		"rows": []interface{}{
+			map[string]interface{}{
+				"insertId": "1/e2e",
+				"json": map[string]interface{}{
+					"job":              "pull-ci-org-repo-master-e2e",
+					"job_type":         "presubmit",
+					"build_id":         "1",
+					"cluster":          "build01",
+					"step":             "e2e",
+					"step_type":        "multiStageTestStep",
+					"started_at":       "2021-06-01T12:00:00Z",
+					"duration_seconds": float64(60),
+					"state":            "failed",
+					"reason":           "executing_multi_stage_test",
+					"category":         "test",
+				},
+			},
+		},
+	}
+	if diff := cmp.Diff(expected, body); diff != "" {
+		t.Errorf("unexpected body: %s", diff)
+	}
+
+	response = `{"insertErrors": [{"index": 0, "errors": [{"reason": "invalid", "message": "no such field"}]}]}`
+	err := sink.Publish(context.Background(), records)
+	if diff := cmp.Diff("BigQuery rejected step results: row 0: invalid: no such field", errString(err)); diff != "" {
+		t.Errorf("unexpected error: %s", diff)
+	}
+}
+
+func TestPubSubSink(t *testing.T) {
+	var path string
+	var body struct {
+		Messages []pubSubMessage `json:"messages"`
+	}
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("could not decode the body: %v", err)
+		}
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+	sink := &pubSubSink{client: server.Client(), address: server.URL, project: "project", topic: "topic"}
+
+	if err := sink.Publish(context.Background(), records); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff("/v1/projects/project/topics/topic:publish", path); diff != "" {
+		t.Errorf("unexpected path: %s", diff)
+	}
+	if len(body.Messages) != 1 {
+		t.Fatalf("expected one message, got %d", len(body.Messages))
+	}
+	if diff := cmp.Diff(map[string]string{"job": "pull-ci-org-repo-master-e2e", "build_id": "1", "state": "failed"}, body.Messages[0].Attributes); diff != "" {
+		t.Errorf("unexpected attributes: %s", diff)
+	}
+	var record StepRecord
+	if err := json.Unmarshal(body.Messages[0].Data, &record); err != nil {
+		t.Fatalf("could not unmarshal the message: %v", err)
+	}
+	if diff := cmp.Diff(records[0], record); diff != "" {
+		t.Errorf("unexpected message: %s", diff)
+	}
+
+	status = http.StatusForbidden
+	err := sink.Publish(context.Background(), records)
+	if diff := cmp.Diff("could not publish the step results to Pub/Sub: got unexpected response 403 Forbidden: {}", errString(err)); diff != "" {
+		t.Errorf("unexpected error: %s", diff)
+	}
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -1,6 +1,7 @@
 package secrets
 
 import (
+	"io"
 	"io/ioutil"
 	"os"
 	"strings"
@@ -54,3 +55,55 @@ func ReadFromFile(path string, censor *DynamicCensor) (string, error) {
 	censor.AddSecrets(ret)
 	return ret, nil
 }
+
+// Censorer masks secrets in content and knows how long the longest secret
+// it masks is, so that content can be censored as it is streamed.
+type Censorer interface {
+	secretutil.Censorer
+	LargestSecret() int
+}
+
+// CensoringWriter masks secrets in everything written to the delegate. Secrets
+// may be split across writes, so the writer holds back enough content to
+// censor them until more is written or the writer is flushed.
+type CensoringWriter struct {
+	delegate io.Writer
+	censor   Censorer
+	buffer   []byte
+}
+
+// NewCensoringWriter wraps the delegate. Callers must Flush the writer once
+// they are done writing to it.
+func NewCensoringWriter(delegate io.Writer, censor Censorer) *CensoringWriter {
+	return &CensoringWriter{delegate: delegate, censor: censor}
+}
+
+func (w *CensoringWriter) Write(p []byte) (int, error) {
+	w.buffer = append(w.buffer, p...)
+	w.censor.Censor(&w.buffer)
+	// a secret starting in the held content could continue in the next write
+	held := w.censor.LargestSecret() - 1
+	if held < 0 {
+		held = 0
+	}
+	if len(w.buffer) <= held {
+		return len(p), nil
+	}
+	n := len(w.buffer) - held
+	if _, err := w.delegate.Write(w.buffer[:n]); err != nil {
+		return 0, err
+	}
+	w.buffer = append(w.buffer[:0], w.buffer[n:]...)
+	return len(p), nil
+}
+
+// Flush writes the content held back by the writer to the delegate.
+func (w *CensoringWriter) Flush() error {
+	if len(w.buffer) == 0 {
+		return nil
+	}
+	w.censor.Censor(&w.buffer)
+	_, err := w.delegate.Write(w.buffer)
+	w.buffer = w.buffer[:0]
+	return err
+}
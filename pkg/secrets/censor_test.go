@@ -1,6 +1,7 @@
 package secrets
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -27,3 +28,51 @@ func TestDynamicCensor(t *testing.T) {
 		t.Errorf("unexpected result: %s", diff)
 	}
 }
+
+func TestCensoringWriter(t *testing.T) {
+	censor := NewDynamicCensor()
+	censor.AddSecrets("secret")
+	var testCases = []struct {
+		name     string
+		writes   []string
+		expected string
+	}{
+		{
+			name:     "no secrets",
+			writes:   []string{"some ", "output\n"},
+			expected: "some output\n",
+		},
+		{
+			name:     "secret in a single write",
+			writes:   []string{"the secret is out\n"},
+			expected: "the ****** is out\n",
+		},
+		{
+			name:     "secret split across writes",
+			writes:   []string{"the se", "c", "ret is out\n"},
+			expected: "the ****** is out\n",
+		},
+		{
+			name:     "secret at the end is censored when flushing",
+			writes:   []string{"the end: ", "secret"},
+			expected: "the end: ******",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			w := NewCensoringWriter(out, &censor)
+			for _, write := range tc.writes {
+				if n, err := w.Write([]byte(write)); err != nil || n != len(write) {
+					t.Fatalf("unexpected result of write: %d, %v", n, err)
+				}
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("unexpected error flushing: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, out.String()); diff != "" {
+				t.Errorf("unexpected output: %s", diff)
+			}
+		})
+	}
+}
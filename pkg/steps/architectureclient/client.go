@@ -0,0 +1,93 @@
+package architectureclient
+
+import (
+	"context"
+
+	coreapi "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	buildapi "github.com/openshift/api/build/v1"
+
+	"github.com/openshift/ci-tools/pkg/api"
+)
+
+// Wrap wraps the upstream client, scheduling all pods and builds it creates on nodes of the
+// architecture. Objects that already select an architecture, like the builds of an image for
+// multiple architectures, are created as they are.
+func Wrap(upstream ctrlruntimeclient.WithWatch, architecture api.ReleaseArchitecture) ctrlruntimeclient.WithWatch {
+	return &client{
+		upstream:     upstream,
+		architecture: architecture,
+	}
+}
+
+// client selects the nodes of an architecture for the pods and builds it creates
+type client struct {
+	upstream     ctrlruntimeclient.WithWatch
+	architecture api.ReleaseArchitecture
+}
+
+func (c *client) Get(ctx context.Context, key ctrlruntimeclient.ObjectKey, obj ctrlruntimeclient.Object) error {
+	return c.upstream.Get(ctx, key, obj)
+}
+
+func (c *client) List(ctx context.Context, list ctrlruntimeclient.ObjectList, opts ...ctrlruntimeclient.ListOption) error {
+	return c.upstream.List(ctx, list, opts...)
+}
+
+func (c *client) Create(ctx context.Context, obj ctrlruntimeclient.Object, opts ...ctrlruntimeclient.CreateOption) error {
+	switch o := obj.(type) {
+	case *coreapi.Pod:
+		if o.Spec.NodeSelector == nil {
+			o.Spec.NodeSelector = map[string]string{}
+		}
+		c.selectArchitecture(o.Spec.NodeSelector)
+	case *buildapi.Build:
+		if o.Spec.NodeSelector == nil {
+			o.Spec.NodeSelector = buildapi.OptionalNodeSelector{}
+		}
+		c.selectArchitecture(o.Spec.NodeSelector)
+	}
+	return c.upstream.Create(ctx, obj, opts...)
+}
+
+func (c *client) selectArchitecture(selector map[string]string) {
+	if _, set := selector[coreapi.LabelArchStable]; !set {
+		selector[coreapi.LabelArchStable] = string(c.architecture)
+	}
+}
+
+func (c *client) Delete(ctx context.Context, obj ctrlruntimeclient.Object, opts ...ctrlruntimeclient.DeleteOption) error {
+	return c.upstream.Delete(ctx, obj, opts...)
+}
+
+func (c *client) Update(ctx context.Context, obj ctrlruntimeclient.Object, opts ...ctrlruntimeclient.UpdateOption) error {
+	return c.upstream.Update(ctx, obj, opts...)
+}
+
+func (c *client) Patch(ctx context.Context, obj ctrlruntimeclient.Object, patch ctrlruntimeclient.Patch, opts ...ctrlruntimeclient.PatchOption) error {
+	return c.upstream.Patch(ctx, obj, patch, opts...)
+}
+
+func (c *client) DeleteAllOf(ctx context.Context, obj ctrlruntimeclient.Object, opts ...ctrlruntimeclient.DeleteAllOfOption) error {
+	return c.upstream.DeleteAllOf(ctx, obj, opts...)
+}
+
+func (c *client) Watch(ctx context.Context, obj ctrlruntimeclient.ObjectList, opts ...ctrlruntimeclient.ListOption) (watch.Interface, error) {
+	return c.upstream.Watch(ctx, obj, opts...)
+}
+
+func (c *client) Status() ctrlruntimeclient.StatusWriter {
+	return c.upstream.Status()
+}
+
+func (c *client) Scheme() *runtime.Scheme {
+	return c.upstream.Scheme()
+}
+
+func (c *client) RESTMapper() meta.RESTMapper {
+	return c.upstream.RESTMapper()
+}
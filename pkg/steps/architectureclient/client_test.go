@@ -0,0 +1,55 @@
+package architectureclient
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	coreapi "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	buildapi "github.com/openshift/api/build/v1"
+
+	"github.com/openshift/ci-tools/pkg/api"
+)
+
+func TestCreate(t *testing.T) {
+	if err := buildapi.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("failed to add the build scheme: %v", err)
+	}
+	client := Wrap(fakectrlruntimeclient.NewFakeClient(), api.ReleaseArchitectureARM64)
+	ctx := context.Background()
+
+	pod := &coreapi.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "test"},
+		Spec:       coreapi.PodSpec{NodeSelector: map[string]string{"role": "worker"}},
+	}
+	if err := client.Create(ctx, pod); err != nil {
+		t.Fatalf("failed to create the pod: %v", err)
+	}
+	if diff := cmp.Diff(map[string]string{"role": "worker", coreapi.LabelArchStable: "arm64"}, pod.Spec.NodeSelector); diff != "" {
+		t.Errorf("node selector of the pod differs from expected: %s", diff)
+	}
+
+	build := &buildapi.Build{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "src"}}
+	if err := client.Create(ctx, build); err != nil {
+		t.Fatalf("failed to create the build: %v", err)
+	}
+	if diff := cmp.Diff(buildapi.OptionalNodeSelector{coreapi.LabelArchStable: "arm64"}, build.Spec.NodeSelector); diff != "" {
+		t.Errorf("node selector of the build differs from expected: %s", diff)
+	}
+
+	architectureBuild := &buildapi.Build{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "src-amd64"},
+		Spec:       buildapi.BuildSpec{CommonSpec: buildapi.CommonSpec{NodeSelector: buildapi.OptionalNodeSelector{coreapi.LabelArchStable: "amd64"}}},
+	}
+	if err := client.Create(ctx, architectureBuild); err != nil {
+		t.Fatalf("failed to create the build: %v", err)
+	}
+	if diff := cmp.Diff(buildapi.OptionalNodeSelector{coreapi.LabelArchStable: "amd64"}, architectureBuild.Spec.NodeSelector); diff != "" {
+		t.Errorf("node selector of the build for an architecture differs from expected: %s", diff)
+	}
+}
@@ -38,6 +38,7 @@ import (
 
 	"github.com/openshift/ci-tools/pkg/api"
 	"github.com/openshift/ci-tools/pkg/junit"
+	"github.com/openshift/ci-tools/pkg/secrets"
 	"github.com/openshift/ci-tools/pkg/steps/loggingclient"
 )
 
@@ -173,17 +174,22 @@ type PodClient interface {
 	// its LoggingClient.
 	WithNewLoggingClient() PodClient
 	Exec(namespace, pod string, opts *coreapi.PodExecOptions) (remotecommand.Executor, error)
-	GetLogs(namespace, name string, opts *coreapi.PodLogOptions) *rest.Request
+	// GetLogs streams the logs of the container, censored of all secrets
+	// known to the client.
+	GetLogs(namespace, name string, opts *coreapi.PodLogOptions) (io.ReadCloser, error)
+	// Censor returns the censor for artifacts collected from pods.
+	Censor() secrets.Censorer
 }
 
-func NewPodClient(ctrlclient loggingclient.LoggingClient, config *rest.Config, client rest.Interface) PodClient {
-	return &podClient{LoggingClient: ctrlclient, config: config, client: client}
+func NewPodClient(ctrlclient loggingclient.LoggingClient, config *rest.Config, client rest.Interface, censor secrets.Censorer) PodClient {
+	return &podClient{LoggingClient: ctrlclient, config: config, client: client, censor: censor}
 }
 
 type podClient struct {
 	loggingclient.LoggingClient
 	config *rest.Config
 	client rest.Interface
+	censor secrets.Censorer
 }
 
 func (c podClient) Exec(namespace, pod string, opts *coreapi.PodExecOptions) (remotecommand.Executor, error) {
@@ -195,8 +201,16 @@ func (c podClient) Exec(namespace, pod string, opts *coreapi.PodExecOptions) (re
 	return e, nil
 }
 
-func (c podClient) GetLogs(namespace, name string, opts *coreapi.PodLogOptions) *rest.Request {
-	return c.client.Get().Namespace(namespace).Name(name).Resource("pods").SubResource("log").VersionedParams(opts, scheme.ParameterCodec)
+func (c podClient) GetLogs(namespace, name string, opts *coreapi.PodLogOptions) (io.ReadCloser, error) {
+	stream, err := c.client.Get().Namespace(namespace).Name(name).Resource("pods").SubResource("log").VersionedParams(opts, scheme.ParameterCodec).Stream(context.TODO())
+	if err != nil {
+		return nil, err
+	}
+	return censoredStream(stream, c.censor), nil
+}
+
+func (c podClient) Censor() secrets.Censorer {
+	return c.censor
 }
 
 func (c podClient) WithNewLoggingClient() PodClient {
@@ -204,6 +218,7 @@ func (c podClient) WithNewLoggingClient() PodClient {
 		LoggingClient: c.New(),
 		config:        c.config,
 		client:        c.client,
+		censor:        c.censor,
 	}
 }
 
@@ -337,7 +352,12 @@ func copyArtifacts(podClient PodClient, into, ns, name, containerName string, pa
 		if err != nil {
 			return fmt.Errorf("could not create target file %s for artifact: %w", p, err)
 		}
-		if _, err := io.Copy(f, tr); err != nil {
+		censored := secrets.NewCensoringWriter(f, podClient.Censor())
+		if _, err := io.Copy(censored, tr); err != nil {
+			f.Close()
+			return fmt.Errorf("could not copy contents of file %s: %w", p, err)
+		}
+		if err := censored.Flush(); err != nil {
 			f.Close()
 			return fmt.Errorf("could not copy contents of file %s: %w", p, err)
 		}
@@ -730,7 +750,7 @@ func gatherContainerLogsOutput(podClient PodClient, artifactDir, namespace, podN
 
 			w := gzip.NewWriter(file)
 			logger.Trace("Fetching container logs.")
-			if s, err := podClient.GetLogs(namespace, podName, &coreapi.PodLogOptions{Container: status.Name}).Stream(context.TODO()); err == nil {
+			if s, err := podClient.GetLogs(namespace, podName, &coreapi.PodLogOptions{Container: status.Name}); err == nil {
 				if _, err := io.Copy(w, s); err != nil {
 					validationErrors = append(validationErrors, fmt.Errorf("error: Unable to copy log output from pod container %s: %w", status.Name, err))
 				}
@@ -2,7 +2,9 @@ package steps
 
 import (
 	"encoding/base64"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"reflect"
@@ -15,12 +17,12 @@ import (
 	"k8s.io/apimachinery/pkg/api/equality"
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/diff"
-	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/remotecommand"
 	prowv1 "k8s.io/test-infra/prow/apis/prowjobs/v1"
 	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	"github.com/openshift/ci-tools/pkg/junit"
+	"github.com/openshift/ci-tools/pkg/secrets"
 	"github.com/openshift/ci-tools/pkg/steps/loggingclient"
 	"github.com/openshift/ci-tools/pkg/testhelper"
 )
@@ -505,8 +507,13 @@ func (f *fakePodClient) Exec(namespace, name string, opts *coreapi.PodExecOption
 	return &testExecutor{command: opts.Command}, nil
 }
 
-func (*fakePodClient) GetLogs(string, string, *coreapi.PodLogOptions) *rest.Request {
-	return rest.NewRequestWithClient(nil, "", rest.ClientContentConfig{}, nil)
+func (*fakePodClient) GetLogs(string, string, *coreapi.PodLogOptions) (io.ReadCloser, error) {
+	return nil, errors.New("logs are not available")
+}
+
+func (*fakePodClient) Censor() secrets.Censorer {
+	censor := secrets.NewDynamicCensor()
+	return &censor
 }
 
 func (f *fakePodClient) WithNewLoggingClient() PodClient {
@@ -9,6 +9,7 @@ import (
 	buildapi "github.com/openshift/api/build/v1"
 	"github.com/openshift/client-go/build/clientset/versioned/scheme"
 
+	"github.com/openshift/ci-tools/pkg/secrets"
 	"github.com/openshift/ci-tools/pkg/steps/loggingclient"
 )
 
@@ -20,21 +21,55 @@ type BuildClient interface {
 type buildClient struct {
 	loggingclient.LoggingClient
 	client rest.Interface
+	censor secrets.Censorer
 }
 
-func NewBuildClient(client loggingclient.LoggingClient, restClient rest.Interface) BuildClient {
+// NewBuildClient returns a client whose build logs are censored of all the
+// secrets known to the censor.
+func NewBuildClient(client loggingclient.LoggingClient, restClient rest.Interface, censor secrets.Censorer) BuildClient {
 	return &buildClient{
 		LoggingClient: client,
 		client:        restClient,
+		censor:        censor,
 	}
 }
 
 func (c *buildClient) Logs(namespace, name string, options *buildapi.BuildLogOptions) (io.ReadCloser, error) {
-	return c.client.Get().
+	stream, err := c.client.Get().
 		Namespace(namespace).
 		Name(name).
 		Resource("builds").
 		SubResource("log").
 		VersionedParams(options, scheme.ParameterCodec).
 		Stream(context.TODO())
+	if err != nil {
+		return nil, err
+	}
+	return censoredStream(stream, c.censor), nil
+}
+
+// censoredStream masks the secrets known to the censor in everything read
+// from the stream.
+func censoredStream(stream io.ReadCloser, censor secrets.Censorer) io.ReadCloser {
+	r, w := io.Pipe()
+	go func() {
+		writer := secrets.NewCensoringWriter(w, censor)
+		_, err := io.Copy(writer, stream)
+		if err == nil {
+			err = writer.Flush()
+		}
+		// a nil error closes the pipe with io.EOF
+		w.CloseWithError(err)
+	}()
+	return &censoredReadCloser{PipeReader: r, stream: stream}
+}
+
+type censoredReadCloser struct {
+	*io.PipeReader
+	stream io.ReadCloser
+}
+
+func (c *censoredReadCloser) Close() error {
+	c.PipeReader.Close()
+	return c.stream.Close()
 }
@@ -0,0 +1,27 @@
+package steps
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/openshift/ci-tools/pkg/secrets"
+)
+
+func TestCensoredStream(t *testing.T) {
+	censor := secrets.NewDynamicCensor()
+	censor.AddSecrets("hunter2")
+	stream := censoredStream(ioutil.NopCloser(strings.NewReader("logging in with hunter2\nlogged in as hunter2")), &censor)
+	raw, err := ioutil.ReadAll(stream)
+	if err != nil {
+		t.Fatalf("unexpected error reading the stream: %v", err)
+	}
+	if err := stream.Close(); err != nil {
+		t.Fatalf("unexpected error closing the stream: %v", err)
+	}
+	if diff := cmp.Diff("logging in with *******\nlogged in as *******", string(raw)); diff != "" {
+		t.Errorf("unexpected content: %s", diff)
+	}
+}
@@ -0,0 +1,291 @@
+package steps
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	coreapi "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	buildapi "github.com/openshift/api/build/v1"
+)
+
+const (
+	// buildahImage runs the builds; later versions change what --pull=false means
+	buildahImage = "quay.io/buildah/stable:v1.23.1"
+	// buildahPodSuffix is appended to the name of the build to name its pod
+	buildahPodSuffix = "-buildah"
+	// buildahContainerName is the container running buildah in the pod
+	buildahContainerName = "build"
+	buildahWorkspace     = "/workspace"
+	buildahContextDir    = buildahWorkspace + "/context"
+	// buildahCertDir holds the token of the service account and the CA of
+	// the cluster, which is used to access the registry of the cluster
+	buildahCertDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+	// buildahServiceAccount may push to the image streams of the namespace
+	buildahServiceAccount = "builder"
+	buildahPullSecretDir  = "/etc/pull-secret"
+)
+
+// buildahScript logs into the registry of the cluster, makes the images the
+// Dockerfile refers to available under the names it uses and builds and
+// pushes the image. Arguments are passed on to `buildah bud`.
+const buildahScript = `set -o errexit -o nounset -o pipefail
+if [[ -f "${PULL_SECRET}" ]]; then cp "${PULL_SECRET}" "${REGISTRY_AUTH_FILE}"; else echo '{}' > "${REGISTRY_AUTH_FILE}"; fi
+buildah login --cert-dir "${CERT_DIR}" --username serviceaccount --password "$(cat "${CERT_DIR}/token")" "${REGISTRY}"
+if [[ -n "${DOCKERFILE:-}" ]]; then printf '%s' "${DOCKERFILE}" > "${DOCKERFILE_PATH}"; fi
+replace() {
+  buildah pull --cert-dir "${CERT_DIR}" "$2"
+  buildah tag "$2" "$1"
+}
+while read -r name image; do
+  if [[ -n "${name}" ]]; then replace "${name}" "${image}"; fi
+done <<<"${REPLACEMENTS:-}"
+if [[ -n "${BASE_IMAGE:-}" ]]; then
+  replace "$(awk 'toupper($1) == "FROM" { image = $2 } END { print image }' "${DOCKERFILE_PATH}")" "${BASE_IMAGE}"
+fi
+buildah bud --cert-dir "${CERT_DIR}" --pull=false --file "${DOCKERFILE_PATH}" --tag "${OUTPUT_IMAGE}" "$@" "${CONTEXT_DIR}"
+buildah push --cert-dir "${CERT_DIR}" "${OUTPUT_IMAGE}"
+`
+
+// buildahPodBuildClient runs builds with buildah in unprivileged pods in the
+// namespace of the build instead of as OpenShift builds. Builds are not
+// stored on the cluster, they are derived from the pods running them; all
+// other objects are handled by the cluster.
+type buildahPodBuildClient struct {
+	PodClient
+}
+
+// NewBuildahPodBuildClient returns a client that runs builds in buildah pods.
+func NewBuildahPodBuildClient(client PodClient) BuildClient {
+	return &buildahPodBuildClient{PodClient: client}
+}
+
+func (c *buildahPodBuildClient) Create(ctx context.Context, obj ctrlruntimeclient.Object, opts ...ctrlruntimeclient.CreateOption) error {
+	build, ok := obj.(*buildapi.Build)
+	if !ok {
+		return c.PodClient.Create(ctx, obj, opts...)
+	}
+	pod, err := c.podFor(ctx, build)
+	if err != nil {
+		return fmt.Errorf("could not create the buildah pod for build %s: %w", build.Name, err)
+	}
+	logrus.Debugf("Building %s in pod %s", build.Name, pod.Name)
+	if err := c.PodClient.Create(ctx, pod, opts...); err != nil {
+		if kerrors.IsAlreadyExists(err) {
+			return kerrors.NewAlreadyExists(buildapi.Resource("builds"), build.Name)
+		}
+		return err
+	}
+	return nil
+}
+
+func (c *buildahPodBuildClient) Get(ctx context.Context, key ctrlruntimeclient.ObjectKey, obj ctrlruntimeclient.Object) error {
+	build, ok := obj.(*buildapi.Build)
+	if !ok {
+		return c.PodClient.Get(ctx, key, obj)
+	}
+	pod := &coreapi.Pod{}
+	if err := c.PodClient.Get(ctx, ctrlruntimeclient.ObjectKey{Namespace: key.Namespace, Name: key.Name + buildahPodSuffix}, pod); err != nil {
+		if kerrors.IsNotFound(err) {
+			return kerrors.NewNotFound(buildapi.Resource("builds"), key.Name)
+		}
+		return err
+	}
+	buildFromPod(pod, key.Name).DeepCopyInto(build)
+	return nil
+}
+
+func (c *buildahPodBuildClient) Delete(ctx context.Context, obj ctrlruntimeclient.Object, opts ...ctrlruntimeclient.DeleteOption) error {
+	if _, ok := obj.(*buildapi.Build); !ok {
+		return c.PodClient.Delete(ctx, obj, opts...)
+	}
+	pod := &coreapi.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: obj.GetNamespace(), Name: obj.GetName() + buildahPodSuffix}}
+	return c.PodClient.Delete(ctx, pod, opts...)
+}
+
+func (c *buildahPodBuildClient) Logs(namespace, name string, options *buildapi.BuildLogOptions) (io.ReadCloser, error) {
+	return c.GetLogs(namespace, name+buildahPodSuffix, &coreapi.PodLogOptions{Container: buildahContainerName, Timestamps: options.Timestamps})
+}
+
+// buildFromPod determines the status of the build from the pod running it
+func buildFromPod(pod *coreapi.Pod, name string) *buildapi.Build {
+	build := &buildapi.Build{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         pod.Namespace,
+			Name:              name,
+			UID:               pod.UID,
+			Labels:            pod.Labels,
+			CreationTimestamp: pod.CreationTimestamp,
+		},
+		Status: buildapi.BuildStatus{StartTimestamp: pod.Status.StartTime},
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name == buildahContainerName && status.State.Terminated != nil {
+			build.Status.CompletionTimestamp = &status.State.Terminated.FinishedAt
+		}
+	}
+	switch pod.Status.Phase {
+	case coreapi.PodPending:
+		build.Status.Phase = buildapi.BuildPhasePending
+	case coreapi.PodRunning:
+		build.Status.Phase = buildapi.BuildPhaseRunning
+	case coreapi.PodSucceeded:
+		build.Status.Phase = buildapi.BuildPhaseComplete
+	case coreapi.PodFailed:
+		build.Status.Phase = buildapi.BuildPhaseFailed
+		build.Status.Reason = buildapi.StatusReasonGenericBuildFailed
+		build.Status.Message = "the buildah pod failed"
+		if pod.Status.Reason == "Evicted" {
+			build.Status.Reason = buildapi.StatusReason("BuildPodEvicted")
+		}
+		if pod.Status.Message != "" {
+			build.Status.Message = pod.Status.Message
+		}
+	default:
+		build.Status.Phase = buildapi.BuildPhaseNew
+	}
+	return build
+}
+
+// podFor reproduces what the Docker strategy of OpenShift builds does in a
+// pod: init containers copy the paths of the input images into the context
+// directory, the images the Dockerfile refers to are replaced and the result
+// is pushed to the output image stream tag. The environment of the strategy
+// is not added to the image.
+func (c *buildahPodBuildClient) podFor(ctx context.Context, build *buildapi.Build) (*coreapi.Pod, error) {
+	source, strategy := build.Spec.Source, build.Spec.Strategy.DockerStrategy
+	switch {
+	case strategy == nil:
+		return nil, errors.New("only builds with the Docker strategy can run in buildah pods")
+	case source.Git != nil || source.Binary != nil:
+		return nil, errors.New("builds from Git or binary sources cannot run in buildah pods")
+	case len(source.Secrets) > 0 || len(source.ConfigMaps) > 0:
+		return nil, errors.New("builds that mount secrets or config maps cannot run in buildah pods")
+	case build.Spec.Output.To == nil:
+		return nil, errors.New("the build has no output")
+	}
+	workspace := []coreapi.VolumeMount{{Name: "workspace", MountPath: buildahWorkspace}}
+
+	var initContainers []coreapi.Container
+	var replacements []string
+	for i, image := range source.Images {
+		pullSpec, err := pullSpecFor(ctx, c.PodClient, build.Namespace, image.From, false)
+		if err != nil {
+			return nil, err
+		}
+		for _, as := range image.As {
+			replacements = append(replacements, fmt.Sprintf("%s %s", as, pullSpec))
+		}
+		if len(image.Paths) == 0 {
+			continue
+		}
+		var args []string
+		for _, path := range image.Paths {
+			args = append(args, path.SourcePath, filepath.Join(buildahContextDir, path.DestinationDir))
+		}
+		initContainers = append(initContainers, coreapi.Container{
+			Name:         fmt.Sprintf("input-%d", i),
+			Image:        pullSpec,
+			Command:      []string{"/bin/sh", "-c", `set -e; while [ $# -gt 0 ]; do mkdir -p "$2"; cp -a "$1" "$2"; shift 2; done`, "copy"},
+			Args:         args,
+			VolumeMounts: workspace,
+		})
+	}
+
+	target, err := pullSpecFor(ctx, c.PodClient, build.Namespace, *build.Spec.Output.To, false)
+	if err != nil {
+		return nil, err
+	}
+	contextDir := filepath.Join(buildahContextDir, source.ContextDir)
+	env := []coreapi.EnvVar{
+		{Name: "REGISTRY", Value: strings.SplitN(target, "/", 2)[0]},
+		{Name: "REGISTRY_AUTH_FILE", Value: filepath.Join(buildahWorkspace, "auth.json")},
+		{Name: "PULL_SECRET", Value: filepath.Join(buildahPullSecretDir, coreapi.DockerConfigJsonKey)},
+		{Name: "CERT_DIR", Value: buildahCertDir},
+		{Name: "STORAGE_DRIVER", Value: "vfs"},
+		{Name: "BUILDAH_ISOLATION", Value: "chroot"},
+		{Name: "CONTEXT_DIR", Value: contextDir},
+		{Name: "OUTPUT_IMAGE", Value: target},
+	}
+	if source.Dockerfile != nil {
+		env = append(env,
+			coreapi.EnvVar{Name: "DOCKERFILE_PATH", Value: filepath.Join(buildahWorkspace, "Dockerfile")},
+			coreapi.EnvVar{Name: "DOCKERFILE", Value: *source.Dockerfile},
+		)
+	} else {
+		path := strategy.DockerfilePath
+		if path == "" {
+			path = "Dockerfile"
+		}
+		env = append(env, coreapi.EnvVar{Name: "DOCKERFILE_PATH", Value: filepath.Join(contextDir, path)})
+	}
+	if len(replacements) > 0 {
+		env = append(env, coreapi.EnvVar{Name: "REPLACEMENTS", Value: strings.Join(replacements, "\n")})
+	}
+	if strategy.From != nil {
+		base, err := pullSpecFor(ctx, c.PodClient, build.Namespace, *strategy.From, false)
+		if err != nil {
+			return nil, err
+		}
+		env = append(env, coreapi.EnvVar{Name: "BASE_IMAGE", Value: base})
+	}
+
+	var args []string
+	if strategy.NoCache {
+		args = append(args, "--no-cache")
+	}
+	// build arguments are expanded from the environment, so that ones taken
+	// from secrets do not end up in the pod
+	for i, arg := range strategy.BuildArgs {
+		name := fmt.Sprintf("BUILD_ARG_%d", i)
+		env = append(env, coreapi.EnvVar{Name: name, Value: arg.Value, ValueFrom: arg.ValueFrom})
+		args = append(args, "--build-arg", fmt.Sprintf("%s=$(%s)", arg.Name, name))
+	}
+	for _, label := range build.Spec.Output.ImageLabels {
+		args = append(args, "--label", fmt.Sprintf("%s=%s", label.Name, label.Value))
+	}
+
+	volumes := []coreapi.Volume{
+		{Name: "workspace", VolumeSource: coreapi.VolumeSource{EmptyDir: &coreapi.EmptyDirVolumeSource{}}},
+		{Name: "storage", VolumeSource: coreapi.VolumeSource{EmptyDir: &coreapi.EmptyDirVolumeSource{}}},
+	}
+	mounts := append([]coreapi.VolumeMount{{Name: "storage", MountPath: "/var/lib/containers"}}, workspace...)
+	if strategy.PullSecret != nil {
+		volumes = append(volumes, coreapi.Volume{Name: "pull-secret", VolumeSource: coreapi.VolumeSource{Secret: &coreapi.SecretVolumeSource{SecretName: strategy.PullSecret.Name}}})
+		mounts = append(mounts, coreapi.VolumeMount{Name: "pull-secret", MountPath: buildahPullSecretDir, ReadOnly: true})
+	}
+
+	return &coreapi.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       build.Namespace,
+			Name:            build.Name + buildahPodSuffix,
+			Labels:          build.Labels,
+			Annotations:     build.Annotations,
+			OwnerReferences: build.OwnerReferences,
+		},
+		Spec: coreapi.PodSpec{
+			RestartPolicy:      coreapi.RestartPolicyNever,
+			ServiceAccountName: buildahServiceAccount,
+			InitContainers:     initContainers,
+			Containers: []coreapi.Container{{
+				Name:         buildahContainerName,
+				Image:        buildahImage,
+				Command:      []string{"/bin/bash", "-c", buildahScript, "build"},
+				Args:         args,
+				Env:          env,
+				Resources:    build.Spec.Resources,
+				VolumeMounts: mounts,
+			}},
+			Volumes: volumes,
+		},
+	}, nil
+}
@@ -0,0 +1,101 @@
+package steps
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	buildapi "github.com/openshift/api/build/v1"
+	imagev1 "github.com/openshift/api/image/v1"
+
+	"github.com/openshift/ci-tools/pkg/steps/loggingclient"
+	"github.com/openshift/ci-tools/pkg/testhelper"
+)
+
+func TestBuildahPodBuildClient(t *testing.T) {
+	pipeline := &imagev1.ImageStream{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ci-op", Name: "pipeline"},
+		Status:     imagev1.ImageStreamStatus{DockerImageRepository: "image-registry.openshift-image-registry.svc:5000/ci-op/pipeline"},
+	}
+	build := &buildapi.Build{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ci-op", Name: "bin", Labels: map[string]string{CreatesLabel: "bin"}},
+		Spec: buildapi.BuildSpec{CommonSpec: buildapi.CommonSpec{
+			Source: buildapi.BuildSource{
+				ContextDir: "images/bin",
+				Images: []buildapi.ImageSource{
+					{
+						From:  corev1.ObjectReference{Kind: "ImageStreamTag", Name: "pipeline:src"},
+						Paths: []buildapi.ImageSourcePath{{SourcePath: "/go/src/repo/.", DestinationDir: "."}},
+					},
+					{
+						From: corev1.ObjectReference{Kind: "ImageStreamTag", Name: "pipeline:tools"},
+						As:   []string{"registry.ci.openshift.org/ci/tools:latest"},
+					},
+				},
+			},
+			Strategy: buildapi.BuildStrategy{DockerStrategy: &buildapi.DockerBuildStrategy{
+				From:           &corev1.ObjectReference{Kind: "ImageStreamTag", Namespace: "ci-op", Name: "pipeline:root"},
+				DockerfilePath: "Dockerfile.ci",
+				NoCache:        true,
+				PullSecret:     &corev1.LocalObjectReference{Name: PullSecretName},
+				BuildArgs: []corev1.EnvVar{
+					{Name: "ARG", Value: "value"},
+					{Name: "TOKEN", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "token"}, Key: "token"}}},
+				},
+			}},
+			Output: buildapi.BuildOutput{
+				To:          &corev1.ObjectReference{Kind: "ImageStreamTag", Namespace: "ci-op", Name: "pipeline:bin"},
+				ImageLabels: []buildapi.ImageLabel{{Name: "vcs-ref", Value: "abcdef"}},
+			},
+		}},
+	}
+
+	var testCases = []struct {
+		name          string
+		failures      sets.String
+		expectedPhase buildapi.BuildPhase
+	}{
+		{
+			name:          "successful pod completes the build",
+			expectedPhase: buildapi.BuildPhaseComplete,
+		},
+		{
+			name:          "failed pod fails the build",
+			failures:      sets.NewString("bin-buildah"),
+			expectedPhase: buildapi.BuildPhaseFailed,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			executor := &fakePodExecutor{
+				LoggingClient: loggingclient.New(fakectrlruntimeclient.NewClientBuilder().WithObjects(pipeline.DeepCopy()).Build()),
+				failures:      tc.failures,
+			}
+			client := NewBuildahPodBuildClient(&fakePodClient{fakePodExecutor: executor})
+			if err := client.Create(context.Background(), build.DeepCopy()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(executor.createdPods) != 1 {
+				t.Fatalf("expected one pod to be created, got %d", len(executor.createdPods))
+			}
+			testhelper.CompareWithFixture(t, executor.createdPods[0])
+			if err := client.Create(context.Background(), build.DeepCopy()); !kerrors.IsAlreadyExists(err) {
+				t.Errorf("expected the build to exist, got %v", err)
+			}
+
+			created := &buildapi.Build{}
+			if err := client.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: "ci-op", Name: "bin"}, created); err != nil {
+				t.Fatalf("unexpected error getting the build: %v", err)
+			}
+			if created.Status.Phase != tc.expectedPhase {
+				t.Errorf("expected phase %s, got %s", tc.expectedPhase, created.Status.Phase)
+			}
+		})
+	}
+}
@@ -78,27 +78,18 @@ func (s *clusterClaimStep) run(ctx context.Context) error {
 	return aggregateWrappedErrorAndReleaseError(wrappedErr, releaseErr)
 }
 
+// defaultClaimLifetime bounds how long a claimed cluster exists if the claim is never released
+const defaultClaimLifetime = 4 * time.Hour
+
 func acquireCluster(ctx context.Context, clusterClaim api.ClusterClaim, hiveClient ctrlruntimeclient.Client, client loggingclient.LoggingClient, jobSpec api.JobSpec) (*hivev1.ClusterClaim, error) {
-	clusterPools := &hivev1.ClusterPoolList{}
-	listOption := ctrlruntimeclient.MatchingLabels{
-		"product":      string(clusterClaim.Product),
-		"version":      clusterClaim.Version,
-		"architecture": string(clusterClaim.Architecture),
-		"cloud":        string(clusterClaim.Cloud),
-		"owner":        clusterClaim.Owner,
+	clusterPool, err := clusterPoolFor(ctx, clusterClaim, hiveClient)
+	if err != nil {
+		return nil, err
 	}
-	if err := hiveClient.List(ctx, clusterPools, listOption); err != nil {
-		return nil, fmt.Errorf("failed to list cluster pools with list option %v: %w", listOption, err)
+	lifetime := defaultClaimLifetime
+	if clusterClaim.Lifetime != nil {
+		lifetime = clusterClaim.Lifetime.Duration
 	}
-
-	l := len(clusterPools.Items)
-	if l == 0 {
-		return nil, fmt.Errorf("failed to find a cluster pool providing the cluster: %v", listOption)
-	} else if l > 1 {
-		return nil, fmt.Errorf("find %d cluster pools providing the cluster (%v): should be only one", len(clusterPools.Items), listOption)
-	}
-
-	clusterPool := clusterPools.Items[0]
 	claimName := jobSpec.ProwJobID
 	claimNamespace := clusterPool.Namespace
 	claim := &hivev1.ClusterClaim{
@@ -112,7 +103,7 @@ func acquireCluster(ctx context.Context, clusterClaim api.ClusterClaim, hiveClie
 		},
 		Spec: hivev1.ClusterClaimSpec{
 			ClusterPoolName: clusterPool.Name,
-			Lifetime:        &metav1.Duration{Duration: 4 * time.Hour},
+			Lifetime:        &metav1.Duration{Duration: lifetime},
 		},
 	}
 	if err := hiveClient.Create(ctx, claim); err != nil {
@@ -166,6 +157,47 @@ func acquireCluster(ctx context.Context, clusterClaim api.ClusterClaim, hiveClie
 	return claim, nil
 }
 
+// clusterPoolFor finds the cluster pool to claim the cluster from, either
+// by its name or by the labels describing the cluster it provides
+func clusterPoolFor(ctx context.Context, clusterClaim api.ClusterClaim, hiveClient ctrlruntimeclient.Client) (*hivev1.ClusterPool, error) {
+	labels := map[string]string{}
+	if clusterClaim.Pool == "" {
+		labels = map[string]string{
+			"product":      string(clusterClaim.Product),
+			"version":      clusterClaim.Version,
+			"architecture": string(clusterClaim.Architecture),
+			"cloud":        string(clusterClaim.Cloud),
+			"owner":        clusterClaim.Owner,
+		}
+	}
+	for key, value := range clusterClaim.Labels {
+		labels[key] = value
+	}
+	listOption := ctrlruntimeclient.MatchingLabels(labels)
+	clusterPools := &hivev1.ClusterPoolList{}
+	if err := hiveClient.List(ctx, clusterPools, listOption); err != nil {
+		return nil, fmt.Errorf("failed to list cluster pools with list option %v: %w", listOption, err)
+	}
+	var candidates []hivev1.ClusterPool
+	for _, pool := range clusterPools.Items {
+		if clusterClaim.Pool == "" || pool.Name == clusterClaim.Pool {
+			candidates = append(candidates, pool)
+		}
+	}
+	description := fmt.Sprintf("%v", listOption)
+	if clusterClaim.Pool != "" {
+		description = fmt.Sprintf("%s with labels %v", clusterClaim.Pool, listOption)
+	}
+
+	l := len(candidates)
+	if l == 0 {
+		return nil, fmt.Errorf("failed to find a cluster pool providing the cluster: %s", description)
+	} else if l > 1 {
+		return nil, fmt.Errorf("find %d cluster pools providing the cluster (%s): should be only one", l, description)
+	}
+	return &candidates[0], nil
+}
+
 func mutate(secret *corev1.Secret, name, namespace string) (*corev1.Secret, error) {
 	var key string
 	if name == api.HiveAdminKubeconfigSecret {
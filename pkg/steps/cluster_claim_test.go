@@ -191,6 +191,54 @@ func TestClusterClaimStepAcquireCluster(t *testing.T) {
 				return nil
 			},
 		},
+		{
+			name: "pool selected by name",
+			clusterClaim: api.ClusterClaim{
+				Pool:    "ci-ocp-4.7.0-amd64-aws-us-east-1",
+				Timeout: &prowv1.Duration{Duration: time.Hour},
+			},
+			jobSpec: &api.JobSpec{
+				JobSpec: downwardapi.JobSpec{
+					ProwJobID: "c2a971b7-947b-11eb-9747-0a580a820213",
+					BuildID:   "1378330119495487488",
+					Job:       "pull-ci-openshift-console-master-images",
+				},
+			},
+			hiveClient: bcc(fakectrlruntimeclient.NewClientBuilder().WithObjects(aClusterPool()).Build(), func(client *clusterClaimStatusSettingClient) {
+				client.namespace = "ci-ocp-4.7.0-amd64-aws-us-east-1-ccx23"
+				client.conditionStatus = corev1.ConditionTrue
+			}),
+			client:      loggingclient.New(fakectrlruntimeclient.NewFakeClient()),
+			expectClaim: true,
+		},
+		{
+			name: "pool selected by name does not have the labels",
+			clusterClaim: api.ClusterClaim{
+				Pool:    "ci-ocp-4.7.0-amd64-aws-us-east-1",
+				Labels:  map[string]string{"region": "us-west-2"},
+				Timeout: &prowv1.Duration{Duration: time.Hour},
+			},
+			hiveClient: fakectrlruntimeclient.NewClientBuilder().WithObjects(aClusterPool()).Build(),
+			client:     loggingclient.New(fakectrlruntimeclient.NewFakeClient()),
+			jobSpec:    &api.JobSpec{},
+			expected:   fmt.Errorf("failed to find a cluster pool providing the cluster: ci-ocp-4.7.0-amd64-aws-us-east-1 with labels map[region:us-west-2]"),
+		},
+		{
+			name: "additional labels select the pool",
+			clusterClaim: api.ClusterClaim{
+				Product:      api.ReleaseProductOCP,
+				Version:      "4.7.0",
+				Architecture: api.ReleaseArchitectureAMD64,
+				Cloud:        api.CloudAWS,
+				Owner:        "dpp",
+				Labels:       map[string]string{"region": "us-west-2"},
+				Timeout:      &prowv1.Duration{Duration: time.Hour},
+			},
+			hiveClient: fakectrlruntimeclient.NewClientBuilder().WithObjects(aClusterPool()).Build(),
+			client:     loggingclient.New(fakectrlruntimeclient.NewFakeClient()),
+			jobSpec:    &api.JobSpec{},
+			expected:   fmt.Errorf("failed to find a cluster pool providing the cluster: map[architecture:amd64 cloud:aws owner:dpp product:ocp region:us-west-2 version:4.7.0]"),
+		},
 		{
 			name: "timeout",
 			clusterClaim: api.ClusterClaim{
@@ -96,7 +96,7 @@ func E2ETestStep(
 		params = api.NewOverrideParameters(params, overrides)
 	}
 
-	step := steps.TemplateExecutionStep(template, params, podClient, templateClient, jobSpec, resources)
+	step := steps.TemplateExecutionStep(template, params, podClient, templateClient, jobSpec, resources, config.ClusterProfile)
 	subTests, ok := step.(nestedSubTests)
 	if !ok {
 		return nil, fmt.Errorf("unexpected %T", step)
@@ -125,8 +125,9 @@ func (s *e2eTestStep) Run(ctx context.Context) error {
 }
 
 func (s *e2eTestStep) run(ctx context.Context) error {
-	if err := s.client.Get(ctx, ctrlruntimeclient.ObjectKey{Namespace: s.jobSpec.Namespace(), Name: fmt.Sprintf("%s-cluster-profile", s.testConfig.As)}, &corev1.Secret{}); err != nil {
-		return results.ForReason("missing_cluster_profile").WithError(err).Errorf("could not find required secret: %v", err)
+	name := fmt.Sprintf("%s-cluster-profile", s.testConfig.As)
+	if err := s.client.Get(ctx, ctrlruntimeclient.ObjectKey{Namespace: s.jobSpec.Namespace(), Name: name}, &corev1.Secret{}); err != nil {
+		return results.ForReason("missing_cluster_profile").WithCategory(results.CategoryInfra).WithResource("Secret", s.jobSpec.Namespace(), name).WithError(err).Errorf("could not find required secret: %v", err)
 	}
 	return s.step.Run(ctx)
 }
@@ -0,0 +1,173 @@
+package steps
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	coreapi "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	imagev1 "github.com/openshift/api/image/v1"
+	"github.com/openshift/library-go/pkg/image/reference"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/results"
+	"github.com/openshift/ci-tools/pkg/steps/loggingclient"
+	"github.com/openshift/ci-tools/pkg/steps/utils"
+)
+
+// externalImageImportStep imports an image from an
+// external registry into the pipeline ImageStream
+type externalImageImportStep struct {
+	config  api.ExternalImageImportStepConfiguration
+	client  loggingclient.LoggingClient
+	jobSpec *api.JobSpec
+	// Allow faking time for tests
+	now func() time.Time
+}
+
+func (s *externalImageImportStep) Inputs() (api.InputDefinition, error) {
+	return api.InputDefinition{s.config.PullSpec}, nil
+}
+
+func (s *externalImageImportStep) Validate() error {
+	return validatePipelineImageStreamTag("to", s.config.To)
+}
+
+func (s *externalImageImportStep) Run(ctx context.Context) error {
+	return results.ForReason("importing_external_image").ForError(s.run(ctx))
+}
+
+func (s *externalImageImportStep) run(ctx context.Context) error {
+	reusable, err := s.reusable(ctx)
+	if err != nil {
+		return err
+	}
+	if reusable {
+		logrus.Infof("Using the previously imported %s for %s:%s.", s.config.PullSpec, api.PipelineImageStream, s.config.To)
+		return nil
+	}
+
+	logrus.Infof("Importing %s into %s:%s.", s.config.PullSpec, api.PipelineImageStream, s.config.To)
+	streamImport := &imagev1.ImageStreamImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: s.jobSpec.Namespace(),
+			Name:      api.PipelineImageStream,
+		},
+		Spec: imagev1.ImageStreamImportSpec{
+			Import: true,
+			Images: []imagev1.ImageImportSpec{{
+				To: &coreapi.LocalObjectReference{
+					Name: string(s.config.To),
+				},
+				From: coreapi.ObjectReference{
+					Kind: "DockerImage",
+					Name: s.config.PullSpec,
+				},
+				ReferencePolicy: imagev1.TagReferencePolicy{
+					Type: imagev1.LocalTagReferencePolicy,
+				},
+			}},
+		},
+	}
+	// retry importing the image a few times because we might race against establishing credentials/roles
+	var message string
+	if err := wait.ExponentialBackoff(wait.Backoff{Steps: 4, Duration: 1 * time.Second, Factor: 2}, func() (bool, error) {
+		if err := s.client.Create(ctx, streamImport); err != nil {
+			if kerrors.IsConflict(err) || kerrors.IsForbidden(err) {
+				message = err.Error()
+				RecordRetry(ctx)
+				return false, nil
+			}
+			return false, err
+		}
+		status := streamImport.Status.Images[0]
+		if status.Image == nil {
+			message = status.Status.Message
+			RecordRetry(ctx)
+			return false, nil
+		}
+		return true, nil
+	}); err != nil {
+		if err == wait.ErrWaitTimeout {
+			return fmt.Errorf("unable to import %s: %s", s.config.PullSpec, message)
+		}
+		return fmt.Errorf("unable to import %s: %w", s.config.PullSpec, err)
+	}
+	return nil
+}
+
+// reusable determines if the image was already imported into the
+// pipeline by an earlier job that ran in the same namespace and
+// can be used without importing it again.
+func (s *externalImageImportStep) reusable(ctx context.Context) (bool, error) {
+	pipeline := &imagev1.ImageStream{}
+	if err := s.client.Get(ctx, ctrlruntimeclient.ObjectKey{Namespace: s.jobSpec.Namespace(), Name: api.PipelineImageStream}, pipeline); err != nil {
+		if kerrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("could not get the %s imagestream: %w", api.PipelineImageStream, err)
+	}
+	var latest *imagev1.TagEvent
+	for _, tag := range pipeline.Status.Tags {
+		if tag.Tag == string(s.config.To) && len(tag.Items) > 0 {
+			latest = &tag.Items[0]
+			break
+		}
+	}
+	if latest == nil {
+		return false, nil
+	}
+
+	if s.config.Policy == api.ExternalImagePolicyTrack {
+		return s.config.Refresh != nil && s.now().Sub(latest.Created.Time) < s.config.Refresh.Duration, nil
+	}
+	ref, err := reference.Parse(s.config.PullSpec)
+	if err != nil {
+		return false, fmt.Errorf("could not parse pull spec %s: %w", s.config.PullSpec, err)
+	}
+	return ref.ID == latest.Image, nil
+}
+
+func (s *externalImageImportStep) Requires() []api.StepLink {
+	return nil
+}
+
+func (s *externalImageImportStep) Creates() []api.StepLink {
+	return []api.StepLink{api.InternalImageLink(s.config.To)}
+}
+
+func (s *externalImageImportStep) Provides() api.ParameterMap {
+	tag := s.config.To
+	return api.ParameterMap{
+		utils.PipelineImageEnvFor(tag): utils.ImageDigestFor(s.client, s.jobSpec.Namespace, api.PipelineImageStream, string(tag)),
+	}
+}
+
+func (s *externalImageImportStep) Name() string { return fmt.Sprintf("[input:%s]", s.config.To) }
+
+func (s *externalImageImportStep) Description() string {
+	return fmt.Sprintf("Import the external image %s into the pipeline as %s", s.config.PullSpec, s.config.To)
+}
+
+func (s *externalImageImportStep) Objects() []ctrlruntimeclient.Object {
+	return s.client.Objects()
+}
+
+func ExternalImageImportStep(
+	config api.ExternalImageImportStepConfiguration,
+	client loggingclient.LoggingClient,
+	jobSpec *api.JobSpec) api.Step {
+	return &externalImageImportStep{
+		config:  config,
+		client:  client,
+		jobSpec: jobSpec,
+		now:     time.Now,
+	}
+}
@@ -0,0 +1,118 @@
+package steps
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	coreapi "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	prowv1 "k8s.io/test-infra/prow/apis/prowjobs/v1"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	imagev1 "github.com/openshift/api/image/v1"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/steps/loggingclient"
+)
+
+type importRecordingClient struct {
+	ctrlruntimeclient.WithWatch
+	imports []imagev1.ImageStreamImport
+}
+
+func (c *importRecordingClient) Create(ctx context.Context, obj ctrlruntimeclient.Object, opts ...ctrlruntimeclient.CreateOption) error {
+	if streamImport, ok := obj.(*imagev1.ImageStreamImport); ok {
+		c.imports = append(c.imports, *streamImport.DeepCopy())
+		streamImport.Status.Images = []imagev1.ImageImportStatus{{Image: &imagev1.Image{}}}
+		return nil
+	}
+	return c.WithWatch.Create(ctx, obj, opts...)
+}
+
+func TestExternalImageImportStep(t *testing.T) {
+	const digest = "sha256:47e2f82dbede8ff990e6e240f82d78830e7558f7b30df7bd8c0693992018b1e3"
+	now := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+	pipeline := func(image string, created time.Time) *imagev1.ImageStream {
+		return &imagev1.ImageStream{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "namespace", Name: api.PipelineImageStream},
+			Status: imagev1.ImageStreamStatus{
+				Tags: []imagev1.NamedTagEventList{{
+					Tag:   "external",
+					Items: []imagev1.TagEvent{{Image: image, Created: metav1.NewTime(created)}},
+				}},
+			},
+		}
+	}
+	streamImport := func(pullSpec string) imagev1.ImageStreamImport {
+		return imagev1.ImageStreamImport{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "namespace", Name: api.PipelineImageStream},
+			Spec: imagev1.ImageStreamImportSpec{
+				Import: true,
+				Images: []imagev1.ImageImportSpec{{
+					To:              &coreapi.LocalObjectReference{Name: "external"},
+					From:            coreapi.ObjectReference{Kind: "DockerImage", Name: pullSpec},
+					ReferencePolicy: imagev1.TagReferencePolicy{Type: imagev1.LocalTagReferencePolicy},
+				}},
+			},
+		}
+	}
+
+	testCases := []struct {
+		name            string
+		image           api.ExternalImage
+		objects         []ctrlruntimeclient.Object
+		expectedImports []imagev1.ImageStreamImport
+	}{
+		{
+			name:            "pinned image is imported",
+			image:           api.ExternalImage{PullSpec: "quay.io/org/image@" + digest, Policy: api.ExternalImagePolicyPin},
+			expectedImports: []imagev1.ImageStreamImport{streamImport("quay.io/org/image@" + digest)},
+		},
+		{
+			name:    "pinned image that was already imported is reused",
+			image:   api.ExternalImage{PullSpec: "quay.io/org/image@" + digest, Policy: api.ExternalImagePolicyPin},
+			objects: []ctrlruntimeclient.Object{pipeline(digest, now.Add(-24*time.Hour))},
+		},
+		{
+			name:            "tracked image without refresh is imported again",
+			image:           api.ExternalImage{PullSpec: "quay.io/org/image:latest", Policy: api.ExternalImagePolicyTrack},
+			objects:         []ctrlruntimeclient.Object{pipeline(digest, now.Add(-time.Minute))},
+			expectedImports: []imagev1.ImageStreamImport{streamImport("quay.io/org/image:latest")},
+		},
+		{
+			name:    "tracked image imported within the refresh interval is reused",
+			image:   api.ExternalImage{PullSpec: "quay.io/org/image:latest", Policy: api.ExternalImagePolicyTrack, Refresh: &prowv1.Duration{Duration: time.Hour}},
+			objects: []ctrlruntimeclient.Object{pipeline(digest, now.Add(-time.Minute))},
+		},
+		{
+			name:            "tracked image imported before the refresh interval is imported again",
+			image:           api.ExternalImage{PullSpec: "quay.io/org/image:latest", Policy: api.ExternalImagePolicyTrack, Refresh: &prowv1.Duration{Duration: time.Hour}},
+			objects:         []ctrlruntimeclient.Object{pipeline(digest, now.Add(-2*time.Hour))},
+			expectedImports: []imagev1.ImageStreamImport{streamImport("quay.io/org/image:latest")},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := &importRecordingClient{WithWatch: fakectrlruntimeclient.NewClientBuilder().WithObjects(tc.objects...).Build()}
+			jobSpec := &api.JobSpec{}
+			jobSpec.SetNamespace("namespace")
+			step := &externalImageImportStep{
+				config:  api.ExternalImageImportStepConfiguration{ExternalImage: tc.image, To: "external"},
+				client:  loggingclient.New(client),
+				jobSpec: jobSpec,
+				now:     func() time.Time { return now },
+			}
+			if err := step.run(context.Background()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expectedImports, client.imports); diff != "" {
+				t.Errorf("imports differ from expected: %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,202 @@
+package steps
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	coreapi "k8s.io/api/core/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/test-infra/prow/secretutil"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	imagev1 "github.com/openshift/api/image/v1"
+
+	"github.com/openshift/ci-tools/pkg/api"
+)
+
+const (
+	// FailureBundleDir is the directory in the artifacts the failure bundle is written to
+	FailureBundleDir = "failure-bundle"
+	// maxBundleItems is the maximum number of objects of a kind that are part of the bundle
+	maxBundleItems = 500
+	// maxBundleFileSize is the maximum size of a single file in the bundle
+	maxBundleFileSize = 1 << 20
+)
+
+// GatherFailureBundle collects the state of the test namespace that is most helpful to
+// debug a failed job into the artifacts: events, pods, imagestreams and quota usage. It
+// runs independently of any gather steps the tests define, and all files are bounded
+// in size.
+func GatherFailureBundle(ctx context.Context, client ctrlruntimeclient.Client, namespace string, censor secretutil.Censorer) error {
+	bundle, err := failureBundle(ctx, client, namespace)
+	for name, data := range bundle {
+		if err := api.SaveArtifact(censor, filepath.Join(FailureBundleDir, name), data); err != nil {
+			logrus.WithError(err).Warnf("Failed to save %s of the failure bundle.", name)
+		}
+	}
+	return err
+}
+
+func failureBundle(ctx context.Context, client ctrlruntimeclient.Client, namespace string) (map[string][]byte, error) {
+	bundle := map[string][]byte{}
+	var errs []error
+	for name, describe := range map[string]func(context.Context, ctrlruntimeclient.Client, string) (string, error){
+		"events.txt":       describeEvents,
+		"pods.txt":         describePods,
+		"imagestreams.txt": describeImageStreams,
+		"quotas.txt":       describeQuotas,
+	} {
+		description, err := describe(ctx, client, namespace)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to gather %s: %w", name, err))
+			continue
+		}
+		bundle[name] = truncateBundleFile(description)
+	}
+	return bundle, utilerrors.NewAggregate(errs)
+}
+
+func truncateBundleFile(content string) []byte {
+	if len(content) <= maxBundleFileSize {
+		return []byte(content)
+	}
+	return []byte(content[:maxBundleFileSize] + "\n[truncated]\n")
+}
+
+func describeEvents(ctx context.Context, client ctrlruntimeclient.Client, namespace string) (string, error) {
+	events := &coreapi.EventList{}
+	if err := client.List(ctx, events, ctrlruntimeclient.InNamespace(namespace)); err != nil {
+		return "", err
+	}
+	items := events.Items
+	sort.SliceStable(items, func(i, j int) bool {
+		return eventTime(items[i]).Before(eventTime(items[j]))
+	})
+	// The most recent events are the most relevant ones
+	if len(items) > maxBundleItems {
+		items = items[len(items)-maxBundleItems:]
+	}
+	description := &strings.Builder{}
+	for _, event := range items {
+		fmt.Fprintf(description, "%s %s %s %s/%s: %s", eventTime(event).UTC().Format(time.RFC3339), event.Type, event.Reason, event.InvolvedObject.Kind, event.InvolvedObject.Name, event.Message)
+		if event.Count > 1 {
+			fmt.Fprintf(description, " (x%d)", event.Count)
+		}
+		description.WriteString("\n")
+	}
+	return description.String(), nil
+}
+
+func eventTime(event coreapi.Event) time.Time {
+	if !event.LastTimestamp.IsZero() {
+		return event.LastTimestamp.Time
+	}
+	if !event.EventTime.IsZero() {
+		return event.EventTime.Time
+	}
+	return event.CreationTimestamp.Time
+}
+
+func describePods(ctx context.Context, client ctrlruntimeclient.Client, namespace string) (string, error) {
+	pods := &coreapi.PodList{}
+	if err := client.List(ctx, pods, ctrlruntimeclient.InNamespace(namespace)); err != nil {
+		return "", err
+	}
+	items := pods.Items
+	sort.Slice(items, func(i, j int) bool { return items[i].Name < items[j].Name })
+	if len(items) > maxBundleItems {
+		items = items[:maxBundleItems]
+	}
+	description := &strings.Builder{}
+	for _, pod := range items {
+		fmt.Fprintf(description, "Pod %s: phase %s", pod.Name, pod.Status.Phase)
+		if pod.Spec.NodeName != "" {
+			fmt.Fprintf(description, " on node %s", pod.Spec.NodeName)
+		}
+		if pod.Status.Reason != "" {
+			fmt.Fprintf(description, ", %s: %s", pod.Status.Reason, pod.Status.Message)
+		}
+		description.WriteString("\n")
+		for _, condition := range pod.Status.Conditions {
+			if condition.Status == coreapi.ConditionTrue {
+				continue
+			}
+			fmt.Fprintf(description, "  condition %s=%s: %s %s\n", condition.Type, condition.Status, condition.Reason, condition.Message)
+		}
+		for _, status := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+			fmt.Fprintf(description, "  container %s: ready=%t, restarts=%d, %s\n", status.Name, status.Ready, status.RestartCount, describeContainerState(status.State))
+			if status.LastTerminationState.Terminated != nil {
+				fmt.Fprintf(description, "    last state: %s\n", describeContainerState(status.LastTerminationState))
+			}
+		}
+	}
+	return description.String(), nil
+}
+
+func describeContainerState(state coreapi.ContainerState) string {
+	switch {
+	case state.Waiting != nil:
+		return strings.TrimSpace(fmt.Sprintf("waiting (%s) %s", state.Waiting.Reason, state.Waiting.Message))
+	case state.Running != nil:
+		return "running"
+	case state.Terminated != nil:
+		return strings.TrimSpace(fmt.Sprintf("terminated with exit code %d (%s) %s", state.Terminated.ExitCode, state.Terminated.Reason, state.Terminated.Message))
+	default:
+		return "unknown state"
+	}
+}
+
+func describeImageStreams(ctx context.Context, client ctrlruntimeclient.Client, namespace string) (string, error) {
+	streams := &imagev1.ImageStreamList{}
+	if err := client.List(ctx, streams, ctrlruntimeclient.InNamespace(namespace)); err != nil {
+		return "", err
+	}
+	items := streams.Items
+	sort.Slice(items, func(i, j int) bool { return items[i].Name < items[j].Name })
+	if len(items) > maxBundleItems {
+		items = items[:maxBundleItems]
+	}
+	description := &strings.Builder{}
+	for _, stream := range items {
+		fmt.Fprintf(description, "ImageStream %s\n", stream.Name)
+		for _, tag := range stream.Status.Tags {
+			if len(tag.Items) > 0 {
+				fmt.Fprintf(description, "  %s: %s\n", tag.Tag, tag.Items[0].DockerImageReference)
+			} else {
+				fmt.Fprintf(description, "  %s: no image\n", tag.Tag)
+			}
+			for _, condition := range tag.Conditions {
+				fmt.Fprintf(description, "    condition %s=%s: %s %s\n", condition.Type, condition.Status, condition.Reason, condition.Message)
+			}
+		}
+	}
+	return description.String(), nil
+}
+
+func describeQuotas(ctx context.Context, client ctrlruntimeclient.Client, namespace string) (string, error) {
+	quotas := &coreapi.ResourceQuotaList{}
+	if err := client.List(ctx, quotas, ctrlruntimeclient.InNamespace(namespace)); err != nil {
+		return "", err
+	}
+	description := &strings.Builder{}
+	for _, quota := range quotas.Items {
+		fmt.Fprintf(description, "ResourceQuota %s\n", quota.Name)
+		var resources []string
+		for resource := range quota.Status.Hard {
+			resources = append(resources, string(resource))
+		}
+		sort.Strings(resources)
+		for _, resource := range resources {
+			hard := quota.Status.Hard[coreapi.ResourceName(resource)]
+			used := quota.Status.Used[coreapi.ResourceName(resource)]
+			fmt.Fprintf(description, "  %s: %s/%s\n", resource, used.String(), hard.String())
+		}
+	}
+	return description.String(), nil
+}
@@ -0,0 +1,116 @@
+package steps
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	coreapi "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	imagev1 "github.com/openshift/api/image/v1"
+)
+
+func TestFailureBundle(t *testing.T) {
+	start := time.Date(2021, 5, 10, 12, 0, 0, 0, time.UTC)
+	client := fakectrlruntimeclient.NewFakeClient(
+		&coreapi.Event{
+			ObjectMeta:     meta.ObjectMeta{Namespace: "ns", Name: "second"},
+			InvolvedObject: coreapi.ObjectReference{Kind: "Pod", Name: "test"},
+			Type:           coreapi.EventTypeWarning,
+			Reason:         "BackOff",
+			Message:        "Back-off pulling image",
+			Count:          3,
+			LastTimestamp:  meta.NewTime(start.Add(time.Minute)),
+		},
+		&coreapi.Event{
+			ObjectMeta:     meta.ObjectMeta{Namespace: "ns", Name: "first"},
+			InvolvedObject: coreapi.ObjectReference{Kind: "Pod", Name: "test"},
+			Type:           coreapi.EventTypeNormal,
+			Reason:         "Scheduled",
+			Message:        "Successfully assigned ns/test to node-1",
+			LastTimestamp:  meta.NewTime(start),
+		},
+		&coreapi.Event{
+			ObjectMeta: meta.ObjectMeta{Namespace: "other", Name: "other"},
+			Reason:     "Other",
+		},
+		&coreapi.Pod{
+			ObjectMeta: meta.ObjectMeta{Namespace: "ns", Name: "test"},
+			Spec:       coreapi.PodSpec{NodeName: "node-1"},
+			Status: coreapi.PodStatus{
+				Phase: coreapi.PodPending,
+				Conditions: []coreapi.PodCondition{
+					{Type: coreapi.PodScheduled, Status: coreapi.ConditionTrue},
+					{Type: coreapi.PodReady, Status: coreapi.ConditionFalse, Reason: "ContainersNotReady", Message: "containers with unready status: [test]"},
+				},
+				ContainerStatuses: []coreapi.ContainerStatus{{
+					Name:                 "test",
+					RestartCount:         1,
+					State:                coreapi.ContainerState{Waiting: &coreapi.ContainerStateWaiting{Reason: "ImagePullBackOff", Message: "Back-off pulling image"}},
+					LastTerminationState: coreapi.ContainerState{Terminated: &coreapi.ContainerStateTerminated{ExitCode: 137, Reason: "OOMKilled"}},
+				}},
+			},
+		},
+		&imagev1.ImageStream{
+			ObjectMeta: meta.ObjectMeta{Namespace: "ns", Name: "pipeline"},
+			Status: imagev1.ImageStreamStatus{Tags: []imagev1.NamedTagEventList{
+				{Tag: "src", Items: []imagev1.TagEvent{{DockerImageReference: "registry/ns/pipeline@sha256:abc"}}},
+				{Tag: "base", Conditions: []imagev1.TagEventCondition{{Type: imagev1.ImportSuccess, Status: coreapi.ConditionFalse, Reason: "NotFound", Message: "image not found"}}},
+			}},
+		},
+		&coreapi.ResourceQuota{
+			ObjectMeta: meta.ObjectMeta{Namespace: "ns", Name: "quota"},
+			Status: coreapi.ResourceQuotaStatus{
+				Hard: coreapi.ResourceList{coreapi.ResourcePods: resource.MustParse("10"), coreapi.ResourceLimitsMemory: resource.MustParse("8Gi")},
+				Used: coreapi.ResourceList{coreapi.ResourcePods: resource.MustParse("10")},
+			},
+		},
+	)
+
+	bundle, err := failureBundle(context.Background(), client, "ns")
+	if err != nil {
+		t.Fatalf("failed to gather failure bundle: %v", err)
+	}
+	actual := map[string]string{}
+	for name, data := range bundle {
+		actual[name] = string(data)
+	}
+	expected := map[string]string{
+		"events.txt": `2021-05-10T12:00:00Z Normal Scheduled Pod/test: Successfully assigned ns/test to node-1
+2021-05-10T12:01:00Z Warning BackOff Pod/test: Back-off pulling image (x3)
+`,
+		"pods.txt": `Pod test: phase Pending on node node-1
+  condition Ready=False: ContainersNotReady containers with unready status: [test]
+  container test: ready=false, restarts=1, waiting (ImagePullBackOff) Back-off pulling image
+    last state: terminated with exit code 137 (OOMKilled)
+`,
+		"imagestreams.txt": `ImageStream pipeline
+  src: registry/ns/pipeline@sha256:abc
+  base: no image
+    condition ImportSuccess=False: NotFound image not found
+`,
+		"quotas.txt": `ResourceQuota quota
+  limits.memory: 0/8Gi
+  pods: 10/10
+`,
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("bundle differs from expected: %s", diff)
+	}
+}
+
+func TestTruncateBundleFile(t *testing.T) {
+	if actual := string(truncateBundleFile("small")); actual != "small" {
+		t.Errorf("expected small file to be unchanged, got %q", actual)
+	}
+	truncated := string(truncateBundleFile(strings.Repeat("a", maxBundleFileSize+1)))
+	if !strings.HasSuffix(truncated, "\n[truncated]\n") || len(truncated) != maxBundleFileSize+len("\n[truncated]\n") {
+		t.Errorf("expected large file to be truncated, got %d bytes", len(truncated))
+	}
+}
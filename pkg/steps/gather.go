@@ -0,0 +1,73 @@
+package steps
+
+import (
+	"time"
+
+	prowv1 "k8s.io/test-infra/prow/apis/prowjobs/v1"
+
+	"github.com/openshift/ci-tools/pkg/api"
+)
+
+const defaultGatherTimeout = 30 * time.Minute
+
+var defaultGatherResources = api.ResourceRequirements{
+	Requests: api.ResourceList{"cpu": "300m", "memory": "300Mi"},
+}
+
+// gatherCommands collects the data of the cluster under test that is needed
+// to debug most failures. Every part is attempted even when another one fails,
+// and nothing is gathered when no cluster was installed.
+const gatherCommands = `export KUBECONFIG="${SHARED_DIR}/kubeconfig"
+if [[ ! -f "${KUBECONFIG}" ]]; then
+  echo "No kubeconfig in ${SHARED_DIR}, there is no cluster to gather data from."
+  exit 0
+fi
+
+failed=0
+echo "Gathering events..."
+if ! oc --insecure-skip-tls-verify --request-timeout=5m get events --all-namespaces --output=json > "${ARTIFACT_DIR}/events.json"; then
+  echo "Failed to gather events."
+  failed=1
+fi
+
+for target in must-gather audit-logs; do
+  echo "Gathering ${target}..."
+  mkdir -p "${ARTIFACT_DIR}/${target}"
+  args=()
+  if [[ "${target}" == "audit-logs" ]]; then
+    args=(-- /usr/bin/gather_audit_logs)
+  fi
+  if ! oc --insecure-skip-tls-verify adm must-gather --dest-dir "${ARTIFACT_DIR}/${target}" "${args[@]}" > "${ARTIFACT_DIR}/${target}/${target}.log" 2>&1; then
+    echo "Failed to gather ${target}, see ${target}/${target}.log in the artifacts."
+    failed=1
+  fi
+  # compress the data so the many small files do not slow down the upload
+  tar --create --gzip --file "${ARTIFACT_DIR}/${target}.tar.gz" --directory "${ARTIFACT_DIR}" "${target}"
+  rm -rf "${ARTIFACT_DIR:?}/${target}"
+done
+exit "${failed}"
+`
+
+// gatherStep returns the built-in step that gathers must-gather, the audit logs
+// and the events of the cluster under test into the artifacts. It runs `oc`
+// from the release under test and is optional, so it never fails the test.
+func gatherStep(config api.GatherConfiguration) api.LiteralTestStep {
+	timeout := &prowv1.Duration{Duration: defaultGatherTimeout}
+	if config.Timeout != nil {
+		timeout = config.Timeout
+	}
+	resources := defaultGatherResources
+	if config.Resources != nil {
+		resources = *config.Resources
+	}
+	optional := true
+	return api.LiteralTestStep{
+		As:        api.GatherStepName,
+		From:      "cli",
+		Commands:  gatherCommands,
+		Resources: resources,
+		Timeout:   timeout,
+		Optional:  &optional,
+		RunIf:     api.StepRunIfAlways,
+	}
+}
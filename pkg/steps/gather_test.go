@@ -0,0 +1,126 @@
+package steps
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	coreapi "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	prowapi "k8s.io/test-infra/prow/apis/prowjobs/v1"
+	prowdapi "k8s.io/test-infra/prow/pod-utils/downwardapi"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/steps/loggingclient"
+)
+
+func TestGatherStep(t *testing.T) {
+	yes := true
+	resources := api.ResourceRequirements{Requests: api.ResourceList{"cpu": "1", "memory": "1Gi"}}
+	for _, tc := range []struct {
+		name     string
+		config   api.GatherConfiguration
+		expected api.LiteralTestStep
+	}{{
+		name: "defaults",
+		expected: api.LiteralTestStep{
+			As:        "gather-cluster",
+			From:      "cli",
+			Commands:  gatherCommands,
+			Resources: defaultGatherResources,
+			Timeout:   &prowapi.Duration{Duration: 30 * time.Minute},
+			Optional:  &yes,
+			RunIf:     api.StepRunIfAlways,
+		},
+	}, {
+		name: "timeout and resources are configured",
+		config: api.GatherConfiguration{
+			Timeout:   &prowapi.Duration{Duration: time.Hour},
+			Resources: &resources,
+		},
+		expected: api.LiteralTestStep{
+			As:        "gather-cluster",
+			From:      "cli",
+			Commands:  gatherCommands,
+			Resources: resources,
+			Timeout:   &prowapi.Duration{Duration: time.Hour},
+			Optional:  &yes,
+			RunIf:     api.StepRunIfAlways,
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.expected, gatherStep(tc.config)); diff != "" {
+				t.Errorf("got incorrect step: %s", diff)
+			}
+		})
+	}
+}
+
+func TestRunWithGather(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		failures    sets.String
+		expected    []string
+		expectedErr bool
+	}{{
+		name:     "gathering runs first in the post phase",
+		expected: []string{"test-pre0", "test-test0", "test-gather-cluster", "test-post0"},
+	}, {
+		name:        "gathering runs when a pre step failed",
+		failures:    sets.NewString("test-pre0"),
+		expected:    []string{"test-pre0", "test-gather-cluster", "test-post0"},
+		expectedErr: true,
+	}, {
+		name:     "failed gathering does not fail the test",
+		failures: sets.NewString("test-gather-cluster"),
+		expected: []string{"test-pre0", "test-test0", "test-gather-cluster", "test-post0"},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			sa := &coreapi.ServiceAccount{
+				ObjectMeta:       metav1.ObjectMeta{Name: "test", Namespace: "ns", Labels: map[string]string{"ci.openshift.io/multi-stage-test": "test"}},
+				ImagePullSecrets: []coreapi.LocalObjectReference{{Name: "ci-operator-dockercfg-12345"}},
+			}
+			crclient := &fakePodExecutor{LoggingClient: loggingclient.New(fakectrlruntimeclient.NewFakeClient(sa.DeepCopyObject())), failures: tc.failures}
+			jobSpec := api.JobSpec{
+				JobSpec: prowdapi.JobSpec{
+					Job:       "job",
+					BuildID:   "build_id",
+					ProwJobID: "prow_job_id",
+					Type:      prowapi.PeriodicJob,
+					DecorationConfig: &prowapi.DecorationConfig{
+						Timeout:     &prowapi.Duration{Duration: time.Minute},
+						GracePeriod: &prowapi.Duration{Duration: time.Second},
+						UtilityImages: &prowapi.UtilityImages{
+							Sidecar:    "sidecar",
+							Entrypoint: "entrypoint",
+						},
+					},
+				},
+			}
+			jobSpec.SetNamespace("ns")
+			step := MultiStageTestStep(api.TestStepConfiguration{
+				As: "test",
+				MultiStageTestConfigurationLiteral: &api.MultiStageTestConfigurationLiteral{
+					Pre:    []api.LiteralTestStep{{As: "pre0"}},
+					Test:   []api.LiteralTestStep{{As: "test0"}},
+					Post:   []api.LiteralTestStep{{As: "post0"}},
+					Gather: &api.GatherConfiguration{},
+				},
+			}, &api.ReleaseBuildConfiguration{}, nil, &fakePodClient{fakePodExecutor: crclient}, &jobSpec, nil)
+			if err := step.Run(context.Background()); (err != nil) != tc.expectedErr {
+				t.Errorf("expected error: %t, got error: %v", tc.expectedErr, err)
+			}
+			var names []string
+			for _, pod := range crclient.createdPods {
+				names = append(names, pod.Name)
+			}
+			if diff := cmp.Diff(tc.expected, names); diff != "" {
+				t.Errorf("did not execute correct pods: %s", diff)
+			}
+		})
+	}
+}
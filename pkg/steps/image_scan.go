@@ -0,0 +1,201 @@
+package steps
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	coreapi "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/junit"
+	"github.com/openshift/ci-tools/pkg/results"
+	"github.com/openshift/ci-tools/pkg/steps/utils"
+)
+
+const (
+	imageScanContainerName = "scan"
+	// imageScanImageEnv holds the pull spec of the scanned image
+	imageScanImageEnv = "IMAGE"
+)
+
+type imageScanStep struct {
+	config    api.ImageScanStepConfiguration
+	resources api.ResourceConfiguration
+	client    PodClient
+	jobSpec   *api.JobSpec
+
+	subTests []*junit.TestCase
+}
+
+func (s *imageScanStep) Inputs() (api.InputDefinition, error) {
+	return nil, nil
+}
+
+func (s *imageScanStep) Validate() error {
+	if len(s.config.Images) == 0 {
+		return errors.New("images: value required but not provided")
+	}
+	for i, image := range s.config.Images {
+		if err := validatePipelineImageStreamTag(fmt.Sprintf("images[%d]", i), image); err != nil {
+			return err
+		}
+		if name := imageScanPodName(s.config.As, image); len(validation.IsDNS1123Subdomain(name)) != 0 {
+			return fmt.Errorf("images[%d]: '%s' is not a valid Kubernetes object name for the pod scanning the image", i, name)
+		}
+	}
+	if s.config.Scanner == "" {
+		return errors.New("scanner: value required but not provided")
+	}
+	return nil
+}
+
+func (s *imageScanStep) Run(ctx context.Context) error {
+	return results.ForReason("scanning_images").ForError(s.run(ctx))
+}
+
+func (s *imageScanStep) run(ctx context.Context) error {
+	logrus.Infof("Scanning images %s with %s", s.imageList(), s.config.Scanner)
+	containerResources, err := resourcesFor(s.resources.RequirementsForStep(s.config.As))
+	if err != nil {
+		return fmt.Errorf("unable to calculate image scan pod resources for %s: %w", s.config.As, err)
+	}
+
+	go func() {
+		<-ctx.Done()
+		logrus.Infof("cleanup: Deleting image scan pods for %s", s.config.As)
+		for _, image := range s.config.Images {
+			pod := &coreapi.Pod{ObjectMeta: meta.ObjectMeta{Namespace: s.jobSpec.Namespace(), Name: imageScanPodName(s.config.As, image)}}
+			if err := s.client.Delete(cleanupCtx, pod); err != nil && !kerrors.IsNotFound(err) {
+				logrus.WithError(err).Warnf("Could not delete image scan pod %s.", pod.Name)
+			}
+		}
+	}()
+
+	s.subTests = nil
+	var errs []error
+	for _, image := range s.config.Images {
+		start := time.Now()
+		findings, err := s.scan(ctx, image, containerResources)
+		if err != nil {
+			return err
+		}
+		testCase := &junit.TestCase{
+			Name:     fmt.Sprintf("%s - Scan image %s", s.Description(), image),
+			Duration: time.Since(start).Seconds(),
+		}
+		switch {
+		case findings == nil:
+		case s.config.Informational:
+			logrus.Warnf("Informational image scan %s found issues in %s: %v", s.config.As, image, findings)
+			testCase.SkipMessage = &junit.SkipMessage{Message: fmt.Sprintf("informational scan found issues: %v", findings)}
+		default:
+			testCase.FailureOutput = &junit.FailureOutput{Output: findings.Error()}
+			errs = append(errs, findings)
+		}
+		s.subTests = append(s.subTests, testCase)
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// scan runs the scanner against a single image. It returns the issues the
+// scanner found separately from errors that prevented the scan from running.
+func (s *imageScanStep) scan(ctx context.Context, image api.PipelineImageStreamTagReference, resources coreapi.ResourceRequirements) (findings error, err error) {
+	pullSpec, err := utils.ImageDigestFor(s.client, s.jobSpec.Namespace, api.PipelineImageStream, string(image))()
+	if err != nil {
+		return nil, fmt.Errorf("could not resolve image %s to scan: %w", image, err)
+	}
+	pod, err := s.generatePod(image, pullSpec, resources)
+	if err != nil {
+		return nil, fmt.Errorf("image scan pod for %s was invalid: %w", image, err)
+	}
+	pod, err = createOrRestartPod(ctx, s.client, pod)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create or restart image scan pod for %s: %w", image, err)
+	}
+	if _, err := waitForPodCompletion(ctx, s.client, pod.Namespace, pod.Name, nil, false); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return fmt.Errorf("image scan %s of %s failed: %w", s.config.As, image, err), nil
+	}
+	return nil, nil
+}
+
+func (s *imageScanStep) generatePod(image api.PipelineImageStreamTagReference, pullSpec string, resources coreapi.ResourceRequirements) (*coreapi.Pod, error) {
+	artifactDir := filepath.Join(s.config.As, string(image))
+	commands := []string{"/bin/bash", "-c", "#!/bin/bash\nset -eu\n" + s.config.Commands}
+	pod, err := generateBasePod(s.jobSpec, nil, imageScanPodName(s.config.As, image), imageScanContainerName, commands, s.config.Scanner, resources, artifactDir, s.jobSpec.DecorationConfig, s.jobSpec.RawSpec(), nil)
+	if err != nil {
+		return nil, err
+	}
+	container := &pod.Spec.Containers[0]
+	container.Env = append(container.Env, coreapi.EnvVar{Name: imageScanImageEnv, Value: pullSpec})
+	if owner := s.jobSpec.Owner(); owner != nil {
+		pod.OwnerReferences = append(pod.OwnerReferences, *owner)
+	}
+	return pod, nil
+}
+
+func imageScanPodName(as string, image api.PipelineImageStreamTagReference) string {
+	return fmt.Sprintf("%s-%s", as, image)
+}
+
+func (s *imageScanStep) imageList() string {
+	var images []string
+	for _, image := range s.config.Images {
+		images = append(images, string(image))
+	}
+	return strings.Join(images, ", ")
+}
+
+func (s *imageScanStep) SubTests() []*junit.TestCase {
+	return s.subTests
+}
+
+func (s *imageScanStep) Requires() []api.StepLink {
+	var links []api.StepLink
+	for _, image := range s.config.Images {
+		links = append(links, api.InternalImageLink(image))
+	}
+	return links
+}
+
+func (s *imageScanStep) Creates() []api.StepLink {
+	return []api.StepLink{}
+}
+
+func (s *imageScanStep) Provides() api.ParameterMap {
+	return nil
+}
+
+func (s *imageScanStep) Name() string { return s.config.As }
+
+func (s *imageScanStep) Description() string {
+	return fmt.Sprintf("Run image scan %s", s.config.As)
+}
+
+func (s *imageScanStep) Objects() []ctrlruntimeclient.Object {
+	return s.client.Objects()
+}
+
+// ImageScanStep runs a scanner against images in the pipeline. Unless the scan
+// is informational, the step fails when the scanner finds issues in any image.
+func ImageScanStep(config api.ImageScanStepConfiguration, resources api.ResourceConfiguration, client PodClient, jobSpec *api.JobSpec) api.Step {
+	return &imageScanStep{
+		config:    config,
+		resources: resources,
+		client:    client,
+		jobSpec:   jobSpec,
+	}
+}
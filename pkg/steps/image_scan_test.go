@@ -0,0 +1,172 @@
+package steps
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	prowapi "k8s.io/test-infra/prow/apis/prowjobs/v1"
+	"k8s.io/test-infra/prow/pod-utils/downwardapi"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	imagev1 "github.com/openshift/api/image/v1"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/junit"
+	"github.com/openshift/ci-tools/pkg/steps/loggingclient"
+	"github.com/openshift/ci-tools/pkg/testhelper"
+)
+
+func TestImageScanStepValidate(t *testing.T) {
+	testCases := []struct {
+		name     string
+		config   api.ImageScanStepConfiguration
+		expected error
+	}{
+		{
+			name:   "valid",
+			config: api.ImageScanStepConfiguration{As: "cves", Images: []api.PipelineImageStreamTagReference{"src", "operator"}, Scanner: "quay.io/anchore/grype"},
+		},
+		{
+			name:     "no images",
+			config:   api.ImageScanStepConfiguration{As: "cves", Scanner: "quay.io/anchore/grype"},
+			expected: errors.New("images: value required but not provided"),
+		},
+		{
+			name:     "image that cannot be used in a pod name",
+			config:   api.ImageScanStepConfiguration{As: "cves", Images: []api.PipelineImageStreamTagReference{"src", "My_Image"}, Scanner: "quay.io/anchore/grype"},
+			expected: errors.New("images[1]: 'cves-My_Image' is not a valid Kubernetes object name for the pod scanning the image"),
+		},
+		{
+			name:     "no scanner",
+			config:   api.ImageScanStepConfiguration{As: "cves", Images: []api.PipelineImageStreamTagReference{"src"}},
+			expected: errors.New("scanner: value required but not provided"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.expected, ImageScanStep(tc.config, nil, nil, nil).Validate(), testhelper.EquateErrorMessage); diff != "" {
+				t.Errorf("unexpected error: %s", diff)
+			}
+		})
+	}
+}
+
+func TestImageScanStep(t *testing.T) {
+	jobSpec := &api.JobSpec{
+		JobSpec: downwardapi.JobSpec{
+			Job:       "job",
+			BuildID:   "build-id",
+			ProwJobID: "prow-job-id",
+			Type:      prowapi.PeriodicJob,
+			DecorationConfig: &prowapi.DecorationConfig{
+				Timeout:     &prowapi.Duration{Duration: time.Minute},
+				GracePeriod: &prowapi.Duration{Duration: time.Second},
+				UtilityImages: &prowapi.UtilityImages{
+					Sidecar:    "sidecar",
+					Entrypoint: "entrypoint",
+				},
+			},
+		},
+	}
+	jobSpec.SetNamespace("ns")
+	pipeline := &imagev1.ImageStream{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: api.PipelineImageStream},
+		Status: imagev1.ImageStreamStatus{
+			PublicDockerImageRepository: "registry.ci.openshift.org/ns/pipeline",
+			Tags: []imagev1.NamedTagEventList{
+				{Tag: "operator", Items: []imagev1.TagEvent{{Image: "sha256:operator"}}},
+			},
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		informational bool
+		podStatus     corev1.PodPhase
+		expectedErr   bool
+		expected      []*junit.TestCase
+	}{
+		{
+			name:      "scan succeeds",
+			podStatus: corev1.PodSucceeded,
+			expected:  []*junit.TestCase{{Name: "Run image scan cves - Scan image operator"}},
+		},
+		{
+			name:        "scan finds issues",
+			podStatus:   corev1.PodFailed,
+			expectedErr: true,
+			expected: []*junit.TestCase{{
+				Name:          "Run image scan cves - Scan image operator",
+				FailureOutput: &junit.FailureOutput{Output: "image scan cves of operator failed"},
+			}},
+		},
+		{
+			name:          "informational scan finds issues",
+			informational: true,
+			podStatus:     corev1.PodFailed,
+			expected: []*junit.TestCase{{
+				Name:        "Run image scan cves - Scan image operator",
+				SkipMessage: &junit.SkipMessage{Message: "informational scan found issues: image scan cves of operator failed"},
+			}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := api.ImageScanStepConfiguration{
+				As:            "cves",
+				Images:        []api.PipelineImageStreamTagReference{"operator"},
+				Scanner:       "quay.io/anchore/grype",
+				Commands:      "grype ${IMAGE}",
+				Informational: tc.informational,
+			}
+			client := &podClient{LoggingClient: loggingclient.New(&podStatusChangingClient{WithWatch: fakectrlruntimeclient.NewFakeClient(pipeline.DeepCopy()), dest: tc.podStatus})}
+			step := ImageScanStep(config, api.ResourceConfiguration{"*": {}}, client, jobSpec)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if err := step.Run(ctx); (err != nil) != tc.expectedErr {
+				t.Fatalf("expected error: %t, got: %v", tc.expectedErr, err)
+			}
+
+			subTests := step.(*imageScanStep).SubTests()
+			for _, subTest := range subTests {
+				// the output includes the runtime of the pod
+				subTest.Duration = 0
+				if subTest.FailureOutput != nil {
+					subTest.FailureOutput.Output = strings.Split(subTest.FailureOutput.Output, ":")[0]
+				}
+				if subTest.SkipMessage != nil {
+					subTest.SkipMessage.Message = strings.Join(strings.Split(subTest.SkipMessage.Message, ":")[:2], ":")
+				}
+			}
+			if diff := cmp.Diff(tc.expected, subTests); diff != "" {
+				t.Errorf("unexpected test cases: %s", diff)
+			}
+
+			pod := &corev1.Pod{}
+			if err := client.Get(ctx, ctrlruntimeclient.ObjectKey{Namespace: "ns", Name: "cves-operator"}, pod); err != nil {
+				t.Fatalf("failed to get pod: %v", err)
+			}
+			container := pod.Spec.Containers[0]
+			if container.Image != config.Scanner {
+				t.Errorf("expected the scanner image %s, got %s", config.Scanner, container.Image)
+			}
+			var pullSpec string
+			for _, env := range container.Env {
+				if env.Name == imageScanImageEnv {
+					pullSpec = env.Value
+				}
+			}
+			if expected := "registry.ci.openshift.org/ns/pipeline@sha256:operator"; pullSpec != expected {
+				t.Errorf("expected $%s to be %s, got %s", imageScanImageEnv, expected, pullSpec)
+			}
+		})
+	}
+}
@@ -31,7 +31,9 @@ func (s *indexGeneratorStep) Inputs() (api.InputDefinition, error) {
 	return nil, nil
 }
 
-func (*indexGeneratorStep) Validate() error { return nil }
+func (s *indexGeneratorStep) Validate() error {
+	return validateBuildTarget("to", s.config.To)
+}
 
 func (s *indexGeneratorStep) Run(ctx context.Context) error {
 	return results.ForReason("building_index_generator").ForError(s.run(ctx))
@@ -87,8 +89,12 @@ func (s *indexGeneratorStep) run(ctx context.Context) error {
 }
 
 func (s *indexGeneratorStep) indexGenDockerfile() (string, error) {
+	opmImage := s.config.OPMImage
+	if opmImage == "" {
+		opmImage = api.DefaultOPMImage
+	}
 	var dockerCommands []string
-	dockerCommands = append(dockerCommands, "FROM quay.io/operator-framework/upstream-opm-builder AS builder")
+	dockerCommands = append(dockerCommands, fmt.Sprintf("FROM %s AS builder", opmImage))
 	// pull secret is needed for opm command
 	dockerCommands = append(dockerCommands, "COPY .dockerconfigjson .")
 	dockerCommands = append(dockerCommands, "RUN mkdir $HOME/.docker && mv .dockerconfigjson $HOME/.docker/config.json")
@@ -101,7 +107,10 @@ func (s *indexGeneratorStep) indexGenDockerfile() (string, error) {
 		bundles = append(bundles, fullSpec)
 	}
 	baseIndex := ""
-	if s.config.BaseIndex != "" {
+	if api.IsPublishedIndex(s.config.BaseIndex) {
+		// opm pulls the published index itself, using the pull secret set up above
+		baseIndex = s.config.BaseIndex
+	} else if s.config.BaseIndex != "" {
 		fullSpec, err := utils.ImageDigestFor(s.client, s.jobSpec.Namespace, api.PipelineImageStream, s.config.BaseIndex)()
 		if err != nil {
 			return "", fmt.Errorf("failed to get image digest for bundle `%s`: %w", s.config.BaseIndex, err)
@@ -114,10 +123,21 @@ func (s *indexGeneratorStep) indexGenDockerfile() (string, error) {
 	}
 	opmCommand = fmt.Sprintf("%s]", opmCommand)
 	dockerCommands = append(dockerCommands, opmCommand)
+	if s.config.IndexFormat == api.IndexFormatFileBasedCatalog {
+		// opm can only add bundles to sqlite databases while respecting the update
+		// mode, so the generated database is migrated to a file-based catalog
+		dockerCommands = append(dockerCommands, `RUN ["opm", "migrate", "/database/index.db", "/configs"]`)
+		dockerCommands = append(dockerCommands, `RUN ["opm", "generate", "dockerfile", "/configs"]`)
+	}
 	dockerCommands = append(dockerCommands, fmt.Sprintf("FROM %s:%s", api.PipelineImageStream, api.PipelineImageStreamTagReferenceSource))
 	dockerCommands = append(dockerCommands, fmt.Sprintf("WORKDIR %s", IndexDataDirectory))
-	dockerCommands = append(dockerCommands, fmt.Sprintf("COPY --from=builder %s %s", IndexDockerfileName, IndexDockerfileName))
-	dockerCommands = append(dockerCommands, "COPY --from=builder /database/ database")
+	if s.config.IndexFormat == api.IndexFormatFileBasedCatalog {
+		dockerCommands = append(dockerCommands, fmt.Sprintf("COPY --from=builder /configs.Dockerfile %s", IndexDockerfileName))
+		dockerCommands = append(dockerCommands, "COPY --from=builder /configs/ configs")
+	} else {
+		dockerCommands = append(dockerCommands, fmt.Sprintf("COPY --from=builder %s %s", IndexDockerfileName, IndexDockerfileName))
+		dockerCommands = append(dockerCommands, "COPY --from=builder /database/ database")
+	}
 	return strings.Join(dockerCommands, "\n"), nil
 }
 
@@ -127,7 +147,7 @@ func (s *indexGeneratorStep) Requires() []api.StepLink {
 		imageStream, name, _ := s.releaseBuildConfig.DependencyParts(api.StepDependency{Name: bundle})
 		links = append(links, api.LinkForImage(imageStream, name))
 	}
-	if s.config.BaseIndex != "" {
+	if s.config.BaseIndex != "" && !api.IsPublishedIndex(s.config.BaseIndex) {
 		imageStream, name, _ := s.releaseBuildConfig.DependencyParts(api.StepDependency{Name: s.config.BaseIndex})
 		links = append(links, api.LinkForImage(imageStream, name))
 	}
@@ -100,6 +100,47 @@ FROM pipeline:src
 WORKDIR /index-data
 COPY --from=builder index.Dockerfile index.Dockerfile
 COPY --from=builder /database/ database`,
+	}, {
+		name: "With published base index",
+		step: indexGeneratorStep{
+			config: api.IndexGeneratorStepConfiguration{
+				OperatorIndex: []string{"ci-bundle0"},
+				UpdateGraph:   api.IndexUpdateReplaces,
+				BaseIndex:     "registry.redhat.io/redhat/redhat-operator-index:v4.8",
+			},
+			jobSpec: &api.JobSpec{},
+			client:  &buildClient{LoggingClient: loggingclient.New(fakeClientSet)},
+		},
+		expected: `FROM quay.io/operator-framework/upstream-opm-builder AS builder
+COPY .dockerconfigjson .
+RUN mkdir $HOME/.docker && mv .dockerconfigjson $HOME/.docker/config.json
+RUN ["opm", "index", "add", "--mode", "replaces", "--bundles", "some-reg/target-namespace/pipeline@ci-bundle0", "--out-dockerfile", "index.Dockerfile", "--generate", "--from-index", "registry.redhat.io/redhat/redhat-operator-index:v4.8"]
+FROM pipeline:src
+WORKDIR /index-data
+COPY --from=builder index.Dockerfile index.Dockerfile
+COPY --from=builder /database/ database`,
+	}, {
+		name: "file-based catalog with custom opm image",
+		step: indexGeneratorStep{
+			config: api.IndexGeneratorStepConfiguration{
+				OperatorIndex: []string{"ci-bundle0"},
+				UpdateGraph:   api.IndexUpdateReplaces,
+				IndexFormat:   api.IndexFormatFileBasedCatalog,
+				OPMImage:      "quay.io/operator-framework/upstream-opm-builder:v1.19.0",
+			},
+			jobSpec: &api.JobSpec{},
+			client:  &buildClient{LoggingClient: loggingclient.New(fakeClientSet)},
+		},
+		expected: `FROM quay.io/operator-framework/upstream-opm-builder:v1.19.0 AS builder
+COPY .dockerconfigjson .
+RUN mkdir $HOME/.docker && mv .dockerconfigjson $HOME/.docker/config.json
+RUN ["opm", "index", "add", "--mode", "replaces", "--bundles", "some-reg/target-namespace/pipeline@ci-bundle0", "--out-dockerfile", "index.Dockerfile", "--generate"]
+RUN ["opm", "migrate", "/database/index.db", "/configs"]
+RUN ["opm", "generate", "dockerfile", "/configs"]
+FROM pipeline:src
+WORKDIR /index-data
+COPY --from=builder /configs.Dockerfile index.Dockerfile
+COPY --from=builder /configs/ configs`,
 	}}
 	for _, testCase := range testCases {
 		t.Run(testCase.name, func(t *testing.T) {
@@ -114,3 +155,46 @@ COPY --from=builder /database/ database`,
 		})
 	}
 }
+
+func TestIndexGeneratorRequires(t *testing.T) {
+	testCases := []struct {
+		name     string
+		config   api.IndexGeneratorStepConfiguration
+		expected []api.StepLink
+	}{{
+		name: "base index in the pipeline",
+		config: api.IndexGeneratorStepConfiguration{
+			OperatorIndex: []string{"ci-bundle0"},
+			BaseIndex:     "the-index",
+		},
+		expected: []api.StepLink{
+			api.InternalImageLink("ci-bundle0"),
+			api.InternalImageLink("the-index"),
+		},
+	}, {
+		name: "published base index",
+		config: api.IndexGeneratorStepConfiguration{
+			OperatorIndex: []string{"ci-bundle0"},
+			BaseIndex:     "registry.redhat.io/redhat/redhat-operator-index:v4.8",
+		},
+		expected: []api.StepLink{
+			api.InternalImageLink("ci-bundle0"),
+		},
+	}}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			config := &api.ReleaseBuildConfiguration{
+				InputConfiguration: api.InputConfiguration{
+					BaseImages: map[string]api.ImageStreamTagReference{"the-index": {Namespace: "ns", Name: "index", Tag: "latest"}},
+				},
+				Operator: &api.OperatorStepConfiguration{
+					Bundles: []api.Bundle{{As: "ci-bundle0"}},
+				},
+			}
+			step := IndexGeneratorStep(testCase.config, config, api.ResourceConfiguration{}, nil, &api.JobSpec{}, nil)
+			if diff := cmp.Diff(testCase.expected, step.Requires(), api.Comparer()); diff != "" {
+				t.Errorf("step requires differ from expected: %s", diff)
+			}
+		})
+	}
+}
@@ -10,7 +10,6 @@ import (
 	coreapi "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/util/wait"
 	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 
 	imagev1 "github.com/openshift/api/image/v1"
@@ -19,7 +18,6 @@ import (
 	"github.com/openshift/ci-tools/pkg/results"
 	"github.com/openshift/ci-tools/pkg/steps/loggingclient"
 	"github.com/openshift/ci-tools/pkg/steps/utils"
-	"github.com/openshift/ci-tools/pkg/util"
 )
 
 var (
@@ -33,6 +31,7 @@ type inputImageTagStep struct {
 	config  *api.InputImageTagStepConfiguration
 	client  loggingclient.LoggingClient
 	jobSpec *api.JobSpec
+	waiter  *utils.ImageStreamTagWaiter
 
 	imageName string
 }
@@ -58,7 +57,9 @@ func (s *inputImageTagStep) Inputs() (api.InputDefinition, error) {
 	return api.InputDefinition{from.Image.Name}, nil
 }
 
-func (*inputImageTagStep) Validate() error { return nil }
+func (s *inputImageTagStep) Validate() error {
+	return validatePipelineImageStreamTag("to", s.config.To)
+}
 
 func (s *inputImageTagStep) Run(ctx context.Context) error {
 	return results.ForReason("tagging_input_image").ForError(s.run(ctx))
@@ -93,25 +94,17 @@ func (s *inputImageTagStep) run(ctx context.Context) error {
 	}
 
 	// Wait image is ready
-	importCtx, cancel := context.WithTimeout(ctx, 35*time.Minute)
-	defer cancel()
-	if err := wait.PollImmediateUntil(10*time.Second, func() (bool, error) {
-		pipeline := &imagev1.ImageStream{}
-		if err := s.client.Get(importCtx, ctrlruntimeclient.ObjectKey{Namespace: s.jobSpec.Namespace(), Name: api.PipelineImageStream}, pipeline); err != nil {
-			return false, err
-		}
-		_, exists := util.ResolvePullSpec(pipeline, string(s.config.To), true)
-		if !exists {
-			logrus.Debugf("Waiting to import %s ...", ist.ObjectMeta.Name)
-		}
-		return exists, nil
-	}, importCtx.Done()); err != nil {
+	if _, err := s.waiter.Wait(ctx, s.jobSpec.Namespace(), api.PipelineImageStream, string(s.config.To), 35*time.Minute); err != nil {
 		logrus.WithError(err).Errorf("Could not resolve tag %s in imagestream %s.", s.config.To, api.PipelineImageStream)
 		return err
 	}
 	return nil
 }
 
+func (s *inputImageTagStep) SubSteps() []api.CIOperatorStepDetailInfo {
+	return s.waiter.SubSteps()
+}
+
 func (s *inputImageTagStep) Requires() []api.StepLink {
 	return nil
 }
@@ -146,5 +139,6 @@ func InputImageTagStep(
 		config:  config,
 		client:  client,
 		jobSpec: jobSpec,
+		waiter:  utils.NewImageStreamTagWaiter(client),
 	}
 }
@@ -0,0 +1,185 @@
+package steps
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/junit"
+	"github.com/openshift/ci-tools/pkg/results"
+)
+
+// JUnitAggregationFile is the merged report in the artifact directory. Its
+// name matches what the JUnit lens of Prow looks for, so the results of the
+// steps that were merged into it are removed to not report them twice.
+const JUnitAggregationFile = "junit_steps.xml"
+
+// junitFile matches the JUnit results steps produce
+var junitFile = regexp.MustCompile(`^junit.*\.xml$`)
+
+// junitAggregationStep merges the JUnit results that steps gathered into the
+// artifact directory into a single suite. It is run once all other steps are
+// done, whether they succeeded or not.
+type junitAggregationStep struct {
+	artifactDir string
+}
+
+func (s *junitAggregationStep) Inputs() (api.InputDefinition, error) {
+	return nil, nil
+}
+
+func (*junitAggregationStep) Validate() error { return nil }
+
+func (s *junitAggregationStep) Run(_ context.Context) error {
+	return results.ForReason("aggregating_junit").ForError(s.run())
+}
+
+func (s *junitAggregationStep) run() error {
+	suite, merged, err := aggregateJUnit(s.artifactDir)
+	if err != nil {
+		return err
+	}
+	if len(suite.TestCases) == 0 {
+		logrus.Debug("No JUnit results were gathered from the steps.")
+		return nil
+	}
+	out, err := xml.MarshalIndent(&junit.TestSuites{Suites: []*junit.TestSuite{suite}}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not marshal the aggregated JUnit results: %w", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(s.artifactDir, JUnitAggregationFile), out, 0644); err != nil {
+		return fmt.Errorf("could not write the aggregated JUnit results: %w", err)
+	}
+	for _, path := range merged {
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("could not remove the merged JUnit results: %w", err)
+		}
+	}
+	return nil
+}
+
+// aggregateJUnit merges the JUnit results in the directories of the steps in
+// the artifact directory and returns the files that were merged. Test cases
+// are prefixed with the name of the step that produced them. Results at the
+// top of the artifact directory are the ones of ci-operator itself and are
+// not merged.
+func aggregateJUnit(artifactDir string) (*junit.TestSuite, []string, error) {
+	suite := &junit.TestSuite{Name: "steps"}
+	seen := map[string]bool{}
+	var merged []string
+	err := filepath.Walk(artifactDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !junitFile.MatchString(info.Name()) {
+			return nil
+		}
+		rel, err := filepath.Rel(artifactDir, path)
+		if err != nil {
+			return err
+		}
+		parts := strings.SplitN(filepath.ToSlash(rel), "/", 2)
+		if len(parts) != 2 {
+			return nil
+		}
+		raw, err := ioutil.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("could not read %s: %w", rel, err)
+		}
+		suites, err := parseJUnit(raw)
+		if err != nil {
+			logrus.WithError(err).Warnf("Ignoring JUnit results in %s that could not be parsed.", rel)
+			return nil
+		}
+		merged = append(merged, path)
+		for _, testCase := range testCasesIn(suites) {
+			testCase.Name = fmt.Sprintf("%s: %s", parts[0], testCase.Name)
+			// the same results may be gathered more than once, but a test
+			// that passes after it failed is reported with both outcomes
+			key := fmt.Sprintf("%s/%t/%t", testCase.Name, testCase.FailureOutput != nil, testCase.SkipMessage != nil)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			suite.TestCases = append(suite.TestCases, testCase)
+			suite.NumTests++
+			suite.Duration += testCase.Duration
+			if testCase.FailureOutput != nil {
+				suite.NumFailed++
+			}
+			if testCase.SkipMessage != nil {
+				suite.NumSkipped++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not gather the JUnit results of the steps: %w", err)
+	}
+	sort.SliceStable(suite.TestCases, func(i, j int) bool {
+		return suite.TestCases[i].Name < suite.TestCases[j].Name
+	})
+	return suite, merged, nil
+}
+
+// parseJUnit reads results with either a collection of suites or a single
+// suite at their root
+func parseJUnit(raw []byte) ([]*junit.TestSuite, error) {
+	suites := &junit.TestSuites{}
+	if err := xml.Unmarshal(raw, suites); err == nil {
+		return suites.Suites, nil
+	}
+	suite := &junit.TestSuite{}
+	if err := xml.Unmarshal(raw, suite); err != nil {
+		return nil, err
+	}
+	return []*junit.TestSuite{suite}, nil
+}
+
+func testCasesIn(suites []*junit.TestSuite) []*junit.TestCase {
+	var testCases []*junit.TestCase
+	for _, suite := range suites {
+		testCases = append(testCases, suite.TestCases...)
+		testCases = append(testCases, testCasesIn(suite.Children)...)
+	}
+	return testCases
+}
+
+func (s *junitAggregationStep) Requires() []api.StepLink {
+	return []api.StepLink{api.AllStepsLink()}
+}
+
+func (s *junitAggregationStep) Creates() []api.StepLink {
+	return nil
+}
+
+func (s *junitAggregationStep) Provides() api.ParameterMap {
+	return nil
+}
+
+func (s *junitAggregationStep) Name() string { return "junit/aggregate" }
+
+func (s *junitAggregationStep) Description() string {
+	return "Merge the JUnit results of all steps into a single report"
+}
+
+func (s *junitAggregationStep) Objects() []ctrlruntimeclient.Object {
+	return nil
+}
+
+// JUnitAggregationStep merges the JUnit results of the steps in the artifact
+// directory into a single report that replaces them.
+func JUnitAggregationStep(artifactDir string) api.Step {
+	return &junitAggregationStep{artifactDir: artifactDir}
+}
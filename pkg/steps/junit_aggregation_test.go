@@ -0,0 +1,88 @@
+package steps
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/openshift/ci-tools/pkg/testhelper"
+)
+
+func TestJUnitAggregationStep(t *testing.T) {
+	files := map[string]string{
+		"junit_operator.xml": `<testsuites><testsuite name="operator"><testcase name="Run unit"></testcase></testsuite></testsuites>`,
+		"unit/junit.xml":     `<testsuite name="unit"><testcase name="TestA" time="1.5"></testcase><testcase name="TestB" time="0.5"><failure message="oops">boom</failure></testcase></testsuite>`,
+		"unit/copy/junit_unit.xml": `<testsuites><testsuite name="unit"><testcase name="TestA" time="1.5"></testcase>` +
+			`<testcase name="TestB" time="0.5"></testcase></testsuite></testsuites>`,
+		"e2e/setup/junit_install.xml": `<testsuites><testsuite name="install"><testsuite name="nested"><testcase name="install"></testcase></testsuite>` +
+			`<testcase name="optional"><skipped message="not needed"></skipped></testcase></testsuite></testsuites>`,
+		"e2e/broken/junit.xml": `<testsuite`,
+		"e2e/build-log.txt":    `not a junit file`,
+	}
+	dir, err := ioutil.TempDir("", "junit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("couldn't clean up tmpdir: %v", err)
+		}
+	}()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := JUnitAggregationStep(dir).Run(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	aggregated, err := ioutil.ReadFile(filepath.Join(dir, JUnitAggregationFile))
+	if err != nil {
+		t.Fatalf("could not read the aggregated results: %v", err)
+	}
+	testhelper.CompareWithFixture(t, aggregated)
+
+	// only results that were merged are removed, so nothing is reported twice
+	var remaining []string
+	if err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		remaining = append(remaining, rel)
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"e2e/broken/junit.xml", "e2e/build-log.txt", "junit_operator.xml", JUnitAggregationFile}
+	if diff := cmp.Diff(expected, remaining); diff != "" {
+		t.Errorf("remaining files differ from expected: %s", diff)
+	}
+}
+
+func TestJUnitAggregationStepWithoutResults(t *testing.T) {
+	dir, err := ioutil.TempDir("", "junit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Errorf("couldn't clean up tmpdir: %v", err)
+		}
+	}()
+	if err := JUnitAggregationStep(dir).Run(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, JUnitAggregationFile)); !os.IsNotExist(err) {
+		t.Errorf("expected no aggregated results to be written, got %v", err)
+	}
+}
@@ -35,6 +35,11 @@ type leaseStep struct {
 
 	// for sending heartbeats during lease acquisition
 	namespace func() string
+
+	// profiles are the cluster profiles the first lease can be acquired for,
+	// in order of preference, and profile is the one it was acquired for
+	profiles []api.ClusterProfile
+	profile  api.ClusterProfile
 }
 
 func LeaseStep(client *lease.Client, leases []api.StepLease, wrapped api.Step, namespace func() string) api.Step {
@@ -49,6 +54,16 @@ func LeaseStep(client *lease.Client, leases []api.StepLease, wrapped api.Step, n
 	return &ret
 }
 
+// ClusterProfileLeaseStep is a LeaseStep whose first lease is the one of a
+// cluster profile. The lease is acquired for the first of the profiles with
+// free capacity, so the test can fall back to another cloud when the quota of
+// the preferred one is exhausted.
+func ClusterProfileLeaseStep(client *lease.Client, profiles []api.ClusterProfile, leases []api.StepLease, wrapped api.Step, namespace func() string) api.Step {
+	ret := LeaseStep(client, leases, wrapped, namespace).(*leaseStep)
+	ret.profiles = profiles
+	return ret
+}
+
 func (s *leaseStep) Inputs() (api.InputDefinition, error) {
 	return s.wrapped.Inputs()
 }
@@ -65,6 +80,7 @@ func (s *leaseStep) Description() string                 { return s.wrapped.Desc
 func (s *leaseStep) Requires() []api.StepLink            { return s.wrapped.Requires() }
 func (s *leaseStep) Creates() []api.StepLink             { return s.wrapped.Creates() }
 func (s *leaseStep) Objects() []ctrlruntimeclient.Object { return s.wrapped.Objects() }
+func (s *leaseStep) unwrap() api.Step                    { return s.wrapped }
 
 func (s *leaseStep) Provides() api.ParameterMap {
 	parameters := s.wrapped.Provides()
@@ -93,6 +109,11 @@ func (s *leaseStep) Provides() api.ParameterMap {
 			return builder.String(), nil
 		}
 	}
+	if len(s.profiles) != 0 {
+		parameters[ClusterProfileNameEnv] = func() (string, error) {
+			return string(s.profile), nil
+		}
+	}
 	return parameters
 }
 
@@ -111,6 +132,10 @@ func (s *leaseStep) run(ctx context.Context) error {
 	logrus.Infof("Acquiring leases for test %s", s.Name())
 	client := *s.client
 	ctx, cancel := context.WithCancel(ctx)
+	if len(s.profiles) != 0 {
+		s.profile = selectClusterProfile(client, s.profiles)
+		s.leases[0].ResourceType = s.profile.LeaseType()
+	}
 	if err := acquireLeases(client, ctx, cancel, s.leases); err != nil {
 		return err
 	}
@@ -169,6 +194,27 @@ func acquireLeases(
 	return utilerrors.NewAggregate(errs)
 }
 
+// selectClusterProfile picks the first of the profiles with free capacity. The
+// preferred profile is waited for when none of them has any.
+func selectClusterProfile(client lease.Client, profiles []api.ClusterProfile) api.ClusterProfile {
+	for _, p := range profiles {
+		m, err := client.Metrics(p.LeaseType())
+		if err != nil {
+			logrus.WithError(err).Warnf("Could not get the capacity of cluster profile %s.", p)
+			continue
+		}
+		if m.Free > 0 {
+			if p != profiles[0] {
+				logrus.Infof("Falling back to cluster profile %s, %s has no free capacity.", p, profiles[0])
+			}
+			return p
+		}
+		logrus.Debugf("Cluster profile %s has no free capacity.", p)
+	}
+	logrus.Infof("None of the cluster profiles has free capacity, waiting for %s.", profiles[0])
+	return profiles[0]
+}
+
 func releaseLeases(client lease.Client, leases []stepLease) error {
 	var errs []error
 	for _, l := range leases {
@@ -224,3 +224,66 @@ func TestAcquireRelease(t *testing.T) {
 		t.Fatalf("wrong calls to the lease client: %s", diff.ObjectDiff(calls, expected))
 	}
 }
+
+type capacityClient struct {
+	lease.Client
+	free map[string]int
+}
+
+func (c capacityClient) Metrics(rtype string) (lease.Metrics, error) {
+	free, ok := c.free[rtype]
+	if !ok {
+		return lease.Metrics{}, errors.New("injected failure")
+	}
+	return lease.Metrics{Free: free}, nil
+}
+
+func TestClusterProfileFallback(t *testing.T) {
+	profiles := []api.ClusterProfile{api.ClusterProfileAWS, api.ClusterProfileGCP, api.ClusterProfileAzure4}
+	for _, tc := range []struct {
+		name     string
+		free     map[string]int
+		expected api.ClusterProfile
+	}{{
+		name:     "preferred profile has capacity",
+		free:     map[string]int{"aws-quota-slice": 1, "gcp-quota-slice": 1, "azure4-quota-slice": 1},
+		expected: api.ClusterProfileAWS,
+	}, {
+		name:     "first profile with capacity is chosen",
+		free:     map[string]int{"aws-quota-slice": 0, "gcp-quota-slice": 0, "azure4-quota-slice": 1},
+		expected: api.ClusterProfileAzure4,
+	}, {
+		name:     "profiles whose capacity is unknown are skipped",
+		free:     map[string]int{"gcp-quota-slice": 1},
+		expected: api.ClusterProfileGCP,
+	}, {
+		name:     "preferred profile is waited for without capacity",
+		free:     map[string]int{"aws-quota-slice": 0, "gcp-quota-slice": 0, "azure4-quota-slice": 0},
+		expected: api.ClusterProfileAWS,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			var calls []string
+			var client lease.Client = capacityClient{Client: lease.NewFakeClient("owner", "url", 0, nil, &calls), free: tc.free}
+			leases := []api.StepLease{
+				{ResourceType: "aws-quota-slice", Env: DefaultLeaseEnv, Count: 1},
+				{ResourceType: "rtype", Env: "OTHER", Count: 1},
+			}
+			step := stepNeedsLease{}
+			withLease := ClusterProfileLeaseStep(&client, profiles, leases, &step, emptyNamespace)
+			if err := withLease.Run(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			profile, err := withLease.Provides()[ClusterProfileNameEnv]()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if profile != string(tc.expected) {
+				t.Errorf("expected profile %s, got %s", tc.expected, profile)
+			}
+			acquire := "acquire owner " + tc.expected.LeaseType() + " free leased random"
+			if !sets.NewString(calls...).Has(acquire) {
+				t.Errorf("lease for %s was not acquired, calls: %v", tc.expected, calls)
+			}
+		})
+	}
+}
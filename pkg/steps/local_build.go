@@ -0,0 +1,357 @@
+package steps
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift/builder/pkg/build/builder/util/dockerfile"
+	dockercmd "github.com/openshift/imagebuilder/dockerfile/command"
+	"github.com/openshift/imagebuilder/dockerfile/parser"
+
+	buildapi "github.com/openshift/api/build/v1"
+	imagev1 "github.com/openshift/api/image/v1"
+
+	"github.com/openshift/ci-tools/pkg/secrets"
+	"github.com/openshift/ci-tools/pkg/steps/loggingclient"
+)
+
+// commandRunner runs a command and returns its combined output
+type commandRunner func(ctx context.Context, name string, args ...string) ([]byte, error)
+
+func runCommand(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return exec.CommandContext(ctx, name, args...).CombinedOutput()
+}
+
+// localBuildClient runs builds with podman on the local machine instead of
+// creating them on the cluster. Inputs are pulled from and outputs pushed to
+// the image streams of the cluster through the public route of its registry,
+// so the user must be logged into that registry. Builds only live in memory;
+// all other objects are handled by the cluster.
+type localBuildClient struct {
+	loggingclient.LoggingClient
+	run    commandRunner
+	censor secrets.Censorer
+
+	lock   sync.Mutex
+	builds map[ctrlruntimeclient.ObjectKey]*localBuild
+}
+
+type localBuild struct {
+	build *buildapi.Build
+	log   []byte
+}
+
+// NewLocalBuildClient returns a client that runs builds with podman.
+func NewLocalBuildClient(client loggingclient.LoggingClient, censor secrets.Censorer) BuildClient {
+	return &localBuildClient{
+		LoggingClient: client,
+		run:           runCommand,
+		censor:        censor,
+		builds:        map[ctrlruntimeclient.ObjectKey]*localBuild{},
+	}
+}
+
+func (c *localBuildClient) Create(ctx context.Context, obj ctrlruntimeclient.Object, opts ...ctrlruntimeclient.CreateOption) error {
+	build, ok := obj.(*buildapi.Build)
+	if !ok {
+		return c.LoggingClient.Create(ctx, obj, opts...)
+	}
+	key := ctrlruntimeclient.ObjectKeyFromObject(build)
+	c.lock.Lock()
+	_, exists := c.builds[key]
+	c.lock.Unlock()
+	if exists {
+		return kerrors.NewAlreadyExists(buildapi.Resource("builds"), build.Name)
+	}
+
+	logrus.Infof("Building %s locally", build.Name)
+	start := metav1.Now()
+	build.Status.StartTimestamp = &start
+	log, err := c.build(ctx, build)
+	finished := metav1.Now()
+	build.Status.CompletionTimestamp = &finished
+	build.Status.Phase = buildapi.BuildPhaseComplete
+	if err != nil {
+		build.Status.Phase = buildapi.BuildPhaseFailed
+		build.Status.Reason = buildapi.StatusReasonGenericBuildFailed
+		build.Status.Message = err.Error()
+	}
+	c.lock.Lock()
+	c.builds[key] = &localBuild{build: build.DeepCopy(), log: log}
+	c.lock.Unlock()
+	return nil
+}
+
+func (c *localBuildClient) Get(ctx context.Context, key ctrlruntimeclient.ObjectKey, obj ctrlruntimeclient.Object) error {
+	build, ok := obj.(*buildapi.Build)
+	if !ok {
+		return c.LoggingClient.Get(ctx, key, obj)
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	local, exists := c.builds[key]
+	if !exists {
+		return kerrors.NewNotFound(buildapi.Resource("builds"), key.Name)
+	}
+	local.build.DeepCopyInto(build)
+	return nil
+}
+
+func (c *localBuildClient) Delete(ctx context.Context, obj ctrlruntimeclient.Object, opts ...ctrlruntimeclient.DeleteOption) error {
+	if _, ok := obj.(*buildapi.Build); !ok {
+		return c.LoggingClient.Delete(ctx, obj, opts...)
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.builds, ctrlruntimeclient.ObjectKeyFromObject(obj))
+	return nil
+}
+
+func (c *localBuildClient) Logs(namespace, name string, _ *buildapi.BuildLogOptions) (io.ReadCloser, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	local, exists := c.builds[ctrlruntimeclient.ObjectKey{Namespace: namespace, Name: name}]
+	if !exists {
+		return nil, kerrors.NewNotFound(buildapi.Resource("builds"), name)
+	}
+	log := make([]byte, len(local.log))
+	copy(log, local.log)
+	c.censor.Censor(&log)
+	return ioutil.NopCloser(bytes.NewReader(log)), nil
+}
+
+// build reproduces what the Docker strategy of OpenShift builds does: the
+// paths of the input images are copied into the context directory, the
+// images in the Dockerfile are replaced and the result is pushed to the
+// output image stream tag.
+func (c *localBuildClient) build(ctx context.Context, build *buildapi.Build) ([]byte, error) {
+	log := &bytes.Buffer{}
+	podman := func(args ...string) error {
+		out, err := c.run(ctx, "podman", args...)
+		log.Write(out)
+		if err != nil {
+			return fmt.Errorf("podman %s failed: %w", args[0], err)
+		}
+		return nil
+	}
+
+	source, strategy := build.Spec.Source, build.Spec.Strategy.DockerStrategy
+	switch {
+	case strategy == nil:
+		return nil, errors.New("only builds with the Docker strategy can run locally")
+	case source.Git != nil || source.Binary != nil:
+		return nil, errors.New("builds from Git or binary sources cannot run locally")
+	case len(source.Secrets) > 0 || len(source.ConfigMaps) > 0:
+		return nil, errors.New("builds that mount secrets or config maps cannot run locally")
+	}
+
+	dir, err := ioutil.TempDir("", build.Name)
+	if err != nil {
+		return nil, fmt.Errorf("could not create the build directory: %w", err)
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			logrus.WithError(err).Warnf("Failed to remove the build directory %s.", dir)
+		}
+	}()
+	contextDir := filepath.Join(dir, "context")
+
+	replacements := map[string]string{}
+	for i, image := range source.Images {
+		pullSpec, err := pullSpecFor(ctx, c.LoggingClient, build.Namespace, image.From, true)
+		if err != nil {
+			return log.Bytes(), err
+		}
+		for _, as := range image.As {
+			replacements[as] = pullSpec
+		}
+		if len(image.Paths) == 0 {
+			continue
+		}
+		container := fmt.Sprintf("%s-%s-input-%d", build.Namespace, build.Name, i)
+		if err := podman("create", "--name", container, pullSpec); err != nil {
+			return log.Bytes(), err
+		}
+		for _, path := range image.Paths {
+			destination := filepath.Join(contextDir, path.DestinationDir)
+			if err := os.MkdirAll(destination, 0755); err != nil {
+				return log.Bytes(), fmt.Errorf("could not create %s: %w", destination, err)
+			}
+			if err := podman("cp", fmt.Sprintf("%s:%s", container, path.SourcePath), destination); err != nil {
+				_ = podman("rm", "--force", container)
+				return log.Bytes(), err
+			}
+		}
+		if err := podman("rm", "--force", container); err != nil {
+			return log.Bytes(), err
+		}
+	}
+	if err := os.MkdirAll(contextDir, 0755); err != nil {
+		return log.Bytes(), fmt.Errorf("could not create the context directory: %w", err)
+	}
+	contextDir = filepath.Join(contextDir, source.ContextDir)
+
+	var raw []byte
+	if source.Dockerfile != nil {
+		raw = []byte(*source.Dockerfile)
+	} else {
+		path := strategy.DockerfilePath
+		if path == "" {
+			path = "Dockerfile"
+		}
+		if raw, err = ioutil.ReadFile(filepath.Join(contextDir, path)); err != nil {
+			return log.Bytes(), fmt.Errorf("could not read the Dockerfile: %w", err)
+		}
+	}
+	if strategy.From != nil {
+		if replacements[""], err = pullSpecFor(ctx, c.LoggingClient, build.Namespace, *strategy.From, true); err != nil {
+			return log.Bytes(), err
+		}
+	}
+	raw, err = rewriteDockerfile(raw, replacements, strategy.Env)
+	if err != nil {
+		return log.Bytes(), err
+	}
+	dockerfilePath := filepath.Join(dir, "Dockerfile")
+	if err := ioutil.WriteFile(dockerfilePath, raw, 0644); err != nil {
+		return log.Bytes(), fmt.Errorf("could not write the Dockerfile: %w", err)
+	}
+
+	if build.Spec.Output.To == nil {
+		return log.Bytes(), errors.New("the build has no output")
+	}
+	target, err := pullSpecFor(ctx, c.LoggingClient, build.Namespace, *build.Spec.Output.To, true)
+	if err != nil {
+		return log.Bytes(), err
+	}
+	args := []string{"build", "--file", dockerfilePath, "--tag", target}
+	if strategy.NoCache {
+		args = append(args, "--no-cache")
+	}
+	if strategy.ForcePull {
+		args = append(args, "--pull-always")
+	}
+	for _, arg := range strategy.BuildArgs {
+		args = append(args, "--build-arg", fmt.Sprintf("%s=%s", arg.Name, arg.Value))
+	}
+	for _, label := range build.Spec.Output.ImageLabels {
+		args = append(args, "--label", fmt.Sprintf("%s=%s", label.Name, label.Value))
+	}
+	if err := podman(append(args, contextDir)...); err != nil {
+		return log.Bytes(), err
+	}
+	if err := podman("push", target); err != nil {
+		return log.Bytes(), err
+	}
+	return log.Bytes(), nil
+}
+
+// pullSpecFor resolves the reference to an image in the cluster to the pull
+// spec in the registry of the cluster or, for public specs, in its public
+// route.
+func pullSpecFor(ctx context.Context, client ctrlruntimeclient.Client, namespace string, ref corev1.ObjectReference, public bool) (string, error) {
+	if ref.Namespace != "" {
+		namespace = ref.Namespace
+	}
+	var name, suffix string
+	switch ref.Kind {
+	case "DockerImage":
+		return ref.Name, nil
+	case "ImageStreamTag":
+		parts := strings.SplitN(ref.Name, ":", 2)
+		if len(parts) != 2 {
+			return "", fmt.Errorf("invalid image stream tag %q", ref.Name)
+		}
+		name, suffix = parts[0], ":"+parts[1]
+	case "ImageStreamImage":
+		parts := strings.SplitN(ref.Name, "@", 2)
+		if len(parts) != 2 {
+			return "", fmt.Errorf("invalid image stream image %q", ref.Name)
+		}
+		name, suffix = parts[0], "@"+parts[1]
+	default:
+		return "", fmt.Errorf("references to %s cannot be resolved", ref.Kind)
+	}
+	stream := &imagev1.ImageStream{}
+	if err := client.Get(ctx, ctrlruntimeclient.ObjectKey{Namespace: namespace, Name: name}, stream); err != nil {
+		return "", fmt.Errorf("could not get image stream %s/%s: %w", namespace, name, err)
+	}
+	if !public {
+		if stream.Status.DockerImageRepository == "" {
+			return "", fmt.Errorf("image stream %s/%s has no repository in the registry", namespace, name)
+		}
+		return stream.Status.DockerImageRepository + suffix, nil
+	}
+	if stream.Status.PublicDockerImageRepository == "" {
+		return "", fmt.Errorf("image stream %s/%s is not exposed by a public route of the registry", namespace, name)
+	}
+	return stream.Status.PublicDockerImageRepository + suffix, nil
+}
+
+// rewriteDockerfile replaces the images the Dockerfile uses like the Docker
+// strategy of OpenShift builds does. The image for the empty name replaces
+// the base image of the last stage, the environment is added to it.
+func rewriteDockerfile(raw []byte, replacements map[string]string, env []corev1.EnvVar) ([]byte, error) {
+	node, err := dockerfile.Parse(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("could not parse the Dockerfile: %w", err)
+	}
+	lastFrom := -1
+	for i, child := range node.Children {
+		switch child.Value {
+		case dockercmd.From:
+			lastFrom = i
+			if child.Next != nil {
+				if replacement, ok := replacements[child.Next.Value]; ok {
+					child.Next.Value = replacement
+				}
+			}
+		case dockercmd.Copy:
+			for j, flag := range child.Flags {
+				if replacement, ok := replacements[strings.TrimPrefix(flag, "--from=")]; ok && strings.HasPrefix(flag, "--from=") {
+					child.Flags[j] = "--from=" + replacement
+				}
+			}
+		}
+	}
+	if lastFrom == -1 {
+		return nil, errors.New("the Dockerfile has no FROM instruction")
+	}
+	if replacement, ok := replacements[""]; ok {
+		from := node.Children[lastFrom]
+		if from.Next == nil {
+			from.Next = &parser.Node{}
+		}
+		from.Next.Value = replacement
+	}
+	if len(env) > 0 {
+		var values []dockerfile.KeyValue
+		for _, e := range env {
+			values = append(values, dockerfile.KeyValue{Key: e.Name, Value: e.Value})
+		}
+		instruction, err := dockerfile.Env(values)
+		if err != nil {
+			return nil, fmt.Errorf("could not create the environment of the build: %w", err)
+		}
+		if err := dockerfile.InsertInstructions(node, lastFrom+1, instruction); err != nil {
+			return nil, fmt.Errorf("could not add the environment of the build: %w", err)
+		}
+	}
+	return dockerfile.Write(node), nil
+}
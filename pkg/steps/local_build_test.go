@@ -0,0 +1,159 @@
+package steps
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	buildapi "github.com/openshift/api/build/v1"
+	imagev1 "github.com/openshift/api/image/v1"
+
+	"github.com/openshift/ci-tools/pkg/steps/loggingclient"
+)
+
+func TestRewriteDockerfile(t *testing.T) {
+	var testCases = []struct {
+		name         string
+		dockerfile   string
+		replacements map[string]string
+		env          []corev1.EnvVar
+		expected     string
+	}{
+		{
+			name:         "images are replaced by their aliases",
+			dockerfile:   "FROM builder AS build\nRUN make\nFROM base\nCOPY --from=tools /bin/tool /bin/tool\nCOPY --from=build /bin/app /bin/app\n",
+			replacements: map[string]string{"builder": "registry/ci/pipeline:root", "tools": "registry/ci/pipeline:tools"},
+			expected:     "FROM registry/ci/pipeline:root AS build\nRUN make\nFROM base\nCOPY --from=registry/ci/pipeline:tools /bin/tool /bin/tool\nCOPY --from=build /bin/app /bin/app\n",
+		},
+		{
+			name:         "base image of the last stage is replaced and the environment added",
+			dockerfile:   "FROM builder AS build\nRUN make\nFROM base\nRUN install\n",
+			replacements: map[string]string{"": "registry/ci/pipeline:base"},
+			env:          []corev1.EnvVar{{Name: "OPT", Value: "value"}},
+			expected:     "FROM builder AS build\nRUN make\nFROM registry/ci/pipeline:base\nENV \"OPT\"=\"value\"\nRUN install\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := rewriteDockerfile([]byte(tc.dockerfile), tc.replacements, tc.env)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, string(actual)); diff != "" {
+				t.Errorf("unexpected Dockerfile: %s", diff)
+			}
+		})
+	}
+}
+
+func TestLocalBuildClient(t *testing.T) {
+	pipeline := &imagev1.ImageStream{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ci-op", Name: "pipeline"},
+		Status:     imagev1.ImageStreamStatus{PublicDockerImageRepository: "registry.crc.testing/ci-op/pipeline"},
+	}
+	dockerfile := "FROM root\nRUN make\n"
+	build := &buildapi.Build{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ci-op", Name: "bin"},
+		Spec: buildapi.BuildSpec{CommonSpec: buildapi.CommonSpec{
+			Source: buildapi.BuildSource{
+				Dockerfile: &dockerfile,
+				Images: []buildapi.ImageSource{{
+					From:  corev1.ObjectReference{Kind: "ImageStreamTag", Name: "pipeline:src"},
+					Paths: []buildapi.ImageSourcePath{{SourcePath: "/go/src/repo/.", DestinationDir: "."}},
+				}},
+			},
+			Strategy: buildapi.BuildStrategy{DockerStrategy: &buildapi.DockerBuildStrategy{
+				From:      &corev1.ObjectReference{Kind: "ImageStreamTag", Namespace: "ci-op", Name: "pipeline:root"},
+				NoCache:   true,
+				BuildArgs: []corev1.EnvVar{{Name: "ARG", Value: "value"}},
+			}},
+			Output: buildapi.BuildOutput{To: &corev1.ObjectReference{Kind: "ImageStreamTag", Namespace: "ci-op", Name: "pipeline:bin"}},
+		}},
+	}
+
+	var testCases = []struct {
+		name          string
+		failingCmd    string
+		expectedCmds  []string
+		expectedPhase buildapi.BuildPhase
+	}{
+		{
+			name: "build runs with podman and is pushed",
+			expectedCmds: []string{
+				"create --name ci-op-bin-input-0 registry.crc.testing/ci-op/pipeline:src",
+				"cp ci-op-bin-input-0:/go/src/repo/. CONTEXT",
+				"rm --force ci-op-bin-input-0",
+				"build --file DOCKERFILE --tag registry.crc.testing/ci-op/pipeline:bin --no-cache --build-arg ARG=value CONTEXT",
+				"push registry.crc.testing/ci-op/pipeline:bin",
+			},
+			expectedPhase: buildapi.BuildPhaseComplete,
+		},
+		{
+			name:       "failed build is not pushed",
+			failingCmd: "build",
+			expectedCmds: []string{
+				"create --name ci-op-bin-input-0 registry.crc.testing/ci-op/pipeline:src",
+				"cp ci-op-bin-input-0:/go/src/repo/. CONTEXT",
+				"rm --force ci-op-bin-input-0",
+				"build --file DOCKERFILE --tag registry.crc.testing/ci-op/pipeline:bin --no-cache --build-arg ARG=value CONTEXT",
+			},
+			expectedPhase: buildapi.BuildPhaseFailed,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var cmds []string
+			var rewritten string
+			client := &localBuildClient{
+				LoggingClient: loggingclient.New(fakectrlruntimeclient.NewClientBuilder().WithObjects(pipeline.DeepCopy()).Build()),
+				builds:        map[ctrlruntimeclient.ObjectKey]*localBuild{},
+				run: func(_ context.Context, name string, args ...string) ([]byte, error) {
+					if name != "podman" {
+						t.Errorf("expected podman to run, got %s", name)
+					}
+					if args[0] == "build" {
+						raw, err := ioutil.ReadFile(args[2])
+						if err != nil {
+							t.Errorf("could not read the Dockerfile: %v", err)
+						}
+						rewritten = string(raw)
+						args[2], args[len(args)-1] = "DOCKERFILE", "CONTEXT"
+					}
+					if args[0] == "cp" {
+						args[2] = "CONTEXT"
+					}
+					cmds = append(cmds, strings.Join(args, " "))
+					if args[0] == tc.failingCmd {
+						return []byte("oopsie"), fmt.Errorf("exit status 1")
+					}
+					return nil, nil
+				},
+			}
+			if err := client.Create(context.Background(), build.DeepCopy()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expectedCmds, cmds); diff != "" {
+				t.Errorf("unexpected commands: %s", diff)
+			}
+			if diff := cmp.Diff("FROM registry.crc.testing/ci-op/pipeline:root\nRUN make\n", rewritten); diff != "" {
+				t.Errorf("unexpected Dockerfile: %s", diff)
+			}
+			created := &buildapi.Build{}
+			if err := client.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: "ci-op", Name: "bin"}, created); err != nil {
+				t.Fatalf("unexpected error getting the build: %v", err)
+			}
+			if created.Status.Phase != tc.expectedPhase {
+				t.Errorf("expected phase %s, got %s", tc.expectedPhase, created.Status.Phase)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package steps
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/results"
+)
+
+// StepMetrics records how long every step took, how often it retried and
+// whether it succeeded, labelled by the name and the type of the step, so
+// that the runtime of jobs can be analyzed across the fleet. It also keeps
+// the individual results of the steps, for sinks that analyze them.
+type StepMetrics struct {
+	registry *prometheus.Registry
+	duration *prometheus.HistogramVec
+	retries  *prometheus.CounterVec
+
+	lock    sync.Mutex
+	results []results.StepResult
+}
+
+// NewStepMetrics returns an empty set of step metrics.
+func NewStepMetrics() *StepMetrics {
+	m := &StepMetrics{
+		registry: prometheus.NewRegistry(),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "ci_operator_step_duration_seconds",
+			Help:    "How long steps took to run, by step name, type and outcome.",
+			Buckets: prometheus.ExponentialBuckets(1, 2, 16),
+		}, []string{"step", "type", "outcome"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "ci_operator_step_retries_total",
+			Help: "How often steps retried an operation, by step name and type.",
+		}, []string{"step", "type"}),
+	}
+	m.registry.MustRegister(m.duration, m.retries)
+	return m
+}
+
+// observe records a finished step. Recording on nil metrics is a no-op.
+func (m *StepMetrics) observe(step api.Step, start time.Time, duration time.Duration, retries int64, err error) {
+	if m == nil {
+		return
+	}
+	outcome := "succeeded"
+	if err != nil {
+		outcome = "failed"
+	}
+	name, kind := step.Name(), stepType(step)
+	m.duration.WithLabelValues(name, kind, outcome).Observe(duration.Seconds())
+	m.retries.WithLabelValues(name, kind).Add(float64(retries))
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.results = append(m.results, results.ForStep(name, kind, start, duration, err))
+}
+
+// Results returns the results of the observed steps in the order they started.
+func (m *StepMetrics) Results() []results.StepResult {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	ret := make([]results.StepResult, len(m.results))
+	copy(ret, m.results)
+	sort.SliceStable(ret, func(i, j int) bool { return ret[i].StartedAt.Before(ret[j].StartedAt) })
+	return ret
+}
+
+// stepWrapper is implemented by steps that add behavior to another step
+type stepWrapper interface {
+	unwrap() api.Step
+}
+
+// stepType is the name of the implementation of the step, like
+// "projectDirectoryImageBuildStep". Wrappers report the step they wrap.
+func stepType(step api.Step) string {
+	for {
+		wrapper, ok := step.(stepWrapper)
+		if !ok {
+			break
+		}
+		step = wrapper.unwrap()
+	}
+	kind := fmt.Sprintf("%T", step)
+	return kind[strings.LastIndex(kind, ".")+1:]
+}
+
+// Push adds the metrics to the group the job and grouping labels identify on the
+// aggregating Pushgateway at the address, which sums them up with the metrics
+// that were pushed to the group before.
+func (m *StepMetrics) Push(ctx context.Context, address, job string, grouping map[string]string) error {
+	families, err := m.registry.Gather()
+	if err != nil {
+		return fmt.Errorf("could not gather the step metrics: %w", err)
+	}
+	body := &bytes.Buffer{}
+	encoder := expfmt.NewEncoder(body, expfmt.FmtText)
+	for _, family := range families {
+		if err := encoder.Encode(family); err != nil {
+			return fmt.Errorf("could not encode the step metrics: %w", err)
+		}
+	}
+
+	target := strings.TrimSuffix(address, "/") + "/metrics/job/" + url.PathEscape(job)
+	for _, label := range sets.StringKeySet(grouping).List() {
+		target += "/" + label + "/" + url.PathEscape(grouping[label])
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, target, body)
+	if err != nil {
+		return fmt.Errorf("could not create the request to the Pushgateway: %w", err)
+	}
+	request.Header.Set("Content-Type", string(expfmt.FmtText))
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return fmt.Errorf("could not push the step metrics: %w", err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusAccepted {
+		return fmt.Errorf("could not push the step metrics: the Pushgateway responded with %s", response.Status)
+	}
+	return nil
+}
+
+type retryCounterKey struct{}
+
+// withRetryCounter returns a context steps record their retries in.
+func withRetryCounter(ctx context.Context) (context.Context, *int64) {
+	var retries int64
+	return context.WithValue(ctx, retryCounterKey{}, &retries), &retries
+}
+
+// RecordRetry counts a retry of an operation towards the step that runs with
+// the context.
+func RecordRetry(ctx context.Context) {
+	if retries, ok := ctx.Value(retryCounterKey{}).(*int64); ok {
+		atomic.AddInt64(retries, 1)
+	}
+}
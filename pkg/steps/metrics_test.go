@@ -0,0 +1,96 @@
+package steps
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/openshift/ci-tools/pkg/api"
+)
+
+// retryingStep records the given number of retries before it succeeds
+type retryingStep struct {
+	fakeStep
+	retries int
+}
+
+func (s *retryingStep) Run(ctx context.Context) error {
+	for i := 0; i < s.retries; i++ {
+		RecordRetry(ctx)
+	}
+	return nil
+}
+
+func TestStepMetrics(t *testing.T) {
+	metrics := NewStepMetrics()
+	graph := api.BuildGraph([]api.Step{
+		&retryingStep{fakeStep: fakeStep{name: "flaky"}, retries: 2},
+		&fakeStep{name: "broken", runErr: errors.New("oopsie")},
+	})
+	if _, _, errs := Run(context.Background(), graph, metrics); len(errs) != 1 {
+		t.Fatalf("expected one step to fail, got %v", errs)
+	}
+	states := map[string]string{}
+	for _, result := range metrics.Results() {
+		states[result.Step] = fmt.Sprintf("%s/%s/%s", result.StepType, result.State, result.Reason)
+	}
+	if diff := cmp.Diff(map[string]string{"flaky": "retryingStep/succeeded/", "broken": "fakeStep/failed/unknown"}, states); diff != "" {
+		t.Errorf("unexpected step results: %s", diff)
+	}
+
+	var path, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("expected a POST, got %s", r.Method)
+		}
+		raw, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("could not read the body: %v", err)
+		}
+		path, body = r.URL.EscapedPath(), string(raw)
+	}))
+	defer server.Close()
+
+	if err := metrics.Push(context.Background(), server.URL+"/", "ci-operator", map[string]string{"prow_job": "pull-ci-org-repo-master-e2e", "build": "1/2"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff("/metrics/job/ci-operator/build/1%2F2/prow_job/pull-ci-org-repo-master-e2e", path); diff != "" {
+		t.Errorf("unexpected path: %s", diff)
+	}
+	for _, line := range []string{
+		`ci_operator_step_duration_seconds_count{outcome="succeeded",step="flaky",type="retryingStep"} 1`,
+		`ci_operator_step_duration_seconds_count{outcome="failed",step="broken",type="fakeStep"} 1`,
+		`ci_operator_step_retries_total{step="flaky",type="retryingStep"} 2`,
+		`ci_operator_step_retries_total{step="broken",type="fakeStep"} 0`,
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("expected the pushed metrics to contain %q, got:\n%s", line, body)
+		}
+	}
+}
+
+func TestStepMetricsPushFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	err := NewStepMetrics().Push(context.Background(), server.URL, "ci-operator", nil)
+	if diff := cmp.Diff("could not push the step metrics: the Pushgateway responded with 400 Bad Request", errString(err)); diff != "" {
+		t.Errorf("unexpected error: %s", diff)
+	}
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
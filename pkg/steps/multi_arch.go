@@ -0,0 +1,127 @@
+package steps
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
+
+	coreapi "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	buildapi "github.com/openshift/api/build/v1"
+	imagev1 "github.com/openshift/api/image/v1"
+
+	"github.com/openshift/ci-tools/pkg/api"
+)
+
+const (
+	// manifestListServiceAccount is allowed to push to the pipeline image stream,
+	// as it is the account builds use to push their output
+	manifestListServiceAccount = "builder"
+	serviceAccountTokenPath    = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+)
+
+// buildArchitectures runs one build per configured architecture on nodes of that
+// architecture and assembles the results into a manifest list tagged as `to`
+func (s *projectDirectoryImageBuildStep) buildArchitectures(ctx context.Context, source buildapi.BuildSource, fromDigest string) error {
+	g, gctx := errgroup.WithContext(ctx)
+	for _, architecture := range s.config.Architectures {
+		tag := api.ArchitectureTag(s.config.To, architecture)
+		build := buildFromSource(
+			s.jobSpec, s.config.From, tag,
+			source,
+			fromDigest,
+			s.config.DockerfilePath,
+			architectureResources(s.resources, s.config.To, architecture),
+			s.pullSecret,
+			s.config.BuildArgs,
+		)
+		build.Spec.NodeSelector = buildapi.OptionalNodeSelector{coreapi.LabelArchStable: string(architecture)}
+		g.Go(func() error {
+			return handleBuild(gctx, s.client, build)
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return err
+	}
+
+	if s.podClient == nil {
+		return fmt.Errorf("cannot assemble the manifest list for %s with a nil pod client", s.config.To)
+	}
+	pipeline := &imagev1.ImageStream{}
+	if err := s.podClient.Get(ctx, ctrlruntimeclient.ObjectKey{Namespace: s.jobSpec.Namespace(), Name: api.PipelineImageStream}, pipeline); err != nil {
+		return fmt.Errorf("could not resolve pipeline imagestream: %w", err)
+	}
+	repository := pipeline.Status.PublicDockerImageRepository
+	if repository == "" {
+		repository = pipeline.Status.DockerImageRepository
+	}
+	if repository == "" {
+		return fmt.Errorf("pipeline imagestream has no accessible image registry value")
+	}
+	logrus.Infof("Assembling the manifest list for %s from %d architectures", s.config.To, len(s.config.Architectures))
+	if _, err := RunPod(ctx, s.podClient, manifestListPod(s.config, repository, s.jobSpec)); err != nil {
+		return fmt.Errorf("failed to assemble the manifest list for %s: %w", s.config.To, err)
+	}
+	return nil
+}
+
+// architectureResources returns the resources for the build of a single architecture,
+// which are the resources of the image overridden by those for the architecture
+func architectureResources(resources api.ResourceConfiguration, to api.PipelineImageStreamTagReference, architecture api.ReleaseArchitecture) api.ResourceConfiguration {
+	tag := string(api.ArchitectureTag(to, architecture))
+	requirements := api.ResourceRequirements{Requests: api.ResourceList{}, Limits: api.ResourceList{}}
+	for _, name := range []string{string(to), tag} {
+		if values, ok := resources[name]; ok {
+			values = values.For("", architecture)
+			requirements.Requests.Add(values.Requests)
+			requirements.Limits.Add(values.Limits)
+		}
+	}
+	ret := api.ResourceConfiguration{tag: requirements}
+	if defaults, ok := resources["*"]; ok {
+		ret["*"] = defaults.For("", architecture)
+	}
+	return ret
+}
+
+// manifestListPod pushes a manifest list that references the images built for all
+// architectures into the pipeline image stream
+func manifestListPod(config api.ProjectDirectoryImageBuildStepConfiguration, repository string, jobSpec *api.JobSpec) *coreapi.Pod {
+	var platforms []string
+	for _, architecture := range config.Architectures {
+		platforms = append(platforms, fmt.Sprintf("linux/%s", architecture))
+	}
+	// manifest-tool replaces ARCH in the template with the architecture of every platform
+	command := fmt.Sprintf(`manifest-tool --username=unused --password="$(cat %s)" push from-args --platforms %s --template %s:%s --target %s:%s`,
+		serviceAccountTokenPath, strings.Join(platforms, ","), repository, api.ArchitectureTag(config.To, "ARCH"), repository, config.To)
+	pod := &coreapi.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-manifest-list", config.To),
+			Namespace: jobSpec.Namespace(),
+			Labels:    labelsFor(jobSpec, map[string]string{CreatesLabel: string(config.To)}),
+			Annotations: map[string]string{
+				JobSpecAnnotation: jobSpec.RawSpec(),
+			},
+		},
+		Spec: coreapi.PodSpec{
+			RestartPolicy:      coreapi.RestartPolicyNever,
+			ServiceAccountName: manifestListServiceAccount,
+			Containers: []coreapi.Container{{
+				Name:                     "manifest-list",
+				Image:                    fmt.Sprintf("%s/ci/manifest-tool:latest", api.DomainForService(api.ServiceRegistry)),
+				Command:                  []string{"/bin/sh", "-c"},
+				Args:                     []string{command},
+				TerminationMessagePolicy: coreapi.TerminationMessageFallbackToLogsOnError,
+			}},
+		},
+	}
+	if owner := jobSpec.Owner(); owner != nil {
+		pod.OwnerReferences = append(pod.OwnerReferences, *owner)
+	}
+	return pod
+}
@@ -0,0 +1,105 @@
+package steps
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	prowapi "k8s.io/test-infra/prow/apis/prowjobs/v1"
+	"k8s.io/test-infra/prow/pod-utils/downwardapi"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/testhelper"
+)
+
+func TestArchitectureResources(t *testing.T) {
+	var testCases = []struct {
+		name      string
+		resources api.ResourceConfiguration
+		expected  api.ResourceConfiguration
+	}{
+		{
+			name:      "no resources",
+			resources: api.ResourceConfiguration{},
+			expected:  api.ResourceConfiguration{"src-arm64": {Requests: api.ResourceList{}, Limits: api.ResourceList{}}},
+		},
+		{
+			name: "defaults are kept",
+			resources: api.ResourceConfiguration{
+				"*":     {Requests: api.ResourceList{"cpu": "100m"}},
+				"other": {Requests: api.ResourceList{"cpu": "3"}},
+			},
+			expected: api.ResourceConfiguration{
+				"*":         {Requests: api.ResourceList{"cpu": "100m"}},
+				"src-arm64": {Requests: api.ResourceList{}, Limits: api.ResourceList{}},
+			},
+		},
+		{
+			name: "resources for the image apply to all architectures",
+			resources: api.ResourceConfiguration{
+				"src": {Requests: api.ResourceList{"cpu": "2"}, Limits: api.ResourceList{"memory": "4Gi"}},
+			},
+			expected: api.ResourceConfiguration{
+				"src-arm64": {Requests: api.ResourceList{"cpu": "2"}, Limits: api.ResourceList{"memory": "4Gi"}},
+			},
+		},
+		{
+			name: "resources for the architecture override those for the image",
+			resources: api.ResourceConfiguration{
+				"src":       {Requests: api.ResourceList{"cpu": "2", "memory": "1Gi"}},
+				"src-arm64": {Requests: api.ResourceList{"cpu": "4"}},
+				"src-s390x": {Requests: api.ResourceList{"cpu": "8"}},
+			},
+			expected: api.ResourceConfiguration{
+				"src-arm64": {Requests: api.ResourceList{"cpu": "4", "memory": "1Gi"}, Limits: api.ResourceList{}},
+			},
+		},
+		{
+			name: "architecture overrides apply to the image and the defaults",
+			resources: api.ResourceConfiguration{
+				"*": {Requests: api.ResourceList{"cpu": "100m"}, Architectures: map[api.ReleaseArchitecture]api.ResourceOverride{
+					api.ReleaseArchitectureARM64: {Requests: api.ResourceList{"cpu": "200m"}},
+				}},
+				"src": {Requests: api.ResourceList{"cpu": "2", "memory": "1Gi"}, Architectures: map[api.ReleaseArchitecture]api.ResourceOverride{
+					api.ReleaseArchitectureARM64: {Requests: api.ResourceList{"memory": "2Gi"}},
+					api.ReleaseArchitectureS390x: {Requests: api.ResourceList{"memory": "8Gi"}},
+				}},
+				"src-arm64": {Requests: api.ResourceList{"cpu": "4"}},
+			},
+			expected: api.ResourceConfiguration{
+				"*":         {Requests: api.ResourceList{"cpu": "200m"}, Limits: api.ResourceList{}},
+				"src-arm64": {Requests: api.ResourceList{"cpu": "4", "memory": "2Gi"}, Limits: api.ResourceList{}},
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			actual := architectureResources(testCase.resources, "src", api.ReleaseArchitectureARM64)
+			if diff := cmp.Diff(testCase.expected, actual); diff != "" {
+				t.Errorf("resources differ from expected: %s", diff)
+			}
+		})
+	}
+}
+
+func TestManifestListPod(t *testing.T) {
+	jobSpec := &api.JobSpec{
+		JobSpec: downwardapi.JobSpec{
+			Job:       "job",
+			BuildID:   "buildId",
+			ProwJobID: "prowJobId",
+			Refs: &prowapi.Refs{
+				Org:     "org",
+				Repo:    "repo",
+				BaseRef: "master",
+				BaseSHA: "masterSHA",
+			},
+		},
+	}
+	jobSpec.SetNamespace("namespace")
+	config := api.ProjectDirectoryImageBuildStepConfiguration{
+		To:            "component",
+		Architectures: []api.ReleaseArchitecture{api.ReleaseArchitectureAMD64, api.ReleaseArchitectureARM64},
+	}
+	testhelper.CompareWithFixture(t, manifestListPod(config, "registry.build01.ci.openshift.org/namespace/pipeline", jobSpec))
+}
@@ -43,6 +43,10 @@ const (
 	SecretMountEnv = "SHARED_DIR"
 	// ClusterProfileMountEnv is the env we use to expose the cluster profile dir
 	ClusterProfileMountEnv = "CLUSTER_PROFILE_DIR"
+	// ClusterProfileNameEnv is the env we use to expose the name of the
+	// cluster profile, which is only known when the leases are acquired if
+	// the test can fall back to other profiles
+	ClusterProfileNameEnv = "CLUSTER_PROFILE_NAME"
 	// CliMountPath is where we mount the cli in a pod
 	CliMountPath = "/cli"
 	// CliEnv if the env we use to expose the path to the cli
@@ -51,6 +55,10 @@ const (
 	CommandPrefix = "#!/bin/bash\nset -eu\n"
 	// CommandScriptMountPath is where we mount the command script
 	CommandScriptMountPath = "/var/run/configmaps/ci.openshift.io/multi-stage"
+	// InitDirMountPath is where we mount the dir the init containers of a step prepare for it
+	InitDirMountPath = "/var/run/ci.openshift.io/init"
+	// InitDirEnv is the env we use to expose the init dir
+	InitDirEnv = "INIT_DIR"
 )
 
 var envForProfile = []string{
@@ -61,7 +69,11 @@ var envForProfile = []string{
 type multiStageTestStep struct {
 	name    string
 	profile api.ClusterProfile
-	config  *api.ReleaseBuildConfiguration
+	// fallbackProfiles are the profiles the lease step can choose instead
+	// of the configured profile, which is kept in defaultProfile
+	fallbackProfiles []api.ClusterProfile
+	defaultProfile   api.ClusterProfile
+	config           *api.ReleaseBuildConfiguration
 	// params exposes getters for variables created by other steps
 	params                   api.Parameters
 	env                      api.TestEnvironment
@@ -74,6 +86,10 @@ type multiStageTestStep struct {
 	allowBestEffortPostSteps *bool
 	leases                   []api.StepLease
 	clusterClaim             *api.ClusterClaim
+	// nodeArchitectures are the architectures of the nodes of the
+	// build cluster. It is only populated if a step is restricted
+	// to some architectures.
+	nodeArchitectures sets.String
 }
 
 func MultiStageTestStep(
@@ -96,9 +112,16 @@ func newMultiStageTestStep(
 	leases []api.StepLease,
 ) *multiStageTestStep {
 	ms := testConfig.MultiStageTestConfigurationLiteral
+	post := ms.Post
+	if ms.Gather != nil {
+		// gather before any post step can tear the cluster down
+		post = append([]api.LiteralTestStep{gatherStep(*ms.Gather)}, ms.Post...)
+	}
 	return &multiStageTestStep{
 		name:                     testConfig.As,
 		profile:                  ms.ClusterProfile,
+		fallbackProfiles:         ms.FallbackClusterProfiles,
+		defaultProfile:           ms.ClusterProfile,
 		config:                   config,
 		params:                   params,
 		env:                      ms.Environment,
@@ -106,7 +129,7 @@ func newMultiStageTestStep(
 		jobSpec:                  jobSpec,
 		pre:                      ms.Pre,
 		test:                     ms.Test,
-		post:                     ms.Post,
+		post:                     post,
 		allowSkipOnSuccess:       ms.AllowSkipOnSuccess,
 		allowBestEffortPostSteps: ms.AllowBestEffortPostSteps,
 		leases:                   leases,
@@ -115,6 +138,9 @@ func newMultiStageTestStep(
 }
 
 func (s *multiStageTestStep) profileSecretName() string {
+	if s.profile != s.defaultProfile {
+		return fmt.Sprintf("%s-cluster-profile-%s", s.name, s.profile)
+	}
 	return s.name + "-cluster-profile"
 }
 
@@ -130,6 +156,15 @@ func (s *multiStageTestStep) Run(ctx context.Context) error {
 
 func (s *multiStageTestStep) run(ctx context.Context) error {
 	logrus.Infof("Running multi-stage test %s", s.name)
+	if len(s.fallbackProfiles) != 0 {
+		profile, err := s.params.Get(ClusterProfileNameEnv)
+		if err != nil {
+			return fmt.Errorf("could not determine the cluster profile: %w", err)
+		}
+		if profile != "" {
+			s.profile = api.ClusterProfile(profile)
+		}
+	}
 	env, err := s.environment(ctx)
 	if err != nil {
 		return err
@@ -150,10 +185,21 @@ func (s *multiStageTestStep) run(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	if s.hasArchitectureSpecificSteps() {
+		architectures, err := nodeArchitectures(ctx, s.client)
+		if err != nil {
+			// Skipping steps that could have run is worse than failing them
+			logrus.WithError(err).Warn("Failed to determine the node architectures of the cluster, running all steps.")
+		} else if architectures.Len() != 0 {
+			s.nodeArchitectures = architectures
+		}
+	}
 	var errs []error
 	if err := s.runSteps(ctx, s.pre, env, true, false, secretVolumes, secretVolumeMounts); err != nil {
 		errs = append(errs, fmt.Errorf("%q pre steps failed: %w", s.name, err))
-	} else if err := s.runSteps(ctx, s.test, env, true, len(errs) != 0, secretVolumes, secretVolumeMounts); err != nil {
+	}
+	// test steps only run after failed pre steps when they ask for it
+	if err := s.runSteps(ctx, s.test, env, true, len(errs) != 0, secretVolumes, secretVolumeMounts); err != nil {
 		errs = append(errs, fmt.Errorf("%q test steps failed: %w", s.name, err))
 	}
 	if err := s.runSteps(context.Background(), s.post, env, false, len(errs) != 0, secretVolumes, secretVolumeMounts); err != nil {
@@ -203,6 +249,15 @@ func (s *multiStageTestStep) Requires() (ret []api.StepLink) {
 			imageStream, name, _ := s.config.DependencyParts(dependency)
 			ret = append(ret, api.LinkForImage(imageStream, name))
 		}
+
+		for _, initContainer := range step.InitContainers {
+			imageStream, name, explicit := s.config.DependencyParts(api.StepDependency{Name: initContainer.From})
+			if explicit {
+				ret = append(ret, api.LinkForImage(imageStream, name))
+			} else {
+				needsReleaseImage = true
+			}
+		}
 	}
 	if s.profile != "" {
 		needsReleasePayload = true
@@ -368,8 +423,12 @@ func (s *multiStageTestStep) runSteps(
 	if err != nil {
 		return err
 	}
+	stepsByPod := map[string]api.LiteralTestStep{}
+	for _, step := range steps {
+		stepsByPod[fmt.Sprintf("%s-%s", s.name, step.As)] = step
+	}
 	var errs []error
-	if err := s.runPods(ctx, pods, shortCircuit, isBestEffort); err != nil {
+	if err := s.runPods(ctx, pods, shortCircuit, hasPrevErrs, isBestEffort, stepsByPod); err != nil {
 		errs = append(errs, err)
 	}
 	select {
@@ -405,10 +464,55 @@ func (s *multiStageTestStep) runSteps(
 
 const multiStageTestStepContainerName = "test"
 
+func (s *multiStageTestStep) hasArchitectureSpecificSteps() bool {
+	for _, steps := range [][]api.LiteralTestStep{s.pre, s.test, s.post} {
+		for _, step := range steps {
+			if len(step.Architectures) != 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// architectureSkipReason returns why the step can not run on the build
+// cluster, if the cluster has no nodes of any of its architectures
+func (s *multiStageTestStep) architectureSkipReason(step api.LiteralTestStep) string {
+	if len(step.Architectures) == 0 || s.nodeArchitectures == nil {
+		return ""
+	}
+	var architectures []string
+	for _, architecture := range step.Architectures {
+		if s.nodeArchitectures.Has(string(architecture)) {
+			return ""
+		}
+		architectures = append(architectures, string(architecture))
+	}
+	return fmt.Sprintf("step requires one of the architectures %s, but the cluster only has nodes of the architectures %s", strings.Join(architectures, ", "), strings.Join(s.nodeArchitectures.List(), ", "))
+}
+
+// nodeArchitectures returns the architectures of the nodes of the cluster
+func nodeArchitectures(ctx context.Context, client ctrlruntimeclient.Client) (sets.String, error) {
+	nodes := &coreapi.NodeList{}
+	if err := client.List(ctx, nodes); err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	architectures := sets.NewString()
+	for _, node := range nodes.Items {
+		if architecture, ok := node.Labels[coreapi.LabelArchStable]; ok {
+			architectures.Insert(architecture)
+		}
+	}
+	return architectures, nil
+}
+
 func (s *multiStageTestStep) generatePods(steps []api.LiteralTestStep, env []coreapi.EnvVar,
 	hasPrevErrs bool, secretVolumes []coreapi.Volume, secretVolumeMounts []coreapi.VolumeMount) ([]coreapi.Pod, func(string) bool, error) {
-	bestEffort := sets.NewString()
+	bestEffort, optional := sets.NewString(), sets.NewString()
 	isBestEffort := func(podName string) bool {
+		if optional.Has(podName) {
+			return true
+		}
 		if s.allowBestEffortPostSteps == nil || !*s.allowBestEffortPostSteps {
 			// the user has not requested best-effort steps or they've explicitly disabled them
 			return false
@@ -425,6 +529,14 @@ func (s *multiStageTestStep) generatePods(steps []api.LiteralTestStep, env []cor
 			logrus.Infof(fmt.Sprintf("Skipping optional step %s", name))
 			continue
 		}
+		if reason := s.architectureSkipReason(step); reason != "" {
+			logrus.Infof("Skipping step %s: %s", name, reason)
+			s.subTests = append(s.subTests, &junit.TestCase{
+				Name:        fmt.Sprintf("%s - %s", s.Description(), name),
+				SkipMessage: &junit.SkipMessage{Message: reason},
+			})
+			continue
+		}
 		image := step.From
 		if link, ok := step.FromImageTag(); ok {
 			image = fmt.Sprintf("%s:%s", api.PipelineImageStream, link)
@@ -433,7 +545,7 @@ func (s *multiStageTestStep) generatePods(steps []api.LiteralTestStep, env []cor
 			stream, tag, _ := s.config.DependencyParts(dep)
 			image = fmt.Sprintf("%s:%s", stream, tag)
 		}
-		resources, err := resourcesFor(step.Resources)
+		resources, err := resourcesFor(step.Resources.For(s.profile, ""))
 		if err != nil {
 			errs = append(errs, err)
 			continue
@@ -441,6 +553,9 @@ func (s *multiStageTestStep) generatePods(steps []api.LiteralTestStep, env []cor
 		if step.BestEffort != nil && *step.BestEffort {
 			bestEffort.Insert(name)
 		}
+		if step.Optional != nil && *step.Optional {
+			optional.Insert(name)
+		}
 		p := func(i int64) *int64 {
 			return &i
 		}
@@ -535,6 +650,9 @@ func (s *multiStageTestStep) generatePods(steps []api.LiteralTestStep, env []cor
 		if step.Cli != "" {
 			addCliInjector(step.Cli, pod)
 		}
+		if len(step.InitContainers) > 0 {
+			s.addInitContainers(step.InitContainers, resources, pod)
+		}
 		addSharedDirSecret(s.name, pod)
 		addCredentials(step.Credentials, pod)
 		if step.RunAsScript != nil && *step.RunAsScript {
@@ -583,12 +701,23 @@ func getMountPath(secretName string) string {
 	return path.Join("/secrets", secretName)
 }
 
+// dependencyParameters resolves the dependencies of the step, including
+// images from releases, to the pull specs of their digests.
+func (s *multiStageTestStep) dependencyParameters(step api.LiteralTestStep) api.ParameterMap {
+	params := api.ParameterMap{}
+	for _, dependency := range step.Dependencies {
+		imageStream, name, _ := s.config.DependencyParts(dependency)
+		params[dependency.Env] = utils.ImageDigestFor(s.client, s.jobSpec.Namespace, imageStream, name)
+	}
+	return params
+}
+
 func (s *multiStageTestStep) envForDependencies(step api.LiteralTestStep) ([]coreapi.EnvVar, []error) {
 	var env []coreapi.EnvVar
 	var errs []error
+	params := s.dependencyParameters(step)
 	for _, dependency := range step.Dependencies {
-		imageStream, name, _ := s.config.DependencyParts(dependency)
-		ref, err := utils.ImageDigestFor(s.client, s.jobSpec.Namespace, imageStream, name)()
+		ref, err := params[dependency.Env]()
 		if err != nil {
 			errs = append(errs, fmt.Errorf("could not determine image pull spec for image %s on step %s", dependency.Name, step.As))
 			continue
@@ -657,9 +786,27 @@ func addSharedDirSecret(secret string, pod *coreapi.Pod) {
 	})
 }
 
+// addCredentials mounts the credentials into the step and exposes the requested
+// keys as environment variables. The values need no extra handling to be censored:
+// ci-operator records every secret it copies into the test namespace and the
+// sidecar censors all secrets of the namespace from the artifacts.
 func addCredentials(credentials []api.CredentialReference, pod *coreapi.Pod) {
 	for _, credential := range credentials {
 		name := fmt.Sprintf("%s-%s", credential.Namespace, credential.Name)
+		for _, env := range credential.Env {
+			pod.Spec.Containers[0].Env = append(pod.Spec.Containers[0].Env, coreapi.EnvVar{
+				Name: env.Name,
+				ValueFrom: &coreapi.EnvVarSource{
+					SecretKeyRef: &coreapi.SecretKeySelector{
+						LocalObjectReference: coreapi.LocalObjectReference{Name: name},
+						Key:                  env.Key,
+					},
+				},
+			})
+		}
+		if credential.MountPath == "" {
+			continue
+		}
 		volumeName := volumeName(credential.Namespace, credential.Name)
 		pod.Spec.Volumes = append(pod.Spec.Volumes, coreapi.Volume{
 			Name: volumeName,
@@ -712,6 +859,9 @@ func addProfile(name string, profile api.ClusterProfile, pod *coreapi.Pod) {
 	}, {
 		Name:  ClusterProfileMountEnv,
 		Value: ClusterProfileMountPath,
+	}, {
+		Name:  ClusterProfileNameEnv,
+		Value: string(profile),
 	}}...)
 }
 
@@ -766,30 +916,129 @@ func addCliInjector(release string, pod *coreapi.Pod) {
 	})
 }
 
-func (s *multiStageTestStep) runPods(ctx context.Context, pods []coreapi.Pod, shortCircuit bool, isBestEffort func(string) bool) error {
+// addInitContainers runs the init containers of a step before it. They share a
+// directory with the step and can read, but not modify, the shared dir.
+func (s *multiStageTestStep) addInitContainers(initContainers []api.StepInitContainer, resources coreapi.ResourceRequirements, pod *coreapi.Pod) {
+	volumeName := "init-dir"
+	pod.Spec.Volumes = append(pod.Spec.Volumes, coreapi.Volume{
+		Name: volumeName,
+		VolumeSource: coreapi.VolumeSource{
+			EmptyDir: &coreapi.EmptyDirVolumeSource{},
+		},
+	})
+	mounts := []coreapi.VolumeMount{
+		{Name: volumeName, MountPath: InitDirMountPath},
+		{Name: s.name, MountPath: SecretMountPath, ReadOnly: true},
+	}
+	env := []coreapi.EnvVar{
+		{Name: InitDirEnv, Value: InitDirMountPath},
+		{Name: SecretMountEnv, Value: SecretMountPath},
+		{Name: "NAMESPACE", Value: s.jobSpec.Namespace()},
+	}
+	for _, initContainer := range initContainers {
+		stream, tag, _ := s.config.DependencyParts(api.StepDependency{Name: initContainer.From})
+		pod.Spec.InitContainers = append(pod.Spec.InitContainers, coreapi.Container{
+			// Prefixed so they can not collide with the containers we add
+			Name:                     "init-" + initContainer.As,
+			Image:                    fmt.Sprintf("%s:%s", stream, tag),
+			Command:                  []string{"/bin/bash", "-c", CommandPrefix + initContainer.Commands},
+			Env:                      env,
+			Resources:                resources,
+			VolumeMounts:             mounts,
+			TerminationMessagePolicy: coreapi.TerminationMessageFallbackToLogsOnError,
+		})
+	}
+	container := &pod.Spec.Containers[0]
+	container.VolumeMounts = append(container.VolumeMounts, coreapi.VolumeMount{
+		Name:      volumeName,
+		MountPath: InitDirMountPath,
+	})
+	container.Env = append(container.Env, coreapi.EnvVar{
+		Name:  InitDirEnv,
+		Value: InitDirMountPath,
+	})
+}
+
+// runPods runs the pods in order. When shortCircuit is set, steps only run after
+// a failure if they ask for it with `run_if`, otherwise they all run by default.
+func (s *multiStageTestStep) runPods(ctx context.Context, pods []coreapi.Pod, shortCircuit, hasPrevErrs bool, isBestEffort func(string) bool, stepsByPod map[string]api.LiteralTestStep) error {
 	var errs []error
+	failed := hasPrevErrs
 	for _, pod := range pods {
-		err := s.runPod(ctx, &pod, NewTestCaseNotifier(NopNotifier))
+		step := stepsByPod[pod.Name]
+		if reason := runIfSkipReason(step.RunIf, shortCircuit, failed); reason != "" {
+			if step.RunIf == "" {
+				// steps after a failure are not reported unless they set a condition
+				continue
+			}
+			logrus.Infof("Skipping step %s: %s", pod.Name, reason)
+			s.subTests = append(s.subTests, &junit.TestCase{
+				Name:        fmt.Sprintf("%s - %s", s.Description(), pod.Name),
+				SkipMessage: &junit.SkipMessage{Message: reason},
+			})
+			continue
+		}
+		err := s.runPodWithRetries(ctx, pod, step.Retries)
 		if err != nil {
 			if isBestEffort(pod.Name) {
 				logrus.Infof("Pod %s is running in best-effort mode, ignoring the failure...", pod.Name)
 				continue
 			}
 			errs = append(errs, err)
-			if shortCircuit {
+			failed = true
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// runIfSkipReason returns why a step does not run, given whether a step
+// before it has failed
+func runIfSkipReason(condition api.StepRunCondition, shortCircuit, failed bool) string {
+	if condition == "" {
+		condition = api.StepRunIfAlways
+		if shortCircuit {
+			condition = api.StepRunIfSuccess
+		}
+	}
+	switch {
+	case condition == api.StepRunIfSuccess && failed:
+		return "a previous step failed"
+	case condition == api.StepRunIfFailure && !failed:
+		return "step only runs when a previous step failed"
+	}
+	return ""
+}
+
+// runPodWithRetries runs the pod again when it fails, as many times as the
+// step allows
+func (s *multiStageTestStep) runPodWithRetries(ctx context.Context, pod coreapi.Pod, retries *int) error {
+	var err error
+	attempts := 1
+	if retries != nil {
+		attempts += *retries
+	}
+	for attempt := 1; attempt <= attempts; attempt++ {
+		// the created pod is updated in place, retries must start from the template
+		if err = s.runPod(ctx, pod.DeepCopy(), NewTestCaseNotifier(NopNotifier)); err == nil {
+			return nil
+		}
+		if attempt < attempts {
+			if ctx.Err() != nil {
 				break
 			}
+			logrus.Infof("Step %s failed, retrying (attempt %d of %d).", pod.Name, attempt+1, attempts)
+			RecordRetry(ctx)
 		}
 	}
-	return utilerrors.NewAggregate(errs)
+	return err
 }
 
 func (s *multiStageTestStep) runPod(ctx context.Context, pod *coreapi.Pod, notifier *TestCaseNotifier) error {
 	start := time.Now()
 	logrus.Infof("Running step %s.", pod.Name)
 	client := s.client.WithNewLoggingClient()
-	if _, err := createOrRestartPod(client, pod); err != nil {
-		return fmt.Errorf("failed to create or restart %s pod: %w", pod.Name, err)
+	if _, err := createOrRestartPod(ctx, client, pod); err != nil {
+		return results.ForReason("creating_pod").WithCategory(results.CategoryInfra).WithResource("Pod", pod.Namespace, pod.Name).WithError(err).Errorf("failed to create or restart %s pod: %v", pod.Name, err)
 	}
 	newPod, err := waitForPodCompletion(ctx, client, pod.Namespace, pod.Name, notifier, false)
 	if newPod != nil {
@@ -826,7 +1075,7 @@ func (s *multiStageTestStep) runPod(ctx context.Context, pod *coreapi.Pod, notif
 				status = fmt.Sprintf("%s activeDeadlineSeconds=%d", status, *pod.Spec.ActiveDeadlineSeconds)
 			}
 		}
-		return fmt.Errorf("%q pod %q %s: %w\n%s", s.name, pod.Name, status, err, linksText.String())
+		return results.ForReason("running_step").WithCategory(results.CategoryTest).WithResource("Pod", pod.Namespace, pod.Name).WithError(err).Errorf("%q pod %q %s: %v\n%s", s.name, pod.Name, status, err, linksText.String())
 	}
 	return nil
 }
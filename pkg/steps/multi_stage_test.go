@@ -14,6 +14,7 @@ import (
 	coreapi "k8s.io/api/core/v1"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/diff"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
@@ -76,6 +77,15 @@ func TestRequires(t *testing.T) {
 			api.InternalImageLink(
 				api.PipelineImageStreamTagReferenceSource),
 		},
+	}, {
+		name: "step needs an image from a release, should have ReleaseImagesLink",
+		steps: api.MultiStageTestConfigurationLiteral{
+			Test: []api.LiteralTestStep{{From: "src", Dependencies: []api.StepDependency{{Name: "installer", Release: api.InitialReleaseName, Env: "INSTALLER"}}}},
+		},
+		req: []api.StepLink{
+			api.InternalImageLink(api.PipelineImageStreamTagReferenceSource),
+			api.ReleaseImagesLink(api.InitialReleaseName),
+		},
 	}, {
 		name: "step needs pipeline image explicitly, should have InternalImageLink",
 		steps: api.MultiStageTestConfigurationLiteral{
@@ -191,6 +201,95 @@ func TestGeneratePods(t *testing.T) {
 	testhelper.CompareWithFixture(t, ret)
 }
 
+func TestGeneratePodsResourcesForClusterProfile(t *testing.T) {
+	config := api.ReleaseBuildConfiguration{
+		Tests: []api.TestStepConfiguration{{
+			As: "test",
+			MultiStageTestConfigurationLiteral: &api.MultiStageTestConfigurationLiteral{
+				ClusterProfile:          api.ClusterProfileAWS,
+				FallbackClusterProfiles: []api.ClusterProfile{api.ClusterProfileGCP},
+				Test: []api.LiteralTestStep{{
+					As: "step0", From: "src", Commands: "command0",
+					Resources: api.ResourceRequirements{
+						Requests: api.ResourceList{"cpu": "100m", "memory": "200Mi"},
+						ClusterProfiles: map[api.ClusterProfile]api.ResourceOverride{
+							api.ClusterProfileAWS: {Requests: api.ResourceList{"memory": "1Gi"}},
+							api.ClusterProfileGCP: {Requests: api.ResourceList{"memory": "2Gi"}},
+						},
+					},
+				}},
+			},
+		}},
+	}
+	jobSpec := api.JobSpec{
+		JobSpec: prowdapi.JobSpec{
+			Job:     "job",
+			BuildID: "build id",
+			Type:    "periodic",
+			DecorationConfig: &prowapi.DecorationConfig{
+				Timeout:       &prowapi.Duration{Duration: time.Minute},
+				GracePeriod:   &prowapi.Duration{Duration: time.Second},
+				UtilityImages: &prowapi.UtilityImages{Sidecar: "sidecar", Entrypoint: "entrypoint"},
+			},
+		},
+	}
+	jobSpec.SetNamespace("namespace")
+	for _, tc := range []struct {
+		profile  api.ClusterProfile
+		expected string
+	}{
+		{profile: api.ClusterProfileAWS, expected: "1Gi"},
+		{profile: api.ClusterProfileGCP, expected: "2Gi"},
+	} {
+		t.Run(string(tc.profile), func(t *testing.T) {
+			step := newMultiStageTestStep(config.Tests[0], &config, nil, nil, &jobSpec, nil)
+			// the profile the lease was acquired for, which may be a fallback
+			step.profile = tc.profile
+			pods, _, err := step.generatePods(config.Tests[0].MultiStageTestConfigurationLiteral.Test, nil, false, nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			requests := pods[0].Spec.Containers[0].Resources.Requests
+			if actual := requests.Memory().String(); actual != tc.expected {
+				t.Errorf("expected the memory request to be %s, got %s", tc.expected, actual)
+			}
+			if actual := requests.Cpu().String(); actual != "100m" {
+				t.Errorf("expected the cpu request to be 100m, got %s", actual)
+			}
+		})
+	}
+}
+
+func TestProfileSecretName(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		profile  api.ClusterProfile
+		expected string
+	}{{
+		name:     "configured profile",
+		profile:  api.ClusterProfileAWS,
+		expected: "test-cluster-profile",
+	}, {
+		name:     "fallback profile",
+		profile:  api.ClusterProfileGCP,
+		expected: "test-cluster-profile-gcp",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			step := newMultiStageTestStep(api.TestStepConfiguration{
+				As: "test",
+				MultiStageTestConfigurationLiteral: &api.MultiStageTestConfigurationLiteral{
+					ClusterProfile:          api.ClusterProfileAWS,
+					FallbackClusterProfiles: []api.ClusterProfile{api.ClusterProfileGCP},
+				},
+			}, &api.ReleaseBuildConfiguration{}, nil, nil, nil, nil)
+			step.profile = tc.profile
+			if actual := step.profileSecretName(); actual != tc.expected {
+				t.Errorf("expected secret %q, got %q", tc.expected, actual)
+			}
+		})
+	}
+}
+
 func TestGeneratePodsEnvironment(t *testing.T) {
 	value := "test"
 	defValue := "default"
@@ -342,10 +441,22 @@ func TestGeneratePodBestEffort(t *testing.T) {
 
 type fakePodExecutor struct {
 	loggingclient.LoggingClient
-	failures    sets.String
+	failures sets.String
+	// flakes are how many times the pods fail before they succeed
+	flakes      map[string]int
 	createdPods []*coreapi.Pod
 }
 
+func (f *fakePodExecutor) attempts(name string) int {
+	var attempts int
+	for _, pod := range f.createdPods {
+		if pod.Name == name {
+			attempts++
+		}
+	}
+	return attempts
+}
+
 func (f *fakePodExecutor) Create(ctx context.Context, o ctrlruntimeclient.Object, opts ...ctrlruntimeclient.CreateOption) error {
 	if pod, ok := o.(*coreapi.Pod); ok {
 		if pod.Namespace == "" {
@@ -362,7 +473,7 @@ func (f *fakePodExecutor) Get(ctx context.Context, n ctrlruntimeclient.ObjectKey
 		return err
 	}
 	if pod, ok := o.(*coreapi.Pod); ok {
-		fail := f.failures.Has(n.Name)
+		fail := f.failures.Has(n.Name) || f.attempts(n.Name) <= f.flakes[n.Name]
 		if fail {
 			pod.Status.Phase = coreapi.PodFailed
 		} else {
@@ -476,6 +587,101 @@ func TestRun(t *testing.T) {
 	}
 }
 
+func TestRunConditions(t *testing.T) {
+	yes, one, two := true, 1, 2
+	for _, tc := range []struct {
+		name            string
+		failures        sets.String
+		flakes          map[string]int
+		pre, test       []api.LiteralTestStep
+		post            []api.LiteralTestStep
+		expected        []string
+		expectedRetries int64
+		expectedError   bool
+	}{{
+		name:     "steps that run on failure are skipped without failures",
+		pre:      []api.LiteralTestStep{{As: "pre0"}, {As: "pre1", RunIf: api.StepRunIfFailure}},
+		test:     []api.LiteralTestStep{{As: "test0"}},
+		post:     []api.LiteralTestStep{{As: "post0", RunIf: api.StepRunIfFailure}, {As: "post1"}},
+		expected: []string{"test-pre0", "test-test0", "test-post1"},
+	}, {
+		name:          "steps that run on failure or always run after a failure",
+		failures:      sets.NewString("test-pre0"),
+		pre:           []api.LiteralTestStep{{As: "pre0"}, {As: "pre1"}, {As: "pre2", RunIf: api.StepRunIfFailure}},
+		test:          []api.LiteralTestStep{{As: "test0"}, {As: "test1", RunIf: api.StepRunIfAlways}},
+		post:          []api.LiteralTestStep{{As: "post0", RunIf: api.StepRunIfSuccess}, {As: "post1"}},
+		expected:      []string{"test-pre0", "test-pre2", "test-test1", "test-post1"},
+		expectedError: true,
+	}, {
+		name:     "failures of optional steps are ignored",
+		failures: sets.NewString("test-pre0"),
+		pre:      []api.LiteralTestStep{{As: "pre0", Optional: &yes}, {As: "pre1"}},
+		test:     []api.LiteralTestStep{{As: "test0"}},
+		post:     []api.LiteralTestStep{{As: "post0", RunIf: api.StepRunIfFailure}},
+		expected: []string{"test-pre0", "test-pre1", "test-test0"},
+	}, {
+		name:            "steps are retried until they succeed",
+		flakes:          map[string]int{"test-pre0": 2},
+		pre:             []api.LiteralTestStep{{As: "pre0", Retries: &two}},
+		test:            []api.LiteralTestStep{{As: "test0"}},
+		expected:        []string{"test-pre0", "test-pre0", "test-pre0", "test-test0"},
+		expectedRetries: 2,
+	}, {
+		name:            "steps fail when they run out of retries",
+		flakes:          map[string]int{"test-pre0": 2},
+		pre:             []api.LiteralTestStep{{As: "pre0", Retries: &one}},
+		test:            []api.LiteralTestStep{{As: "test0"}},
+		post:            []api.LiteralTestStep{{As: "post0"}},
+		expected:        []string{"test-pre0", "test-pre0", "test-post0"},
+		expectedRetries: 1,
+		expectedError:   true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			sa := &coreapi.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "ns", Labels: map[string]string{"ci.openshift.io/multi-stage-test": "test"}}}
+			crclient := &fakePodExecutor{LoggingClient: loggingclient.New(fakectrlruntimeclient.NewFakeClient(sa.DeepCopyObject())), failures: tc.failures, flakes: tc.flakes}
+			jobSpec := api.JobSpec{
+				JobSpec: prowdapi.JobSpec{
+					Job:       "job",
+					BuildID:   "build_id",
+					ProwJobID: "prow_job_id",
+					Type:      prowapi.PeriodicJob,
+					DecorationConfig: &prowapi.DecorationConfig{
+						Timeout:     &prowapi.Duration{Duration: time.Minute},
+						GracePeriod: &prowapi.Duration{Duration: time.Second},
+						UtilityImages: &prowapi.UtilityImages{
+							Sidecar:    "sidecar",
+							Entrypoint: "entrypoint",
+						},
+					},
+				},
+			}
+			jobSpec.SetNamespace("ns")
+			step := MultiStageTestStep(api.TestStepConfiguration{
+				As: "test",
+				MultiStageTestConfigurationLiteral: &api.MultiStageTestConfigurationLiteral{
+					Pre:  tc.pre,
+					Test: tc.test,
+					Post: tc.post,
+				},
+			}, &api.ReleaseBuildConfiguration{}, nil, &fakePodClient{fakePodExecutor: crclient}, &jobSpec, nil)
+			ctx, retries := withRetryCounter(context.Background())
+			if err := step.Run(ctx); (err != nil) != tc.expectedError {
+				t.Errorf("expected error: %t, got error: %v", tc.expectedError, err)
+			}
+			var names []string
+			for _, pod := range crclient.createdPods {
+				names = append(names, pod.Name)
+			}
+			if diff := cmp.Diff(tc.expected, names); diff != "" {
+				t.Errorf("did not execute correct pods: %s", diff)
+			}
+			if *retries != tc.expectedRetries {
+				t.Errorf("expected %d retries to be recorded, got %d", tc.expectedRetries, *retries)
+			}
+		})
+	}
+}
+
 func TestJUnit(t *testing.T) {
 	for _, tc := range []struct {
 		name     string
@@ -565,6 +771,78 @@ func TestJUnit(t *testing.T) {
 	}
 }
 
+func TestArchitectureSpecificSteps(t *testing.T) {
+	for _, tc := range []struct {
+		name         string
+		nodes        []ctrlruntimeclient.Object
+		expectedPods []string
+		expectedSkip []string
+	}{{
+		name:         "step for other architecture is skipped",
+		nodes:        []ctrlruntimeclient.Object{&coreapi.Node{ObjectMeta: metav1.ObjectMeta{Name: "node", Labels: map[string]string{coreapi.LabelArchStable: "amd64"}}}},
+		expectedPods: []string{"test-amd64", "test-any"},
+		expectedSkip: []string{"step requires one of the architectures s390x, ppc64le, but the cluster only has nodes of the architectures amd64"},
+	}, {
+		name:         "all steps run on mixed cluster",
+		nodes:        []ctrlruntimeclient.Object{&coreapi.Node{ObjectMeta: metav1.ObjectMeta{Name: "node", Labels: map[string]string{coreapi.LabelArchStable: "amd64"}}}, &coreapi.Node{ObjectMeta: metav1.ObjectMeta{Name: "other-node", Labels: map[string]string{coreapi.LabelArchStable: "ppc64le"}}}},
+		expectedPods: []string{"test-amd64", "test-power-or-z", "test-any"},
+	}, {
+		name:         "all steps run when node architectures are unknown",
+		expectedPods: []string{"test-amd64", "test-power-or-z", "test-any"},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			sa := &coreapi.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "test-namespace", Labels: map[string]string{"ci.openshift.io/multi-stage-test": "test"}}}
+			client := &fakePodExecutor{LoggingClient: loggingclient.New(fakectrlruntimeclient.NewClientBuilder().WithObjects(append(tc.nodes, sa)...).Build())}
+			jobSpec := api.JobSpec{
+				JobSpec: prowdapi.JobSpec{
+					Job:       "job",
+					BuildID:   "build_id",
+					ProwJobID: "prow_job_id",
+					Type:      prowapi.PeriodicJob,
+					DecorationConfig: &prowapi.DecorationConfig{
+						Timeout:     &prowapi.Duration{Duration: time.Minute},
+						GracePeriod: &prowapi.Duration{Duration: time.Second},
+						UtilityImages: &prowapi.UtilityImages{
+							Sidecar:    "sidecar",
+							Entrypoint: "entrypoint",
+						},
+					},
+				},
+			}
+			jobSpec.SetNamespace("test-namespace")
+			step := MultiStageTestStep(api.TestStepConfiguration{
+				As: "test",
+				MultiStageTestConfigurationLiteral: &api.MultiStageTestConfigurationLiteral{
+					Test: []api.LiteralTestStep{
+						{As: "amd64", Architectures: []api.ReleaseArchitecture{api.ReleaseArchitectureAMD64}},
+						{As: "power-or-z", Architectures: []api.ReleaseArchitecture{api.ReleaseArchitectureS390x, api.ReleaseArchitecturePPC64le}},
+						{As: "any"},
+					},
+				},
+			}, &api.ReleaseBuildConfiguration{}, nil, &fakePodClient{fakePodExecutor: client}, &jobSpec, nil)
+			if err := step.Run(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			var pods []string
+			for _, pod := range client.createdPods {
+				pods = append(pods, pod.Name)
+			}
+			if diff := cmp.Diff(tc.expectedPods, pods); diff != "" {
+				t.Errorf("created pods differ from expected: %s", diff)
+			}
+			var skipped []string
+			for _, test := range step.(subtestReporter).SubTests() {
+				if test.SkipMessage != nil {
+					skipped = append(skipped, test.SkipMessage.Message)
+				}
+			}
+			if diff := cmp.Diff(tc.expectedSkip, skipped); diff != "" {
+				t.Errorf("skipped steps differ from expected: %s", diff)
+			}
+		})
+	}
+}
+
 func TestAddCredentials(t *testing.T) {
 	var testCases = []struct {
 		name        string
@@ -629,6 +907,28 @@ func TestAddCredentials(t *testing.T) {
 				},
 			}},
 		},
+		{
+			name: "keys exposed as environment variables",
+			credentials: []api.CredentialReference{
+				{Namespace: "ns", Name: "name", MountPath: "/tmp", Env: []api.CredentialEnv{{Name: "TOKEN", Key: "token"}}},
+				{Namespace: "other", Name: "name", Env: []api.CredentialEnv{{Name: "USER", Key: "user"}, {Name: "PASSWORD", Key: "password"}}},
+			},
+			pod: coreapi.Pod{Spec: coreapi.PodSpec{
+				Containers: []coreapi.Container{{VolumeMounts: []coreapi.VolumeMount{}}},
+				Volumes:    []coreapi.Volume{},
+			}},
+			expected: coreapi.Pod{Spec: coreapi.PodSpec{
+				Containers: []coreapi.Container{{
+					Env: []coreapi.EnvVar{
+						{Name: "TOKEN", ValueFrom: &coreapi.EnvVarSource{SecretKeyRef: &coreapi.SecretKeySelector{LocalObjectReference: coreapi.LocalObjectReference{Name: "ns-name"}, Key: "token"}}},
+						{Name: "USER", ValueFrom: &coreapi.EnvVarSource{SecretKeyRef: &coreapi.SecretKeySelector{LocalObjectReference: coreapi.LocalObjectReference{Name: "other-name"}, Key: "user"}}},
+						{Name: "PASSWORD", ValueFrom: &coreapi.EnvVarSource{SecretKeyRef: &coreapi.SecretKeySelector{LocalObjectReference: coreapi.LocalObjectReference{Name: "other-name"}, Key: "password"}}},
+					},
+					VolumeMounts: []coreapi.VolumeMount{{Name: "ns-name", MountPath: "/tmp"}},
+				}},
+				Volumes: []coreapi.Volume{{Name: "ns-name", VolumeSource: coreapi.VolumeSource{Secret: &coreapi.SecretVolumeSource{SecretName: "ns-name"}}}},
+			}},
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -641,6 +941,58 @@ func TestAddCredentials(t *testing.T) {
 	}
 }
 
+func TestAddInitContainers(t *testing.T) {
+	jobSpec := api.JobSpec{}
+	jobSpec.SetNamespace("namespace")
+	step := multiStageTestStep{name: "test", config: &api.ReleaseBuildConfiguration{}, jobSpec: &jobSpec}
+	resources := coreapi.ResourceRequirements{Requests: coreapi.ResourceList{"cpu": resource.MustParse("1")}}
+	pod := coreapi.Pod{Spec: coreapi.PodSpec{
+		Containers: []coreapi.Container{{Name: "test"}},
+	}}
+	step.addInitContainers([]api.StepInitContainer{
+		{As: "data", From: "src", Commands: "cp data ${INIT_DIR}"},
+		{As: "release", From: "stable:tools", Commands: "oc version"},
+	}, resources, &pod)
+
+	mounts := []coreapi.VolumeMount{
+		{Name: "init-dir", MountPath: "/var/run/ci.openshift.io/init"},
+		{Name: "test", MountPath: "/var/run/secrets/ci.openshift.io/multi-stage", ReadOnly: true},
+	}
+	env := []coreapi.EnvVar{
+		{Name: "INIT_DIR", Value: "/var/run/ci.openshift.io/init"},
+		{Name: "SHARED_DIR", Value: "/var/run/secrets/ci.openshift.io/multi-stage"},
+		{Name: "NAMESPACE", Value: "namespace"},
+	}
+	expected := coreapi.Pod{Spec: coreapi.PodSpec{
+		InitContainers: []coreapi.Container{{
+			Name:                     "init-data",
+			Image:                    "pipeline:src",
+			Command:                  []string{"/bin/bash", "-c", "#!/bin/bash\nset -eu\ncp data ${INIT_DIR}"},
+			Env:                      env,
+			Resources:                resources,
+			VolumeMounts:             mounts,
+			TerminationMessagePolicy: coreapi.TerminationMessageFallbackToLogsOnError,
+		}, {
+			Name:                     "init-release",
+			Image:                    "stable:tools",
+			Command:                  []string{"/bin/bash", "-c", "#!/bin/bash\nset -eu\noc version"},
+			Env:                      env,
+			Resources:                resources,
+			VolumeMounts:             mounts,
+			TerminationMessagePolicy: coreapi.TerminationMessageFallbackToLogsOnError,
+		}},
+		Containers: []coreapi.Container{{
+			Name:         "test",
+			Env:          []coreapi.EnvVar{{Name: "INIT_DIR", Value: "/var/run/ci.openshift.io/init"}},
+			VolumeMounts: []coreapi.VolumeMount{{Name: "init-dir", MountPath: "/var/run/ci.openshift.io/init"}},
+		}},
+		Volumes: []coreapi.Volume{{Name: "init-dir", VolumeSource: coreapi.VolumeSource{EmptyDir: &coreapi.EmptyDirVolumeSource{}}}},
+	}}
+	if !equality.Semantic.DeepEqual(pod, expected) {
+		t.Errorf("got incorrect Pod: %s", cmp.Diff(pod, expected))
+	}
+}
+
 func TestSecretsForCensoring(t *testing.T) {
 	// this ends up returning based on alphanumeric sort of names, so name things accordingly
 	client := loggingclient.New(
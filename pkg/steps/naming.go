@@ -0,0 +1,58 @@
+package steps
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/openshift/ci-tools/pkg/api"
+)
+
+var (
+	// imageTagRegexp matches valid tags of container images and ImageStreams
+	imageTagRegexp = regexp.MustCompile(`^[\w][\w.-]{0,127}$`)
+	// imageStreamNameRegexp matches the names that the image API accepts for
+	// ImageStreams, as they are used as repository name components
+	imageStreamNameRegexp = regexp.MustCompile(`^[a-z0-9]+(?:[._-][a-z0-9]+)*$`)
+)
+
+// validatePipelineImageStreamTag ensures that the value of the given
+// field can be used as a tag in the pipeline ImageStream.
+func validatePipelineImageStreamTag(field string, tag api.PipelineImageStreamTagReference) error {
+	if tag == "" {
+		return fmt.Errorf("%s: value required but not provided", field)
+	}
+	if !imageTagRegexp.MatchString(string(tag)) {
+		return fmt.Errorf("%s: '%s' is not a valid image tag: it must consist of at most 128 alphanumeric characters, '_', '.' or '-' and must not start with '.' or '-'", field, tag)
+	}
+	return nil
+}
+
+// validateBuildTarget ensures that the value of the given field can be
+// used as a tag in the pipeline ImageStream and as the name of the Build
+// that produces the image for that tag.
+func validateBuildTarget(field string, tag api.PipelineImageStreamTagReference) error {
+	if err := validatePipelineImageStreamTag(field, tag); err != nil {
+		return err
+	}
+	if errs := validation.IsDNS1123Subdomain(string(tag)); len(errs) != 0 {
+		return fmt.Errorf("%s: '%s' is not a valid Kubernetes object name for the build producing the image: %s", field, tag, strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// validateImageStreamTagReference ensures that the given reference names
+// a tag that can exist in the image API.
+func validateImageStreamTagReference(field string, ref api.ImageStreamTagReference) error {
+	if ref.Namespace != "" {
+		if errs := validation.IsDNS1123Label(ref.Namespace); len(errs) != 0 {
+			return fmt.Errorf("%s.namespace: '%s' is not a valid namespace: %s", field, ref.Namespace, strings.Join(errs, ", "))
+		}
+	}
+	if !imageStreamNameRegexp.MatchString(ref.Name) {
+		return fmt.Errorf("%s.name: '%s' is not a valid ImageStream name: it must consist of lower case alphanumeric characters separated by '.', '_' or '-'", field, ref.Name)
+	}
+	return validatePipelineImageStreamTag(field+".tag", api.PipelineImageStreamTagReference(ref.Tag))
+}
@@ -0,0 +1,80 @@
+package steps
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/testhelper"
+)
+
+func TestValidateBuildTarget(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		tag      api.PipelineImageStreamTagReference
+		expected error
+	}{
+		{
+			name: "valid",
+			tag:  "ci-operator.v1",
+		},
+		{
+			name:     "empty",
+			expected: errors.New("to: value required but not provided"),
+		},
+		{
+			name:     "invalid tag",
+			tag:      "-image",
+			expected: errors.New("to: '-image' is not a valid image tag: it must consist of at most 128 alphanumeric characters, '_', '.' or '-' and must not start with '.' or '-'"),
+		},
+		{
+			name:     "valid tag but invalid build name",
+			tag:      "My_Image",
+			expected: errors.New("to: 'My_Image' is not a valid Kubernetes object name for the build producing the image: a lowercase RFC 1123 subdomain must consist of lower case alphanumeric characters, '-' or '.', and must start and end with an alphanumeric character (e.g. 'example.com', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?(\\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*')"),
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if diff := cmp.Diff(testCase.expected, validateBuildTarget("to", testCase.tag), testhelper.EquateErrorMessage); diff != "" {
+				t.Errorf("unexpected error: %s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateImageStreamTagReference(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		ref      api.ImageStreamTagReference
+		expected error
+	}{
+		{
+			name: "valid",
+			ref:  api.ImageStreamTagReference{Namespace: "ocp", Name: "4.9", Tag: "My_Image"},
+		},
+		{
+			name:     "invalid namespace",
+			ref:      api.ImageStreamTagReference{Namespace: "OCP", Name: "4.9", Tag: "cli"},
+			expected: errors.New("to.namespace: 'OCP' is not a valid namespace: a lowercase RFC 1123 label must consist of lower case alphanumeric characters or '-', and must start and end with an alphanumeric character (e.g. 'my-name',  or '123-abc', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?')"),
+		},
+		{
+			name:     "invalid name",
+			ref:      api.ImageStreamTagReference{Name: "stable:latest", Tag: "cli"},
+			expected: errors.New("to.name: 'stable:latest' is not a valid ImageStream name: it must consist of lower case alphanumeric characters separated by '.', '_' or '-'"),
+		},
+		{
+			name:     "missing tag",
+			ref:      api.ImageStreamTagReference{Name: "stable"},
+			expected: errors.New("to.tag: value required but not provided"),
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if diff := cmp.Diff(testCase.expected, validateImageStreamTagReference("to", testCase.ref), testhelper.EquateErrorMessage); diff != "" {
+				t.Errorf("unexpected error: %s", diff)
+			}
+		})
+	}
+}
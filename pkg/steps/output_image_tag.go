@@ -29,13 +29,19 @@ type outputImageTagStep struct {
 	config  api.OutputImageTagStepConfiguration
 	client  loggingclient.LoggingClient
 	jobSpec *api.JobSpec
+	tagger  *OutputImageTagger
 }
 
 func (s *outputImageTagStep) Inputs() (api.InputDefinition, error) {
 	return nil, nil
 }
 
-func (*outputImageTagStep) Validate() error { return nil }
+func (s *outputImageTagStep) Validate() error {
+	if err := validatePipelineImageStreamTag("from", s.config.From); err != nil {
+		return err
+	}
+	return validateImageStreamTagReference("to", s.config.To)
+}
 
 func (s *outputImageTagStep) Run(ctx context.Context) error {
 	return results.ForReason("tagging_output_image").ForError(s.run(ctx))
@@ -56,6 +62,20 @@ func (s *outputImageTagStep) run(ctx context.Context) error {
 		return fmt.Errorf("could not resolve base image: %w", err)
 	}
 	desired := s.imageStreamTag(from.Image.Name)
+	var err error
+	if s.tagger != nil {
+		err = s.tagger.Tag(ctx, desired)
+	} else {
+		err = upsertImageStreamTag(ctx, s.client, desired)
+	}
+	if err != nil {
+		return fmt.Errorf("could not upsert output imagestreamtag: %w", err)
+	}
+	return nil
+}
+
+// upsertImageStreamTag ensures that the ImageStreamTag exists and has the desired tag
+func upsertImageStreamTag(ctx context.Context, client loggingclient.LoggingClient, desired *imagev1.ImageStreamTag) error {
 	ist := &imagev1.ImageStreamTag{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: desired.ObjectMeta.Namespace,
@@ -67,13 +87,14 @@ func (s *outputImageTagStep) run(ctx context.Context) error {
 	// not supposed return a conflict so in theory we should not need it but we do:
 	// > Clayton Coleman  6 hours ago
 	// > i think we may have found a bug in kube, which is exciting
-	if waitErr := wait.ExponentialBackoff(wait.Backoff{Steps: 4, Factor: 2, Duration: time.Second}, func() (bool, error) {
-		_, err := crcontrollerutil.CreateOrPatch(ctx, s.client, ist, func() error {
+	return wait.ExponentialBackoff(wait.Backoff{Steps: 4, Factor: 2, Duration: time.Second}, func() (bool, error) {
+		_, err := crcontrollerutil.CreateOrPatch(ctx, client, ist, func() error {
 			ist.Tag = desired.Tag
 			return nil
 		})
 		switch {
 		case err != nil && errors.IsConflict(err):
+			RecordRetry(ctx)
 			return false, nil
 		case err != nil && errors.IsAlreadyExists(err):
 			return true, nil
@@ -81,11 +102,7 @@ func (s *outputImageTagStep) run(ctx context.Context) error {
 			return false, err
 		}
 		return true, nil
-	}); waitErr != nil {
-		return fmt.Errorf("could not upsert output imagestreamtag: %w", waitErr)
-	}
-
-	return nil
+	})
 }
 
 func (s *outputImageTagStep) Requires() []api.StepLink {
@@ -164,10 +181,13 @@ func (s *outputImageTagStep) imageStreamTag(fromImage string) *imagev1.ImageStre
 	}
 }
 
-func OutputImageTagStep(config api.OutputImageTagStepConfiguration, client loggingclient.LoggingClient, jobSpec *api.JobSpec) api.Step {
+// OutputImageTagStep tags a pipeline image into an output ImageStream. If a
+// tagger is passed, the update is batched with those of other steps.
+func OutputImageTagStep(config api.OutputImageTagStepConfiguration, client loggingclient.LoggingClient, jobSpec *api.JobSpec, tagger *OutputImageTagger) api.Step {
 	return &outputImageTagStep{
 		config:  config,
 		client:  client,
 		jobSpec: jobSpec,
+		tagger:  tagger,
 	}
 }
@@ -131,7 +131,7 @@ func TestOutputImageStep(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			client := loggingclient.New(fakectrlruntimeclient.NewFakeClient(tt.input...))
 
-			oits := OutputImageTagStep(config, client, jobspec)
+			oits := OutputImageTagStep(config, client, jobspec, nil)
 
 			examineStep(t, oits, stepSpec)
 			if err := oits.Run(ctx); err != nil != tt.execSpecification.runError {
@@ -0,0 +1,119 @@
+package steps
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/semaphore"
+
+	imagev1 "github.com/openshift/api/image/v1"
+
+	"github.com/openshift/ci-tools/pkg/steps/loggingclient"
+)
+
+const (
+	// DefaultOutputImageTagParallelism is the default number of ImageStreamTags
+	// that are updated at the same time.
+	DefaultOutputImageTagParallelism = 10
+	// outputImageTagBatchWindow is the time tags that are requested for the same
+	// ImageStream are collected before they are updated together
+	outputImageTagBatchWindow = 500 * time.Millisecond
+)
+
+// OutputImageTagger aggregates the ImageStreamTag updates of output image
+// tag steps. Updates that are requested for the same ImageStream around the
+// same time are batched and performed concurrently with bounded parallelism,
+// which speeds up jobs that promote many images.
+type OutputImageTagger struct {
+	client loggingclient.LoggingClient
+	sem    *semaphore.Weighted
+	window time.Duration
+
+	lock    sync.Mutex
+	batches map[string][]*tagRequest
+}
+
+type tagRequest struct {
+	ctx    context.Context
+	ist    *imagev1.ImageStreamTag
+	result chan error
+}
+
+// NewOutputImageTagger returns a tagger that performs at most parallelism
+// updates at the same time.
+func NewOutputImageTagger(client loggingclient.LoggingClient, parallelism int) *OutputImageTagger {
+	if parallelism < 1 {
+		parallelism = DefaultOutputImageTagParallelism
+	}
+	return &OutputImageTagger{
+		client:  client,
+		sem:     semaphore.NewWeighted(int64(parallelism)),
+		window:  outputImageTagBatchWindow,
+		batches: map[string][]*tagRequest{},
+	}
+}
+
+// Tag ensures the desired ImageStreamTag exists and blocks until it does or
+// the update failed.
+func (t *OutputImageTagger) Tag(ctx context.Context, desired *imagev1.ImageStreamTag) error {
+	request := &tagRequest{ctx: ctx, ist: desired, result: make(chan error, 1)}
+	stream := fmt.Sprintf("%s/%s", desired.Namespace, strings.SplitN(desired.Name, ":", 2)[0])
+
+	t.lock.Lock()
+	t.batches[stream] = append(t.batches[stream], request)
+	if len(t.batches[stream]) == 1 {
+		time.AfterFunc(t.window, func() { t.flush(stream) })
+	}
+	t.lock.Unlock()
+
+	select {
+	case err := <-request.result:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (t *OutputImageTagger) flush(stream string) {
+	t.lock.Lock()
+	batch := t.batches[stream]
+	delete(t.batches, stream)
+	t.lock.Unlock()
+
+	logrus.Debugf("Updating %d tags of the image stream %s", len(batch), stream)
+	var wg sync.WaitGroup
+	var failed int
+	var failedLock sync.Mutex
+	for _, request := range batch {
+		wg.Add(1)
+		go func(request *tagRequest) {
+			defer wg.Done()
+			err := t.tag(request)
+			if err != nil {
+				failedLock.Lock()
+				failed++
+				failedLock.Unlock()
+				logrus.WithError(err).Warnf("Failed to tag %s/%s", request.ist.Namespace, request.ist.Name)
+			} else {
+				logrus.Debugf("Tagged %s/%s", request.ist.Namespace, request.ist.Name)
+			}
+			request.result <- err
+		}(request)
+	}
+	wg.Wait()
+	if len(batch) > 1 {
+		logrus.Infof("Updated %d/%d tags of the image stream %s", len(batch)-failed, len(batch), stream)
+	}
+}
+
+func (t *OutputImageTagger) tag(request *tagRequest) error {
+	if err := t.sem.Acquire(request.ctx, 1); err != nil {
+		return err
+	}
+	defer t.sem.Release(1)
+	return upsertImageStreamTag(request.ctx, t.client, request.ist)
+}
@@ -0,0 +1,118 @@
+package steps
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	coreapi "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	imagev1 "github.com/openshift/api/image/v1"
+
+	"github.com/openshift/ci-tools/pkg/steps/loggingclient"
+)
+
+// concurrencyTrackingClient records the highest number of concurrent creations
+// and fails the creation of the given objects
+type concurrencyTrackingClient struct {
+	ctrlruntimeclient.WithWatch
+	fail sets.String
+
+	lock        sync.Mutex
+	inFlight    int
+	maxInFlight int
+}
+
+func (c *concurrencyTrackingClient) Create(ctx context.Context, o ctrlruntimeclient.Object, opts ...ctrlruntimeclient.CreateOption) error {
+	c.lock.Lock()
+	c.inFlight++
+	if c.inFlight > c.maxInFlight {
+		c.maxInFlight = c.inFlight
+	}
+	c.lock.Unlock()
+	defer func() {
+		c.lock.Lock()
+		c.inFlight--
+		c.lock.Unlock()
+	}()
+	time.Sleep(10 * time.Millisecond)
+	if c.fail.Has(o.GetName()) {
+		return errors.New("injected failure")
+	}
+	return c.WithWatch.Create(ctx, o, opts...)
+}
+
+func desiredTag(stream, tag string) *imagev1.ImageStreamTag {
+	return &imagev1.ImageStreamTag{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: fmt.Sprintf("%s:%s", stream, tag)},
+		Tag: &imagev1.TagReference{
+			From: &coreapi.ObjectReference{Kind: "ImageStreamImage", Namespace: "ci-op", Name: "pipeline@sha256:" + tag},
+		},
+	}
+}
+
+func TestOutputImageTagger(t *testing.T) {
+	var desired []*imagev1.ImageStreamTag
+	for i := 0; i < 8; i++ {
+		desired = append(desired, desiredTag("stable", fmt.Sprintf("image-%d", i)))
+	}
+	desired = append(desired, desiredTag("other", "image"))
+	client := &concurrencyTrackingClient{WithWatch: fakectrlruntimeclient.NewFakeClient(), fail: sets.NewString("stable:image-3")}
+	tagger := NewOutputImageTagger(loggingclient.New(client), 3)
+	tagger.window = 10 * time.Millisecond
+
+	errs := make([]error, len(desired))
+	var wg sync.WaitGroup
+	for i := range desired {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = tagger.Tag(context.Background(), desired[i])
+		}(i)
+	}
+	wg.Wait()
+
+	for i, ist := range desired {
+		if ist.Name == "stable:image-3" {
+			if errs[i] == nil {
+				t.Errorf("expected an error for %s", ist.Name)
+			}
+			continue
+		}
+		if errs[i] != nil {
+			t.Errorf("unexpected error for %s: %v", ist.Name, errs[i])
+		}
+		actual := &imagev1.ImageStreamTag{}
+		if err := client.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: ist.Namespace, Name: ist.Name}, actual); err != nil {
+			t.Fatalf("failed to get %s: %v", ist.Name, err)
+		}
+		if diff := cmp.Diff(ist.Tag, actual.Tag); diff != "" {
+			t.Errorf("unexpected tag of %s: %s", ist.Name, diff)
+		}
+	}
+	if client.maxInFlight > 3 {
+		t.Errorf("expected at most 3 concurrent updates, got %d", client.maxInFlight)
+	}
+	if len(tagger.batches) != 0 {
+		t.Errorf("expected all batches to be flushed, got %v", tagger.batches)
+	}
+}
+
+func TestOutputImageTaggerCancelled(t *testing.T) {
+	tagger := NewOutputImageTagger(loggingclient.New(fakectrlruntimeclient.NewFakeClient()), 1)
+	tagger.window = time.Hour
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := tagger.Tag(ctx, desiredTag("stable", "image")); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the context error, got %v", err)
+	}
+}
@@ -32,7 +32,12 @@ func (s *pipelineImageCacheStep) Inputs() (api.InputDefinition, error) {
 	return nil, nil
 }
 
-func (*pipelineImageCacheStep) Validate() error { return nil }
+func (s *pipelineImageCacheStep) Validate() error {
+	if err := validatePipelineImageStreamTag("from", s.config.From); err != nil {
+		return err
+	}
+	return validateBuildTarget("to", s.config.To)
+}
 
 func (s *pipelineImageCacheStep) Run(ctx context.Context) error {
 	return results.ForReason("building_cache_image").ForError(s.run(ctx))
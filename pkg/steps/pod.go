@@ -50,6 +50,12 @@ type PodStepConfiguration struct {
 	ServiceAccountName string
 	Secrets            []*api.Secret
 	MemoryBackedVolume *api.MemoryBackedVolume
+	// Leases are exposed to the pod in their environment variables. The
+	// names of the leased resources are read from the step's parameters.
+	Leases []api.StepLease
+	// ClusterProfile is the profile of the test, its resource overrides
+	// apply to the pod.
+	ClusterProfile api.ClusterProfile
 }
 
 type podStep struct {
@@ -62,6 +68,7 @@ type podStep struct {
 	subTests []*junit.TestCase
 
 	clusterClaim *api.ClusterClaim
+	params       api.Parameters
 }
 
 func (s *podStep) Inputs() (api.InputDefinition, error) {
@@ -78,7 +85,7 @@ func (s *podStep) run(ctx context.Context) error {
 	if !s.config.SkipLogs {
 		logrus.Infof("Executing %s %s", s.name, s.config.As)
 	}
-	containerResources, err := resourcesFor(s.resources.RequirementsForStep(s.config.As))
+	containerResources, err := resourcesFor(s.resources.RequirementsForStepOn(s.config.As, s.config.ClusterProfile, ""))
 	if err != nil {
 		return fmt.Errorf("unable to calculate %s pod resources for %s: %w", s.name, s.config.As, err)
 	}
@@ -106,7 +113,7 @@ func (s *podStep) run(ctx context.Context) error {
 		}
 	}()
 
-	pod, err = createOrRestartPod(s.client, pod)
+	pod, err = createOrRestartPod(ctx, s.client, pod)
 	if err != nil {
 		return fmt.Errorf("failed to create or restart %s pod: %w", s.name, err)
 	}
@@ -152,21 +159,24 @@ func (s *podStep) Objects() []ctrlruntimeclient.Object {
 	return s.client.Objects()
 }
 
-func TestStep(config api.TestStepConfiguration, resources api.ResourceConfiguration, client PodClient, jobSpec *api.JobSpec) api.Step {
-	return PodStep(
-		"test",
-		PodStepConfiguration{
+func TestStep(config api.TestStepConfiguration, resources api.ResourceConfiguration, client PodClient, jobSpec *api.JobSpec, params api.Parameters) api.Step {
+	return &podStep{
+		name: "test",
+		config: PodStepConfiguration{
 			As:                 config.As,
 			From:               api.ImageStreamTagReference{Name: api.PipelineImageStream, Tag: string(config.ContainerTestConfiguration.From)},
 			Commands:           config.Commands,
 			Secrets:            config.Secrets,
 			MemoryBackedVolume: config.ContainerTestConfiguration.MemoryBackedVolume,
+			Leases:             config.Leases,
+			ClusterProfile:     config.GetClusterProfile(),
 		},
-		resources,
-		client,
-		jobSpec,
-		config.ClusterClaim,
-	)
+		resources:    resources,
+		client:       client,
+		jobSpec:      jobSpec,
+		clusterClaim: config.ClusterClaim,
+		params:       params,
+	}
 }
 
 func PodStep(name string, config PodStepConfiguration, resources api.ResourceConfiguration, client PodClient, jobSpec *api.JobSpec, clusterClaim *api.ClusterClaim) api.Step {
@@ -213,7 +223,7 @@ func generateBasePod(
 			Containers: []coreapi.Container{
 				{
 					Image:                    image,
-					Env:                      decorate.KubeEnv(envMap),
+					Env:                      append(decorate.KubeEnv(envMap), resourceHintEnv(containerResources)...),
 					Name:                     containerName,
 					Command:                  command,
 					Resources:                containerResources,
@@ -284,6 +294,13 @@ func (s *podStep) generatePodForStep(image string, containerResources coreapi.Re
 		}...)
 	}
 	pod.Spec.Volumes = append(pod.Spec.Volumes, secretVolumes...)
+	for _, lease := range s.config.Leases {
+		resources, err := s.params.Get(lease.Env)
+		if err != nil {
+			return nil, fmt.Errorf("could not determine the leased %s resources: %w", lease.ResourceType, err)
+		}
+		container.Env = append(container.Env, coreapi.EnvVar{Name: lease.Env, Value: resources})
+	}
 
 	if v := s.config.MemoryBackedVolume; v != nil {
 		size, err := resource.ParseQuantity(v.Size)
@@ -355,7 +372,7 @@ func getSecretVolumeMountFromSecret(secretMountPath string, secretIndex int) []c
 // This pod will not be able to gather artifacts, nor will it report log messages
 // unless it fails.
 func RunPod(ctx context.Context, podClient PodClient, pod *coreapi.Pod) (*coreapi.Pod, error) {
-	pod, err := createOrRestartPod(podClient, pod)
+	pod, err := createOrRestartPod(ctx, podClient, pod)
 	if err != nil {
 		return pod, err
 	}
@@ -69,7 +69,7 @@ func preparePodStep(namespace string) (*podStep, stepExpectation) {
 	}
 	jobSpec.SetNamespace(namespace)
 
-	client := &podClient{loggingclient.New(fakectrlruntimeclient.NewFakeClient()), nil, nil}
+	client := &podClient{loggingclient.New(fakectrlruntimeclient.NewFakeClient()), nil, nil, nil}
 	ps := PodStep(stepName, config, resources, client, jobSpec, nil)
 
 	specification := stepExpectation{
@@ -239,6 +239,23 @@ func TestGetPodObjectMounts(t *testing.T) {
 
 }
 
+func TestGetPodLeases(t *testing.T) {
+	params := api.NewDeferredParameters(nil)
+	params.Add("ACCOUNTS", func() (string, error) { return "aws-1 aws-2", nil })
+	podStepTemplate := expectedPodStepTemplate()
+	podStepTemplate.config.Leases = []api.StepLease{{ResourceType: "aws-quota-slice", Env: "ACCOUNTS", Count: 2}}
+	podStepTemplate.params = params
+
+	pod, err := podStepTemplate.generatePodForStep("", corev1.ResourceRequirements{})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	env := pod.Spec.Containers[0].Env
+	if diff := cmp.Diff(corev1.EnvVar{Name: "ACCOUNTS", Value: "aws-1 aws-2"}, env[len(env)-1]); diff != "" {
+		t.Errorf("leased resources are not exposed: %s", diff)
+	}
+}
+
 func expectedPodStepTemplate() *podStep {
 	s := &podStep{
 		jobSpec: &api.JobSpec{
@@ -286,6 +303,35 @@ func (ps *podStatusChangingClient) Create(ctx context.Context, o ctrlruntimeclie
 	return ps.WithWatch.Create(ctx, o, opts...)
 }
 
+func TestPodStepResourcesForClusterProfile(t *testing.T) {
+	namespace := "TestNamespace"
+	ps, _ := preparePodStep(namespace)
+	ps.client = &podClient{LoggingClient: loggingclient.New(&podStatusChangingClient{WithWatch: fakectrlruntimeclient.NewFakeClient(), dest: corev1.PodSucceeded})}
+	ps.config.ClusterProfile = api.ClusterProfileAWS
+	ps.resources = api.ResourceConfiguration{
+		"*": {
+			Requests: api.ResourceList{"cpu": "100m", "memory": "200Mi"},
+			ClusterProfiles: map[api.ClusterProfile]api.ResourceOverride{
+				api.ClusterProfileAWS: {Requests: api.ResourceList{"memory": "1Gi"}},
+			},
+		},
+	}
+	if err := ps.Run(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	pod := &corev1.Pod{}
+	if err := ps.client.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: namespace, Name: ps.Name()}, pod); err != nil {
+		t.Fatalf("failed to get pod: %v", err)
+	}
+	requests := map[corev1.ResourceName]string{}
+	for name, quantity := range pod.Spec.Containers[0].Resources.Requests {
+		requests[name] = quantity.String()
+	}
+	if diff := cmp.Diff(map[corev1.ResourceName]string{corev1.ResourceCPU: "100m", corev1.ResourceMemory: "1Gi"}, requests); diff != "" {
+		t.Errorf("requests differ from expected: %s", diff)
+	}
+}
+
 func TestTestStepAndRequires(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -303,7 +349,7 @@ func TestTestStepAndRequires(t *testing.T) {
 	}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			actual := TestStep(tc.config, nil, nil, nil).Requires()
+			actual := TestStep(tc.config, nil, nil, nil, nil).Requires()
 			if len(actual) == len(tc.expected) {
 				matches := true
 				for i := range actual {
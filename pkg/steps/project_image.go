@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"path"
+	"strings"
 
 	"github.com/sirupsen/logrus"
 
@@ -26,6 +27,7 @@ type projectDirectoryImageBuildStep struct {
 	releaseBuildConfig *api.ReleaseBuildConfiguration
 	resources          api.ResourceConfiguration
 	client             BuildClient
+	podClient          PodClient
 	secretClient       ctrlruntimeclient.Client
 	jobSpec            *api.JobSpec
 	pullSecret         *coreapi.Secret
@@ -35,7 +37,14 @@ func (s *projectDirectoryImageBuildStep) Inputs() (api.InputDefinition, error) {
 	return nil, nil
 }
 
-func (s *projectDirectoryImageBuildStep) Validate() error { return nil }
+func (s *projectDirectoryImageBuildStep) Validate() error {
+	if s.config.From != "" {
+		if err := validatePipelineImageStreamTag("from", s.config.From); err != nil {
+			return err
+		}
+	}
+	return validateBuildTarget("to", s.config.To)
+}
 
 func (s *projectDirectoryImageBuildStep) Run(ctx context.Context) error {
 	return results.ForReason("building_project_image").ForError(s.run(ctx))
@@ -55,13 +64,23 @@ func (s *projectDirectoryImageBuildStep) run(ctx context.Context) error {
 	if err := s.createSecrets(ctx); err != nil {
 		return err
 	}
+	source := buildapi.BuildSource{
+		Type:       buildapi.BuildSourceImage,
+		Dockerfile: s.config.DockerfileLiteral,
+		Images:     images,
+	}
+	if s.config.RequiresEntitlement {
+		source.Secrets = append(source.Secrets, buildapi.SecretBuildSource{
+			Secret:         coreapi.LocalObjectReference{Name: EntitlementSecretName},
+			DestinationDir: EntitlementSecretName,
+		})
+	}
+	if s.config.IsMultiArch() {
+		return s.buildArchitectures(ctx, source, fromDigest)
+	}
 	build := buildFromSource(
 		s.jobSpec, s.config.From, s.config.To,
-		buildapi.BuildSource{
-			Type:       buildapi.BuildSourceImage,
-			Dockerfile: s.config.DockerfileLiteral,
-			Images:     images,
-		},
+		source,
 		fromDigest,
 		s.config.DockerfilePath,
 		s.resources,
@@ -86,10 +105,36 @@ func (s *projectDirectoryImageBuildStep) createSecrets(ctx context.Context) erro
 			toCreate[name] = ctrlruntimeclient.ObjectKey{Namespace: args.ValueFrom.Namespace, Name: args.ValueFrom.Name}
 		}
 	}
+	if s.config.RequiresEntitlement {
+		if err := validateEntitlementSecret(ctx, s.secretClient); err != nil {
+			return err
+		}
+		toCreate[EntitlementSecretName] = ctrlruntimeclient.ObjectKey{Namespace: EntitlementSecretNamespace, Name: EntitlementSecretName}
+	}
 
 	return util.CopySecretsIntoJobNamespace(ctx, s.secretClient, s.jobSpec, toCreate)
 }
 
+// validateEntitlementSecret makes sure the shared entitlement secret exists and
+// holds all the files needed to consume subscription content. The secret client
+// records the secret, so its content is censored from any output.
+func validateEntitlementSecret(ctx context.Context, client ctrlruntimeclient.Client) error {
+	secret := &coreapi.Secret{}
+	if err := client.Get(ctx, ctrlruntimeclient.ObjectKey{Namespace: EntitlementSecretNamespace, Name: EntitlementSecretName}, secret); err != nil {
+		return fmt.Errorf("could not get entitlement secret %s/%s: %w", EntitlementSecretNamespace, EntitlementSecretName, err)
+	}
+	var missing []string
+	for _, key := range entitlementSecretKeys {
+		if len(secret.Data[key]) == 0 {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("entitlement secret %s/%s is missing keys: %s", EntitlementSecretNamespace, EntitlementSecretName, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
 type workingDir func(tag string) (string, error)
 type isBundleImage func(tag string) bool
 
@@ -184,16 +229,21 @@ func (s *projectDirectoryImageBuildStep) Description() string {
 }
 
 func (s *projectDirectoryImageBuildStep) Objects() []ctrlruntimeclient.Object {
-	return s.client.Objects()
+	objects := s.client.Objects()
+	if s.config.IsMultiArch() && s.podClient != nil {
+		objects = append(objects, s.podClient.Objects()...)
+	}
+	return objects
 }
 
-func ProjectDirectoryImageBuildStep(config api.ProjectDirectoryImageBuildStepConfiguration, releaseBuildConfig *api.ReleaseBuildConfiguration, resources api.ResourceConfiguration, secretClient ctrlruntimeclient.Client, buildClient BuildClient, jobSpec *api.JobSpec, pullSecret *coreapi.Secret) api.Step {
+func ProjectDirectoryImageBuildStep(config api.ProjectDirectoryImageBuildStepConfiguration, releaseBuildConfig *api.ReleaseBuildConfiguration, resources api.ResourceConfiguration, secretClient ctrlruntimeclient.Client, buildClient BuildClient, podClient PodClient, jobSpec *api.JobSpec, pullSecret *coreapi.Secret) api.Step {
 	return &projectDirectoryImageBuildStep{
 		config:             config,
 		releaseBuildConfig: releaseBuildConfig,
 		resources:          resources,
 		secretClient:       secretClient,
 		client:             buildClient,
+		podClient:          podClient,
 		jobSpec:            jobSpec,
 		pullSecret:         pullSecret,
 	}
@@ -393,6 +393,42 @@ func TestCreateSecrets(t *testing.T) {
 			}
 			return nil
 		},
+	}, {
+		name: "entitlement is copied",
+		s: &projectDirectoryImageBuildStep{
+			secretClient: fake.NewClientBuilder().WithObjects(anEntitlementSecret(true)).Build(),
+			jobSpec:      &api.JobSpec{},
+			config: api.ProjectDirectoryImageBuildStepConfiguration{
+				RequiresEntitlement: true,
+			},
+		},
+		verifyFunc: func(client ctrlruntimeclient.Client) error {
+			actualSecret := &corev1.Secret{}
+			if err := client.Get(context.TODO(), ctrlruntimeclient.ObjectKey{Name: "etc-pki-entitlement", Namespace: "ci-op-zcsc2986"}, actualSecret); err != nil {
+				return err
+			}
+			if diff := cmp.Diff(anEntitlementSecret(true).Data, actualSecret.Data); diff != "" {
+				return fmt.Errorf("actual does not match expected, diff: %s", diff)
+			}
+			return nil
+		},
+	}, {
+		name: "entitlement is incomplete",
+		s: &projectDirectoryImageBuildStep{
+			secretClient: fake.NewClientBuilder().WithObjects(anEntitlementSecret(false)).Build(),
+			jobSpec:      &api.JobSpec{},
+			config: api.ProjectDirectoryImageBuildStepConfiguration{
+				RequiresEntitlement: true,
+			},
+		},
+		expected: errors.New("entitlement secret ci/etc-pki-entitlement is missing keys: redhat-uep.pem"),
+		verifyFunc: func(client ctrlruntimeclient.Client) error {
+			actualSecret := &corev1.Secret{}
+			if err := client.Get(context.TODO(), ctrlruntimeclient.ObjectKey{Name: "etc-pki-entitlement", Namespace: "ci-op-zcsc2986"}, actualSecret); !kerrors.IsNotFound(err) {
+				return fmt.Errorf("expected Not Found error did not occur")
+			}
+			return nil
+		},
 	}} {
 		t.Run(tc.name, func(t *testing.T) {
 			tc.s.jobSpec.SetNamespace("ci-op-zcsc2986")
@@ -408,3 +444,17 @@ func TestCreateSecrets(t *testing.T) {
 		})
 	}
 }
+
+func anEntitlementSecret(withCA bool) *corev1.Secret {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ci", Name: "etc-pki-entitlement"},
+		Data: map[string][]byte{
+			"entitlement.pem":     []byte("cert"),
+			"entitlement-key.pem": []byte("key"),
+		},
+	}
+	if withCA {
+		secret.Data["redhat-uep.pem"] = []byte("ca")
+	}
+	return secret
+}
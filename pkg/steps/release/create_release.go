@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -12,6 +13,7 @@ import (
 	rbacapi "k8s.io/api/rbac/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
 	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -140,8 +142,9 @@ func (s *assembleReleaseStep) run(ctx context.Context) error {
 	}
 
 	streamName := api.ReleaseStreamFor(s.name)
+	flavor := flavorFor(s.config)
 	stable := &imageapi.ImageStream{}
-	var cvo string
+	var cvo, cli string
 	cvoExists := false
 	cliExists := false
 	// waiting for importing the images
@@ -153,16 +156,16 @@ func (s *assembleReleaseStep) run(ctx context.Context) error {
 			return false, err
 		}
 		cvo, cvoExists = util.ResolvePullSpec(stable, "cluster-version-operator", true)
-		_, cliExists = util.ResolvePullSpec(stable, "cli", true)
+		cli, cliExists = flavor.resolveCLI(stable)
 		ret := cvoExists && cliExists
 		if !ret {
-			logrus.Infof("Waiting to import cluster-version-operator and cli ...")
+			logrus.Infof("Waiting to import cluster-version-operator and %s ...", strings.Join(flavor.cliTags, " or "))
 		}
 		return ret, nil
 	}, importCtx.Done()); err != nil {
 		if wait.ErrWaitTimeout == err {
 			if !cliExists {
-				return results.ForReason("missing_cli").WithError(err).Errorf("no 'cli' image was tagged into the %s stream, that image is required for building a release", streamName)
+				return results.ForReason("missing_cli").WithError(err).Errorf("no '%s' image was tagged into the %s stream, that image is required for building a release", strings.Join(flavor.cliTags, "' or '"), streamName)
 			}
 			logrus.Infof("No %s release image necessary, %s image stream does not include a cluster-version-operator image", s.name, streamName)
 			return nil
@@ -177,7 +180,7 @@ func (s *assembleReleaseStep) run(ctx context.Context) error {
 
 	// we want to expose the release payload as a CI version that looks just like
 	// the release versions for nightlies and CI release candidates
-	prefix := "0.0.1-0"
+	prefix := flavor.versionPrefix
 	if raw, ok := stable.ObjectMeta.Annotations[releaseConfigAnnotation]; ok {
 		var releaseConfig struct {
 			Name string `json:"name"`
@@ -190,14 +193,22 @@ func (s *assembleReleaseStep) run(ctx context.Context) error {
 	now := time.Now().UTC().Truncate(time.Second)
 	version := fmt.Sprintf("%s.test-%s-%s-%s", prefix, now.Format("2006-01-02-150405"), s.jobSpec.Namespace(), s.name)
 
+	// The release is created from the copy of the stream we verify it against, so
+	// images that get tagged into the stream in the meantime end up in neither
+	rawStream, err := json.Marshal(stable)
+	if err != nil {
+		return results.ForReason("creating_release").WithError(err).Errorf("could not serialize imagestream %s: %v", streamName, err)
+	}
+
 	destination := fmt.Sprintf("%s:%s", releaseImageStreamRepo, s.name)
+	payloadConfigMap := fmt.Sprintf("release-payload-%s", s.name)
 	logrus.Infof("Creating release image %s.", destination)
 	podConfig := steps.PodStepConfiguration{
 		SkipLogs: true,
 		As:       fmt.Sprintf("release-%s", s.name),
 		From: api.ImageStreamTagReference{
 			Name: streamName,
-			Tag:  "cli",
+			Tag:  cli,
 		},
 		Labels:             map[string]string{releaseLabel: s.name},
 		ServiceAccountName: "ci-operator",
@@ -205,9 +216,16 @@ func (s *assembleReleaseStep) run(ctx context.Context) error {
 set -xeuo pipefail
 export HOME=/tmp
 oc registry login
-oc adm release new --max-per-registry=32 -n %q --from-image-stream %q --to-image-base %q --to-image %q --name %q
+cat > /tmp/%s.json <<'EOF'
+%s
+EOF
+oc adm release new --max-per-registry=32 --from-image-stream-file /tmp/%s.json --to-image-base %q --to-image %q --name %q
 oc adm release extract --from=%q --to=${ARTIFACT_DIR}/release-payload-%s
-`, s.jobSpec.Namespace(), streamName, cvo, destination, version, destination, s.name),
+if oc get configmap %s; then
+	oc delete configmap %s
+fi
+oc create configmap %s --from-file=%s=${ARTIFACT_DIR}/release-payload-%s/image-references
+`, streamName, rawStream, streamName, cvo, destination, version, destination, s.name, payloadConfigMap, payloadConfigMap, payloadConfigMap, imageReferencesKey, s.name),
 	}
 
 	// set an explicit default for release-latest resources, but allow customization if necessary
@@ -223,8 +241,56 @@ oc adm release extract --from=%q --to=${ARTIFACT_DIR}/release-payload-%s
 	}
 
 	step := steps.PodStep("release", podConfig, resources, s.client, s.jobSpec, nil)
+	if err := step.Run(ctx); err != nil {
+		return results.ForReason("creating_release").ForError(err)
+	}
+
+	return s.verifyPayload(ctx, stable, payloadConfigMap)
+}
+
+const imageReferencesKey = "image-references"
+
+// verifyPayload verifies the payload contains exactly the images of the stream we created it
+// from, so we do not silently test a payload that is missing components
+func (s *assembleReleaseStep) verifyPayload(ctx context.Context, stream *imagev1.ImageStream, payloadConfigMap string) error {
+	var configMap coreapi.ConfigMap
+	if err := s.client.Get(ctx, ctrlruntimeclient.ObjectKey{Namespace: s.jobSpec.Namespace(), Name: payloadConfigMap}, &configMap); err != nil {
+		return results.ForReason("verifying_release").WithError(err).Errorf("could not fetch the image references of release %s: %v", s.name, err)
+	}
+	var payload imagev1.ImageStream
+	if err := json.Unmarshal([]byte(configMap.Data[imageReferencesKey]), &payload); err != nil {
+		return results.ForReason("verifying_release").WithError(err).Errorf("unable to decode the image references of release %s: %v", s.name, err)
+	}
+	if err := verifyReleasePayload(stream, &payload); err != nil {
+		return results.ForReason("verifying_release").ForError(err)
+	}
+	return nil
+}
 
-	return results.ForReason("creating_release").ForError(step.Run(ctx))
+// verifyReleasePayload returns an error that names the missing and extra images if the
+// image references of a release payload do not match the tags of the stream it was created from
+func verifyReleasePayload(stream, payload *imagev1.ImageStream) error {
+	expected := sets.NewString()
+	for _, tag := range stream.Status.Tags {
+		if len(tag.Items) > 0 {
+			expected.Insert(tag.Tag)
+		}
+	}
+	actual := sets.NewString()
+	for _, tag := range payload.Spec.Tags {
+		actual.Insert(tag.Name)
+	}
+	var problems []string
+	if missing := expected.Difference(actual); missing.Len() > 0 {
+		problems = append(problems, fmt.Sprintf("missing images: %s", strings.Join(missing.List(), ", ")))
+	}
+	if extra := actual.Difference(expected); extra.Len() > 0 {
+		problems = append(problems, fmt.Sprintf("unexpected images: %s", strings.Join(extra.List(), ", ")))
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("release payload does not match the %s image stream: %s", stream.Name, strings.Join(problems, "; "))
+	}
+	return nil
 }
 
 func (s *assembleReleaseStep) Requires() []api.StepLink {
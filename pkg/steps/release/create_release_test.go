@@ -0,0 +1,124 @@
+package release
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	coreapi "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	imagev1 "github.com/openshift/api/image/v1"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/steps"
+	"github.com/openshift/ci-tools/pkg/steps/loggingclient"
+	"github.com/openshift/ci-tools/pkg/testhelper"
+)
+
+func init() {
+	if err := imagev1.AddToScheme(scheme.Scheme); err != nil {
+		panic(fmt.Sprintf("failed to add imagev1 to scheme: %v", err))
+	}
+}
+
+func TestVerifyReleasePayload(t *testing.T) {
+	stream := &imagev1.ImageStream{
+		ObjectMeta: metav1.ObjectMeta{Name: "stable"},
+		Status: imagev1.ImageStreamStatus{Tags: []imagev1.NamedTagEventList{
+			{Tag: "cli", Items: []imagev1.TagEvent{{Image: "sha256:cli"}}},
+			{Tag: "installer", Items: []imagev1.TagEvent{{Image: "sha256:installer"}}},
+			{Tag: "not-imported"},
+		}},
+	}
+	payload := func(tags ...string) *imagev1.ImageStream {
+		is := &imagev1.ImageStream{}
+		for _, tag := range tags {
+			is.Spec.Tags = append(is.Spec.Tags, imagev1.TagReference{Name: tag})
+		}
+		return is
+	}
+
+	testCases := []struct {
+		name     string
+		payload  *imagev1.ImageStream
+		expected error
+	}{
+		{
+			name:    "payload matches the stream",
+			payload: payload("cli", "installer"),
+		},
+		{
+			name:     "images are missing",
+			payload:  payload("cli"),
+			expected: errors.New("release payload does not match the stable image stream: missing images: installer"),
+		},
+		{
+			name:     "images are missing and unexpected",
+			payload:  payload("cli", "machine-os-content", "tests"),
+			expected: errors.New("release payload does not match the stable image stream: missing images: installer; unexpected images: machine-os-content, tests"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.expected, verifyReleasePayload(stream, tc.payload), testhelper.EquateErrorMessage); diff != "" {
+				t.Errorf("error differs from expected: %s", diff)
+			}
+		})
+	}
+}
+
+func TestVerifyPayload(t *testing.T) {
+	// The stream the release was created from
+	stream := &imagev1.ImageStream{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "stable"},
+		Status: imagev1.ImageStreamStatus{Tags: []imagev1.NamedTagEventList{
+			{Tag: "cli", Items: []imagev1.TagEvent{{Image: "sha256:cli"}}},
+		}},
+	}
+	// The installer got tagged into the stream on the cluster after the release was created
+	current := stream.DeepCopy()
+	current.Status.Tags = append(current.Status.Tags, imagev1.NamedTagEventList{Tag: "installer", Items: []imagev1.TagEvent{{Image: "sha256:installer"}}})
+	testCases := []struct {
+		name       string
+		references string
+		expected   string
+	}{
+		{
+			name:       "payload matches the stream it was created from",
+			references: `{"spec":{"tags":[{"name":"cli"}]}}`,
+		},
+		{
+			name:       "payload misses images",
+			references: `{"spec":{"tags":[]}}`,
+			expected:   "release payload does not match the stable image stream: missing images: cli",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			configMap := &coreapi.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "release-payload-latest"},
+				Data:       map[string]string{imageReferencesKey: tc.references},
+			}
+			jobSpec := &api.JobSpec{}
+			jobSpec.SetNamespace("ns")
+			s := &assembleReleaseStep{
+				name:    "latest",
+				client:  steps.NewPodClient(loggingclient.New(fakectrlruntimeclient.NewFakeClient(current, configMap)), nil, nil, nil),
+				jobSpec: jobSpec,
+			}
+			var actual string
+			if err := s.verifyPayload(context.Background(), stream, "release-payload-latest"); err != nil {
+				actual = err.Error()
+			}
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Errorf("error differs from expected: %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package release
+
+import (
+	imagev1 "github.com/openshift/api/image/v1"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/util"
+)
+
+// flavor holds what differs between assembling payloads of the release flavors
+type flavor struct {
+	// versionPrefix is used for the version of assembled payloads when the
+	// stable stream does not record the release it is assembled for
+	versionPrefix string
+	// cliTags are the tags of the stable stream that oc can be run from, in
+	// the order of preference. OKD streams do not always carry the cli image,
+	// but cli-artifacts is built from it.
+	cliTags []string
+}
+
+var flavors = map[api.ReleaseFlavor]flavor{
+	api.ReleaseFlavorOCP:  {versionPrefix: "0.0.1-0", cliTags: []string{"cli"}},
+	api.ReleaseFlavorOKD:  {versionPrefix: "0.0.1-0.okd", cliTags: []string{"cli", "cli-artifacts"}},
+	api.ReleaseFlavorSCOS: {versionPrefix: "0.0.1-0.okd-scos", cliTags: []string{"cli", "cli-artifacts"}},
+}
+
+// flavorFor returns the flavor of the release, which defaults to OCP
+func flavorFor(config *api.ReleaseTagConfiguration) flavor {
+	if config != nil {
+		if f, ok := flavors[config.Flavor]; ok {
+			return f
+		}
+	}
+	return flavors[api.ReleaseFlavorOCP]
+}
+
+// resolveCLI returns the tag of the stable stream oc is run from
+func (f flavor) resolveCLI(stable *imagev1.ImageStream) (string, bool) {
+	for _, tag := range f.cliTags {
+		if _, exists := util.ResolvePullSpec(stable, tag, true); exists {
+			return tag, true
+		}
+	}
+	return "", false
+}
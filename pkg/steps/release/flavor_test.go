@@ -0,0 +1,66 @@
+package release
+
+import (
+	"testing"
+
+	imagev1 "github.com/openshift/api/image/v1"
+
+	"github.com/openshift/ci-tools/pkg/api"
+)
+
+func TestFlavorResolveCLI(t *testing.T) {
+	stream := func(tags ...string) *imagev1.ImageStream {
+		is := &imagev1.ImageStream{Status: imagev1.ImageStreamStatus{PublicDockerImageRepository: "registry/ns/stable"}}
+		for _, tag := range tags {
+			is.Status.Tags = append(is.Status.Tags, imagev1.NamedTagEventList{Tag: tag, Items: []imagev1.TagEvent{{Image: "sha256:" + tag}}})
+		}
+		return is
+	}
+	var testCases = []struct {
+		name           string
+		config         *api.ReleaseTagConfiguration
+		stable         *imagev1.ImageStream
+		expectedTag    string
+		expectedExists bool
+	}{
+		{
+			name:           "OCP uses the cli image",
+			config:         &api.ReleaseTagConfiguration{},
+			stable:         stream("cli", "cli-artifacts"),
+			expectedTag:    "cli",
+			expectedExists: true,
+		},
+		{
+			name:   "OCP does not fall back to cli-artifacts",
+			config: &api.ReleaseTagConfiguration{Flavor: api.ReleaseFlavorOCP},
+			stable: stream("cli-artifacts"),
+		},
+		{
+			name:           "OKD prefers the cli image",
+			config:         &api.ReleaseTagConfiguration{Flavor: api.ReleaseFlavorOKD},
+			stable:         stream("cli", "cli-artifacts"),
+			expectedTag:    "cli",
+			expectedExists: true,
+		},
+		{
+			name:           "SCOS falls back to cli-artifacts",
+			config:         &api.ReleaseTagConfiguration{Flavor: api.ReleaseFlavorSCOS},
+			stable:         stream("cli-artifacts"),
+			expectedTag:    "cli-artifacts",
+			expectedExists: true,
+		},
+		{
+			name:   "no cli image",
+			config: &api.ReleaseTagConfiguration{Flavor: api.ReleaseFlavorSCOS},
+			stable: stream("installer"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tag, exists := flavorFor(tc.config).resolveCLI(tc.stable)
+			if tag != tc.expectedTag || exists != tc.expectedExists {
+				t.Errorf("expected (%q, %t), got (%q, %t)", tc.expectedTag, tc.expectedExists, tag, exists)
+			}
+		})
+	}
+}
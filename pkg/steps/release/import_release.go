@@ -124,17 +124,20 @@ func (s *importReleaseStep) run(ctx context.Context) error {
 	if err := wait.ExponentialBackoff(wait.Backoff{Steps: 4, Duration: 1 * time.Second, Factor: 2}, func() (bool, error) {
 		if err := s.client.Create(ctx, streamImport); err != nil {
 			if kerrors.IsConflict(err) {
+				steps.RecordRetry(ctx)
 				return false, nil
 			}
 			if kerrors.IsForbidden(err) {
 				// the ci-operator expects to have POST /imagestreamimports in the namespace of the job
 				logrus.Warnf("Unable to lock %s to an image digest pull spec, you don't have permission to access the necessary API.", utils.ReleaseImageEnv(s.name))
+				steps.RecordRetry(ctx)
 				return false, nil
 			}
 			return false, err
 		}
 		image := streamImport.Status.Images[0]
 		if image.Image == nil {
+			steps.RecordRetry(ctx)
 			return false, nil
 		}
 		pullSpec = streamImport.Status.Images[0].Image.DockerImageReference
@@ -73,9 +73,87 @@ func (s *promotionStep) run(ctx context.Context) error {
 	if _, err := steps.RunPod(ctx, s.client, getPromotionPod(imageMirrorTarget, s.jobSpec.Namespace())); err != nil {
 		return fmt.Errorf("unable to run promotion pod: %w", err)
 	}
+
+	if external := s.configuration.PromotionConfiguration.ExternalRegistry; external != nil {
+		copies := getExternalCopies(tags, pipeline, *external)
+		logrus.Infof("Pushing %d images to the external registry %s", len(copies), external.Registry)
+		if _, err := steps.RunPod(ctx, s.client, getExternalPromotionPod(copies, s.jobSpec.Namespace())); err != nil {
+			return fmt.Errorf("unable to run external promotion pod: %w", err)
+		}
+	}
 	return nil
 }
 
+// externalCopy is an image pushed to an external registry. The push is only
+// successful once the copy has the digest of the source.
+type externalCopy struct {
+	source      string
+	destination string
+	digest      string
+}
+
+func getExternalCopies(tags map[string][]api.ImageStreamTagReference, pipeline *imagev1.ImageStream, external api.ExternalRegistryPromotion) []externalCopy {
+	if pipeline == nil {
+		return nil
+	}
+	var copies []externalCopy
+	for src, dsts := range tags {
+		event := findTagEvent(pipeline, src)
+		if event == nil || event.DockerImageReference == "" {
+			continue
+		}
+		source := getPublicImageReference(event.DockerImageReference, pipeline.Status.PublicDockerImageRepository)
+		for _, dst := range dsts {
+			organization := external.Organization
+			if organization == "" {
+				organization = dst.Namespace
+			}
+			copies = append(copies, externalCopy{
+				source:      source,
+				destination: fmt.Sprintf("%s/%s/%s:%s", external.Registry, organization, dst.Name, dst.Tag),
+				digest:      event.Image,
+			})
+		}
+	}
+	sort.Slice(copies, func(i, j int) bool {
+		return copies[i].destination < copies[j].destination
+	})
+	return copies
+}
+
+// externalPromotionAttempts is how often pushing an image to an external
+// registry is attempted, as those are less reliable than the internal one
+const externalPromotionAttempts = 3
+
+// getExternalPromotionPod returns the pod pushing the copies. The script is the same for
+// all configurations, the copies are passed to it as arguments, so no part of the
+// configuration is interpreted by the shell.
+func getExternalPromotionPod(copies []externalCopy, namespace string) *coreapi.Pod {
+	registryConfig := filepath.Join(api.RegistryPushCredentialsCICentralSecretMountPath, coreapi.DockerConfigJsonKey)
+	script := fmt.Sprintf(`set -o errexit
+copy() {
+  for attempt in $(seq 1 %d); do
+    if oc image mirror --registry-config=%s "$1" "$2" && oc image info --registry-config=%s "$2" | grep -q "^Digest:[[:space:]]*$3$"; then
+      return 0
+    fi
+    echo "Attempt ${attempt} to push $1 to $2 failed"
+    sleep 10
+  done
+  echo "Failed to push $1 to $2 with digest $3"
+  return 1
+}
+while [ "$#" -gt 0 ]; do
+  copy "$1" "$2" "$3"
+  shift 3
+done`, externalPromotionAttempts, registryConfig, registryConfig)
+	// the first argument after the script is the name of the script, $0
+	args := []string{script, "promote-external"}
+	for _, c := range copies {
+		args = append(args, c.source, c.destination, c.digest)
+	}
+	return promotionPod("promotion-external", namespace, args)
+}
+
 // registryDomain determines the domain of the registry we promote to
 func registryDomain(configuration *api.PromotionConfiguration) string {
 	registry := api.DomainForService(api.ServiceRegistry)
@@ -85,18 +163,20 @@ func registryDomain(configuration *api.PromotionConfiguration) string {
 	return registry
 }
 
-func getImageMirrorTarget(tags map[string]api.ImageStreamTagReference, pipeline *imagev1.ImageStream, registry string) map[string]string {
+func getImageMirrorTarget(tags map[string][]api.ImageStreamTagReference, pipeline *imagev1.ImageStream, registry string) map[string][]string {
 	if pipeline == nil {
 		return nil
 	}
-	imageMirror := map[string]string{}
-	for src, dst := range tags {
+	imageMirror := map[string][]string{}
+	for src, dsts := range tags {
 		dockerImageReference := findDockerImageReference(pipeline, src)
 		if dockerImageReference == "" {
 			continue
 		}
 		dockerImageReference = getPublicImageReference(dockerImageReference, pipeline.Status.PublicDockerImageRepository)
-		imageMirror[dockerImageReference] = fmt.Sprintf("%s/%s", registry, dst.ISTagName())
+		for _, dst := range dsts {
+			imageMirror[dockerImageReference] = append(imageMirror[dockerImageReference], fmt.Sprintf("%s/%s", registry, dst.ISTagName()))
+		}
 	}
 	if len(imageMirror) == 0 {
 		return nil
@@ -124,7 +204,7 @@ func getPublicImageReference(dockerImageReference, publicDockerImageRepository s
 	return strings.Replace(dockerImageReference, splits[0], publicHost, 1)
 }
 
-func getPromotionPod(imageMirrorTarget map[string]string, namespace string) *coreapi.Pod {
+func getPromotionPod(imageMirrorTarget map[string][]string, namespace string) *coreapi.Pod {
 	keys := make([]string, 0, len(imageMirrorTarget))
 	for k := range imageMirrorTarget {
 		keys = append(keys, k)
@@ -133,20 +213,28 @@ func getPromotionPod(imageMirrorTarget map[string]string, namespace string) *cor
 
 	var images []string
 	for _, k := range keys {
-		images = append(images, fmt.Sprintf("%s=%s", k, imageMirrorTarget[k]))
+		for _, dst := range imageMirrorTarget[k] {
+			images = append(images, fmt.Sprintf("%s=%s", k, dst))
+		}
 	}
-	command := []string{"/bin/sh", "-c"}
 	args := []string{fmt.Sprintf("oc image mirror --registry-config=%s --continue-on-error=true --max-per-registry=20 %s", filepath.Join(api.RegistryPushCredentialsCICentralSecretMountPath, coreapi.DockerConfigJsonKey), strings.Join(images, " "))}
+	return promotionPod("promotion", namespace, args)
+}
+
+// promotionPod returns a pod running the arguments as a script with the
+// push secret mounted
+func promotionPod(name, namespace string, args []string) *coreapi.Pod {
+	command := []string{"/bin/sh", "-c"}
 	return &coreapi.Pod{
 		ObjectMeta: meta.ObjectMeta{
-			Name:      "promotion",
+			Name:      name,
 			Namespace: namespace,
 		},
 		Spec: coreapi.PodSpec{
 			RestartPolicy: coreapi.RestartPolicyNever,
 			Containers: []coreapi.Container{
 				{
-					Name:    "promotion",
+					Name:    name,
 					Image:   fmt.Sprintf("%s/ocp/4.8:cli", api.DomainForService(api.ServiceRegistry)),
 					Command: command,
 					Args:    args,
@@ -174,16 +262,24 @@ func getPromotionPod(imageMirrorTarget map[string]string, namespace string) *cor
 // findDockerImageReference returns DockerImageReference, the string that can be used to pull this image,
 // to a tag if it exists in the ImageStream's Spec
 func findDockerImageReference(is *imagev1.ImageStream, tag string) string {
+	if event := findTagEvent(is, tag); event != nil {
+		return event.DockerImageReference
+	}
+	return ""
+}
+
+// findTagEvent returns the current content of a tag if it exists in the ImageStream
+func findTagEvent(is *imagev1.ImageStream, tag string) *imagev1.TagEvent {
 	for _, t := range is.Status.Tags {
 		if t.Tag != tag {
 			continue
 		}
 		if len(t.Items) == 0 {
-			return ""
+			return nil
 		}
-		return t.Items[0].DockerImageReference
+		return &t.Items[0]
 	}
-	return ""
+	return nil
 }
 
 // toPromote determines the mapping of local tag to external tag which should be promoted
@@ -201,6 +297,10 @@ func toPromote(config api.PromotionConfiguration, images []api.ProjectDirectoryI
 		if requiredImages.Has(tag) || !image.Optional {
 			tagsByDst[tag] = tag
 			names.Insert(tag)
+			for _, name := range image.AdditionalNames {
+				tagsByDst[name] = tag
+				names.Insert(name)
+			}
 		}
 	}
 	for _, tag := range config.ExcludedImages {
@@ -219,8 +319,8 @@ func toPromote(config api.PromotionConfiguration, images []api.ProjectDirectoryI
 func PromotedTags(configuration *api.ReleaseBuildConfiguration) []api.ImageStreamTagReference {
 	var tags []api.ImageStreamTagReference
 	mapping, _ := PromotedTagsWithRequiredImages(configuration, sets.NewString())
-	for _, dest := range mapping {
-		tags = append(tags, dest)
+	for _, dests := range mapping {
+		tags = append(tags, dests...)
 	}
 	sort.Slice(tags, func(i, j int) bool {
 		return tags[i].ISTagName() < tags[j].ISTagName()
@@ -228,15 +328,51 @@ func PromotedTags(configuration *api.ReleaseBuildConfiguration) []api.ImageStrea
 	return tags
 }
 
+// PromotionIndexName is the name under which configurations are indexed by
+// PromotionIndexKeys in a ConfigAgent
+const PromotionIndexName = "release-build-config-by-image-stream-tag"
+
+// PromotionIndexKeys returns the ImageStreamTags the given ReleaseBuildConfiguration promotes to in
+// namespace/name:tag form. They are the keys under which configurations are indexed to look up which
+// configuration builds a given ImageStreamTag.
+func PromotionIndexKeys(configuration api.ReleaseBuildConfiguration) []string {
+	var keys []string
+	for _, tag := range PromotedTags(&configuration) {
+		keys = append(keys, tag.ISTagName())
+	}
+	return keys
+}
+
+// PromotionCollisions builds the promotion index for the given configurations, which are keyed by an
+// identifier, and returns the identifiers of the configurations for every ImageStreamTag that is
+// promoted to by more than one of them. A promotion index with collisions can not be used to look up
+// which configuration builds an ImageStreamTag.
+func PromotionCollisions(configurations map[string]api.ReleaseBuildConfiguration) map[string][]string {
+	index := map[string][]string{}
+	for identifier, configuration := range configurations {
+		for _, key := range PromotionIndexKeys(configuration) {
+			index[key] = append(index[key], identifier)
+		}
+	}
+	collisions := map[string][]string{}
+	for key, identifiers := range index {
+		if len(identifiers) > 1 {
+			sort.Strings(identifiers)
+			collisions[key] = identifiers
+		}
+	}
+	return collisions
+}
+
 // PromotedTagsWithRequiredImages returns the tags that are being promoted for the given ReleaseBuildConfiguration
 // accounting for the list of required images. Promoted tags are mapped by the source tag in the pipeline ImageStream
-// we will promote to the output.
-func PromotedTagsWithRequiredImages(configuration *api.ReleaseBuildConfiguration, requiredImages sets.String) (map[string]api.ImageStreamTagReference, sets.String) {
+// we will promote to the output; a source tag is promoted to more than one output when the image has additional names.
+func PromotedTagsWithRequiredImages(configuration *api.ReleaseBuildConfiguration, requiredImages sets.String) (map[string][]api.ImageStreamTagReference, sets.String) {
 	if configuration == nil || configuration.PromotionConfiguration == nil || configuration.PromotionConfiguration.Disabled {
 		return nil, nil
 	}
 	tags, names := toPromote(*configuration.PromotionConfiguration, configuration.Images, requiredImages)
-	promotedTags := map[string]api.ImageStreamTagReference{}
+	promotedTags := map[string][]api.ImageStreamTagReference{}
 	for dst, src := range tags {
 		var tag api.ImageStreamTagReference
 		if configuration.PromotionConfiguration.Name != "" {
@@ -252,11 +388,16 @@ func PromotedTagsWithRequiredImages(configuration *api.ReleaseBuildConfiguration
 				Tag:       configuration.PromotionConfiguration.Tag,
 			}
 		}
-		promotedTags[src] = tag
+		promotedTags[src] = append(promotedTags[src], tag)
+	}
+	for src := range promotedTags {
+		sort.Slice(promotedTags[src], func(i, j int) bool {
+			return promotedTags[src][i].ISTagName() < promotedTags[src][j].ISTagName()
+		})
 	}
 	// promote the binary build if one exists and this isn't disabled
 	if configuration.BinaryBuildCommands != "" && !configuration.PromotionConfiguration.DisableBuildCache {
-		promotedTags[string(api.PipelineImageStreamTagReferenceBinaries)] = api.BuildCacheFor(configuration.Metadata)
+		promotedTags[string(api.PipelineImageStreamTagReferenceBinaries)] = []api.ImageStreamTagReference{api.BuildCacheFor(configuration.Metadata)}
 	}
 	return promotedTags, names
 }
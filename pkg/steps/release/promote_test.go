@@ -127,6 +127,19 @@ func TestToPromote(t *testing.T) {
 			expectedBySource: map[string]string{"bar": "bar", "baz": "baz", "boo": "ah"},
 			expectedNames:    sets.NewString("bar", "baz", "boo"),
 		},
+		{
+			name: "additional names of promoted images are promoted as well",
+			config: api.PromotionConfiguration{
+				Disabled: false,
+			},
+			images: []api.ProjectDirectoryImageBuildStepConfiguration{
+				{To: api.PipelineImageStreamTagReference("driver"), AdditionalNames: []string{"driver-rhel8"}},
+				{To: api.PipelineImageStreamTagReference("opt"), AdditionalNames: []string{"opt-rhel8"}, Optional: true},
+			},
+			requiredImages:   sets.NewString(),
+			expectedBySource: map[string]string{"driver": "driver", "driver-rhel8": "driver"},
+			expectedNames:    sets.NewString("driver", "driver-rhel8"),
+		},
 	}
 
 	for _, test := range testCases {
@@ -261,6 +274,27 @@ func TestPromotedTags(t *testing.T) {
 				Tag:       "branch",
 			}},
 		},
+		{
+			name: "image with additional names is promoted under every name",
+			input: &api.ReleaseBuildConfiguration{
+				Images: []api.ProjectDirectoryImageBuildStepConfiguration{
+					{To: api.PipelineImageStreamTagReference("driver"), AdditionalNames: []string{"driver-rhel8"}},
+				},
+				PromotionConfiguration: &api.PromotionConfiguration{
+					Namespace: "roger",
+					Name:      "fred",
+				},
+			},
+			expected: []api.ImageStreamTagReference{{
+				Namespace: "roger",
+				Name:      "fred",
+				Tag:       "driver",
+			}, {
+				Namespace: "roger",
+				Name:      "fred",
+				Tag:       "driver-rhel8",
+			}},
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -272,12 +306,61 @@ func TestPromotedTags(t *testing.T) {
 	}
 }
 
+func TestPromotionCollisions(t *testing.T) {
+	configuration := func(namespace, name string, images ...string) api.ReleaseBuildConfiguration {
+		configuration := api.ReleaseBuildConfiguration{PromotionConfiguration: &api.PromotionConfiguration{Namespace: namespace, Name: name}}
+		for _, image := range images {
+			configuration.Images = append(configuration.Images, api.ProjectDirectoryImageBuildStepConfiguration{To: api.PipelineImageStreamTagReference(image)})
+		}
+		return configuration
+	}
+	var testCases = []struct {
+		name           string
+		configurations map[string]api.ReleaseBuildConfiguration
+		expected       map[string][]string
+	}{
+		{
+			name: "no collisions",
+			configurations: map[string]api.ReleaseBuildConfiguration{
+				"org/repo@master":      configuration("ocp", "4.8", "foo"),
+				"org/repo@release-4.7": configuration("ocp", "4.7", "foo"),
+				"org/other@master":     configuration("ocp", "4.8", "bar"),
+			},
+			expected: map[string][]string{},
+		},
+		{
+			name: "collisions are reported with all configurations",
+			configurations: map[string]api.ReleaseBuildConfiguration{
+				"org/repo@master":           configuration("ocp", "4.8", "foo", "bar"),
+				"org/repo@master [variant]": configuration("ocp", "4.8", "foo"),
+				"org/other@master":          configuration("ocp", "4.8", "foo", "bar", "baz"),
+				"org/disabled@master": func() api.ReleaseBuildConfiguration {
+					configuration := configuration("ocp", "4.8", "foo")
+					configuration.PromotionConfiguration.Disabled = true
+					return configuration
+				}(),
+			},
+			expected: map[string][]string{
+				"ocp/4.8:foo": {"org/other@master", "org/repo@master", "org/repo@master [variant]"},
+				"ocp/4.8:bar": {"org/other@master", "org/repo@master"},
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if diff := cmp.Diff(testCase.expected, PromotionCollisions(testCase.configurations)); diff != "" {
+				t.Errorf("collisions differ from expected: %s", diff)
+			}
+		})
+	}
+}
+
 func TestPromotedTagsWithRequiredImages(t *testing.T) {
 	var testCases = []struct {
 		name     string
 		input    *api.ReleaseBuildConfiguration
 		images   sets.String
-		expected map[string]api.ImageStreamTagReference
+		expected map[string][]api.ImageStreamTagReference
 		names    sets.String
 	}{
 		{
@@ -295,11 +378,11 @@ func TestPromotedTagsWithRequiredImages(t *testing.T) {
 					Name:      "fred",
 				},
 			},
-			expected: map[string]api.ImageStreamTagReference{"foo": {
+			expected: map[string][]api.ImageStreamTagReference{"foo": {{
 				Namespace: "roger",
 				Name:      "fred",
 				Tag:       "foo",
-			}},
+			}}},
 		},
 		{
 			name: "optional image is ignored means output tags",
@@ -313,11 +396,11 @@ func TestPromotedTagsWithRequiredImages(t *testing.T) {
 					Name:      "fred",
 				},
 			},
-			expected: map[string]api.ImageStreamTagReference{"foo": {
+			expected: map[string][]api.ImageStreamTagReference{"foo": {{
 				Namespace: "roger",
 				Name:      "fred",
 				Tag:       "foo",
-			}},
+			}}},
 		},
 		{
 			name: "optional image that's required means output tags",
@@ -331,11 +414,11 @@ func TestPromotedTagsWithRequiredImages(t *testing.T) {
 				},
 			},
 			images: sets.NewString("foo"),
-			expected: map[string]api.ImageStreamTagReference{"foo": {
+			expected: map[string][]api.ImageStreamTagReference{"foo": {{
 				Namespace: "roger",
 				Name:      "fred",
 				Tag:       "foo",
-			}},
+			}}},
 		},
 		{
 			name: "promoted image but disabled promotion means no output tags",
@@ -362,11 +445,11 @@ func TestPromotedTagsWithRequiredImages(t *testing.T) {
 					Tag:       "fred",
 				},
 			},
-			expected: map[string]api.ImageStreamTagReference{"foo": {
+			expected: map[string][]api.ImageStreamTagReference{"foo": {{
 				Namespace: "roger",
 				Name:      "foo",
 				Tag:       "fred",
-			}},
+			}}},
 		},
 		{
 			name: "promoted additional image with rename",
@@ -382,15 +465,36 @@ func TestPromotedTagsWithRequiredImages(t *testing.T) {
 					},
 				},
 			},
-			expected: map[string]api.ImageStreamTagReference{"foo": {
+			expected: map[string][]api.ImageStreamTagReference{"foo": {{
 				Namespace: "roger",
 				Name:      "foo",
 				Tag:       "fred",
-			}, "src": {
+			}}, "src": {{
 				Namespace: "roger",
 				Name:      "output",
 				Tag:       "fred",
-			}},
+			}}},
+		},
+		{
+			name: "image promoted under additional names",
+			input: &api.ReleaseBuildConfiguration{
+				Images: []api.ProjectDirectoryImageBuildStepConfiguration{
+					{To: api.PipelineImageStreamTagReference("driver"), AdditionalNames: []string{"driver-rhel8"}},
+				},
+				PromotionConfiguration: &api.PromotionConfiguration{
+					Namespace: "roger",
+					Name:      "fred",
+				},
+			},
+			expected: map[string][]api.ImageStreamTagReference{"driver": {{
+				Namespace: "roger",
+				Name:      "fred",
+				Tag:       "driver",
+			}, {
+				Namespace: "roger",
+				Name:      "fred",
+				Tag:       "driver-rhel8",
+			}}},
 		},
 		{
 			name: "disabled image",
@@ -404,7 +508,7 @@ func TestPromotedTagsWithRequiredImages(t *testing.T) {
 					ExcludedImages: []string{"foo"},
 				},
 			},
-			expected: map[string]api.ImageStreamTagReference{},
+			expected: map[string][]api.ImageStreamTagReference{},
 		},
 		{
 			name: "promotion set and binaries built, means binaries promoted",
@@ -421,11 +525,11 @@ func TestPromotedTagsWithRequiredImages(t *testing.T) {
 					Branch: "branch",
 				},
 			},
-			expected: map[string]api.ImageStreamTagReference{"bin": {
+			expected: map[string][]api.ImageStreamTagReference{"bin": {{
 				Namespace: "build-cache",
 				Name:      "org-repo",
 				Tag:       "branch",
-			}},
+			}}},
 		},
 		{
 			name: "promotion set and binaries built, build cache disabled means no binaries promoted",
@@ -443,7 +547,7 @@ func TestPromotedTagsWithRequiredImages(t *testing.T) {
 					Branch: "branch",
 				},
 			},
-			expected: map[string]api.ImageStreamTagReference{},
+			expected: map[string][]api.ImageStreamTagReference{},
 		},
 	}
 
@@ -498,15 +602,22 @@ func TestBuildCacheFor(t *testing.T) {
 func TestGetPromotionPod(t *testing.T) {
 	var testCases = []struct {
 		name        string
-		imageMirror map[string]string
+		imageMirror map[string][]string
 		namespace   string
 		expected    *coreapi.Pod
 	}{
 		{
 			name: "basic case",
-			imageMirror: map[string]string{
-				"docker-registry.default.svc:5000/ci-op-y2n8rsh3/pipeline@sha256:afd71aa3cbbf7d2e00cd8696747b2abf164700147723c657919c20b13d13ec62": "registy.ci.openshift.org/ci/applyconfig:latest",
-				"docker-registry.default.svc:5000/ci-op-y2n8rsh3/pipeline@sha256:bbb":                                                              "registy.ci.openshift.org/ci/bin:latest",
+			imageMirror: map[string][]string{
+				"docker-registry.default.svc:5000/ci-op-y2n8rsh3/pipeline@sha256:afd71aa3cbbf7d2e00cd8696747b2abf164700147723c657919c20b13d13ec62": {"registy.ci.openshift.org/ci/applyconfig:latest"},
+				"docker-registry.default.svc:5000/ci-op-y2n8rsh3/pipeline@sha256:bbb":                                                              {"registy.ci.openshift.org/ci/bin:latest"},
+			},
+			namespace: "ci-op-zyvwvffx",
+		},
+		{
+			name: "image promoted under additional names",
+			imageMirror: map[string][]string{
+				"docker-registry.default.svc:5000/ci-op-y2n8rsh3/pipeline@sha256:bbb": {"registy.ci.openshift.org/ci/driver:latest", "registy.ci.openshift.org/ci/driver-rhel8:latest"},
 			},
 			namespace: "ci-op-zyvwvffx",
 		},
@@ -519,12 +630,97 @@ func TestGetPromotionPod(t *testing.T) {
 	}
 }
 
+func TestGetExternalPromotionPod(t *testing.T) {
+	copies := []externalCopy{
+		{
+			source:      "registry.svc.ci.openshift.org/ci-op-y2n8rsh3/pipeline@sha256:bbb",
+			destination: "quay.io/openshift/bin:latest",
+			digest:      "sha256:bbb",
+		},
+		{
+			source:      "registry.svc.ci.openshift.org/ci-op-y2n8rsh3/pipeline@sha256:ddd",
+			destination: "quay.io/openshift/driver:latest",
+			digest:      "sha256:ddd",
+		},
+	}
+	testhelper.CompareWithFixture(t, getExternalPromotionPod(copies, "ci-op-zyvwvffx"))
+}
+
+func TestGetExternalCopies(t *testing.T) {
+	pipeline := &imageapi.ImageStream{
+		Status: imageapi.ImageStreamStatus{
+			PublicDockerImageRepository: "registry.svc.ci.openshift.org/ci-op-y2n8rsh3/pipeline",
+			Tags: []imageapi.NamedTagEventList{
+				{
+					Tag: "b",
+					Items: []imageapi.TagEvent{{
+						DockerImageReference: "docker-registry.default.svc:5000/ci-op-y2n8rsh3/pipeline@sha256:bbb",
+						Image:                "sha256:bbb",
+					}},
+				},
+				{
+					Tag: "d",
+					Items: []imageapi.TagEvent{{
+						DockerImageReference: "docker-registry.default.svc:5000/ci-op-y2n8rsh3/pipeline@sha256:ddd",
+						Image:                "sha256:ddd",
+					}},
+				},
+			},
+		},
+	}
+	tags := map[string][]api.ImageStreamTagReference{
+		"b":       {{Namespace: "ci", Name: "a", Tag: "latest"}, {Namespace: "ci", Name: "a", Tag: "4.8"}},
+		"d":       {{Namespace: "ci", Name: "c", Tag: "latest"}},
+		"missing": {{Namespace: "ci", Name: "e", Tag: "latest"}},
+	}
+	var testCases = []struct {
+		name     string
+		pipeline *imageapi.ImageStream
+		external api.ExternalRegistryPromotion
+		expected []externalCopy
+	}{
+		{
+			name:     "no pipeline",
+			external: api.ExternalRegistryPromotion{Registry: "quay.io"},
+		},
+		{
+			name:     "organization defaults to the namespace",
+			pipeline: pipeline,
+			external: api.ExternalRegistryPromotion{Registry: "quay.io"},
+			expected: []externalCopy{
+				{source: "registry.svc.ci.openshift.org/ci-op-y2n8rsh3/pipeline@sha256:bbb", destination: "quay.io/ci/a:4.8", digest: "sha256:bbb"},
+				{source: "registry.svc.ci.openshift.org/ci-op-y2n8rsh3/pipeline@sha256:bbb", destination: "quay.io/ci/a:latest", digest: "sha256:bbb"},
+				{source: "registry.svc.ci.openshift.org/ci-op-y2n8rsh3/pipeline@sha256:ddd", destination: "quay.io/ci/c:latest", digest: "sha256:ddd"},
+			},
+		},
+		{
+			name:     "organization is set",
+			pipeline: pipeline,
+			external: api.ExternalRegistryPromotion{Registry: "quay.io", Organization: "openshift"},
+			expected: []externalCopy{
+				{source: "registry.svc.ci.openshift.org/ci-op-y2n8rsh3/pipeline@sha256:bbb", destination: "quay.io/openshift/a:4.8", digest: "sha256:bbb"},
+				{source: "registry.svc.ci.openshift.org/ci-op-y2n8rsh3/pipeline@sha256:bbb", destination: "quay.io/openshift/a:latest", digest: "sha256:bbb"},
+				{source: "registry.svc.ci.openshift.org/ci-op-y2n8rsh3/pipeline@sha256:ddd", destination: "quay.io/openshift/c:latest", digest: "sha256:ddd"},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			actual := getExternalCopies(tags, testCase.pipeline, testCase.external)
+			if diff := cmp.Diff(testCase.expected, actual, cmp.AllowUnexported(externalCopy{})); diff != "" {
+				t.Errorf("got incorrect copies: %s", diff)
+			}
+		})
+	}
+}
+
 func TestGetImageMirror(t *testing.T) {
 	var testCases = []struct {
 		name     string
-		tags     map[string]api.ImageStreamTagReference
+		tags     map[string][]api.ImageStreamTagReference
 		pipeline *imageapi.ImageStream
-		expected map[string]string
+		expected map[string][]string
 	}{
 		{
 			name: "empty input",
@@ -535,17 +731,17 @@ func TestGetImageMirror(t *testing.T) {
 		},
 		{
 			name: "basic case",
-			tags: map[string]api.ImageStreamTagReference{
-				"b": {
+			tags: map[string][]api.ImageStreamTagReference{
+				"b": {{
 					Namespace: "ci",
 					Name:      "a",
 					Tag:       "latest",
-				},
-				"d": {
+				}},
+				"d": {{
 					Namespace: "ci",
 					Name:      "c",
 					Tag:       "latest",
-				},
+				}},
 			},
 			pipeline: &imageapi.ImageStream{
 				Status: imageapi.ImageStreamStatus{
@@ -569,9 +765,9 @@ func TestGetImageMirror(t *testing.T) {
 					},
 				},
 			},
-			expected: map[string]string{
-				"docker-registry.default.svc:5000/ci-op-y2n8rsh3/pipeline@sha256:bbb": "registry.ci.openshift.org/ci/a:latest",
-				"docker-registry.default.svc:5000/ci-op-y2n8rsh3/pipeline@sha256:ddd": "registry.ci.openshift.org/ci/c:latest",
+			expected: map[string][]string{
+				"docker-registry.default.svc:5000/ci-op-y2n8rsh3/pipeline@sha256:bbb": {"registry.ci.openshift.org/ci/a:latest"},
+				"docker-registry.default.svc:5000/ci-op-y2n8rsh3/pipeline@sha256:ddd": {"registry.ci.openshift.org/ci/c:latest"},
 			},
 		},
 	}
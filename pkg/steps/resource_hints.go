@@ -0,0 +1,48 @@
+package steps
+
+import (
+	"strconv"
+
+	coreapi "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	// CPUHintEnv exposes the number of cores a container may use, rounded up
+	CPUHintEnv = "RESOURCE_HINT_CPU"
+	// MemoryHintEnv exposes the memory limit of a container in bytes
+	MemoryHintEnv = "RESOURCE_HINT_MEMORY"
+	// goMemoryLimitRatio is the share of the memory limit Go programs are told to stay within,
+	// the rest is left for memory that is not managed by the Go runtime
+	goMemoryLimitRatio = 0.9
+)
+
+// resourceHintEnv returns environment variables that tell the processes in a container
+// how much of the node they may use. Go test suites otherwise size GOMAXPROCS after all
+// cores of the node, which oversubscribes the CPU on shared nodes. The hints are only
+// derived from limits, as requests do not bound what a container may use; no hints are
+// set for resources without a limit. Variables that are set later in the container, e.g.
+// through the environment of a step, take precedence.
+func resourceHintEnv(resources coreapi.ResourceRequirements) []coreapi.EnvVar {
+	var env []coreapi.EnvVar
+	if cpu, hasCPU := resources.Limits[coreapi.ResourceCPU]; hasCPU {
+		cores := strconv.FormatInt(cpuCores(cpu), 10)
+		env = append(env, coreapi.EnvVar{Name: "GOMAXPROCS", Value: cores}, coreapi.EnvVar{Name: CPUHintEnv, Value: cores})
+	}
+	if memory, hasMemory := resources.Limits[coreapi.ResourceMemory]; hasMemory && memory.Value() > 0 {
+		env = append(env,
+			coreapi.EnvVar{Name: "GOMEMLIMIT", Value: strconv.FormatInt(int64(float64(memory.Value())*goMemoryLimitRatio), 10)},
+			coreapi.EnvVar{Name: MemoryHintEnv, Value: strconv.FormatInt(memory.Value(), 10)},
+		)
+	}
+	return env
+}
+
+// cpuCores rounds the quantity up to full cores, but returns at least one
+func cpuCores(cpu resource.Quantity) int64 {
+	cores := (cpu.MilliValue() + 999) / 1000
+	if cores < 1 {
+		return 1
+	}
+	return cores
+}
@@ -0,0 +1,62 @@
+package steps
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	coreapi "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestResourceHintEnv(t *testing.T) {
+	var testCases = []struct {
+		name      string
+		resources coreapi.ResourceRequirements
+		expected  []coreapi.EnvVar
+	}{
+		{
+			name: "no resources",
+		},
+		{
+			name: "requests without limits mean no hints",
+			resources: coreapi.ResourceRequirements{
+				Requests: coreapi.ResourceList{coreapi.ResourceCPU: resource.MustParse("1500m"), coreapi.ResourceMemory: resource.MustParse("1Gi")},
+			},
+		},
+		{
+			name: "cpu limit is rounded up",
+			resources: coreapi.ResourceRequirements{
+				Limits: coreapi.ResourceList{coreapi.ResourceCPU: resource.MustParse("1500m")},
+			},
+			expected: []coreapi.EnvVar{{Name: "GOMAXPROCS", Value: "2"}, {Name: "RESOURCE_HINT_CPU", Value: "2"}},
+		},
+		{
+			name: "small cpu limit means one core",
+			resources: coreapi.ResourceRequirements{
+				Limits: coreapi.ResourceList{coreapi.ResourceCPU: resource.MustParse("10m")},
+			},
+			expected: []coreapi.EnvVar{{Name: "GOMAXPROCS", Value: "1"}, {Name: "RESOURCE_HINT_CPU", Value: "1"}},
+		},
+		{
+			name: "hints are derived from the limits rather than the requests",
+			resources: coreapi.ResourceRequirements{
+				Requests: coreapi.ResourceList{coreapi.ResourceCPU: resource.MustParse("1"), coreapi.ResourceMemory: resource.MustParse("1Gi")},
+				Limits:   coreapi.ResourceList{coreapi.ResourceCPU: resource.MustParse("4"), coreapi.ResourceMemory: resource.MustParse("2Gi")},
+			},
+			expected: []coreapi.EnvVar{
+				{Name: "GOMAXPROCS", Value: "4"},
+				{Name: "RESOURCE_HINT_CPU", Value: "4"},
+				{Name: "GOMEMLIMIT", Value: "1932735283"},
+				{Name: "RESOURCE_HINT_MEMORY", Value: "2147483648"},
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if diff := cmp.Diff(testCase.expected, resourceHintEnv(testCase.resources)); diff != "" {
+				t.Errorf("env differs from expected: %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package steps
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	coreapi "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/util/retry"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	imagev1 "github.com/openshift/api/image/v1"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/junit"
+)
+
+const (
+	// ResumeManifestName is the ConfigMap in the test namespace recording
+	// which steps completed, so that later runs can resume after them.
+	ResumeManifestName = "ci-operator-resume"
+	// resumeManifestKey holds the completed steps and when they finished
+	resumeManifestKey = "completed-steps.json"
+)
+
+// ResumeManifest records the steps that completed in a namespace.
+type ResumeManifest struct {
+	client    ctrlruntimeclient.Client
+	namespace string
+
+	lock      sync.Mutex
+	completed map[string]time.Time
+}
+
+// LoadResumeManifest reads the steps that completed in earlier runs in the
+// namespace. The manifest is empty when nothing ran in the namespace yet.
+func LoadResumeManifest(ctx context.Context, client ctrlruntimeclient.Client, namespace string) (*ResumeManifest, error) {
+	m := &ResumeManifest{client: client, namespace: namespace, completed: map[string]time.Time{}}
+	cm := &coreapi.ConfigMap{}
+	if err := client.Get(ctx, ctrlruntimeclient.ObjectKey{Namespace: namespace, Name: ResumeManifestName}, cm); err != nil {
+		if kerrors.IsNotFound(err) {
+			return m, nil
+		}
+		return nil, fmt.Errorf("could not get the resume manifest: %w", err)
+	}
+	if err := unmarshalCompletedSteps(cm, m.completed); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func unmarshalCompletedSteps(cm *coreapi.ConfigMap, into map[string]time.Time) error {
+	raw, ok := cm.Data[resumeManifestKey]
+	if !ok {
+		return nil
+	}
+	if err := json.Unmarshal([]byte(raw), &into); err != nil {
+		return fmt.Errorf("could not parse the resume manifest: %w", err)
+	}
+	return nil
+}
+
+func (m *ResumeManifest) isCompleted(step string) bool {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	_, completed := m.completed[step]
+	return completed
+}
+
+// record adds the step to the manifest. Jobs running in the same namespace
+// may update the manifest concurrently, so their records are merged.
+func (m *ResumeManifest) record(ctx context.Context, step string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.completed[step] = time.Now().UTC().Truncate(time.Second)
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		cm := &coreapi.ConfigMap{}
+		err := m.client.Get(ctx, ctrlruntimeclient.ObjectKey{Namespace: m.namespace, Name: ResumeManifestName}, cm)
+		if err != nil && !kerrors.IsNotFound(err) {
+			return fmt.Errorf("could not get the resume manifest: %w", err)
+		}
+		exists := err == nil
+		if err := unmarshalCompletedSteps(cm, m.completed); err != nil {
+			return err
+		}
+		raw, err := json.Marshal(m.completed)
+		if err != nil {
+			return fmt.Errorf("could not serialize the resume manifest: %w", err)
+		}
+		cm.Namespace, cm.Name = m.namespace, ResumeManifestName
+		cm.Data = map[string]string{resumeManifestKey: string(raw)}
+		if !exists {
+			return m.client.Create(ctx, cm)
+		}
+		return m.client.Update(ctx, cm)
+	})
+}
+
+// resumableStep skips the wrapped step if it completed in an earlier run and
+// the pipeline images it created still exist.
+type resumableStep struct {
+	manifest *ResumeManifest
+	wrapped  api.Step
+}
+
+// ResumableStep records when the wrapped step completes and skips it in later
+// runs in the same namespace. Steps that are not resumable are returned as-is.
+func ResumableStep(manifest *ResumeManifest, wrapped api.Step) api.Step {
+	if !resumable(wrapped) {
+		return wrapped
+	}
+	return &resumableStep{manifest: manifest, wrapped: wrapped}
+}
+
+// resumable determines whether the step can be skipped once it completed. Only
+// steps that create pipeline images are, as their images existing means their
+// work is still done. Tests, templates and leases always run again, as their
+// outcome is what the job reports.
+func resumable(step api.Step) bool {
+	for current := step; current != nil; {
+		switch current.(type) {
+		case *podStep, *templateExecutionStep, *multiStageTestStep, *leaseStep, *clusterClaimStep:
+			return false
+		}
+		wrapper, ok := current.(stepWrapper)
+		if !ok {
+			break
+		}
+		current = wrapper.unwrap()
+	}
+	for _, link := range step.Creates() {
+		if stream, _, ok := api.ImageStreamTagFor(link); ok && stream == api.PipelineImageStream {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *resumableStep) Inputs() (api.InputDefinition, error) { return s.wrapped.Inputs() }
+func (s *resumableStep) Validate() error                      { return s.wrapped.Validate() }
+func (s *resumableStep) Name() string                         { return s.wrapped.Name() }
+func (s *resumableStep) Description() string                  { return s.wrapped.Description() }
+func (s *resumableStep) Requires() []api.StepLink             { return s.wrapped.Requires() }
+func (s *resumableStep) Creates() []api.StepLink              { return s.wrapped.Creates() }
+func (s *resumableStep) Provides() api.ParameterMap           { return s.wrapped.Provides() }
+func (s *resumableStep) Objects() []ctrlruntimeclient.Object  { return s.wrapped.Objects() }
+func (s *resumableStep) unwrap() api.Step                     { return s.wrapped }
+
+func (s *resumableStep) SubTests() []*junit.TestCase {
+	if subTests, ok := s.wrapped.(subtestReporter); ok {
+		return subTests.SubTests()
+	}
+	return nil
+}
+
+func (s *resumableStep) SubSteps() []api.CIOperatorStepDetailInfo {
+	if subSteps, ok := s.wrapped.(SubStepReporter); ok {
+		return subSteps.SubSteps()
+	}
+	return nil
+}
+
+func (s *resumableStep) Run(ctx context.Context) error {
+	name := s.wrapped.Name()
+	if s.manifest.isCompleted(name) {
+		exist, err := s.outputsExist(ctx)
+		if err != nil {
+			logrus.WithError(err).Warnf("Could not determine whether the outputs of %s still exist, running it again.", name)
+		}
+		if exist {
+			logrus.Infof("Skipping %s as it completed in an earlier run.", name)
+			return nil
+		}
+	}
+	if err := s.wrapped.Run(ctx); err != nil {
+		return err
+	}
+	if err := s.manifest.record(ctx, name); err != nil {
+		logrus.WithError(err).Warnf("Failed to record that %s completed.", name)
+	}
+	return nil
+}
+
+// outputsExist determines whether the image stream tags the step created are
+// still in the namespace. Only resumable steps are wrapped, so the step always
+// created at least one pipeline image.
+func (s *resumableStep) outputsExist(ctx context.Context) (bool, error) {
+	for _, link := range s.wrapped.Creates() {
+		stream, tag, ok := api.ImageStreamTagFor(link)
+		if !ok {
+			continue
+		}
+		key := ctrlruntimeclient.ObjectKey{Namespace: s.manifest.namespace, Name: fmt.Sprintf("%s:%s", stream, tag)}
+		if err := s.manifest.client.Get(ctx, key, &imagev1.ImageStreamTag{}); err != nil {
+			if kerrors.IsNotFound(err) {
+				logrus.Debugf("Image stream tag %s/%s was removed since %s completed.", key.Namespace, key.Name, s.wrapped.Name())
+				return false, nil
+			}
+			return false, err
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,132 @@
+package steps
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	coreapi "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	imagev1 "github.com/openshift/api/image/v1"
+
+	"github.com/openshift/ci-tools/pkg/api"
+)
+
+func TestResumableStep(t *testing.T) {
+	manifest := &coreapi.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ci-op", Name: ResumeManifestName},
+		Data:       map[string]string{resumeManifestKey: `{"src":"2021-06-01T10:00:00Z","other":"2021-06-01T10:00:00Z"}`},
+	}
+	src := &imagev1.ImageStreamTag{ObjectMeta: metav1.ObjectMeta{Namespace: "ci-op", Name: "pipeline:src"}}
+
+	var testCases = []struct {
+		name            string
+		objects         []ctrlruntimeclient.Object
+		expectedRuns    int
+		expectedRecords []string
+	}{
+		{
+			name:            "step without a record runs and is recorded",
+			objects:         []ctrlruntimeclient.Object{src},
+			expectedRuns:    1,
+			expectedRecords: []string{"src"},
+		},
+		{
+			name:            "completed step whose image exists is skipped",
+			objects:         []ctrlruntimeclient.Object{manifest.DeepCopy(), src},
+			expectedRecords: []string{"other", "src"},
+		},
+		{
+			name:            "completed step whose image was removed runs again",
+			objects:         []ctrlruntimeclient.Object{manifest.DeepCopy()},
+			expectedRuns:    1,
+			expectedRecords: []string{"other", "src"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			client := fakectrlruntimeclient.NewClientBuilder().WithObjects(tc.objects...).Build()
+			loaded, err := LoadResumeManifest(ctx, client, "ci-op")
+			if err != nil {
+				t.Fatalf("unexpected error loading the manifest: %v", err)
+			}
+			wrapped := &fakeStep{name: "src", creates: []api.StepLink{api.InternalImageLink(api.PipelineImageStreamTagReferenceSource)}}
+			if err := ResumableStep(loaded, wrapped).Run(ctx); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if wrapped.numRuns != tc.expectedRuns {
+				t.Errorf("expected %d runs, got %d", tc.expectedRuns, wrapped.numRuns)
+			}
+
+			reloaded, err := LoadResumeManifest(ctx, client, "ci-op")
+			if err != nil {
+				t.Fatalf("unexpected error reloading the manifest: %v", err)
+			}
+			var records []string
+			for _, step := range []string{"other", "src"} {
+				if reloaded.isCompleted(step) {
+					records = append(records, step)
+				}
+			}
+			if diff := cmp.Diff(tc.expectedRecords, records); diff != "" {
+				t.Errorf("unexpected records: %s", diff)
+			}
+		})
+	}
+}
+
+func TestResumable(t *testing.T) {
+	pipelineImage := []api.StepLink{api.InternalImageLink(api.PipelineImageStreamTagReferenceSource)}
+	var testCases = []struct {
+		name     string
+		step     api.Step
+		expected bool
+	}{
+		{
+			name:     "step creating a pipeline image",
+			step:     &fakeStep{name: "src", creates: pipelineImage},
+			expected: true,
+		},
+		{
+			name:     "step creating a pipeline image behind a wrapper",
+			step:     &timeoutStep{wrapped: &fakeStep{name: "src", creates: pipelineImage}},
+			expected: true,
+		},
+		{
+			name: "step creating nothing",
+			step: &fakeStep{name: "nothing"},
+		},
+		{
+			name: "step creating a release image",
+			step: &fakeStep{name: "release", creates: []api.StepLink{api.ReleasePayloadImageLink("latest")}},
+		},
+		{
+			name: "test",
+			step: &podStep{name: "unit"},
+		},
+		{
+			name: "template",
+			step: &templateExecutionStep{},
+		},
+		{
+			name: "lease around a step creating a pipeline image",
+			step: &leaseStep{wrapped: &fakeStep{name: "src", creates: pipelineImage}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := resumable(tc.step); actual != tc.expected {
+				t.Errorf("expected resumable to be %t, got %t", tc.expected, actual)
+			}
+			_, wrapped := ResumableStep(&ResumeManifest{}, tc.step).(*resumableStep)
+			if wrapped != tc.expected {
+				t.Errorf("expected the step to be wrapped to be %t, got %t", tc.expected, wrapped)
+			}
+		})
+	}
+}
@@ -14,9 +14,17 @@ import (
 	"github.com/openshift/ci-tools/pkg/results"
 )
 
-func rpmInjectionDockerfile(from api.PipelineImageStreamTagReference, repo string) string {
+func rpmInjectionDockerfile(config api.RPMImageInjectionStepConfiguration, repo string) string {
+	baseURL := fmt.Sprintf("http://%s/", repo)
+	if config.PerArchitecture {
+		baseURL += "$basearch/"
+	}
+	settings := "gpgcheck = 0"
+	if config.Signed {
+		settings += fmt.Sprintf("\\nrepo_gpgcheck = 1\\ngpgkey = http://%s/%s", repo, RPMSigningPublicKey)
+	}
 	return fmt.Sprintf(`FROM %s:%s
-RUN echo $'[built]\nname = Built RPMs\nbaseurl = http://%s/\ngpgcheck = 0\nenabled = 0\n\n[origin-local-release]\nname = Built RPMs\nbaseurl = http://%s/\ngpgcheck = 0\nenabled = 0' > /etc/yum.repos.d/built.repo`, api.PipelineImageStream, from, repo, repo)
+RUN echo $'[built]\nname = Built RPMs\nbaseurl = %s\n%s\nenabled = 0\n\n[origin-local-release]\nname = Built RPMs\nbaseurl = %s\n%s\nenabled = 0' > /etc/yum.repos.d/built.repo`, api.PipelineImageStream, config.From, baseURL, settings, baseURL, settings)
 }
 
 type rpmImageInjectionStep struct {
@@ -31,7 +39,12 @@ func (s *rpmImageInjectionStep) Inputs() (api.InputDefinition, error) {
 	return nil, nil
 }
 
-func (*rpmImageInjectionStep) Validate() error { return nil }
+func (s *rpmImageInjectionStep) Validate() error {
+	if err := validatePipelineImageStreamTag("from", s.config.From); err != nil {
+		return err
+	}
+	return validateBuildTarget("to", s.config.To)
+}
 
 func (s *rpmImageInjectionStep) Run(ctx context.Context) error {
 	return results.ForReason("injecting_rpms").ForError(s.run(ctx))
@@ -43,7 +56,7 @@ func (s *rpmImageInjectionStep) run(ctx context.Context) error {
 		return fmt.Errorf("could not get Route for RPM server: %w", err)
 	}
 
-	dockerfile := rpmInjectionDockerfile(s.config.From, route.Spec.Host)
+	dockerfile := rpmInjectionDockerfile(s.config, route.Spec.Host)
 	fromDigest, err := resolvePipelineImageStreamTagReference(ctx, s.client, s.config.From, s.jobSpec)
 	if err != nil {
 		return err
@@ -0,0 +1,37 @@
+package steps
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/openshift/ci-tools/pkg/api"
+)
+
+func TestRPMInjectionDockerfile(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		config   api.RPMImageInjectionStepConfiguration
+		expected string
+	}{
+		{
+			name:   "single repository",
+			config: api.RPMImageInjectionStepConfiguration{From: "base-without-rpms", To: "base"},
+			expected: `FROM pipeline:base-without-rpms
+RUN echo $'[built]\nname = Built RPMs\nbaseurl = http://rpms.ci/\ngpgcheck = 0\nenabled = 0\n\n[origin-local-release]\nname = Built RPMs\nbaseurl = http://rpms.ci/\ngpgcheck = 0\nenabled = 0' > /etc/yum.repos.d/built.repo`,
+		},
+		{
+			name:   "signed repositories per architecture",
+			config: api.RPMImageInjectionStepConfiguration{From: "base-without-rpms", To: "base", PerArchitecture: true, Signed: true},
+			expected: `FROM pipeline:base-without-rpms
+RUN echo $'[built]\nname = Built RPMs\nbaseurl = http://rpms.ci/$basearch/\ngpgcheck = 0\nrepo_gpgcheck = 1\ngpgkey = http://rpms.ci/RPM-GPG-KEY\nenabled = 0\n\n[origin-local-release]\nname = Built RPMs\nbaseurl = http://rpms.ci/$basearch/\ngpgcheck = 0\nrepo_gpgcheck = 1\ngpgkey = http://rpms.ci/RPM-GPG-KEY\nenabled = 0' > /etc/yum.repos.d/built.repo`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.expected, rpmInjectionDockerfile(tc.config, "rpms.ci")); diff != "" {
+				t.Errorf("unexpected Dockerfile: %s", diff)
+			}
+		})
+	}
+}
@@ -27,12 +27,26 @@ import (
 	"github.com/openshift/ci-tools/pkg/api"
 	"github.com/openshift/ci-tools/pkg/results"
 	"github.com/openshift/ci-tools/pkg/steps/loggingclient"
+	"github.com/openshift/ci-tools/pkg/util"
 )
 
 const (
 	RPMRepoName    = "rpm-repo"
 	AppLabel       = "app"
 	TTLIgnoreLabel = "ci.openshift.io/ttl.ignore"
+
+	// rpmPreparedRepoLocation is where the repositories are prepared
+	// when they are served per architecture or signed
+	rpmPreparedRepoLocation = "/srv/prepared-repo"
+	// rpmSigningKeyLocation is where the signing key is mounted
+	rpmSigningKeyLocation = "/etc/rpm-signing"
+	// rpmSigningImage signs the repository metadata. The key must never be
+	// mounted into images built from the code under test, so signing runs
+	// in this image instead.
+	rpmSigningImage = "registry.access.redhat.com/ubi8/ubi:8.4"
+	// RPMSigningPublicKey is the file the public key that the repository
+	// metadata is signed with is served as
+	RPMSigningPublicKey = "RPM-GPG-KEY"
 )
 
 type rpmServerStep struct {
@@ -59,6 +73,13 @@ func (s *rpmServerStep) run(ctx context.Context) error {
 		ist); err != nil {
 		return fmt.Errorf("could not find source ImageStreamTag for RPM repo deployment: %w", err)
 	}
+	if signing := s.config.Signing; signing != nil {
+		if err := util.CopySecretsIntoJobNamespace(ctx, s.client, s.jobSpec, map[string]ctrlruntimeclient.ObjectKey{
+			rpmSigningSecretName(*signing): {Namespace: signing.Namespace, Name: signing.Name},
+		}); err != nil {
+			return fmt.Errorf("could not copy the RPM signing key: %w", err)
+		}
+	}
 
 	labelSet := labelsFor(s.jobSpec, map[string]string{AppLabel: RPMRepoName, TTLIgnoreLabel: "true"})
 	selectorSet := map[string]string{
@@ -190,6 +211,9 @@ fi
 			},
 		},
 	}
+	if len(s.config.Architectures) > 0 || s.config.Signing != nil {
+		prepareRPMRepo(&deployment.Spec.Template.Spec, s.config)
+	}
 	if owner := s.jobSpec.Owner(); owner != nil {
 		deployment.OwnerReferences = append(deployment.OwnerReferences, *owner)
 	}
@@ -240,6 +264,98 @@ fi
 	return waitForRouteReachable(ctx, s.client, s.jobSpec.Namespace(), route.Name, "http")
 }
 
+// rpmArchitectures maps architectures to the names RPM uses for them
+var rpmArchitectures = map[api.ReleaseArchitecture]string{
+	api.ReleaseArchitectureAMD64:   "x86_64",
+	api.ReleaseArchitectureARM64:   "aarch64",
+	api.ReleaseArchitecturePPC64le: "ppc64le",
+	api.ReleaseArchitectureS390x:   "s390x",
+}
+
+func rpmSigningSecretName(signing api.RPMSigningConfiguration) string {
+	return fmt.Sprintf("%s-%s", signing.Namespace, signing.Name)
+}
+
+// prepareRPMRepo adds an init container to the RPM server that sorts the RPMs
+// into one repository per architecture and another one that signs the
+// repository metadata. The server then serves the prepared repositories instead
+// of the built ones. All replicas need to serve identical metadata, so its
+// timestamps are fixed.
+func prepareRPMRepo(spec *coreapi.PodSpec, config api.RPMServeStepConfiguration) {
+	script := []string{
+		"set -o errexit",
+		"set -o nounset",
+		"set -o pipefail",
+	}
+	var repos []string
+	if len(config.Architectures) == 0 {
+		script = append(script,
+			fmt.Sprintf("ln -s %s/* %s/", api.RPMServeLocation, rpmPreparedRepoLocation),
+			fmt.Sprintf("rm %s/repodata", rpmPreparedRepoLocation),
+			fmt.Sprintf("cp -r %s/repodata %s/repodata", api.RPMServeLocation, rpmPreparedRepoLocation),
+		)
+		repos = append(repos, rpmPreparedRepoLocation)
+	}
+	for _, architecture := range config.Architectures {
+		repo := fmt.Sprintf("%s/%s", rpmPreparedRepoLocation, rpmArchitectures[architecture])
+		script = append(script,
+			fmt.Sprintf("mkdir -p %s", repo),
+			fmt.Sprintf(`find -L %s \( -name '*.%s.rpm' -o -name '*.noarch.rpm' \) -exec ln -sf -t %s {} +`, api.RPMServeLocation, rpmArchitectures[architecture], repo),
+			fmt.Sprintf("createrepo_c --no-database --revision 1 --set-timestamp-to-revision %s", repo),
+		)
+		repos = append(repos, repo)
+	}
+	server := &spec.Containers[0]
+	prepared := coreapi.VolumeMount{Name: "prepared-repo", MountPath: rpmPreparedRepoLocation}
+	spec.Volumes = append(spec.Volumes, coreapi.Volume{Name: prepared.Name, VolumeSource: coreapi.VolumeSource{EmptyDir: &coreapi.EmptyDirVolumeSource{}}})
+	spec.InitContainers = append(spec.InitContainers, coreapi.Container{
+		Name:         "prepare-repo",
+		Image:        server.Image,
+		Command:      []string{"/bin/bash", "-c"},
+		Args:         []string{strings.Join(script, "\n")},
+		VolumeMounts: []coreapi.VolumeMount{prepared},
+		Resources:    server.Resources,
+	})
+	if config.Signing != nil {
+		spec.Volumes = append(spec.Volumes, coreapi.Volume{Name: "signing-key", VolumeSource: coreapi.VolumeSource{
+			Secret: &coreapi.SecretVolumeSource{SecretName: rpmSigningSecretName(*config.Signing)},
+		}})
+		spec.InitContainers = append(spec.InitContainers, coreapi.Container{
+			Name:         "sign-repo",
+			Image:        rpmSigningImage,
+			Command:      []string{"/bin/bash", "-c"},
+			Args:         []string{signRPMRepoScript(*config.Signing, repos)},
+			VolumeMounts: []coreapi.VolumeMount{prepared, {Name: "signing-key", MountPath: rpmSigningKeyLocation, ReadOnly: true}},
+			Resources:    server.Resources,
+		})
+	}
+	server.WorkingDir = rpmPreparedRepoLocation
+	server.VolumeMounts = append(server.VolumeMounts, prepared)
+}
+
+// signRPMRepoScript signs the metadata of the repositories and exports the
+// public key. The repositories were prepared from the code under test, so only
+// regular files within the prepared repositories are signed.
+func signRPMRepoScript(signing api.RPMSigningConfiguration, repos []string) string {
+	script := []string{
+		"set -o errexit",
+		"set -o nounset",
+		"set -o pipefail",
+		`export GNUPGHOME="$( mktemp -d )"`,
+		fmt.Sprintf("gpg --batch --import %s/%s", rpmSigningKeyLocation, signing.Key),
+		fmt.Sprintf("rm -f %s/%s", rpmPreparedRepoLocation, RPMSigningPublicKey),
+		fmt.Sprintf("gpg --batch --export --armor > %s/%s", rpmPreparedRepoLocation, RPMSigningPublicKey),
+	}
+	for _, repo := range repos {
+		script = append(script,
+			fmt.Sprintf(`repomd="$( realpath -e %s/repodata/repomd.xml )"`, repo),
+			fmt.Sprintf(`[[ "${repomd}" == %s/* && -f "${repomd}" ]]`, rpmPreparedRepoLocation),
+			`gpg --batch --yes --detach-sign --armor "${repomd}"`,
+		)
+	}
+	return strings.Join(script, "\n")
+}
+
 func waitForDeployment(ctx context.Context, client ctrlruntimeclient.Client, name string) error {
 	return waitForDeploymentOrTimeout(ctx, client, name)
 }
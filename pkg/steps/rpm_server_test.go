@@ -0,0 +1,51 @@
+package steps
+
+import (
+	"testing"
+
+	coreapi "k8s.io/api/core/v1"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/testhelper"
+)
+
+func TestPrepareRPMRepo(t *testing.T) {
+	var testCases = []struct {
+		name   string
+		config api.RPMServeStepConfiguration
+	}{
+		{
+			name: "repositories per architecture",
+			config: api.RPMServeStepConfiguration{
+				From:          api.PipelineImageStreamTagReferenceRPMs,
+				Architectures: []api.ReleaseArchitecture{api.ReleaseArchitectureAMD64, api.ReleaseArchitectureARM64},
+			},
+		},
+		{
+			name: "signed repository",
+			config: api.RPMServeStepConfiguration{
+				From:    api.PipelineImageStreamTagReferenceRPMs,
+				Signing: &api.RPMSigningConfiguration{Namespace: "ci", Name: "rpm-signing", Key: "private.key"},
+			},
+		},
+		{
+			name: "signed repositories per architecture",
+			config: api.RPMServeStepConfiguration{
+				From:          api.PipelineImageStreamTagReferenceRPMs,
+				Architectures: []api.ReleaseArchitecture{api.ReleaseArchitectureAMD64, api.ReleaseArchitectureS390x},
+				Signing:       &api.RPMSigningConfiguration{Namespace: "ci", Name: "rpm-signing", Key: "private.key"},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			spec := &coreapi.PodSpec{Containers: []coreapi.Container{{
+				Name:       RPMRepoName,
+				Image:      "registry/ci-op/pipeline@sha256:rpms",
+				WorkingDir: api.RPMServeLocation,
+			}}}
+			prepareRPMRepo(spec, tc.config)
+			testhelper.CompareWithFixture(t, spec)
+		})
+	}
+}
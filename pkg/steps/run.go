@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/openshift/ci-tools/pkg/api"
@@ -19,7 +20,9 @@ type message struct {
 	stepDetails     api.CIOperatorStepDetails
 }
 
-func Run(ctx context.Context, graph []*api.StepNode) (*junit.TestSuites, []api.CIOperatorStepDetails, []error) {
+// Run executes the graph and records the outcome of every step in the
+// metrics, which may be nil.
+func Run(ctx context.Context, graph []*api.StepNode, metrics *StepMetrics) (*junit.TestSuites, []api.CIOperatorStepDetails, []error) {
 	var seen []api.StepLink
 	executionResults := make(chan message)
 	done := make(chan bool)
@@ -34,7 +37,7 @@ func Run(ctx context.Context, graph []*api.StepNode) (*junit.TestSuites, []api.C
 
 	start := time.Now()
 	for _, root := range graph {
-		go runStep(ctx, root, executionResults)
+		go runStep(ctx, root, metrics, executionResults)
 	}
 
 	suites := &junit.TestSuites{
@@ -68,7 +71,7 @@ func Run(ctx context.Context, graph []*api.StepNode) (*junit.TestSuites, []api.C
 						// when the last of its parents finishes.
 						if api.HasAllLinks(child.Step.Requires(), seen) {
 							wg.Add(1)
-							go runStep(ctx, child, executionResults)
+							go runStep(ctx, child, metrics, executionResults)
 						}
 					}
 				}
@@ -114,8 +117,9 @@ type SubStepReporter interface {
 	SubSteps() []api.CIOperatorStepDetailInfo
 }
 
-func runStep(ctx context.Context, node *api.StepNode, out chan<- message) {
+func runStep(ctx context.Context, node *api.StepNode, metrics *StepMetrics, out chan<- message) {
 	start := time.Now()
+	ctx, retries := withRetryCounter(ctx)
 	err := node.Step.Run(ctx)
 	var additionalTests []*junit.TestCase
 	if reporter, ok := node.Step.(subtestReporter); ok {
@@ -124,6 +128,7 @@ func runStep(ctx context.Context, node *api.StepNode, out chan<- message) {
 	duration := time.Since(start)
 	failed := err != nil
 	finishedAt := start.Add(duration)
+	metrics.observe(node.Step, start, duration, atomic.LoadInt64(retries), err)
 
 	var subSteps []api.CIOperatorStepDetailInfo
 	if x, ok := node.Step.(SubStepReporter); ok {
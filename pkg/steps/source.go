@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path"
 	"sort"
 	"strings"
 	"time"
@@ -19,7 +20,9 @@ import (
 	"k8s.io/apimachinery/pkg/util/wait"
 	prowv1 "k8s.io/test-infra/prow/apis/prowjobs/v1"
 	"k8s.io/test-infra/prow/clonerefs"
+	"k8s.io/test-infra/prow/pod-utils/clone"
 	"k8s.io/test-infra/prow/pod-utils/decorate"
+	"k8s.io/test-infra/prow/pod-utils/downwardapi"
 	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 
 	buildapi "github.com/openshift/api/build/v1"
@@ -46,8 +49,16 @@ const (
 	OauthSecretKey = "oauth-token"
 
 	PullSecretName = "registry-pull-credentials"
+
+	// EntitlementSecretNamespace and EntitlementSecretName identify the shared
+	// secret holding the subscription entitlement for builds that need RHEL content
+	EntitlementSecretNamespace = "ci"
+	EntitlementSecretName      = "etc-pki-entitlement"
 )
 
+// entitlementSecretKeys are the files the entitlement secret must provide
+var entitlementSecretKeys = []string{"entitlement.pem", "entitlement-key.pem", "redhat-uep.pem"}
+
 type CloneAuthType string
 
 var (
@@ -71,7 +82,7 @@ var (
 	JobSpecAnnotation = fmt.Sprintf("%s/%s", CiAnnotationPrefix, "job-spec")
 )
 
-func sourceDockerfile(fromTag api.PipelineImageStreamTagReference, workingDir string, cloneAuthConfig *CloneAuthConfig) string {
+func sourceDockerfile(fromTag api.PipelineImageStreamTagReference, workingDir string, cloneAuthConfig *CloneAuthConfig, refs []prowv1.Refs, filter string) string {
 	var dockerCommands []string
 	var secretPath string
 
@@ -91,7 +102,14 @@ func sourceDockerfile(fromTag api.PipelineImageStreamTagReference, workingDir st
 		}
 	}
 
-	dockerCommands = append(dockerCommands, fmt.Sprintf("RUN umask 0002 && /clonerefs && find %s/src -type d -not -perm -0775 | xargs --max-procs 10 --max-args 100 --no-run-if-empty chmod g+xw", gopath))
+	cloneCommand := "/clonerefs"
+	if filter != "" && len(refs) > 0 {
+		dockerCommands = append(dockerCommands, partialCloneCommand(refs, filter))
+		if cloneAuthConfig != nil && cloneAuthConfig.Type == CloneAuthTypeOAuth {
+			cloneCommand = oauthHeaderCloneCommand
+		}
+	}
+	dockerCommands = append(dockerCommands, fmt.Sprintf("RUN umask 0002 && %s && find %s/src -type d -not -perm -0775 | xargs --max-procs 10 --max-args 100 --no-run-if-empty chmod g+xw", cloneCommand, gopath))
 	dockerCommands = append(dockerCommands, fmt.Sprintf("WORKDIR %s/", workingDir))
 	dockerCommands = append(dockerCommands, fmt.Sprintf("ENV GOPATH=%s", gopath))
 
@@ -106,6 +124,46 @@ func sourceDockerfile(fromTag api.PipelineImageStreamTagReference, workingDir st
 	return strings.Join(dockerCommands, "\n")
 }
 
+// partialCloneCommand returns the command that makes clonerefs create partial clones
+// with the filter. clonerefs fetches from the URLs of the repositories rather than from
+// named remotes, so the repositories are initialized beforehand with their URLs set up
+// as promisor remotes, which git then uses for the fetches of clonerefs.
+func partialCloneCommand(refs []prowv1.Refs, filter string) string {
+	var setup []string
+	for _, r := range refs {
+		dir := clone.PathForRefs(gopath, r)
+		uri := repositoryURI(r)
+		setup = append(setup,
+			fmt.Sprintf("git init --quiet %s", dir),
+			fmt.Sprintf("git -C %s config core.repositoryformatversion 1", dir),
+			fmt.Sprintf(`git -C %s config extensions.partialClone "%s"`, dir, uri),
+			fmt.Sprintf(`git -C %s config "remote.%s.promisor" true`, dir, uri),
+			fmt.Sprintf(`git -C %s config "remote.%s.partialclonefilter" %s`, dir, uri, filter),
+		)
+	}
+	return "RUN umask 0002 && " + strings.Join(setup, " && ")
+}
+
+// oauthHeaderCloneCommand runs clonerefs for partial clones with OAuth. clonerefs would put
+// the token into the URLs it fetches from, which then would not match the promisor remotes,
+// so the token is passed to the fetches as an HTTP header in the environment instead. This
+// also keeps the token out of the git config of the repositories.
+const oauthHeaderCloneCommand = `token="$(tr -d '[:space:]' < ` + oauthToken + `)" && ` +
+	`GIT_CONFIG_COUNT=1 GIT_CONFIG_KEY_0=http.extraHeader ` +
+	`GIT_CONFIG_VALUE_0="Authorization: Basic $(printf '%s:x-oauth-basic' "${token}" | base64 -w 0)" /clonerefs`
+
+// repositoryURI returns the URL clonerefs fetches the refs from, without credentials
+func repositoryURI(refs prowv1.Refs) string {
+	switch {
+	case refs.CloneURI != "":
+		return refs.CloneURI
+	case refs.RepoLink != "":
+		return refs.RepoLink + ".git"
+	default:
+		return fmt.Sprintf("https://github.com/%s/%s.git", refs.Org, refs.Repo)
+	}
+}
+
 const (
 	LabelMetadataOrg     = "ci.openshift.io/metadata.org"
 	LabelMetadataRepo    = "ci.openshift.io/metadata.repo"
@@ -142,13 +200,26 @@ func (s *sourceStep) Inputs() (api.InputDefinition, error) {
 	return s.jobSpec.Inputs(), nil
 }
 
-func (*sourceStep) Validate() error { return nil }
+func (s *sourceStep) Validate() error {
+	if err := validatePipelineImageStreamTag("from", s.config.From); err != nil {
+		return err
+	}
+	return validateBuildTarget("to", s.config.To)
+}
 
 func (s *sourceStep) Run(ctx context.Context) error {
 	return results.ForReason("cloning_source").ForError(s.run(ctx))
 }
 
 func (s *sourceStep) run(ctx context.Context) error {
+	if s.config.SourceArchive != nil {
+		fromDigest, err := resolvePipelineImageStreamTagReference(ctx, s.client, s.config.From, s.jobSpec)
+		if err != nil {
+			return err
+		}
+		return handleBuild(ctx, s.client, createArchiveBuild(s.config, s.jobSpec, s.resources, s.pullSecret, fromDigest))
+	}
+
 	clonerefsRef, err := istObjectReference(ctx, s.client, s.config.ClonerefsImage)
 	if err != nil {
 		return fmt.Errorf("could not resolve clonerefs source: %w", err)
@@ -168,17 +239,23 @@ func createBuild(config api.SourceStepConfiguration, jobSpec *api.JobSpec, clone
 		if cloneAuthConfig != nil {
 			r.CloneURI = cloneAuthConfig.getCloneURI(r.Org, r.Repo)
 		}
+		applyCloneConfiguration(&r, config.Clone)
 		refs = append(refs, r)
 	}
 
-	for _, r := range jobSpec.ExtraRefs {
+	for _, r := range append(append([]prowv1.Refs{}, jobSpec.ExtraRefs...), config.ExtraRefs...) {
 		if cloneAuthConfig != nil {
 			r.CloneURI = cloneAuthConfig.getCloneURI(r.Org, r.Repo)
 		}
+		applyCloneConfiguration(&r, config.Clone)
 		refs = append(refs, r)
 	}
 
-	dockerfile := sourceDockerfile(config.From, decorate.DetermineWorkDir(gopath, refs), cloneAuthConfig)
+	var filter string
+	if config.Clone != nil {
+		filter = config.Clone.Filter
+	}
+	dockerfile := sourceDockerfile(config.From, decorate.DetermineWorkDir(gopath, refs), cloneAuthConfig, refs, filter)
 	buildSource := buildapi.BuildSource{
 		Type:       buildapi.BuildSourceDockerfile,
 		Dockerfile: &dockerfile,
@@ -218,9 +295,9 @@ func createBuild(config api.SourceStepConfiguration, jobSpec *api.JobSpec, clone
 				}
 			}
 			optionsSpec.KeyFiles = append(optionsSpec.KeyFiles, sshPrivateKey)
-		} else {
+		} else if filter == "" {
+			// partial clones pass the token to the fetches themselves
 			optionsSpec.OauthTokenFile = oauthToken
-
 		}
 	}
 
@@ -238,6 +315,67 @@ func createBuild(config api.SourceStepConfiguration, jobSpec *api.JobSpec, clone
 	return build
 }
 
+// applyCloneConfiguration configures clonerefs to clone the refs as configured,
+// unless the refs already ask for a different depth or to skip submodules
+func applyCloneConfiguration(refs *prowv1.Refs, clone *api.CloneConfiguration) {
+	if clone == nil {
+		return
+	}
+	if refs.CloneDepth == 0 {
+		refs.CloneDepth = clone.Depth
+	}
+	if clone.SkipSubmodules {
+		refs.SkipSubmodules = true
+	}
+}
+
+// archiveDockerfile extracts the source archive into the working directory. ADD
+// transparently extracts local archives, so no tools are needed in the base image.
+func archiveDockerfile(fromTag api.PipelineImageStreamTagReference, workingDir, archive string) string {
+	return strings.Join([]string{
+		"",
+		fmt.Sprintf("FROM %s:%s", api.PipelineImageStream, fromTag),
+		fmt.Sprintf("ADD ./%s %s/", archive, workingDir),
+		fmt.Sprintf("RUN find %s/src -type d -not -perm -0775 | xargs --max-procs 10 --max-args 100 --no-run-if-empty chmod g+xw", gopath),
+		fmt.Sprintf("WORKDIR %s/", workingDir),
+		fmt.Sprintf("ENV GOPATH=%s", gopath),
+		"",
+	}, "\n")
+}
+
+func createArchiveBuild(config api.SourceStepConfiguration, jobSpec *api.JobSpec, resources api.ResourceConfiguration, pullSecret *corev1.Secret, fromDigest string) *buildapi.Build {
+	var refs []prowv1.Refs
+	if jobSpec.Refs != nil {
+		refs = append(refs, *jobSpec.Refs)
+	}
+	refs = append(refs, jobSpec.ExtraRefs...)
+	workingDir := fmt.Sprintf("%s/src", gopath)
+	if len(refs) > 0 {
+		workingDir = decorate.DetermineWorkDir(gopath, refs)
+	}
+
+	dockerfile := archiveDockerfile(config.From, workingDir, path.Base(config.SourceArchive.Path))
+	buildSource := buildapi.BuildSource{
+		Type:       buildapi.BuildSourceDockerfile,
+		Dockerfile: &dockerfile,
+		Images: []buildapi.ImageSource{
+			{
+				From: corev1.ObjectReference{
+					Kind: "ImageStreamTag",
+					Name: fmt.Sprintf("%s:%s", api.PipelineImageStream, config.SourceArchive.From),
+				},
+				Paths: []buildapi.ImageSourcePath{
+					{
+						SourcePath:     config.SourceArchive.Path,
+						DestinationDir: ".",
+					},
+				},
+			},
+		},
+	}
+	return buildFromSource(jobSpec, config.From, config.To, buildSource, fromDigest, "", resources, pullSecret, nil)
+}
+
 func resolvePipelineImageStreamTagReference(ctx context.Context, client loggingclient.LoggingClient, tag api.PipelineImageStreamTagReference, jobSpec *api.JobSpec) (string, error) {
 	ist := &imagev1.ImageStreamTag{}
 	if err := client.Get(ctx, ctrlruntimeclient.ObjectKey{Namespace: jobSpec.Namespace(), Name: fmt.Sprintf("%s:%s", api.PipelineImageStream, tag)}, ist); err != nil {
@@ -261,6 +399,9 @@ func buildFromSource(jobSpec *api.JobSpec, fromTag, toTag api.PipelineImageStrea
 		}
 	}
 
+	// Dockerfiles can consume the resource hints by declaring them as ARG
+	buildArgEnv := append(resourceHintEnv(buildResources), toEnv(buildArgs, jobSpec)...)
+
 	layer := buildapi.ImageOptimizationSkipLayers
 	labels := labelsFor(jobSpec, map[string]string{CreatesLabel: string(toTag)})
 	build := &buildapi.Build{
@@ -285,7 +426,7 @@ func buildFromSource(jobSpec *api.JobSpec, fromTag, toTag api.PipelineImageStrea
 						NoCache:                 true,
 						Env:                     []corev1.EnvVar{{Name: "BUILD_LOGLEVEL", Value: "0"}}, // this mirrors the default and is done for documentary purposes
 						ImageOptimizationPolicy: &layer,
-						BuildArgs:               toEnv(buildArgs),
+						BuildArgs:               buildArgEnv,
 					},
 				},
 				Output: buildapi.BuildOutput{
@@ -315,9 +456,20 @@ func buildFromSource(jobSpec *api.JobSpec, fromTag, toTag api.PipelineImageStrea
 	return build
 }
 
-func toEnv(args []api.BuildArg) []corev1.EnvVar {
+func toEnv(args []api.BuildArg, jobSpec *api.JobSpec) []corev1.EnvVar {
 	var ret []corev1.EnvVar
+	var jobEnv map[string]string
 	for i, arg := range args {
+		if arg.FromJobSpec != "" {
+			if jobEnv == nil {
+				var err error
+				if jobEnv, err = downwardapi.EnvForSpec(jobSpec.JobSpec); err != nil {
+					logrus.WithError(err).Warnf("build_args[%d]: failed to determine the variables of the job", i)
+				}
+			}
+			ret = append(ret, corev1.EnvVar{Name: arg.Name, Value: jobEnv[arg.FromJobSpec]})
+			continue
+		}
 		if arg.ValueFrom == nil {
 			ret = append(ret, corev1.EnvVar{Name: arg.Name, Value: arg.Value})
 			continue
@@ -391,6 +543,7 @@ func handleBuild(ctx context.Context, buildClient BuildClient, build *buildapi.B
 		if isBuildPhaseTerminated(b.Status.Phase) &&
 			(isInfraReason(b.Status.Reason) || hintsAtInfraReason(b.Status.LogSnippet)) {
 			logrus.Infof("Build %s previously failed from an infrastructure error (%s), retrying...", b.Name, b.Status.Reason)
+			RecordRetry(ctx)
 			zero := int64(0)
 			foreground := metav1.DeletePropagationForeground
 			opts := metav1.DeleteOptions{
@@ -588,7 +741,11 @@ func resourcesFor(req api.ResourceRequirements) (corev1.ResourceRequirements, er
 }
 
 func (s *sourceStep) Requires() []api.StepLink {
-	return []api.StepLink{api.InternalImageLink(s.config.From)}
+	links := []api.StepLink{api.InternalImageLink(s.config.From)}
+	if s.config.SourceArchive != nil {
+		links = append(links, api.InternalImageLink(s.config.SourceArchive.From))
+	}
+	return links
 }
 
 func (s *sourceStep) Creates() []api.StepLink {
@@ -604,6 +761,9 @@ func (s *sourceStep) Provides() api.ParameterMap {
 func (s *sourceStep) Name() string { return string(s.config.To) }
 
 func (s *sourceStep) Description() string {
+	if s.config.SourceArchive != nil {
+		return fmt.Sprintf("Extract the source archive from %s into an image and tag it as %s", s.config.SourceArchive.From, s.config.To)
+	}
 	return fmt.Sprintf("Clone the correct source code into an image and tag it as %s", s.config.To)
 }
 
@@ -3,6 +3,8 @@ package steps
 import (
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
+
 	coreapi "k8s.io/api/core/v1"
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
 	prowapi "k8s.io/test-infra/prow/apis/prowjobs/v1"
@@ -283,6 +285,157 @@ func TestCreateBuild(t *testing.T) {
 			clonerefsRef: coreapi.ObjectReference{Kind: "ImageStreamTag", Name: "clonerefs:latest", Namespace: "ci"},
 			resources:    map[string]api.ResourceRequirements{"*": {Requests: map[string]string{"cpu": "200m"}}},
 		},
+		{
+			name: "source snapshot with extra refs",
+			config: api.SourceStepConfiguration{
+				From: api.PipelineImageStreamTagReferenceRoot,
+				To:   api.SourceSnapshotName("other-pr"),
+				ClonerefsImage: api.ImageStreamTagReference{
+					Namespace: "ci",
+					Name:      "clonerefs",
+					Tag:       "latest",
+				},
+				ClonerefsPath: "/clonerefs",
+				ExtraRefs: []prowapi.Refs{{
+					Org:     "other-org",
+					Repo:    "other-repo",
+					BaseRef: "main",
+					Pulls:   []prowapi.Pull{{Number: 42}},
+				}},
+			},
+			jobSpec: &api.JobSpec{
+				JobSpec: downwardapi.JobSpec{
+					Job:       "job",
+					BuildID:   "buildId",
+					ProwJobID: "prowJobId",
+					Refs: &prowapi.Refs{
+						Org:     "org",
+						Repo:    "repo",
+						BaseRef: "master",
+						BaseSHA: "masterSHA",
+						Pulls: []prowapi.Pull{{
+							Number: 1,
+							SHA:    "pullSHA",
+						}},
+					},
+				},
+			},
+			clonerefsRef: coreapi.ObjectReference{Kind: "ImageStreamTag", Name: "clonerefs:latest", Namespace: "ci"},
+			resources:    map[string]api.ResourceRequirements{"*": {Requests: map[string]string{"cpu": "200m"}}},
+		},
+		{
+			name: "shallow clone without submodules",
+			config: api.SourceStepConfiguration{
+				From: api.PipelineImageStreamTagReferenceRoot,
+				To:   api.PipelineImageStreamTagReferenceSource,
+				ClonerefsImage: api.ImageStreamTagReference{
+					Namespace: "ci",
+					Name:      "clonerefs",
+					Tag:       "latest",
+				},
+				ClonerefsPath: "/clonerefs",
+				Clone:         &api.CloneConfiguration{Depth: 50, SkipSubmodules: true},
+			},
+			jobSpec: &api.JobSpec{
+				JobSpec: downwardapi.JobSpec{
+					Job:       "job",
+					BuildID:   "buildId",
+					ProwJobID: "prowJobId",
+					Refs: &prowapi.Refs{
+						Org:     "org",
+						Repo:    "repo",
+						BaseRef: "master",
+						BaseSHA: "masterSHA",
+						Pulls: []prowapi.Pull{{
+							Number: 1,
+							SHA:    "pullSHA",
+						}},
+					},
+					ExtraRefs: []prowapi.Refs{{
+						Org:        "org",
+						Repo:       "other",
+						BaseRef:    "master",
+						CloneDepth: 1,
+					}},
+				},
+			},
+			clonerefsRef: coreapi.ObjectReference{Kind: "ImageStreamTag", Name: "clonerefs:latest", Namespace: "ci"},
+			resources:    map[string]api.ResourceRequirements{"*": {Requests: map[string]string{"cpu": "200m"}}},
+		},
+		{
+			name: "partial clone",
+			config: api.SourceStepConfiguration{
+				From: api.PipelineImageStreamTagReferenceRoot,
+				To:   api.PipelineImageStreamTagReferenceSource,
+				ClonerefsImage: api.ImageStreamTagReference{
+					Namespace: "ci",
+					Name:      "clonerefs",
+					Tag:       "latest",
+				},
+				ClonerefsPath: "/clonerefs",
+				Clone:         &api.CloneConfiguration{Filter: "blob:none"},
+			},
+			jobSpec: &api.JobSpec{
+				JobSpec: downwardapi.JobSpec{
+					Job:       "job",
+					BuildID:   "buildId",
+					ProwJobID: "prowJobId",
+					Refs: &prowapi.Refs{
+						Org:     "org",
+						Repo:    "repo",
+						BaseRef: "master",
+						BaseSHA: "masterSHA",
+						Pulls: []prowapi.Pull{{
+							Number: 1,
+							SHA:    "pullSHA",
+						}},
+					},
+					ExtraRefs: []prowapi.Refs{{
+						Org:       "org",
+						Repo:      "other",
+						BaseRef:   "master",
+						PathAlias: "example.com/other",
+					}},
+				},
+			},
+			clonerefsRef: coreapi.ObjectReference{Kind: "ImageStreamTag", Name: "clonerefs:latest", Namespace: "ci"},
+			resources:    map[string]api.ResourceRequirements{"*": {Requests: map[string]string{"cpu": "200m"}}},
+		},
+		{
+			name: "partial clone with OAuth token",
+			config: api.SourceStepConfiguration{
+				From: api.PipelineImageStreamTagReferenceRoot,
+				To:   api.PipelineImageStreamTagReferenceSource,
+				ClonerefsImage: api.ImageStreamTagReference{
+					Namespace: "ci",
+					Name:      "clonerefs",
+					Tag:       "latest",
+				},
+				ClonerefsPath: "/clonerefs",
+				Clone:         &api.CloneConfiguration{Filter: "blob:limit=1m"},
+			},
+			jobSpec: &api.JobSpec{
+				JobSpec: downwardapi.JobSpec{
+					Job:       "job",
+					BuildID:   "buildId",
+					ProwJobID: "prowJobId",
+					Refs: &prowapi.Refs{
+						Org:     "org",
+						Repo:    "repo",
+						BaseRef: "master",
+						BaseSHA: "masterSHA",
+					},
+				},
+			},
+			cloneAuthConfig: &CloneAuthConfig{
+				Secret: &coreapi.Secret{
+					ObjectMeta: meta.ObjectMeta{Name: "oauth-nykd6bfg"},
+				},
+				Type: CloneAuthTypeOAuth,
+			},
+			clonerefsRef: coreapi.ObjectReference{Kind: "ImageStreamTag", Name: "clonerefs:latest", Namespace: "ci"},
+			resources:    map[string]api.ResourceRequirements{"*": {Requests: map[string]string{"cpu": "200m"}}},
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -294,6 +447,62 @@ func TestCreateBuild(t *testing.T) {
 	}
 }
 
+func TestCreateArchiveBuild(t *testing.T) {
+	t.Parallel()
+	var testCases = []struct {
+		name    string
+		jobSpec *api.JobSpec
+	}{
+		{
+			name: "presubmit",
+			jobSpec: &api.JobSpec{
+				JobSpec: downwardapi.JobSpec{
+					Job:       "job",
+					BuildID:   "buildId",
+					ProwJobID: "prowJobId",
+					Refs: &prowapi.Refs{
+						Org:       "org",
+						Repo:      "repo",
+						BaseRef:   "master",
+						BaseSHA:   "masterSHA",
+						PathAlias: "somewhere.com/org/repo",
+						Pulls: []prowapi.Pull{{
+							Number: 1,
+							SHA:    "pullSHA",
+						}},
+					},
+				},
+			},
+		},
+		{
+			name: "without refs",
+			jobSpec: &api.JobSpec{
+				JobSpec: downwardapi.JobSpec{
+					Job:       "job",
+					BuildID:   "buildId",
+					ProwJobID: "prowJobId",
+				},
+			},
+		},
+	}
+
+	config := api.SourceStepConfiguration{
+		From: api.PipelineImageStreamTagReferenceRoot,
+		To:   api.PipelineImageStreamTagReferenceSource,
+		SourceArchive: &api.SourceArchive{
+			From: "source-archive",
+			Path: "/archives/source.tar.gz",
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.jobSpec.SetNamespace("namespace")
+			actual := createArchiveBuild(config, testCase.jobSpec, map[string]api.ResourceRequirements{"*": {Requests: map[string]string{"cpu": "200m"}}}, nil, "imagedigest")
+			testhelper.CompareWithFixture(t, actual)
+		})
+	}
+}
+
 func TestBuildFromSource(t *testing.T) {
 	var testCases = []struct {
 		name                          string
@@ -359,3 +568,58 @@ func TestBuildFromSource(t *testing.T) {
 		})
 	}
 }
+
+func TestToEnv(t *testing.T) {
+	t.Parallel()
+	jobSpec := &api.JobSpec{
+		JobSpec: downwardapi.JobSpec{
+			Type:      prowapi.PresubmitJob,
+			Job:       "job",
+			BuildID:   "buildId",
+			ProwJobID: "prowJobId",
+			Refs: &prowapi.Refs{
+				Org:     "org",
+				Repo:    "repo",
+				BaseRef: "master",
+				BaseSHA: "masterSHA",
+				Pulls:   []prowapi.Pull{{Number: 1, SHA: "pullSHA"}},
+			},
+		},
+	}
+	periodicJobSpec := &api.JobSpec{JobSpec: downwardapi.JobSpec{Type: prowapi.PeriodicJob, Job: "periodic"}}
+	var testCases = []struct {
+		name     string
+		args     []api.BuildArg
+		jobSpec  *api.JobSpec
+		expected []coreapi.EnvVar
+	}{
+		{
+			name:    "static values and values from secrets",
+			args:    []api.BuildArg{{Name: "a", Value: "b"}, {Name: "c", ValueFrom: &api.SecretKeySelector{Namespace: "ns", Name: "secret", Key: "key"}}},
+			jobSpec: jobSpec,
+			expected: []coreapi.EnvVar{
+				{Name: "a", Value: "b"},
+				{Name: "c", ValueFrom: &coreapi.EnvVarSource{SecretKeyRef: &coreapi.SecretKeySelector{LocalObjectReference: coreapi.LocalObjectReference{Name: "ns-secret"}, Key: "key"}}},
+			},
+		},
+		{
+			name:     "values from the job spec",
+			args:     []api.BuildArg{{Name: "COMMIT", FromJobSpec: "PULL_PULL_SHA"}, {Name: "PR", FromJobSpec: "PULL_NUMBER"}, {Name: "JOB", FromJobSpec: "JOB_NAME"}},
+			jobSpec:  jobSpec,
+			expected: []coreapi.EnvVar{{Name: "COMMIT", Value: "pullSHA"}, {Name: "PR", Value: "1"}, {Name: "JOB", Value: "job"}},
+		},
+		{
+			name:     "variables that are undefined for the job are empty",
+			args:     []api.BuildArg{{Name: "PR", FromJobSpec: "PULL_NUMBER"}, {Name: "JOB", FromJobSpec: "JOB_NAME"}},
+			jobSpec:  periodicJobSpec,
+			expected: []coreapi.EnvVar{{Name: "PR"}, {Name: "JOB", Value: "periodic"}},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if diff := cmp.Diff(testCase.expected, toEnv(testCase.args, testCase.jobSpec)); diff != "" {
+				t.Errorf("env differs from expected: %s", diff)
+			}
+		})
+	}
+}
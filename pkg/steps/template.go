@@ -58,6 +58,7 @@ const (
 type templateExecutionStep struct {
 	template  *templateapi.Template
 	resources api.ResourceConfiguration
+	profile   api.ClusterProfile
 	params    api.Parameters
 	podClient PodClient
 	client    TemplateClient
@@ -109,7 +110,7 @@ func (s *templateExecutionStep) run(ctx context.Context) error {
 		}
 	}
 
-	operateOnTemplatePods(s.template, s.resources)
+	operateOnTemplatePods(s.template, s.resources, s.profile)
 	injectLabelsToTemplate(s.jobSpec, s.template)
 
 	// TODO: enforce single namespace behavior
@@ -216,8 +217,8 @@ func hasContainerResources(container coreapi.Container) bool {
 	return len(resources.Limits) > 0 || len(resources.Requests) > 0
 }
 
-func injectResourcesToPod(pod *coreapi.Pod, templateName string, resources api.ResourceConfiguration) error {
-	containerResources, err := resourcesFor(resources.RequirementsForStep(templateName))
+func injectResourcesToPod(pod *coreapi.Pod, templateName string, resources api.ResourceConfiguration, profile api.ClusterProfile) error {
+	containerResources, err := resourcesFor(resources.RequirementsForStepOn(templateName, profile, ""))
 	if err != nil {
 		return fmt.Errorf("unable to calculate resources for %s: %w", pod.Name, err)
 	}
@@ -232,13 +233,13 @@ func injectResourcesToPod(pod *coreapi.Pod, templateName string, resources api.R
 	return nil
 }
 
-func operateOnTemplatePods(template *templateapi.Template, resources api.ResourceConfiguration) {
+func operateOnTemplatePods(template *templateapi.Template, resources api.ResourceConfiguration, profile api.ClusterProfile) {
 	for index, object := range template.Objects {
 		if pod := getPodFromObject(object); pod != nil {
 			addArtifactsToPod(pod)
 
 			if resources != nil && !hasTestContainerWithResources(pod) {
-				if err := injectResourcesToPod(pod, template.Name, resources); err != nil {
+				if err := injectResourcesToPod(pod, template.Name, resources, profile); err != nil {
 					logrus.WithError(err).Warn("Couldn't inject resources to pod.")
 				}
 			}
@@ -281,10 +282,13 @@ func (s *templateExecutionStep) Objects() []ctrlruntimeclient.Object {
 	return s.client.Objects()
 }
 
-func TemplateExecutionStep(template *templateapi.Template, params api.Parameters, podClient PodClient, templateClient TemplateClient, jobSpec *api.JobSpec, resources api.ResourceConfiguration) api.Step {
+// TemplateExecutionStep runs the template for a test with the cluster profile,
+// whose resource overrides apply to the test container of the template.
+func TemplateExecutionStep(template *templateapi.Template, params api.Parameters, podClient PodClient, templateClient TemplateClient, jobSpec *api.JobSpec, resources api.ResourceConfiguration, profile api.ClusterProfile) api.Step {
 	return &templateExecutionStep{
 		template:  template,
 		resources: resources,
+		profile:   profile,
 		params:    params,
 		podClient: podClient,
 		client:    templateClient,
@@ -408,7 +412,7 @@ func waitForCompletedTemplateInstanceDeletion(client ctrlruntimeclient.Client, n
 	return nil
 }
 
-func createOrRestartPod(podClient ctrlruntimeclient.Client, pod *coreapi.Pod) (*coreapi.Pod, error) {
+func createOrRestartPod(ctx context.Context, podClient ctrlruntimeclient.Client, pod *coreapi.Pod) (*coreapi.Pod, error) {
 	namespace, name := pod.Namespace, pod.Name
 	if err := waitForCompletedPodDeletion(podClient, namespace, name); err != nil {
 		return nil, fmt.Errorf("unable to delete completed pod: %w", err)
@@ -421,17 +425,18 @@ func createOrRestartPod(podClient ctrlruntimeclient.Client, pod *coreapi.Pod) (*
 	// creating a pod in close proximity to namespace creation can result in forbidden errors due to
 	// initializing secrets or policy - use a short backoff to mitigate flakes
 	if err := wait.ExponentialBackoff(wait.Backoff{Steps: 4, Factor: 2, Duration: time.Second}, func() (bool, error) {
-		err := podClient.Create(context.TODO(), pod)
+		err := podClient.Create(ctx, pod)
 		if err != nil {
 			if kerrors.IsForbidden(err) {
 				logrus.WithError(err).Warnf("Unable to create pod %s, may be temporary.", name)
+				RecordRetry(ctx)
 				return false, nil
 			}
 			if !kerrors.IsAlreadyExists(err) {
 				return false, err
 			}
 
-			if err := podClient.Get(context.TODO(), ctrlruntimeclient.ObjectKey{Namespace: namespace, Name: name}, pod); err != nil {
+			if err := podClient.Get(ctx, ctrlruntimeclient.ObjectKey{Namespace: namespace, Name: name}, pod); err != nil {
 				return false, err
 			}
 		}
@@ -793,7 +798,7 @@ func podLogNewFailedContainers(podClient PodClient, pod *coreapi.Pod, completed
 
 		if s, err := podClient.GetLogs(pod.Namespace, pod.Name, &coreapi.PodLogOptions{
 			Container: status.Name,
-		}).Stream(context.TODO()); err == nil {
+		}); err == nil {
 			logs := &bytes.Buffer{}
 			if _, err := io.Copy(logs, s); err != nil {
 				logrus.WithError(err).Warnf("Unable to copy log output from failed pod container %s.", status.Name)
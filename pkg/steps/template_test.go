@@ -235,7 +235,7 @@ func TestOperateOnTemplatePods(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.testID, func(t *testing.T) {
-			operateOnTemplatePods(tc.template, tc.resources)
+			operateOnTemplatePods(tc.template, tc.resources, "")
 			testhelper.CompareWithFixture(t, tc.template)
 		})
 	}
@@ -246,6 +246,7 @@ func TestInjectResourcesToPod(t *testing.T) {
 	testCases := []struct {
 		testID    string
 		resources api.ResourceConfiguration
+		profile   api.ClusterProfile
 		pod       *coreapi.Pod
 	}{
 		{
@@ -310,13 +311,35 @@ func TestInjectResourcesToPod(t *testing.T) {
 				},
 			},
 		},
+
+		{
+			testID: "resource requests are overridden for the cluster profile of the test, pod has container named 'test', and is changed",
+			resources: api.ResourceConfiguration{
+				"*": {
+					Requests: api.ResourceList{"cpu": "3", "memory": "8Gi"},
+					Limits:   api.ResourceList{"memory": "10Gi"},
+					ClusterProfiles: map[api.ClusterProfile]api.ResourceOverride{
+						api.ClusterProfileAWS: {Requests: api.ResourceList{"memory": "12Gi"}, Limits: api.ResourceList{"memory": "14Gi"}},
+						api.ClusterProfileGCP: {Requests: api.ResourceList{"cpu": "100"}},
+					},
+				},
+			},
+			profile: api.ClusterProfileAWS,
+			pod: &coreapi.Pod{
+				TypeMeta:   meta.TypeMeta{Kind: "Pod", APIVersion: "v1"},
+				ObjectMeta: meta.ObjectMeta{Name: "test-pod"},
+				Spec: coreapi.PodSpec{
+					Containers: []coreapi.Container{{Name: "test"}},
+				},
+			},
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.testID, func(t *testing.T) {
 			pod := tc.pod
 
-			if err := injectResourcesToPod(pod, testTemplateName, tc.resources); err != nil {
+			if err := injectResourcesToPod(pod, testTemplateName, tc.resources, tc.profile); err != nil {
 				t.Fatalf("injectResourcesToPod failed: %v", err)
 			}
 			testhelper.CompareWithFixture(t, pod)
@@ -0,0 +1,105 @@
+package steps
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/junit"
+	"github.com/openshift/ci-tools/pkg/results"
+)
+
+// defaultStepGracePeriod is how long a step may take to clean up after it
+// timed out when no grace period is configured
+const defaultStepGracePeriod = time.Minute
+
+// timeoutStep wraps another step and aborts it when it exceeds its timeout.
+type timeoutStep struct {
+	timeout     time.Duration
+	gracePeriod time.Duration
+	wrapped     api.Step
+}
+
+// TimeoutStep enforces the timeouts on the wrapped step. Steps without a
+// timeout are returned unchanged.
+func TimeoutStep(timeouts api.StepTimeouts, wrapped api.Step) api.Step {
+	if timeouts.Timeout == nil {
+		return wrapped
+	}
+	step := &timeoutStep{
+		timeout:     timeouts.Timeout.Duration,
+		gracePeriod: defaultStepGracePeriod,
+		wrapped:     wrapped,
+	}
+	if timeouts.GracePeriod != nil {
+		step.gracePeriod = timeouts.GracePeriod.Duration
+	}
+	return step
+}
+
+func (s *timeoutStep) Inputs() (api.InputDefinition, error) {
+	return s.wrapped.Inputs()
+}
+
+func (s *timeoutStep) Validate() error {
+	if s.timeout <= 0 {
+		return fmt.Errorf("timeout must be positive, got %s", s.timeout)
+	}
+	if s.gracePeriod < 0 {
+		return fmt.Errorf("grace period must not be negative, got %s", s.gracePeriod)
+	}
+	return s.wrapped.Validate()
+}
+
+func (s *timeoutStep) Name() string                        { return s.wrapped.Name() }
+func (s *timeoutStep) Description() string                 { return s.wrapped.Description() }
+func (s *timeoutStep) Requires() []api.StepLink            { return s.wrapped.Requires() }
+func (s *timeoutStep) Creates() []api.StepLink             { return s.wrapped.Creates() }
+func (s *timeoutStep) Provides() api.ParameterMap          { return s.wrapped.Provides() }
+func (s *timeoutStep) Objects() []ctrlruntimeclient.Object { return s.wrapped.Objects() }
+func (s *timeoutStep) unwrap() api.Step                    { return s.wrapped }
+
+func (s *timeoutStep) SubTests() []*junit.TestCase {
+	if subTests, ok := s.wrapped.(subtestReporter); ok {
+		return subTests.SubTests()
+	}
+	return nil
+}
+
+func (s *timeoutStep) SubSteps() []api.CIOperatorStepDetailInfo {
+	if subSteps, ok := s.wrapped.(SubStepReporter); ok {
+		return subSteps.SubSteps()
+	}
+	return nil
+}
+
+func (s *timeoutStep) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	errs := make(chan error, 1)
+	go func() {
+		errs <- s.wrapped.Run(ctx)
+	}()
+
+	timer := time.NewTimer(s.timeout)
+	defer timer.Stop()
+	select {
+	case err := <-errs:
+		return err
+	case <-timer.C:
+	}
+
+	logrus.Warnf("Step %s did not finish within its timeout of %s, aborting it.", s.Name(), s.timeout)
+	cancel()
+	select {
+	case <-errs:
+	case <-time.After(s.gracePeriod):
+		logrus.Warnf("Step %s did not terminate within its grace period of %s.", s.Name(), s.gracePeriod)
+	}
+	return results.ForReason(results.ReasonTimeout).ForError(fmt.Errorf("step %s did not finish within its timeout of %s", s.Name(), s.timeout))
+}
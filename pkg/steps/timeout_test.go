@@ -0,0 +1,117 @@
+package steps
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	prowv1 "k8s.io/test-infra/prow/apis/prowjobs/v1"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/junit"
+	"github.com/openshift/ci-tools/pkg/results"
+)
+
+// slowStep takes the given time to run and honors cancellation unless told
+// to ignore it
+type slowStep struct {
+	fakeStep
+	duration           time.Duration
+	ignoreCancellation bool
+}
+
+func (s *slowStep) Run(ctx context.Context) error {
+	if s.ignoreCancellation {
+		time.Sleep(s.duration)
+		return nil
+	}
+	select {
+	case <-time.After(s.duration):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *slowStep) SubTests() []*junit.TestCase {
+	return []*junit.TestCase{{Name: s.name}}
+}
+
+func TestTimeoutStep(t *testing.T) {
+	var testCases = []struct {
+		name           string
+		step           *slowStep
+		timeout        time.Duration
+		gracePeriod    time.Duration
+		expectedReason string
+		maxDuration    time.Duration
+	}{
+		{
+			name:        "step finishes within its timeout",
+			step:        &slowStep{fakeStep: fakeStep{name: "fast"}, duration: time.Millisecond},
+			timeout:     time.Minute,
+			maxDuration: 10 * time.Second,
+		},
+		{
+			name:           "step is aborted when it exceeds its timeout",
+			step:           &slowStep{fakeStep: fakeStep{name: "slow"}, duration: time.Hour},
+			timeout:        10 * time.Millisecond,
+			gracePeriod:    time.Hour,
+			expectedReason: "timeout",
+			maxDuration:    10 * time.Second,
+		},
+		{
+			name:           "step that ignores cancellation is abandoned after its grace period",
+			step:           &slowStep{fakeStep: fakeStep{name: "stuck"}, duration: time.Hour, ignoreCancellation: true},
+			timeout:        10 * time.Millisecond,
+			gracePeriod:    10 * time.Millisecond,
+			expectedReason: "timeout",
+			maxDuration:    10 * time.Second,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			step := TimeoutStep(api.StepTimeouts{
+				Timeout:     &prowv1.Duration{Duration: tc.timeout},
+				GracePeriod: &prowv1.Duration{Duration: tc.gracePeriod},
+			}, tc.step)
+			if err := step.Validate(); err != nil {
+				t.Fatalf("unexpected validation error: %v", err)
+			}
+			start := time.Now()
+			err := step.Run(context.Background())
+			if duration := time.Since(start); duration > tc.maxDuration {
+				t.Errorf("expected the step to return within %s, took %s", tc.maxDuration, duration)
+			}
+			if tc.expectedReason == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected an error, got none")
+			}
+			if reason := results.FullReason(err); reason != tc.expectedReason {
+				t.Errorf("expected reason %q, got %q", tc.expectedReason, reason)
+			}
+			if subTests := step.(subtestReporter).SubTests(); len(subTests) != 1 || subTests[0].Name != tc.step.name {
+				t.Errorf("expected the sub-tests of the wrapped step, got %v", subTests)
+			}
+		})
+	}
+}
+
+func TestTimeoutStepWithoutTimeout(t *testing.T) {
+	wrapped := &fakeStep{name: "step"}
+	if step := TimeoutStep(api.StepTimeouts{}, wrapped); step != wrapped {
+		t.Errorf("expected the step to be returned unchanged, got %T", step)
+	}
+}
+
+func TestTimeoutStepValidate(t *testing.T) {
+	step := TimeoutStep(api.StepTimeouts{Timeout: &prowv1.Duration{}}, &fakeStep{name: "step"})
+	if err := step.Validate(); err == nil {
+		t.Error("expected a zero timeout to be rejected")
+	}
+}
@@ -0,0 +1,108 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+	toolswatch "k8s.io/client-go/tools/watch"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	imagev1 "github.com/openshift/api/image/v1"
+
+	"github.com/openshift/ci-tools/pkg/api"
+)
+
+// ImageStreamTagWaiter blocks until tags of image streams are imported. The
+// image streams are watched instead of polled, so waits end as soon as a tag
+// is available. How long every wait took is recorded, so that steps can
+// report it as a sub-step.
+type ImageStreamTagWaiter struct {
+	client ctrlruntimeclient.WithWatch
+
+	lock  sync.Mutex
+	waits []api.CIOperatorStepDetailInfo
+}
+
+// NewImageStreamTagWaiter returns a waiter that watches with the client.
+func NewImageStreamTagWaiter(client ctrlruntimeclient.WithWatch) *ImageStreamTagWaiter {
+	return &ImageStreamTagWaiter{client: client}
+}
+
+// Wait blocks until the tag of the image stream resolves to an image and
+// returns the image stream at that point. The image stream does not need to
+// exist yet. A timeout of zero waits until the context is cancelled.
+func (w *ImageStreamTagWaiter) Wait(ctx context.Context, namespace, name, tag string, timeout time.Duration) (*imagev1.ImageStream, error) {
+	logger := logrus.WithFields(logrus.Fields{"namespace": namespace, "name": name, "tag": tag})
+	logger.Debug("Waiting for the image stream tag to be imported.")
+	start := time.Now()
+
+	selector := fields.OneTermEqualSelector("metadata.name", name)
+	lw := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			options.FieldSelector = selector.String()
+			list := &imagev1.ImageStreamList{}
+			return list, w.client.List(ctx, list, &ctrlruntimeclient.ListOptions{Namespace: namespace, Raw: &options})
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			return w.client.Watch(ctx, &imagev1.ImageStreamList{}, &ctrlruntimeclient.ListOptions{Namespace: namespace, FieldSelector: selector, Raw: &options})
+		},
+	}
+
+	var stream *imagev1.ImageStream
+	imported := func(event watch.Event) (bool, error) {
+		is, ok := event.Object.(*imagev1.ImageStream)
+		if !ok || is.Name != name {
+			// clients may ignore the field selector
+			return false, nil
+		}
+		switch event.Type {
+		case watch.Deleted:
+			return false, fmt.Errorf("image stream %s/%s was deleted", namespace, name)
+		case watch.Added, watch.Modified:
+			stream = is
+			_, image := FindStatusTag(is, tag)
+			return image != "", nil
+		}
+		return false, nil
+	}
+
+	waitCtx, cancel := toolswatch.ContextWithOptionalTimeout(ctx, timeout)
+	defer cancel()
+	_, err := toolswatch.UntilWithSync(waitCtx, lw, &imagev1.ImageStream{}, nil, imported)
+
+	finished := time.Now()
+	duration := finished.Sub(start)
+	failed := err != nil
+	w.lock.Lock()
+	w.waits = append(w.waits, api.CIOperatorStepDetailInfo{
+		StepName:    fmt.Sprintf("wait-for-%s-%s", name, tag),
+		Description: fmt.Sprintf("Wait for the image stream tag %s/%s:%s to be imported", namespace, name, tag),
+		StartedAt:   &start,
+		FinishedAt:  &finished,
+		Duration:    &duration,
+		Failed:      &failed,
+	})
+	w.lock.Unlock()
+
+	if err != nil {
+		return nil, fmt.Errorf("image stream tag %s/%s:%s was not imported after %s: %w", namespace, name, tag, duration.Truncate(time.Second), err)
+	}
+	logger.Debugf("The image stream tag was imported after %s.", duration)
+	return stream, nil
+}
+
+// SubSteps returns a record of every wait.
+func (w *ImageStreamTagWaiter) SubSteps() []api.CIOperatorStepDetailInfo {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return append([]api.CIOperatorStepDetailInfo(nil), w.waits...)
+}
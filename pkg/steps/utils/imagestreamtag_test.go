@@ -0,0 +1,96 @@
+package utils
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	imagev1 "github.com/openshift/api/image/v1"
+)
+
+func TestImageStreamTagWaiter(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := imagev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add to scheme: %v", err)
+	}
+	stream := func(tags ...string) *imagev1.ImageStream {
+		is := &imagev1.ImageStream{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "pipeline"}}
+		for _, tag := range tags {
+			is.Status.Tags = append(is.Status.Tags, imagev1.NamedTagEventList{Tag: tag, Items: []imagev1.TagEvent{{Image: "sha256:" + tag}}})
+		}
+		return is
+	}
+	var testCases = []struct {
+		name        string
+		existing    []ctrlruntimeclient.Object
+		update      *imagev1.ImageStream
+		create      *imagev1.ImageStream
+		expectError bool
+	}{
+		{
+			name:     "tag is already imported",
+			existing: []ctrlruntimeclient.Object{stream("src"), &imagev1.ImageStream{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "other"}}},
+		},
+		{
+			name:     "tag is imported while waiting",
+			existing: []ctrlruntimeclient.Object{stream("root")},
+			update:   stream("root", "src"),
+		},
+		{
+			name:   "image stream is created while waiting",
+			create: stream("src"),
+		},
+		{
+			name:        "tag is never imported",
+			existing:    []ctrlruntimeclient.Object{stream("root")},
+			expectError: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := fakectrlruntimeclient.NewClientBuilder().WithScheme(scheme).WithObjects(tc.existing...).Build()
+			waiter := NewImageStreamTagWaiter(client)
+			go func() {
+				time.Sleep(50 * time.Millisecond)
+				if tc.update != nil {
+					current := &imagev1.ImageStream{}
+					if err := client.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: "ns", Name: "pipeline"}, current); err != nil {
+						t.Errorf("failed to get image stream: %v", err)
+						return
+					}
+					current.Status = tc.update.Status
+					if err := client.Update(context.Background(), current); err != nil {
+						t.Errorf("failed to update image stream: %v", err)
+					}
+				}
+				if tc.create != nil {
+					if err := client.Create(context.Background(), tc.create); err != nil {
+						t.Errorf("failed to create image stream: %v", err)
+					}
+				}
+			}()
+
+			is, err := waiter.Wait(context.Background(), "ns", "pipeline", "src", time.Second)
+			if tc.expectError != (err != nil) {
+				t.Fatalf("expected error: %t, got %v", tc.expectError, err)
+			}
+			if err == nil {
+				if _, image := FindStatusTag(is, "src"); image != "sha256:src" {
+					t.Errorf("expected the imported image stream to be returned, got %v", is)
+				}
+			}
+			waits := waiter.SubSteps()
+			if len(waits) != 1 {
+				t.Fatalf("expected one recorded wait, got %d", len(waits))
+			}
+			if waits[0].StepName != "wait-for-pipeline-src" || waits[0].Duration == nil || *waits[0].Failed != tc.expectError {
+				t.Errorf("unexpected recorded wait: %+v", waits[0])
+			}
+		})
+	}
+}
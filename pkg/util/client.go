@@ -31,6 +31,16 @@ func LoadClusterConfig() (*rest.Config, error) {
 	return rest.InClusterConfig()
 }
 
+// LoadLocalClusterConfig loads the kubeconfig of the user from $KUBECONFIG or
+// the default location, never falling back to the in-cluster config.
+func LoadLocalClusterConfig() (*rest.Config, error) {
+	clusterConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(clientcmd.NewDefaultClientConfigLoadingRules(), &clientcmd.ConfigOverrides{}).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("could not load the local kubeconfig: %w", err)
+	}
+	return clusterConfig, nil
+}
+
 // LoadKubeConfigs loads kubeconfigs. If the kubeconfigChangedCallBack is non-nil, it will watch all kubeconfigs it loaded
 // and call the callback once they change.
 func LoadKubeConfigs(kubeconfig string, kubeconfigChangedCallBack func(fsnotify.Event)) (map[string]*rest.Config, string, error) {
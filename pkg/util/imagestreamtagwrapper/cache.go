@@ -0,0 +1,96 @@
+package imagestreamtagwrapper
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	toolscache "k8s.io/client-go/tools/cache"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	imagev1 "github.com/openshift/api/image/v1"
+)
+
+var cacheLookupsCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "imagestreamtagwrapper_cache_lookups_total",
+	Help: "The number of imagestreamtag lookups served by the imagestreamtagwrapper, partitioned by whether they were a cache hit or miss",
+}, []string{"result"})
+
+func init() {
+	metrics.Registry.MustRegister(cacheLookupsCounter)
+}
+
+// imageStreamTagCache caches assembled imagestreamtags keyed by their imagestream
+// and tag. All entries of an imagestream are dropped whenever an event for that
+// imagestream is observed.
+type imageStreamTagCache struct {
+	lock sync.Mutex
+	// entries maps namespace/name of an imagestream to its tags
+	entries map[ctrlruntimeclient.ObjectKey]map[string]*imagev1.ImageStreamTag
+	// generations is bumped for an imagestream each time it gets invalidated. It
+	// is used to avoid storing results that were assembled from an imagestream
+	// that got invalidated in the meantime.
+	generations map[ctrlruntimeclient.ObjectKey]uint64
+}
+
+func newImageStreamTagCache() *imageStreamTagCache {
+	return &imageStreamTagCache{
+		entries:     map[ctrlruntimeclient.ObjectKey]map[string]*imagev1.ImageStreamTag{},
+		generations: map[ctrlruntimeclient.ObjectKey]uint64{},
+	}
+}
+
+// get returns a copy of the cached imagestreamtag, if any, and the current
+// generation of the imagestream which must be passed to a subsequent set.
+func (c *imageStreamTagCache) get(stream ctrlruntimeclient.ObjectKey, tag string) (*imagev1.ImageStreamTag, uint64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if ist, ok := c.entries[stream][tag]; ok {
+		cacheLookupsCounter.WithLabelValues("hit").Inc()
+		return ist.DeepCopy(), c.generations[stream]
+	}
+	cacheLookupsCounter.WithLabelValues("miss").Inc()
+	return nil, c.generations[stream]
+}
+
+// set stores a copy of the imagestreamtag, unless the imagestream got
+// invalidated after generation was retrieved.
+func (c *imageStreamTagCache) set(stream ctrlruntimeclient.ObjectKey, tag string, generation uint64, ist *imagev1.ImageStreamTag) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.generations[stream] != generation {
+		return
+	}
+	if c.entries[stream] == nil {
+		c.entries[stream] = map[string]*imagev1.ImageStreamTag{}
+	}
+	c.entries[stream][tag] = ist.DeepCopy()
+}
+
+func (c *imageStreamTagCache) invalidate(stream ctrlruntimeclient.ObjectKey) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.entries, stream)
+	c.generations[stream]++
+}
+
+func (c *imageStreamTagCache) invalidateFor(obj interface{}) {
+	if tombstone, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	imageStream, ok := obj.(*imagev1.ImageStream)
+	if !ok {
+		return
+	}
+	c.invalidate(ctrlruntimeclient.ObjectKey{Namespace: imageStream.Namespace, Name: imageStream.Name})
+}
+
+// eventHandler returns a handler that invalidates the cache for all imagestream events
+func (c *imageStreamTagCache) eventHandler() toolscache.ResourceEventHandler {
+	return toolscache.ResourceEventHandlerFuncs{
+		AddFunc:    c.invalidateFor,
+		UpdateFunc: func(_, newObj interface{}) { c.invalidateFor(newObj) },
+		DeleteFunc: c.invalidateFor,
+	}
+}
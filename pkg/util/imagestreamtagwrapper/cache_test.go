@@ -0,0 +1,90 @@
+package imagestreamtagwrapper
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	toolscache "k8s.io/client-go/tools/cache"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	imagev1 "github.com/openshift/api/image/v1"
+)
+
+func TestImageStreamTagCache(t *testing.T) {
+	stream := ctrlruntimeclient.ObjectKey{Namespace: "ns", Name: "stream"}
+	otherStream := ctrlruntimeclient.ObjectKey{Namespace: "ns", Name: "other"}
+	ist := &imagev1.ImageStreamTag{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "stream:tag"}}
+	imageStream := &imagev1.ImageStream{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "stream"}}
+
+	testCases := []struct {
+		name     string
+		actions  func(c *imageStreamTagCache)
+		expected *imagev1.ImageStreamTag
+	}{
+		{
+			name:    "empty cache, miss",
+			actions: func(*imageStreamTagCache) {},
+		},
+		{
+			name: "set, hit",
+			actions: func(c *imageStreamTagCache) {
+				_, generation := c.get(stream, "tag")
+				c.set(stream, "tag", generation, ist)
+			},
+			expected: ist,
+		},
+		{
+			name: "set for different tag, miss",
+			actions: func(c *imageStreamTagCache) {
+				_, generation := c.get(stream, "other")
+				c.set(stream, "other", generation, ist)
+			},
+		},
+		{
+			name: "invalidation of different stream, hit",
+			actions: func(c *imageStreamTagCache) {
+				_, generation := c.get(stream, "tag")
+				c.set(stream, "tag", generation, ist)
+				c.invalidate(otherStream)
+			},
+			expected: ist,
+		},
+		{
+			name: "update event invalidates, miss",
+			actions: func(c *imageStreamTagCache) {
+				_, generation := c.get(stream, "tag")
+				c.set(stream, "tag", generation, ist)
+				c.eventHandler().OnUpdate(imageStream, imageStream)
+			},
+		},
+		{
+			name: "tombstone invalidates, miss",
+			actions: func(c *imageStreamTagCache) {
+				_, generation := c.get(stream, "tag")
+				c.set(stream, "tag", generation, ist)
+				c.eventHandler().OnDelete(toolscache.DeletedFinalStateUnknown{Obj: imageStream})
+			},
+		},
+		{
+			name: "invalidation between get and set, result is not stored",
+			actions: func(c *imageStreamTagCache) {
+				_, generation := c.get(stream, "tag")
+				c.invalidate(stream)
+				c.set(stream, "tag", generation, ist)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := newImageStreamTagCache()
+			tc.actions(c)
+			actual, _ := c.get(stream, "tag")
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Errorf("cached imagestreamtag differs from expected: %s", diff)
+			}
+		})
+	}
+}
@@ -5,7 +5,14 @@ imagestreamtags client-side by fetching the corresponding imagestream and image(
 It is a workaround to get a caching client for imagestreamtags even though they do not support
 the watch verb (xref https://github.com/openshift/api/issues/601).
 
+Imagestreamtag lists are assembled from all matching imagestreams, supporting label selectors and
+pagination.
+
 Note that this still does not allow to get informers for imagestreamtags. Reacting to iamgestreamtags
-can be achieved by reacting to imagestreams and then enqueue all referenced tags.
+can be achieved by reacting to imagestreams and then enqueue all referenced tags, which is what
+ImageStreamTagMapper does.
+
+Assembled imagestreamtags are cached in-memory until an event for their imagestream is observed.
+The ratio of cache hits is exposed via the imagestreamtagwrapper_cache_lookups_total metric.
 */
 package imagestreamtagwrapper
@@ -3,7 +3,6 @@ package imagestreamtagwrapper
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"net/url"
 
@@ -34,10 +33,13 @@ func New(upstream ctrlruntimeclient.Client, cache cache.Cache) (ctrlruntimeclien
 	if _, err := cache.GetInformer(context.TODO(), &imagev1.Image{}); err != nil {
 		return nil, fmt.Errorf("failed to get informer for image: %w", err)
 	}
-	if _, err := cache.GetInformer(context.TODO(), &imagev1.ImageStream{}); err != nil {
+	imageStreamInformer, err := cache.GetInformer(context.TODO(), &imagev1.ImageStream{})
+	if err != nil {
 		return nil, fmt.Errorf("failed to get informer for imagestream: %w", err)
 	}
-	return &imagestreamtagwrapper{Client: upstream}, nil
+	istCache := newImageStreamTagCache()
+	imageStreamInformer.AddEventHandler(istCache.eventHandler())
+	return &imagestreamtagwrapper{Client: upstream, cache: istCache}, nil
 }
 
 // MustNew panics when there was an error during initialisation
@@ -51,6 +53,9 @@ func MustNew(upstream ctrlruntimeclient.Client, cache cache.Cache) ctrlruntimecl
 
 type imagestreamtagwrapper struct {
 	ctrlruntimeclient.Client
+	// cache is optional and holds assembled imagestreamtags until
+	// their imagestream changes
+	cache *imageStreamTagCache
 }
 
 func (istw *imagestreamtagwrapper) Get(ctx context.Context, key ctrlruntimeclient.ObjectKey, obj ctrlruntimeclient.Object) error {
@@ -67,11 +72,31 @@ func (istw *imagestreamtagwrapper) assembleImageStreamTag(ctx context.Context, k
 	if err != nil {
 		return err
 	}
+	streamKey := ctrlruntimeclient.ObjectKey{Namespace: key.Namespace, Name: name}
+	var generation uint64
+	if istw.cache != nil {
+		var cached *imagev1.ImageStreamTag
+		if cached, generation = istw.cache.get(streamKey, tag); cached != nil {
+			*ist = *cached
+			return nil
+		}
+	}
+
 	imageStream := &imagev1.ImageStream{}
-	if err := istw.Get(ctx, ctrlruntimeclient.ObjectKey{Namespace: key.Namespace, Name: name}, imageStream); err != nil {
+	if err := istw.Get(ctx, streamKey, imageStream); err != nil {
 		return err
 	}
 
+	if err := istw.imageStreamTagFromStream(ctx, tag, imageStream, ist); err != nil {
+		return err
+	}
+	if istw.cache != nil {
+		istw.cache.set(streamKey, tag, generation, ist)
+	}
+	return nil
+}
+
+func (istw *imagestreamtagwrapper) imageStreamTagFromStream(ctx context.Context, tag string, imageStream *imagev1.ImageStream, ist *imagev1.ImageStreamTag) error {
 	image, err := istw.imageFor(ctx, tag, imageStream)
 	if err != nil {
 		if !kapierrors.IsNotFound(err) {
@@ -421,10 +446,3 @@ func fillImageLayers(image *imagev1.Image, manifest dockerapi10.DockerImageManif
 
 	return nil
 }
-
-func (istw *imagestreamtagwrapper) List(ctx context.Context, list ctrlruntimeclient.ObjectList, opts ...ctrlruntimeclient.ListOption) error {
-	if _, isImageStreamTagList := list.(*imagev1.ImageStreamTagList); isImageStreamTagList {
-		return errors.New("list for imageStramTags is not implemented")
-	}
-	return istw.Client.List(ctx, list, opts...)
-}
@@ -0,0 +1,138 @@
+package imagestreamtagwrapper
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	kapierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	imagev1 "github.com/openshift/api/image/v1"
+	"github.com/openshift/library-go/pkg/image/imageutil"
+)
+
+// List assembles imagestreamtag lists from the imagestreams in the cache. Namespace and
+// label selectors are applied to the imagestreams, as the imagestreamtags inherit their
+// labels. Pagination is supported through Limit and Continue, the returned continue token
+// is only valid for this client. Field selectors are not supported.
+func (istw *imagestreamtagwrapper) List(ctx context.Context, list ctrlruntimeclient.ObjectList, opts ...ctrlruntimeclient.ListOption) error {
+	imageStreamTagList, isImageStreamTagList := list.(*imagev1.ImageStreamTagList)
+	if !isImageStreamTagList {
+		return istw.Client.List(ctx, list, opts...)
+	}
+
+	listOpts := &ctrlruntimeclient.ListOptions{}
+	listOpts.ApplyOptions(opts)
+	if listOpts.FieldSelector != nil && !listOpts.FieldSelector.Empty() {
+		return errors.New("field selectors are not supported when listing imagestreamtags")
+	}
+	var continueAfter *position
+	if listOpts.Continue != "" {
+		var err error
+		if continueAfter, err = decodeContinue(listOpts.Continue); err != nil {
+			return kapierrors.NewBadRequest(fmt.Sprintf("invalid continue token: %v", err))
+		}
+	}
+
+	imageStreams := &imagev1.ImageStreamList{}
+	if err := istw.Client.List(ctx, imageStreams, &ctrlruntimeclient.ListOptions{Namespace: listOpts.Namespace, LabelSelector: listOpts.LabelSelector}); err != nil {
+		return fmt.Errorf("failed to list imagestreams: %w", err)
+	}
+	sort.Slice(imageStreams.Items, func(i, j int) bool {
+		return streamKeyFor(&imageStreams.Items[i]) < streamKeyFor(&imageStreams.Items[j])
+	})
+
+	var items []imagev1.ImageStreamTag
+	var last position
+	for i := range imageStreams.Items {
+		imageStream := &imageStreams.Items[i]
+		tags := make([]string, 0, len(imageStream.Status.Tags))
+		for _, tag := range imageStream.Status.Tags {
+			tags = append(tags, tag.Tag)
+		}
+		sort.Strings(tags)
+		for _, tag := range tags {
+			current := position{stream: streamKeyFor(imageStream), tag: tag}
+			if continueAfter != nil && !continueAfter.before(current) {
+				continue
+			}
+			if listOpts.Limit > 0 && int64(len(items)) == listOpts.Limit {
+				imageStreamTagList.Items = items
+				imageStreamTagList.Continue = last.encode()
+				return nil
+			}
+			ist := imagev1.ImageStreamTag{}
+			if err := istw.imageStreamTagFromStream(ctx, tag, imageStream, &ist); err != nil {
+				if kapierrors.IsNotFound(err) {
+					// The tag has no image yet
+					continue
+				}
+				return fmt.Errorf("failed to assemble imagestreamtag %s: %w", imageutil.JoinImageStreamTag(current.stream, tag), err)
+			}
+			items = append(items, ist)
+			last = current
+		}
+	}
+
+	imageStreamTagList.Items = items
+	imageStreamTagList.Continue = ""
+	return nil
+}
+
+func streamKeyFor(imageStream *imagev1.ImageStream) string {
+	return types.NamespacedName{Namespace: imageStream.Namespace, Name: imageStream.Name}.String()
+}
+
+// position identifies an imagestreamtag in the order in which they are listed
+type position struct {
+	stream string
+	tag    string
+}
+
+func (p position) before(other position) bool {
+	if p.stream != other.stream {
+		return p.stream < other.stream
+	}
+	return p.tag < other.tag
+}
+
+func (p position) encode() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(p.stream + "\x00" + p.tag))
+}
+
+func decodeContinue(token string) (*position, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, err
+	}
+	parts := strings.SplitN(string(raw), "\x00", 2)
+	if len(parts) != 2 {
+		return nil, errors.New("malformed token")
+	}
+	return &position{stream: parts[0], tag: parts[1]}, nil
+}
+
+// ImageStreamTagMapper is a handler.MapFunc that maps an imagestream to reconcile
+// requests for all of its imagestreamtags. As imagestreamtags can not be watched, this
+// can be used to get notified about imagestreamtag changes by watching imagestreams:
+//     c.Watch(&source.Kind{Type: &imagev1.ImageStream{}}, handler.EnqueueRequestsFromMapFunc(imagestreamtagwrapper.ImageStreamTagMapper))
+func ImageStreamTagMapper(obj ctrlruntimeclient.Object) []reconcile.Request {
+	imageStream, ok := obj.(*imagev1.ImageStream)
+	if !ok {
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, tag := range imageStream.Status.Tags {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{
+			Namespace: imageStream.Namespace,
+			Name:      imageutil.JoinImageStreamTag(imageStream.Name, tag.Tag),
+		}})
+	}
+	return requests
+}
@@ -0,0 +1,141 @@
+package imagestreamtagwrapper
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/yaml"
+
+	imagev1 "github.com/openshift/api/image/v1"
+)
+
+func TestListImageStreamTags(t *testing.T) {
+	rawImageStream, err := ioutil.ReadFile("testdata/imagestream.yaml")
+	if err != nil {
+		t.Fatalf("failed to read imagestream from disk: %v", err)
+	}
+	imageStream := &imagev1.ImageStream{}
+	if err := yaml.Unmarshal(rawImageStream, imageStream); err != nil {
+		t.Fatalf("failed to unmarshal imagestream: %v", err)
+	}
+	rawImages, err := ioutil.ReadFile("testdata/images.yaml")
+	if err != nil {
+		t.Fatalf("failed to read images from disk: %v", err)
+	}
+	images := &imagev1.ImageList{}
+	if err := yaml.Unmarshal(rawImages, images); err != nil {
+		t.Fatalf("failed to unmarshal images: %v", err)
+	}
+	otherImageStream := &imagev1.ImageStream{
+		ObjectMeta: metav1.ObjectMeta{Namespace: imageStream.Namespace, Name: "other", Labels: map[string]string{"other": "true"}},
+		Status: imagev1.ImageStreamStatus{Tags: []imagev1.NamedTagEventList{
+			{Tag: "latest", Items: []imagev1.TagEvent{{Image: "sha256:doesnotexist", DockerImageReference: "registry/other@sha256:doesnotexist"}}},
+			{Tag: "empty"},
+		}},
+	}
+
+	scheme := runtime.NewScheme()
+	if err := imagev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to register imagev1 to scheme: %v", err)
+	}
+	client := &imagestreamtagwrapper{
+		Client: fakectrlruntimeclient.NewFakeClientWithScheme(scheme, imageStream, otherImageStream, images),
+	}
+	ctx := context.Background()
+
+	all := []string{
+		"other:latest",
+		"pipeline:base",
+		"pipeline:bin",
+		"pipeline:cluster-etcd-operator",
+		"pipeline:root",
+		"pipeline:src",
+	}
+
+	testCases := []struct {
+		name     string
+		opts     []ctrlruntimeclient.ListOption
+		expected []string
+	}{
+		{
+			name:     "all",
+			expected: all,
+		},
+		{
+			name:     "paginated",
+			opts:     []ctrlruntimeclient.ListOption{ctrlruntimeclient.Limit(2)},
+			expected: all,
+		},
+		{
+			name:     "label selector",
+			opts:     []ctrlruntimeclient.ListOption{ctrlruntimeclient.MatchingLabels{"other": "true"}},
+			expected: []string{"other:latest"},
+		},
+		{
+			name: "other namespace",
+			opts: []ctrlruntimeclient.ListOption{ctrlruntimeclient.InNamespace("other")},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var actual []string
+			var pages int
+			opts := tc.opts
+			for {
+				list := &imagev1.ImageStreamTagList{}
+				if err := client.List(ctx, list, opts...); err != nil {
+					t.Fatalf("list failed: %v", err)
+				}
+				pages++
+				for _, item := range list.Items {
+					actual = append(actual, item.Name)
+				}
+				if list.Continue == "" {
+					break
+				}
+				if pages > len(all) {
+					t.Fatal("pagination did not terminate")
+				}
+				opts = append(tc.opts, ctrlruntimeclient.Continue(list.Continue))
+			}
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Errorf("listed imagestreamtags differ from expected: %s", diff)
+			}
+		})
+	}
+}
+
+func TestListImageStreamTagsRejectsFieldSelector(t *testing.T) {
+	client := &imagestreamtagwrapper{Client: fakectrlruntimeclient.NewClientBuilder().Build()}
+	err := client.List(context.Background(), &imagev1.ImageStreamTagList{}, ctrlruntimeclient.MatchingFields{"metadata.name": "foo"})
+	if err == nil {
+		t.Error("expected an error, got none")
+	}
+}
+
+func TestImageStreamTagMapper(t *testing.T) {
+	imageStream := &imagev1.ImageStream{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "stream"},
+		Status: imagev1.ImageStreamStatus{Tags: []imagev1.NamedTagEventList{
+			{Tag: "latest"},
+			{Tag: "other"},
+		}},
+	}
+	expected := []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "stream:latest"}},
+		{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "stream:other"}},
+	}
+	if diff := cmp.Diff(expected, ImageStreamTagMapper(imageStream)); diff != "" {
+		t.Errorf("requests differ from expected: %s", diff)
+	}
+}
@@ -3,10 +3,18 @@ package validation
 import (
 	"errors"
 	"fmt"
+	"regexp"
 	"strings"
 
+	dockerreference "github.com/docker/distribution/reference"
+
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	prowv1 "k8s.io/test-infra/prow/apis/prowjobs/v1"
+	"k8s.io/test-infra/prow/pod-utils/downwardapi"
+
+	"github.com/openshift/library-go/pkg/image/reference"
 
 	"github.com/openshift/ci-tools/pkg/api"
 )
@@ -49,6 +57,9 @@ func validateConfiguration(config *api.ReleaseBuildConfiguration, org, repo stri
 		validationErrors = append(validationErrors, validateImages("images", config.Images)...)
 	}
 
+	validationErrors = append(validationErrors, validateSourceSnapshots("source_snapshots", config)...)
+	validationErrors = append(validationErrors, validateImageScans("image_scans", config)...)
+
 	if config.Operator != nil {
 		// validateOperator needs a method that maps `substitute.with` values to image links
 		// to validate the value is meaningful in the context of the configuration
@@ -67,6 +78,10 @@ func validateConfiguration(config *api.ReleaseBuildConfiguration, org, repo stri
 		validationErrors = append(validationErrors, validateImageStreamTagReferenceMap("base_rpm_images", config.InputConfiguration.BaseRPMImages)...)
 	}
 
+	if config.InputConfiguration.ExternalImages != nil {
+		validationErrors = append(validationErrors, validateExternalImages("external_images", config.InputConfiguration)...)
+	}
+
 	// Validate tag_specification
 	if config.InputConfiguration.ReleaseTagConfiguration != nil {
 		validationErrors = append(validationErrors, validateReleaseTagConfiguration("tag_specification", *config.InputConfiguration.ReleaseTagConfiguration)...)
@@ -136,6 +151,12 @@ func validateBuildRootImageStreamTag(fieldRoot string, buildRoot api.ImageStream
 	return validationErrors
 }
 
+// jobSpecVariables are the variables Prow exposes to jobs, presubmits get all of them
+var jobSpecVariables = sets.NewString(downwardapi.EnvForType(prowv1.PresubmitJob)...)
+
+// cloneFilterRegex matches the partial clone filters that can be used for the source
+var cloneFilterRegex = regexp.MustCompile(`^(blob:none|blob:limit=[0-9]+[kmg]?|tree:[0-9]+)$`)
+
 func validateImages(fieldRoot string, input []api.ProjectDirectoryImageBuildStepConfiguration) []error {
 	var validationErrors []error
 	seenNames := map[api.PipelineImageStreamTagReference]int{}
@@ -158,6 +179,7 @@ func validateImages(fieldRoot string, input []api.ProjectDirectoryImageBuildStep
 		if strings.HasPrefix(string(image.To), string(api.PipelineImageStreamTagReferenceIndexImage)) {
 			validationErrors = append(validationErrors, fmt.Errorf("%s: `to` cannot begin with %s", fieldRootN, api.PipelineImageStreamTagReferenceIndexImage))
 		}
+		validationErrors = append(validationErrors, validateStepTimeouts(fieldRootN, image.StepTimeouts)...)
 		if image.DockerfileLiteral != nil && (image.ContextDir != "" || image.DockerfilePath != "") {
 			validationErrors = append(validationErrors, fmt.Errorf("%s: dockerfile_literal is mutually exclusive with context_dir and dockerfile_path", fieldRootN))
 		}
@@ -168,12 +190,214 @@ func validateImages(fieldRoot string, input []api.ProjectDirectoryImageBuildStep
 			if args.Value != "" && args.ValueFrom != nil {
 				validationErrors = append(validationErrors, fmt.Errorf("%s.build_args[%d]: value is mutually exclusive with value_from", fieldRootN, i))
 			}
+			if args.FromJobSpec != "" {
+				if args.Value != "" || args.ValueFrom != nil {
+					validationErrors = append(validationErrors, fmt.Errorf("%s.build_args[%d]: from_job_spec is mutually exclusive with value and value_from", fieldRootN, i))
+				}
+				if !jobSpecVariables.Has(args.FromJobSpec) {
+					validationErrors = append(validationErrors, fmt.Errorf("%s.build_args[%d]: from_job_spec must be one of %s", fieldRootN, i, strings.Join(jobSpecVariables.List(), ", ")))
+				}
+			}
 			if args.ValueFrom != nil {
 				if _, err := args.ValueFrom.NamespacedName(); err != nil {
 					validationErrors = append(validationErrors, fmt.Errorf("%s.build_args[%d]: failed to determine the namespaced name: %w", fieldRootN, i, err))
 				}
 			}
 		}
+		validationErrors = append(validationErrors, validateImageArchitectures(fieldRootN, image, input)...)
+		validationErrors = append(validationErrors, validateBuildBackend(fieldRootN, image)...)
+		validationErrors = append(validationErrors, validateAdditionalNames(fieldRootN, num, input)...)
+	}
+	return validationErrors
+}
+
+// validateAdditionalNames ensures that every name an image is published as
+// belongs to exactly one image
+func validateAdditionalNames(fieldRoot string, num int, images []api.ProjectDirectoryImageBuildStepConfiguration) []error {
+	var validationErrors []error
+	seen := sets.NewString()
+	for i, name := range images[num].AdditionalNames {
+		fieldRootN := fmt.Sprintf("%s.additional_names[%d]", fieldRoot, i)
+		if name == "" {
+			validationErrors = append(validationErrors, fmt.Errorf("%s: must not be empty", fieldRootN))
+			continue
+		}
+		if seen.Has(name) {
+			validationErrors = append(validationErrors, fmt.Errorf("%s: duplicate name %s", fieldRootN, name))
+		}
+		seen.Insert(name)
+		for j, other := range images {
+			if string(other.To) == name {
+				validationErrors = append(validationErrors, fmt.Errorf("%s: %s is already the name of images[%d]", fieldRootN, name, j))
+			}
+			if j < num && sets.NewString(other.AdditionalNames...).Has(name) {
+				validationErrors = append(validationErrors, fmt.Errorf("%s: %s is already an additional name of images[%d]", fieldRootN, name, j))
+			}
+		}
+	}
+	return validationErrors
+}
+
+func validateBuildBackend(fieldRoot string, image api.ProjectDirectoryImageBuildStepConfiguration) []error {
+	switch image.BuildBackend {
+	case "", api.BuildBackendOpenShift:
+		return nil
+	case api.BuildBackendBuildahPod:
+	default:
+		return []error{fmt.Errorf("%s.build_backend: must be one of %s, %s", fieldRoot, api.BuildBackendOpenShift, api.BuildBackendBuildahPod)}
+	}
+	var validationErrors []error
+	if image.IsMultiArch() {
+		validationErrors = append(validationErrors, fmt.Errorf("%s.build_backend: %s cannot be used with architectures", fieldRoot, image.BuildBackend))
+	}
+	if image.RequiresEntitlement {
+		validationErrors = append(validationErrors, fmt.Errorf("%s.build_backend: %s cannot be used with requires_entitlement", fieldRoot, image.BuildBackend))
+	}
+	return validationErrors
+}
+
+func validateImageArchitectures(fieldRoot string, image api.ProjectDirectoryImageBuildStepConfiguration, images []api.ProjectDirectoryImageBuildStepConfiguration) []error {
+	var validationErrors []error
+	seen := sets.NewString()
+	for i, architecture := range image.Architectures {
+		fieldRootN := fmt.Sprintf("%s.architectures[%d]", fieldRoot, i)
+		if err := ValidateArchitecture(fieldRootN, architecture); err != nil {
+			validationErrors = append(validationErrors, err)
+			continue
+		}
+		if seen.Has(string(architecture)) {
+			validationErrors = append(validationErrors, fmt.Errorf("%s: duplicate architecture %s", fieldRootN, architecture))
+		}
+		seen.Insert(string(architecture))
+		archTag := api.ArchitectureTag(image.To, architecture)
+		for _, other := range images {
+			if other.To == archTag {
+				validationErrors = append(validationErrors, fmt.Errorf("%s: the image for %s is tagged as %s, which conflicts with another image", fieldRootN, architecture, archTag))
+			}
+		}
+	}
+	return validationErrors
+}
+
+func validateRPMBuildArchitectures(fieldRoot string, architectures []api.ReleaseArchitecture) []error {
+	var validationErrors []error
+	seen := sets.NewString()
+	for i, architecture := range architectures {
+		fieldRootN := fmt.Sprintf("%s[%d]", fieldRoot, i)
+		if err := ValidateArchitecture(fieldRootN, architecture); err != nil {
+			validationErrors = append(validationErrors, err)
+			continue
+		}
+		if seen.Has(string(architecture)) {
+			validationErrors = append(validationErrors, fmt.Errorf("%s: duplicate architecture %s", fieldRootN, architecture))
+		}
+		seen.Insert(string(architecture))
+	}
+	return validationErrors
+}
+
+func validateRPMSigning(fieldRoot string, signing api.RPMSigningConfiguration) []error {
+	var validationErrors []error
+	if signing.Namespace == "" {
+		validationErrors = append(validationErrors, fmt.Errorf("%s.namespace: must be set", fieldRoot))
+	}
+	if signing.Name == "" {
+		validationErrors = append(validationErrors, fmt.Errorf("%s.name: must be set", fieldRoot))
+	}
+	if signing.Key == "" {
+		validationErrors = append(validationErrors, fmt.Errorf("%s.key: must be set", fieldRoot))
+	}
+	return validationErrors
+}
+
+func validateSourceSnapshots(fieldRoot string, config *api.ReleaseBuildConfiguration) []error {
+	var validationErrors []error
+	if len(config.SourceSnapshots) > 0 && config.SourceArchive != nil {
+		validationErrors = append(validationErrors, fmt.Errorf("%s: source snapshots cannot be used together with source_archive", fieldRoot))
+	}
+	seen := sets.NewString()
+	for num, snapshot := range config.SourceSnapshots {
+		fieldRootN := fmt.Sprintf("%s[%d]", fieldRoot, num)
+		if snapshot.As == "" {
+			validationErrors = append(validationErrors, fmt.Errorf("%s.as: value required but not provided", fieldRootN))
+		} else if name := string(api.SourceSnapshotName(snapshot.As)); len(validation.IsDNS1123Subdomain(name)) != 0 {
+			validationErrors = append(validationErrors, fmt.Errorf("%s.as: '%s' is not a valid Kubernetes object name", fieldRootN, name))
+		} else if seen.Has(snapshot.As) {
+			validationErrors = append(validationErrors, fmt.Errorf("%s.as: duplicate source snapshot name '%s'", fieldRootN, snapshot.As))
+		}
+		seen.Insert(snapshot.As)
+		if len(snapshot.Refs) == 0 {
+			validationErrors = append(validationErrors, fmt.Errorf("%s.refs: value required but not provided", fieldRootN))
+		}
+		for i, ref := range snapshot.Refs {
+			fieldRootI := fmt.Sprintf("%s.refs[%d]", fieldRootN, i)
+			if ref.Org == "" || ref.Repo == "" || ref.BaseRef == "" {
+				validationErrors = append(validationErrors, fmt.Errorf("%s: org, repo and base_ref are required", fieldRootI))
+			}
+			for j, pull := range ref.Pulls {
+				if pull.Number <= 0 {
+					validationErrors = append(validationErrors, fmt.Errorf("%s.pulls[%d].number: must be positive", fieldRootI, j))
+				}
+			}
+		}
+	}
+	for num, test := range config.Tests {
+		if test.SourceSnapshot != "" && !seen.Has(test.SourceSnapshot) {
+			validationErrors = append(validationErrors, fmt.Errorf("tests[%d].source_snapshot: no source snapshot named '%s' is defined in %s", num, test.SourceSnapshot, fieldRoot))
+		}
+	}
+	return validationErrors
+}
+
+// validateStepTimeouts validates the timeouts that can be set on steps
+func validateStepTimeouts(fieldRoot string, timeouts api.StepTimeouts) []error {
+	var validationErrors []error
+	if timeouts.Timeout != nil && timeouts.Timeout.Duration <= 0 {
+		validationErrors = append(validationErrors, fmt.Errorf("%s.timeout: must be positive", fieldRoot))
+	}
+	if timeouts.GracePeriod != nil {
+		if timeouts.Timeout == nil {
+			validationErrors = append(validationErrors, fmt.Errorf("%s.grace_period: grace_period requires timeout to be set", fieldRoot))
+		}
+		if timeouts.GracePeriod.Duration < 0 {
+			validationErrors = append(validationErrors, fmt.Errorf("%s.grace_period: must not be negative", fieldRoot))
+		}
+	}
+	return validationErrors
+}
+
+func validateImageScans(fieldRoot string, config *api.ReleaseBuildConfiguration) []error {
+	var validationErrors []error
+	seen := sets.NewString()
+	for _, test := range config.Tests {
+		seen.Insert(test.As)
+	}
+	for num, scan := range config.ImageScans {
+		fieldRootN := fmt.Sprintf("%s[%d]", fieldRoot, num)
+		if scan.As == "" {
+			validationErrors = append(validationErrors, fmt.Errorf("%s.as: value required but not provided", fieldRootN))
+		} else if len(validation.IsDNS1123Label(scan.As)) != 0 {
+			validationErrors = append(validationErrors, fmt.Errorf("%s.as: '%s' is not a valid Kubernetes object name", fieldRootN, scan.As))
+		} else if seen.Has(scan.As) {
+			validationErrors = append(validationErrors, fmt.Errorf("%s.as: duplicated name '%s' already declared in tests or image_scans", fieldRootN, scan.As))
+		}
+		seen.Insert(scan.As)
+		if len(scan.Images) == 0 {
+			validationErrors = append(validationErrors, fmt.Errorf("%s.images: value required but not provided", fieldRootN))
+		}
+		for i, image := range scan.Images {
+			if !config.BuildsImage(string(image)) && !config.IsPipelineImage(string(image)) {
+				validationErrors = append(validationErrors, fmt.Errorf("%s.images[%d]: '%s' is not an image in the pipeline", fieldRootN, i, image))
+			}
+		}
+		if scan.Scanner == "" {
+			validationErrors = append(validationErrors, fmt.Errorf("%s.scanner: value required but not provided", fieldRootN))
+		} else if _, err := reference.Parse(scan.Scanner); err != nil {
+			validationErrors = append(validationErrors, fmt.Errorf("%s.scanner: invalid pull spec %q: %v", fieldRootN, scan.Scanner, err))
+		}
+		if scan.Commands == "" {
+			validationErrors = append(validationErrors, fmt.Errorf("%s.commands: value required but not provided", fieldRootN))
+		}
 	}
 	return validationErrors
 }
@@ -190,9 +414,15 @@ func validateOperator(fieldRoot string, input *api.OperatorStepConfiguration, li
 				validationErrors = append(validationErrors, fmt.Errorf("%s.as: bundle name `%s` matches image defined in `images`", fieldRootN, bundle.As))
 			}
 		}
+		validationErrors = append(validationErrors, validateStepTimeouts(fieldRootN, bundle.StepTimeouts)...)
 		if bundle.As == "" && bundle.BaseIndex != "" {
 			validationErrors = append(validationErrors, fmt.Errorf("%s.base_index: base_index requires as to be set", fieldRootN))
 		}
+		if api.IsPublishedIndex(bundle.BaseIndex) {
+			if _, err := reference.Parse(bundle.BaseIndex); err != nil {
+				validationErrors = append(validationErrors, fmt.Errorf("%s.base_index: invalid pull spec %q: %v", fieldRootN, bundle.BaseIndex, err))
+			}
+		}
 		if bundle.UpdateGraph != "" {
 			if bundle.BaseIndex == "" {
 				validationErrors = append(validationErrors, fmt.Errorf("%s.update_graph: update_graph requires base_index to be set", fieldRootN))
@@ -201,6 +431,22 @@ func validateOperator(fieldRoot string, input *api.OperatorStepConfiguration, li
 				validationErrors = append(validationErrors, fmt.Errorf("%s.update_graph: update_graph must be %s, %s, or %s", fieldRootN, api.IndexUpdateSemver, api.IndexUpdateSemverSkippatch, api.IndexUpdateReplaces))
 			}
 		}
+		if bundle.IndexFormat != "" {
+			if bundle.As == "" {
+				validationErrors = append(validationErrors, fmt.Errorf("%s.index_format: index_format requires as to be set", fieldRootN))
+			}
+			if bundle.IndexFormat != api.IndexFormatSQLite && bundle.IndexFormat != api.IndexFormatFileBasedCatalog {
+				validationErrors = append(validationErrors, fmt.Errorf("%s.index_format: index_format must be %s or %s", fieldRootN, api.IndexFormatSQLite, api.IndexFormatFileBasedCatalog))
+			}
+		}
+		if bundle.OPMImage != "" {
+			if bundle.As == "" {
+				validationErrors = append(validationErrors, fmt.Errorf("%s.opm_image: opm_image requires as to be set", fieldRootN))
+			}
+			if _, err := reference.Parse(bundle.OPMImage); err != nil {
+				validationErrors = append(validationErrors, fmt.Errorf("%s.opm_image: invalid pull spec %q: %v", fieldRootN, bundle.OPMImage, err))
+			}
+		}
 	}
 	for num, sub := range input.Substitutions {
 		fieldRootN := fmt.Sprintf("%s.substitute[%d]", fieldRoot, num)
@@ -231,19 +477,71 @@ func validateImageStreamTagReference(fieldRoot string, input api.ImageStreamTagR
 func validateImageStreamTagReferenceMap(fieldRoot string, input map[string]api.ImageStreamTagReference) []error {
 	var validationErrors []error
 	for k, v := range input {
-		if k == "root" {
-			validationErrors = append(validationErrors, fmt.Errorf("%s.%s can't be named 'root'", fieldRoot, k))
+		validationErrors = append(validationErrors, validateInputImageAlias(fieldRoot, k)...)
+		validationErrors = append(validationErrors, validateImageStreamTagReference(fmt.Sprintf("%s.%s", fieldRoot, k), v)...)
+	}
+	return validationErrors
+}
+
+func validateInputImageAlias(fieldRoot, k string) []error {
+	var validationErrors []error
+	if k == "root" {
+		validationErrors = append(validationErrors, fmt.Errorf("%s.%s can't be named 'root'", fieldRoot, k))
+	}
+	if k == string(api.PipelineImageStreamTagReferenceBundleSource) {
+		validationErrors = append(validationErrors, fmt.Errorf("%s.%s: cannot be named %s", fieldRoot, k, api.PipelineImageStreamTagReferenceBundleSource))
+	}
+	if strings.HasPrefix(k, api.BundlePrefix) {
+		validationErrors = append(validationErrors, fmt.Errorf("%s.%s: cannot begin with `%s`", fieldRoot, k, api.BundlePrefix))
+	}
+	if strings.HasPrefix(k, string(api.PipelineImageStreamTagReferenceIndexImage)) {
+		validationErrors = append(validationErrors, fmt.Errorf("%s.%s: cannot begin with %s", fieldRoot, k, api.PipelineImageStreamTagReferenceIndexImage))
+	}
+	return validationErrors
+}
+
+func validateExternalImages(fieldRoot string, input api.InputConfiguration) []error {
+	var validationErrors []error
+	for k, v := range input.ExternalImages {
+		validationErrors = append(validationErrors, validateInputImageAlias(fieldRoot, k)...)
+		if _, ok := input.BaseImages[k]; ok {
+			validationErrors = append(validationErrors, fmt.Errorf("%s.%s: conflicts with base_images.%s", fieldRoot, k, k))
+		}
+		if _, ok := input.BaseRPMImages[k]; ok {
+			validationErrors = append(validationErrors, fmt.Errorf("%s.%s: conflicts with base_rpm_images.%s", fieldRoot, k, k))
 		}
-		if k == string(api.PipelineImageStreamTagReferenceBundleSource) {
-			validationErrors = append(validationErrors, fmt.Errorf("%s.%s: cannot be named %s", fieldRoot, k, api.PipelineImageStreamTagReferenceBundleSource))
+		validationErrors = append(validationErrors, validateExternalImage(fmt.Sprintf("%s.%s", fieldRoot, k), v)...)
+	}
+	return validationErrors
+}
+
+func validateExternalImage(fieldRoot string, image api.ExternalImage) []error {
+	var validationErrors []error
+	if image.Refresh != nil {
+		if image.Policy != api.ExternalImagePolicyTrack {
+			validationErrors = append(validationErrors, fmt.Errorf("%s.refresh: can only be set when the policy is %s", fieldRoot, api.ExternalImagePolicyTrack))
+		} else if image.Refresh.Duration <= 0 {
+			validationErrors = append(validationErrors, fmt.Errorf("%s.refresh: must be positive", fieldRoot))
 		}
-		if strings.HasPrefix(k, api.BundlePrefix) {
-			validationErrors = append(validationErrors, fmt.Errorf("%s.%s: cannot begin with `%s`", fieldRoot, k, api.BundlePrefix))
+	}
+	if image.PullSpec == "" {
+		return append(validationErrors, fmt.Errorf("%s.pull_spec: value required but not provided", fieldRoot))
+	}
+	ref, err := reference.Parse(image.PullSpec)
+	if err != nil {
+		return append(validationErrors, fmt.Errorf("%s.pull_spec: invalid pull spec %q: %w", fieldRoot, image.PullSpec, err))
+	}
+	switch image.Policy {
+	case "", api.ExternalImagePolicyPin:
+		if ref.ID == "" {
+			validationErrors = append(validationErrors, fmt.Errorf("%s.pull_spec: must reference a digest when the policy is %s", fieldRoot, api.ExternalImagePolicyPin))
 		}
-		if strings.HasPrefix(k, string(api.PipelineImageStreamTagReferenceIndexImage)) {
-			validationErrors = append(validationErrors, fmt.Errorf("%s.%s: cannot begin with %s", fieldRoot, k, api.PipelineImageStreamTagReferenceIndexImage))
+	case api.ExternalImagePolicyTrack:
+		if ref.ID != "" || ref.Tag == "" {
+			validationErrors = append(validationErrors, fmt.Errorf("%s.pull_spec: must reference a tag and no digest when the policy is %s", fieldRoot, api.ExternalImagePolicyTrack))
 		}
-		validationErrors = append(validationErrors, validateImageStreamTagReference(fmt.Sprintf("%s.%s", fieldRoot, k), v)...)
+	default:
+		validationErrors = append(validationErrors, fmt.Errorf("%s.policy: must be one of %s or %s", fieldRoot, api.ExternalImagePolicyPin, api.ExternalImagePolicyTrack))
 	}
 	return validationErrors
 }
@@ -262,9 +560,46 @@ func validatePromotionConfiguration(fieldRoot string, input api.PromotionConfigu
 	if len(input.Name) != 0 && len(input.Tag) != 0 {
 		validationErrors = append(validationErrors, fmt.Errorf("%s: both name and tag defined", fieldRoot))
 	}
+
+	if external := input.ExternalRegistry; external != nil {
+		validationErrors = append(validationErrors, validateExternalRegistryPromotion(fieldRoot+".external_registry", *external, input.Namespace)...)
+	}
 	return validationErrors
 }
 
+// allowedExternalRegistries are the registries images may be promoted to, with the
+// organizations in them. The push credentials of the CI are used for the external
+// registry, so they must not be handed to arbitrary registries.
+var allowedExternalRegistries = map[string]sets.String{
+	"quay.io": sets.NewString("openshift", "openshift-ci"),
+}
+
+// validateExternalRegistryPromotion validates that the images are pushed to an allowed
+// organization of an allowed registry and that the references of the pushed images
+// are valid. The organization defaults to the promotion namespace.
+func validateExternalRegistryPromotion(fieldRoot string, external api.ExternalRegistryPromotion, namespace string) []error {
+	if external.Registry == "" {
+		return []error{fmt.Errorf("%s.registry: must be set", fieldRoot)}
+	}
+	organization, organizationField := external.Organization, "organization"
+	if organization == "" {
+		organization, organizationField = namespace, "organization (defaulted to the promotion namespace)"
+	}
+	// the name of the image does not matter, it is only needed to parse the reference
+	named, err := dockerreference.ParseNamed(fmt.Sprintf("%s/%s/image", external.Registry, organization))
+	if err != nil || dockerreference.Domain(named) != external.Registry {
+		return []error{fmt.Errorf("%s: %q and %q do not form a valid image repository", fieldRoot, external.Registry, organization)}
+	}
+	organizations, allowed := allowedExternalRegistries[external.Registry]
+	if !allowed {
+		return []error{fmt.Errorf("%s.registry: must be one of %s, got %q", fieldRoot, strings.Join(sets.StringKeySet(allowedExternalRegistries).List(), ", "), external.Registry)}
+	}
+	if !organizations.Has(organization) {
+		return []error{fmt.Errorf("%s.%s: must be one of %s for %s, got %q", fieldRoot, organizationField, strings.Join(organizations.List(), ", "), external.Registry, organization)}
+	}
+	return nil
+}
+
 func validateReleaseTagConfiguration(fieldRoot string, input api.ReleaseTagConfiguration) []error {
 	var validationErrors []error
 
@@ -276,6 +611,13 @@ func validateReleaseTagConfiguration(fieldRoot string, input api.ReleaseTagConfi
 		validationErrors = append(validationErrors, fmt.Errorf("%s: no name defined", fieldRoot))
 	}
 
+	if input.Flavor != "" {
+		flavors := sets.NewString(string(api.ReleaseFlavorOCP), string(api.ReleaseFlavorOKD), string(api.ReleaseFlavorSCOS))
+		if !flavors.Has(string(input.Flavor)) {
+			validationErrors = append(validationErrors, fmt.Errorf("%s.flavor: must be one of %s", fieldRoot, strings.Join(flavors.List(), ", ")))
+		}
+	}
+
 	return validationErrors
 }
 
@@ -294,12 +636,45 @@ func validateReleaseBuildConfiguration(input *api.ReleaseBuildConfiguration, org
 		validationErrors = append(validationErrors, errors.New("'base_rpm_images' defined but no 'rpm_build_commands' found"))
 	}
 
+	if len(input.RpmBuildArchitectures) != 0 && len(input.RpmBuildCommands) == 0 {
+		validationErrors = append(validationErrors, errors.New("'rpm_build_architectures' defined but no 'rpm_build_commands' found"))
+	}
+	validationErrors = append(validationErrors, validateRPMBuildArchitectures("rpm_build_architectures", input.RpmBuildArchitectures)...)
+
+	if input.RpmSigning != nil {
+		if len(input.RpmBuildCommands) == 0 {
+			validationErrors = append(validationErrors, errors.New("'rpm_signing' defined but no 'rpm_build_commands' found"))
+		}
+		validationErrors = append(validationErrors, validateRPMSigning("rpm_signing", *input.RpmSigning)...)
+	}
+
 	if org != "" && repo != "" {
 		if input.CanonicalGoRepository != nil && *input.CanonicalGoRepository == fmt.Sprintf("github.com/%s/%s", org, repo) {
 			validationErrors = append(validationErrors, errors.New("'canonical_go_repository' provides the default location, so is unnecessary"))
 		}
 	}
 
+	if input.SourceArchive != nil {
+		if input.SourceArchive.From == "" {
+			validationErrors = append(validationErrors, errors.New("'source_archive.from' must be set"))
+		}
+		if !strings.HasPrefix(input.SourceArchive.Path, "/") {
+			validationErrors = append(validationErrors, errors.New("'source_archive.path' must be an absolute path"))
+		}
+	}
+
+	if input.Clone != nil {
+		if input.SourceArchive != nil {
+			validationErrors = append(validationErrors, errors.New("'clone' cannot be set together with 'source_archive', which is not cloned"))
+		}
+		if input.Clone.Depth < 0 {
+			validationErrors = append(validationErrors, errors.New("'clone.depth' must not be negative"))
+		}
+		if input.Clone.Filter != "" && !cloneFilterRegex.MatchString(input.Clone.Filter) {
+			validationErrors = append(validationErrors, fmt.Errorf("'clone.filter' must be one of blob:none, blob:limit=<n>[kmg] or tree:<depth>, not %q", input.Clone.Filter))
+		}
+	}
+
 	validationErrors = append(validationErrors, validateResources("resources", input.Resources)...)
 	return validationErrors
 }
@@ -326,13 +701,42 @@ func validateResourceRequirements(fieldRoot string, requirements api.ResourceReq
 	validationErrors = append(validationErrors, validateResourceList(fmt.Sprintf("%s.limits", fieldRoot), requirements.Limits)...)
 	validationErrors = append(validationErrors, validateResourceList(fmt.Sprintf("%s.requests", fieldRoot), requirements.Requests)...)
 
-	if len(requirements.Requests) == 0 && len(requirements.Limits) == 0 {
+	profiles := sets.NewString()
+	for _, profile := range api.ClusterProfiles() {
+		profiles.Insert(string(profile))
+	}
+	for profile, override := range requirements.ClusterProfiles {
+		overrideRoot := fmt.Sprintf("%s.cluster_profiles.%s", fieldRoot, profile)
+		if !profiles.Has(string(profile)) {
+			validationErrors = append(validationErrors, fmt.Errorf("%s: invalid cluster profile %q", overrideRoot, profile))
+		}
+		validationErrors = append(validationErrors, validateResourceOverride(overrideRoot, override)...)
+	}
+	for architecture, override := range requirements.Architectures {
+		overrideRoot := fmt.Sprintf("%s.architectures.%s", fieldRoot, architecture)
+		if err := ValidateArchitecture(overrideRoot, architecture); err != nil {
+			validationErrors = append(validationErrors, err)
+		}
+		validationErrors = append(validationErrors, validateResourceOverride(overrideRoot, override)...)
+	}
+
+	if len(requirements.Requests) == 0 && len(requirements.Limits) == 0 && len(requirements.ClusterProfiles) == 0 && len(requirements.Architectures) == 0 {
 		validationErrors = append(validationErrors, fmt.Errorf("'%s' should have at least one request or limit", fieldRoot))
 	}
 
 	return validationErrors
 }
 
+func validateResourceOverride(fieldRoot string, override api.ResourceOverride) []error {
+	var validationErrors []error
+	validationErrors = append(validationErrors, validateResourceList(fmt.Sprintf("%s.limits", fieldRoot), override.Limits)...)
+	validationErrors = append(validationErrors, validateResourceList(fmt.Sprintf("%s.requests", fieldRoot), override.Requests)...)
+	if len(override.Requests) == 0 && len(override.Limits) == 0 {
+		validationErrors = append(validationErrors, fmt.Errorf("'%s' should have at least one request or limit", fieldRoot))
+	}
+	return validationErrors
+}
+
 func validateResourceList(fieldRoot string, list api.ResourceList) []error {
 	var validationErrors []error
 
@@ -5,13 +5,16 @@ import (
 	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 
+	prowv1 "k8s.io/test-infra/prow/apis/prowjobs/v1"
 	"k8s.io/utils/diff"
 	utilpointer "k8s.io/utils/pointer"
 
 	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/testhelper"
 )
 
 func TestValidateBuildRoot(t *testing.T) {
@@ -139,6 +142,209 @@ func TestValidateImageStreamTagReferenceMap(t *testing.T) {
 	}
 }
 
+func TestValidateExternalImages(t *testing.T) {
+	const digest = "sha256:47e2f82dbede8ff990e6e240f82d78830e7558f7b30df7bd8c0693992018b1e3"
+	for _, tc := range []struct {
+		name     string
+		input    api.InputConfiguration
+		expected []error
+	}{
+		{
+			name: "valid",
+			input: api.InputConfiguration{ExternalImages: map[string]api.ExternalImage{
+				"pinned":  {PullSpec: "quay.io/org/image@" + digest},
+				"tracked": {PullSpec: "quay.io/org/image:latest", Policy: api.ExternalImagePolicyTrack, Refresh: &prowv1.Duration{Duration: time.Hour}},
+			}},
+		},
+		{
+			name: "conflicts with base image",
+			input: api.InputConfiguration{
+				BaseImages:     map[string]api.ImageStreamTagReference{"image": {Tag: "tag"}},
+				ExternalImages: map[string]api.ExternalImage{"image": {PullSpec: "quay.io/org/image@" + digest}},
+			},
+			expected: []error{errors.New("external_images.image: conflicts with base_images.image")},
+		},
+		{
+			name:     "invalid alias",
+			input:    api.InputConfiguration{ExternalImages: map[string]api.ExternalImage{"root": {PullSpec: "quay.io/org/image@" + digest}}},
+			expected: []error{errors.New("external_images.root can't be named 'root'")},
+		},
+		{
+			name:     "missing pull spec",
+			input:    api.InputConfiguration{ExternalImages: map[string]api.ExternalImage{"image": {}}},
+			expected: []error{errors.New("external_images.image.pull_spec: value required but not provided")},
+		},
+		{
+			name:     "pinned image without digest",
+			input:    api.InputConfiguration{ExternalImages: map[string]api.ExternalImage{"image": {PullSpec: "quay.io/org/image:latest", Policy: api.ExternalImagePolicyPin}}},
+			expected: []error{errors.New("external_images.image.pull_spec: must reference a digest when the policy is pin")},
+		},
+		{
+			name:     "tracked image with digest",
+			input:    api.InputConfiguration{ExternalImages: map[string]api.ExternalImage{"image": {PullSpec: "quay.io/org/image@" + digest, Policy: api.ExternalImagePolicyTrack}}},
+			expected: []error{errors.New("external_images.image.pull_spec: must reference a tag and no digest when the policy is track")},
+		},
+		{
+			name:     "refresh on pinned image",
+			input:    api.InputConfiguration{ExternalImages: map[string]api.ExternalImage{"image": {PullSpec: "quay.io/org/image@" + digest, Refresh: &prowv1.Duration{Duration: time.Hour}}}},
+			expected: []error{errors.New("external_images.image.refresh: can only be set when the policy is track")},
+		},
+		{
+			name:     "unknown policy",
+			input:    api.InputConfiguration{ExternalImages: map[string]api.ExternalImage{"image": {PullSpec: "quay.io/org/image:latest", Policy: "latest"}}},
+			expected: []error{errors.New("external_images.image.policy: must be one of pin or track")},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.expected, validateExternalImages("external_images", tc.input), testhelper.EquateErrorMessage); diff != "" {
+				t.Errorf("errors differ from expected: %s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateSourceSnapshots(t *testing.T) {
+	refs := []prowv1.Refs{{Org: "org", Repo: "repo", BaseRef: "master", Pulls: []prowv1.Pull{{Number: 1}}}}
+	for _, tc := range []struct {
+		name     string
+		input    *api.ReleaseBuildConfiguration
+		expected []error
+	}{
+		{
+			name: "valid",
+			input: &api.ReleaseBuildConfiguration{
+				SourceSnapshots: []api.SourceSnapshot{{As: "with-pr", Refs: refs}},
+				Tests:           []api.TestStepConfiguration{{As: "e2e", SourceSnapshot: "with-pr"}, {As: "unit"}},
+			},
+		},
+		{
+			name: "invalid snapshots",
+			input: &api.ReleaseBuildConfiguration{
+				SourceSnapshots: []api.SourceSnapshot{
+					{Refs: refs},
+					{As: "With_PR", Refs: refs},
+					{As: "with-pr", Refs: refs},
+					{As: "with-pr", Refs: []prowv1.Refs{{Org: "org", Repo: "repo", Pulls: []prowv1.Pull{{}}}}},
+					{As: "no-refs"},
+				},
+			},
+			expected: []error{
+				errors.New("source_snapshots[0].as: value required but not provided"),
+				errors.New("source_snapshots[1].as: 'src-With_PR' is not a valid Kubernetes object name"),
+				errors.New("source_snapshots[3].as: duplicate source snapshot name 'with-pr'"),
+				errors.New("source_snapshots[3].refs[0]: org, repo and base_ref are required"),
+				errors.New("source_snapshots[3].refs[0].pulls[0].number: must be positive"),
+				errors.New("source_snapshots[4].refs: value required but not provided"),
+			},
+		},
+		{
+			name: "used with source archive",
+			input: &api.ReleaseBuildConfiguration{
+				SourceArchive:   &api.SourceArchive{From: "archive", Path: "/src.tar"},
+				SourceSnapshots: []api.SourceSnapshot{{As: "with-pr", Refs: refs}},
+			},
+			expected: []error{errors.New("source_snapshots: source snapshots cannot be used together with source_archive")},
+		},
+		{
+			name: "test references unknown snapshot",
+			input: &api.ReleaseBuildConfiguration{
+				Tests: []api.TestStepConfiguration{{As: "e2e", SourceSnapshot: "with-pr"}},
+			},
+			expected: []error{errors.New("tests[0].source_snapshot: no source snapshot named 'with-pr' is defined in source_snapshots")},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.expected, validateSourceSnapshots("source_snapshots", tc.input), testhelper.EquateErrorMessage); diff != "" {
+				t.Errorf("errors differ from expected: %s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateImageScans(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		input    *api.ReleaseBuildConfiguration
+		expected []error
+	}{
+		{
+			name: "valid",
+			input: &api.ReleaseBuildConfiguration{
+				Images: []api.ProjectDirectoryImageBuildStepConfiguration{{To: "operator"}},
+				ImageScans: []api.ImageScanStepConfiguration{
+					{As: "cves", Images: []api.PipelineImageStreamTagReference{"operator", "src"}, Scanner: "quay.io/anchore/grype:latest", Commands: "grype ${IMAGE}"},
+					{As: "licenses", Images: []api.PipelineImageStreamTagReference{"operator"}, Scanner: "quay.io/org/licenses", Commands: "scan", Informational: true},
+				},
+			},
+		},
+		{
+			name: "invalid scans",
+			input: &api.ReleaseBuildConfiguration{
+				Tests: []api.TestStepConfiguration{{As: "unit"}},
+				ImageScans: []api.ImageScanStepConfiguration{
+					{Images: []api.PipelineImageStreamTagReference{"src"}, Scanner: "quay.io/anchore/grype", Commands: "grype ${IMAGE}"},
+					{As: "CVEs", Images: []api.PipelineImageStreamTagReference{"src"}, Scanner: "quay.io/anchore/grype", Commands: "grype ${IMAGE}"},
+					{As: "unit", Images: []api.PipelineImageStreamTagReference{"unknown"}, Scanner: "quay.io/Anchore/grype", Commands: "grype ${IMAGE}"},
+					{As: "empty"},
+				},
+			},
+			expected: []error{
+				errors.New("image_scans[0].as: value required but not provided"),
+				errors.New("image_scans[1].as: 'CVEs' is not a valid Kubernetes object name"),
+				errors.New("image_scans[2].as: duplicated name 'unit' already declared in tests or image_scans"),
+				errors.New("image_scans[2].images[0]: 'unknown' is not an image in the pipeline"),
+				errors.New(`image_scans[2].scanner: invalid pull spec "quay.io/Anchore/grype": repository name must be lowercase`),
+				errors.New("image_scans[3].images: value required but not provided"),
+				errors.New("image_scans[3].scanner: value required but not provided"),
+				errors.New("image_scans[3].commands: value required but not provided"),
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.expected, validateImageScans("image_scans", tc.input), testhelper.EquateErrorMessage); diff != "" {
+				t.Errorf("errors differ from expected: %s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateStepTimeouts(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		input    api.StepTimeouts
+		expected []error
+	}{
+		{
+			name: "no timeouts",
+		},
+		{
+			name:  "valid timeouts",
+			input: api.StepTimeouts{Timeout: &prowv1.Duration{Duration: time.Hour}, GracePeriod: &prowv1.Duration{Duration: time.Minute}},
+		},
+		{
+			name:  "invalid timeouts",
+			input: api.StepTimeouts{Timeout: &prowv1.Duration{}, GracePeriod: &prowv1.Duration{Duration: -time.Minute}},
+			expected: []error{
+				errors.New("images[0].timeout: must be positive"),
+				errors.New("images[0].grace_period: must not be negative"),
+			},
+		},
+		{
+			name:  "grace period without timeout",
+			input: api.StepTimeouts{GracePeriod: &prowv1.Duration{Duration: time.Minute}},
+			expected: []error{
+				errors.New("images[0].grace_period: grace_period requires timeout to be set"),
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.expected, validateStepTimeouts("images[0]", tc.input), testhelper.EquateErrorMessage); diff != "" {
+				t.Errorf("errors differ from expected: %s", diff)
+			}
+		})
+	}
+}
+
 func TestValidateResources(t *testing.T) {
 	for _, testCase := range []struct {
 		name        string
@@ -249,6 +455,69 @@ func TestValidateResources(t *testing.T) {
 			},
 			expectedErr: true,
 		},
+		{
+			name: "valid overrides make no error",
+			input: api.ResourceConfiguration{
+				"*": api.ResourceRequirements{
+					Requests: api.ResourceList{"cpu": "100m"},
+					ClusterProfiles: map[api.ClusterProfile]api.ResourceOverride{
+						api.ClusterProfileAWS: {Requests: api.ResourceList{"memory": "1Gi"}},
+					},
+					Architectures: map[api.ReleaseArchitecture]api.ResourceOverride{
+						api.ReleaseArchitectureARM64: {Limits: api.ResourceList{"cpu": "2"}},
+					},
+				},
+			},
+			expectedErr: false,
+		},
+		{
+			name: "override for an unknown cluster profile makes an error",
+			input: api.ResourceConfiguration{
+				"*": api.ResourceRequirements{
+					Requests: api.ResourceList{"cpu": "100m"},
+					ClusterProfiles: map[api.ClusterProfile]api.ResourceOverride{
+						"donkeys": {Requests: api.ResourceList{"memory": "1Gi"}},
+					},
+				},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "override for an unknown architecture makes an error",
+			input: api.ResourceConfiguration{
+				"*": api.ResourceRequirements{
+					Requests: api.ResourceList{"cpu": "100m"},
+					Architectures: map[api.ReleaseArchitecture]api.ResourceOverride{
+						"donkeys": {Requests: api.ResourceList{"memory": "1Gi"}},
+					},
+				},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "empty override makes an error",
+			input: api.ResourceConfiguration{
+				"*": api.ResourceRequirements{
+					Requests: api.ResourceList{"cpu": "100m"},
+					Architectures: map[api.ReleaseArchitecture]api.ResourceOverride{
+						api.ReleaseArchitectureARM64: {},
+					},
+				},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "invalid value in an override makes an error",
+			input: api.ResourceConfiguration{
+				"*": api.ResourceRequirements{
+					Requests: api.ResourceList{"cpu": "100m"},
+					ClusterProfiles: map[api.ClusterProfile]api.ResourceOverride{
+						api.ClusterProfileAWS: {Limits: api.ResourceList{"cpu": "-1"}},
+					},
+				},
+			},
+			expectedErr: true,
+		},
 	} {
 		t.Run(testCase.name, func(t *testing.T) {
 			err := validateResources("", testCase.input)
@@ -288,6 +557,46 @@ func TestValidatePromotion(t *testing.T) {
 			input:    api.PromotionConfiguration{Namespace: "foo", Name: "bar", Tag: "baz"},
 			expected: []error{errors.New("promotion: both name and tag defined")},
 		},
+		{
+			name:     "external registry is valid",
+			input:    api.PromotionConfiguration{Namespace: "foo", Name: "bar", ExternalRegistry: &api.ExternalRegistryPromotion{Registry: "quay.io", Organization: "openshift"}},
+			expected: nil,
+		},
+		{
+			name:     "external registry without registry yields an error",
+			input:    api.PromotionConfiguration{Namespace: "foo", Name: "bar", ExternalRegistry: &api.ExternalRegistryPromotion{Organization: "openshift"}},
+			expected: []error{errors.New("promotion.external_registry.registry: must be set")},
+		},
+		{
+			name:     "external registry with a repository and a tagged organization yields an error",
+			input:    api.PromotionConfiguration{Namespace: "foo", Name: "bar", ExternalRegistry: &api.ExternalRegistryPromotion{Registry: "quay.io/openshift", Organization: "openshift:latest"}},
+			expected: []error{errors.New(`promotion.external_registry: "quay.io/openshift" and "openshift:latest" do not form a valid image repository`)},
+		},
+		{
+			name:     "external registry with shell syntax yields an error",
+			input:    api.PromotionConfiguration{Namespace: "foo", Name: "bar", ExternalRegistry: &api.ExternalRegistryPromotion{Registry: "quay.io", Organization: "openshift $(id)"}},
+			expected: []error{errors.New(`promotion.external_registry: "quay.io" and "openshift $(id)" do not form a valid image repository`)},
+		},
+		{
+			name:     "external registry that is not allowed yields an error",
+			input:    api.PromotionConfiguration{Namespace: "foo", Name: "bar", ExternalRegistry: &api.ExternalRegistryPromotion{Registry: "registry.example.com", Organization: "openshift"}},
+			expected: []error{errors.New(`promotion.external_registry.registry: must be one of quay.io, got "registry.example.com"`)},
+		},
+		{
+			name:     "organization that is not allowed yields an error",
+			input:    api.PromotionConfiguration{Namespace: "foo", Name: "bar", ExternalRegistry: &api.ExternalRegistryPromotion{Registry: "quay.io", Organization: "someone"}},
+			expected: []error{errors.New(`promotion.external_registry.organization: must be one of openshift, openshift-ci for quay.io, got "someone"`)},
+		},
+		{
+			name:     "organization defaulted to a namespace that is not allowed yields an error",
+			input:    api.PromotionConfiguration{Namespace: "foo", Name: "bar", ExternalRegistry: &api.ExternalRegistryPromotion{Registry: "quay.io"}},
+			expected: []error{errors.New(`promotion.external_registry.organization (defaulted to the promotion namespace): must be one of openshift, openshift-ci for quay.io, got "foo"`)},
+		},
+		{
+			name:     "organization defaulted to an allowed namespace is valid",
+			input:    api.PromotionConfiguration{Namespace: "openshift", Name: "bar", ExternalRegistry: &api.ExternalRegistryPromotion{Registry: "quay.io"}},
+			expected: nil,
+		},
 	}
 	for _, test := range testCases {
 		t.Run(test.name, func(t *testing.T) {
@@ -309,6 +618,16 @@ func TestValidateReleaseTagConfiguration(t *testing.T) {
 			input:    api.ReleaseTagConfiguration{Name: "test", Namespace: "test"},
 			expected: nil,
 		},
+		{
+			name:     "valid tag_specification with a flavor",
+			input:    api.ReleaseTagConfiguration{Name: "scos-4.12", Namespace: "origin", Flavor: api.ReleaseFlavorSCOS},
+			expected: nil,
+		},
+		{
+			name:     "unknown flavor",
+			input:    api.ReleaseTagConfiguration{Name: "test", Namespace: "test", Flavor: "fcos"},
+			expected: []error{errors.New("tag_specification.flavor: must be one of ocp, okd, okd-scos")},
+		},
 	}
 	for _, testCase := range testCases {
 		t.Run(testCase.name, func(t *testing.T) {
@@ -485,6 +804,103 @@ func TestValidateImages(t *testing.T) {
 				fmt.Errorf("images[0].build_args[0]: name must be set"),
 			},
 		},
+		{
+			name: "build args from the job spec",
+			input: []api.ProjectDirectoryImageBuildStepConfiguration{{
+				ProjectDirectoryImageBuildInputs: api.ProjectDirectoryImageBuildInputs{
+					BuildArgs: []api.BuildArg{
+						{Name: "COMMIT", FromJobSpec: "PULL_BASE_SHA"},
+						{Name: "PR", FromJobSpec: "PULL_NUMBER"},
+					},
+				},
+				To: "amsterdam",
+			}},
+		},
+		{
+			name: "build args from the job spec must be known and exclusive",
+			input: []api.ProjectDirectoryImageBuildStepConfiguration{{
+				ProjectDirectoryImageBuildInputs: api.ProjectDirectoryImageBuildInputs{
+					BuildArgs: []api.BuildArg{
+						{Name: "COMMIT", Value: "abc", FromJobSpec: "PULL_BASE_SHA"},
+						{Name: "HOME", FromJobSpec: "HOME"},
+					},
+				},
+				To: "amsterdam",
+			}},
+			output: []error{
+				errors.New("images[0].build_args[0]: from_job_spec is mutually exclusive with value and value_from"),
+				errors.New("images[0].build_args[1]: from_job_spec must be one of BUILD_ID, BUILD_NUMBER, CI, JOB_NAME, JOB_SPEC, JOB_TYPE, PROW_JOB_ID, PULL_BASE_REF, PULL_BASE_SHA, PULL_NUMBER, PULL_PULL_SHA, PULL_REFS, REPO_NAME, REPO_OWNER"),
+			},
+		},
+		{
+			name: "multi-arch image",
+			input: []api.ProjectDirectoryImageBuildStepConfiguration{{
+				To:            "amsterdam",
+				Architectures: []api.ReleaseArchitecture{api.ReleaseArchitectureAMD64, api.ReleaseArchitectureARM64},
+			}},
+		},
+		{
+			name: "architectures must be known and unique",
+			input: []api.ProjectDirectoryImageBuildStepConfiguration{{
+				To:            "amsterdam",
+				Architectures: []api.ReleaseArchitecture{api.ReleaseArchitectureAMD64, "sparc", api.ReleaseArchitectureAMD64},
+			}},
+			output: []error{
+				errors.New("images[0].architectures[1]: must be one of amd64, arm64, ppc64le, s390x"),
+				errors.New("images[0].architectures[2]: duplicate architecture amd64"),
+			},
+		},
+		{
+			name: "image for an architecture cannot conflict with another image",
+			input: []api.ProjectDirectoryImageBuildStepConfiguration{
+				{To: "amsterdam", Architectures: []api.ReleaseArchitecture{api.ReleaseArchitectureARM64}},
+				{To: "amsterdam-arm64"},
+			},
+			output: []error{
+				errors.New("images[0].architectures[0]: the image for arm64 is tagged as amsterdam-arm64, which conflicts with another image"),
+			},
+		},
+		{
+			name:  "image built in a buildah pod",
+			input: []api.ProjectDirectoryImageBuildStepConfiguration{{To: "amsterdam", BuildBackend: api.BuildBackendBuildahPod}},
+		},
+		{
+			name: "buildah pods cannot build for architectures or with entitlements",
+			input: []api.ProjectDirectoryImageBuildStepConfiguration{
+				{To: "amsterdam", BuildBackend: api.BuildBackendBuildahPod, Architectures: []api.ReleaseArchitecture{api.ReleaseArchitectureARM64}},
+				{To: "rotterdam", BuildBackend: api.BuildBackendBuildahPod, RequiresEntitlement: true},
+			},
+			output: []error{
+				errors.New("images[0].build_backend: buildah-pod cannot be used with architectures"),
+				errors.New("images[1].build_backend: buildah-pod cannot be used with requires_entitlement"),
+			},
+		},
+		{
+			name:   "unknown build backend",
+			input:  []api.ProjectDirectoryImageBuildStepConfiguration{{To: "amsterdam", BuildBackend: "docker"}},
+			output: []error{errors.New("images[0].build_backend: must be one of openshift, buildah-pod")},
+		},
+		{
+			name: "image with additional names",
+			input: []api.ProjectDirectoryImageBuildStepConfiguration{
+				{To: "driver", AdditionalNames: []string{"driver-rhel8"}},
+				{To: "operator"},
+			},
+		},
+		{
+			name: "additional names cannot be empty, repeated or name another image",
+			input: []api.ProjectDirectoryImageBuildStepConfiguration{
+				{To: "driver", AdditionalNames: []string{"", "driver-rhel8", "driver-rhel8", "operator"}},
+				{To: "operator", AdditionalNames: []string{"driver-rhel8", "driver"}},
+			},
+			output: []error{
+				errors.New("images[0].additional_names[0]: must not be empty"),
+				errors.New("images[0].additional_names[2]: duplicate name driver-rhel8"),
+				errors.New("images[0].additional_names[3]: operator is already the name of images[1]"),
+				errors.New("images[1].additional_names[0]: driver-rhel8 is already an additional name of images[0]"),
+				errors.New("images[1].additional_names[1]: driver is already the name of images[0]"),
+			},
+		},
 	}
 	for _, testCase := range testCases {
 		t.Run(testCase.name, func(t *testing.T) {
@@ -497,6 +913,111 @@ func TestValidateImages(t *testing.T) {
 	}
 }
 
+func TestValidateRPMServing(t *testing.T) {
+	var testCases = []struct {
+		name   string
+		input  api.ReleaseBuildConfiguration
+		output []error
+	}{
+		{
+			name: "signed RPMs for multiple architectures",
+			input: api.ReleaseBuildConfiguration{
+				RpmBuildCommands:      "make rpms",
+				RpmBuildArchitectures: []api.ReleaseArchitecture{api.ReleaseArchitectureAMD64, api.ReleaseArchitectureARM64},
+				RpmSigning:            &api.RPMSigningConfiguration{Namespace: "ci", Name: "rpm-signing", Key: "private.key"},
+			},
+		},
+		{
+			name: "architectures and signing require an RPM build",
+			input: api.ReleaseBuildConfiguration{
+				RpmBuildArchitectures: []api.ReleaseArchitecture{api.ReleaseArchitectureAMD64},
+				RpmSigning:            &api.RPMSigningConfiguration{Namespace: "ci", Name: "rpm-signing", Key: "private.key"},
+			},
+			output: []error{
+				errors.New("'rpm_build_architectures' defined but no 'rpm_build_commands' found"),
+				errors.New("'rpm_signing' defined but no 'rpm_build_commands' found"),
+			},
+		},
+		{
+			name: "invalid architectures and incomplete signing",
+			input: api.ReleaseBuildConfiguration{
+				RpmBuildCommands:      "make rpms",
+				RpmBuildArchitectures: []api.ReleaseArchitecture{api.ReleaseArchitectureAMD64, "x86_64", api.ReleaseArchitectureAMD64},
+				RpmSigning:            &api.RPMSigningConfiguration{Name: "rpm-signing"},
+			},
+			output: []error{
+				errors.New("rpm_build_architectures[1]: must be one of amd64, arm64, ppc64le, s390x"),
+				errors.New("rpm_build_architectures[2]: duplicate architecture amd64"),
+				errors.New("rpm_signing.namespace: must be set"),
+				errors.New("rpm_signing.key: must be set"),
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.input.Tests = []api.TestStepConfiguration{{As: "unit"}}
+			testCase.input.Resources = api.ResourceConfiguration{"*": {Requests: api.ResourceList{"cpu": "1"}}}
+			if diff := cmp.Diff(testCase.output, validateReleaseBuildConfiguration(&testCase.input, "", ""), cmp.Comparer(func(x, y error) bool {
+				return x.Error() == y.Error()
+			})); diff != "" {
+				t.Errorf("got incorrect errors: %s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateClone(t *testing.T) {
+	var testCases = []struct {
+		name   string
+		input  api.ReleaseBuildConfiguration
+		output []error
+	}{
+		{
+			name: "shallow clone without submodules",
+			input: api.ReleaseBuildConfiguration{
+				Clone: &api.CloneConfiguration{Depth: 1, SkipSubmodules: true},
+			},
+		},
+		{
+			name: "partial clone",
+			input: api.ReleaseBuildConfiguration{
+				Clone: &api.CloneConfiguration{Filter: "blob:limit=1m"},
+			},
+		},
+		{
+			name: "unsupported filter",
+			input: api.ReleaseBuildConfiguration{
+				Clone: &api.CloneConfiguration{Filter: "sparse:oid=master:.sparse"},
+			},
+			output: []error{
+				errors.New(`'clone.filter' must be one of blob:none, blob:limit=<n>[kmg] or tree:<depth>, not "sparse:oid=master:.sparse"`),
+			},
+		},
+		{
+			name: "negative depth and a source archive",
+			input: api.ReleaseBuildConfiguration{
+				SourceArchive: &api.SourceArchive{From: "archive", Path: "/src.tar"},
+				Clone:         &api.CloneConfiguration{Depth: -1},
+			},
+			output: []error{
+				errors.New("'clone' cannot be set together with 'source_archive', which is not cloned"),
+				errors.New("'clone.depth' must not be negative"),
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.input.Tests = []api.TestStepConfiguration{{As: "unit"}}
+			testCase.input.Resources = api.ResourceConfiguration{"*": {Requests: api.ResourceList{"cpu": "1"}}}
+			if diff := cmp.Diff(testCase.output, validateReleaseBuildConfiguration(&testCase.input, "", ""), cmp.Comparer(func(x, y error) bool {
+				return x.Error() == y.Error()
+			})); diff != "" {
+				t.Errorf("got incorrect errors: %s", diff)
+			}
+		})
+	}
+}
+
 func TestValidateOperator(t *testing.T) {
 	var goodStepLink = api.AllStepsLink()
 	var badStepLink api.StepLink
@@ -638,6 +1159,68 @@ func TestValidateOperator(t *testing.T) {
 				errors.New("operator.bundles[0].update_graph: update_graph must be semver, semver-skippatch, or replaces"),
 			},
 		},
+		{
+			name: "bundle with published base_index",
+			input: &api.OperatorStepConfiguration{
+				Bundles: []api.Bundle{{
+					As:          "valid bundle",
+					BaseIndex:   "registry.redhat.io/redhat/redhat-operator-index:v4.8",
+					UpdateGraph: "replaces",
+				}},
+			},
+			withResolvesTo: goodStepLink,
+		},
+		{
+			name: "bundle with invalid published base_index",
+			input: &api.OperatorStepConfiguration{
+				Bundles: []api.Bundle{{
+					As:        "valid bundle",
+					BaseIndex: "registry.redhat.io/Redhat/index:v4.8",
+				}},
+			},
+			withResolvesTo: goodStepLink,
+			output: []error{
+				errors.New(`operator.bundles[0].base_index: invalid pull spec "registry.redhat.io/Redhat/index:v4.8": repository name must be lowercase`),
+			},
+		},
+		{
+			name: "file-based catalog with custom opm image",
+			input: &api.OperatorStepConfiguration{
+				Bundles: []api.Bundle{{
+					As:          "valid bundle",
+					IndexFormat: api.IndexFormatFileBasedCatalog,
+					OPMImage:    "quay.io/operator-framework/upstream-opm-builder:v1.19.0",
+				}},
+			},
+			withResolvesTo: goodStepLink,
+		},
+		{
+			name: "invalid index_format",
+			input: &api.OperatorStepConfiguration{
+				Bundles: []api.Bundle{{
+					As:          "valid bundle",
+					IndexFormat: "hello",
+				}},
+			},
+			withResolvesTo: goodStepLink,
+			output: []error{
+				errors.New("operator.bundles[0].index_format: index_format must be sqlite or file-based-catalog"),
+			},
+		},
+		{
+			name: "bundle set with index_format and opm_image but not as set",
+			input: &api.OperatorStepConfiguration{
+				Bundles: []api.Bundle{{
+					IndexFormat: api.IndexFormatFileBasedCatalog,
+					OPMImage:    "quay.io/operator-framework/upstream-opm-builder:v1.19.0",
+				}},
+			},
+			withResolvesTo: goodStepLink,
+			output: []error{
+				errors.New("operator.bundles[0].index_format: index_format requires as to be set"),
+				errors.New("operator.bundles[0].opm_image: opm_image requires as to be set"),
+			},
+		},
 	}
 	for _, testCase := range testCases {
 		t.Run(testCase.name, func(t *testing.T) {
@@ -706,6 +1289,7 @@ func TestReleaseBuildConfiguration_validateTestStepDependencies(t *testing.T) {
 							{LiteralTestStep: &api.LiteralTestStep{Dependencies: []api.StepDependency{{Name: "src"}, {Name: "bin"}, {Name: "installer"}, {Name: "pipeline:ci-index"}}}},
 							{LiteralTestStep: &api.LiteralTestStep{Dependencies: []api.StepDependency{{Name: "pipeline:my-bundle"}}}},
 							{LiteralTestStep: &api.LiteralTestStep{Dependencies: []api.StepDependency{{Name: "stable:installer"}, {Name: "stable-initial:installer"}}}},
+							{LiteralTestStep: &api.LiteralTestStep{Dependencies: []api.StepDependency{{Name: "installer", Release: "initial"}, {Name: "cli", Release: "custom"}}}},
 						},
 						Test: []api.TestStep{{LiteralTestStep: &api.LiteralTestStep{Dependencies: []api.StepDependency{{Name: "pipeline:bin"}}}}},
 						Post: []api.TestStep{{LiteralTestStep: &api.LiteralTestStep{Dependencies: []api.StepDependency{{Name: "image"}}}}},
@@ -738,7 +1322,7 @@ func TestReleaseBuildConfiguration_validateTestStepDependencies(t *testing.T) {
 							{Dependencies: []api.StepDependency{{Name: "release:custom"}, {Name: "pipeline:ci-index"}}},
 							{Dependencies: []api.StepDependency{{Name: "pipeline:ci-index-my-bundle"}}}},
 						Test: []api.LiteralTestStep{{Dependencies: []api.StepDependency{{Name: "pipeline:root"}}}},
-						Post: []api.LiteralTestStep{{Dependencies: []api.StepDependency{{Name: "pipeline:rpms"}}}},
+						Post: []api.LiteralTestStep{{Dependencies: []api.StepDependency{{Name: "pipeline:rpms"}, {Name: "installer", Release: "previous"}}}},
 					}},
 				},
 			},
@@ -755,6 +1339,7 @@ func TestReleaseBuildConfiguration_validateTestStepDependencies(t *testing.T) {
 				errors.New(`tests[1].literal_steps.pre[1].dependencies[0]: cannot determine source for dependency "pipeline:ci-index-my-bundle" - this dependency requires an operator bundle configuration, which is not configured`),
 				errors.New(`tests[1].literal_steps.test[0].dependencies[0]: cannot determine source for dependency "pipeline:root" - this dependency requires a build root, which is not configured`),
 				errors.New(`tests[1].literal_steps.post[0].dependencies[0]: cannot determine source for dependency "pipeline:rpms" - this dependency requires built RPMs, which are not configured`),
+				errors.New(`tests[1].literal_steps.post[0].dependencies[1]: cannot determine source for dependency "installer" - this dependency requires a "previous" release, which is not configured`),
 			},
 		},
 	}
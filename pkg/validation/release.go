@@ -59,13 +59,13 @@ func validateCandidate(fieldRoot string, candidate api.Candidate) []error {
 
 	// we allow an unset architecture, we will default it later
 	if candidate.Architecture != "" {
-		if err := validateArchitecture(fmt.Sprintf("%s.architecture", fieldRoot), candidate.Architecture); err != nil {
+		if err := ValidateArchitecture(fmt.Sprintf("%s.architecture", fieldRoot), candidate.Architecture); err != nil {
 			validationErrors = append(validationErrors, err)
 		}
 	}
 
 	streamsByProduct := map[api.ReleaseProduct]sets.String{
-		api.ReleaseProductOKD: sets.NewString("", string(api.ReleaseStreamOKD)), // we allow unset and will default it
+		api.ReleaseProductOKD: sets.NewString("", string(api.ReleaseStreamOKD), string(api.ReleaseStreamOKDSCOS)), // we allow unset and will default it
 		api.ReleaseProductOCP: sets.NewString(string(api.ReleaseStreamCI), string(api.ReleaseStreamNightly)),
 	}
 	if !streamsByProduct[candidate.Product].Has(string(candidate.Stream)) {
@@ -91,8 +91,9 @@ func validateProduct(fieldRoot string, product api.ReleaseProduct) error {
 	return nil
 }
 
-func validateArchitecture(fieldRoot string, architecture api.ReleaseArchitecture) error {
-	architectures := sets.NewString(string(api.ReleaseArchitectureAMD64), string(api.ReleaseArchitecturePPC64le), string(api.ReleaseArchitectureS390x))
+// ValidateArchitecture validates the architecture is one a release can be built for
+func ValidateArchitecture(fieldRoot string, architecture api.ReleaseArchitecture) error {
+	architectures := sets.NewString(string(api.ReleaseArchitectureAMD64), string(api.ReleaseArchitectureARM64), string(api.ReleaseArchitecturePPC64le), string(api.ReleaseArchitectureS390x))
 	if !architectures.Has(string(architecture)) {
 		return fmt.Errorf("%s: must be one of %s", fieldRoot, strings.Join(architectures.List(), ", "))
 	}
@@ -110,7 +111,7 @@ func validateRelease(fieldRoot string, release api.Release) []error {
 	var validationErrors []error
 	// we allow an unset architecture, we will default it later
 	if release.Architecture != "" {
-		if err := validateArchitecture(fmt.Sprintf("%s.architecture", fieldRoot), release.Architecture); err != nil {
+		if err := ValidateArchitecture(fmt.Sprintf("%s.architecture", fieldRoot), release.Architecture); err != nil {
 			validationErrors = append(validationErrors, err)
 		}
 	}
@@ -137,7 +138,7 @@ func validatePrerelease(fieldRoot string, prerelease api.Prerelease) []error {
 
 	// we allow an unset architecture, we will default it later
 	if prerelease.Architecture != "" {
-		if err := validateArchitecture(fmt.Sprintf("%s.architecture", fieldRoot), prerelease.Architecture); err != nil {
+		if err := ValidateArchitecture(fmt.Sprintf("%s.architecture", fieldRoot), prerelease.Architecture); err != nil {
 			validationErrors = append(validationErrors, err)
 		}
 	}
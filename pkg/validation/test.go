@@ -12,6 +12,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation"
+	prowv1 "k8s.io/test-infra/prow/apis/prowjobs/v1"
 
 	"github.com/openshift/ci-tools/pkg/api"
 )
@@ -58,6 +59,23 @@ func (c *context) forField(name string) context {
 
 var trapPattern = regexp.MustCompile(`(^|\W)\s*trap\s*['"]?\w*['"]?\s*\w*`)
 
+// reservedCredentialEnv are the environment variables ci-operator sets in
+// test step containers, which credentials must not override
+var reservedCredentialEnv = sets.NewString(
+	"ARTIFACT_DIR",
+	"CLUSTER_PROFILE_DIR",
+	"CLUSTER_PROFILE_NAME",
+	"CLUSTER_TYPE",
+	"INIT_DIR",
+	"JOB_NAME_HASH",
+	"JOB_NAME_SAFE",
+	"KUBEADMIN_PASSWORD_FILE",
+	"KUBECONFIG",
+	"LEASED_RESOURCE",
+	"NAMESPACE",
+	"SHARED_DIR",
+)
+
 // IsValidReference validates the contents of a registry reference.
 // Checks that are context-dependent (whether all parameters are set in a parent
 // component, the image references exist in the test configuration, etc.) are
@@ -89,6 +107,8 @@ func validateTestStepConfiguration(fieldRoot string, input []api.TestStepConfigu
 			validationErrors = append(validationErrors, fmt.Errorf("%s: `commands`, `steps`, and `literal_steps` are mutually exclusive", fieldRootN))
 		}
 
+		validationErrors = append(validationErrors, validateStepTimeouts(fieldRootN, test.StepTimeouts)...)
+
 		if test.Postsubmit && test.Cron != nil {
 			validationErrors = append(validationErrors, fmt.Errorf("%s: `cron` and `postsubmit` are mututally exclusive", fieldRootN))
 		}
@@ -106,6 +126,21 @@ func validateTestStepConfiguration(fieldRoot string, input []api.TestStepConfigu
 			validationErrors = append(validationErrors, fmt.Errorf("%s: `interval` cannot be set for release controller jobs", fieldRootN))
 		}
 
+		if len(test.Architectures) != 0 && test.Postsubmit {
+			validationErrors = append(validationErrors, fmt.Errorf("%s.architectures: cannot be set for postsubmits", fieldRootN))
+		}
+		seenArchitectures := sets.NewString()
+		for i, architecture := range test.Architectures {
+			fieldRootI := fmt.Sprintf("%s.architectures[%d]", fieldRootN, i)
+			if err := ValidateArchitecture(fieldRootI, architecture); err != nil {
+				validationErrors = append(validationErrors, err)
+			}
+			if seenArchitectures.Has(string(architecture)) {
+				validationErrors = append(validationErrors, fmt.Errorf("%s: duplicate architecture %s", fieldRootI, architecture))
+			}
+			seenArchitectures.Insert(string(architecture))
+		}
+
 		if test.Interval != nil {
 			if _, err := time.ParseDuration(*test.Interval); err != nil {
 				validationErrors = append(validationErrors, fmt.Errorf("%s: cannot parse interval: %w", fieldRootN, err))
@@ -328,10 +363,17 @@ func searchForTestDuplicates(tests []api.TestStepConfiguration) []error {
 	var testNames []string
 
 	for _, test := range tests {
-		if _, exist := duplicates[test.As]; exist {
-			testNames = append(testNames, test.As)
-		} else {
-			duplicates[test.As] = true
+		// jobs for additional architectures must not clash with other tests
+		names := []string{test.As}
+		for _, architecture := range test.Architectures {
+			names = append(names, api.ArchitectureTestName(test.As, architecture))
+		}
+		for _, name := range names {
+			if _, exist := duplicates[name]; exist {
+				testNames = append(testNames, name)
+			} else {
+				duplicates[name] = true
+			}
 		}
 	}
 
@@ -346,20 +388,57 @@ func validateTestConfigurationType(fieldRoot string, test api.TestStepConfigurat
 	clusterCount := 0
 	if claim := test.ClusterClaim; claim != nil {
 		clusterCount++
-		if claim.Version == "" {
+		if claim.Pool != "" {
+			if errs := validation.IsDNS1123Subdomain(claim.Pool); len(errs) != 0 {
+				validationErrors = append(validationErrors, fmt.Errorf("%s.cluster_claim.pool is not a valid name: %s", fieldRoot, strings.Join(errs, ", ")))
+			}
+		} else if claim.Version == "" {
 			validationErrors = append(validationErrors, fmt.Errorf("%s.cluster_claim.version cannot be empty when cluster_claim is not nil", fieldRoot))
 		}
-		if claim.Cloud == "" {
+		if claim.Cloud == "" && claim.Pool == "" {
 			validationErrors = append(validationErrors, fmt.Errorf("%s.cluster_claim.cloud cannot be empty when cluster_claim is not nil", fieldRoot))
 		}
-		if claim.Owner == "" {
+		if claim.Owner == "" && claim.Pool == "" {
 			validationErrors = append(validationErrors, fmt.Errorf("%s.cluster_claim.owner cannot be empty when cluster_claim is not nil", fieldRoot))
 		}
+		for key, value := range claim.Labels {
+			if errs := validation.IsQualifiedName(key); len(errs) != 0 {
+				validationErrors = append(validationErrors, fmt.Errorf("%s.cluster_claim.labels: %q is not a valid label key: %s", fieldRoot, key, strings.Join(errs, ", ")))
+			}
+			if errs := validation.IsValidLabelValue(value); len(errs) != 0 {
+				validationErrors = append(validationErrors, fmt.Errorf("%s.cluster_claim.labels.%s: %q is not a valid label value: %s", fieldRoot, key, value, strings.Join(errs, ", ")))
+			}
+		}
+		if claim.Lifetime != nil && (claim.Lifetime.Duration <= 0 || claim.Lifetime.Duration > api.MaxClusterClaimLifetime) {
+			validationErrors = append(validationErrors, fmt.Errorf("%s.cluster_claim.lifetime must be positive and at most %s", fieldRoot, api.MaxClusterClaimLifetime))
+		}
+	}
+	if ttl := test.NamespaceTTL; ttl != nil {
+		for _, field := range []struct {
+			name     string
+			duration *prowv1.Duration
+		}{
+			{name: "hard", duration: ttl.Hard},
+			{name: "idle", duration: ttl.Idle},
+			{name: "keep_on_failure", duration: ttl.KeepOnFailure},
+		} {
+			if field.duration != nil && (field.duration.Duration <= 0 || field.duration.Duration > api.MaxNamespaceTTL) {
+				validationErrors = append(validationErrors, fmt.Errorf("%s.namespace_ttl.%s must be positive and at most %s", fieldRoot, field.name, api.MaxNamespaceTTL))
+			}
+		}
 	}
 	typeCount := 0
 	if cluster := test.Cluster; cluster != "" && !api.ValidClusterNames.Has(string(cluster)) {
 		validationErrors = append(validationErrors, fmt.Errorf("%s.cluster is not a vailid cluster: %s", fieldRoot, string(cluster)))
 	}
+	if len(test.Leases) != 0 {
+		if test.ContainerTestConfiguration == nil {
+			validationErrors = append(validationErrors, fmt.Errorf("%s.leases: only container tests can acquire leases this way, multi-stage tests configure them in 'steps'", fieldRoot))
+		} else {
+			context := newContext(fieldRoot, nil, releases)
+			validationErrors = append(validationErrors, validateLeases(context.forField(".leases"), test.Leases)...)
+		}
+	}
 	if testConfig := test.ContainerTestConfiguration; testConfig != nil {
 		typeCount++
 		if testConfig.MemoryBackedVolume != nil {
@@ -424,6 +503,8 @@ func validateTestConfigurationType(fieldRoot string, test api.TestStepConfigurat
 		validationErrors = append(validationErrors, validateTestSteps(context.forField(".pre"), testStagePre, testConfig.Pre)...)
 		validationErrors = append(validationErrors, validateTestSteps(context.forField(".test"), testStageTest, testConfig.Test)...)
 		validationErrors = append(validationErrors, validateTestSteps(context.forField(".post"), testStagePost, testConfig.Post)...)
+		validationErrors = append(validationErrors, validateFallbackClusterProfiles(fieldRoot, testConfig.ClusterProfile, testConfig.FallbackClusterProfiles)...)
+		validationErrors = append(validationErrors, validateGather(fieldRoot+".gather", testConfig.Gather)...)
 	}
 	if testConfig := test.MultiStageTestConfigurationLiteral; testConfig != nil {
 		typeCount++
@@ -432,6 +513,7 @@ func validateTestConfigurationType(fieldRoot string, test api.TestStepConfigurat
 			clusterCount++
 			validationErrors = append(validationErrors, validateClusterProfile(fieldRoot, testConfig.ClusterProfile)...)
 		}
+		validationErrors = append(validationErrors, validateFallbackClusterProfiles(fieldRoot, testConfig.ClusterProfile, testConfig.FallbackClusterProfiles)...)
 		validationErrors = append(validationErrors, validateLeases(context.forField(".leases"), testConfig.Leases)...)
 		for i, s := range testConfig.Pre {
 			validationErrors = append(validationErrors, validateLiteralTestStep(context.forField(fmt.Sprintf(".pre[%d]", i)), testStagePre, s)...)
@@ -442,6 +524,14 @@ func validateTestConfigurationType(fieldRoot string, test api.TestStepConfigurat
 		for i, s := range testConfig.Post {
 			validationErrors = append(validationErrors, validateLiteralTestStep(context.forField(fmt.Sprintf(".post[%d]", i)), testStagePost, s)...)
 		}
+		validationErrors = append(validationErrors, validateGather(fieldRoot+".gather", testConfig.Gather)...)
+		if testConfig.Gather != nil {
+			for _, s := range append(testConfig.Pre, append(testConfig.Test, testConfig.Post...)...) {
+				if s.As == api.GatherStepName {
+					validationErrors = append(validationErrors, fmt.Errorf("%s: step name %q is reserved for the gather step", fieldRoot, api.GatherStepName))
+				}
+			}
+		}
 	}
 	if typeCount == 0 {
 		validationErrors = append(validationErrors, fmt.Errorf("%s has no type, you may want to specify 'container' for a container based test", fieldRoot))
@@ -459,6 +549,46 @@ func validateTestConfigurationType(fieldRoot string, test api.TestStepConfigurat
 	return validationErrors
 }
 
+// validateFallbackClusterProfiles ensures that every profile a test can fall
+// back to is acquired through a lease of its own, as the capacity of the lease
+// types is what the profile is chosen by.
+func validateFallbackClusterProfiles(fieldRoot string, profile api.ClusterProfile, fallbacks []api.ClusterProfile) (ret []error) {
+	if len(fallbacks) == 0 {
+		return nil
+	}
+	if profile == "" {
+		return []error{fmt.Errorf("%s: 'fallback_cluster_profiles' requires 'cluster_profile'", fieldRoot)}
+	}
+	seen := map[string]api.ClusterProfile{profile.LeaseType(): profile}
+	for i, fallback := range fallbacks {
+		fieldRootI := fmt.Sprintf("%s.fallback_cluster_profiles[%d]", fieldRoot, i)
+		if errs := validateClusterProfile(fieldRootI, fallback); errs != nil {
+			ret = append(ret, errs...)
+			continue
+		}
+		leaseType := fallback.LeaseType()
+		if other, ok := seen[leaseType]; ok {
+			ret = append(ret, fmt.Errorf("%s: cluster profile %q uses the same lease type %q as %q", fieldRootI, fallback, leaseType, other))
+		} else {
+			seen[leaseType] = fallback
+		}
+	}
+	return ret
+}
+
+func validateGather(fieldRoot string, gather *api.GatherConfiguration) (ret []error) {
+	if gather == nil {
+		return nil
+	}
+	if gather.Timeout != nil && gather.Timeout.Duration <= 0 {
+		ret = append(ret, fmt.Errorf("%s.timeout must be positive", fieldRoot))
+	}
+	if gather.Resources != nil {
+		ret = append(ret, validateResourceRequirements(fieldRoot+".resources", *gather.Resources)...)
+	}
+	return ret
+}
+
 func validateTestSteps(context context, stage testStage, steps []api.TestStep) (ret []error) {
 	for i, s := range steps {
 		contextI := context.forField(fmt.Sprintf("[%d]", i))
@@ -527,24 +657,7 @@ func validateLiteralTestStep(context context, stage testStage, step api.LiteralT
 			ret = append(ret, fmt.Errorf("%s.from_image: `tag` is required", context.fieldRoot))
 		}
 	} else {
-		imageParts := strings.Split(step.From, ":")
-		if len(imageParts) > 2 {
-			ret = append(ret, fmt.Errorf("%s.from: '%s' is not a valid imagestream reference", context.fieldRoot, step.From))
-		}
-		for i, obj := range imageParts {
-			if len(validation.IsDNS1123Subdomain(obj)) != 0 {
-				ret = append(ret, fmt.Errorf("%s.from: '%s' is not a valid Kubernetes object name", context.fieldRoot, obj))
-			} else if i == 0 && len(imageParts) == 2 {
-				switch obj {
-				case api.PipelineImageStream, api.ReleaseStreamFor(api.LatestReleaseName), api.ReleaseStreamFor(api.InitialReleaseName), api.ReleaseImageStream:
-				default:
-					releaseName := api.ReleaseNameFrom(obj)
-					if !context.releases.Has(releaseName) {
-						ret = append(ret, fmt.Errorf("%s.from: unknown imagestream '%s'", context.fieldRoot, imageParts[0]))
-					}
-				}
-			}
-		}
+		ret = append(ret, validateFrom(context, context.fieldRoot+".from", step.From)...)
 	}
 	if len(step.Commands) == 0 {
 		ret = append(ret, fmt.Errorf("%s: `commands` is required", context.fieldRoot))
@@ -558,6 +671,12 @@ func validateLiteralTestStep(context context, stage testStage, step api.LiteralT
 
 	ret = append(ret, validateResourceRequirements(context.fieldRoot+".resources", step.Resources)...)
 	ret = append(ret, validateCredentials(context.fieldRoot, step.Credentials)...)
+	ret = append(ret, validateInitContainers(context, step.InitContainers)...)
+	for i, param := range step.Environment {
+		if err := param.Validate(); err != nil {
+			ret = append(ret, fmt.Errorf("%s.env[%d]: %v", context.fieldRoot, i, err))
+		}
+	}
 	if context.env != nil {
 		if err := validateParameters(&context, step.Environment); err != nil {
 			ret = append(ret, err)
@@ -565,6 +684,19 @@ func validateLiteralTestStep(context context, stage testStage, step api.LiteralT
 	}
 	ret = append(ret, validateDependencies(context.fieldRoot, step.Dependencies)...)
 	ret = append(ret, validateLeases(context.forField(".leases"), step.Leases)...)
+	for i, architecture := range step.Architectures {
+		if err := ValidateArchitecture(fmt.Sprintf("%s.architectures[%d]", context.fieldRoot, i), architecture); err != nil {
+			ret = append(ret, err)
+		}
+	}
+	switch step.RunIf {
+	case "", api.StepRunIfSuccess, api.StepRunIfFailure, api.StepRunIfAlways:
+	default:
+		ret = append(ret, fmt.Errorf("%s.run_if: must be one of %s, %s or %s", context.fieldRoot, api.StepRunIfSuccess, api.StepRunIfFailure, api.StepRunIfAlways))
+	}
+	if step.Retries != nil && (*step.Retries < 0 || *step.Retries > api.MaxStepRetries) {
+		ret = append(ret, fmt.Errorf("%s.retries: must be between 0 and %d", context.fieldRoot, api.MaxStepRetries))
+	}
 	switch stage {
 	case testStagePre, testStageTest:
 		if step.OptionalOnSuccess != nil {
@@ -584,8 +716,57 @@ func validateCommands(test api.LiteralTestStep) []error {
 	return validationErrors
 }
 
+// validateFrom validates an image of a step, which references an imagestream tag in the test namespace
+func validateFrom(context context, fieldRoot, from string) (ret []error) {
+	imageParts := strings.Split(from, ":")
+	if len(imageParts) > 2 {
+		ret = append(ret, fmt.Errorf("%s: '%s' is not a valid imagestream reference", fieldRoot, from))
+	}
+	for i, obj := range imageParts {
+		if len(validation.IsDNS1123Subdomain(obj)) != 0 {
+			ret = append(ret, fmt.Errorf("%s: '%s' is not a valid Kubernetes object name", fieldRoot, obj))
+		} else if i == 0 && len(imageParts) == 2 {
+			switch obj {
+			case api.PipelineImageStream, api.ReleaseStreamFor(api.LatestReleaseName), api.ReleaseStreamFor(api.InitialReleaseName), api.ReleaseImageStream:
+			default:
+				releaseName := api.ReleaseNameFrom(obj)
+				if !context.releases.Has(releaseName) {
+					ret = append(ret, fmt.Errorf("%s: unknown imagestream '%s'", fieldRoot, imageParts[0]))
+				}
+			}
+		}
+	}
+	return ret
+}
+
+func validateInitContainers(context context, initContainers []api.StepInitContainer) (ret []error) {
+	seen := sets.NewString()
+	for i, initContainer := range initContainers {
+		fieldRoot := fmt.Sprintf("%s.init_containers[%d]", context.fieldRoot, i)
+		if initContainer.As == "" {
+			ret = append(ret, fmt.Errorf("%s: `as` is required", fieldRoot))
+		} else if errs := validation.IsDNS1123Label("init-" + initContainer.As); len(errs) > 0 {
+			ret = append(ret, fmt.Errorf("%s.as: '%s' is not a valid container name: %s", fieldRoot, initContainer.As, strings.Join(errs, ", ")))
+		} else if seen.Has(initContainer.As) {
+			ret = append(ret, fmt.Errorf("%s: duplicated name %q", fieldRoot, initContainer.As))
+		} else {
+			seen.Insert(initContainer.As)
+		}
+		if initContainer.From == "" {
+			ret = append(ret, fmt.Errorf("%s: `from` is required", fieldRoot))
+		} else {
+			ret = append(ret, validateFrom(context, fieldRoot+".from", initContainer.From)...)
+		}
+		if initContainer.Commands == "" {
+			ret = append(ret, fmt.Errorf("%s: `commands` is required", fieldRoot))
+		}
+	}
+	return ret
+}
+
 func validateCredentials(fieldRoot string, credentials []api.CredentialReference) []error {
 	var errs []error
+	env := sets.NewString()
 	for i, credential := range credentials {
 		if credential.Name == "" {
 			errs = append(errs, fmt.Errorf("%s.credentials[%d].name cannot be empty", fieldRoot, i))
@@ -593,13 +774,36 @@ func validateCredentials(fieldRoot string, credentials []api.CredentialReference
 		if credential.Namespace == "" {
 			errs = append(errs, fmt.Errorf("%s.credentials[%d].namespace cannot be empty", fieldRoot, i))
 		}
+		for j, credentialEnv := range credential.Env {
+			if credentialEnv.Name == "" {
+				errs = append(errs, fmt.Errorf("%s.credentials[%d].env[%d].name cannot be empty", fieldRoot, i, j))
+			} else if nameErrs := validation.IsEnvVarName(credentialEnv.Name); len(nameErrs) > 0 {
+				errs = append(errs, fmt.Errorf("%s.credentials[%d].env[%d].name is not a valid environment variable name: %s", fieldRoot, i, j, strings.Join(nameErrs, ", ")))
+			} else if reservedCredentialEnv.Has(credentialEnv.Name) {
+				errs = append(errs, fmt.Errorf("%s.credentials[%d].env[%d].name targets %s, which is set by ci-operator", fieldRoot, i, j, credentialEnv.Name))
+			} else if env.Has(credentialEnv.Name) {
+				errs = append(errs, fmt.Errorf("%s.credentials[%d].env[%d].name targets an environment variable that is already set by another credential", fieldRoot, i, j))
+			} else {
+				env.Insert(credentialEnv.Name)
+			}
+			if credentialEnv.Key == "" {
+				errs = append(errs, fmt.Errorf("%s.credentials[%d].env[%d].key cannot be empty", fieldRoot, i, j))
+			}
+		}
 		if credential.MountPath == "" {
-			errs = append(errs, fmt.Errorf("%s.credentials[%d].mountPath cannot be empty", fieldRoot, i))
+			// Credentials that are only exposed as environment variables are not mounted
+			if len(credential.Env) == 0 {
+				errs = append(errs, fmt.Errorf("%s.credentials[%d].mountPath cannot be empty", fieldRoot, i))
+			}
+			continue
 		} else if !filepath.IsAbs(credential.MountPath) {
 			errs = append(errs, fmt.Errorf("%s.credentials[%d].mountPath is not absolute: %s", fieldRoot, i, credential.MountPath))
 		}
 		for j, other := range credentials[i+1:] {
 			index := i + j + 1
+			if other.MountPath == "" {
+				continue
+			}
 			if credential.MountPath == other.MountPath {
 				errs = append(errs, fmt.Errorf("%s.credentials[%d] and credentials[%d] mount to the same location (%s)", fieldRoot, i, index, credential.MountPath))
 				continue
@@ -653,6 +857,8 @@ func validateDependencies(fieldRoot string, dependencies []api.StepDependency) [
 			errs = append(errs, fmt.Errorf("%s.dependencies[%d].name must be set", fieldRoot, i))
 		} else if numColons := strings.Count(dependency.Name, ":"); !(numColons == 0 || numColons == 1) {
 			errs = append(errs, fmt.Errorf("%s.dependencies[%d].name must take the `tag` or `stream:tag` form, not %q", fieldRoot, i, dependency.Name))
+		} else if dependency.Release != "" && numColons != 0 {
+			errs = append(errs, fmt.Errorf("%s.dependencies[%d].name must be a tag when a release is set, not %q", fieldRoot, i, dependency.Name))
 		}
 		if dependency.Env == "" {
 			errs = append(errs, fmt.Errorf("%s.dependencies[%d].env must be set", fieldRoot, i))
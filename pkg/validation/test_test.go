@@ -497,6 +497,72 @@ func TestValidateTests(t *testing.T) {
 			},
 			expectedValid: false,
 		},
+		{
+			id: "test on additional architectures",
+			tests: []api.TestStepConfiguration{
+				{
+					As:                         "unit",
+					Commands:                   "commands",
+					ContainerTestConfiguration: &api.ContainerTestConfiguration{From: "ignored"},
+					Architectures:              []api.ReleaseArchitecture{api.ReleaseArchitectureARM64},
+				},
+			},
+			expectedValid: true,
+		},
+		{
+			id: "test on unknown architecture",
+			tests: []api.TestStepConfiguration{
+				{
+					As:                         "unit",
+					Commands:                   "commands",
+					ContainerTestConfiguration: &api.ContainerTestConfiguration{From: "ignored"},
+					Architectures:              []api.ReleaseArchitecture{"mips"},
+				},
+			},
+			expectedValid: false,
+		},
+		{
+			id: "test on duplicate architectures",
+			tests: []api.TestStepConfiguration{
+				{
+					As:                         "unit",
+					Commands:                   "commands",
+					ContainerTestConfiguration: &api.ContainerTestConfiguration{From: "ignored"},
+					Architectures:              []api.ReleaseArchitecture{api.ReleaseArchitectureARM64, api.ReleaseArchitectureARM64},
+				},
+			},
+			expectedValid: false,
+		},
+		{
+			id: "postsubmit on additional architectures",
+			tests: []api.TestStepConfiguration{
+				{
+					As:                         "unit",
+					Commands:                   "commands",
+					ContainerTestConfiguration: &api.ContainerTestConfiguration{From: "ignored"},
+					Architectures:              []api.ReleaseArchitecture{api.ReleaseArchitectureARM64},
+					Postsubmit:                 true,
+				},
+			},
+			expectedValid: false,
+		},
+		{
+			id: "test on additional architecture clashes with another test",
+			tests: []api.TestStepConfiguration{
+				{
+					As:                         "unit",
+					Commands:                   "commands",
+					ContainerTestConfiguration: &api.ContainerTestConfiguration{From: "ignored"},
+					Architectures:              []api.ReleaseArchitecture{api.ReleaseArchitectureARM64},
+				},
+				{
+					As:                         "unit-arm64",
+					Commands:                   "commands",
+					ContainerTestConfiguration: &api.ContainerTestConfiguration{From: "ignored"},
+				},
+			},
+			expectedValid: false,
+		},
 	} {
 		t.Run(tc.id, func(t *testing.T) {
 			if errs := validateTestStepConfiguration("tests", tc.tests, tc.release, tc.releases, tc.resolved); len(errs) > 0 && tc.expectedValid {
@@ -517,6 +583,7 @@ func TestValidateTestSteps(t *testing.T) {
 	myReference := "my-reference"
 	asReference := "as"
 	yes := true
+	maxRetries, tooManyRetries := api.MaxStepRetries, api.MaxStepRetries+1
 	defaultDuration := &prowv1.Duration{Duration: 1 * time.Minute}
 	for _, tc := range []struct {
 		name     string
@@ -738,6 +805,29 @@ func TestValidateTestSteps(t *testing.T) {
 			errors.New("'test[0].resources.limits' specifies an invalid key piña_colada"),
 			errors.New("test[0].resources.requests.cpu: invalid quantity: quantities must match the regular expression '^([+-]?[0-9.]+)([eEinumkKMGTP]*[-+]?[0-9]*)$'"),
 		},
+	}, {
+		name: "valid architectures",
+		steps: []api.TestStep{{
+			LiteralTestStep: &api.LiteralTestStep{
+				As:            "as",
+				From:          "from",
+				Commands:      "commands",
+				Resources:     resources,
+				Architectures: []api.ReleaseArchitecture{api.ReleaseArchitectureAMD64, api.ReleaseArchitectureS390x},
+			},
+		}},
+	}, {
+		name: "invalid architecture",
+		steps: []api.TestStep{{
+			LiteralTestStep: &api.LiteralTestStep{
+				As:            "as",
+				From:          "from",
+				Commands:      "commands",
+				Resources:     resources,
+				Architectures: []api.ReleaseArchitecture{"arm"},
+			},
+		}},
+		errs: []error{errors.New("test[0].architectures[0]: must be one of amd64, arm64, ppc64le, s390x")},
 	}, {
 		name: "Reference and TestStep set",
 		steps: []api.TestStep{{
@@ -779,6 +869,33 @@ func TestValidateTestSteps(t *testing.T) {
 		errs: []error{
 			errors.New("test[0]: `optional_on_success` is only allowed for Post steps"),
 		},
+	}, {
+		name: "step with run condition and retries",
+		steps: []api.TestStep{{
+			LiteralTestStep: &api.LiteralTestStep{
+				As:        "as",
+				From:      "from",
+				Commands:  "commands",
+				Resources: resources,
+				RunIf:     api.StepRunIfFailure,
+				Retries:   &maxRetries,
+				Optional:  &yes},
+		}},
+	}, {
+		name: "step with invalid run condition and too many retries",
+		steps: []api.TestStep{{
+			LiteralTestStep: &api.LiteralTestStep{
+				As:        "as",
+				From:      "from",
+				Commands:  "commands",
+				Resources: resources,
+				RunIf:     "sometimes",
+				Retries:   &tooManyRetries},
+		}},
+		errs: []error{
+			errors.New("test[0].run_if: must be one of success, failure or always"),
+			errors.New("test[0].retries: must be between 0 and 5"),
+		},
 	}, {
 		name: "Multiple errors",
 		steps: []api.TestStep{{
@@ -855,6 +972,44 @@ func TestValidateTestSteps(t *testing.T) {
 		errs: []error{
 			errors.New("test best-effort contains best_effort without timeout"),
 		},
+	}, {
+		name: "valid init containers",
+		steps: []api.TestStep{{
+			LiteralTestStep: &api.LiteralTestStep{
+				As:        "as",
+				From:      "from",
+				Commands:  "commands",
+				Resources: resources,
+				InitContainers: []api.StepInitContainer{
+					{As: "data", From: "pipeline:src", Commands: "cp -r data ${INIT_DIR}"},
+					{As: "more-data", From: "tools", Commands: "cp -r data ${INIT_DIR}"},
+				},
+			},
+		}},
+	}, {
+		name: "invalid init containers",
+		steps: []api.TestStep{{
+			LiteralTestStep: &api.LiteralTestStep{
+				As:        "as",
+				From:      "from",
+				Commands:  "commands",
+				Resources: resources,
+				InitContainers: []api.StepInitContainer{
+					{From: "tools", Commands: "commands"},
+					{As: "data", Commands: "commands"},
+					{As: "data", From: "tools", Commands: "commands"},
+					{As: "Data", From: "unknown:tools"},
+				},
+			},
+		}},
+		errs: []error{
+			errors.New("test[0].init_containers[0]: `as` is required"),
+			errors.New("test[0].init_containers[1]: `from` is required"),
+			errors.New(`test[0].init_containers[2]: duplicated name "data"`),
+			errors.New("test[0].init_containers[3].as: 'Data' is not a valid container name: a lowercase RFC 1123 label must consist of lower case alphanumeric characters or '-', and must start and end with an alphanumeric character (e.g. 'my-name',  or '123-abc', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?')"),
+			errors.New("test[0].init_containers[3].from: unknown imagestream 'unknown'"),
+			errors.New("test[0].init_containers[3]: `commands` is required"),
+		},
 	}} {
 		t.Run(tc.name, func(t *testing.T) {
 			context := newContext("test", nil, tc.releases)
@@ -911,6 +1066,7 @@ func TestValidatePostSteps(t *testing.T) {
 
 func TestValidateParameters(t *testing.T) {
 	defaultStr := "default"
+	count := "3"
 	for _, tc := range []struct {
 		name     string
 		params   []api.StepParameter
@@ -931,6 +1087,30 @@ func TestValidateParameters(t *testing.T) {
 		params: []api.StepParameter{{Name: "TEST0"}, {Name: "TEST1"}},
 		env:    api.TestEnvironment{"TEST0": "test0"},
 		err:    []error{errors.New("test: unresolved parameter(s): [TEST1]")},
+	}, {
+		name: "typed parameters with valid defaults",
+		params: []api.StepParameter{
+			{Name: "COUNT", Type: api.ParameterTypeInteger, Default: &count},
+			{Name: "MODE", Type: api.ParameterTypeEnum, Values: []string{"default", "other"}, Default: &defaultStr},
+		},
+	}, {
+		name:   "unknown type",
+		params: []api.StepParameter{{Name: "TEST", Type: "float", Default: &defaultStr}},
+		err:    []error{errors.New(`test.env[0]: parameter TEST: invalid type "float", must be one of string, integer, boolean or enum`)},
+	}, {
+		name: "values are only allowed for enums",
+		params: []api.StepParameter{
+			{Name: "ENUM", Type: api.ParameterTypeEnum, Default: &defaultStr},
+			{Name: "STRING", Values: []string{"default"}, Default: &defaultStr},
+		},
+		err: []error{
+			errors.New("test.env[0]: parameter ENUM: `values` must be set for parameters of type enum"),
+			errors.New("test.env[1]: parameter STRING: `values` can only be set for parameters of type enum"),
+		},
+	}, {
+		name:   "default does not match the type",
+		params: []api.StepParameter{{Name: "ENABLED", Type: api.ParameterTypeBoolean, Default: &defaultStr}},
+		err:    []error{errors.New(`test.env[0]: parameter ENABLED: "default" is not a boolean`)},
 	}} {
 		t.Run(tc.name, func(t *testing.T) {
 			err := validateLiteralTestStep(newContext("test", tc.env, tc.releases), testStageTest, api.LiteralTestStep{
@@ -1032,6 +1212,46 @@ func TestValidateCredentials(t *testing.T) {
 				{Namespace: "ns", Name: "name", MountPath: "/foo"},
 			},
 		},
+		{
+			name: "cred exposed as env without mount path means no error",
+			input: []api.CredentialReference{
+				{Namespace: "ns", Name: "name", Env: []api.CredentialEnv{{Name: "TOKEN", Key: "token"}}},
+				{Namespace: "ns", Name: "other", Env: []api.CredentialEnv{{Name: "PASSWORD", Key: "password"}}},
+				{Namespace: "ns", Name: "name", MountPath: "/foo"},
+			},
+		},
+		{
+			name: "invalid cred env means error",
+			input: []api.CredentialReference{
+				{Namespace: "ns", Name: "name", Env: []api.CredentialEnv{{Key: "token"}, {Name: "1TOKEN", Key: "token"}, {Name: "TOKEN"}}},
+			},
+			output: []error{
+				errors.New("root.credentials[0].env[0].name cannot be empty"),
+				errors.New("root.credentials[0].env[1].name is not a valid environment variable name: a valid environment variable name must consist of alphabetic characters, digits, '_', '-', or '.', and must not start with a digit (e.g. 'my.env-name',  or 'MY_ENV.NAME',  or 'MyEnvName1', regex used for validation is '[-._a-zA-Z][-._a-zA-Z0-9]*')"),
+				errors.New("root.credentials[0].env[2].key cannot be empty"),
+			},
+		},
+		{
+			name: "duped cred env name means error",
+			input: []api.CredentialReference{
+				{Namespace: "ns", Name: "name", Env: []api.CredentialEnv{{Name: "TOKEN", Key: "token"}}},
+				{Namespace: "ns", Name: "other", Env: []api.CredentialEnv{{Name: "TOKEN", Key: "token"}}},
+			},
+			output: []error{
+				errors.New("root.credentials[1].env[0].name targets an environment variable that is already set by another credential"),
+			},
+		},
+		{
+			name: "cred env name reserved by ci-operator means error",
+			input: []api.CredentialReference{
+				{Namespace: "ns", Name: "name", Env: []api.CredentialEnv{{Name: "TOKEN", Key: "token"}, {Name: "KUBECONFIG", Key: "kubeconfig"}}},
+				{Namespace: "ns", Name: "other", Env: []api.CredentialEnv{{Name: "SHARED_DIR", Key: "dir"}}},
+			},
+			output: []error{
+				errors.New("root.credentials[0].env[1].name targets KUBECONFIG, which is set by ci-operator"),
+				errors.New("root.credentials[1].env[0].name targets SHARED_DIR, which is set by ci-operator"),
+			},
+		},
 	}
 	for _, testCase := range testCases {
 		t.Run(testCase.name, func(t *testing.T) {
@@ -1059,6 +1279,7 @@ func TestValidateDependencies(t *testing.T) {
 			input: []api.StepDependency{
 				{Name: "src", Env: "SOURCE"},
 				{Name: "stable:installer", Env: "INSTALLER"},
+				{Name: "installer", Release: "initial", Env: "INITIAL_INSTALLER"},
 			},
 		},
 		{
@@ -1068,12 +1289,14 @@ func TestValidateDependencies(t *testing.T) {
 				{Name: "src", Env: "SOURCE"},
 				{Name: "src", Env: "SOURCE"},
 				{Name: "src:lol:oops", Env: "WHOA"},
+				{Name: "stable:installer", Release: "initial", Env: "INSTALLER"},
 			},
 			output: []error{
 				errors.New("root.dependencies[0].name must be set"),
 				errors.New("root.dependencies[0].env must be set"),
 				errors.New("root.dependencies[2].env targets an environment variable that is already set by another dependency"),
 				errors.New("root.dependencies[3].name must take the `tag` or `stream:tag` form, not \"src:lol:oops\""),
+				errors.New("root.dependencies[4].name must be a tag when a release is set, not \"stable:installer\""),
 			},
 		},
 	}
@@ -1285,6 +1508,197 @@ func TestValidateTestConfigurationType(t *testing.T) {
 				fmt.Errorf("test.cluster_claim.cloud cannot be empty when cluster_claim is not nil"),
 				fmt.Errorf("test.cluster_claim.owner cannot be empty when cluster_claim is not nil")},
 		},
+		{
+			name: "claim from a named pool",
+			test: api.TestStepConfiguration{
+				ClusterClaim: &api.ClusterClaim{
+					Pool:     "ci-ocp-4-7-amd64-aws",
+					Labels:   map[string]string{"region": "us-east-1"},
+					Lifetime: &prowv1.Duration{Duration: 2 * time.Hour},
+					Timeout:  &prowv1.Duration{Duration: time.Hour},
+				},
+				MultiStageTestConfiguration: &api.MultiStageTestConfiguration{
+					Test: []api.TestStep{
+						{
+							LiteralTestStep: &api.LiteralTestStep{
+								As:        "e2e-aws-test",
+								Commands:  "oc get node",
+								From:      "cli",
+								Resources: api.ResourceRequirements{Requests: api.ResourceList{"cpu": "1"}},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "claim with invalid pool, labels and lifetime",
+			test: api.TestStepConfiguration{
+				ClusterClaim: &api.ClusterClaim{
+					Pool:     "Pool_Name",
+					Labels:   map[string]string{"region": "us east"},
+					Lifetime: &prowv1.Duration{Duration: -time.Hour},
+					Timeout:  &prowv1.Duration{Duration: time.Hour},
+				},
+				MultiStageTestConfiguration: &api.MultiStageTestConfiguration{
+					Test: []api.TestStep{
+						{
+							LiteralTestStep: &api.LiteralTestStep{
+								As:        "e2e-aws-test",
+								Commands:  "oc get node",
+								From:      "cli",
+								Resources: api.ResourceRequirements{Requests: api.ResourceList{"cpu": "1"}},
+							},
+						},
+					},
+				},
+			},
+			expected: []error{
+				fmt.Errorf("test.cluster_claim.pool is not a valid name: a lowercase RFC 1123 subdomain must consist of lower case alphanumeric characters, '-' or '.', and must start and end with an alphanumeric character (e.g. 'example.com', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?(\\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*')"),
+				fmt.Errorf("test.cluster_claim.labels.region: \"us east\" is not a valid label value: a valid label must be an empty string or consist of alphanumeric characters, '-', '_' or '.', and must start and end with an alphanumeric character (e.g. 'MyValue',  or 'my_value',  or '12345', regex used for validation is '(([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9])?')"),
+				fmt.Errorf("test.cluster_claim.lifetime must be positive and at most 12h0m0s"),
+			},
+		},
+		{
+			name: "claim with a lifetime over the limit",
+			test: api.TestStepConfiguration{
+				ClusterClaim: &api.ClusterClaim{
+					Pool:     "ci-ocp-4-7-amd64-aws",
+					Lifetime: &prowv1.Duration{Duration: 72 * time.Hour},
+				},
+				MultiStageTestConfiguration: &api.MultiStageTestConfiguration{
+					Test: []api.TestStep{
+						{
+							LiteralTestStep: &api.LiteralTestStep{
+								As:        "e2e-aws-test",
+								Commands:  "oc get node",
+								From:      "cli",
+								Resources: api.ResourceRequirements{Requests: api.ResourceList{"cpu": "1"}},
+							},
+						},
+					},
+				},
+			},
+			expected: []error{fmt.Errorf("test.cluster_claim.lifetime must be positive and at most 12h0m0s")},
+		},
+		{
+			name: "namespace TTL",
+			test: api.TestStepConfiguration{
+				NamespaceTTL: &api.NamespaceTTL{
+					Hard:          &prowv1.Duration{Duration: 24 * time.Hour},
+					KeepOnFailure: &prowv1.Duration{Duration: 48 * time.Hour},
+				},
+				ContainerTestConfiguration: &api.ContainerTestConfiguration{From: "src"},
+			},
+		},
+		{
+			name: "namespace TTL with durations that are not positive",
+			test: api.TestStepConfiguration{
+				NamespaceTTL: &api.NamespaceTTL{
+					Idle:          &prowv1.Duration{},
+					KeepOnFailure: &prowv1.Duration{Duration: -time.Hour},
+				},
+				ContainerTestConfiguration: &api.ContainerTestConfiguration{From: "src"},
+			},
+			expected: []error{
+				fmt.Errorf("test.namespace_ttl.idle must be positive and at most 72h0m0s"),
+				fmt.Errorf("test.namespace_ttl.keep_on_failure must be positive and at most 72h0m0s"),
+			},
+		},
+		{
+			name: "namespace TTL with durations that are too long",
+			test: api.TestStepConfiguration{
+				NamespaceTTL: &api.NamespaceTTL{
+					Hard:          &prowv1.Duration{Duration: 72 * time.Hour},
+					Idle:          &prowv1.Duration{Duration: 96 * time.Hour},
+					KeepOnFailure: &prowv1.Duration{Duration: 30 * 24 * time.Hour},
+				},
+				ContainerTestConfiguration: &api.ContainerTestConfiguration{From: "src"},
+			},
+			expected: []error{
+				fmt.Errorf("test.namespace_ttl.idle must be positive and at most 72h0m0s"),
+				fmt.Errorf("test.namespace_ttl.keep_on_failure must be positive and at most 72h0m0s"),
+			},
+		},
+		{
+			name: "gather step",
+			test: api.TestStepConfiguration{
+				MultiStageTestConfiguration: &api.MultiStageTestConfiguration{
+					Test: []api.TestStep{
+						{
+							LiteralTestStep: &api.LiteralTestStep{
+								As:        "e2e-aws-test",
+								Commands:  "oc get node",
+								From:      "cli",
+								Resources: api.ResourceRequirements{Requests: api.ResourceList{"cpu": "1"}},
+							},
+						},
+					},
+					Gather: &api.GatherConfiguration{
+						Timeout:   &prowv1.Duration{Duration: time.Hour},
+						Resources: &api.ResourceRequirements{Requests: api.ResourceList{"cpu": "1"}},
+					},
+				},
+			},
+		},
+		{
+			name: "invalid gather step",
+			test: api.TestStepConfiguration{
+				MultiStageTestConfigurationLiteral: &api.MultiStageTestConfigurationLiteral{
+					Post: []api.LiteralTestStep{
+						{
+							As:        "gather-cluster",
+							Commands:  "oc adm must-gather",
+							From:      "cli",
+							Resources: api.ResourceRequirements{Requests: api.ResourceList{"cpu": "1"}},
+						},
+					},
+					Gather: &api.GatherConfiguration{
+						Timeout:   &prowv1.Duration{},
+						Resources: &api.ResourceRequirements{},
+					},
+				},
+			},
+			expected: []error{
+				fmt.Errorf("test.gather.timeout must be positive"),
+				fmt.Errorf("'test.gather.resources' should have at least one request or limit"),
+				fmt.Errorf("test: step name \"gather-cluster\" is reserved for the gather step"),
+			},
+		},
+		{
+			name: "fallback cluster profiles",
+			test: api.TestStepConfiguration{
+				MultiStageTestConfiguration: &api.MultiStageTestConfiguration{
+					ClusterProfile:          api.ClusterProfileAWS,
+					FallbackClusterProfiles: []api.ClusterProfile{api.ClusterProfileGCP, api.ClusterProfileAzure4},
+				},
+			},
+		},
+		{
+			name: "fallback cluster profiles without a cluster profile",
+			test: api.TestStepConfiguration{
+				MultiStageTestConfiguration: &api.MultiStageTestConfiguration{
+					FallbackClusterProfiles: []api.ClusterProfile{api.ClusterProfileGCP},
+				},
+			},
+			expected: []error{
+				fmt.Errorf("test: 'fallback_cluster_profiles' requires 'cluster_profile'"),
+			},
+		},
+		{
+			name: "invalid fallback cluster profiles",
+			test: api.TestStepConfiguration{
+				MultiStageTestConfigurationLiteral: &api.MultiStageTestConfigurationLiteral{
+					ClusterProfile:          api.ClusterProfileAWS,
+					FallbackClusterProfiles: []api.ClusterProfile{"nope", api.ClusterProfileGCP, api.ClusterProfileAWSAtomic, api.ClusterProfileGCPHA},
+				},
+			},
+			expected: []error{
+				fmt.Errorf("test.fallback_cluster_profiles[0]: invalid cluster profile \"nope\""),
+				fmt.Errorf("test.fallback_cluster_profiles[2]: cluster profile \"aws-atomic\" uses the same lease type \"aws-quota-slice\" as \"aws\""),
+				fmt.Errorf("test.fallback_cluster_profiles[3]: cluster profile \"gcp-ha\" uses the same lease type \"gcp-quota-slice\" as \"gcp\""),
+			},
+		},
 		{
 			name: "valid cluster",
 			test: api.TestStepConfiguration{
@@ -1322,6 +1736,51 @@ func TestValidateTestConfigurationType(t *testing.T) {
 			},
 			expected: []error{fmt.Errorf("test.cluster is not a vailid cluster: bar")},
 		},
+		{
+			name: "container test with leases",
+			test: api.TestStepConfiguration{
+				Commands:                   "make test",
+				ContainerTestConfiguration: &api.ContainerTestConfiguration{From: "src"},
+				Leases: []api.StepLease{
+					{ResourceType: "aws-quota-slice", Env: "AWS_ACCOUNTS", Count: 2},
+					{ResourceType: "gcp-quota-slice", Env: "GCP_PROJECT", Count: 1},
+				},
+			},
+		},
+		{
+			name: "container test with invalid leases",
+			test: api.TestStepConfiguration{
+				Commands:                   "make test",
+				ContainerTestConfiguration: &api.ContainerTestConfiguration{From: "src"},
+				Leases: []api.StepLease{
+					{ResourceType: "aws-quota-slice", Env: "ACCOUNT"},
+					{Env: "ACCOUNT"},
+				},
+			},
+			expected: []error{
+				fmt.Errorf("test.leases[1]: 'resource_type' cannot be empty"),
+				fmt.Errorf("test.leases[1]: duplicate environment variable: ACCOUNT"),
+			},
+		},
+		{
+			name: "leases for a multi-stage test",
+			test: api.TestStepConfiguration{
+				Leases: []api.StepLease{{ResourceType: "aws-quota-slice", Env: "ACCOUNT"}},
+				MultiStageTestConfiguration: &api.MultiStageTestConfiguration{
+					Test: []api.TestStep{
+						{
+							LiteralTestStep: &api.LiteralTestStep{
+								As:        "e2e-aws-test",
+								Commands:  "oc get node",
+								From:      "cli",
+								Resources: api.ResourceRequirements{Requests: api.ResourceList{"cpu": "1"}},
+							},
+						},
+					},
+				},
+			},
+			expected: []error{fmt.Errorf("test.leases: only container tests can acquire leases this way, multi-stage tests configure them in 'steps'")},
+		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			actual := validateTestConfigurationType("test", tc.test, nil, nil, false)
@@ -371,7 +371,7 @@ const templateDefinitions = `
    <tr>
      <td style="font-family:monospace">{{ $dep.Env }}</td>
      <td>Dependency<sup>[<a href="https://docs.ci.openshift.org/docs/architecture/ci-operator/#referring-to-images-in-tests">?</a>]</sup></td>
-     <td>Pull specification for <span style="font-family:monospace">{{ $dep.Name }}</span> image</td>
+     <td>Pull specification for <span style="font-family:monospace">{{ $dep.Name }}</span> image{{ if $dep.Release }} from the <span style="font-family:monospace">{{ $dep.Release }}</span> release{{ end }}</td>
    </tr>
    {{ end  }}
    {{ range $idx, $env := .Environment }}
@@ -380,6 +380,11 @@ const templateDefinitions = `
      <td>Parameter<sup>[<a href="https://docs.ci.openshift.org/docs/architecture/step-registry/#parameters">?</a>]</sup></td>
      <td>
        {{ $env.Documentation }}
+       {{ if $env.Values }}
+         (one of: {{ range $i, $value := $env.Values }}{{ if $i }}, {{ end }}<span style="font-family:monospace">{{ $value }}</span>{{ end }})
+       {{ else if $env.Type }}
+         (type: <span style="font-family:monospace">{{ $env.Type }}</span>)
+       {{ end }}
        {{ if $env.Default }}
        {{ if gt (len $env.Default) 0 }}
          (default: <span style="font-family:monospace">{{ $env.Default }}</span>)
@@ -840,6 +845,15 @@ type dependencyData struct {
 	Type  string
 }
 
+// dependencyImage names the image of the dependency in the `stream:tag` form
+// if it is taken from a release
+func dependencyImage(dependency api.StepDependency) string {
+	if dependency.Release == "" {
+		return dependency.Name
+	}
+	return fmt.Sprintf("%s:%s", api.ReleaseStreamFor(dependency.Release), dependency.Name)
+}
+
 func getDependencyDataItems(worklist []api.TestStep, registryRefs registry.ReferenceByName, registryChains registry.ChainByName, overrides api.TestDependencies) map[string]dependencyVars {
 	var data map[string]dependencyVars
 	add := func(image, variable, step string) {
@@ -874,7 +888,7 @@ func getDependencyDataItems(worklist []api.TestStep, registryRefs registry.Refer
 				return
 			}
 			for _, dep := range ref.Dependencies {
-				add(dep.Name, dep.Env, ref.As)
+				add(dependencyImage(dep), dep.Env, ref.As)
 			}
 		case step.Chain != nil:
 			if !seenChains.Has(*step.Chain) {
@@ -882,7 +896,7 @@ func getDependencyDataItems(worklist []api.TestStep, registryRefs registry.Refer
 			}
 		case step.LiteralTestStep != nil:
 			for _, dep := range step.Dependencies {
-				add(dep.Name, dep.Env, step.As)
+				add(dependencyImage(dep), dep.Env, step.As)
 			}
 		}
 	}
@@ -39,9 +39,14 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"        # BuildArgs contains build arguments that will be resolved in the Dockerfile.\n" +
 	"        # See https://docs.docker.com/engine/reference/builder/#/arg for more details.\n" +
 	"        build_args:\n" +
-	"            - # Name of the build arg.\n" +
+	"            - # FromJobSpec specifies the value of the build arg is taken from the variable Prow\n" +
+	"              # exposes to the job, e.g. PULL_BASE_SHA or PULL_NUMBER. Variables that are not defined\n" +
+	"              # for the type of the job, like PULL_NUMBER for periodic jobs, resolve to an empty string.\n" +
+	"              # Cannot be set if Value or ValueFrom is set.\n" +
+	"              from_job_spec: ' '\n" +
+	"              # Name of the build arg.\n" +
 	"              name: ' '\n" +
-	"              # ValueFrom specifies the value of the build Arg is taken from a key of a secret. Cannot be set if Value is set.\n" +
+	"              # ValueFrom specifies the value of the build Arg is taken from a key of a secret. Cannot be set if Value or FromJobSpec is set.\n" +
 	"              secret_key_ref:\n" +
 	"                # Name is the key of the secret to take the value from\n" +
 	"                key: ' '\n" +
@@ -49,7 +54,7 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                name: ' '\n" +
 	"                # Namespace is the namespace of the secret\n" +
 	"                namespace: ' '\n" +
-	"              # Value of the build arg. Cannot be set if ValueFrom is set.\n" +
+	"              # Value of the build arg. Cannot be set if ValueFrom or FromJobSpec is set.\n" +
 	"              value: ' '\n" +
 	"        # ContextDir is the directory in the project\n" +
 	"        # from which this build should be run.\n" +
@@ -85,17 +90,78 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"# Go. If specified the location of the repository we are\n" +
 	"# cloning from is ignored.\n" +
 	"canonical_go_repository: \"\"\n" +
+	"# Clone configures how the source code is cloned, e.g.\n" +
+	"# to limit the history that is fetched for repositories\n" +
+	"# that take long to clone.\n" +
+	"clone:\n" +
+	"    # Filter makes the clones partial clones that omit the\n" +
+	"    # objects the filter excludes, e.g. `blob:none` fetches\n" +
+	"    # the files of the checked out commit only. Omitted\n" +
+	"    # objects are fetched when they are needed, which\n" +
+	"    # requires git in the image the source is built from.\n" +
+	"    # Supported filters are `blob:none`, `blob:limit=<n>[kmg]`\n" +
+	"    # and `tree:<depth>`.\n" +
+	"    filter: ' '\n" +
+	"# ExternalImages is a list of images and their aliases that are\n" +
+	"# imported directly from an external registry instead of from an\n" +
+	"# ImageStream on the build farm. The key will be the alias that other\n" +
+	"# steps use to refer to this image.\n" +
+	"external_images:\n" +
+	"    \"\":\n" +
+	"        # Policy determines whether the image is pinned to the\n" +
+	"        # digest in the pull spec or tracks its tag. Defaults\n" +
+	"        # to pin.\n" +
+	"        policy: ' '\n" +
+	"        # PullSpec is the pull spec of the image in the external\n" +
+	"        # registry. It must contain a digest when the image is\n" +
+	"        # pinned and a tag when it is tracked.\n" +
+	"        pull_spec: ' '\n" +
+	"        # Refresh is how long a tracked image that was imported\n" +
+	"        # into the namespace of a job is reused by later jobs\n" +
+	"        # running in the same namespace before it is imported\n" +
+	"        # again. By default, every job imports it again.\n" +
+	"        refresh: 0s\n" +
+	"# ImageScans describes scans of built images for vulnerabilities,\n" +
+	"# licenses or other issues.\n" +
+	"image_scans:\n" +
+	"    - # As is the name of the scan.\n" +
+	"      as: ' '\n" +
+	"      # Commands are the shell commands that scan the image.\n" +
+	"      commands: ' '\n" +
+	"      # Images are the images in the pipeline to scan.\n" +
+	"      images:\n" +
+	"        - \"\"\n" +
+	"      # Scanner is the pull spec of the image that provides the scanner,\n" +
+	"      # for instance clair or grype.\n" +
+	"      scanner: ' '\n" +
 	"# Images describes the images that are built\n" +
 	"# baseImage the project as part of the release\n" +
 	"# process. The name of each image is its \"to\" value\n" +
 	"# and can be used to build only a specific image.\n" +
 	"images:\n" +
-	"    - # BuildArgs contains build arguments that will be resolved in the Dockerfile.\n" +
+	"    - # AdditionalNames are further names the image is published and promoted\n" +
+	"      # as, next to `to`. The image is only built once.\n" +
+	"      additional_names:\n" +
+	"        - \"\"\n" +
+	"      # Architectures are the architectures the image is built for. One\n" +
+	"      # build is run for every architecture and the results are assembled\n" +
+	"      # into a manifest list that is tagged as `to`. Resources for the build\n" +
+	"      # of a single architecture can be overridden under the `to-architecture`\n" +
+	"      # key, e.g. `src-arm64`. When unset, a single image is built for the\n" +
+	"      # architecture of the build cluster.\n" +
+	"      architectures:\n" +
+	"        - \"\"\n" +
+	"      # BuildArgs contains build arguments that will be resolved in the Dockerfile.\n" +
 	"      # See https://docs.docker.com/engine/reference/builder/#/arg for more details.\n" +
 	"      build_args:\n" +
-	"        - # Name of the build arg.\n" +
+	"        - # FromJobSpec specifies the value of the build arg is taken from the variable Prow\n" +
+	"          # exposes to the job, e.g. PULL_BASE_SHA or PULL_NUMBER. Variables that are not defined\n" +
+	"          # for the type of the job, like PULL_NUMBER for periodic jobs, resolve to an empty string.\n" +
+	"          # Cannot be set if Value or ValueFrom is set.\n" +
+	"          from_job_spec: ' '\n" +
+	"          # Name of the build arg.\n" +
 	"          name: ' '\n" +
-	"          # ValueFrom specifies the value of the build Arg is taken from a key of a secret. Cannot be set if Value is set.\n" +
+	"          # ValueFrom specifies the value of the build Arg is taken from a key of a secret. Cannot be set if Value or FromJobSpec is set.\n" +
 	"          secret_key_ref:\n" +
 	"            # Name is the key of the secret to take the value from\n" +
 	"            key: ' '\n" +
@@ -103,8 +169,15 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"            name: ' '\n" +
 	"            # Namespace is the namespace of the secret\n" +
 	"            namespace: ' '\n" +
-	"          # Value of the build arg. Cannot be set if ValueFrom is set.\n" +
+	"          # Value of the build arg. Cannot be set if ValueFrom or FromJobSpec is set.\n" +
 	"          value: ' '\n" +
+	"      # BuildBackend selects how the image is built. Images are built as\n" +
+	"      # OpenShift builds unless `buildah-pod` is set, in which case buildah\n" +
+	"      # runs unprivileged in a pod in the test namespace, with the pipeline\n" +
+	"      # images mounted into the build context. Use it on clusters where the\n" +
+	"      # build controller is unavailable or too slow. It cannot be used with\n" +
+	"      # architectures or requires_entitlement.\n" +
+	"      build_backend: ' '\n" +
 	"      # ContextDir is the directory in the project\n" +
 	"      # from which this build should be run.\n" +
 	"      context_dir: ' '\n" +
@@ -115,6 +188,9 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"      # project to run relative to the context_dir.\n" +
 	"      dockerfile_path: ' '\n" +
 	"      from: ' '\n" +
+	"      # GracePeriod is how long the step may take to clean up after it was\n" +
+	"      # aborted before ci-operator stops waiting for it. Defaults to one minute.\n" +
+	"      grace_period: 0s\n" +
 	"      # Inputs is a map of tag reference name to image input changes\n" +
 	"      # that will populate the build context for the Dockerfile or\n" +
 	"      # alter the input image for a multi-stage build.\n" +
@@ -135,6 +211,8 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                  destination_dir: ' '\n" +
 	"                  # SourcePath is a file or directory in the source image to copy from.\n" +
 	"                  source_path: ' '\n" +
+	"      # Timeout is how long the step may run before it is aborted.\n" +
+	"      timeout: 0s\n" +
 	"      to: ' '\n" +
 	"# Operator describes the operator bundle(s) that is built by the project\n" +
 	"operator:\n" +
@@ -142,12 +220,25 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"    bundles:\n" +
 	"        - # As defines the name for this bundle. If not set, a name will be automatically generated for the bundle.\n" +
 	"          as: ' '\n" +
-	"          # BaseIndex defines what index image to use as a base when adding the bundle to an index\n" +
+	"          # BaseIndex defines what index image to use as a base when adding the bundle to an index.\n" +
+	"          # This can either be the name of an image in the pipeline or the pull spec of a published\n" +
+	"          # index image, like registry.redhat.io/redhat/redhat-operator-index:v4.8\n" +
 	"          base_index: ' '\n" +
 	"          # ContextDir defines the source directory to build the bundle from relative to the repository root\n" +
 	"          context_dir: ' '\n" +
 	"          # DockerfilePath defines where the dockerfile for build the bundle exists relative to the contextdir\n" +
 	"          dockerfile_path: ' '\n" +
+	"          # GracePeriod is how long the step may take to clean up after it was\n" +
+	"          # aborted before ci-operator stops waiting for it. Defaults to one minute.\n" +
+	"          grace_period: 0s\n" +
+	"          # IndexFormat defines how the content of the index for this bundle is stored.\n" +
+	"          # Can be: sqlite (default) or file-based-catalog\n" +
+	"          index_format: ' '\n" +
+	"          # OPMImage is the pull spec of the image that provides the opm binary used to\n" +
+	"          # generate the index for this bundle. Defaults to quay.io/operator-framework/upstream-opm-builder\n" +
+	"          opm_image: ' '\n" +
+	"          # Timeout is how long the step may run before it is aborted.\n" +
+	"          timeout: 0s\n" +
 	"          # UpdateGraph defines the update mode to use when adding the bundle to the base index.\n" +
 	"          # Can be: semver (default), semver-skippatch, or replaces\n" +
 	"          update_graph: ' '\n" +
@@ -177,6 +268,15 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"    # but not promote them afterwards.\n" +
 	"    excluded_images:\n" +
 	"        - \"\"\n" +
+	"    # ExternalRegistry is a registry outside of the cluster the images\n" +
+	"    # are pushed to in addition to the promotion target, e.g. quay.io.\n" +
+	"    # The credentials for it are taken from the push secret.\n" +
+	"    external_registry:\n" +
+	"        # Organization is the organization in the external registry the\n" +
+	"        # images are pushed to. Defaults to the promotion namespace.\n" +
+	"        organization: ' '\n" +
+	"        # Registry is the domain of the external registry.\n" +
+	"        registry: ' '\n" +
 	"    # Name is an optional image stream name to use that\n" +
 	"    # contains all component tags. If specified, tag is\n" +
 	"    # ignored.\n" +
@@ -203,13 +303,51 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"              pullspec: ' '\n" +
 	"              # With is the string that the PullSpec is being replaced by\n" +
 	"              with: ' '\n" +
+	"      external_image_import_step:\n" +
+	"        # Policy determines whether the image is pinned to the\n" +
+	"        # digest in the pull spec or tracks its tag. Defaults\n" +
+	"        # to pin.\n" +
+	"        policy: ' '\n" +
+	"        # PullSpec is the pull spec of the image in the external\n" +
+	"        # registry. It must contain a digest when the image is\n" +
+	"        # pinned and a tag when it is tracked.\n" +
+	"        pull_spec: ' '\n" +
+	"        # Refresh is how long a tracked image that was imported\n" +
+	"        # into the namespace of a job is reused by later jobs\n" +
+	"        # running in the same namespace before it is imported\n" +
+	"        # again. By default, every job imports it again.\n" +
+	"        refresh: 0s\n" +
+	"        to: ' '\n" +
+	"      image_scan_step:\n" +
+	"        # As is the name of the scan.\n" +
+	"        as: ' '\n" +
+	"        # Commands are the shell commands that scan the image.\n" +
+	"        commands: ' '\n" +
+	"        # Images are the images in the pipeline to scan.\n" +
+	"        images:\n" +
+	"            - \"\"\n" +
+	"        # Scanner is the pull spec of the image that provides the scanner,\n" +
+	"        # for instance clair or grype.\n" +
+	"        scanner: ' '\n" +
 	"      index_generator_step:\n" +
-	"        # BaseIndex is the index image to add the bundle(s) to. If unset, a new index is created\n" +
+	"        # BaseIndex is the index image to add the bundle(s) to. If unset, a new index is created.\n" +
+	"        # This is either the name of an image in the pipeline or the pull spec of a published index\n" +
 	"        base_index: ' '\n" +
+	"        # GracePeriod is how long the step may take to clean up after it was\n" +
+	"        # aborted before ci-operator stops waiting for it. Defaults to one minute.\n" +
+	"        grace_period: 0s\n" +
+	"        # IndexFormat defines how the content of the index is stored. If unset,\n" +
+	"        # a sqlite database is generated\n" +
+	"        index_format: ' '\n" +
 	"        # OperatorIndex is a list of the names of the bundle images that the\n" +
 	"        # index will contain in its database.\n" +
 	"        operator_index:\n" +
 	"            - \"\"\n" +
+	"        # OPMImage is the image that provides the opm binary. If unset,\n" +
+	"        # DefaultOPMImage is used\n" +
+	"        opm_image: ' '\n" +
+	"        # Timeout is how long the step may run before it is aborted.\n" +
+	"        timeout: 0s\n" +
 	"        to: ' '\n" +
 	"        # UpdateGraph defines the mode to us when updating the index graph\n" +
 	"        update_graph: ' '\n" +
@@ -244,9 +382,14 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"        # BuildArgs contains build arguments that will be resolved in the Dockerfile.\n" +
 	"        # See https://docs.docker.com/engine/reference/builder/#/arg for more details.\n" +
 	"        build_args:\n" +
-	"            - # Name of the build arg.\n" +
+	"            - # FromJobSpec specifies the value of the build arg is taken from the variable Prow\n" +
+	"              # exposes to the job, e.g. PULL_BASE_SHA or PULL_NUMBER. Variables that are not defined\n" +
+	"              # for the type of the job, like PULL_NUMBER for periodic jobs, resolve to an empty string.\n" +
+	"              # Cannot be set if Value or ValueFrom is set.\n" +
+	"              from_job_spec: ' '\n" +
+	"              # Name of the build arg.\n" +
 	"              name: ' '\n" +
-	"              # ValueFrom specifies the value of the build Arg is taken from a key of a secret. Cannot be set if Value is set.\n" +
+	"              # ValueFrom specifies the value of the build Arg is taken from a key of a secret. Cannot be set if Value or FromJobSpec is set.\n" +
 	"              secret_key_ref:\n" +
 	"                # Name is the key of the secret to take the value from\n" +
 	"                key: ' '\n" +
@@ -254,7 +397,7 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                name: ' '\n" +
 	"                # Namespace is the namespace of the secret\n" +
 	"                namespace: ' '\n" +
-	"              # Value of the build arg. Cannot be set if ValueFrom is set.\n" +
+	"              # Value of the build arg. Cannot be set if ValueFrom or FromJobSpec is set.\n" +
 	"              value: ' '\n" +
 	"        # ContextDir is the directory in the project\n" +
 	"        # from which this build should be run.\n" +
@@ -286,12 +429,29 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                      # SourcePath is a file or directory in the source image to copy from.\n" +
 	"                      source_path: ' '\n" +
 	"      project_directory_image_build_step:\n" +
+	"        # AdditionalNames are further names the image is published and promoted\n" +
+	"        # as, next to `to`. The image is only built once.\n" +
+	"        additional_names:\n" +
+	"            - \"\"\n" +
+	"        # Architectures are the architectures the image is built for. One\n" +
+	"        # build is run for every architecture and the results are assembled\n" +
+	"        # into a manifest list that is tagged as `to`. Resources for the build\n" +
+	"        # of a single architecture can be overridden under the `to-architecture`\n" +
+	"        # key, e.g. `src-arm64`. When unset, a single image is built for the\n" +
+	"        # architecture of the build cluster.\n" +
+	"        architectures:\n" +
+	"            - \"\"\n" +
 	"        # BuildArgs contains build arguments that will be resolved in the Dockerfile.\n" +
 	"        # See https://docs.docker.com/engine/reference/builder/#/arg for more details.\n" +
 	"        build_args:\n" +
-	"            - # Name of the build arg.\n" +
+	"            - # FromJobSpec specifies the value of the build arg is taken from the variable Prow\n" +
+	"              # exposes to the job, e.g. PULL_BASE_SHA or PULL_NUMBER. Variables that are not defined\n" +
+	"              # for the type of the job, like PULL_NUMBER for periodic jobs, resolve to an empty string.\n" +
+	"              # Cannot be set if Value or ValueFrom is set.\n" +
+	"              from_job_spec: ' '\n" +
+	"              # Name of the build arg.\n" +
 	"              name: ' '\n" +
-	"              # ValueFrom specifies the value of the build Arg is taken from a key of a secret. Cannot be set if Value is set.\n" +
+	"              # ValueFrom specifies the value of the build Arg is taken from a key of a secret. Cannot be set if Value or FromJobSpec is set.\n" +
 	"              secret_key_ref:\n" +
 	"                # Name is the key of the secret to take the value from\n" +
 	"                key: ' '\n" +
@@ -299,8 +459,15 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                name: ' '\n" +
 	"                # Namespace is the namespace of the secret\n" +
 	"                namespace: ' '\n" +
-	"              # Value of the build arg. Cannot be set if ValueFrom is set.\n" +
+	"              # Value of the build arg. Cannot be set if ValueFrom or FromJobSpec is set.\n" +
 	"              value: ' '\n" +
+	"        # BuildBackend selects how the image is built. Images are built as\n" +
+	"        # OpenShift builds unless `buildah-pod` is set, in which case buildah\n" +
+	"        # runs unprivileged in a pod in the test namespace, with the pipeline\n" +
+	"        # images mounted into the build context. Use it on clusters where the\n" +
+	"        # build controller is unavailable or too slow. It cannot be used with\n" +
+	"        # architectures or requires_entitlement.\n" +
+	"        build_backend: ' '\n" +
 	"        # ContextDir is the directory in the project\n" +
 	"        # from which this build should be run.\n" +
 	"        context_dir: ' '\n" +
@@ -311,6 +478,9 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"        # project to run relative to the context_dir.\n" +
 	"        dockerfile_path: ' '\n" +
 	"        from: ' '\n" +
+	"        # GracePeriod is how long the step may take to clean up after it was\n" +
+	"        # aborted before ci-operator stops waiting for it. Defaults to one minute.\n" +
+	"        grace_period: 0s\n" +
 	"        # Inputs is a map of tag reference name to image input changes\n" +
 	"        # that will populate the build context for the Dockerfile or\n" +
 	"        # alter the input image for a multi-stage build.\n" +
@@ -331,8 +501,13 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                      destination_dir: ' '\n" +
 	"                      # SourcePath is a file or directory in the source image to copy from.\n" +
 	"                      source_path: ' '\n" +
+	"        # Timeout is how long the step may run before it is aborted.\n" +
+	"        timeout: 0s\n" +
 	"        to: ' '\n" +
 	"      release_images_tag_step:\n" +
+	"        # Flavor is the flavor of the release payloads that are assembled\n" +
+	"        # from the stream. Defaults to ocp.\n" +
+	"        flavor: ' '\n" +
 	"        # Name is the image stream name to use that contains all\n" +
 	"        # component tags.\n" +
 	"        name: ' '\n" +
@@ -377,8 +552,31 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"        from: ' '\n" +
 	"        to: ' '\n" +
 	"      rpm_serve_step:\n" +
+	"        # Architectures are served from separate repositories.\n" +
+	"        architectures:\n" +
+	"            - \"\"\n" +
 	"        from: ' '\n" +
+	"        # Signing configures the key the repository metadata\n" +
+	"        # is signed with.\n" +
+	"        signing:\n" +
+	"            # Key is the key in the secret that holds the\n" +
+	"            # ASCII-armored private GPG key.\n" +
+	"            key: ' '\n" +
+	"            # Name is the secret holding the key.\n" +
+	"            name: ' '\n" +
+	"            # Namespace is where the secret holding the key exists.\n" +
+	"            namespace: ' '\n" +
 	"      source_step:\n" +
+	"        # Clone configures how the refs are cloned\n" +
+	"        clone:\n" +
+	"            # Filter makes the clones partial clones that omit the\n" +
+	"            # objects the filter excludes, e.g. `blob:none` fetches\n" +
+	"            # the files of the checked out commit only. Omitted\n" +
+	"            # objects are fetched when they are needed, which\n" +
+	"            # requires git in the image the source is built from.\n" +
+	"            # Supported filters are `blob:none`, `blob:limit=<n>[kmg]`\n" +
+	"            # and `tree:<depth>`.\n" +
+	"            filter: ' '\n" +
 	"        # ClonerefsImage is the image where we get the clonerefs tool\n" +
 	"        clonerefs_image:\n" +
 	"            # As is an optional string to use as the intermediate name for this reference.\n" +
@@ -389,9 +587,47 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"        # ClonerefsPath is the path in the above image where the\n" +
 	"        # clonerefs tool is placed\n" +
 	"        clonerefs_path: ' '\n" +
+	"        # ExtraRefs are cloned in addition to the refs of the job\n" +
+	"        extra_refs:\n" +
+	"            - base_link: ' '\n" +
+	"              base_ref: ' '\n" +
+	"              base_sha: ' '\n" +
+	"              clone_uri: ' '\n" +
+	"              org: ' '\n" +
+	"              path_alias: ' '\n" +
+	"              pulls:\n" +
+	"                - author: ' '\n" +
+	"                  author_link: ' '\n" +
+	"                  commit_link: ' '\n" +
+	"                  link: ' '\n" +
+	"                  number: 0\n" +
+	"                  ref: ' '\n" +
+	"                  sha: ' '\n" +
+	"                  title: ' '\n" +
+	"              repo: ' '\n" +
+	"              repo_link: ' '\n" +
 	"        from: ' '\n" +
+	"        # SourceArchive, if set, is extracted instead of\n" +
+	"        # cloning the source repositories\n" +
+	"        source_archive:\n" +
+	"            # From is the image that contains the archive. It can\n" +
+	"            # be produced by an earlier step or be imported from\n" +
+	"            # an external system through `base_images`.\n" +
+	"            from: ' '\n" +
+	"            # Path is the location of the archive in the above\n" +
+	"            # image. The archive can be uncompressed or be\n" +
+	"            # compressed with gzip, bzip2 or xz. Its content is\n" +
+	"            # extracted into the working directory of the source.\n" +
+	"            path: ' '\n" +
 	"        to: ' '\n" +
 	"      test_step:\n" +
+	"        # Architectures are additional architectures the test runs on. For each\n" +
+	"        # of them, prowgen generates another job for the test, named after the\n" +
+	"        # test and the architecture, e.g. `e2e-arm64`, that is dispatched to a\n" +
+	"        # build cluster with nodes of the architecture and runs the builds and\n" +
+	"        # the test on them. Postsubmits cannot run on additional architectures.\n" +
+	"        architectures:\n" +
+	"            - \"\"\n" +
 	"        # As is the name of the test.\n" +
 	"        as: ' '\n" +
 	"        # Cluster specifies the name of the cluster where the test runs.\n" +
@@ -403,8 +639,20 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"            architecture: ' '\n" +
 	"            # Cloud is the cloud where the product is installed, e.g., aws.\n" +
 	"            cloud: ' '\n" +
+	"            # Labels are additional labels the cluster pool must have, e.g. to\n" +
+	"            # select the pool of a team among those providing the same cluster.\n" +
+	"            labels:\n" +
+	"                \"\": \"\"\n" +
+	"            # Lifetime is how long the claimed cluster exists before Hive deletes\n" +
+	"            # it, in case the claim is never released. Defaults to 4h and can not\n" +
+	"            # exceed 12h.\n" +
+	"            lifetime: 0s\n" +
 	"            # Owner is the owner of cloud account used to install the product, e.g., dpp.\n" +
 	"            owner: ' '\n" +
+	"            # Pool is the name of the Hive ClusterPool to claim the cluster from.\n" +
+	"            # When set, the pool is not selected by the product, version,\n" +
+	"            # architecture, cloud and owner, which become optional.\n" +
+	"            pool: ' '\n" +
 	"            # Product is the name of the product being released.\n" +
 	"            # Defaults to ocp.\n" +
 	"            product: ' '\n" +
@@ -431,10 +679,22 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"        # of pull request workflows. Setting this field will\n" +
 	"        # create a periodic job instead of a presubmit\n" +
 	"        cron: \"\"\n" +
+	"        # GracePeriod is how long the step may take to clean up after it was\n" +
+	"        # aborted before ci-operator stops waiting for it. Defaults to one minute.\n" +
+	"        grace_period: 0s\n" +
 	"        # Interval is how frequently the test should be run based\n" +
 	"        # on the last time the test ran. Setting this field will\n" +
 	"        # create a periodic job instead of a presubmit\n" +
 	"        interval: \"\"\n" +
+	"        # Leases lists resources that are acquired for a container test. The\n" +
+	"        # names of the leased resources are exposed to the test container in\n" +
+	"        # the configured environment variables. Multi-stage tests configure\n" +
+	"        # their leases under `steps` instead.\n" +
+	"        leases:\n" +
+	"            - # Env is the environment variable that will contain the resource name.\n" +
+	"              env: ' '\n" +
+	"              # ResourceType is the type of resource that will be leased.\n" +
+	"              resource_type: ' '\n" +
 	"        literal_steps:\n" +
 	"            # AllowBestEffortPostSteps defines if any `post` steps can be ignored when\n" +
 	"            # they fail. The given step must explicitly ask for being ignored by setting\n" +
@@ -460,6 +720,49 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"            # Environment has the values of parameters for the steps.\n" +
 	"            env:\n" +
 	"                \"\": \"\"\n" +
+	"            # FallbackClusterProfiles are the profiles the test falls back to, in\n" +
+	"            # order, when the leases of ClusterProfile have no free capacity. The\n" +
+	"            # profile the test runs with is exposed to the steps as\n" +
+	"            # $CLUSTER_PROFILE_NAME.\n" +
+	"            fallback_cluster_profiles:\n" +
+	"                - \"\"\n" +
+	"            # Gather enables the built-in step that gathers must-gather, the audit\n" +
+	"            # logs and the events of the cluster under test at the start of the\n" +
+	"            # `post` phase, even when earlier steps failed.\n" +
+	"            gather:\n" +
+	"                # Resources defines the resource requirements for the gathering step.\n" +
+	"                # Defaults to requesting 300m of CPU and 300Mi of memory.\n" +
+	"                resources:\n" +
+	"                    # Architectures override the requests and limits for the builds\n" +
+	"                    # of images for one of the architectures.\n" +
+	"                    architectures:\n" +
+	"                        \"\":\n" +
+	"                            # Limits are resource limits applied to an individual step in the job.\n" +
+	"                            limits:\n" +
+	"                                \"\": \"\"\n" +
+	"                            # Requests are resource requests applied to an individual step in the job.\n" +
+	"                            requests:\n" +
+	"                                \"\": \"\"\n" +
+	"                    # ClusterProfiles override the requests and limits for steps that\n" +
+	"                    # run with one of the cluster profiles.\n" +
+	"                    cluster_profiles:\n" +
+	"                        \"\":\n" +
+	"                            # Limits are resource limits applied to an individual step in the job.\n" +
+	"                            limits:\n" +
+	"                                \"\": \"\"\n" +
+	"                            # Requests are resource requests applied to an individual step in the job.\n" +
+	"                            requests:\n" +
+	"                                \"\": \"\"\n" +
+	"                    # Limits are resource limits applied to an individual step in the job.\n" +
+	"                    # These are directly used in creating the Pods that execute the Job.\n" +
+	"                    limits:\n" +
+	"                        \"\": \"\"\n" +
+	"                    # Requests are resource requests applied to an individual step in the job.\n" +
+	"                    # These are directly used in creating the Pods that execute the Job.\n" +
+	"                    requests:\n" +
+	"                        \"\": \"\"\n" +
+	"                # Timeout is how long the gathering may take. Defaults to 30 minutes.\n" +
+	"                timeout: 0s\n" +
 	"            # Leases lists resources that should be acquired for the test.\n" +
 	"            leases:\n" +
 	"                - # Env is the environment variable that will contain the resource name.\n" +
@@ -484,7 +787,12 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"            # Post is the array of test steps run after the tests finish and teardown/deprovision resources.\n" +
 	"            # Post steps always run, even if previous steps fail.\n" +
 	"            post:\n" +
-	"                - # As is the name of the LiteralTestStep.\n" +
+	"                - # Architectures restricts the step to build clusters that have nodes of\n" +
+	"                  # at least one of the given architectures. On other clusters, the step is\n" +
+	"                  # skipped instead of failing. If unset, the step runs on all clusters.\n" +
+	"                  architectures:\n" +
+	"                    - \"\"\n" +
+	"                  # As is the name of the LiteralTestStep.\n" +
 	"                  as: ' '\n" +
 	"                  # BestEffort defines if this step should cause the job to fail when the\n" +
 	"                  # step fails. This only applies when AllowBestEffortPostSteps flag is set\n" +
@@ -498,7 +806,16 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                  commands: ' '\n" +
 	"                  # Credentials defines the credentials we'll mount into this step.\n" +
 	"                  credentials:\n" +
-	"                    - # MountPath is where the secret should be mounted.\n" +
+	"                    - # Env lists keys of the secret that are exposed to the step as\n" +
+	"                      # environment variables. Like the rest of the secret, their values\n" +
+	"                      # are censored from all output.\n" +
+	"                      env:\n" +
+	"                        - # Key in the secret whose value is exposed.\n" +
+	"                          key: ' '\n" +
+	"                          # Name of the environment variable.\n" +
+	"                          name: ' '\n" +
+	"                      # MountPath is where the secret should be mounted. It may be omitted\n" +
+	"                      # if keys of the secret are exposed through Env instead.\n" +
 	"                      mount_path: ' '\n" +
 	"                      # Names is which source secret to mount.\n" +
 	"                      name: ' '\n" +
@@ -511,6 +828,10 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                      env: ' '\n" +
 	"                      # Name is the tag or stream:tag that this dependency references\n" +
 	"                      name: ' '\n" +
+	"                      # Release is the name of the release the image is taken from, like\n" +
+	"                      # `initial`, `latest` or the name of a custom release. If set, Name is\n" +
+	"                      # the tag of the image in the release.\n" +
+	"                      release: ' '\n" +
 	"                  # DnsConfig for step's Pod.\n" +
 	"                  dnsConfig:\n" +
 	"                    # Nameservers is a list of IP addresses that will be used as DNS servers for the Pod\n" +
@@ -527,6 +848,12 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                      documentation: ' '\n" +
 	"                      # Name of the environment variable.\n" +
 	"                      name: ' '\n" +
+	"                      # Type of the value of the parameter, one of `string` (the\n" +
+	"                      # default), `integer`, `boolean` or `enum`.\n" +
+	"                      type: ' '\n" +
+	"                      # Values are the values allowed for an `enum` parameter.\n" +
+	"                      values:\n" +
+	"                        - \"\"\n" +
 	"                  # From is the container image that will be used for this step.\n" +
 	"                  from: ' '\n" +
 	"                  # FromImage is a literal ImageStreamTag reference to use for this step.\n" +
@@ -539,6 +866,18 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                  # GracePeriod is how long the we will wait after sending SIGINT to send\n" +
 	"                  # SIGKILL when aborting a Step.\n" +
 	"                  grace_period: 0s\n" +
+	"                  # InitContainers run in order before the step to prepare data for it,\n" +
+	"                  # e.g. to download fixtures or unpack tools, keeping the image and the\n" +
+	"                  # commands of the step simple. They write the data into the directory\n" +
+	"                  # exposed to them and to the step as $INIT_DIR.\n" +
+	"                  init_containers:\n" +
+	"                    - # As is the name of the init container.\n" +
+	"                      as: ' '\n" +
+	"                      # Commands is the command(s) that will be run inside the image.\n" +
+	"                      commands: ' '\n" +
+	"                      # From is the container image that will be used, it is resolved like\n" +
+	"                      # the `from` of the step.\n" +
+	"                      from: ' '\n" +
 	"                  # Leases lists resources that should be acquired for the test.\n" +
 	"                  leases:\n" +
 	"                    - # Env is the environment variable that will contain the resource name.\n" +
@@ -548,6 +887,9 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                  # Observers are the observers that should be running\n" +
 	"                  observers:\n" +
 	"                    - \"\"\n" +
+	"                  # Optional defines if a failure of this step should be ignored: it\n" +
+	"                  # neither fails the test nor prevents the following steps from running.\n" +
+	"                  optional: false\n" +
 	"                  # OptionalOnSuccess defines if this step should be skipped as long\n" +
 	"                  # as all `pre` and `test` steps were successful and AllowSkipOnSuccess\n" +
 	"                  # flag is set to true in MultiStageTestConfiguration. This option is\n" +
@@ -555,6 +897,26 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                  optional_on_success: false\n" +
 	"                  # Resources defines the resource requirements for the step.\n" +
 	"                  resources:\n" +
+	"                    # Architectures override the requests and limits for the builds\n" +
+	"                    # of images for one of the architectures.\n" +
+	"                    architectures:\n" +
+	"                        \"\":\n" +
+	"                            # Limits are resource limits applied to an individual step in the job.\n" +
+	"                            limits:\n" +
+	"                                \"\": \"\"\n" +
+	"                            # Requests are resource requests applied to an individual step in the job.\n" +
+	"                            requests:\n" +
+	"                                \"\": \"\"\n" +
+	"                    # ClusterProfiles override the requests and limits for steps that\n" +
+	"                    # run with one of the cluster profiles.\n" +
+	"                    cluster_profiles:\n" +
+	"                        \"\":\n" +
+	"                            # Limits are resource limits applied to an individual step in the job.\n" +
+	"                            limits:\n" +
+	"                                \"\": \"\"\n" +
+	"                            # Requests are resource requests applied to an individual step in the job.\n" +
+	"                            requests:\n" +
+	"                                \"\": \"\"\n" +
 	"                    # Limits are resource limits applied to an individual step in the job.\n" +
 	"                    # These are directly used in creating the Pods that execute the Job.\n" +
 	"                    limits:\n" +
@@ -563,14 +925,28 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                    # These are directly used in creating the Pods that execute the Job.\n" +
 	"                    requests:\n" +
 	"                        \"\": \"\"\n" +
+	"                  # Retries is how many more times the step is run when it fails before\n" +
+	"                  # its failure is reported, e.g. for steps that provision infrastructure\n" +
+	"                  # and fail intermittently. Defaults to 0.\n" +
+	"                  retries: 0\n" +
 	"                  # RunAsScript defines if this step should be executed as a script mounted\n" +
 	"                  # in the test container instead of being executed directly via bash\n" +
 	"                  run_as_script: false\n" +
+	"                  # RunIf defines when the step runs, based on whether any step before it\n" +
+	"                  # failed: `success` runs it only if none failed, `failure` only if one\n" +
+	"                  # failed and `always` regardless. Defaults to `success` for `pre` and\n" +
+	"                  # `test` steps and to `always` for `post` steps.\n" +
+	"                  run_if: ' '\n" +
 	"                  # Timeout is how long the we will wait before aborting a job with SIGINT.\n" +
 	"                  timeout: 0s\n" +
 	"            # Pre is the array of test steps run to set up the environment for the test.\n" +
 	"            pre:\n" +
-	"                - # As is the name of the LiteralTestStep.\n" +
+	"                - # Architectures restricts the step to build clusters that have nodes of\n" +
+	"                  # at least one of the given architectures. On other clusters, the step is\n" +
+	"                  # skipped instead of failing. If unset, the step runs on all clusters.\n" +
+	"                  architectures:\n" +
+	"                    - \"\"\n" +
+	"                  # As is the name of the LiteralTestStep.\n" +
 	"                  as: ' '\n" +
 	"                  # BestEffort defines if this step should cause the job to fail when the\n" +
 	"                  # step fails. This only applies when AllowBestEffortPostSteps flag is set\n" +
@@ -584,7 +960,16 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                  commands: ' '\n" +
 	"                  # Credentials defines the credentials we'll mount into this step.\n" +
 	"                  credentials:\n" +
-	"                    - # MountPath is where the secret should be mounted.\n" +
+	"                    - # Env lists keys of the secret that are exposed to the step as\n" +
+	"                      # environment variables. Like the rest of the secret, their values\n" +
+	"                      # are censored from all output.\n" +
+	"                      env:\n" +
+	"                        - # Key in the secret whose value is exposed.\n" +
+	"                          key: ' '\n" +
+	"                          # Name of the environment variable.\n" +
+	"                          name: ' '\n" +
+	"                      # MountPath is where the secret should be mounted. It may be omitted\n" +
+	"                      # if keys of the secret are exposed through Env instead.\n" +
 	"                      mount_path: ' '\n" +
 	"                      # Names is which source secret to mount.\n" +
 	"                      name: ' '\n" +
@@ -597,6 +982,10 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                      env: ' '\n" +
 	"                      # Name is the tag or stream:tag that this dependency references\n" +
 	"                      name: ' '\n" +
+	"                      # Release is the name of the release the image is taken from, like\n" +
+	"                      # `initial`, `latest` or the name of a custom release. If set, Name is\n" +
+	"                      # the tag of the image in the release.\n" +
+	"                      release: ' '\n" +
 	"                  # DnsConfig for step's Pod.\n" +
 	"                  dnsConfig:\n" +
 	"                    # Nameservers is a list of IP addresses that will be used as DNS servers for the Pod\n" +
@@ -613,6 +1002,12 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                      documentation: ' '\n" +
 	"                      # Name of the environment variable.\n" +
 	"                      name: ' '\n" +
+	"                      # Type of the value of the parameter, one of `string` (the\n" +
+	"                      # default), `integer`, `boolean` or `enum`.\n" +
+	"                      type: ' '\n" +
+	"                      # Values are the values allowed for an `enum` parameter.\n" +
+	"                      values:\n" +
+	"                        - \"\"\n" +
 	"                  # From is the container image that will be used for this step.\n" +
 	"                  from: ' '\n" +
 	"                  # FromImage is a literal ImageStreamTag reference to use for this step.\n" +
@@ -625,6 +1020,18 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                  # GracePeriod is how long the we will wait after sending SIGINT to send\n" +
 	"                  # SIGKILL when aborting a Step.\n" +
 	"                  grace_period: 0s\n" +
+	"                  # InitContainers run in order before the step to prepare data for it,\n" +
+	"                  # e.g. to download fixtures or unpack tools, keeping the image and the\n" +
+	"                  # commands of the step simple. They write the data into the directory\n" +
+	"                  # exposed to them and to the step as $INIT_DIR.\n" +
+	"                  init_containers:\n" +
+	"                    - # As is the name of the init container.\n" +
+	"                      as: ' '\n" +
+	"                      # Commands is the command(s) that will be run inside the image.\n" +
+	"                      commands: ' '\n" +
+	"                      # From is the container image that will be used, it is resolved like\n" +
+	"                      # the `from` of the step.\n" +
+	"                      from: ' '\n" +
 	"                  # Leases lists resources that should be acquired for the test.\n" +
 	"                  leases:\n" +
 	"                    - # Env is the environment variable that will contain the resource name.\n" +
@@ -634,6 +1041,9 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                  # Observers are the observers that should be running\n" +
 	"                  observers:\n" +
 	"                    - \"\"\n" +
+	"                  # Optional defines if a failure of this step should be ignored: it\n" +
+	"                  # neither fails the test nor prevents the following steps from running.\n" +
+	"                  optional: false\n" +
 	"                  # OptionalOnSuccess defines if this step should be skipped as long\n" +
 	"                  # as all `pre` and `test` steps were successful and AllowSkipOnSuccess\n" +
 	"                  # flag is set to true in MultiStageTestConfiguration. This option is\n" +
@@ -641,6 +1051,26 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                  optional_on_success: false\n" +
 	"                  # Resources defines the resource requirements for the step.\n" +
 	"                  resources:\n" +
+	"                    # Architectures override the requests and limits for the builds\n" +
+	"                    # of images for one of the architectures.\n" +
+	"                    architectures:\n" +
+	"                        \"\":\n" +
+	"                            # Limits are resource limits applied to an individual step in the job.\n" +
+	"                            limits:\n" +
+	"                                \"\": \"\"\n" +
+	"                            # Requests are resource requests applied to an individual step in the job.\n" +
+	"                            requests:\n" +
+	"                                \"\": \"\"\n" +
+	"                    # ClusterProfiles override the requests and limits for steps that\n" +
+	"                    # run with one of the cluster profiles.\n" +
+	"                    cluster_profiles:\n" +
+	"                        \"\":\n" +
+	"                            # Limits are resource limits applied to an individual step in the job.\n" +
+	"                            limits:\n" +
+	"                                \"\": \"\"\n" +
+	"                            # Requests are resource requests applied to an individual step in the job.\n" +
+	"                            requests:\n" +
+	"                                \"\": \"\"\n" +
 	"                    # Limits are resource limits applied to an individual step in the job.\n" +
 	"                    # These are directly used in creating the Pods that execute the Job.\n" +
 	"                    limits:\n" +
@@ -649,14 +1079,28 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                    # These are directly used in creating the Pods that execute the Job.\n" +
 	"                    requests:\n" +
 	"                        \"\": \"\"\n" +
+	"                  # Retries is how many more times the step is run when it fails before\n" +
+	"                  # its failure is reported, e.g. for steps that provision infrastructure\n" +
+	"                  # and fail intermittently. Defaults to 0.\n" +
+	"                  retries: 0\n" +
 	"                  # RunAsScript defines if this step should be executed as a script mounted\n" +
 	"                  # in the test container instead of being executed directly via bash\n" +
 	"                  run_as_script: false\n" +
+	"                  # RunIf defines when the step runs, based on whether any step before it\n" +
+	"                  # failed: `success` runs it only if none failed, `failure` only if one\n" +
+	"                  # failed and `always` regardless. Defaults to `success` for `pre` and\n" +
+	"                  # `test` steps and to `always` for `post` steps.\n" +
+	"                  run_if: ' '\n" +
 	"                  # Timeout is how long the we will wait before aborting a job with SIGINT.\n" +
 	"                  timeout: 0s\n" +
 	"            # Test is the array of test steps that define the actual test.\n" +
 	"            test:\n" +
-	"                - # As is the name of the LiteralTestStep.\n" +
+	"                - # Architectures restricts the step to build clusters that have nodes of\n" +
+	"                  # at least one of the given architectures. On other clusters, the step is\n" +
+	"                  # skipped instead of failing. If unset, the step runs on all clusters.\n" +
+	"                  architectures:\n" +
+	"                    - \"\"\n" +
+	"                  # As is the name of the LiteralTestStep.\n" +
 	"                  as: ' '\n" +
 	"                  # BestEffort defines if this step should cause the job to fail when the\n" +
 	"                  # step fails. This only applies when AllowBestEffortPostSteps flag is set\n" +
@@ -670,7 +1114,16 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                  commands: ' '\n" +
 	"                  # Credentials defines the credentials we'll mount into this step.\n" +
 	"                  credentials:\n" +
-	"                    - # MountPath is where the secret should be mounted.\n" +
+	"                    - # Env lists keys of the secret that are exposed to the step as\n" +
+	"                      # environment variables. Like the rest of the secret, their values\n" +
+	"                      # are censored from all output.\n" +
+	"                      env:\n" +
+	"                        - # Key in the secret whose value is exposed.\n" +
+	"                          key: ' '\n" +
+	"                          # Name of the environment variable.\n" +
+	"                          name: ' '\n" +
+	"                      # MountPath is where the secret should be mounted. It may be omitted\n" +
+	"                      # if keys of the secret are exposed through Env instead.\n" +
 	"                      mount_path: ' '\n" +
 	"                      # Names is which source secret to mount.\n" +
 	"                      name: ' '\n" +
@@ -683,6 +1136,10 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                      env: ' '\n" +
 	"                      # Name is the tag or stream:tag that this dependency references\n" +
 	"                      name: ' '\n" +
+	"                      # Release is the name of the release the image is taken from, like\n" +
+	"                      # `initial`, `latest` or the name of a custom release. If set, Name is\n" +
+	"                      # the tag of the image in the release.\n" +
+	"                      release: ' '\n" +
 	"                  # DnsConfig for step's Pod.\n" +
 	"                  dnsConfig:\n" +
 	"                    # Nameservers is a list of IP addresses that will be used as DNS servers for the Pod\n" +
@@ -699,6 +1156,12 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                      documentation: ' '\n" +
 	"                      # Name of the environment variable.\n" +
 	"                      name: ' '\n" +
+	"                      # Type of the value of the parameter, one of `string` (the\n" +
+	"                      # default), `integer`, `boolean` or `enum`.\n" +
+	"                      type: ' '\n" +
+	"                      # Values are the values allowed for an `enum` parameter.\n" +
+	"                      values:\n" +
+	"                        - \"\"\n" +
 	"                  # From is the container image that will be used for this step.\n" +
 	"                  from: ' '\n" +
 	"                  # FromImage is a literal ImageStreamTag reference to use for this step.\n" +
@@ -711,6 +1174,18 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                  # GracePeriod is how long the we will wait after sending SIGINT to send\n" +
 	"                  # SIGKILL when aborting a Step.\n" +
 	"                  grace_period: 0s\n" +
+	"                  # InitContainers run in order before the step to prepare data for it,\n" +
+	"                  # e.g. to download fixtures or unpack tools, keeping the image and the\n" +
+	"                  # commands of the step simple. They write the data into the directory\n" +
+	"                  # exposed to them and to the step as $INIT_DIR.\n" +
+	"                  init_containers:\n" +
+	"                    - # As is the name of the init container.\n" +
+	"                      as: ' '\n" +
+	"                      # Commands is the command(s) that will be run inside the image.\n" +
+	"                      commands: ' '\n" +
+	"                      # From is the container image that will be used, it is resolved like\n" +
+	"                      # the `from` of the step.\n" +
+	"                      from: ' '\n" +
 	"                  # Leases lists resources that should be acquired for the test.\n" +
 	"                  leases:\n" +
 	"                    - # Env is the environment variable that will contain the resource name.\n" +
@@ -720,6 +1195,9 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                  # Observers are the observers that should be running\n" +
 	"                  observers:\n" +
 	"                    - \"\"\n" +
+	"                  # Optional defines if a failure of this step should be ignored: it\n" +
+	"                  # neither fails the test nor prevents the following steps from running.\n" +
+	"                  optional: false\n" +
 	"                  # OptionalOnSuccess defines if this step should be skipped as long\n" +
 	"                  # as all `pre` and `test` steps were successful and AllowSkipOnSuccess\n" +
 	"                  # flag is set to true in MultiStageTestConfiguration. This option is\n" +
@@ -727,6 +1205,26 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                  optional_on_success: false\n" +
 	"                  # Resources defines the resource requirements for the step.\n" +
 	"                  resources:\n" +
+	"                    # Architectures override the requests and limits for the builds\n" +
+	"                    # of images for one of the architectures.\n" +
+	"                    architectures:\n" +
+	"                        \"\":\n" +
+	"                            # Limits are resource limits applied to an individual step in the job.\n" +
+	"                            limits:\n" +
+	"                                \"\": \"\"\n" +
+	"                            # Requests are resource requests applied to an individual step in the job.\n" +
+	"                            requests:\n" +
+	"                                \"\": \"\"\n" +
+	"                    # ClusterProfiles override the requests and limits for steps that\n" +
+	"                    # run with one of the cluster profiles.\n" +
+	"                    cluster_profiles:\n" +
+	"                        \"\":\n" +
+	"                            # Limits are resource limits applied to an individual step in the job.\n" +
+	"                            limits:\n" +
+	"                                \"\": \"\"\n" +
+	"                            # Requests are resource requests applied to an individual step in the job.\n" +
+	"                            requests:\n" +
+	"                                \"\": \"\"\n" +
 	"                    # Limits are resource limits applied to an individual step in the job.\n" +
 	"                    # These are directly used in creating the Pods that execute the Job.\n" +
 	"                    limits:\n" +
@@ -735,11 +1233,33 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                    # These are directly used in creating the Pods that execute the Job.\n" +
 	"                    requests:\n" +
 	"                        \"\": \"\"\n" +
+	"                  # Retries is how many more times the step is run when it fails before\n" +
+	"                  # its failure is reported, e.g. for steps that provision infrastructure\n" +
+	"                  # and fail intermittently. Defaults to 0.\n" +
+	"                  retries: 0\n" +
 	"                  # RunAsScript defines if this step should be executed as a script mounted\n" +
 	"                  # in the test container instead of being executed directly via bash\n" +
 	"                  run_as_script: false\n" +
+	"                  # RunIf defines when the step runs, based on whether any step before it\n" +
+	"                  # failed: `success` runs it only if none failed, `failure` only if one\n" +
+	"                  # failed and `always` regardless. Defaults to `success` for `pre` and\n" +
+	"                  # `test` steps and to `always` for `post` steps.\n" +
+	"                  run_if: ' '\n" +
 	"                  # Timeout is how long the we will wait before aborting a job with SIGINT.\n" +
 	"                  timeout: 0s\n" +
+	"        # NamespaceTTL configures how long the namespace the test runs in is\n" +
+	"        # retained, e.g. to keep it around for debugging after a failure.\n" +
+	"        namespace_ttl:\n" +
+	"            # Hard is how long the namespace is retained after it was last\n" +
+	"            # active. Overrides the --delete-after flag of ci-operator.\n" +
+	"            hard: 0s\n" +
+	"            # Idle is how long the namespace is retained after it was last\n" +
+	"            # active once no pods run in it anymore. Overrides the\n" +
+	"            # --delete-when-idle flag of ci-operator.\n" +
+	"            idle: 0s\n" +
+	"            # KeepOnFailure is how long the namespace is retained after the\n" +
+	"            # test failed, regardless of the other TTLs.\n" +
+	"            keep_on_failure: 0s\n" +
 	"        openshift_ansible:\n" +
 	"            cluster_profile: ' '\n" +
 	"        openshift_ansible_custom:\n" +
@@ -777,6 +1297,9 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"              mount_path: ' '\n" +
 	"              # Secret name, used inside test containers\n" +
 	"              name: ' '\n" +
+	"        # SourceSnapshot is the name of an entry in source_snapshots\n" +
+	"        # whose image replaces \"src\" for this test.\n" +
+	"        source_snapshot: ' '\n" +
 	"        steps:\n" +
 	"            # AllowBestEffortPostSteps defines if any `post` steps can be ignored when\n" +
 	"            # they fail. The given step must explicitly ask for being ignored by setting\n" +
@@ -802,6 +1325,49 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"            # Environment has the values of parameters for the steps.\n" +
 	"            env:\n" +
 	"                \"\": \"\"\n" +
+	"            # FallbackClusterProfiles are the profiles the test falls back to, in\n" +
+	"            # order, when the leases of ClusterProfile have no free capacity. The\n" +
+	"            # profile the test runs with is exposed to the steps as\n" +
+	"            # $CLUSTER_PROFILE_NAME.\n" +
+	"            fallback_cluster_profiles:\n" +
+	"                - \"\"\n" +
+	"            # Gather enables the built-in step that gathers must-gather, the audit\n" +
+	"            # logs and the events of the cluster under test at the start of the\n" +
+	"            # `post` phase, even when earlier steps failed.\n" +
+	"            gather:\n" +
+	"                # Resources defines the resource requirements for the gathering step.\n" +
+	"                # Defaults to requesting 300m of CPU and 300Mi of memory.\n" +
+	"                resources:\n" +
+	"                    # Architectures override the requests and limits for the builds\n" +
+	"                    # of images for one of the architectures.\n" +
+	"                    architectures:\n" +
+	"                        \"\":\n" +
+	"                            # Limits are resource limits applied to an individual step in the job.\n" +
+	"                            limits:\n" +
+	"                                \"\": \"\"\n" +
+	"                            # Requests are resource requests applied to an individual step in the job.\n" +
+	"                            requests:\n" +
+	"                                \"\": \"\"\n" +
+	"                    # ClusterProfiles override the requests and limits for steps that\n" +
+	"                    # run with one of the cluster profiles.\n" +
+	"                    cluster_profiles:\n" +
+	"                        \"\":\n" +
+	"                            # Limits are resource limits applied to an individual step in the job.\n" +
+	"                            limits:\n" +
+	"                                \"\": \"\"\n" +
+	"                            # Requests are resource requests applied to an individual step in the job.\n" +
+	"                            requests:\n" +
+	"                                \"\": \"\"\n" +
+	"                    # Limits are resource limits applied to an individual step in the job.\n" +
+	"                    # These are directly used in creating the Pods that execute the Job.\n" +
+	"                    limits:\n" +
+	"                        \"\": \"\"\n" +
+	"                    # Requests are resource requests applied to an individual step in the job.\n" +
+	"                    # These are directly used in creating the Pods that execute the Job.\n" +
+	"                    requests:\n" +
+	"                        \"\": \"\"\n" +
+	"                # Timeout is how long the gathering may take. Defaults to 30 minutes.\n" +
+	"                timeout: 0s\n" +
 	"            # Leases lists resources that should be acquired for the test.\n" +
 	"            leases:\n" +
 	"                - # Env is the environment variable that will contain the resource name.\n" +
@@ -821,7 +1387,10 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"            # execution if previous Pre and Test steps passed.\n" +
 	"            post:\n" +
 	"                # LiteralTestStep is a full test step definition.\n" +
-	"                - as: ' '\n" +
+	"                - architectures:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    - \"\"\n" +
+	"                  as: ' '\n" +
 	"                  best_effort: false\n" +
 	"                  # Chain is the name of a step chain reference.\n" +
 	"                  chain: \"\"\n" +
@@ -831,13 +1400,18 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                  commands: ' '\n" +
 	"                  credentials:\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
-	"                    - mount_path: ' '\n" +
+	"                    - env:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - key: ' '\n" +
+	"                          name: ' '\n" +
+	"                      mount_path: ' '\n" +
 	"                      name: ' '\n" +
 	"                      namespace: ' '\n" +
 	"                  dependencies:\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
 	"                    - env: ' '\n" +
 	"                      name: ' '\n" +
+	"                      release: ' '\n" +
 	"                  dnsConfig:\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
 	"                    nameservers:\n" +
@@ -851,6 +1425,10 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                    - default: \"\"\n" +
 	"                      documentation: ' '\n" +
 	"                      name: ' '\n" +
+	"                      type: ' '\n" +
+	"                      values:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - \"\"\n" +
 	"                  from: ' '\n" +
 	"                  from_image:\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
@@ -859,6 +1437,11 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                    namespace: ' '\n" +
 	"                    tag: ' '\n" +
 	"                  grace_period: 0s\n" +
+	"                  init_containers:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    - as: ' '\n" +
+	"                      commands: ' '\n" +
+	"                      from: ' '\n" +
 	"                  leases:\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
 	"                    - env: ' '\n" +
@@ -866,24 +1449,44 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                  observers:\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
 	"                    - \"\"\n" +
+	"                  optional: false\n" +
 	"                  optional_on_success: false\n" +
 	"                  # Reference is the name of a step reference.\n" +
 	"                  ref: \"\"\n" +
 	"                  # Resources defines the resource requirements for the step.\n" +
 	"                  resources:\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    architectures:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        \"\":\n" +
+	"                            limits:\n" +
+	"                                \"\": \"\"\n" +
+	"                            requests:\n" +
+	"                                \"\": \"\"\n" +
+	"                    cluster_profiles:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        \"\":\n" +
+	"                            limits:\n" +
+	"                                \"\": \"\"\n" +
+	"                            requests:\n" +
+	"                                \"\": \"\"\n" +
 	"                    limits:\n" +
 	"                        # LiteralTestStep is a full test step definition.\n" +
 	"                        \"\": \"\"\n" +
 	"                    requests:\n" +
 	"                        # LiteralTestStep is a full test step definition.\n" +
 	"                        \"\": \"\"\n" +
+	"                  retries: 0\n" +
 	"                  run_as_script: false\n" +
+	"                  run_if: ' '\n" +
 	"                  timeout: 0s\n" +
 	"            # Pre is the array of test steps run to set up the environment for the test.\n" +
 	"            pre:\n" +
 	"                # LiteralTestStep is a full test step definition.\n" +
-	"                - as: ' '\n" +
+	"                - architectures:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    - \"\"\n" +
+	"                  as: ' '\n" +
 	"                  best_effort: false\n" +
 	"                  # Chain is the name of a step chain reference.\n" +
 	"                  chain: \"\"\n" +
@@ -893,13 +1496,18 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                  commands: ' '\n" +
 	"                  credentials:\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
-	"                    - mount_path: ' '\n" +
+	"                    - env:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - key: ' '\n" +
+	"                          name: ' '\n" +
+	"                      mount_path: ' '\n" +
 	"                      name: ' '\n" +
 	"                      namespace: ' '\n" +
 	"                  dependencies:\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
 	"                    - env: ' '\n" +
 	"                      name: ' '\n" +
+	"                      release: ' '\n" +
 	"                  dnsConfig:\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
 	"                    nameservers:\n" +
@@ -913,6 +1521,10 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                    - default: \"\"\n" +
 	"                      documentation: ' '\n" +
 	"                      name: ' '\n" +
+	"                      type: ' '\n" +
+	"                      values:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - \"\"\n" +
 	"                  from: ' '\n" +
 	"                  from_image:\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
@@ -921,6 +1533,11 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                    namespace: ' '\n" +
 	"                    tag: ' '\n" +
 	"                  grace_period: 0s\n" +
+	"                  init_containers:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    - as: ' '\n" +
+	"                      commands: ' '\n" +
+	"                      from: ' '\n" +
 	"                  leases:\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
 	"                    - env: ' '\n" +
@@ -928,24 +1545,44 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                  observers:\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
 	"                    - \"\"\n" +
+	"                  optional: false\n" +
 	"                  optional_on_success: false\n" +
 	"                  # Reference is the name of a step reference.\n" +
 	"                  ref: \"\"\n" +
 	"                  # Resources defines the resource requirements for the step.\n" +
 	"                  resources:\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    architectures:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        \"\":\n" +
+	"                            limits:\n" +
+	"                                \"\": \"\"\n" +
+	"                            requests:\n" +
+	"                                \"\": \"\"\n" +
+	"                    cluster_profiles:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        \"\":\n" +
+	"                            limits:\n" +
+	"                                \"\": \"\"\n" +
+	"                            requests:\n" +
+	"                                \"\": \"\"\n" +
 	"                    limits:\n" +
 	"                        # LiteralTestStep is a full test step definition.\n" +
 	"                        \"\": \"\"\n" +
 	"                    requests:\n" +
 	"                        # LiteralTestStep is a full test step definition.\n" +
 	"                        \"\": \"\"\n" +
+	"                  retries: 0\n" +
 	"                  run_as_script: false\n" +
+	"                  run_if: ' '\n" +
 	"                  timeout: 0s\n" +
 	"            # Test is the array of test steps that define the actual test.\n" +
 	"            test:\n" +
 	"                # LiteralTestStep is a full test step definition.\n" +
-	"                - as: ' '\n" +
+	"                - architectures:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    - \"\"\n" +
+	"                  as: ' '\n" +
 	"                  best_effort: false\n" +
 	"                  # Chain is the name of a step chain reference.\n" +
 	"                  chain: \"\"\n" +
@@ -955,13 +1592,18 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                  commands: ' '\n" +
 	"                  credentials:\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
-	"                    - mount_path: ' '\n" +
+	"                    - env:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - key: ' '\n" +
+	"                          name: ' '\n" +
+	"                      mount_path: ' '\n" +
 	"                      name: ' '\n" +
 	"                      namespace: ' '\n" +
 	"                  dependencies:\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
 	"                    - env: ' '\n" +
 	"                      name: ' '\n" +
+	"                      release: ' '\n" +
 	"                  dnsConfig:\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
 	"                    nameservers:\n" +
@@ -975,6 +1617,10 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                    - default: \"\"\n" +
 	"                      documentation: ' '\n" +
 	"                      name: ' '\n" +
+	"                      type: ' '\n" +
+	"                      values:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - \"\"\n" +
 	"                  from: ' '\n" +
 	"                  from_image:\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
@@ -983,6 +1629,11 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                    namespace: ' '\n" +
 	"                    tag: ' '\n" +
 	"                  grace_period: 0s\n" +
+	"                  init_containers:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    - as: ' '\n" +
+	"                      commands: ' '\n" +
+	"                      from: ' '\n" +
 	"                  leases:\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
 	"                    - env: ' '\n" +
@@ -990,23 +1641,42 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                  observers:\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
 	"                    - \"\"\n" +
+	"                  optional: false\n" +
 	"                  optional_on_success: false\n" +
 	"                  # Reference is the name of a step reference.\n" +
 	"                  ref: \"\"\n" +
 	"                  # Resources defines the resource requirements for the step.\n" +
 	"                  resources:\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    architectures:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        \"\":\n" +
+	"                            limits:\n" +
+	"                                \"\": \"\"\n" +
+	"                            requests:\n" +
+	"                                \"\": \"\"\n" +
+	"                    cluster_profiles:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        \"\":\n" +
+	"                            limits:\n" +
+	"                                \"\": \"\"\n" +
+	"                            requests:\n" +
+	"                                \"\": \"\"\n" +
 	"                    limits:\n" +
 	"                        # LiteralTestStep is a full test step definition.\n" +
 	"                        \"\": \"\"\n" +
 	"                    requests:\n" +
 	"                        # LiteralTestStep is a full test step definition.\n" +
 	"                        \"\": \"\"\n" +
+	"                  retries: 0\n" +
 	"                  run_as_script: false\n" +
+	"                  run_if: ' '\n" +
 	"                  timeout: 0s\n" +
 	"            # Workflow is the name of the workflow to be used for this configuration. For fields defined in both\n" +
 	"            # the config and the workflow, the fields from the config will override what is set in Workflow.\n" +
 	"            workflow: \"\"\n" +
+	"        # Timeout is how long the step may run before it is aborted.\n" +
+	"        timeout: 0s\n" +
 	"# Releases maps semantic release payload identifiers\n" +
 	"# to the names that they will be exposed under. For\n" +
 	"# instance, an 'initial' name will be exposed as\n" +
@@ -1051,10 +1721,29 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"# requests and limits.\n" +
 	"resources:\n" +
 	"    \"\":\n" +
+	"        architectures:\n" +
+	"            \"\":\n" +
+	"                limits:\n" +
+	"                    \"\": \"\"\n" +
+	"                requests:\n" +
+	"                    \"\": \"\"\n" +
+	"        cluster_profiles:\n" +
+	"            \"\":\n" +
+	"                limits:\n" +
+	"                    \"\": \"\"\n" +
+	"                requests:\n" +
+	"                    \"\": \"\"\n" +
 	"        limits:\n" +
 	"            \"\": \"\"\n" +
 	"        requests:\n" +
 	"            \"\": \"\"\n" +
+	"# RpmBuildArchitectures are the architectures the RPM build produces\n" +
+	"# packages for. When set, the RPMs are served from one repository per\n" +
+	"# architecture under its RPM name, e.g. x86_64/, which also holds the\n" +
+	"# noarch packages. The repository injected into images selects the one\n" +
+	"# for their architecture. The \"rpms\" image must provide createrepo_c.\n" +
+	"rpm_build_architectures:\n" +
+	"    - \"\"\n" +
 	"# RpmBuildCommands will create an \"rpms\" image from \"bin\" (or \"src\", if no\n" +
 	"# binary build commands were specified) that contains the output of this\n" +
 	"# command. The created RPMs will then be served via HTTP to the \"base\" image\n" +
@@ -1064,9 +1753,67 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"# unset, this will default under the repository root to\n" +
 	"# _output/local/releases/rpms/.\n" +
 	"rpm_build_location: ' '\n" +
+	"# RpmSigning makes the metadata of the served RPM repositories signed\n" +
+	"# with a key from a secret, so that consumers can validate it the same\n" +
+	"# way they do for production composes. The \"rpms\" image must provide gpg.\n" +
+	"rpm_signing:\n" +
+	"    # Key is the key in the secret that holds the\n" +
+	"    # ASCII-armored private GPG key.\n" +
+	"    key: ' '\n" +
+	"    # Name is the secret holding the key.\n" +
+	"    name: ' '\n" +
+	"    # Namespace is where the secret holding the key exists.\n" +
+	"    namespace: ' '\n" +
+	"# SourceArchive configures the source code to be extracted\n" +
+	"# from an archive instead of being cloned. This allows to\n" +
+	"# build from source that was pre-processed, e.g. to generate\n" +
+	"# code or vendor dependencies.\n" +
+	"source_archive:\n" +
+	"    # From is the image that contains the archive. It can\n" +
+	"    # be produced by an earlier step or be imported from\n" +
+	"    # an external system through `base_images`.\n" +
+	"    from: ' '\n" +
+	"    # Path is the location of the archive in the above\n" +
+	"    # image. The archive can be uncompressed or be\n" +
+	"    # compressed with gzip, bzip2 or xz. Its content is\n" +
+	"    # extracted into the working directory of the source.\n" +
+	"    path: ' '\n" +
+	"# SourceSnapshots describe alternative source images that\n" +
+	"# contain the code under test along with the code of other\n" +
+	"# repositories, like an open pull request of another repo.\n" +
+	"# Tests can use a snapshot instead of \"src\" by setting\n" +
+	"# source_snapshot.\n" +
+	"source_snapshots:\n" +
+	"    - # As is the name of the snapshot. Its image is tagged\n" +
+	"      # into the pipeline as src-<as>.\n" +
+	"      as: ' '\n" +
+	"      # Refs are the repositories that are cloned in addition\n" +
+	"      # to the refs of the job. Pull requests listed for a\n" +
+	"      # repository are merged into its base_ref.\n" +
+	"      refs:\n" +
+	"        - base_link: ' '\n" +
+	"          base_ref: ' '\n" +
+	"          base_sha: ' '\n" +
+	"          clone_uri: ' '\n" +
+	"          org: ' '\n" +
+	"          path_alias: ' '\n" +
+	"          pulls:\n" +
+	"            - author: ' '\n" +
+	"              author_link: ' '\n" +
+	"              commit_link: ' '\n" +
+	"              link: ' '\n" +
+	"              number: 0\n" +
+	"              ref: ' '\n" +
+	"              sha: ' '\n" +
+	"              title: ' '\n" +
+	"          repo: ' '\n" +
+	"          repo_link: ' '\n" +
 	"# ReleaseTagConfiguration determines how the\n" +
 	"# full release is assembled.\n" +
 	"tag_specification:\n" +
+	"    # Flavor is the flavor of the release payloads that are assembled\n" +
+	"    # from the stream. Defaults to ocp.\n" +
+	"    flavor: ' '\n" +
 	"    # Name is the image stream name to use that contains all\n" +
 	"    # component tags.\n" +
 	"    name: ' '\n" +
@@ -1082,7 +1829,14 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"# The images launched as pods but have no explicit access to\n" +
 	"# the cluster they are running on.\n" +
 	"tests:\n" +
-	"    - # As is the name of the test.\n" +
+	"    - # Architectures are additional architectures the test runs on. For each\n" +
+	"      # of them, prowgen generates another job for the test, named after the\n" +
+	"      # test and the architecture, e.g. `e2e-arm64`, that is dispatched to a\n" +
+	"      # build cluster with nodes of the architecture and runs the builds and\n" +
+	"      # the test on them. Postsubmits cannot run on additional architectures.\n" +
+	"      architectures:\n" +
+	"        - \"\"\n" +
+	"      # As is the name of the test.\n" +
 	"      as: ' '\n" +
 	"      # Cluster specifies the name of the cluster where the test runs.\n" +
 	"      cluster: ' '\n" +
@@ -1093,8 +1847,20 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"        architecture: ' '\n" +
 	"        # Cloud is the cloud where the product is installed, e.g., aws.\n" +
 	"        cloud: ' '\n" +
+	"        # Labels are additional labels the cluster pool must have, e.g. to\n" +
+	"        # select the pool of a team among those providing the same cluster.\n" +
+	"        labels:\n" +
+	"            \"\": \"\"\n" +
+	"        # Lifetime is how long the claimed cluster exists before Hive deletes\n" +
+	"        # it, in case the claim is never released. Defaults to 4h and can not\n" +
+	"        # exceed 12h.\n" +
+	"        lifetime: 0s\n" +
 	"        # Owner is the owner of cloud account used to install the product, e.g., dpp.\n" +
 	"        owner: ' '\n" +
+	"        # Pool is the name of the Hive ClusterPool to claim the cluster from.\n" +
+	"        # When set, the pool is not selected by the product, version,\n" +
+	"        # architecture, cloud and owner, which become optional.\n" +
+	"        pool: ' '\n" +
 	"        # Product is the name of the product being released.\n" +
 	"        # Defaults to ocp.\n" +
 	"        product: ' '\n" +
@@ -1121,10 +1887,22 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"      # of pull request workflows. Setting this field will\n" +
 	"      # create a periodic job instead of a presubmit\n" +
 	"      cron: \"\"\n" +
+	"      # GracePeriod is how long the step may take to clean up after it was\n" +
+	"      # aborted before ci-operator stops waiting for it. Defaults to one minute.\n" +
+	"      grace_period: 0s\n" +
 	"      # Interval is how frequently the test should be run based\n" +
 	"      # on the last time the test ran. Setting this field will\n" +
 	"      # create a periodic job instead of a presubmit\n" +
 	"      interval: \"\"\n" +
+	"      # Leases lists resources that are acquired for a container test. The\n" +
+	"      # names of the leased resources are exposed to the test container in\n" +
+	"      # the configured environment variables. Multi-stage tests configure\n" +
+	"      # their leases under `steps` instead.\n" +
+	"      leases:\n" +
+	"        - # Env is the environment variable that will contain the resource name.\n" +
+	"          env: ' '\n" +
+	"          # ResourceType is the type of resource that will be leased.\n" +
+	"          resource_type: ' '\n" +
 	"      literal_steps:\n" +
 	"        # AllowBestEffortPostSteps defines if any `post` steps can be ignored when\n" +
 	"        # they fail. The given step must explicitly ask for being ignored by setting\n" +
@@ -1150,6 +1928,49 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"        # Environment has the values of parameters for the steps.\n" +
 	"        env:\n" +
 	"            \"\": \"\"\n" +
+	"        # FallbackClusterProfiles are the profiles the test falls back to, in\n" +
+	"        # order, when the leases of ClusterProfile have no free capacity. The\n" +
+	"        # profile the test runs with is exposed to the steps as\n" +
+	"        # $CLUSTER_PROFILE_NAME.\n" +
+	"        fallback_cluster_profiles:\n" +
+	"            - \"\"\n" +
+	"        # Gather enables the built-in step that gathers must-gather, the audit\n" +
+	"        # logs and the events of the cluster under test at the start of the\n" +
+	"        # `post` phase, even when earlier steps failed.\n" +
+	"        gather:\n" +
+	"            # Resources defines the resource requirements for the gathering step.\n" +
+	"            # Defaults to requesting 300m of CPU and 300Mi of memory.\n" +
+	"            resources:\n" +
+	"                # Architectures override the requests and limits for the builds\n" +
+	"                # of images for one of the architectures.\n" +
+	"                architectures:\n" +
+	"                    \"\":\n" +
+	"                        # Limits are resource limits applied to an individual step in the job.\n" +
+	"                        limits:\n" +
+	"                            \"\": \"\"\n" +
+	"                        # Requests are resource requests applied to an individual step in the job.\n" +
+	"                        requests:\n" +
+	"                            \"\": \"\"\n" +
+	"                # ClusterProfiles override the requests and limits for steps that\n" +
+	"                # run with one of the cluster profiles.\n" +
+	"                cluster_profiles:\n" +
+	"                    \"\":\n" +
+	"                        # Limits are resource limits applied to an individual step in the job.\n" +
+	"                        limits:\n" +
+	"                            \"\": \"\"\n" +
+	"                        # Requests are resource requests applied to an individual step in the job.\n" +
+	"                        requests:\n" +
+	"                            \"\": \"\"\n" +
+	"                # Limits are resource limits applied to an individual step in the job.\n" +
+	"                # These are directly used in creating the Pods that execute the Job.\n" +
+	"                limits:\n" +
+	"                    \"\": \"\"\n" +
+	"                # Requests are resource requests applied to an individual step in the job.\n" +
+	"                # These are directly used in creating the Pods that execute the Job.\n" +
+	"                requests:\n" +
+	"                    \"\": \"\"\n" +
+	"            # Timeout is how long the gathering may take. Defaults to 30 minutes.\n" +
+	"            timeout: 0s\n" +
 	"        # Leases lists resources that should be acquired for the test.\n" +
 	"        leases:\n" +
 	"            - # Env is the environment variable that will contain the resource name.\n" +
@@ -1174,7 +1995,12 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"        # Post is the array of test steps run after the tests finish and teardown/deprovision resources.\n" +
 	"        # Post steps always run, even if previous steps fail.\n" +
 	"        post:\n" +
-	"            - # As is the name of the LiteralTestStep.\n" +
+	"            - # Architectures restricts the step to build clusters that have nodes of\n" +
+	"              # at least one of the given architectures. On other clusters, the step is\n" +
+	"              # skipped instead of failing. If unset, the step runs on all clusters.\n" +
+	"              architectures:\n" +
+	"                - \"\"\n" +
+	"              # As is the name of the LiteralTestStep.\n" +
 	"              as: ' '\n" +
 	"              # BestEffort defines if this step should cause the job to fail when the\n" +
 	"              # step fails. This only applies when AllowBestEffortPostSteps flag is set\n" +
@@ -1188,7 +2014,16 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"              commands: ' '\n" +
 	"              # Credentials defines the credentials we'll mount into this step.\n" +
 	"              credentials:\n" +
-	"                - # MountPath is where the secret should be mounted.\n" +
+	"                - # Env lists keys of the secret that are exposed to the step as\n" +
+	"                  # environment variables. Like the rest of the secret, their values\n" +
+	"                  # are censored from all output.\n" +
+	"                  env:\n" +
+	"                    - # Key in the secret whose value is exposed.\n" +
+	"                      key: ' '\n" +
+	"                      # Name of the environment variable.\n" +
+	"                      name: ' '\n" +
+	"                  # MountPath is where the secret should be mounted. It may be omitted\n" +
+	"                  # if keys of the secret are exposed through Env instead.\n" +
 	"                  mount_path: ' '\n" +
 	"                  # Names is which source secret to mount.\n" +
 	"                  name: ' '\n" +
@@ -1201,6 +2036,10 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                  env: ' '\n" +
 	"                  # Name is the tag or stream:tag that this dependency references\n" +
 	"                  name: ' '\n" +
+	"                  # Release is the name of the release the image is taken from, like\n" +
+	"                  # `initial`, `latest` or the name of a custom release. If set, Name is\n" +
+	"                  # the tag of the image in the release.\n" +
+	"                  release: ' '\n" +
 	"              # DnsConfig for step's Pod.\n" +
 	"              dnsConfig:\n" +
 	"                # Nameservers is a list of IP addresses that will be used as DNS servers for the Pod\n" +
@@ -1217,6 +2056,12 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                  documentation: ' '\n" +
 	"                  # Name of the environment variable.\n" +
 	"                  name: ' '\n" +
+	"                  # Type of the value of the parameter, one of `string` (the\n" +
+	"                  # default), `integer`, `boolean` or `enum`.\n" +
+	"                  type: ' '\n" +
+	"                  # Values are the values allowed for an `enum` parameter.\n" +
+	"                  values:\n" +
+	"                    - \"\"\n" +
 	"              # From is the container image that will be used for this step.\n" +
 	"              from: ' '\n" +
 	"              # FromImage is a literal ImageStreamTag reference to use for this step.\n" +
@@ -1229,6 +2074,18 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"              # GracePeriod is how long the we will wait after sending SIGINT to send\n" +
 	"              # SIGKILL when aborting a Step.\n" +
 	"              grace_period: 0s\n" +
+	"              # InitContainers run in order before the step to prepare data for it,\n" +
+	"              # e.g. to download fixtures or unpack tools, keeping the image and the\n" +
+	"              # commands of the step simple. They write the data into the directory\n" +
+	"              # exposed to them and to the step as $INIT_DIR.\n" +
+	"              init_containers:\n" +
+	"                - # As is the name of the init container.\n" +
+	"                  as: ' '\n" +
+	"                  # Commands is the command(s) that will be run inside the image.\n" +
+	"                  commands: ' '\n" +
+	"                  # From is the container image that will be used, it is resolved like\n" +
+	"                  # the `from` of the step.\n" +
+	"                  from: ' '\n" +
 	"              # Leases lists resources that should be acquired for the test.\n" +
 	"              leases:\n" +
 	"                - # Env is the environment variable that will contain the resource name.\n" +
@@ -1238,6 +2095,9 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"              # Observers are the observers that should be running\n" +
 	"              observers:\n" +
 	"                - \"\"\n" +
+	"              # Optional defines if a failure of this step should be ignored: it\n" +
+	"              # neither fails the test nor prevents the following steps from running.\n" +
+	"              optional: false\n" +
 	"              # OptionalOnSuccess defines if this step should be skipped as long\n" +
 	"              # as all `pre` and `test` steps were successful and AllowSkipOnSuccess\n" +
 	"              # flag is set to true in MultiStageTestConfiguration. This option is\n" +
@@ -1245,6 +2105,26 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"              optional_on_success: false\n" +
 	"              # Resources defines the resource requirements for the step.\n" +
 	"              resources:\n" +
+	"                # Architectures override the requests and limits for the builds\n" +
+	"                # of images for one of the architectures.\n" +
+	"                architectures:\n" +
+	"                    \"\":\n" +
+	"                        # Limits are resource limits applied to an individual step in the job.\n" +
+	"                        limits:\n" +
+	"                            \"\": \"\"\n" +
+	"                        # Requests are resource requests applied to an individual step in the job.\n" +
+	"                        requests:\n" +
+	"                            \"\": \"\"\n" +
+	"                # ClusterProfiles override the requests and limits for steps that\n" +
+	"                # run with one of the cluster profiles.\n" +
+	"                cluster_profiles:\n" +
+	"                    \"\":\n" +
+	"                        # Limits are resource limits applied to an individual step in the job.\n" +
+	"                        limits:\n" +
+	"                            \"\": \"\"\n" +
+	"                        # Requests are resource requests applied to an individual step in the job.\n" +
+	"                        requests:\n" +
+	"                            \"\": \"\"\n" +
 	"                # Limits are resource limits applied to an individual step in the job.\n" +
 	"                # These are directly used in creating the Pods that execute the Job.\n" +
 	"                limits:\n" +
@@ -1253,14 +2133,28 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                # These are directly used in creating the Pods that execute the Job.\n" +
 	"                requests:\n" +
 	"                    \"\": \"\"\n" +
+	"              # Retries is how many more times the step is run when it fails before\n" +
+	"              # its failure is reported, e.g. for steps that provision infrastructure\n" +
+	"              # and fail intermittently. Defaults to 0.\n" +
+	"              retries: 0\n" +
 	"              # RunAsScript defines if this step should be executed as a script mounted\n" +
 	"              # in the test container instead of being executed directly via bash\n" +
 	"              run_as_script: false\n" +
+	"              # RunIf defines when the step runs, based on whether any step before it\n" +
+	"              # failed: `success` runs it only if none failed, `failure` only if one\n" +
+	"              # failed and `always` regardless. Defaults to `success` for `pre` and\n" +
+	"              # `test` steps and to `always` for `post` steps.\n" +
+	"              run_if: ' '\n" +
 	"              # Timeout is how long the we will wait before aborting a job with SIGINT.\n" +
 	"              timeout: 0s\n" +
 	"        # Pre is the array of test steps run to set up the environment for the test.\n" +
 	"        pre:\n" +
-	"            - # As is the name of the LiteralTestStep.\n" +
+	"            - # Architectures restricts the step to build clusters that have nodes of\n" +
+	"              # at least one of the given architectures. On other clusters, the step is\n" +
+	"              # skipped instead of failing. If unset, the step runs on all clusters.\n" +
+	"              architectures:\n" +
+	"                - \"\"\n" +
+	"              # As is the name of the LiteralTestStep.\n" +
 	"              as: ' '\n" +
 	"              # BestEffort defines if this step should cause the job to fail when the\n" +
 	"              # step fails. This only applies when AllowBestEffortPostSteps flag is set\n" +
@@ -1274,7 +2168,16 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"              commands: ' '\n" +
 	"              # Credentials defines the credentials we'll mount into this step.\n" +
 	"              credentials:\n" +
-	"                - # MountPath is where the secret should be mounted.\n" +
+	"                - # Env lists keys of the secret that are exposed to the step as\n" +
+	"                  # environment variables. Like the rest of the secret, their values\n" +
+	"                  # are censored from all output.\n" +
+	"                  env:\n" +
+	"                    - # Key in the secret whose value is exposed.\n" +
+	"                      key: ' '\n" +
+	"                      # Name of the environment variable.\n" +
+	"                      name: ' '\n" +
+	"                  # MountPath is where the secret should be mounted. It may be omitted\n" +
+	"                  # if keys of the secret are exposed through Env instead.\n" +
 	"                  mount_path: ' '\n" +
 	"                  # Names is which source secret to mount.\n" +
 	"                  name: ' '\n" +
@@ -1287,6 +2190,10 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                  env: ' '\n" +
 	"                  # Name is the tag or stream:tag that this dependency references\n" +
 	"                  name: ' '\n" +
+	"                  # Release is the name of the release the image is taken from, like\n" +
+	"                  # `initial`, `latest` or the name of a custom release. If set, Name is\n" +
+	"                  # the tag of the image in the release.\n" +
+	"                  release: ' '\n" +
 	"              # DnsConfig for step's Pod.\n" +
 	"              dnsConfig:\n" +
 	"                # Nameservers is a list of IP addresses that will be used as DNS servers for the Pod\n" +
@@ -1303,6 +2210,12 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                  documentation: ' '\n" +
 	"                  # Name of the environment variable.\n" +
 	"                  name: ' '\n" +
+	"                  # Type of the value of the parameter, one of `string` (the\n" +
+	"                  # default), `integer`, `boolean` or `enum`.\n" +
+	"                  type: ' '\n" +
+	"                  # Values are the values allowed for an `enum` parameter.\n" +
+	"                  values:\n" +
+	"                    - \"\"\n" +
 	"              # From is the container image that will be used for this step.\n" +
 	"              from: ' '\n" +
 	"              # FromImage is a literal ImageStreamTag reference to use for this step.\n" +
@@ -1315,6 +2228,18 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"              # GracePeriod is how long the we will wait after sending SIGINT to send\n" +
 	"              # SIGKILL when aborting a Step.\n" +
 	"              grace_period: 0s\n" +
+	"              # InitContainers run in order before the step to prepare data for it,\n" +
+	"              # e.g. to download fixtures or unpack tools, keeping the image and the\n" +
+	"              # commands of the step simple. They write the data into the directory\n" +
+	"              # exposed to them and to the step as $INIT_DIR.\n" +
+	"              init_containers:\n" +
+	"                - # As is the name of the init container.\n" +
+	"                  as: ' '\n" +
+	"                  # Commands is the command(s) that will be run inside the image.\n" +
+	"                  commands: ' '\n" +
+	"                  # From is the container image that will be used, it is resolved like\n" +
+	"                  # the `from` of the step.\n" +
+	"                  from: ' '\n" +
 	"              # Leases lists resources that should be acquired for the test.\n" +
 	"              leases:\n" +
 	"                - # Env is the environment variable that will contain the resource name.\n" +
@@ -1324,6 +2249,9 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"              # Observers are the observers that should be running\n" +
 	"              observers:\n" +
 	"                - \"\"\n" +
+	"              # Optional defines if a failure of this step should be ignored: it\n" +
+	"              # neither fails the test nor prevents the following steps from running.\n" +
+	"              optional: false\n" +
 	"              # OptionalOnSuccess defines if this step should be skipped as long\n" +
 	"              # as all `pre` and `test` steps were successful and AllowSkipOnSuccess\n" +
 	"              # flag is set to true in MultiStageTestConfiguration. This option is\n" +
@@ -1331,6 +2259,26 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"              optional_on_success: false\n" +
 	"              # Resources defines the resource requirements for the step.\n" +
 	"              resources:\n" +
+	"                # Architectures override the requests and limits for the builds\n" +
+	"                # of images for one of the architectures.\n" +
+	"                architectures:\n" +
+	"                    \"\":\n" +
+	"                        # Limits are resource limits applied to an individual step in the job.\n" +
+	"                        limits:\n" +
+	"                            \"\": \"\"\n" +
+	"                        # Requests are resource requests applied to an individual step in the job.\n" +
+	"                        requests:\n" +
+	"                            \"\": \"\"\n" +
+	"                # ClusterProfiles override the requests and limits for steps that\n" +
+	"                # run with one of the cluster profiles.\n" +
+	"                cluster_profiles:\n" +
+	"                    \"\":\n" +
+	"                        # Limits are resource limits applied to an individual step in the job.\n" +
+	"                        limits:\n" +
+	"                            \"\": \"\"\n" +
+	"                        # Requests are resource requests applied to an individual step in the job.\n" +
+	"                        requests:\n" +
+	"                            \"\": \"\"\n" +
 	"                # Limits are resource limits applied to an individual step in the job.\n" +
 	"                # These are directly used in creating the Pods that execute the Job.\n" +
 	"                limits:\n" +
@@ -1339,14 +2287,28 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                # These are directly used in creating the Pods that execute the Job.\n" +
 	"                requests:\n" +
 	"                    \"\": \"\"\n" +
+	"              # Retries is how many more times the step is run when it fails before\n" +
+	"              # its failure is reported, e.g. for steps that provision infrastructure\n" +
+	"              # and fail intermittently. Defaults to 0.\n" +
+	"              retries: 0\n" +
 	"              # RunAsScript defines if this step should be executed as a script mounted\n" +
 	"              # in the test container instead of being executed directly via bash\n" +
 	"              run_as_script: false\n" +
+	"              # RunIf defines when the step runs, based on whether any step before it\n" +
+	"              # failed: `success` runs it only if none failed, `failure` only if one\n" +
+	"              # failed and `always` regardless. Defaults to `success` for `pre` and\n" +
+	"              # `test` steps and to `always` for `post` steps.\n" +
+	"              run_if: ' '\n" +
 	"              # Timeout is how long the we will wait before aborting a job with SIGINT.\n" +
 	"              timeout: 0s\n" +
 	"        # Test is the array of test steps that define the actual test.\n" +
 	"        test:\n" +
-	"            - # As is the name of the LiteralTestStep.\n" +
+	"            - # Architectures restricts the step to build clusters that have nodes of\n" +
+	"              # at least one of the given architectures. On other clusters, the step is\n" +
+	"              # skipped instead of failing. If unset, the step runs on all clusters.\n" +
+	"              architectures:\n" +
+	"                - \"\"\n" +
+	"              # As is the name of the LiteralTestStep.\n" +
 	"              as: ' '\n" +
 	"              # BestEffort defines if this step should cause the job to fail when the\n" +
 	"              # step fails. This only applies when AllowBestEffortPostSteps flag is set\n" +
@@ -1360,7 +2322,16 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"              commands: ' '\n" +
 	"              # Credentials defines the credentials we'll mount into this step.\n" +
 	"              credentials:\n" +
-	"                - # MountPath is where the secret should be mounted.\n" +
+	"                - # Env lists keys of the secret that are exposed to the step as\n" +
+	"                  # environment variables. Like the rest of the secret, their values\n" +
+	"                  # are censored from all output.\n" +
+	"                  env:\n" +
+	"                    - # Key in the secret whose value is exposed.\n" +
+	"                      key: ' '\n" +
+	"                      # Name of the environment variable.\n" +
+	"                      name: ' '\n" +
+	"                  # MountPath is where the secret should be mounted. It may be omitted\n" +
+	"                  # if keys of the secret are exposed through Env instead.\n" +
 	"                  mount_path: ' '\n" +
 	"                  # Names is which source secret to mount.\n" +
 	"                  name: ' '\n" +
@@ -1373,6 +2344,10 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                  env: ' '\n" +
 	"                  # Name is the tag or stream:tag that this dependency references\n" +
 	"                  name: ' '\n" +
+	"                  # Release is the name of the release the image is taken from, like\n" +
+	"                  # `initial`, `latest` or the name of a custom release. If set, Name is\n" +
+	"                  # the tag of the image in the release.\n" +
+	"                  release: ' '\n" +
 	"              # DnsConfig for step's Pod.\n" +
 	"              dnsConfig:\n" +
 	"                # Nameservers is a list of IP addresses that will be used as DNS servers for the Pod\n" +
@@ -1389,6 +2364,12 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                  documentation: ' '\n" +
 	"                  # Name of the environment variable.\n" +
 	"                  name: ' '\n" +
+	"                  # Type of the value of the parameter, one of `string` (the\n" +
+	"                  # default), `integer`, `boolean` or `enum`.\n" +
+	"                  type: ' '\n" +
+	"                  # Values are the values allowed for an `enum` parameter.\n" +
+	"                  values:\n" +
+	"                    - \"\"\n" +
 	"              # From is the container image that will be used for this step.\n" +
 	"              from: ' '\n" +
 	"              # FromImage is a literal ImageStreamTag reference to use for this step.\n" +
@@ -1401,6 +2382,18 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"              # GracePeriod is how long the we will wait after sending SIGINT to send\n" +
 	"              # SIGKILL when aborting a Step.\n" +
 	"              grace_period: 0s\n" +
+	"              # InitContainers run in order before the step to prepare data for it,\n" +
+	"              # e.g. to download fixtures or unpack tools, keeping the image and the\n" +
+	"              # commands of the step simple. They write the data into the directory\n" +
+	"              # exposed to them and to the step as $INIT_DIR.\n" +
+	"              init_containers:\n" +
+	"                - # As is the name of the init container.\n" +
+	"                  as: ' '\n" +
+	"                  # Commands is the command(s) that will be run inside the image.\n" +
+	"                  commands: ' '\n" +
+	"                  # From is the container image that will be used, it is resolved like\n" +
+	"                  # the `from` of the step.\n" +
+	"                  from: ' '\n" +
 	"              # Leases lists resources that should be acquired for the test.\n" +
 	"              leases:\n" +
 	"                - # Env is the environment variable that will contain the resource name.\n" +
@@ -1410,6 +2403,9 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"              # Observers are the observers that should be running\n" +
 	"              observers:\n" +
 	"                - \"\"\n" +
+	"              # Optional defines if a failure of this step should be ignored: it\n" +
+	"              # neither fails the test nor prevents the following steps from running.\n" +
+	"              optional: false\n" +
 	"              # OptionalOnSuccess defines if this step should be skipped as long\n" +
 	"              # as all `pre` and `test` steps were successful and AllowSkipOnSuccess\n" +
 	"              # flag is set to true in MultiStageTestConfiguration. This option is\n" +
@@ -1417,6 +2413,26 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"              optional_on_success: false\n" +
 	"              # Resources defines the resource requirements for the step.\n" +
 	"              resources:\n" +
+	"                # Architectures override the requests and limits for the builds\n" +
+	"                # of images for one of the architectures.\n" +
+	"                architectures:\n" +
+	"                    \"\":\n" +
+	"                        # Limits are resource limits applied to an individual step in the job.\n" +
+	"                        limits:\n" +
+	"                            \"\": \"\"\n" +
+	"                        # Requests are resource requests applied to an individual step in the job.\n" +
+	"                        requests:\n" +
+	"                            \"\": \"\"\n" +
+	"                # ClusterProfiles override the requests and limits for steps that\n" +
+	"                # run with one of the cluster profiles.\n" +
+	"                cluster_profiles:\n" +
+	"                    \"\":\n" +
+	"                        # Limits are resource limits applied to an individual step in the job.\n" +
+	"                        limits:\n" +
+	"                            \"\": \"\"\n" +
+	"                        # Requests are resource requests applied to an individual step in the job.\n" +
+	"                        requests:\n" +
+	"                            \"\": \"\"\n" +
 	"                # Limits are resource limits applied to an individual step in the job.\n" +
 	"                # These are directly used in creating the Pods that execute the Job.\n" +
 	"                limits:\n" +
@@ -1425,11 +2441,33 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                # These are directly used in creating the Pods that execute the Job.\n" +
 	"                requests:\n" +
 	"                    \"\": \"\"\n" +
+	"              # Retries is how many more times the step is run when it fails before\n" +
+	"              # its failure is reported, e.g. for steps that provision infrastructure\n" +
+	"              # and fail intermittently. Defaults to 0.\n" +
+	"              retries: 0\n" +
 	"              # RunAsScript defines if this step should be executed as a script mounted\n" +
 	"              # in the test container instead of being executed directly via bash\n" +
 	"              run_as_script: false\n" +
+	"              # RunIf defines when the step runs, based on whether any step before it\n" +
+	"              # failed: `success` runs it only if none failed, `failure` only if one\n" +
+	"              # failed and `always` regardless. Defaults to `success` for `pre` and\n" +
+	"              # `test` steps and to `always` for `post` steps.\n" +
+	"              run_if: ' '\n" +
 	"              # Timeout is how long the we will wait before aborting a job with SIGINT.\n" +
 	"              timeout: 0s\n" +
+	"      # NamespaceTTL configures how long the namespace the test runs in is\n" +
+	"      # retained, e.g. to keep it around for debugging after a failure.\n" +
+	"      namespace_ttl:\n" +
+	"        # Hard is how long the namespace is retained after it was last\n" +
+	"        # active. Overrides the --delete-after flag of ci-operator.\n" +
+	"        hard: 0s\n" +
+	"        # Idle is how long the namespace is retained after it was last\n" +
+	"        # active once no pods run in it anymore. Overrides the\n" +
+	"        # --delete-when-idle flag of ci-operator.\n" +
+	"        idle: 0s\n" +
+	"        # KeepOnFailure is how long the namespace is retained after the\n" +
+	"        # test failed, regardless of the other TTLs.\n" +
+	"        keep_on_failure: 0s\n" +
 	"      openshift_ansible:\n" +
 	"        cluster_profile: ' '\n" +
 	"      openshift_ansible_custom:\n" +
@@ -1467,6 +2505,9 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"          mount_path: ' '\n" +
 	"          # Secret name, used inside test containers\n" +
 	"          name: ' '\n" +
+	"      # SourceSnapshot is the name of an entry in source_snapshots\n" +
+	"      # whose image replaces \"src\" for this test.\n" +
+	"      source_snapshot: ' '\n" +
 	"      steps:\n" +
 	"        # AllowBestEffortPostSteps defines if any `post` steps can be ignored when\n" +
 	"        # they fail. The given step must explicitly ask for being ignored by setting\n" +
@@ -1492,6 +2533,49 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"        # Environment has the values of parameters for the steps.\n" +
 	"        env:\n" +
 	"            \"\": \"\"\n" +
+	"        # FallbackClusterProfiles are the profiles the test falls back to, in\n" +
+	"        # order, when the leases of ClusterProfile have no free capacity. The\n" +
+	"        # profile the test runs with is exposed to the steps as\n" +
+	"        # $CLUSTER_PROFILE_NAME.\n" +
+	"        fallback_cluster_profiles:\n" +
+	"            - \"\"\n" +
+	"        # Gather enables the built-in step that gathers must-gather, the audit\n" +
+	"        # logs and the events of the cluster under test at the start of the\n" +
+	"        # `post` phase, even when earlier steps failed.\n" +
+	"        gather:\n" +
+	"            # Resources defines the resource requirements for the gathering step.\n" +
+	"            # Defaults to requesting 300m of CPU and 300Mi of memory.\n" +
+	"            resources:\n" +
+	"                # Architectures override the requests and limits for the builds\n" +
+	"                # of images for one of the architectures.\n" +
+	"                architectures:\n" +
+	"                    \"\":\n" +
+	"                        # Limits are resource limits applied to an individual step in the job.\n" +
+	"                        limits:\n" +
+	"                            \"\": \"\"\n" +
+	"                        # Requests are resource requests applied to an individual step in the job.\n" +
+	"                        requests:\n" +
+	"                            \"\": \"\"\n" +
+	"                # ClusterProfiles override the requests and limits for steps that\n" +
+	"                # run with one of the cluster profiles.\n" +
+	"                cluster_profiles:\n" +
+	"                    \"\":\n" +
+	"                        # Limits are resource limits applied to an individual step in the job.\n" +
+	"                        limits:\n" +
+	"                            \"\": \"\"\n" +
+	"                        # Requests are resource requests applied to an individual step in the job.\n" +
+	"                        requests:\n" +
+	"                            \"\": \"\"\n" +
+	"                # Limits are resource limits applied to an individual step in the job.\n" +
+	"                # These are directly used in creating the Pods that execute the Job.\n" +
+	"                limits:\n" +
+	"                    \"\": \"\"\n" +
+	"                # Requests are resource requests applied to an individual step in the job.\n" +
+	"                # These are directly used in creating the Pods that execute the Job.\n" +
+	"                requests:\n" +
+	"                    \"\": \"\"\n" +
+	"            # Timeout is how long the gathering may take. Defaults to 30 minutes.\n" +
+	"            timeout: 0s\n" +
 	"        # Leases lists resources that should be acquired for the test.\n" +
 	"        leases:\n" +
 	"            - # Env is the environment variable that will contain the resource name.\n" +
@@ -1511,7 +2595,10 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"        # execution if previous Pre and Test steps passed.\n" +
 	"        post:\n" +
 	"            # LiteralTestStep is a full test step definition.\n" +
-	"            - as: ' '\n" +
+	"            - architectures:\n" +
+	"                # LiteralTestStep is a full test step definition.\n" +
+	"                - \"\"\n" +
+	"              as: ' '\n" +
 	"              best_effort: false\n" +
 	"              # Chain is the name of a step chain reference.\n" +
 	"              chain: \"\"\n" +
@@ -1521,13 +2608,18 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"              commands: ' '\n" +
 	"              credentials:\n" +
 	"                # LiteralTestStep is a full test step definition.\n" +
-	"                - mount_path: ' '\n" +
+	"                - env:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    - key: ' '\n" +
+	"                      name: ' '\n" +
+	"                  mount_path: ' '\n" +
 	"                  name: ' '\n" +
 	"                  namespace: ' '\n" +
 	"              dependencies:\n" +
 	"                # LiteralTestStep is a full test step definition.\n" +
 	"                - env: ' '\n" +
 	"                  name: ' '\n" +
+	"                  release: ' '\n" +
 	"              dnsConfig:\n" +
 	"                # LiteralTestStep is a full test step definition.\n" +
 	"                nameservers:\n" +
@@ -1541,6 +2633,10 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                - default: \"\"\n" +
 	"                  documentation: ' '\n" +
 	"                  name: ' '\n" +
+	"                  type: ' '\n" +
+	"                  values:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    - \"\"\n" +
 	"              from: ' '\n" +
 	"              from_image:\n" +
 	"                # LiteralTestStep is a full test step definition.\n" +
@@ -1549,6 +2645,11 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                namespace: ' '\n" +
 	"                tag: ' '\n" +
 	"              grace_period: 0s\n" +
+	"              init_containers:\n" +
+	"                # LiteralTestStep is a full test step definition.\n" +
+	"                - as: ' '\n" +
+	"                  commands: ' '\n" +
+	"                  from: ' '\n" +
 	"              leases:\n" +
 	"                # LiteralTestStep is a full test step definition.\n" +
 	"                - env: ' '\n" +
@@ -1556,24 +2657,44 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"              observers:\n" +
 	"                # LiteralTestStep is a full test step definition.\n" +
 	"                - \"\"\n" +
+	"              optional: false\n" +
 	"              optional_on_success: false\n" +
 	"              # Reference is the name of a step reference.\n" +
 	"              ref: \"\"\n" +
 	"              # Resources defines the resource requirements for the step.\n" +
 	"              resources:\n" +
 	"                # LiteralTestStep is a full test step definition.\n" +
+	"                architectures:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    \"\":\n" +
+	"                        limits:\n" +
+	"                            \"\": \"\"\n" +
+	"                        requests:\n" +
+	"                            \"\": \"\"\n" +
+	"                cluster_profiles:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    \"\":\n" +
+	"                        limits:\n" +
+	"                            \"\": \"\"\n" +
+	"                        requests:\n" +
+	"                            \"\": \"\"\n" +
 	"                limits:\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
 	"                    \"\": \"\"\n" +
 	"                requests:\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
 	"                    \"\": \"\"\n" +
+	"              retries: 0\n" +
 	"              run_as_script: false\n" +
+	"              run_if: ' '\n" +
 	"              timeout: 0s\n" +
 	"        # Pre is the array of test steps run to set up the environment for the test.\n" +
 	"        pre:\n" +
 	"            # LiteralTestStep is a full test step definition.\n" +
-	"            - as: ' '\n" +
+	"            - architectures:\n" +
+	"                # LiteralTestStep is a full test step definition.\n" +
+	"                - \"\"\n" +
+	"              as: ' '\n" +
 	"              best_effort: false\n" +
 	"              # Chain is the name of a step chain reference.\n" +
 	"              chain: \"\"\n" +
@@ -1583,13 +2704,18 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"              commands: ' '\n" +
 	"              credentials:\n" +
 	"                # LiteralTestStep is a full test step definition.\n" +
-	"                - mount_path: ' '\n" +
+	"                - env:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    - key: ' '\n" +
+	"                      name: ' '\n" +
+	"                  mount_path: ' '\n" +
 	"                  name: ' '\n" +
 	"                  namespace: ' '\n" +
 	"              dependencies:\n" +
 	"                # LiteralTestStep is a full test step definition.\n" +
 	"                - env: ' '\n" +
 	"                  name: ' '\n" +
+	"                  release: ' '\n" +
 	"              dnsConfig:\n" +
 	"                # LiteralTestStep is a full test step definition.\n" +
 	"                nameservers:\n" +
@@ -1603,6 +2729,10 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                - default: \"\"\n" +
 	"                  documentation: ' '\n" +
 	"                  name: ' '\n" +
+	"                  type: ' '\n" +
+	"                  values:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    - \"\"\n" +
 	"              from: ' '\n" +
 	"              from_image:\n" +
 	"                # LiteralTestStep is a full test step definition.\n" +
@@ -1611,6 +2741,11 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                namespace: ' '\n" +
 	"                tag: ' '\n" +
 	"              grace_period: 0s\n" +
+	"              init_containers:\n" +
+	"                # LiteralTestStep is a full test step definition.\n" +
+	"                - as: ' '\n" +
+	"                  commands: ' '\n" +
+	"                  from: ' '\n" +
 	"              leases:\n" +
 	"                # LiteralTestStep is a full test step definition.\n" +
 	"                - env: ' '\n" +
@@ -1618,24 +2753,44 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"              observers:\n" +
 	"                # LiteralTestStep is a full test step definition.\n" +
 	"                - \"\"\n" +
+	"              optional: false\n" +
 	"              optional_on_success: false\n" +
 	"              # Reference is the name of a step reference.\n" +
 	"              ref: \"\"\n" +
 	"              # Resources defines the resource requirements for the step.\n" +
 	"              resources:\n" +
 	"                # LiteralTestStep is a full test step definition.\n" +
+	"                architectures:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    \"\":\n" +
+	"                        limits:\n" +
+	"                            \"\": \"\"\n" +
+	"                        requests:\n" +
+	"                            \"\": \"\"\n" +
+	"                cluster_profiles:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    \"\":\n" +
+	"                        limits:\n" +
+	"                            \"\": \"\"\n" +
+	"                        requests:\n" +
+	"                            \"\": \"\"\n" +
 	"                limits:\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
 	"                    \"\": \"\"\n" +
 	"                requests:\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
 	"                    \"\": \"\"\n" +
+	"              retries: 0\n" +
 	"              run_as_script: false\n" +
+	"              run_if: ' '\n" +
 	"              timeout: 0s\n" +
 	"        # Test is the array of test steps that define the actual test.\n" +
 	"        test:\n" +
 	"            # LiteralTestStep is a full test step definition.\n" +
-	"            - as: ' '\n" +
+	"            - architectures:\n" +
+	"                # LiteralTestStep is a full test step definition.\n" +
+	"                - \"\"\n" +
+	"              as: ' '\n" +
 	"              best_effort: false\n" +
 	"              # Chain is the name of a step chain reference.\n" +
 	"              chain: \"\"\n" +
@@ -1645,13 +2800,18 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"              commands: ' '\n" +
 	"              credentials:\n" +
 	"                # LiteralTestStep is a full test step definition.\n" +
-	"                - mount_path: ' '\n" +
+	"                - env:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    - key: ' '\n" +
+	"                      name: ' '\n" +
+	"                  mount_path: ' '\n" +
 	"                  name: ' '\n" +
 	"                  namespace: ' '\n" +
 	"              dependencies:\n" +
 	"                # LiteralTestStep is a full test step definition.\n" +
 	"                - env: ' '\n" +
 	"                  name: ' '\n" +
+	"                  release: ' '\n" +
 	"              dnsConfig:\n" +
 	"                # LiteralTestStep is a full test step definition.\n" +
 	"                nameservers:\n" +
@@ -1665,6 +2825,10 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                - default: \"\"\n" +
 	"                  documentation: ' '\n" +
 	"                  name: ' '\n" +
+	"                  type: ' '\n" +
+	"                  values:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    - \"\"\n" +
 	"              from: ' '\n" +
 	"              from_image:\n" +
 	"                # LiteralTestStep is a full test step definition.\n" +
@@ -1673,6 +2837,11 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                namespace: ' '\n" +
 	"                tag: ' '\n" +
 	"              grace_period: 0s\n" +
+	"              init_containers:\n" +
+	"                # LiteralTestStep is a full test step definition.\n" +
+	"                - as: ' '\n" +
+	"                  commands: ' '\n" +
+	"                  from: ' '\n" +
 	"              leases:\n" +
 	"                # LiteralTestStep is a full test step definition.\n" +
 	"                - env: ' '\n" +
@@ -1680,23 +2849,42 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"              observers:\n" +
 	"                # LiteralTestStep is a full test step definition.\n" +
 	"                - \"\"\n" +
+	"              optional: false\n" +
 	"              optional_on_success: false\n" +
 	"              # Reference is the name of a step reference.\n" +
 	"              ref: \"\"\n" +
 	"              # Resources defines the resource requirements for the step.\n" +
 	"              resources:\n" +
 	"                # LiteralTestStep is a full test step definition.\n" +
+	"                architectures:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    \"\":\n" +
+	"                        limits:\n" +
+	"                            \"\": \"\"\n" +
+	"                        requests:\n" +
+	"                            \"\": \"\"\n" +
+	"                cluster_profiles:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    \"\":\n" +
+	"                        limits:\n" +
+	"                            \"\": \"\"\n" +
+	"                        requests:\n" +
+	"                            \"\": \"\"\n" +
 	"                limits:\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
 	"                    \"\": \"\"\n" +
 	"                requests:\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
 	"                    \"\": \"\"\n" +
+	"              retries: 0\n" +
 	"              run_as_script: false\n" +
+	"              run_if: ' '\n" +
 	"              timeout: 0s\n" +
 	"        # Workflow is the name of the workflow to be used for this configuration. For fields defined in both\n" +
 	"        # the config and the workflow, the fields from the config will override what is set in Workflow.\n" +
 	"        workflow: \"\"\n" +
+	"      # Timeout is how long the step may run before it is aborted.\n" +
+	"      timeout: 0s\n" +
 	"zz_generated_metadata:\n" +
 	"    branch: ' '\n" +
 	"    org: ' '\n" +